@@ -5,17 +5,54 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/upb/llm-control-plane/backend/auth"
 	"github.com/upb/llm-control-plane/backend/config"
 	"github.com/upb/llm-control-plane/backend/cognito"
 	"github.com/upb/llm-control-plane/backend/internal/providers"
+	"github.com/upb/llm-control-plane/backend/internal/rag"
 	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/oidc"
 	"github.com/upb/llm-control-plane/backend/repositories"
 	"github.com/upb/llm-control-plane/backend/repositories/postgres"
 	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/apikey"
+	"github.com/upb/llm-control-plane/backend/services/async"
+	"github.com/upb/llm-control-plane/backend/services/audit"
+	"github.com/upb/llm-control-plane/backend/services/batch"
+	"github.com/upb/llm-control-plane/backend/services/budget"
+	"github.com/upb/llm-control-plane/backend/services/cache"
+	"github.com/upb/llm-control-plane/backend/services/conversation"
+	"github.com/upb/llm-control-plane/backend/services/embedding"
+	"github.com/upb/llm-control-plane/backend/services/experiment"
+	"github.com/upb/llm-control-plane/backend/services/idempotency"
+	"github.com/upb/llm-control-plane/backend/services/impersonation"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/ingestion"
+	"github.com/upb/llm-control-plane/backend/services/policy"
+	"github.com/upb/llm-control-plane/backend/services/prompt"
+	svcproviders "github.com/upb/llm-control-plane/backend/services/providers"
+	"github.com/upb/llm-control-plane/backend/services/providers/openai"
+	"github.com/upb/llm-control-plane/backend/services/ratelimit"
+	"github.com/upb/llm-control-plane/backend/services/rbac"
+	"github.com/upb/llm-control-plane/backend/services/routing"
+	"github.com/upb/llm-control-plane/backend/services/scim"
+	"github.com/upb/llm-control-plane/backend/services/serviceaccount"
+	"github.com/upb/llm-control-plane/backend/services/session"
+	"github.com/upb/llm-control-plane/backend/services/shadow"
+	"github.com/upb/llm-control-plane/backend/services/template"
+	"github.com/upb/llm-control-plane/backend/services/tenant"
+	"github.com/upb/llm-control-plane/backend/services/webhook"
+	"github.com/upb/llm-control-plane/backend/tokens"
 	"go.uber.org/zap"
 )
 
+// Policy cache bounds for the inference pipeline
+const (
+	policyCacheSize = 1000
+	policyCacheTTL  = 5 * time.Minute
+)
+
 // Dependencies holds all application dependencies following the GrantPulse pattern.
 // This is the central wiring point for dependency injection.
 type Dependencies struct {
@@ -24,6 +61,10 @@ type Dependencies struct {
 	DB     *postgres.DB
 	Logger *zap.Logger
 
+	// VectorSearch is set when the pgvector extension is installed; RAG
+	// ingestion and retrieval are disabled without it
+	VectorSearch bool
+
 	// Repository Factory
 	RepoFactory *postgres.RepositoryFactory
 
@@ -34,14 +75,91 @@ type Dependencies struct {
 	Policies          repositories.PolicyRepository
 	AuditLogs         repositories.AuditRepository
 	InferenceRequests repositories.InferenceRequestRepository
+	Experiments       repositories.ExperimentRepository
+	ShadowResponses   repositories.ShadowResponseRepository
+	PromptTemplates   repositories.PromptTemplateRepository
+	RAGDocuments      repositories.RAGDocumentRepository
+	Conversations     repositories.ConversationRepository
+	Batches           repositories.BatchRepository
+	APIKeys           repositories.APIKeyRepository
+	Roles             repositories.RoleRepository
+	Sessions          repositories.SessionRepository
+	ServiceAccounts   repositories.ServiceAccountRepository
+	SCIMConfigs       repositories.SCIMConfigRepository
+	SCIMGroups        repositories.SCIMGroupRepository
 	TxManager         repositories.TransactionManager
 
+	// Services
+	ExperimentService *experiment.ExperimentService
+	ResponseCache     *cache.ResponseCacheService
+	TemplateService   *template.TemplateService
+	Embedder          *embedding.EmbeddingService // nil when no embedding provider is configured
+	IngestionService  *ingestion.IngestionService
+	Retriever         rag.Retriever
+	WebhookService    *webhook.WebhookService
+	Idempotency       *idempotency.IdempotencyService
+	APIKeyService     *apikey.APIKeyService
+	RBACService       *rbac.RBACService
+	TenantService     *tenant.TenantService
+	SessionService    *session.SessionService
+	AuditService      *audit.AuditService
+
+	// TokenIssuer signs the gateway's own JWTs, issued to service accounts
+	// by ServiceAccountService and accepted by AuthMiddleware
+	TokenIssuer           *tokens.Issuer
+	ServiceAccountService *serviceaccount.ServiceAccountService
+
+	// SCIMService provisions users and groups from organizations' identity providers
+	SCIMService *scim.SCIMService
+
+	// ImpersonationService issues admins tokens acting as users, signed by TokenIssuer
+	ImpersonationService *impersonation.ImpersonationService
+
+	// ConversationService needs an inference pipeline to complete turns; it is
+	// nil until one is wired with InitConversations
+	ConversationService *conversation.ConversationService
+
+	// BatchService runs batch jobs through the inference pipeline; it is nil
+	// until one is wired with InitBatches
+	BatchService *batch.BatchService
+
+	// Pipeline runs chat completions for the vendor-compatible /v1 APIs and
+	// Models lists the models it routes to; both are nil until wired with
+	// InitPipeline
+	Pipeline Completer
+	Models   ModelCatalog
+
+	// ShadowService mirrors the pipeline's sampled traffic to shadow targets
+	ShadowService *shadow.ShadowService
+
+	// AsyncInferenceService runs ?async=true chat completions in the background;
+	// it is nil until a pipeline is wired with InitAsyncInference
+	AsyncInferenceService *async.AsyncService
+
 	// Provider Registry
 	ProviderRegistry *ProviderRegistry
 
 	// Auth
 	authHandler    *auth.Handler
+	oidcHandler    *auth.OIDCHandler
 	AuthMiddleware *middleware.AuthMiddleware
+
+	// APIKeyMiddleware authenticates applications by API key
+	APIKeyMiddleware *middleware.APIKeyMiddleware
+
+	// IdempotencyMiddleware replays outcomes of requests retried with an Idempotency-Key
+	IdempotencyMiddleware *middleware.IdempotencyMiddleware
+}
+
+// Completer runs chat completions through the inference pipeline
+type Completer interface {
+	ProcessChatCompletion(ctx context.Context, req *inference.CompletionRequest) (*inference.CompletionResponse, error)
+}
+
+// ModelCatalog lists the models the inference pipeline routes to
+type ModelCatalog interface {
+	ListModels() []string
+	GetModelInfo(model string) (*svcproviders.ModelInfo, error)
 }
 
 // AuthHandler returns the auth handler for route wiring (implements handlers.AuthDeps)
@@ -49,6 +167,12 @@ func (d *Dependencies) AuthHandler() *auth.Handler {
 	return d.authHandler
 }
 
+// OIDCHandler returns the generic OIDC auth handler, or nil when no OIDC
+// providers are configured
+func (d *Dependencies) OIDCHandler() *auth.OIDCHandler {
+	return d.oidcHandler
+}
+
 // NewDependencies creates and wires up all application dependencies.
 // This follows the GrantPulse pattern of centralized dependency injection.
 func NewDependencies(ctx context.Context, cfg *config.Config, logger *zap.Logger) (*Dependencies, error) {
@@ -67,13 +191,36 @@ func NewDependencies(ctx context.Context, cfg *config.Config, logger *zap.Logger
 		return nil, fmt.Errorf("failed to initialize repositories: %w", err)
 	}
 
+	// Initialize the gateway's embedder
+	deps.initEmbedder(cfg)
+
+	// Initialize the issuer of gateway-signed tokens
+	if err := deps.initTokens(cfg); err != nil {
+		return nil, fmt.Errorf("failed to initialize token issuer: %w", err)
+	}
+
+	// Initialize services
+	deps.initServices()
+
+	// Start the audit log workers
+	if err := deps.initAudit(); err != nil {
+		return nil, fmt.Errorf("failed to initialize audit service: %w", err)
+	}
+
 	// Initialize provider registry
 	if err := deps.initProviders(cfg); err != nil {
 		return nil, fmt.Errorf("failed to initialize providers: %w", err)
 	}
 
-	// Initialize auth (Cognito OAuth2)
-	deps.initAuth(cfg)
+	// Initialize auth (Cognito and generic OIDC OAuth2)
+	if err := deps.initAuth(cfg); err != nil {
+		return nil, fmt.Errorf("failed to initialize auth: %w", err)
+	}
+
+	// Initialize the inference pipeline and the features that run through it
+	if err := deps.initPipeline(cfg); err != nil {
+		return nil, fmt.Errorf("failed to initialize inference pipeline: %w", err)
+	}
 
 	logger.Info("all dependencies initialized successfully")
 	return deps, nil
@@ -94,6 +241,13 @@ func (d *Dependencies) initDatabase(ctx context.Context, cfg *config.Config) err
 		return fmt.Errorf("database ping failed: %w", err)
 	}
 
+	// RAG needs pgvector, which the schema does not require
+	vector, err := d.DB.VectorAvailable(ctx)
+	if err != nil {
+		return err
+	}
+	d.VectorSearch = vector
+
 	// Initialize audit schema when using separate audit DB
 	if err := factory.InitAuditSchema(ctx); err != nil {
 		return fmt.Errorf("failed to initialize audit schema: %w", err)
@@ -115,12 +269,180 @@ func (d *Dependencies) initRepositories() error {
 	d.Policies = repos.Policies
 	d.AuditLogs = repos.AuditLogs
 	d.InferenceRequests = repos.InferenceRequests
+	d.Experiments = repos.Experiments
+	d.ShadowResponses = repos.ShadowResponses
+	d.PromptTemplates = repos.PromptTemplates
+	d.RAGDocuments = repos.RAGDocuments
+	d.Conversations = repos.Conversations
+	d.Batches = repos.Batches
+	d.APIKeys = repos.APIKeys
+	d.Roles = repos.Roles
+	d.Sessions = repos.Sessions
+	d.ServiceAccounts = repos.ServiceAccounts
+	d.SCIMConfigs = repos.SCIMConfigs
+	d.SCIMGroups = repos.SCIMGroups
 	d.TxManager = d.RepoFactory.GetTransactionManager()
 
 	d.Logger.Info("repositories initialized")
 	return nil
 }
 
+// initTokens initializes the issuer of gateway-signed tokens
+func (d *Dependencies) initTokens(cfg *config.Config) error {
+	issuer, err := tokens.NewIssuer(cfg.Tokens)
+	if err != nil {
+		return err
+	}
+	if issuer.Ephemeral() {
+		d.Logger.Warn("gateway signing key not configured, using an ephemeral key; service account tokens will not survive restarts or be accepted by other instances")
+	}
+	d.TokenIssuer = issuer
+	return nil
+}
+
+// initServices initializes service instances that depend only on repositories
+func (d *Dependencies) initServices() {
+	d.ExperimentService = experiment.NewExperimentService(d.Experiments, d.InferenceRequests, d.Logger)
+	d.TemplateService = template.NewTemplateService(d.PromptTemplates, d.Applications, d.TxManager, d.Logger)
+
+	// Without an embedder the cache serves exact matches only and RAG is
+	// disabled; without pgvector only RAG is
+	var embedder rag.Embedder
+	if d.Embedder != nil {
+		embedder = d.Embedder
+		budgetService := budget.NewBudgetService(d.DB.DB, d.Logger)
+		d.Embedder.SetCostRecorder(budgetService)
+		if d.VectorSearch {
+			d.IngestionService = ingestion.NewIngestionService(d.RAGDocuments, d.Embedder, budgetService, d.TxManager, d.Logger)
+			d.Retriever = rag.NewPGVectorRetriever(d.DB.DB, d.Embedder)
+		} else {
+			d.Logger.Warn("pgvector extension not installed, RAG ingestion and retrieval disabled")
+		}
+	}
+	d.ResponseCache = cache.NewResponseCacheService(cache.DefaultMaxEntries, embedder, d.Logger)
+	d.WebhookService = webhook.NewWebhookService(d.Applications, d.Logger, webhook.DefaultConfig())
+	d.Idempotency = idempotency.NewIdempotencyService(d.Logger, idempotency.DefaultConfig())
+	d.IdempotencyMiddleware = middleware.NewIdempotencyMiddleware(d.Idempotency, d.Logger)
+	d.APIKeyService = apikey.NewAPIKeyService(d.APIKeys, d.Applications, d.TxManager, d.Logger)
+	d.APIKeyMiddleware = middleware.NewAPIKeyMiddleware(d.APIKeyService, d.Logger)
+	d.RBACService = rbac.NewRBACService(d.Roles, d.Logger)
+	d.TenantService = tenant.NewTenantService(d.Organizations, d.Applications, d.Users, d.APIKeyService, d.TxManager, d.RBACService, d.Logger)
+	d.SessionService = session.NewSessionService(d.Sessions, d.Logger)
+	d.ServiceAccountService = serviceaccount.NewServiceAccountService(d.ServiceAccounts, d.TokenIssuer, d.RBACService, d.Logger)
+	d.ImpersonationService = impersonation.NewImpersonationService(d.Users, d.TokenIssuer, d.RBACService, d.Logger)
+	d.SCIMService = scim.NewSCIMService(d.SCIMConfigs, d.Users, d.SCIMGroups, d.Sessions, d.APIKeys, d.TxManager, d.RBACService, d.Logger)
+
+	d.Logger.Info("services initialized")
+}
+
+// initAudit starts the audit service and has services record their changes with it
+func (d *Dependencies) initAudit() error {
+	d.AuditService = audit.NewAuditService(d.AuditLogs, d.Logger, audit.DefaultConfig())
+	if err := d.AuditService.Start(); err != nil {
+		return err
+	}
+
+	d.APIKeyService.SetAuditService(d.AuditService)
+	d.RBACService.SetAuditService(d.AuditService)
+	d.TenantService.SetAuditService(d.AuditService)
+	d.ServiceAccountService.SetAuditService(d.AuditService)
+	d.SCIMService.SetAuditService(d.AuditService)
+	d.ImpersonationService.SetAuditService(d.AuditService)
+	return nil
+}
+
+// InitConversations enables conversation threads completed by completer
+func (d *Dependencies) InitConversations(completer conversation.Completer) {
+	d.ConversationService = conversation.NewConversationService(d.Conversations, completer, d.TxManager, d.Logger)
+}
+
+// InitBatches enables batch jobs completed by completer and starts their workers
+func (d *Dependencies) InitBatches(completer batch.Completer) error {
+	d.BatchService = batch.NewBatchService(d.Batches, completer, d.TxManager, d.Logger, batch.DefaultConfig())
+	return d.BatchService.Start()
+}
+
+// InitAsyncInference enables async chat completions run by completer and starts their workers
+func (d *Dependencies) InitAsyncInference(completer async.Completer) error {
+	d.AsyncInferenceService = async.NewAsyncService(d.InferenceRequests, completer, d.WebhookService, d.Logger, async.DefaultConfig())
+	return d.AsyncInferenceService.Start()
+}
+
+// InitPipeline exposes the inference pipeline through the vendor-compatible
+// /v1 APIs, listing the models of catalog
+func (d *Dependencies) InitPipeline(pipeline Completer, catalog ModelCatalog) {
+	d.Pipeline = pipeline
+	d.Models = catalog
+}
+
+// initPipeline builds the inference pipeline over the configured providers
+// and enables the /v1 APIs, conversations, batches and async completions on it
+func (d *Dependencies) initPipeline(cfg *config.Config) error {
+	registry := svcproviders.NewRegistry()
+	if cfg.Providers.OpenAI.APIKey != "" {
+		if err := registry.RegisterProvider(openai.NewOpenAIAdapter(openAIProviderConfig(cfg.Providers.OpenAI))); err != nil {
+			return fmt.Errorf("failed to register OpenAI provider: %w", err)
+		}
+	}
+
+	routingService := routing.NewRoutingService(routing.DefaultRoutingConfig(), registry)
+	budgetService := budget.NewBudgetService(d.DB.DB, d.Logger)
+	d.ShadowService = shadow.NewShadowService(routingService, budgetService, d.ShadowResponses, d.Logger)
+
+	pipeline := inference.NewInferenceService(
+		policy.NewPolicyService(d.Policies, policy.NewPolicyCache(policyCacheSize, policyCacheTTL), d.Logger),
+		ratelimit.NewRateLimitService(d.DB.DB, d.Logger),
+		budgetService,
+		prompt.NewPromptServiceWithDefaults(),
+		routingService,
+		d.AuditService,
+		d.Logger,
+	)
+	pipeline.SetInferenceRequestRepository(d.InferenceRequests)
+	pipeline.SetExperimentService(d.ExperimentService)
+	pipeline.SetShadowService(d.ShadowService)
+	pipeline.SetResponseCache(d.ResponseCache)
+	pipeline.SetTemplateService(d.TemplateService)
+	pipeline.SetAPIKeyService(d.APIKeyService)
+	if d.Retriever != nil {
+		pipeline.SetRetriever(d.Retriever)
+	}
+
+	d.InitPipeline(pipeline, registry)
+	d.InitConversations(pipeline)
+	if err := d.InitBatches(pipeline); err != nil {
+		return fmt.Errorf("failed to start batch service: %w", err)
+	}
+	if err := d.InitAsyncInference(pipeline); err != nil {
+		return fmt.Errorf("failed to start async inference service: %w", err)
+	}
+
+	d.Logger.Info("inference pipeline initialized", zap.Strings("models", registry.ListModels()))
+	return nil
+}
+
+// openAIProviderConfig converts the OpenAI configuration for the provider adapter
+func openAIProviderConfig(cfg config.OpenAIConfig) svcproviders.ProviderConfig {
+	return svcproviders.ProviderConfig{
+		APIKey:     cfg.APIKey,
+		BaseURL:    cfg.BaseURL,
+		Timeout:    cfg.Timeout,
+		MaxRetries: cfg.MaxRetries,
+	}
+}
+
+// initEmbedder creates the gateway's embedder from the OpenAI configuration
+func (d *Dependencies) initEmbedder(cfg *config.Config) {
+	if cfg.Providers.OpenAI.APIKey == "" {
+		d.Logger.Warn("no embedding provider configured, RAG ingestion and semantic caching disabled")
+		return
+	}
+
+	adapter := openai.NewOpenAIAdapter(openAIProviderConfig(cfg.Providers.OpenAI))
+	d.Embedder = embedding.NewEmbeddingService(adapter, cfg.Providers.OpenAI.EmbeddingModel, d.Logger)
+	d.Logger.Info("embedder initialized", zap.String("model", cfg.Providers.OpenAI.EmbeddingModel))
+}
+
 // initProviders initializes the provider registry with configured providers
 func (d *Dependencies) initProviders(cfg *config.Config) error {
 	registry := NewProviderRegistry(d.Logger)
@@ -154,26 +476,69 @@ func (d *Dependencies) initProviders(cfg *config.Config) error {
 	return nil
 }
 
-func (d *Dependencies) initAuth(cfg *config.Config) {
+func (d *Dependencies) initAuth(cfg *config.Config) error {
+	// IdP refresh tokens are only kept when they can be encrypted at rest
+	key, err := cfg.Sessions.RefreshTokenKeyBytes()
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		d.Logger.Warn("session refresh token key not configured, sessions end with their identity")
+	} else if err := d.SessionService.SetRefreshTokenKey(key); err != nil {
+		return err
+	}
+
+	// Use reject-all validator so protected routes return 401 without an identity provider
+	var tokenValidator middleware.TokenValidator = &rejectAllValidator{}
+
 	if cfg.Cognito.Domain == "" || cfg.Cognito.ClientID == "" {
-		d.Logger.Warn("cognito not configured, auth endpoints disabled")
-		// Use reject-all validator so protected routes return 401
-		d.AuthMiddleware = middleware.NewAuthMiddleware(&rejectAllValidator{}, d.Logger)
-		return
+		d.Logger.Warn("cognito not configured, cognito auth endpoints disabled")
+	} else {
+		cognitoValidator := cognito.NewCognitoValidator(cognito.Config{
+			Region:      cfg.Cognito.Region,
+			UserPoolID:  cfg.Cognito.UserPoolID,
+			ClientID:    cfg.Cognito.ClientID,
+			CacheTTL:    time.Hour,
+			HTTPTimeout: 10 * time.Second,
+		})
+		// Adapter converts cognito.ParsedClaims to middleware.Claims for AuthMiddleware
+		tokenValidator = &cognitoTokenValidatorAdapter{validator: cognitoValidator}
+		exchanger := services.NewCognitoTokenExchanger(cfg.Cognito)
+		d.authHandler = auth.NewHandler(cfg, exchanger, cognitoValidator, d.Logger)
+		d.authHandler.SetSessionManager(d.SessionService)
+		d.SessionService.SetRefresher(auth.CognitoProviderName, auth.NewCognitoRefresher(exchanger, cognitoValidator))
+		d.Logger.Info("auth handler initialized")
+	}
+
+	// Generic OIDC providers; tokens of their issuers are validated by them,
+	// everything else by Cognito
+	if len(cfg.OIDC.Providers) > 0 {
+		registry, err := oidc.NewRegistryFromConfig(cfg.OIDC, nil)
+		if err != nil {
+			return err
+		}
+		tokenValidator = &oidcTokenValidatorAdapter{registry: registry, fallback: tokenValidator}
+
+		providers := make(map[string]auth.OIDCProvider)
+		for _, provider := range registry.Providers() {
+			providers[provider.Name()] = provider
+			d.SessionService.SetRefresher(provider.Name(), auth.NewOIDCRefresher(provider))
+		}
+		d.oidcHandler = auth.NewOIDCHandler(providers, cfg.Cognito.FrontEndURL, d.Logger)
+		d.oidcHandler.SetSessionManager(d.SessionService)
+		d.Logger.Info("oidc providers initialized", zap.Int("providers", registry.Len()))
 	}
-	cognitoValidator := cognito.NewCognitoValidator(cognito.Config{
-		Region:      cfg.Cognito.Region,
-		UserPoolID:  cfg.Cognito.UserPoolID,
-		ClientID:    cfg.Cognito.ClientID,
-		CacheTTL:    time.Hour,
-		HTTPTimeout: 10 * time.Second,
-	})
-	// Adapter converts cognito.ParsedClaims to middleware.Claims for AuthMiddleware
-	tokenValidator := &cognitoTokenValidatorAdapter{validator: cognitoValidator}
+
+	// Tokens the gateway issued itself are validated locally
+	tokenValidator = &gatewayTokenValidatorAdapter{issuer: d.TokenIssuer, fallback: tokenValidator}
+
 	d.AuthMiddleware = middleware.NewAuthMiddleware(tokenValidator, d.Logger)
-	exchanger := services.NewCognitoTokenExchanger(cfg.Cognito)
-	d.authHandler = auth.NewHandler(cfg, exchanger, cognitoValidator, d.Logger)
-	d.Logger.Info("auth handler initialized")
+	d.AuthMiddleware.SetAPIKeyAuthenticator(d.APIKeyService)
+	d.AuthMiddleware.SetSessionAuthenticator(d.SessionService)
+	d.AuthMiddleware.SetPermissionChecker(d.RBACService)
+	d.AuthMiddleware.SetImpersonationAuditor(d.AuditService)
+	d.AuthMiddleware.SetUserFinder(d.Users)
+	return nil
 }
 
 // cognitoTokenValidatorAdapter adapts cognito.CognitoValidator to middleware.TokenValidator
@@ -206,6 +571,87 @@ func (a *cognitoTokenValidatorAdapter) ValidateToken(ctx context.Context, token
 	}, nil
 }
 
+// oidcTokenValidatorAdapter adapts the OIDC providers to middleware.TokenValidator,
+// passing tokens of other issuers to the fallback
+type oidcTokenValidatorAdapter struct {
+	registry *oidc.Registry
+	fallback middleware.TokenValidator
+}
+
+func (a *oidcTokenValidatorAdapter) ValidateToken(ctx context.Context, token string) (*middleware.Claims, error) {
+	if !a.registry.Handles(token) {
+		return a.fallback.ValidateToken(ctx, token)
+	}
+
+	identity, err := a.registry.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	appIDStr := ""
+	if identity.AppID != nil {
+		appIDStr = identity.AppID.String()
+	}
+	// Only UUID subjects identify users of the gateway; other IdPs' subjects are opaque
+	userIDStr := ""
+	if _, err := uuid.Parse(identity.Subject); err == nil {
+		userIDStr = identity.Subject
+	}
+	return &middleware.Claims{
+		Sub:           identity.Subject,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+		Groups:        identity.Roles,
+		OrgID:         identity.OrgID.String(),
+		AppID:         appIDStr,
+		UserID:        userIDStr,
+		Iss:           identity.Issuer,
+		Exp:           identity.ExpiresAt.Unix(),
+		Iat:           identity.IssuedAt.Unix(),
+	}, nil
+}
+
+// gatewayTokenValidatorAdapter adapts the gateway's token issuer to
+// middleware.TokenValidator, passing tokens of other issuers to the fallback.
+// Service accounts are presented with their roles as groups and their scopes;
+// impersonation tokens as their user, with the admin as impersonator.
+type gatewayTokenValidatorAdapter struct {
+	issuer   *tokens.Issuer
+	fallback middleware.TokenValidator
+}
+
+func (a *gatewayTokenValidatorAdapter) ValidateToken(ctx context.Context, token string) (*middleware.Claims, error) {
+	if !a.issuer.Handles(token) {
+		return a.fallback.ValidateToken(ctx, token)
+	}
+
+	claims, err := a.issuer.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	validated := &middleware.Claims{
+		Sub:    "serviceaccount:" + claims.Subject,
+		Groups: claims.Roles,
+		OrgID:  claims.OrgID,
+		Iss:    claims.Issuer,
+		Scopes: claims.Scopes(),
+	}
+	if claims.Actor != nil {
+		validated.Sub = claims.Subject
+		validated.UserID = claims.Subject
+		validated.Impersonator = &middleware.Impersonator{
+			Sub:    claims.Actor.Subject,
+			UserID: claims.Actor.UserID,
+		}
+	}
+	if claims.ExpiresAt != nil {
+		validated.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		validated.Iat = claims.IssuedAt.Unix()
+	}
+	return validated, nil
+}
+
 // rejectAllValidator rejects all tokens (used when Cognito is not configured)
 type rejectAllValidator struct{}
 
@@ -219,6 +665,26 @@ func (d *Dependencies) Close(ctx context.Context) error {
 
 	var errs []error
 
+	// Stop background workers before the database they report to
+	if d.AsyncInferenceService != nil {
+		if err := d.AsyncInferenceService.Stop(30 * time.Second); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop async inference service: %w", err))
+		}
+	}
+	if d.BatchService != nil {
+		if err := d.BatchService.Stop(30 * time.Second); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop batch service: %w", err))
+		}
+	}
+	if d.ShadowService != nil {
+		d.ShadowService.Wait()
+	}
+	if d.AuditService != nil {
+		if err := d.AuditService.Stop(30 * time.Second); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop audit service: %w", err))
+		}
+	}
+
 	// Close database connection
 	if d.RepoFactory != nil {
 		if err := d.RepoFactory.Close(); err != nil {
@@ -45,6 +45,14 @@ func TestNewDependencies(t *testing.T) {
 		// Verify provider registry
 		assert.NotNil(t, deps.ProviderRegistry)
 
+		// Verify the inference pipeline and the features that run through it
+		assert.NotNil(t, deps.Pipeline)
+		assert.NotNil(t, deps.Models)
+		assert.NotNil(t, deps.ShadowService)
+		assert.NotNil(t, deps.ConversationService)
+		assert.NotNil(t, deps.BatchService)
+		assert.NotNil(t, deps.AsyncInferenceService)
+
 		// Cleanup
 		err = deps.Close(ctx)
 		assert.NoError(t, err)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/upb/llm-control-plane/backend/config"
 	"github.com/upb/llm-control-plane/backend/cognito"
+	"github.com/upb/llm-control-plane/backend/services"
 	"github.com/upb/llm-control-plane/backend/utils"
 	"go.uber.org/zap"
 )
@@ -17,7 +18,8 @@ import (
 const (
 	// StateCookieName is the cookie name for OAuth state (CSRF)
 	StateCookieName = "oauth_state"
-	// SessionCookieName is the cookie name for the session token
+	// SessionCookieName is the cookie name for the session token, or for the
+	// ID token when no SessionManager is set
 	SessionCookieName = "session"
 	stateCookieMaxAge = 600
 	sessionCookieMaxAge = 86400 * 7 // 7 days
@@ -25,7 +27,7 @@ const (
 
 // TokenExchanger exchanges OAuth2 authorization codes for tokens via the OAuth2 token endpoint.
 type TokenExchanger interface {
-	ExchangeCode(ctx context.Context, code, redirectURI, state string) (idToken string, err error)
+	ExchangeCode(ctx context.Context, code, redirectURI, state string) (*services.TokenResponse, error)
 }
 
 // TokenValidator validates JWT tokens and returns parsed claims.
@@ -38,6 +40,7 @@ type Handler struct {
 	cfg       *config.Config
 	exchanger TokenExchanger
 	validator TokenValidator
+	sessions  SessionManager
 	logger    *zap.Logger
 }
 
@@ -51,6 +54,11 @@ func NewHandler(cfg *config.Config, exchanger TokenExchanger, validator TokenVal
 	}
 }
 
+// SetSessionManager starts a server-side session at login and ends it at logout
+func (h *Handler) SetSessionManager(sessions SessionManager) {
+	h.sessions = sessions
+}
+
 // HandleLogin redirects to Cognito hosted UI for OAuth2 authorization
 func (h *Handler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	if h.cfg.Cognito.Domain == "" || h.cfg.Cognito.ClientID == "" {
@@ -80,7 +88,8 @@ func (h *Handler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, authURL, http.StatusFound)
 }
 
-// HandleCallback exchanges the authorization code for tokens, validates the JWT, and sets the session cookie
+// HandleCallback exchanges the authorization code for tokens, validates the JWT,
+// starts a session and sets the session cookie
 func (h *Handler) HandleCallback(w http.ResponseWriter, r *http.Request) {
 	code := r.URL.Query().Get("code")
 	state := r.URL.Query().Get("state")
@@ -116,7 +125,7 @@ func (h *Handler) HandleCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	idToken, err := h.exchanger.ExchangeCode(r.Context(), code, h.cfg.Cognito.RedirectURI, state)
+	tokens, err := h.exchanger.ExchangeCode(r.Context(), code, h.cfg.Cognito.RedirectURI, state)
 	if err != nil {
 		h.logger.Warn("token exchange failed", zap.Error(err))
 		_ = utils.WriteUnauthorized(w, "Authentication failed")
@@ -129,7 +138,7 @@ func (h *Handler) HandleCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = h.validator.ValidateToken(r.Context(), idToken)
+	parsed, err := h.validator.ValidateToken(r.Context(), tokens.IDToken)
 	if err != nil {
 		h.logger.Warn("token validation failed", zap.Error(err))
 		_ = utils.WriteUnauthorized(w, "Invalid token")
@@ -137,15 +146,17 @@ func (h *Handler) HandleCallback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	secure := strings.HasPrefix(h.cfg.Cognito.RedirectURI, "https")
-	http.SetCookie(w, &http.Cookie{
-		Name:     SessionCookieName,
-		Value:    idToken,
-		Path:     "/",
-		MaxAge:   sessionCookieMaxAge,
-		HttpOnly: true,
-		Secure:   secure,
-		SameSite: http.SameSiteStrictMode,
-	})
+	if h.sessions == nil {
+		setSessionCookie(w, tokens.IDToken, sessionCookieMaxAge, secure)
+	} else {
+		created, err := h.sessions.Create(r.Context(), CognitoIdentity(parsed), tokens.RefreshToken, clientInfo(r))
+		if err != nil {
+			h.logger.Error("failed to create session", zap.Error(err))
+			_ = utils.WriteInternalServerError(w, "Failed to sign in")
+			return
+		}
+		setSessionCookie(w, created.Token, sessionCookieAge(created.ExpiresAt), secure)
+	}
 
 	redirectURL := h.cfg.Cognito.FrontEndURL
 	if redirectURL == "" {
@@ -154,17 +165,15 @@ func (h *Handler) HandleCallback(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, redirectURL, http.StatusFound)
 }
 
-// HandleLogout clears the session cookie and redirects to Cognito logout
+// HandleLogout ends the session, clears the session cookie and redirects to Cognito logout
 func (h *Handler) HandleLogout(w http.ResponseWriter, r *http.Request) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     SessionCookieName,
-		Value:    "",
-		Path:     "/",
-		MaxAge:   -1,
-		HttpOnly: true,
-		Secure:   strings.HasPrefix(h.cfg.Cognito.RedirectURI, "https"),
-		SameSite: http.SameSiteStrictMode,
-	})
+	if cookie, err := r.Cookie(SessionCookieName); err == nil && h.sessions != nil {
+		if err := h.sessions.RevokeToken(r.Context(), cookie.Value); err != nil {
+			h.logger.Error("failed to revoke session", zap.Error(err))
+		}
+	}
+
+	setSessionCookie(w, "", -1, strings.HasPrefix(h.cfg.Cognito.RedirectURI, "https"))
 
 	logoutURL := buildLogoutURL(h.cfg.Cognito.Domain, h.cfg.Cognito.ClientID, h.cfg.Cognito.RedirectURI)
 	http.Redirect(w, r, logoutURL, http.StatusFound)
@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/upb/llm-control-plane/backend/oidc"
+	"github.com/upb/llm-control-plane/backend/services/session"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// OIDCStateCookieName is the cookie name for OIDC OAuth state (CSRF)
+	OIDCStateCookieName = "oidc_state"
+	// OIDCNonceCookieName is the cookie name for the nonce the ID token must carry
+	OIDCNonceCookieName = "oidc_nonce"
+)
+
+// OIDCProvider signs users in with an OpenID Connect issuer
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce string) (string, error)
+	ExchangeCode(ctx context.Context, code string) (*oidc.Tokens, error)
+	ValidateToken(ctx context.Context, token string) (*oidc.Identity, error)
+	EndSessionURL(ctx context.Context, idTokenHint, postLogoutRedirectURI string) (string, error)
+	RedirectURI() string
+}
+
+// OIDCHandler handles OAuth2 authentication flows with generic OpenID Connect
+// providers, addressed by name in the {provider} URL parameter
+type OIDCHandler struct {
+	providers   map[string]OIDCProvider
+	frontEndURL string
+	sessions    SessionManager
+	logger      *zap.Logger
+}
+
+// NewOIDCHandler creates a new OIDC auth handler for the named providers
+func NewOIDCHandler(providers map[string]OIDCProvider, frontEndURL string, logger *zap.Logger) *OIDCHandler {
+	return &OIDCHandler{
+		providers:   providers,
+		frontEndURL: frontEndURL,
+		logger:      logger,
+	}
+}
+
+// SetSessionManager starts a server-side session at login and ends it at logout
+func (h *OIDCHandler) SetSessionManager(sessions SessionManager) {
+	h.sessions = sessions
+}
+
+// HandleLogin redirects to the provider's authorization endpoint
+func (h *OIDCHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.provider(w, r)
+	if !ok {
+		return
+	}
+
+	state, err := generateSecureState()
+	if err != nil {
+		h.logger.Error("failed to generate state", zap.Error(err))
+		_ = utils.WriteInternalServerError(w, "Failed to initiate login")
+		return
+	}
+	nonce, err := generateSecureState()
+	if err != nil {
+		h.logger.Error("failed to generate nonce", zap.Error(err))
+		_ = utils.WriteInternalServerError(w, "Failed to initiate login")
+		return
+	}
+
+	authURL, err := provider.AuthCodeURL(r.Context(), state, nonce)
+	if err != nil {
+		h.logger.Error("oidc discovery failed",
+			zap.String("provider", chi.URLParam(r, "provider")),
+			zap.Error(err))
+		_ = utils.WriteInternalServerError(w, "Failed to initiate login")
+		return
+	}
+
+	secure := strings.HasPrefix(provider.RedirectURI(), "https")
+	setShortLivedCookie(w, OIDCStateCookieName, state, stateCookieMaxAge, secure)
+	setShortLivedCookie(w, OIDCNonceCookieName, nonce, stateCookieMaxAge, secure)
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// HandleCallback exchanges the authorization code for an ID token, validates
+// it against the provider and the login's nonce, starts a session and sets
+// the session cookie
+func (h *OIDCHandler) HandleCallback(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.provider(w, r)
+	if !ok {
+		return
+	}
+
+	code := r.URL.Query().Get("code")
+	state := r.URL.Query().Get("state")
+	if code == "" {
+		_ = utils.WriteBadRequest(w, "Missing authorization code", nil)
+		return
+	}
+	if state == "" {
+		_ = utils.WriteBadRequest(w, "Missing state parameter", nil)
+		return
+	}
+
+	stateCookie, err := r.Cookie(OIDCStateCookieName)
+	if err != nil || stateCookie.Value != state {
+		_ = utils.WriteBadRequest(w, "Invalid or expired state", nil)
+		return
+	}
+	nonceCookie, err := r.Cookie(OIDCNonceCookieName)
+	if err != nil || nonceCookie.Value == "" {
+		_ = utils.WriteBadRequest(w, "Invalid or expired state", nil)
+		return
+	}
+
+	secure := strings.HasPrefix(provider.RedirectURI(), "https")
+	setShortLivedCookie(w, OIDCStateCookieName, "", -1, secure)
+	setShortLivedCookie(w, OIDCNonceCookieName, "", -1, secure)
+
+	tokens, err := provider.ExchangeCode(r.Context(), code)
+	if err != nil {
+		h.logger.Warn("token exchange failed", zap.Error(err))
+		_ = utils.WriteUnauthorized(w, "Authentication failed")
+		return
+	}
+
+	identity, err := provider.ValidateToken(r.Context(), tokens.IDToken)
+	if err != nil {
+		h.logger.Warn("token validation failed", zap.Error(err))
+		_ = utils.WriteUnauthorized(w, "Invalid token")
+		return
+	}
+	if identity.Nonce != nonceCookie.Value {
+		h.logger.Warn("id token nonce mismatch", zap.String("provider", identity.Provider))
+		_ = utils.WriteUnauthorized(w, "Invalid token")
+		return
+	}
+
+	if h.sessions == nil {
+		setSessionCookie(w, tokens.IDToken, sessionCookieMaxAge, secure)
+	} else {
+		created, err := h.sessions.Create(r.Context(), OIDCIdentity(identity), tokens.RefreshToken, clientInfo(r))
+		if err != nil {
+			h.logger.Error("failed to create session", zap.Error(err))
+			_ = utils.WriteInternalServerError(w, "Failed to sign in")
+			return
+		}
+		setSessionCookie(w, created.Token, sessionCookieAge(created.ExpiresAt), secure)
+	}
+
+	h.logger.Info("oidc login",
+		zap.String("provider", identity.Provider),
+		zap.String("sub", identity.Subject),
+		zap.String("org_id", identity.OrgID.String()))
+
+	http.Redirect(w, r, h.redirectURL(), http.StatusFound)
+}
+
+// HandleLogout ends the session, clears the session cookie and redirects to
+// the provider's end session endpoint, or to the front end when it has none
+func (h *OIDCHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	provider, ok := h.provider(w, r)
+	if !ok {
+		return
+	}
+
+	// Legacy cookies hold the ID token, which is passed on as a hint
+	var idToken string
+	if cookie, err := r.Cookie(SessionCookieName); err == nil {
+		if strings.HasPrefix(cookie.Value, session.TokenPrefix) {
+			if h.sessions != nil {
+				if err := h.sessions.RevokeToken(r.Context(), cookie.Value); err != nil {
+					h.logger.Error("failed to revoke session", zap.Error(err))
+				}
+			}
+		} else {
+			idToken = cookie.Value
+		}
+	}
+
+	setSessionCookie(w, "", -1, strings.HasPrefix(provider.RedirectURI(), "https"))
+
+	logoutURL, err := provider.EndSessionURL(r.Context(), idToken, h.redirectURL())
+	if err != nil {
+		h.logger.Warn("oidc discovery failed", zap.Error(err))
+	}
+	if logoutURL == "" {
+		logoutURL = h.redirectURL()
+	}
+	http.Redirect(w, r, logoutURL, http.StatusFound)
+}
+
+// provider returns the provider named in the URL
+func (h *OIDCHandler) provider(w http.ResponseWriter, r *http.Request) (OIDCProvider, bool) {
+	provider, ok := h.providers[chi.URLParam(r, "provider")]
+	if !ok {
+		_ = utils.WriteNotFound(w, "Unknown identity provider")
+		return nil, false
+	}
+	return provider, true
+}
+
+// redirectURL returns the post-login redirect target
+func (h *OIDCHandler) redirectURL() string {
+	if h.frontEndURL == "" {
+		return "/"
+	}
+	return h.frontEndURL
+}
+
+// setShortLivedCookie sets an HttpOnly cookie of the login flow
+func setShortLivedCookie(w http.ResponseWriter, name, value string, maxAge int, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   secure,
+		// Lax so the cookies come along on the provider's redirect back
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/cognito"
+	"github.com/upb/llm-control-plane/backend/oidc"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/session"
+)
+
+// CognitoProviderName names Cognito as the provider of sessions
+const CognitoProviderName = "cognito"
+
+// SessionManager keeps signed-in users' sessions on the server. When one is
+// set, the session cookie carries an opaque session token instead of the
+// provider's ID token.
+type SessionManager interface {
+	Create(ctx context.Context, identity *session.Identity, refreshToken string, client session.ClientInfo) (*session.CreatedSession, error)
+	RevokeToken(ctx context.Context, token string) error
+}
+
+// CognitoRefreshExchanger exchanges Cognito refresh tokens for new tokens
+type CognitoRefreshExchanger interface {
+	RefreshTokens(ctx context.Context, refreshToken string) (*services.TokenResponse, error)
+}
+
+// CognitoRefresher renews the identities of Cognito sessions
+type CognitoRefresher struct {
+	exchanger CognitoRefreshExchanger
+	validator TokenValidator
+}
+
+// NewCognitoRefresher creates a refresher of Cognito sessions
+func NewCognitoRefresher(exchanger CognitoRefreshExchanger, validator TokenValidator) *CognitoRefresher {
+	return &CognitoRefresher{
+		exchanger: exchanger,
+		validator: validator,
+	}
+}
+
+// Refresh implements session.Refresher
+func (r *CognitoRefresher) Refresh(ctx context.Context, refreshToken string) (*session.Identity, string, error) {
+	tokens, err := r.exchanger.RefreshTokens(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidGrant) {
+			return nil, "", fmt.Errorf("%w: %v", session.ErrRefreshRejected, err)
+		}
+		return nil, "", err
+	}
+	parsed, err := r.validator.ValidateToken(ctx, tokens.IDToken)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", session.ErrRefreshRejected, err)
+	}
+	return CognitoIdentity(parsed), tokens.RefreshToken, nil
+}
+
+// OIDCRefreshProvider refreshes and validates an OIDC provider's ID tokens
+type OIDCRefreshProvider interface {
+	Refresh(ctx context.Context, refreshToken string) (*oidc.Tokens, error)
+	ValidateToken(ctx context.Context, token string) (*oidc.Identity, error)
+}
+
+// OIDCRefresher renews the identities of an OIDC provider's sessions
+type OIDCRefresher struct {
+	provider OIDCRefreshProvider
+}
+
+// NewOIDCRefresher creates a refresher of an OIDC provider's sessions
+func NewOIDCRefresher(provider OIDCRefreshProvider) *OIDCRefresher {
+	return &OIDCRefresher{provider: provider}
+}
+
+// Refresh implements session.Refresher
+func (r *OIDCRefresher) Refresh(ctx context.Context, refreshToken string) (*session.Identity, string, error) {
+	tokens, err := r.provider.Refresh(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidGrant) {
+			return nil, "", fmt.Errorf("%w: %v", session.ErrRefreshRejected, err)
+		}
+		return nil, "", err
+	}
+	identity, err := r.provider.ValidateToken(ctx, tokens.IDToken)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", session.ErrRefreshRejected, err)
+	}
+	return OIDCIdentity(identity), tokens.RefreshToken, nil
+}
+
+// CognitoIdentity maps a validated Cognito ID token to a session identity
+func CognitoIdentity(parsed *cognito.ParsedClaims) *session.Identity {
+	userID := parsed.Sub
+	roles := []string{}
+	if parsed.Role != "" {
+		roles = append(roles, parsed.Role)
+	}
+	return &session.Identity{
+		Provider:      CognitoProviderName,
+		Subject:       parsed.Sub.String(),
+		Email:         parsed.Email,
+		EmailVerified: parsed.EmailVerified,
+		OrgID:         parsed.OrgID,
+		AppID:         parsed.AppID,
+		UserID:        &userID,
+		Roles:         roles,
+		ExpiresAt:     parsed.ExpiresAt,
+	}
+}
+
+// OIDCIdentity maps a validated OIDC ID token to a session identity
+func OIDCIdentity(identity *oidc.Identity) *session.Identity {
+	mapped := &session.Identity{
+		Provider:      identity.Provider,
+		Issuer:        identity.Issuer,
+		Subject:       identity.Subject,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+		OrgID:         identity.OrgID,
+		AppID:         identity.AppID,
+		Roles:         identity.Roles,
+		ExpiresAt:     identity.ExpiresAt,
+	}
+	// Only UUID subjects identify users of the gateway; other IdPs' subjects are opaque
+	if userID, err := uuid.Parse(identity.Subject); err == nil {
+		mapped.UserID = &userID
+	}
+	return mapped
+}
+
+// clientInfo describes the client of a login request
+func clientInfo(r *http.Request) session.ClientInfo {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return session.ClientInfo{
+		UserAgent: r.UserAgent(),
+		IPAddress: ip,
+	}
+}
+
+// sessionCookieAge returns the max age of a session's cookie
+func sessionCookieAge(expiresAt time.Time) int {
+	maxAge := int(time.Until(expiresAt).Seconds())
+	if maxAge < 1 {
+		maxAge = 1
+	}
+	return maxAge
+}
+
+// setSessionCookie sets or, with a negative max age, clears the session cookie
+func setSessionCookie(w http.ResponseWriter, value string, maxAge int, secure bool) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookieName,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
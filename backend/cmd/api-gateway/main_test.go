@@ -326,6 +326,31 @@ func TestIntegrationWithRealDependencies(t *testing.T) {
 		checks := body["checks"].(map[string]interface{})
 		assert.Equal(t, "healthy", checks["database"])
 	})
+
+	t.Run("inference pipeline routes are mounted", func(t *testing.T) {
+		testCases := []struct {
+			method string
+			path   string
+		}{
+			{"POST", "/v1/chat/completions"},
+			{"GET", "/v1/models"},
+			{"POST", "/v1/messages"},
+			{"GET", "/api/v1/conversations"},
+			{"GET", "/api/v1/batches"},
+		}
+
+		for _, tc := range testCases {
+			req, err := http.NewRequest(tc.method, ts.URL+tc.path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			// Mounted routes reject the missing credentials instead of returning 404
+			assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, "endpoint: %s %s", tc.method, tc.path)
+		}
+	})
 }
 
 // Test helpers
@@ -0,0 +1,121 @@
+"""Assembles the training corpus for vocabgen.
+
+Usage: python3 build_corpus.py OUTPUT [REPO_ROOT]
+
+The corpus mixes English prose (vim docs, man pages, package docs),
+multilingual text (vim tutors, with Chinese, Japanese and Korean weighted
+twice, and gettext message catalogs), code (Go and Python standard library
+sources) and the repository's SQL, Markdown and JSON files. Sources are read
+from the local system, so the corpus depends on the image it is built on.
+
+The bundled vocabularies were trained on a 72,706,104 byte corpus built on
+Debian 12 (bookworm) with Go 1.24 and Python 3.11 from the repository as it
+was just before they were added, with SHA-256
+09c010866a7b2fa448c5f3164f07cedc5475d63b1e120c42ed20a13ac7308318.
+"""
+
+import glob
+import gettext
+import gzip
+import os
+import random
+import re
+import sys
+
+
+def main():
+    if len(sys.argv) < 2:
+        sys.exit(__doc__)
+    output = sys.argv[1]
+    repo = sys.argv[2] if len(sys.argv) > 2 else os.path.abspath(os.path.join(os.path.dirname(__file__), '..', '..', '..'))
+
+    random.seed(7)
+    out = []
+
+    def add(path, limit=None):
+        try:
+            data = open(path, encoding='utf-8').read()
+        except Exception:
+            return 0
+        if limit:
+            data = data[:limit]
+        out.append(data)
+        return len(data)
+
+    # English prose
+    for p in sorted(glob.glob('/usr/share/vim/vim90/doc/*.txt')):
+        add(p)
+
+    # Multilingual prose (UTF-8 tutors); CJK weighted twice
+    for p in sorted(glob.glob('/usr/share/vim/vim90/tutor/tutor*.utf-8')) + ['/usr/share/vim/vim90/tutor/tutor']:
+        n = 2 if any(k in p for k in ('.zh', '.ja', '.ko')) else 1
+        for _ in range(n):
+            add(p)
+
+    # Message catalogs
+    mo_total = 0
+    for p in sorted(glob.glob('/usr/share/vim/vim90/lang/*/LC_MESSAGES/*.mo') + glob.glob('/usr/share/locale/*/LC_MESSAGES/*.mo')):
+        try:
+            t = gettext.GNUTranslations(open(p, 'rb'))
+            msgs = [v for k, v in t._catalog.items() if isinstance(v, str) and k]
+            text = "\n".join(msgs)
+            if mo_total > 6_000_000:
+                break
+            mo_total += len(text)
+            out.append(text)
+        except Exception:
+            pass
+
+    # Man pages and package docs (English prose)
+    prose = 0
+    for p in sorted(glob.glob('/usr/share/man/man*/*.gz')):
+        try:
+            t = gzip.open(p, 'rt', encoding='utf-8', errors='ignore').read()
+        except Exception:
+            continue
+        t = re.sub(r'^\.[A-Za-z]+ ?', '', t, flags=re.M)
+        t = re.sub(r'\\f[BIRP]|\\-|\\&|\\\(..', '', t)
+        out.append(t)
+        prose += len(t)
+    docs = sorted(glob.glob('/usr/share/doc/**/*', recursive=True))
+    random.shuffle(docs)
+    for p in docs:
+        if prose > 40_000_000:
+            break
+        if 'changelog' in p.lower():
+            continue
+        if os.path.isfile(p) and not p.endswith('.gz') and os.path.getsize(p) < 2_000_000:
+            prose += add(p)
+        elif p.endswith('.gz') and os.path.isfile(p):
+            try:
+                t = gzip.open(p, 'rt', encoding='utf-8').read()
+                out.append(t)
+                prose += len(t)
+            except Exception:
+                pass
+
+    # Code: Go, Python, SQL, JSON
+    gofiles = [p for p in glob.glob('/usr/local/go/src/**/*.go', recursive=True) if '/testdata/' not in p and 'vendor' not in p]
+    random.shuffle(gofiles)
+    total = 0
+    for p in gofiles:
+        total += add(p)
+        if total > 8_000_000:
+            break
+    pyfiles = glob.glob('/usr/local/lib/python3.11/**/*.py', recursive=True) or glob.glob('/usr/lib/python3*/**/*.py', recursive=True)
+    random.shuffle(pyfiles)
+    total = 0
+    for p in pyfiles:
+        total += add(p)
+        if total > 3_000_000:
+            break
+    for p in glob.glob(os.path.join(repo, 'backend', '**', '*.sql'), recursive=True) + glob.glob(os.path.join(repo, '**', '*.md'), recursive=True) + glob.glob(os.path.join(repo, '**', '*.json'), recursive=True):
+        add(p)
+
+    s = "\n".join(out)
+    open(output, 'w', encoding='utf-8').write(s)
+    print(len(s.encode()), 'bytes written to', output)
+
+
+if __name__ == '__main__':
+    main()
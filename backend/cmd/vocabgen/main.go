@@ -0,0 +1,63 @@
+// Command vocabgen trains the approximate BPE vocabularies bundled with the
+// tokenizer package from a text corpus.
+//
+// The bundled files were generated from the corpus build_corpus.py assembles
+// out of the documentation, man pages and Go and Python sources of a Debian
+// development image:
+//
+//	python3 build_corpus.py corpus.txt
+//	go run ./cmd/vocabgen -corpus corpus.txt -size 16384 -out internal/tokenizer/vocab/cl100k_approx.tiktoken
+//	go run ./cmd/vocabgen -corpus corpus.txt -size 32768 -out internal/tokenizer/vocab/o200k_approx.tiktoken
+//
+// Training is deterministic, so the same corpus reproduces the same files.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/upb/llm-control-plane/backend/internal/tokenizer"
+)
+
+func main() {
+	corpus := flag.String("corpus", "", "UTF-8 text file to train on")
+	size := flag.Int("size", 16384, "vocabulary size, including the 256 single bytes")
+	out := flag.String("out", "", "tiktoken rank file to write")
+	flag.Parse()
+
+	if err := run(*corpus, *size, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "vocabgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(corpus string, size int, out string) error {
+	if corpus == "" || out == "" {
+		return fmt.Errorf("-corpus and -out are required")
+	}
+	if size < 256 {
+		return fmt.Errorf("-size must be at least 256")
+	}
+
+	text, err := os.ReadFile(corpus)
+	if err != nil {
+		return err
+	}
+	vocab := tokenizer.Train(string(text), size)
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := tokenizer.WriteRanks(f, vocab); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %d tokens to %s\n", len(vocab), out)
+	return nil
+}
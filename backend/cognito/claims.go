@@ -6,6 +6,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
 )
 
 var (
@@ -168,16 +169,15 @@ func ValidateCustomClaims(claims *Claims) error {
 		}
 	}
 
-	// Role (from custom:userRole) is optional but should be one of known values if present
+	// Role (from custom:userRole) is optional; organizations may define custom
+	// roles, so only its format is checked. The platform superadmin role is
+	// never taken from a user-pool attribute.
 	if claims.Role != "" {
-		validRoles := map[string]bool{
-			"admin":     true,
-			"developer": true,
-			"user":      true,
-			"viewer":    true,
+		if err := models.ValidateRoleName(claims.Role); err != nil {
+			return fmt.Errorf("invalid custom:userRole value: %w", err)
 		}
-		if !validRoles[claims.Role] {
-			return fmt.Errorf("invalid custom:userRole value: %s", claims.Role)
+		if claims.Role == string(models.RoleSuperAdmin) {
+			return fmt.Errorf("invalid custom:userRole value: %q is reserved", claims.Role)
 		}
 	}
 
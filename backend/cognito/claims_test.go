@@ -322,11 +322,20 @@ func TestValidateCustomClaims(t *testing.T) {
 			name: "invalid role",
 			claims: &Claims{
 				OrgID: uuid.New().String(),
-				Role:  "superadmin", // Not in valid roles list
+				Role:  "Super Admin!", // Not a role name
 			},
 			expectError: true,
 			errorMsg:    "userRole",
 		},
+		{
+			name: "reserved role - superadmin",
+			claims: &Claims{
+				OrgID: uuid.New().String(),
+				Role:  "superadmin",
+			},
+			expectError: true,
+			errorMsg:    "reserved",
+		},
 		{
 			name: "valid role - admin",
 			claims: &Claims{
@@ -351,6 +360,14 @@ func TestValidateCustomClaims(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "valid role - custom",
+			claims: &Claims{
+				OrgID: uuid.New().String(),
+				Role:  "auditor",
+			},
+			expectError: false,
+		},
 		{
 			name: "valid role - viewer",
 			claims: &Claims{
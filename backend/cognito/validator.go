@@ -186,6 +186,11 @@ func (v *CognitoValidator) ValidateToken(ctx context.Context, tokenString string
 		return nil, fmt.Errorf("invalid token_use: %s", claims.TokenUse)
 	}
 
+	// Verify the custom claims, including that the role is not reserved
+	if err := ValidateCustomClaims(claims); err != nil {
+		return nil, err
+	}
+
 	// Parse UUIDs
 	sub, err := uuid.Parse(claims.Sub)
 	if err != nil {
@@ -392,6 +392,38 @@ func TestValidateToken_MissingOrgID(t *testing.T) {
 	assert.Contains(t, err.Error(), "tenantId")
 }
 
+func TestValidateToken_SuperAdminRoleRefused(t *testing.T) {
+	privateKey, publicKey := generateTestKeyPair(t)
+	kid := "test-kid-123"
+	region := "us-east-1"
+	userPoolID := "us-east-1_test123"
+	clientID := "test-client-id"
+
+	server := createMockJWKSServer(t, publicKey, kid)
+	defer server.Close()
+
+	validator := &CognitoValidator{
+		region:       region,
+		userPoolID:   userPoolID,
+		clientID:     clientID,
+		jwksURL:      server.URL,
+		httpClient:   &http.Client{Timeout: 5 * time.Second},
+		jwksCacheTTL: 1 * time.Hour,
+		keyCache:     make(map[string]*rsa.PublicKey),
+	}
+
+	tokenString := createTestToken(t, privateKey, kid, region, userPoolID, clientID, map[string]string{
+		"org_id": uuid.New().String(),
+		"role":   "superadmin",
+	})
+
+	parsed, err := validator.ValidateToken(context.Background(), tokenString)
+
+	assert.Nil(t, parsed)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "userRole")
+}
+
 func TestInvalidateCache(t *testing.T) {
 	_, publicKey := generateTestKeyPair(t)
 	kid := "test-kid-123"
@@ -2,7 +2,10 @@ package config
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"strconv"
@@ -18,6 +21,9 @@ type Config struct {
 	Database       DatabaseConfig
 	AuditDatabase  *DatabaseConfig // Optional: separate DB for audit logs. When nil, audit uses main DB.
 	Cognito        CognitoConfig
+	OIDC           OIDCConfig
+	Tokens         TokensConfig
+	Sessions       SessionsConfig
 	Providers      ProvidersConfig
 	Observability  ObservabilityConfig
 	Environment    string
@@ -30,6 +36,7 @@ type ServerConfig struct {
 	ReadTimeout     time.Duration
 	WriteTimeout    time.Duration
 	ShutdownTimeout time.Duration
+	TrustedProxies  []string // CIDRs or IPs of proxies whose X-Forwarded-For is honoured
 	TLS             struct {
 		Enabled  bool
 		CertFile string
@@ -63,6 +70,76 @@ type CognitoConfig struct {
 	FrontEndURL  string // Post-login redirect target (loaded from FRONT_END_URL)
 }
 
+// OIDCConfig holds the generic OpenID Connect identity providers (Okta, Auth0,
+// Keycloak, ...) users can sign in with besides Cognito
+type OIDCConfig struct {
+	Providers []OIDCProviderConfig
+}
+
+// OIDCProviderConfig holds the configuration of one OpenID Connect issuer.
+// Its endpoints are discovered from the issuer's
+// .well-known/openid-configuration.
+type OIDCProviderConfig struct {
+	Name         string   `json:"name"`                // Used in the login URL: /auth/oidc/{name}/login
+	Issuer       string   `json:"issuer"`              // Must match the tokens' iss claim exactly
+	ClientID     string   `json:"client_id"`           // Also the expected audience
+	ClientSecret string   `json:"client_secret"`       // Optional for public clients
+	RedirectURI  string   `json:"redirect_uri"`        // OAuth2 callback URL: /auth/oidc/{name}/callback
+	Scopes       []string `json:"scopes,omitempty"`    // Defaults to openid email profile
+	Audiences    []string `json:"audiences,omitempty"` // Accepted audiences besides the client ID
+
+	// Tenant mapping: every user of the issuer belongs to OrgID when set;
+	// otherwise the organization is read from TenantClaim, translated
+	// through TenantMappings when any are given
+	OrgID          string            `json:"org_id,omitempty"`
+	TenantClaim    string            `json:"tenant_claim,omitempty"` // Defaults to org_id; dotted paths reach nested claims
+	TenantMappings map[string]string `json:"tenant_mappings,omitempty"`
+
+	// Role mapping: roles are read from RoleClaim and translated through
+	// RoleMappings when any are given, dropping unmapped values
+	RoleClaim    string            `json:"role_claim,omitempty"` // Defaults to groups
+	RoleMappings map[string]string `json:"role_mappings,omitempty"`
+
+	AppClaim   string `json:"app_claim,omitempty"`   // Optional application claim
+	EmailClaim string `json:"email_claim,omitempty"` // Defaults to email
+
+	// AllowSuperAdmin honours the platform superadmin role from this
+	// issuer; it is dropped otherwise, so customer IdPs cannot grant it
+	AllowSuperAdmin bool `json:"allow_superadmin,omitempty"`
+}
+
+// TokensConfig holds the configuration of the JWTs the gateway issues to
+// service accounts and publishes the keys of at /.well-known/jwks.json
+type TokensConfig struct {
+	Issuer         string        // iss and aud of the tokens
+	SigningKeyFile string        // PEM RSA private key; an ephemeral key is generated when unset
+	KeyID          string        // Optional kid; derived from the public key when unset
+	TTL            time.Duration // Lifetime of issued tokens
+}
+
+// SessionsConfig holds the configuration of server-side browser sessions
+type SessionsConfig struct {
+	// RefreshTokenKey is a base64-encoded 32-byte key the IdP refresh tokens
+	// of sessions are encrypted with at rest. When unset, refresh tokens are
+	// not kept and sessions end with their identity.
+	RefreshTokenKey string
+}
+
+// RefreshTokenKeyBytes decodes the refresh token key; it is nil when unset
+func (c *SessionsConfig) RefreshTokenKeyBytes() ([]byte, error) {
+	if c.RefreshTokenKey == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(c.RefreshTokenKey)
+	if err != nil {
+		return nil, fmt.Errorf("session refresh token key must be base64: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("session refresh token key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
 // ProvidersConfig holds LLM provider configurations
 type ProvidersConfig struct {
 	OpenAI    OpenAIConfig
@@ -72,10 +149,11 @@ type ProvidersConfig struct {
 
 // OpenAIConfig holds OpenAI provider configuration
 type OpenAIConfig struct {
-	APIKey     string
-	BaseURL    string
-	Timeout    time.Duration
-	MaxRetries int
+	APIKey         string
+	BaseURL        string
+	Timeout        time.Duration
+	MaxRetries     int
+	EmbeddingModel string // Model used by the gateway's embedder
 }
 
 // AnthropicConfig holds Anthropic provider configuration
@@ -112,6 +190,11 @@ func New(ctx context.Context) (*Config, error) {
 	_ = godotenv.Load("backend/.env")
 	_ = godotenv.Load(".env")
 
+	oidcConfig, err := loadOIDCConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &Config{
 		Environment: getEnv("ENVIRONMENT", "development"),
 		Server: ServerConfig{
@@ -120,6 +203,7 @@ func New(ctx context.Context) (*Config, error) {
 			ReadTimeout:     getEnvAsDuration("SERVER_READ_TIMEOUT", 30*time.Second),
 			WriteTimeout:    getEnvAsDuration("SERVER_WRITE_TIMEOUT", 30*time.Second),
 			ShutdownTimeout: getEnvAsDuration("SERVER_SHUTDOWN_TIMEOUT", 10*time.Second),
+			TrustedProxies:  getEnvAsList("TRUSTED_PROXIES"),
 			TLS: struct {
 				Enabled  bool
 				CertFile string
@@ -141,12 +225,23 @@ func New(ctx context.Context) (*Config, error) {
 			RedirectURI:  getEnv("COGNITO_REDIRECT_URI", "https://localhost:8443/oauth2/idpresponse"),
 			FrontEndURL:  getEnv("FRONT_END_URL", "http://localhost:5173"),
 		},
+		OIDC: oidcConfig,
+		Tokens: TokensConfig{
+			Issuer:         getEnv("GATEWAY_TOKEN_ISSUER", "llm-control-plane"),
+			SigningKeyFile: getEnv("GATEWAY_SIGNING_KEY_FILE", ""),
+			KeyID:          getEnv("GATEWAY_SIGNING_KEY_ID", ""),
+			TTL:            getEnvAsDuration("GATEWAY_TOKEN_TTL", 15*time.Minute),
+		},
+		Sessions: SessionsConfig{
+			RefreshTokenKey: getEnv("SESSION_REFRESH_TOKEN_KEY", ""),
+		},
 		Providers: ProvidersConfig{
 			OpenAI: OpenAIConfig{
 				APIKey:     getEnv("OPENAI_API_KEY", ""),
 				BaseURL:    getEnv("OPENAI_BASE_URL", "https://api.openai.com/v1"),
 				Timeout:    getEnvAsDuration("OPENAI_TIMEOUT", 60*time.Second),
 				MaxRetries: getEnvAsInt("OPENAI_MAX_RETRIES", 3),
+				EmbeddingModel: getEnv("OPENAI_EMBEDDING_MODEL", "text-embedding-3-small"),
 			},
 			Anthropic: AnthropicConfig{
 				APIKey:     getEnv("ANTHROPIC_API_KEY", ""),
@@ -196,6 +291,13 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Forwarded client addresses are only honoured from trusted proxies
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return fmt.Errorf("invalid trusted proxy %q: must be an IP or CIDR", proxy)
+		}
+	}
+
 	// Cognito validation (required in production)
 	if c.IsProduction() {
 		if c.Cognito.UserPoolID == "" {
@@ -206,6 +308,37 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// OIDC providers are addressed by name and matched to tokens by issuer
+	names := make(map[string]bool)
+	issuers := make(map[string]bool)
+	for _, p := range c.OIDC.Providers {
+		if p.Name == "" || p.Issuer == "" || p.ClientID == "" {
+			return fmt.Errorf("oidc providers require a name, issuer and client ID")
+		}
+		if names[p.Name] || issuers[p.Issuer] {
+			return fmt.Errorf("duplicate oidc provider %q", p.Name)
+		}
+		// Sessions of Cognito logins are kept under the provider name "cognito"
+		if p.Name == "cognito" {
+			return fmt.Errorf("oidc provider name %q is reserved", p.Name)
+		}
+		names[p.Name] = true
+		issuers[p.Issuer] = true
+	}
+
+	// Gateway-issued tokens are told apart from IdP tokens by issuer
+	if issuers[c.Tokens.Issuer] {
+		return fmt.Errorf("gateway token issuer %q is also an oidc provider's issuer", c.Tokens.Issuer)
+	}
+	if c.Tokens.TTL < 0 || c.Tokens.TTL > time.Hour {
+		return fmt.Errorf("gateway token TTL must be at most 1h")
+	}
+
+	// IdP refresh tokens are encrypted at rest with an AES-256 key
+	if _, err := c.Sessions.RefreshTokenKeyBytes(); err != nil {
+		return err
+	}
+
 	// Provider validation (at least one provider API key required in production)
 	if c.IsProduction() {
 		if c.Providers.OpenAI.APIKey == "" &&
@@ -287,6 +420,20 @@ func loadDatabaseConfig() DatabaseConfig {
 	}
 }
 
+// loadOIDCConfig loads the OIDC providers from OIDC_PROVIDERS, a JSON array
+// of provider configurations
+func loadOIDCConfig() (OIDCConfig, error) {
+	var cfg OIDCConfig
+	raw := getEnv("OIDC_PROVIDERS", "")
+	if raw == "" {
+		return cfg, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &cfg.Providers); err != nil {
+		return cfg, fmt.Errorf("invalid OIDC_PROVIDERS: %w", err)
+	}
+	return cfg, nil
+}
+
 // loadAuditDatabaseConfig loads audit DB config from DATABASE_URL_AUDIT.
 // Returns nil when not set (audit uses main DB).
 func loadAuditDatabaseConfig() *DatabaseConfig {
@@ -378,3 +525,14 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return value
 }
+
+// getEnvAsList reads a comma-separated list, dropping empty entries
+func getEnvAsList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
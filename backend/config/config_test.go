@@ -265,6 +265,82 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			errMsg:  "database user is required",
 		},
+		{
+			name: "duplicate oidc issuer",
+			config: &Config{
+				Environment: "development",
+				Database: DatabaseConfig{
+					Host:     "localhost",
+					User:     "user",
+					Database: "db",
+				},
+				OIDC: OIDCConfig{Providers: []OIDCProviderConfig{
+					{Name: "okta", Issuer: "https://acme.okta.com", ClientID: "a"},
+					{Name: "okta-2", Issuer: "https://acme.okta.com", ClientID: "b"},
+				}},
+				Observability: ObservabilityConfig{
+					LogLevel: "info",
+				},
+			},
+			wantErr: true,
+			errMsg:  "duplicate oidc provider",
+		},
+		{
+			name: "reserved oidc provider name",
+			config: &Config{
+				Environment: "development",
+				Database: DatabaseConfig{
+					Host:     "localhost",
+					User:     "user",
+					Database: "db",
+				},
+				OIDC: OIDCConfig{Providers: []OIDCProviderConfig{
+					{Name: "cognito", Issuer: "https://acme.okta.com", ClientID: "a"},
+				}},
+				Observability: ObservabilityConfig{
+					LogLevel: "info",
+				},
+			},
+			wantErr: true,
+			errMsg:  "is reserved",
+		},
+		{
+			name: "gateway token issuer of an oidc provider",
+			config: &Config{
+				Environment: "development",
+				Database: DatabaseConfig{
+					Host:     "localhost",
+					User:     "user",
+					Database: "db",
+				},
+				OIDC: OIDCConfig{Providers: []OIDCProviderConfig{
+					{Name: "okta", Issuer: "https://acme.okta.com", ClientID: "a"},
+				}},
+				Tokens: TokensConfig{Issuer: "https://acme.okta.com", TTL: 15 * time.Minute},
+				Observability: ObservabilityConfig{
+					LogLevel: "info",
+				},
+			},
+			wantErr: true,
+			errMsg:  "gateway token issuer",
+		},
+		{
+			name: "session refresh token key of the wrong size",
+			config: &Config{
+				Environment: "development",
+				Database: DatabaseConfig{
+					Host:     "localhost",
+					User:     "user",
+					Database: "db",
+				},
+				Sessions: SessionsConfig{RefreshTokenKey: "c2hvcnQ="},
+				Observability: ObservabilityConfig{
+					LogLevel: "info",
+				},
+			},
+			wantErr: true,
+			errMsg:  "must be 32 bytes",
+		},
 	}
 
 	for _, tt := range tests {
@@ -447,3 +523,20 @@ func TestGetEnvAsDuration(t *testing.T) {
 		})
 	}
 }
+
+func TestLoadOIDCConfig(t *testing.T) {
+	defer os.Unsetenv("OIDC_PROVIDERS")
+
+	os.Setenv("OIDC_PROVIDERS", `[{"name":"keycloak","issuer":"https://sso.acme.test/realms/acme","client_id":"gateway","role_claim":"realm_access.roles","role_mappings":{"gateway-admin":"admin"}}]`)
+	cfg, err := loadOIDCConfig()
+	assert.NoError(t, err)
+	if assert.Len(t, cfg.Providers, 1) {
+		assert.Equal(t, "keycloak", cfg.Providers[0].Name)
+		assert.Equal(t, "realm_access.roles", cfg.Providers[0].RoleClaim)
+		assert.Equal(t, "admin", cfg.Providers[0].RoleMappings["gateway-admin"])
+	}
+
+	os.Setenv("OIDC_PROVIDERS", `{"name":"okta"}`)
+	_, err = loadOIDCConfig()
+	assert.Error(t, err)
+}
@@ -0,0 +1,404 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+// Anthropic error types
+const (
+	anthropicInvalidRequest = "invalid_request_error"
+	anthropicAuthentication = "authentication_error"
+	anthropicBilling        = "billing_error"
+	anthropicPermission     = "permission_error"
+	anthropicNotFound       = "not_found_error"
+	anthropicRateLimit      = "rate_limit_error"
+	anthropicAPIError       = "api_error"
+)
+
+// AnthropicContent is message or system content, sent by clients either as a
+// string or as an array of content blocks. Only text blocks are supported.
+type AnthropicContent string
+
+// UnmarshalJSON accepts a string or an array of text blocks
+func (c *AnthropicContent) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = AnthropicContent(text)
+		return nil
+	}
+
+	var blocks []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return fmt.Errorf("content must be a string or an array of content blocks")
+	}
+
+	texts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Type != "text" {
+			return fmt.Errorf("content blocks of type %q are not supported", block.Type)
+		}
+		texts = append(texts, block.Text)
+	}
+	*c = AnthropicContent(strings.Join(texts, "\n"))
+	return nil
+}
+
+// AnthropicMessage is a message of an Anthropic Messages API request
+type AnthropicMessage struct {
+	Role    string           `json:"role"`
+	Content AnthropicContent `json:"content"`
+}
+
+// AnthropicMessagesRequest is the request body of POST /v1/messages
+type AnthropicMessagesRequest struct {
+	Model         string             `json:"model"`
+	Messages      []AnthropicMessage `json:"messages"`
+	System        AnthropicContent   `json:"system,omitempty"`
+	MaxTokens     int                `json:"max_tokens"`
+	Temperature   *float64           `json:"temperature,omitempty"`
+	TopP          *float64           `json:"top_p,omitempty"`
+	TopK          *int               `json:"top_k,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+	Stream        bool               `json:"stream,omitempty"`
+	Metadata      *struct {
+		UserID string `json:"user_id,omitempty"`
+	} `json:"metadata,omitempty"`
+	Tools      json.RawMessage `json:"tools,omitempty"`
+	ToolChoice json.RawMessage `json:"tool_choice,omitempty"`
+}
+
+// AnthropicContentBlock is a content block of a message
+type AnthropicContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// AnthropicUsage reports token usage
+type AnthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// AnthropicMessageResponse is the response of POST /v1/messages, and the
+// message of the message_start stream event
+type AnthropicMessageResponse struct {
+	ID           string                  `json:"id"`
+	Type         string                  `json:"type"`
+	Role         string                  `json:"role"`
+	Content      []AnthropicContentBlock `json:"content"`
+	Model        string                  `json:"model"`
+	StopReason   *string                 `json:"stop_reason"`
+	StopSequence *string                 `json:"stop_sequence"`
+	Usage        AnthropicUsage          `json:"usage"`
+}
+
+// AnthropicStreamEvent is a server-sent event of a streamed message
+type AnthropicStreamEvent struct {
+	Type         string                    `json:"type"`
+	Message      *AnthropicMessageResponse `json:"message,omitempty"`
+	Index        *int                      `json:"index,omitempty"`
+	ContentBlock *AnthropicContentBlock    `json:"content_block,omitempty"`
+	Delta        interface{}               `json:"delta,omitempty"`
+	Usage        *AnthropicUsage           `json:"usage,omitempty"`
+}
+
+// AnthropicErrorResponse is the error body of the Anthropic API
+type AnthropicErrorResponse struct {
+	Type  string         `json:"type"`
+	Error AnthropicError `json:"error"`
+}
+
+// AnthropicError describes an error in the Anthropic API's shape
+type AnthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// AnthropicCompleter runs chat completions through the inference pipeline
+type AnthropicCompleter interface {
+	ProcessChatCompletion(ctx context.Context, req *inference.CompletionRequest) (*inference.CompletionResponse, error)
+}
+
+// AnthropicHandler serves a Messages API that is wire-compatible with the
+// official Anthropic SDKs. Requests are converted into the unified chat
+// request, so they are governed like any other and may be routed to any
+// provider. Requests authenticate with the application's API key.
+type AnthropicHandler struct {
+	completer AnthropicCompleter
+	logger    *zap.Logger
+}
+
+// NewAnthropicHandler creates a new AnthropicHandler
+func NewAnthropicHandler(completer AnthropicCompleter, logger *zap.Logger) *AnthropicHandler {
+	return &AnthropicHandler{
+		completer: completer,
+		logger:    logger,
+	}
+}
+
+// HandleMessages handles POST /v1/messages
+// Streamed responses are produced by the pipeline in full, so that output
+// policies apply to them, and then sent as Messages API stream events.
+func (h *AnthropicHandler) HandleMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var msgReq AnthropicMessagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&msgReq); err != nil {
+		writeAnthropicError(w, http.StatusBadRequest, anthropicInvalidRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	chatReq, err := anthropicChatRequest(&msgReq)
+	if err != nil {
+		writeAnthropicError(w, http.StatusBadRequest, anthropicInvalidRequest, err.Error())
+		return
+	}
+
+	req := &inference.CompletionRequest{
+		OrgID:       middleware.GetOrgIDFromContext(ctx),
+		AppID:       middleware.GetAppIDFromContext(ctx),
+		APIKey:      middleware.GetAPIKeyFromContext(ctx),
+		Model:       chatReq.Model,
+		Messages:    chatReq.Messages,
+		MaxTokens:   chatReq.MaxTokens,
+		Temperature: chatReq.Temperature,
+		TopP:        chatReq.TopP,
+		Stop:        chatReq.Stop,
+		RequestID:   middleware.GetRequestIDFromContext(ctx),
+		Metadata:    chatReq.Metadata,
+		IPAddress:   getClientIP(r),
+		UserAgent:   r.UserAgent(),
+	}
+
+	resp, err := h.completer.ProcessChatCompletion(ctx, req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("X-Request-ID", resp.RequestID)
+	message := anthropicMessage(resp)
+	if msgReq.Stream {
+		h.streamMessage(w, message)
+		return
+	}
+	writeUnwrappedJSON(w, http.StatusOK, message)
+}
+
+// WriteAnthropicUnauthorized rejects a request without a valid API key in the
+// Anthropic API's error shape
+func WriteAnthropicUnauthorized(w http.ResponseWriter, message string) {
+	writeAnthropicError(w, http.StatusUnauthorized, anthropicAuthentication, message)
+}
+
+// anthropicChatRequest converts a Messages API request into a unified chat
+// request. The system prompt becomes a leading system message.
+func anthropicChatRequest(msgReq *AnthropicMessagesRequest) (*providers.ChatRequest, error) {
+	switch {
+	case msgReq.Model == "":
+		return nil, fmt.Errorf("model: field required")
+	case msgReq.MaxTokens <= 0:
+		return nil, fmt.Errorf("max_tokens: must be greater than 0")
+	case len(msgReq.Messages) == 0:
+		return nil, fmt.Errorf("messages: at least one message is required")
+	case len(msgReq.Tools) > 0 || len(msgReq.ToolChoice) > 0:
+		return nil, fmt.Errorf("tools: tool use is not supported")
+	case msgReq.TopK != nil:
+		return nil, fmt.Errorf("top_k: not supported")
+	}
+
+	messages := make([]providers.Message, 0, len(msgReq.Messages)+1)
+	if msgReq.System != "" {
+		messages = append(messages, providers.Message{Role: "system", Content: string(msgReq.System)})
+	}
+	for i, msg := range msgReq.Messages {
+		if msg.Role != "user" && msg.Role != "assistant" {
+			return nil, fmt.Errorf("messages.%d.role: unsupported role %q", i, msg.Role)
+		}
+		messages = append(messages, providers.Message{Role: msg.Role, Content: string(msg.Content)})
+	}
+
+	chatReq := &providers.ChatRequest{
+		Model:     msgReq.Model,
+		Messages:  messages,
+		MaxTokens: msgReq.MaxTokens,
+		Stop:      msgReq.StopSequences,
+	}
+	if msgReq.Temperature != nil {
+		chatReq.Temperature = *msgReq.Temperature
+	}
+	if msgReq.TopP != nil {
+		chatReq.TopP = *msgReq.TopP
+	}
+	if msgReq.Metadata != nil && msgReq.Metadata.UserID != "" {
+		chatReq.Metadata = map[string]string{"user": msgReq.Metadata.UserID}
+	}
+	return chatReq, nil
+}
+
+// streamMessage sends a completed message as Messages API stream events
+func (h *AnthropicHandler) streamMessage(w http.ResponseWriter, message AnthropicMessageResponse) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	start := message
+	start.Content = []AnthropicContentBlock{}
+	start.StopReason = nil
+	start.Usage = AnthropicUsage{InputTokens: message.Usage.InputTokens}
+	events := []AnthropicStreamEvent{{Type: "message_start", Message: &start}}
+
+	for i, block := range message.Content {
+		index := i
+		events = append(events,
+			AnthropicStreamEvent{Type: "content_block_start", Index: &index, ContentBlock: &AnthropicContentBlock{Type: "text"}},
+			AnthropicStreamEvent{Type: "content_block_delta", Index: &index, Delta: map[string]string{"type": "text_delta", "text": block.Text}},
+			AnthropicStreamEvent{Type: "content_block_stop", Index: &index})
+	}
+
+	events = append(events,
+		AnthropicStreamEvent{
+			Type:  "message_delta",
+			Delta: map[string]*string{"stop_reason": message.StopReason, "stop_sequence": nil},
+			Usage: &AnthropicUsage{OutputTokens: message.Usage.OutputTokens},
+		},
+		AnthropicStreamEvent{Type: "message_stop"})
+
+	controller := http.NewResponseController(w)
+	for _, event := range events {
+		if err := writeNamedEvent(w, event.Type, event); err != nil {
+			h.logger.Warn("failed to stream message", zap.Error(err))
+			return
+		}
+		_ = controller.Flush()
+	}
+}
+
+// handleError writes a pipeline or service error in the Anthropic API's shape
+func (h *AnthropicHandler) handleError(w http.ResponseWriter, err error) {
+	var inferenceErr *inference.InferenceError
+	if errors.As(err, &inferenceErr) {
+		status := inferenceErr.StatusCode
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		if status >= http.StatusInternalServerError {
+			h.logger.Error("inference failed", zap.String("code", inferenceErr.Code), zap.Error(err))
+		}
+		writeAnthropicError(w, status, anthropicErrorType(status), inferenceErr.Message)
+		return
+	}
+
+	status := http.StatusInternalServerError
+	message := "Internal server error"
+	switch {
+	case services.IsValidationError(err):
+		status, message = http.StatusBadRequest, err.Error()
+	case services.IsNotFoundError(err):
+		status, message = http.StatusNotFound, err.Error()
+	case services.IsRateLimitError(err):
+		status, message = http.StatusTooManyRequests, err.Error()
+	case services.IsBudgetError(err):
+		status, message = http.StatusPaymentRequired, err.Error()
+	case services.IsForbiddenError(err), services.IsPolicyViolationError(err):
+		status, message = http.StatusForbidden, err.Error()
+	default:
+		h.logger.Error("request failed", zap.Error(err))
+	}
+	writeAnthropicError(w, status, anthropicErrorType(status), message)
+}
+
+// anthropicMessage converts a pipeline response into a Messages API message
+func anthropicMessage(resp *inference.CompletionResponse) AnthropicMessageResponse {
+	message := AnthropicMessageResponse{
+		ID:      "msg_" + strings.ReplaceAll(resp.ID.String(), "-", ""),
+		Type:    "message",
+		Role:    "assistant",
+		Content: []AnthropicContentBlock{},
+		Model:   resp.Model,
+		Usage: AnthropicUsage{
+			InputTokens:  resp.Usage.PromptTokens,
+			OutputTokens: resp.Usage.CompletionTokens,
+		},
+	}
+	if len(resp.Choices) > 0 {
+		choice := resp.Choices[0]
+		message.Content = append(message.Content, AnthropicContentBlock{Type: "text", Text: choice.Message.Content})
+		message.StopReason = stopReason(choice.FinishReason)
+	} else {
+		message.StopReason = stopReason("")
+	}
+	return message
+}
+
+// stopReason maps a unified finish reason to an Anthropic stop reason
+func stopReason(finishReason string) *string {
+	reason := "end_turn"
+	switch finishReason {
+	case "length":
+		reason = "max_tokens"
+	case "content_filter":
+		reason = "refusal"
+	case "tool_calls":
+		reason = "tool_use"
+	}
+	return &reason
+}
+
+// anthropicErrorType maps a status code to an Anthropic error type
+func anthropicErrorType(status int) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return anthropicAuthentication
+	case status == http.StatusPaymentRequired:
+		return anthropicBilling
+	case status == http.StatusForbidden:
+		return anthropicPermission
+	case status == http.StatusNotFound:
+		return anthropicNotFound
+	case status == http.StatusTooManyRequests:
+		return anthropicRateLimit
+	case status >= http.StatusInternalServerError:
+		return anthropicAPIError
+	default:
+		return anthropicInvalidRequest
+	}
+}
+
+// writeNamedEvent writes a server-sent event with an event name, carrying
+// data as JSON
+func writeNamedEvent(w http.ResponseWriter, name string, data interface{}) error {
+	var buf bytes.Buffer
+	buf.WriteString("event: " + name + "\n")
+	buf.WriteString("data: ")
+	if err := json.NewEncoder(&buf).Encode(data); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeAnthropicError writes an error in the Anthropic API's shape
+func writeAnthropicError(w http.ResponseWriter, status int, errType, message string) {
+	writeUnwrappedJSON(w, status, AnthropicErrorResponse{
+		Type:  "error",
+		Error: AnthropicError{Type: errType, Message: message},
+	})
+}
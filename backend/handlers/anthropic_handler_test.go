@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"go.uber.org/zap"
+)
+
+func TestAnthropicHandler_Messages(t *testing.T) {
+	orgID, appID := uuid.New(), uuid.New()
+
+	t.Run("message", func(t *testing.T) {
+		completer := new(MockCompleter)
+		handler := NewAnthropicHandler(completer, zap.NewNop())
+		completer.On("ProcessChatCompletion", mock.Anything, mock.MatchedBy(func(r *inference.CompletionRequest) bool {
+			return r.OrgID == orgID && r.AppID == appID && r.Model == "gpt-4o" &&
+				r.MaxTokens == 64 && r.Temperature == 0.3 &&
+				len(r.Messages) == 3 &&
+				r.Messages[0].Role == "system" && r.Messages[0].Content == "Be brief\nBe kind" &&
+				r.Messages[1].Role == "user" && r.Messages[1].Content == "Hello" &&
+				r.Messages[2].Role == "assistant" &&
+				len(r.Stop) == 1 && r.Stop[0] == "END" &&
+				r.Metadata["user"] == "user-1"
+		})).Return(completionResponse("Hi!"), nil)
+
+		body := `{
+			"model": "gpt-4o",
+			"max_tokens": 64,
+			"system": [{"type": "text", "text": "Be brief"}, {"type": "text", "text": "Be kind"}],
+			"messages": [
+				{"role": "user", "content": [{"type": "text", "text": "Hello"}]},
+				{"role": "assistant", "content": "Hi"}
+			],
+			"temperature": 0.3,
+			"stop_sequences": ["END"],
+			"metadata": {"user_id": "user-1"}
+		}`
+		req := withTenant(httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body)), orgID, appID)
+		w := httptest.NewRecorder()
+
+		handler.HandleMessages(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var message AnthropicMessageResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &message))
+		assert.True(t, strings.HasPrefix(message.ID, "msg_"))
+		assert.Equal(t, "message", message.Type)
+		assert.Equal(t, "assistant", message.Role)
+		assert.Equal(t, "gpt-4o", message.Model)
+		require.Len(t, message.Content, 1)
+		assert.Equal(t, AnthropicContentBlock{Type: "text", Text: "Hi!"}, message.Content[0])
+		require.NotNil(t, message.StopReason)
+		assert.Equal(t, "end_turn", *message.StopReason)
+		assert.Equal(t, AnthropicUsage{InputTokens: 5, OutputTokens: 2}, message.Usage)
+		assert.Equal(t, "req-1", w.Header().Get("X-Request-ID"))
+		completer.AssertExpectations(t)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		completer := new(MockCompleter)
+		handler := NewAnthropicHandler(completer, zap.NewNop())
+		resp := completionResponse("Hi!")
+		resp.Choices[0].FinishReason = "length"
+		completer.On("ProcessChatCompletion", mock.Anything, mock.Anything).Return(resp, nil)
+
+		body := `{"model": "gpt-4o", "max_tokens": 2, "stream": true, "messages": [{"role": "user", "content": "Hello"}]}`
+		req := withTenant(httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body)), orgID, appID)
+		w := httptest.NewRecorder()
+
+		handler.HandleMessages(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+		var names []string
+		var events []map[string]interface{}
+		scanner := bufio.NewScanner(bytes.NewReader(w.Body.Bytes()))
+		for scanner.Scan() {
+			line := scanner.Text()
+			if name, ok := strings.CutPrefix(line, "event: "); ok {
+				names = append(names, name)
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				var event map[string]interface{}
+				require.NoError(t, json.Unmarshal([]byte(data), &event))
+				events = append(events, event)
+			}
+		}
+
+		assert.Equal(t, []string{
+			"message_start", "content_block_start", "content_block_delta",
+			"content_block_stop", "message_delta", "message_stop",
+		}, names)
+		require.Len(t, events, len(names))
+		for i, event := range events {
+			assert.Equal(t, names[i], event["type"])
+		}
+
+		start := events[0]["message"].(map[string]interface{})
+		assert.Nil(t, start["stop_reason"])
+		assert.Empty(t, start["content"])
+		assert.Equal(t, map[string]interface{}{"type": "text_delta", "text": "Hi!"}, events[2]["delta"])
+		assert.Equal(t, "max_tokens", events[4]["delta"].(map[string]interface{})["stop_reason"])
+		assert.Equal(t, float64(2), events[4]["usage"].(map[string]interface{})["output_tokens"])
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		tests := map[string]string{
+			"missing max_tokens": `{"model": "gpt-4o", "messages": [{"role": "user", "content": "Hi"}]}`,
+			"missing model":      `{"max_tokens": 10, "messages": [{"role": "user", "content": "Hi"}]}`,
+			"image block":        `{"model": "gpt-4o", "max_tokens": 10, "messages": [{"role": "user", "content": [{"type": "image"}]}]}`,
+			"system role":        `{"model": "gpt-4o", "max_tokens": 10, "messages": [{"role": "system", "content": "Hi"}]}`,
+			"tools":              `{"model": "gpt-4o", "max_tokens": 10, "messages": [{"role": "user", "content": "Hi"}], "tools": [{}]}`,
+		}
+		for name, body := range tests {
+			t.Run(name, func(t *testing.T) {
+				completer := new(MockCompleter)
+				handler := NewAnthropicHandler(completer, zap.NewNop())
+				req := withTenant(httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body)), orgID, appID)
+				w := httptest.NewRecorder()
+
+				handler.HandleMessages(w, req)
+
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+				var errResp AnthropicErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+				assert.Equal(t, "error", errResp.Type)
+				assert.Equal(t, "invalid_request_error", errResp.Error.Type)
+				completer.AssertNotCalled(t, "ProcessChatCompletion", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("pipeline errors", func(t *testing.T) {
+		tests := []struct {
+			err     error
+			status  int
+			errType string
+		}{
+			{inference.NewRateLimitError("Rate limit exceeded", nil), http.StatusTooManyRequests, "rate_limit_error"},
+			{inference.NewBudgetError("Budget exceeded", nil), http.StatusPaymentRequired, "billing_error"},
+			{inference.NewProviderError("Provider failed", nil, true), http.StatusBadGateway, "api_error"},
+		}
+		for _, tt := range tests {
+			completer := new(MockCompleter)
+			handler := NewAnthropicHandler(completer, zap.NewNop())
+			completer.On("ProcessChatCompletion", mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			body := `{"model": "gpt-4o", "max_tokens": 10, "messages": [{"role": "user", "content": "Hi"}]}`
+			req := withTenant(httptest.NewRequest(http.MethodPost, "/v1/messages", strings.NewReader(body)), orgID, appID)
+			w := httptest.NewRecorder()
+
+			handler.HandleMessages(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			var errResp AnthropicErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+			assert.Equal(t, tt.errType, errResp.Error.Type)
+		}
+	})
+}
+
+func TestWriteAnthropicUnauthorized(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	WriteAnthropicUnauthorized(w, "Invalid API key")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"type": "error", "error": {"type": "authentication_error", "message": "Invalid API key"}}`, w.Body.String())
+}
@@ -0,0 +1,307 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/apikey"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// IssueAPIKeyRequest represents a request to issue an application API key
+type IssueAPIKeyRequest struct {
+	Name      string               `json:"name" validate:"required,max=255"`
+	Scopes    *models.APIKeyScopes `json:"scopes,omitempty"`
+	ExpiresAt *time.Time           `json:"expires_at,omitempty"`
+}
+
+// defaultUsageDays is the usage window returned when none is requested
+const defaultUsageDays = 30
+
+// RotateAPIKeyRequest represents a request to rotate an application API key.
+// The old key stays valid for the grace period, 24 hours by default.
+type RotateAPIKeyRequest struct {
+	GracePeriodSeconds *int `json:"grace_period_seconds,omitempty" validate:"omitempty,min=0"`
+}
+
+// APIKeyService defines the interface for application API key operations
+type APIKeyService interface {
+	// Issue creates a new key; the plaintext is only returned here and by Rotate
+	Issue(ctx context.Context, req apikey.IssueRequest) (*apikey.IssuedKey, error)
+
+	// List lists an application's keys, identified by their prefix
+	List(ctx context.Context, orgID, appID uuid.UUID) ([]*models.APIKey, error)
+
+	// Rotate issues a replacement for a key, which stays valid for the grace period
+	Rotate(ctx context.Context, orgID, appID, keyID uuid.UUID, grace time.Duration, rotatedBy *uuid.UUID) (*apikey.IssuedKey, error)
+
+	// UpdateScopes replaces a key's allowlists and limit overrides
+	UpdateScopes(ctx context.Context, orgID, appID, keyID uuid.UUID, scopes *models.APIKeyScopes, updatedBy *uuid.UUID) (*models.APIKey, error)
+
+	// Revoke invalidates a key immediately
+	Revoke(ctx context.Context, orgID, appID, keyID uuid.UUID, revokedBy *uuid.UUID) error
+
+	// Usage returns a key's daily usage over the last days
+	Usage(ctx context.Context, orgID, appID, keyID uuid.UUID, days int) ([]*models.APIKeyUsage, error)
+}
+
+// Authorizer checks a permission of the authenticated user
+type Authorizer interface {
+	// Authorize reports whether the user's roles grant permission
+	Authorize(r *http.Request, permission models.Permission) (bool, error)
+}
+
+// APIKeyHandler handles application API key HTTP requests
+type APIKeyHandler struct {
+	service    APIKeyService
+	authorizer Authorizer
+	logger     *zap.Logger
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler
+func NewAPIKeyHandler(service APIKeyService, logger *zap.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// SetAuthorizer requires the budgets:override permission to give keys rate
+// limit or budget overrides
+func (h *APIKeyHandler) SetAuthorizer(authorizer Authorizer) {
+	h.authorizer = authorizer
+}
+
+// HandleIssueKey handles POST /v1/applications/{id}/keys
+// The plaintext key is only returned by this call.
+func (h *APIKeyHandler) HandleIssueKey(w http.ResponseWriter, r *http.Request) {
+	orgID, appID, ok := h.parseAppID(w, r)
+	if !ok {
+		return
+	}
+
+	var req IssueAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	if !h.mayOverrideLimits(w, r, req.Scopes) {
+		return
+	}
+
+	issued, err := h.service.Issue(r.Context(), apikey.IssueRequest{
+		OrgID:     orgID,
+		AppID:     appID,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedBy: middleware.GetActorIDFromContext(r.Context()),
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteCreated(w, issued)
+}
+
+// HandleListKeys handles GET /v1/applications/{id}/keys
+func (h *APIKeyHandler) HandleListKeys(w http.ResponseWriter, r *http.Request) {
+	orgID, appID, ok := h.parseAppID(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := h.service.List(r.Context(), orgID, appID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	if keys == nil {
+		keys = []*models.APIKey{}
+	}
+	_ = utils.WriteOK(w, keys)
+}
+
+// HandleRotateKey handles POST /v1/applications/{id}/keys/{keyID}/rotate
+// The replacement's plaintext key is only returned by this call.
+func (h *APIKeyHandler) HandleRotateKey(w http.ResponseWriter, r *http.Request) {
+	orgID, appID, ok := h.parseAppID(w, r)
+	if !ok {
+		return
+	}
+	keyID, ok := h.parseKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	var req RotateAPIKeyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+			return
+		}
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	grace := apikey.DefaultGracePeriod
+	if req.GracePeriodSeconds != nil {
+		grace = time.Duration(*req.GracePeriodSeconds) * time.Second
+	}
+
+	issued, err := h.service.Rotate(r.Context(), orgID, appID, keyID, grace, middleware.GetActorIDFromContext(r.Context()))
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteCreated(w, issued)
+}
+
+// HandleRevokeKey handles DELETE /v1/applications/{id}/keys/{keyID}
+func (h *APIKeyHandler) HandleRevokeKey(w http.ResponseWriter, r *http.Request) {
+	orgID, appID, ok := h.parseAppID(w, r)
+	if !ok {
+		return
+	}
+	keyID, ok := h.parseKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Revoke(r.Context(), orgID, appID, keyID, middleware.GetActorIDFromContext(r.Context())); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// HandleUpdateScopes handles PUT /v1/applications/{id}/keys/{keyID}/scopes
+func (h *APIKeyHandler) HandleUpdateScopes(w http.ResponseWriter, r *http.Request) {
+	orgID, appID, ok := h.parseAppID(w, r)
+	if !ok {
+		return
+	}
+	keyID, ok := h.parseKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	var scopes models.APIKeyScopes
+	if err := json.NewDecoder(r.Body).Decode(&scopes); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if !h.mayOverrideLimits(w, r, &scopes) {
+		return
+	}
+
+	key, err := h.service.UpdateScopes(r.Context(), orgID, appID, keyID, &scopes, middleware.GetActorIDFromContext(r.Context()))
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, key)
+}
+
+// HandleKeyUsage handles GET /v1/applications/{id}/keys/{keyID}/usage
+// Usage covers the last 30 days unless ?days= is given.
+func (h *APIKeyHandler) HandleKeyUsage(w http.ResponseWriter, r *http.Request) {
+	orgID, appID, ok := h.parseAppID(w, r)
+	if !ok {
+		return
+	}
+	keyID, ok := h.parseKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	days := defaultUsageDays
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			_ = utils.WriteBadRequest(w, "Invalid days", nil)
+			return
+		}
+		days = parsed
+	}
+
+	usage, err := h.service.Usage(r.Context(), orgID, appID, keyID, days)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	if usage == nil {
+		usage = []*models.APIKeyUsage{}
+	}
+	_ = utils.WriteOK(w, usage)
+}
+
+// mayOverrideLimits checks that the user may give a key limit overrides
+func (h *APIKeyHandler) mayOverrideLimits(w http.ResponseWriter, r *http.Request, scopes *models.APIKeyScopes) bool {
+	if h.authorizer == nil || scopes == nil || (scopes.RateLimit == nil && scopes.Budget == nil) {
+		return true
+	}
+
+	allowed, err := h.authorizer.Authorize(r, models.PermissionBudgetsOverride)
+	if err != nil {
+		h.logger.Error("permission check failed", zap.Error(err))
+		_ = utils.WriteInternalServerError(w, "Failed to check permissions")
+		return false
+	}
+	if !allowed {
+		_ = utils.WriteForbidden(w, "Overriding rate limits or budgets requires the budgets:override permission")
+		return false
+	}
+	return true
+}
+
+// parseAppID extracts the organization and the application ID
+func (h *APIKeyHandler) parseAppID(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	orgID := middleware.GetOrgIDFromContext(r.Context())
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing tenant information")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	appID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid application ID format", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return orgID, appID, true
+}
+
+// parseKeyID extracts the key ID
+func (h *APIKeyHandler) parseKeyID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	keyID, err := uuid.Parse(chi.URLParam(r, "keyID"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid API key ID format", nil)
+		return uuid.Nil, false
+	}
+	return keyID, true
+}
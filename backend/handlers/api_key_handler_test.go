@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/apikey"
+	"go.uber.org/zap"
+)
+
+// MockAPIKeyService is a mock implementation of APIKeyService
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) Issue(ctx context.Context, req apikey.IssueRequest) (*apikey.IssuedKey, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.IssuedKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) List(ctx context.Context, orgID, appID uuid.UUID) ([]*models.APIKey, error) {
+	args := m.Called(ctx, orgID, appID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) Rotate(ctx context.Context, orgID, appID, keyID uuid.UUID, grace time.Duration, rotatedBy *uuid.UUID) (*apikey.IssuedKey, error) {
+	args := m.Called(ctx, orgID, appID, keyID, grace, rotatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*apikey.IssuedKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) UpdateScopes(ctx context.Context, orgID, appID, keyID uuid.UUID, scopes *models.APIKeyScopes, updatedBy *uuid.UUID) (*models.APIKey, error) {
+	args := m.Called(ctx, orgID, appID, keyID, scopes, updatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) Revoke(ctx context.Context, orgID, appID, keyID uuid.UUID, revokedBy *uuid.UUID) error {
+	args := m.Called(ctx, orgID, appID, keyID, revokedBy)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) Usage(ctx context.Context, orgID, appID, keyID uuid.UUID, days int) ([]*models.APIKeyUsage, error) {
+	args := m.Called(ctx, orgID, appID, keyID, days)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.APIKeyUsage), args.Error(1)
+}
+
+// stubAuthorizer grants the permissions set to true
+type stubAuthorizer map[models.Permission]bool
+
+func (a stubAuthorizer) Authorize(r *http.Request, permission models.Permission) (bool, error) {
+	return a[permission], nil
+}
+
+func TestHandleIssueKey(t *testing.T) {
+	orgID, appID := uuid.New(), uuid.New()
+
+	t.Run("returns plaintext key once", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		handler := NewAPIKeyHandler(mockService, zap.NewNop())
+		key := models.NewAPIKey(orgID, appID, "server", "lcp_0123abcd", "secret-hash", nil)
+		mockService.On("Issue", mock.Anything, mock.MatchedBy(func(req apikey.IssueRequest) bool {
+			return req.OrgID == orgID && req.AppID == appID && req.Name == "server"
+		})).Return(&apikey.IssuedKey{APIKey: key, Key: "lcp_0123abcdef"}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/applications/x/keys", bytes.NewReader([]byte(`{"name":"server"}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleIssueKey(w, withURLParam(withTenant(req, orgID, uuid.Nil), "id", appID.String()))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var resp struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "lcp_0123abcdef", resp.Data["key"])
+		assert.Equal(t, "lcp_0123abcd", resp.Data["prefix"])
+		assert.NotContains(t, w.Body.String(), "secret-hash")
+	})
+
+	t.Run("limit overrides require budgets:override", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		handler := NewAPIKeyHandler(mockService, zap.NewNop())
+		handler.SetAuthorizer(stubAuthorizer{})
+		body := `{"name":"server","scopes":{"budget":{"max_daily_cost":10}}}`
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/applications/x/keys", bytes.NewReader([]byte(body)))
+		w := httptest.NewRecorder()
+
+		handler.HandleIssueKey(w, withURLParam(withTenant(req, orgID, uuid.Nil), "id", appID.String()))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "Issue")
+
+		key := models.NewAPIKey(orgID, appID, "server", "lcp_0123abcd", "hash", nil)
+		mockService.On("Issue", mock.Anything, mock.Anything).Return(&apikey.IssuedKey{APIKey: key, Key: "lcp_0123abcdef"}, nil)
+		handler.SetAuthorizer(stubAuthorizer{models.PermissionBudgetsOverride: true})
+
+		req = httptest.NewRequest(http.MethodPost, "/v1/applications/x/keys", bytes.NewReader([]byte(body)))
+		w = httptest.NewRecorder()
+
+		handler.HandleIssueKey(w, withURLParam(withTenant(req, orgID, uuid.Nil), "id", appID.String()))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("requires name", func(t *testing.T) {
+		handler := NewAPIKeyHandler(new(MockAPIKeyService), zap.NewNop())
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/applications/x/keys", bytes.NewReader([]byte(`{}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleIssueKey(w, withURLParam(withTenant(req, orgID, uuid.Nil), "id", appID.String()))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleListKeys(t *testing.T) {
+	orgID, appID := uuid.New(), uuid.New()
+	mockService := new(MockAPIKeyService)
+	handler := NewAPIKeyHandler(mockService, zap.NewNop())
+	key := models.NewAPIKey(orgID, appID, "server", "lcp_0123abcd", "secret-hash", nil)
+	mockService.On("List", mock.Anything, orgID, appID).Return([]*models.APIKey{key}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/applications/x/keys", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleListKeys(w, withURLParam(withTenant(req, orgID, uuid.Nil), "id", appID.String()))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "lcp_0123abcd")
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+}
+
+func TestHandleRotateKey(t *testing.T) {
+	orgID, appID, keyID := uuid.New(), uuid.New(), uuid.New()
+
+	tests := []struct {
+		name  string
+		body  string
+		grace time.Duration
+	}{
+		{"default grace period", "", apikey.DefaultGracePeriod},
+		{"custom grace period", `{"grace_period_seconds": 3600}`, time.Hour},
+		{"immediate", `{"grace_period_seconds": 0}`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAPIKeyService)
+			handler := NewAPIKeyHandler(mockService, zap.NewNop())
+			key := models.NewAPIKey(orgID, appID, "server", "lcp_4567abcd", "hash", nil)
+			mockService.On("Rotate", mock.Anything, orgID, appID, keyID, tt.grace, (*uuid.UUID)(nil)).
+				Return(&apikey.IssuedKey{APIKey: key, Key: "lcp_4567abcdef"}, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/applications/x/keys/y/rotate", bytes.NewReader([]byte(tt.body)))
+			req = withURLParams(withTenant(req, orgID, uuid.Nil), map[string]string{"id": appID.String(), "keyID": keyID.String()})
+			w := httptest.NewRecorder()
+
+			handler.HandleRotateKey(w, req)
+
+			assert.Equal(t, http.StatusCreated, w.Code)
+			assert.Contains(t, w.Body.String(), "lcp_4567abcdef")
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("negative grace period", func(t *testing.T) {
+		handler := NewAPIKeyHandler(new(MockAPIKeyService), zap.NewNop())
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/applications/x/keys/y/rotate", bytes.NewReader([]byte(`{"grace_period_seconds": -1}`)))
+		req = withURLParams(withTenant(req, orgID, uuid.Nil), map[string]string{"id": appID.String(), "keyID": keyID.String()})
+		w := httptest.NewRecorder()
+
+		handler.HandleRotateKey(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleRevokeKey(t *testing.T) {
+	orgID, appID, keyID := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("revokes", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		handler := NewAPIKeyHandler(mockService, zap.NewNop())
+		mockService.On("Revoke", mock.Anything, orgID, appID, keyID, mock.Anything).Return(nil)
+
+		req := httptest.NewRequest(http.MethodDelete, "/v1/applications/x/keys/y", nil)
+		req = withURLParams(withTenant(req, orgID, uuid.Nil), map[string]string{"id": appID.String(), "keyID": keyID.String()})
+		w := httptest.NewRecorder()
+
+		handler.HandleRevokeKey(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("key of another application", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		handler := NewAPIKeyHandler(mockService, zap.NewNop())
+		mockService.On("Revoke", mock.Anything, orgID, appID, keyID, mock.Anything).
+			Return(services.NewDomainError(services.ErrorTypeNotFound, "api key not found", nil))
+
+		req := httptest.NewRequest(http.MethodDelete, "/v1/applications/x/keys/y", nil)
+		req = withURLParams(withTenant(req, orgID, uuid.Nil), map[string]string{"id": appID.String(), "keyID": keyID.String()})
+		w := httptest.NewRecorder()
+
+		handler.HandleRevokeKey(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHandleUpdateScopes(t *testing.T) {
+	orgID, appID, keyID := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("replaces scopes", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		handler := NewAPIKeyHandler(mockService, zap.NewNop())
+		key := models.NewAPIKey(orgID, appID, "ci", "sk-abcd", "hash", nil)
+		mockService.On("UpdateScopes", mock.Anything, orgID, appID, keyID, mock.MatchedBy(func(s *models.APIKeyScopes) bool {
+			return len(s.AllowedModels) == 1 && s.AllowedModels[0] == "gpt-4o*" && s.RateLimit.RequestsPerMinute == 10
+		}), mock.Anything).Return(key, nil)
+
+		body, _ := json.Marshal(map[string]interface{}{
+			"allowed_models": []string{"gpt-4o*"},
+			"rate_limit":     map[string]int{"requests_per_minute": 10},
+		})
+		req := httptest.NewRequest(http.MethodPut, "/v1/applications/x/keys/y/scopes", bytes.NewReader(body))
+		req = withURLParams(withTenant(req, orgID, uuid.Nil), map[string]string{"id": appID.String(), "keyID": keyID.String()})
+		w := httptest.NewRecorder()
+
+		handler.HandleUpdateScopes(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid network", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		handler := NewAPIKeyHandler(mockService, zap.NewNop())
+		mockService.On("UpdateScopes", mock.Anything, orgID, appID, keyID, mock.Anything, mock.Anything).
+			Return(nil, services.NewDomainError(services.ErrorTypeValidation, "invalid CIDR", nil))
+
+		body, _ := json.Marshal(map[string]interface{}{"allowed_cidrs": []string{"nope"}})
+		req := httptest.NewRequest(http.MethodPut, "/v1/applications/x/keys/y/scopes", bytes.NewReader(body))
+		req = withURLParams(withTenant(req, orgID, uuid.Nil), map[string]string{"id": appID.String(), "keyID": keyID.String()})
+		w := httptest.NewRecorder()
+
+		handler.HandleUpdateScopes(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleKeyUsage(t *testing.T) {
+	orgID, appID, keyID := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("defaults to 30 days", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		handler := NewAPIKeyHandler(mockService, zap.NewNop())
+		mockService.On("Usage", mock.Anything, orgID, appID, keyID, 30).
+			Return([]*models.APIKeyUsage{{KeyID: keyID, Requests: 3, Tokens: 120}}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/applications/x/keys/y/usage", nil)
+		req = withURLParams(withTenant(req, orgID, uuid.Nil), map[string]string{"id": appID.String(), "keyID": keyID.String()})
+		w := httptest.NewRecorder()
+
+		handler.HandleKeyUsage(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"requests":3`)
+	})
+
+	t.Run("invalid days", func(t *testing.T) {
+		mockService := new(MockAPIKeyService)
+		handler := NewAPIKeyHandler(mockService, zap.NewNop())
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/applications/x/keys/y/usage?days=abc", nil)
+		req = withURLParams(withTenant(req, orgID, uuid.Nil), map[string]string{"id": appID.String(), "keyID": keyID.String()})
+		w := httptest.NewRecorder()
+
+		handler.HandleKeyUsage(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "Usage")
+	})
+}
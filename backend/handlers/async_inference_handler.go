@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// AsyncInferenceResponse acknowledges an accepted async request
+type AsyncInferenceResponse struct {
+	ID        uuid.UUID              `json:"id"`
+	RequestID string                 `json:"request_id"`
+	Status    models.InferenceStatus `json:"status"`
+	StatusURL string                 `json:"status_url"`
+}
+
+// AsyncInferenceService defines the interface for async inference operations
+type AsyncInferenceService interface {
+	// Submit queues a chat completion and returns its pending record
+	Submit(ctx context.Context, req *inference.CompletionRequest) (*models.InferenceRequest, error)
+
+	// GetRequest retrieves an inference request of the organization
+	GetRequest(ctx context.Context, id, orgID uuid.UUID) (*models.InferenceRequest, error)
+
+	// ListRequests lists the organization's inference requests, newest first
+	ListRequests(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.InferenceRequest, error)
+}
+
+// AsyncInferenceHandler handles async chat completions and polling for their results
+type AsyncInferenceHandler struct {
+	service AsyncInferenceService
+	logger  *zap.Logger
+}
+
+// NewAsyncInferenceHandler creates a new AsyncInferenceHandler
+func NewAsyncInferenceHandler(service AsyncInferenceService, logger *zap.Logger) *AsyncInferenceHandler {
+	return &AsyncInferenceHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// WithAsyncMode routes requests with ?async=true to async and all others to sync
+func WithAsyncMode(async, sync http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if enabled, _ := strconv.ParseBool(r.URL.Query().Get("async")); enabled {
+			async(w, r)
+			return
+		}
+		sync(w, r)
+	}
+}
+
+// HandleSubmitChatCompletion handles POST /v1/inference/chat?async=true
+// Responds 202 with the inference ID to poll; the result is also delivered to
+// the application's webhook when one is configured.
+func (h *AsyncInferenceHandler) HandleSubmitChatCompletion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := middleware.GetRequestIDFromContext(ctx)
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	appID := middleware.GetAppIDFromContext(ctx)
+	if orgID == uuid.Nil || appID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing tenant information")
+		return
+	}
+
+	var chatReq ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&chatReq); err != nil {
+		h.logger.Warn("failed to parse request body",
+			zap.String("request_id", requestID),
+			zap.Error(err))
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&chatReq); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	if chatReq.Stream {
+		_ = utils.WriteBadRequest(w, "Streaming is not supported for async requests", nil)
+		return
+	}
+
+	record, err := h.service.Submit(ctx, completionRequest(&chatReq, orgID, appID, r))
+	if err != nil {
+		HandleInferenceError(w, err, h.logger)
+		return
+	}
+
+	statusURL := fmt.Sprintf("/api/v1/inference/requests/%s", record.ID)
+	w.Header().Set("Location", statusURL)
+	_ = utils.WriteAccepted(w, AsyncInferenceResponse{
+		ID:        record.ID,
+		RequestID: record.RequestID,
+		Status:    record.Status,
+		StatusURL: statusURL,
+	})
+}
+
+// HandleGetInferenceRequest handles GET /v1/inference/requests/{id}
+func (h *AsyncInferenceHandler) HandleGetInferenceRequest(w http.ResponseWriter, r *http.Request) {
+	orgID := middleware.GetOrgIDFromContext(r.Context())
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing tenant information")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid inference request ID format", nil)
+		return
+	}
+
+	record, err := h.service.GetRequest(r.Context(), id, orgID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, record)
+}
+
+// HandleListInferenceRequests handles GET /v1/inference/requests
+func (h *AsyncInferenceHandler) HandleListInferenceRequests(w http.ResponseWriter, r *http.Request) {
+	orgID := middleware.GetOrgIDFromContext(r.Context())
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing tenant information")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	records, err := h.service.ListRequests(r.Context(), orgID, limit, offset)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, records)
+}
+
+// completionRequest converts an API chat request to a pipeline request
+func completionRequest(chatReq *ChatCompletionRequest, orgID, appID uuid.UUID, r *http.Request) *inference.CompletionRequest {
+	messages := make([]providers.Message, len(chatReq.Messages))
+	for i, msg := range chatReq.Messages {
+		messages[i] = providers.Message{Role: msg.Role, Content: msg.Content}
+	}
+
+	req := &inference.CompletionRequest{
+		OrgID:     orgID,
+		AppID:     appID,
+		UserID:    middleware.GetUserIDFromContext(r.Context()),
+		APIKey:    middleware.GetAPIKeyFromContext(r.Context()),
+		Model:     chatReq.Model,
+		Messages:  messages,
+		Stop:      chatReq.Stop,
+		RequestID: middleware.GetRequestIDFromContext(r.Context()),
+		IPAddress: getClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+	if chatReq.Provider != "" {
+		req.Provider = &chatReq.Provider
+	}
+	if chatReq.MaxTokens != nil {
+		req.MaxTokens = *chatReq.MaxTokens
+	}
+	if chatReq.Temperature != nil {
+		req.Temperature = *chatReq.Temperature
+	}
+	if chatReq.TopP != nil {
+		req.TopP = *chatReq.TopP
+	}
+	return req
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/async"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/webhook"
+	"go.uber.org/zap"
+)
+
+// MockAsyncInferenceService is a mock implementation of AsyncInferenceService
+type MockAsyncInferenceService struct {
+	mock.Mock
+}
+
+func (m *MockAsyncInferenceService) Submit(ctx context.Context, req *inference.CompletionRequest) (*models.InferenceRequest, error) {
+	args := m.Called(ctx, req)
+	if r := args.Get(0); r != nil {
+		return r.(*models.InferenceRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAsyncInferenceService) GetRequest(ctx context.Context, id, orgID uuid.UUID) (*models.InferenceRequest, error) {
+	args := m.Called(ctx, id, orgID)
+	if r := args.Get(0); r != nil {
+		return r.(*models.InferenceRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockAsyncInferenceService) ListRequests(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.InferenceRequest, error) {
+	args := m.Called(ctx, orgID, limit, offset)
+	if r := args.Get(0); r != nil {
+		return r.([]*models.InferenceRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// MockWebhookService is a mock implementation of WebhookService
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) Configure(ctx context.Context, orgID, appID uuid.UUID, url string) (*webhook.Registration, error) {
+	args := m.Called(ctx, orgID, appID, url)
+	if r := args.Get(0); r != nil {
+		return r.(*webhook.Registration), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockWebhookService) Remove(ctx context.Context, orgID, appID uuid.UUID) error {
+	args := m.Called(ctx, orgID, appID)
+	return args.Error(0)
+}
+
+func TestWithAsyncMode(t *testing.T) {
+	var called string
+	handler := WithAsyncMode(
+		func(w http.ResponseWriter, r *http.Request) { called = "async" },
+		func(w http.ResponseWriter, r *http.Request) { called = "sync" },
+	)
+
+	tests := map[string]string{
+		"/v1/inference/chat?async=true":  "async",
+		"/v1/inference/chat?async=1":     "async",
+		"/v1/inference/chat?async=false": "sync",
+		"/v1/inference/chat":             "sync",
+	}
+	for target, want := range tests {
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, target, nil))
+		assert.Equal(t, want, called, target)
+	}
+}
+
+func TestHandleSubmitChatCompletion(t *testing.T) {
+	logger := zap.NewNop()
+	orgID, appID := uuid.New(), uuid.New()
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}],"max_tokens":100}`
+
+	t.Run("accepts request", func(t *testing.T) {
+		mockService := new(MockAsyncInferenceService)
+		handler := NewAsyncInferenceHandler(mockService, logger)
+
+		record := models.NewInferenceRequest(orgID, appID, "", "gpt-4o", "")
+		mockService.On("Submit", mock.Anything, mock.MatchedBy(func(r *inference.CompletionRequest) bool {
+			return r.OrgID == orgID && r.AppID == appID && r.Model == "gpt-4o" && r.MaxTokens == 100 && r.Messages[0].Content == "hi"
+		})).Return(record, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/inference/chat?async=true", bytes.NewReader([]byte(body)))
+		w := httptest.NewRecorder()
+
+		handler.HandleSubmitChatCompletion(w, withTenant(req, orgID, appID))
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "/api/v1/inference/requests/"+record.ID.String(), w.Header().Get("Location"))
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, record.ID.String(), data["id"])
+		assert.Equal(t, "pending", data["status"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("rejects streaming", func(t *testing.T) {
+		handler := NewAsyncInferenceHandler(new(MockAsyncInferenceService), logger)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/inference/chat?async=true",
+			bytes.NewReader([]byte(`{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}],"stream":true}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleSubmitChatCompletion(w, withTenant(req, orgID, appID))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("queue full", func(t *testing.T) {
+		mockService := new(MockAsyncInferenceService)
+		handler := NewAsyncInferenceHandler(mockService, logger)
+
+		mockService.On("Submit", mock.Anything, mock.Anything).Return(nil, &inference.InferenceError{
+			Code:       async.ErrCodeQueueFull,
+			Message:    "async inference queue is full",
+			StatusCode: http.StatusServiceUnavailable,
+			Retryable:  true,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/inference/chat?async=true", bytes.NewReader([]byte(body)))
+		w := httptest.NewRecorder()
+
+		handler.HandleSubmitChatCompletion(w, withTenant(req, orgID, appID))
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+func TestHandleGetInferenceRequest(t *testing.T) {
+	orgID, appID := uuid.New(), uuid.New()
+	record := models.NewInferenceRequest(orgID, appID, "openai", "gpt-4o", "")
+	record.MarkAsCompleted("Paris", "stop", 3, 1, 120, 0.001)
+
+	t.Run("returns result", func(t *testing.T) {
+		mockService := new(MockAsyncInferenceService)
+		handler := NewAsyncInferenceHandler(mockService, zap.NewNop())
+		mockService.On("GetRequest", mock.Anything, record.ID, orgID).Return(record, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/inference/requests/x", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleGetInferenceRequest(w, withURLParam(withTenant(req, orgID, appID), "id", record.ID.String()))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, "completed", data["status"])
+		assert.Equal(t, "Paris", data["response"])
+	})
+
+	t.Run("unknown request", func(t *testing.T) {
+		mockService := new(MockAsyncInferenceService)
+		handler := NewAsyncInferenceHandler(mockService, zap.NewNop())
+		mockService.On("GetRequest", mock.Anything, record.ID, orgID).
+			Return(nil, services.NewDomainError(services.ErrorTypeNotFound, "inference request not found", nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/inference/requests/x", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleGetInferenceRequest(w, withURLParam(withTenant(req, orgID, appID), "id", record.ID.String()))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHandleConfigureWebhook(t *testing.T) {
+	orgID, appID := uuid.New(), uuid.New()
+
+	t.Run("returns secret", func(t *testing.T) {
+		mockService := new(MockWebhookService)
+		handler := NewWebhookHandler(mockService, zap.NewNop())
+		mockService.On("Configure", mock.Anything, orgID, appID, "https://example.com/hooks").
+			Return(&webhook.Registration{AppID: appID, URL: "https://example.com/hooks", Secret: "whsec_x"}, nil)
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/applications/x/webhook", bytes.NewReader([]byte(`{"url":"https://example.com/hooks"}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleConfigureWebhook(w, withURLParam(withTenant(req, orgID, uuid.Nil), "id", appID.String()))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "whsec_x")
+	})
+
+	t.Run("requires url", func(t *testing.T) {
+		handler := NewWebhookHandler(new(MockWebhookService), zap.NewNop())
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/applications/x/webhook", bytes.NewReader([]byte(`{}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleConfigureWebhook(w, withURLParam(withTenant(req, orgID, uuid.Nil), "id", appID.String()))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleListInferenceRequests(t *testing.T) {
+	orgID, appID := uuid.New(), uuid.New()
+	mockService := new(MockAsyncInferenceService)
+	handler := NewAsyncInferenceHandler(mockService, zap.NewNop())
+
+	record := models.NewInferenceRequest(orgID, appID, "openai", "gpt-4o", "")
+	mockService.On("ListRequests", mock.Anything, orgID, 10, 20).Return([]*models.InferenceRequest{record}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/inference/requests?limit=10&offset=20", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleListInferenceRequests(w, withTenant(req, orgID, appID))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), record.ID.String())
+	mockService.AssertExpectations(t)
+}
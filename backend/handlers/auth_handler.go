@@ -12,6 +12,11 @@ type AuthDeps interface {
 	AuthHandler() *auth.Handler
 }
 
+// OIDCAuthDeps provides the generic OIDC auth handler for route wiring
+type OIDCAuthDeps interface {
+	OIDCHandler() *auth.OIDCHandler
+}
+
 // AuthLoginHandler returns an http.HandlerFunc for the login endpoint
 func AuthLoginHandler(deps AuthDeps) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -44,3 +49,36 @@ func AuthLogoutHandler(deps AuthDeps) http.HandlerFunc {
 		_ = utils.WriteInternalServerError(w, "Authentication not configured")
 	}
 }
+
+// OIDCLoginHandler returns an http.HandlerFunc for the OIDC login endpoint
+func OIDCLoginHandler(deps OIDCAuthDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h := deps.OIDCHandler(); h != nil {
+			h.HandleLogin(w, r)
+			return
+		}
+		_ = utils.WriteNotFound(w, "Unknown identity provider")
+	}
+}
+
+// OIDCCallbackHandler returns an http.HandlerFunc for the OIDC callback endpoint
+func OIDCCallbackHandler(deps OIDCAuthDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h := deps.OIDCHandler(); h != nil {
+			h.HandleCallback(w, r)
+			return
+		}
+		_ = utils.WriteNotFound(w, "Unknown identity provider")
+	}
+}
+
+// OIDCLogoutHandler returns an http.HandlerFunc for the OIDC logout endpoint
+func OIDCLogoutHandler(deps OIDCAuthDeps) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h := deps.OIDCHandler(); h != nil {
+			h.HandleLogout(w, r)
+			return
+		}
+		_ = utils.WriteNotFound(w, "Unknown identity provider")
+	}
+}
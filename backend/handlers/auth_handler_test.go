@@ -7,13 +7,19 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"github.com/upb/llm-control-plane/backend/auth"
 	"github.com/upb/llm-control-plane/backend/config"
 	"github.com/upb/llm-control-plane/backend/cognito"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/oidc"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/session"
 	"go.uber.org/zap"
 )
 
@@ -22,12 +28,12 @@ type MockTokenExchanger struct {
 	mock.Mock
 }
 
-func (m *MockTokenExchanger) ExchangeCode(ctx context.Context, code, redirectURI, state string) (idToken string, err error) {
+func (m *MockTokenExchanger) ExchangeCode(ctx context.Context, code, redirectURI, state string) (*services.TokenResponse, error) {
 	args := m.Called(ctx, code, redirectURI, state)
 	if args.Get(0) == nil {
-		return "", args.Error(1)
+		return nil, args.Error(1)
 	}
-	return args.String(0), args.Error(1)
+	return args.Get(0).(*services.TokenResponse), args.Error(1)
 }
 
 // MockTokenValidator mocks JWT validation
@@ -43,6 +49,41 @@ func (m *MockTokenValidator) ValidateToken(ctx context.Context, token string) (*
 	return args.Get(0).(*cognito.ParsedClaims), args.Error(1)
 }
 
+// MockOIDCProvider mocks a generic OIDC provider
+type MockOIDCProvider struct {
+	mock.Mock
+}
+
+func (m *MockOIDCProvider) AuthCodeURL(ctx context.Context, state, nonce string) (string, error) {
+	args := m.Called(ctx, state, nonce)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockOIDCProvider) ExchangeCode(ctx context.Context, code string) (*oidc.Tokens, error) {
+	args := m.Called(ctx, code)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*oidc.Tokens), args.Error(1)
+}
+
+func (m *MockOIDCProvider) ValidateToken(ctx context.Context, token string) (*oidc.Identity, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*oidc.Identity), args.Error(1)
+}
+
+func (m *MockOIDCProvider) EndSessionURL(ctx context.Context, idTokenHint, postLogoutRedirectURI string) (string, error) {
+	args := m.Called(ctx, idTokenHint, postLogoutRedirectURI)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockOIDCProvider) RedirectURI() string {
+	return "https://gateway.test/auth/oidc/okta/callback"
+}
+
 func TestHandleLogin(t *testing.T) {
 	logger := zap.NewNop()
 	cfg := &config.Config{
@@ -133,7 +174,7 @@ func TestHandleCallback(t *testing.T) {
 		mockValidator := new(MockTokenValidator)
 
 		mockExchanger.On("ExchangeCode", mock.Anything, "auth-code", "http://localhost:8080/auth/callback", "state-123").
-			Return(validIDToken, nil)
+			Return(&services.TokenResponse{IDToken: validIDToken}, nil)
 		mockValidator.On("ValidateToken", mock.Anything, validIDToken).
 			Return(&cognito.ParsedClaims{}, nil)
 
@@ -180,7 +221,7 @@ func TestHandleCallback(t *testing.T) {
 		mockValidator := new(MockTokenValidator)
 
 		mockExchanger.On("ExchangeCode", mock.Anything, "auth-code", "http://localhost:8080/auth/callback", "state-123").
-			Return(validIDToken, nil)
+			Return(&services.TokenResponse{IDToken: validIDToken}, nil)
 		mockValidator.On("ValidateToken", mock.Anything, validIDToken).
 			Return(&cognito.ParsedClaims{}, nil)
 
@@ -252,7 +293,7 @@ func TestHandleCallback(t *testing.T) {
 	t.Run("returns unauthorized when token exchange fails", func(t *testing.T) {
 		mockExchanger := new(MockTokenExchanger)
 		mockExchanger.On("ExchangeCode", mock.Anything, "bad-code", "http://localhost:8080/auth/callback", "state-123").
-			Return("", assert.AnError)
+			Return(nil, assert.AnError)
 
 		handler := auth.NewHandler(cfg, mockExchanger, nil, logger)
 
@@ -271,7 +312,7 @@ func TestHandleCallback(t *testing.T) {
 		mockValidator := new(MockTokenValidator)
 
 		mockExchanger.On("ExchangeCode", mock.Anything, "auth-code", "http://localhost:8080/auth/callback", "state-123").
-			Return("invalid.token", nil)
+			Return(&services.TokenResponse{IDToken: "invalid.token"}, nil)
 		mockValidator.On("ValidateToken", mock.Anything, "invalid.token").
 			Return(nil, cognito.ErrInvalidToken)
 
@@ -332,3 +373,176 @@ func TestHandleLogout(t *testing.T) {
 	})
 }
 
+
+// MockSessionManager mocks server-side sessions
+type MockSessionManager struct {
+	mock.Mock
+}
+
+func (m *MockSessionManager) Create(ctx context.Context, identity *session.Identity, refreshToken string, client session.ClientInfo) (*session.CreatedSession, error) {
+	args := m.Called(ctx, identity, refreshToken, client)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*session.CreatedSession), args.Error(1)
+}
+
+func (m *MockSessionManager) RevokeToken(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func TestHandleCallback_Session(t *testing.T) {
+	logger := zap.NewNop()
+	cfg := &config.Config{
+		Cognito: config.CognitoConfig{
+			Domain:      "https://test.auth.us-east-1.amazoncognito.com",
+			ClientID:    "test-client-id",
+			RedirectURI: "http://localhost:8080/auth/callback",
+		},
+	}
+	sub, orgID := uuid.New(), uuid.New()
+
+	t.Run("callback starts a session and sets its opaque token", func(t *testing.T) {
+		mockExchanger := new(MockTokenExchanger)
+		mockValidator := new(MockTokenValidator)
+		sessions := new(MockSessionManager)
+		mockExchanger.On("ExchangeCode", mock.Anything, "auth-code", "http://localhost:8080/auth/callback", "state-123").
+			Return(&services.TokenResponse{IDToken: "valid.id.token", RefreshToken: "refresh-token"}, nil)
+		mockValidator.On("ValidateToken", mock.Anything, "valid.id.token").
+			Return(&cognito.ParsedClaims{Sub: sub, OrgID: orgID, Role: "admin", ExpiresAt: time.Now().Add(time.Hour)}, nil)
+		sessions.On("Create", mock.Anything, mock.MatchedBy(func(identity *session.Identity) bool {
+			return identity.Provider == auth.CognitoProviderName && identity.Subject == sub.String() &&
+				identity.OrgID == orgID && len(identity.Roles) == 1 && identity.Roles[0] == "admin"
+		}), "refresh-token", mock.Anything).Return(&session.CreatedSession{
+			Session: &models.Session{ID: uuid.New(), ExpiresAt: time.Now().Add(session.DefaultLifetime)},
+			Token:   "lcps_opaque",
+		}, nil)
+
+		handler := auth.NewHandler(cfg, mockExchanger, mockValidator, logger)
+		handler.SetSessionManager(sessions)
+
+		req := httptest.NewRequest(http.MethodGet, "/auth/callback?code=auth-code&state=state-123", nil)
+		req.AddCookie(&http.Cookie{Name: auth.StateCookieName, Value: "state-123"})
+		rec := httptest.NewRecorder()
+
+		handler.HandleCallback(rec, req)
+
+		require.Equal(t, http.StatusFound, rec.Code)
+		var sessionCookie *http.Cookie
+		for _, c := range rec.Result().Cookies() {
+			if c.Name == auth.SessionCookieName {
+				sessionCookie = c
+			}
+		}
+		require.NotNil(t, sessionCookie)
+		assert.Equal(t, "lcps_opaque", sessionCookie.Value, "the cookie carries no token of the identity provider")
+		assert.True(t, sessionCookie.HttpOnly)
+		sessions.AssertExpectations(t)
+	})
+
+	t.Run("logout revokes the session", func(t *testing.T) {
+		sessions := new(MockSessionManager)
+		sessions.On("RevokeToken", mock.Anything, "lcps_opaque").Return(nil)
+		handler := auth.NewHandler(cfg, nil, nil, logger)
+		handler.SetSessionManager(sessions)
+
+		req := httptest.NewRequest(http.MethodGet, "/auth/logout", nil)
+		req.AddCookie(&http.Cookie{Name: auth.SessionCookieName, Value: "lcps_opaque"})
+		rec := httptest.NewRecorder()
+
+		handler.HandleLogout(rec, req)
+
+		assert.Equal(t, http.StatusFound, rec.Code)
+		sessions.AssertExpectations(t)
+	})
+}
+
+func TestOIDCHandler(t *testing.T) {
+	logger := zap.NewNop()
+
+	callback := func(state, nonce string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/auth/oidc/okta/callback?code=auth-code&state="+state, nil)
+		req.AddCookie(&http.Cookie{Name: auth.OIDCStateCookieName, Value: "state-123"})
+		req.AddCookie(&http.Cookie{Name: auth.OIDCNonceCookieName, Value: nonce})
+		return withURLParam(req, "provider", "okta")
+	}
+
+	t.Run("unknown provider", func(t *testing.T) {
+		handler := auth.NewOIDCHandler(map[string]auth.OIDCProvider{}, "/", logger)
+
+		req := httptest.NewRequest(http.MethodGet, "/auth/oidc/nope/login", nil)
+		rec := httptest.NewRecorder()
+		handler.HandleLogin(rec, withURLParam(req, "provider", "nope"))
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("login redirects with state and nonce", func(t *testing.T) {
+		provider := new(MockOIDCProvider)
+		provider.On("AuthCodeURL", mock.Anything, mock.Anything, mock.Anything).Return("https://acme.okta.test/authorize?x=1", nil)
+		handler := auth.NewOIDCHandler(map[string]auth.OIDCProvider{"okta": provider}, "/", logger)
+
+		req := httptest.NewRequest(http.MethodGet, "/auth/oidc/okta/login", nil)
+		rec := httptest.NewRecorder()
+		handler.HandleLogin(rec, withURLParam(req, "provider", "okta"))
+
+		require.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, "https://acme.okta.test/authorize?x=1", rec.Header().Get("Location"))
+
+		cookies := map[string]*http.Cookie{}
+		for _, c := range rec.Result().Cookies() {
+			cookies[c.Name] = c
+		}
+		require.Contains(t, cookies, auth.OIDCStateCookieName)
+		require.Contains(t, cookies, auth.OIDCNonceCookieName)
+		assert.True(t, cookies[auth.OIDCStateCookieName].Secure)
+		state := provider.Calls[0].Arguments.String(1)
+		nonce := provider.Calls[0].Arguments.String(2)
+		assert.Equal(t, state, cookies[auth.OIDCStateCookieName].Value)
+		assert.Equal(t, nonce, cookies[auth.OIDCNonceCookieName].Value)
+		assert.NotEqual(t, state, nonce)
+	})
+
+	t.Run("callback sets session cookie", func(t *testing.T) {
+		provider := new(MockOIDCProvider)
+		provider.On("ExchangeCode", mock.Anything, "auth-code").Return(&oidc.Tokens{IDToken: "id.token"}, nil)
+		provider.On("ValidateToken", mock.Anything, "id.token").Return(&oidc.Identity{Provider: "okta", Nonce: "nonce-123"}, nil)
+		handler := auth.NewOIDCHandler(map[string]auth.OIDCProvider{"okta": provider}, "https://app.test", logger)
+
+		rec := httptest.NewRecorder()
+		handler.HandleCallback(rec, callback("state-123", "nonce-123"))
+
+		require.Equal(t, http.StatusFound, rec.Code)
+		assert.Equal(t, "https://app.test", rec.Header().Get("Location"))
+		var session *http.Cookie
+		for _, c := range rec.Result().Cookies() {
+			if c.Name == auth.SessionCookieName {
+				session = c
+			}
+		}
+		require.NotNil(t, session)
+		assert.Equal(t, "id.token", session.Value)
+	})
+
+	t.Run("callback rejects mismatched nonce", func(t *testing.T) {
+		provider := new(MockOIDCProvider)
+		provider.On("ExchangeCode", mock.Anything, "auth-code").Return(&oidc.Tokens{IDToken: "id.token"}, nil)
+		provider.On("ValidateToken", mock.Anything, "id.token").Return(&oidc.Identity{Provider: "okta", Nonce: "replayed"}, nil)
+		handler := auth.NewOIDCHandler(map[string]auth.OIDCProvider{"okta": provider}, "/", logger)
+
+		rec := httptest.NewRecorder()
+		handler.HandleCallback(rec, callback("state-123", "nonce-123"))
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("callback rejects mismatched state", func(t *testing.T) {
+		handler := auth.NewOIDCHandler(map[string]auth.OIDCProvider{"okta": new(MockOIDCProvider)}, "/", logger)
+
+		rec := httptest.NewRecorder()
+		handler.HandleCallback(rec, callback("forged", "nonce-123"))
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
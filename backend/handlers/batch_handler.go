@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/batch"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// MaxBatchUploadBytes is the largest accepted batch input file
+const MaxBatchUploadBytes = 100 << 20
+
+// BatchJobResponse represents a batch job with its progress
+type BatchJobResponse struct {
+	*models.BatchJob
+	Progress float64 `json:"progress"`
+}
+
+// BatchService defines the interface for batch job operations
+type BatchService interface {
+	// CreateJob validates a JSONL upload and stores it as a pending job
+	CreateJob(ctx context.Context, req batch.CreateJobRequest) (*models.BatchJob, error)
+
+	// GetJob retrieves a job of the organization
+	GetJob(ctx context.Context, id, orgID uuid.UUID) (*models.BatchJob, error)
+
+	// ListJobs lists the organization's jobs
+	ListJobs(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.BatchJob, error)
+
+	// CancelJob stops a job that has not finished
+	CancelJob(ctx context.Context, id, orgID uuid.UUID) (*models.BatchJob, error)
+
+	// WriteResults writes the job's JSONL result file
+	WriteResults(ctx context.Context, id, orgID uuid.UUID, w io.Writer) error
+}
+
+// BatchHandler handles batch inference job HTTP requests
+type BatchHandler struct {
+	service BatchService
+	logger  *zap.Logger
+}
+
+// NewBatchHandler creates a new BatchHandler
+func NewBatchHandler(service BatchService, logger *zap.Logger) *BatchHandler {
+	return &BatchHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleCreateBatch handles POST /v1/batches
+// The body is a JSONL file with one chat completion request per line.
+func (h *BatchHandler) HandleCreateBatch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	appID := middleware.GetAppIDFromContext(ctx)
+	if orgID == uuid.Nil || appID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing tenant information")
+		return
+	}
+
+	body := http.MaxBytesReader(w, r.Body, MaxBatchUploadBytes)
+	job, err := h.service.CreateJob(ctx, batch.CreateJobRequest{
+		OrgID:  orgID,
+		AppID:  appID,
+		UserID: middleware.GetUserIDFromContext(ctx),
+		Input:  body,
+	})
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			_ = utils.WriteError(w, http.StatusRequestEntityTooLarge, "Batch file too large", map[string]interface{}{
+				"max_bytes": MaxBatchUploadBytes,
+			})
+			return
+		}
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteCreated(w, batchJobResponse(job))
+}
+
+// HandleListBatches handles GET /v1/batches
+func (h *BatchHandler) HandleListBatches(w http.ResponseWriter, r *http.Request) {
+	orgID := middleware.GetOrgIDFromContext(r.Context())
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing tenant information")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	jobs, err := h.service.ListJobs(r.Context(), orgID, limit, offset)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	response := make([]BatchJobResponse, 0, len(jobs))
+	for _, job := range jobs {
+		response = append(response, batchJobResponse(job))
+	}
+	_ = utils.WriteOK(w, response)
+}
+
+// HandleGetBatch handles GET /v1/batches/{id}
+func (h *BatchHandler) HandleGetBatch(w http.ResponseWriter, r *http.Request) {
+	h.withBatchID(w, r, h.service.GetJob)
+}
+
+// HandleCancelBatch handles POST /v1/batches/{id}/cancel
+func (h *BatchHandler) HandleCancelBatch(w http.ResponseWriter, r *http.Request) {
+	h.withBatchID(w, r, h.service.CancelJob)
+}
+
+// HandleGetBatchResults handles GET /v1/batches/{id}/results
+func (h *BatchHandler) HandleGetBatchResults(w http.ResponseWriter, r *http.Request) {
+	orgID, id, ok := h.parseBatchID(w, r)
+	if !ok {
+		return
+	}
+
+	// Check access before the body starts so errors keep their status code
+	if _, err := h.service.GetJob(r.Context(), id, orgID); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/jsonl")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="batch_%s_results.jsonl"`, id))
+	w.WriteHeader(http.StatusOK)
+
+	if err := h.service.WriteResults(r.Context(), id, orgID, w); err != nil {
+		h.logger.Error("failed to write batch results",
+			zap.String("job_id", id.String()),
+			zap.Error(err))
+	}
+}
+
+// parseBatchID extracts the organization and the batch job ID
+func (h *BatchHandler) parseBatchID(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	orgID := middleware.GetOrgIDFromContext(r.Context())
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing tenant information")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid batch ID format", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return orgID, id, true
+}
+
+// withBatchID parses the batch job ID and writes the job returned by fn
+func (h *BatchHandler) withBatchID(w http.ResponseWriter, r *http.Request, fn func(ctx context.Context, id, orgID uuid.UUID) (*models.BatchJob, error)) {
+	orgID, id, ok := h.parseBatchID(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := fn(r.Context(), id, orgID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, batchJobResponse(job))
+}
+
+func batchJobResponse(job *models.BatchJob) BatchJobResponse {
+	return BatchJobResponse{BatchJob: job, Progress: job.Progress()}
+}
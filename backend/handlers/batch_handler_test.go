@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/batch"
+	"go.uber.org/zap"
+)
+
+// MockBatchService is a mock implementation of BatchService
+type MockBatchService struct {
+	mock.Mock
+}
+
+func (m *MockBatchService) CreateJob(ctx context.Context, req batch.CreateJobRequest) (*models.BatchJob, error) {
+	args := m.Called(ctx, req)
+	if j := args.Get(0); j != nil {
+		return j.(*models.BatchJob), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBatchService) GetJob(ctx context.Context, id, orgID uuid.UUID) (*models.BatchJob, error) {
+	args := m.Called(ctx, id, orgID)
+	if j := args.Get(0); j != nil {
+		return j.(*models.BatchJob), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBatchService) ListJobs(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.BatchJob, error) {
+	args := m.Called(ctx, orgID, limit, offset)
+	if j := args.Get(0); j != nil {
+		return j.([]*models.BatchJob), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBatchService) CancelJob(ctx context.Context, id, orgID uuid.UUID) (*models.BatchJob, error) {
+	args := m.Called(ctx, id, orgID)
+	if j := args.Get(0); j != nil {
+		return j.(*models.BatchJob), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockBatchService) WriteResults(ctx context.Context, id, orgID uuid.UUID, w io.Writer) error {
+	args := m.Called(ctx, id, orgID, w)
+	_, _ = io.WriteString(w, args.String(0))
+	return args.Error(1)
+}
+
+func withTenant(req *http.Request, orgID, appID uuid.UUID) *http.Request {
+	ctx := context.WithValue(req.Context(), middleware.OrgIDKey, orgID)
+	ctx = context.WithValue(ctx, middleware.AppIDKey, appID)
+	return req.WithContext(ctx)
+}
+
+func TestHandleCreateBatch(t *testing.T) {
+	logger := zap.NewNop()
+	orgID, appID := uuid.New(), uuid.New()
+	input := `{"custom_id":"a","body":{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}}`
+
+	t.Run("creates job", func(t *testing.T) {
+		mockService := new(MockBatchService)
+		handler := NewBatchHandler(mockService, logger)
+
+		job := models.NewBatchJob(orgID, appID, nil, 1)
+		mockService.On("CreateJob", mock.Anything, mock.MatchedBy(func(r batch.CreateJobRequest) bool {
+			body, _ := io.ReadAll(r.Input)
+			return r.OrgID == orgID && r.AppID == appID && string(body) == input
+		})).Return(job, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/batches", strings.NewReader(input))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateBatch(w, withTenant(req, orgID, appID))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, job.ID.String(), data["id"])
+		assert.Equal(t, float64(0), data["progress"])
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		mockService := new(MockBatchService)
+		handler := NewBatchHandler(mockService, logger)
+
+		mockService.On("CreateJob", mock.Anything, mock.Anything).
+			Return(nil, services.NewDomainError(services.ErrorTypeValidation, "line 1: body.model is required", nil))
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/batches", strings.NewReader("{}"))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateBatch(w, withTenant(req, orgID, appID))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("requires an application", func(t *testing.T) {
+		handler := NewBatchHandler(new(MockBatchService), logger)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/batches", strings.NewReader(input))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateBatch(w, withTenant(req, orgID, uuid.Nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestHandleGetBatchResults(t *testing.T) {
+	logger := zap.NewNop()
+	orgID, appID := uuid.New(), uuid.New()
+	job := models.NewBatchJob(orgID, appID, nil, 1)
+
+	t.Run("streams JSONL", func(t *testing.T) {
+		mockService := new(MockBatchService)
+		handler := NewBatchHandler(mockService, logger)
+
+		mockService.On("GetJob", mock.Anything, job.ID, orgID).Return(job, nil)
+		mockService.On("WriteResults", mock.Anything, job.ID, orgID, mock.Anything).Return(`{"custom_id":"a"}`+"\n", nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/batches/x/results", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleGetBatchResults(w, withURLParam(withTenant(req, orgID, appID), "id", job.ID.String()))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/jsonl", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), job.ID.String())
+		assert.Equal(t, `{"custom_id":"a"}`+"\n", w.Body.String())
+	})
+
+	t.Run("unknown job", func(t *testing.T) {
+		mockService := new(MockBatchService)
+		handler := NewBatchHandler(mockService, logger)
+
+		mockService.On("GetJob", mock.Anything, job.ID, orgID).
+			Return(nil, services.NewDomainError(services.ErrorTypeNotFound, "batch job not found", nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/batches/x/results", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleGetBatchResults(w, withURLParam(withTenant(req, orgID, appID), "id", job.ID.String()))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertNotCalled(t, "WriteResults", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleCancelBatch(t *testing.T) {
+	orgID, appID := uuid.New(), uuid.New()
+	jobID := uuid.New()
+
+	mockService := new(MockBatchService)
+	handler := NewBatchHandler(mockService, zap.NewNop())
+	mockService.On("CancelJob", mock.Anything, jobID, orgID).
+		Return(nil, services.NewDomainError(services.ErrorTypeConflict, "batch job has already finished", nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/batches/x/cancel", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleCancelBatch(w, withURLParam(withTenant(req, orgID, appID), "id", jobID.String()))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
@@ -0,0 +1,265 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/conversation"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// CreateConversationRequest represents a request to start a conversation
+type CreateConversationRequest struct {
+	Title        string `json:"title,omitempty" validate:"max=255"`
+	Model        string `json:"model" validate:"required"`
+	SystemPrompt string `json:"system_prompt,omitempty"`
+}
+
+// SendMessageRequest represents a new user message posted to a conversation
+type SendMessageRequest struct {
+	Content       string   `json:"content" validate:"required"`
+	Model         string   `json:"model,omitempty"`
+	MaxTokens     *int     `json:"max_tokens,omitempty" validate:"omitempty,gt=0"`
+	Temperature   *float64 `json:"temperature,omitempty" validate:"omitempty,gte=0,lte=2"`
+	HistoryTokens *int     `json:"history_tokens,omitempty" validate:"omitempty,gt=0"`
+}
+
+// ConversationService defines the interface for conversation operations
+type ConversationService interface {
+	// CreateConversation starts an empty conversation owned by the user
+	CreateConversation(ctx context.Context, req conversation.CreateConversationRequest) (*models.Conversation, error)
+
+	// GetConversation retrieves a conversation owned by the user
+	GetConversation(ctx context.Context, id, orgID, userID uuid.UUID) (*models.Conversation, error)
+
+	// ListConversations lists the user's conversations
+	ListConversations(ctx context.Context, orgID, userID uuid.UUID, limit, offset int) ([]*models.Conversation, error)
+
+	// DeleteConversation deletes a conversation with its messages
+	DeleteConversation(ctx context.Context, id, orgID, userID uuid.UUID) error
+
+	// ListMessages lists the stored turns of a conversation
+	ListMessages(ctx context.Context, id, orgID, userID uuid.UUID) ([]*models.ConversationMessage, error)
+
+	// SendMessage posts a user message and returns the model's reply
+	SendMessage(ctx context.Context, req conversation.SendMessageRequest) (*conversation.SendMessageResult, error)
+}
+
+// ConversationHandler handles conversation thread HTTP requests
+type ConversationHandler struct {
+	service ConversationService
+	logger  *zap.Logger
+}
+
+// NewConversationHandler creates a new ConversationHandler
+func NewConversationHandler(service ConversationService, logger *zap.Logger) *ConversationHandler {
+	return &ConversationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleCreateConversation handles POST /v1/conversations
+func (h *ConversationHandler) HandleCreateConversation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := middleware.GetRequestIDFromContext(ctx)
+
+	orgID, userID, ok := h.owner(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateConversationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("failed to parse request body",
+			zap.String("request_id", requestID),
+			zap.Error(err))
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	var appID *uuid.UUID
+	if id := middleware.GetAppIDFromContext(ctx); id != uuid.Nil {
+		appID = &id
+	}
+
+	created, err := h.service.CreateConversation(ctx, conversation.CreateConversationRequest{
+		OrgID:        orgID,
+		AppID:        appID,
+		UserID:       userID,
+		Title:        req.Title,
+		Model:        req.Model,
+		SystemPrompt: req.SystemPrompt,
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteCreated(w, created)
+}
+
+// HandleListConversations handles GET /v1/conversations
+func (h *ConversationHandler) HandleListConversations(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, ok := h.owner(w, r)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	conversations, err := h.service.ListConversations(r.Context(), orgID, userID, limit, offset)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, conversations)
+}
+
+// HandleGetConversation handles GET /v1/conversations/{id}
+func (h *ConversationHandler) HandleGetConversation(w http.ResponseWriter, r *http.Request) {
+	h.withConversationID(w, r, func(ctx context.Context, id, orgID, userID uuid.UUID) (interface{}, error) {
+		return h.service.GetConversation(ctx, id, orgID, userID)
+	})
+}
+
+// HandleListMessages handles GET /v1/conversations/{id}/messages
+func (h *ConversationHandler) HandleListMessages(w http.ResponseWriter, r *http.Request) {
+	h.withConversationID(w, r, func(ctx context.Context, id, orgID, userID uuid.UUID) (interface{}, error) {
+		return h.service.ListMessages(ctx, id, orgID, userID)
+	})
+}
+
+// HandleDeleteConversation handles DELETE /v1/conversations/{id}
+func (h *ConversationHandler) HandleDeleteConversation(w http.ResponseWriter, r *http.Request) {
+	orgID, userID, ok := h.owner(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid conversation ID format", nil)
+		return
+	}
+
+	if err := h.service.DeleteConversation(r.Context(), id, orgID, userID); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// HandleSendMessage handles POST /v1/conversations/{id}/messages
+func (h *ConversationHandler) HandleSendMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := middleware.GetRequestIDFromContext(ctx)
+
+	orgID, userID, ok := h.owner(w, r)
+	if !ok {
+		return
+	}
+
+	appID := middleware.GetAppIDFromContext(ctx)
+	if appID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing tenant information")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid conversation ID format", nil)
+		return
+	}
+
+	var req SendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("failed to parse request body",
+			zap.String("request_id", requestID),
+			zap.Error(err))
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	serviceReq := conversation.SendMessageRequest{
+		ConversationID: id,
+		OrgID:          orgID,
+		AppID:          appID,
+		UserID:         userID,
+		Content:        req.Content,
+		Model:          req.Model,
+		RequestID:      requestID,
+		IPAddress:      getClientIP(r),
+		UserAgent:      r.UserAgent(),
+	}
+	if req.MaxTokens != nil {
+		serviceReq.MaxTokens = *req.MaxTokens
+	}
+	if req.Temperature != nil {
+		serviceReq.Temperature = *req.Temperature
+	}
+	if req.HistoryTokens != nil {
+		serviceReq.HistoryTokens = *req.HistoryTokens
+	}
+
+	result, err := h.service.SendMessage(ctx, serviceReq)
+	if err != nil {
+		HandleInferenceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, result)
+}
+
+// owner extracts the organization and user that conversations are scoped to
+func (h *ConversationHandler) owner(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	orgID := middleware.GetOrgIDFromContext(r.Context())
+	userID := middleware.GetUserIDFromContext(r.Context())
+	if orgID == uuid.Nil || userID == nil {
+		_ = utils.WriteUnauthorized(w, "Missing user information")
+		return uuid.Nil, uuid.Nil, false
+	}
+	return orgID, *userID, true
+}
+
+// withConversationID parses the conversation ID and owner and writes the result of fn
+func (h *ConversationHandler) withConversationID(w http.ResponseWriter, r *http.Request, fn func(ctx context.Context, id, orgID, userID uuid.UUID) (interface{}, error)) {
+	orgID, userID, ok := h.owner(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid conversation ID format", nil)
+		return
+	}
+
+	result, err := fn(r.Context(), id, orgID, userID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, result)
+}
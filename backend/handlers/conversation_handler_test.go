@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/conversation"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"go.uber.org/zap"
+)
+
+// MockConversationService is a mock implementation of ConversationService
+type MockConversationService struct {
+	mock.Mock
+}
+
+func (m *MockConversationService) CreateConversation(ctx context.Context, req conversation.CreateConversationRequest) (*models.Conversation, error) {
+	args := m.Called(ctx, req)
+	if c := args.Get(0); c != nil {
+		return c.(*models.Conversation), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockConversationService) GetConversation(ctx context.Context, id, orgID, userID uuid.UUID) (*models.Conversation, error) {
+	args := m.Called(ctx, id, orgID, userID)
+	if c := args.Get(0); c != nil {
+		return c.(*models.Conversation), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockConversationService) ListConversations(ctx context.Context, orgID, userID uuid.UUID, limit, offset int) ([]*models.Conversation, error) {
+	args := m.Called(ctx, orgID, userID, limit, offset)
+	if c := args.Get(0); c != nil {
+		return c.([]*models.Conversation), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockConversationService) DeleteConversation(ctx context.Context, id, orgID, userID uuid.UUID) error {
+	args := m.Called(ctx, id, orgID, userID)
+	return args.Error(0)
+}
+
+func (m *MockConversationService) ListMessages(ctx context.Context, id, orgID, userID uuid.UUID) ([]*models.ConversationMessage, error) {
+	args := m.Called(ctx, id, orgID, userID)
+	if msgs := args.Get(0); msgs != nil {
+		return msgs.([]*models.ConversationMessage), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockConversationService) SendMessage(ctx context.Context, req conversation.SendMessageRequest) (*conversation.SendMessageResult, error) {
+	args := m.Called(ctx, req)
+	if r := args.Get(0); r != nil {
+		return r.(*conversation.SendMessageResult), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func withConversationOwner(req *http.Request, orgID, appID uuid.UUID, userID *uuid.UUID) *http.Request {
+	ctx := context.WithValue(req.Context(), middleware.OrgIDKey, orgID)
+	ctx = context.WithValue(ctx, middleware.AppIDKey, appID)
+	if userID != nil {
+		ctx = context.WithValue(ctx, middleware.UserIDKey, userID)
+	}
+	return req.WithContext(ctx)
+}
+
+func TestHandleCreateConversation(t *testing.T) {
+	logger := zap.NewNop()
+	orgID, appID, userID := uuid.New(), uuid.New(), uuid.New()
+
+	t.Run("creates conversation", func(t *testing.T) {
+		mockService := new(MockConversationService)
+		handler := NewConversationHandler(mockService, logger)
+
+		mockService.On("CreateConversation", mock.Anything, mock.MatchedBy(func(r conversation.CreateConversationRequest) bool {
+			return r.OrgID == orgID && *r.AppID == appID && r.UserID == userID && r.Model == "gpt-4o"
+		})).Return(models.NewConversation(orgID, &appID, userID, "", "gpt-4o", ""), nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/conversations", bytes.NewReader([]byte(`{"model":"gpt-4o"}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateConversation(w, withConversationOwner(req, orgID, appID, &userID))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("requires a user", func(t *testing.T) {
+		handler := NewConversationHandler(new(MockConversationService), logger)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/conversations", bytes.NewReader([]byte(`{"model":"gpt-4o"}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateConversation(w, withConversationOwner(req, orgID, appID, nil))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestHandleSendMessage(t *testing.T) {
+	logger := zap.NewNop()
+	orgID, appID, userID := uuid.New(), uuid.New(), uuid.New()
+	conversationID := uuid.New()
+
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/v1/conversations/x/messages", bytes.NewReader([]byte(body)))
+		req = withConversationOwner(req, orgID, appID, &userID)
+		return withURLParam(req, "id", conversationID.String())
+	}
+
+	t.Run("returns reply", func(t *testing.T) {
+		mockService := new(MockConversationService)
+		handler := NewConversationHandler(mockService, logger)
+
+		reply := models.NewConversationMessage(conversationID, "assistant", "Paris", 1)
+		mockService.On("SendMessage", mock.Anything, mock.MatchedBy(func(r conversation.SendMessageRequest) bool {
+			return r.ConversationID == conversationID && r.UserID == userID && r.Content == "Capital of France?" && r.HistoryTokens == 500
+		})).Return(&conversation.SendMessageResult{Message: reply, Completion: &inference.CompletionResponse{}}, nil)
+
+		w := httptest.NewRecorder()
+		handler.HandleSendMessage(w, newRequest(`{"content":"Capital of France?","history_tokens":500}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("pipeline error keeps its status", func(t *testing.T) {
+		mockService := new(MockConversationService)
+		handler := NewConversationHandler(mockService, logger)
+
+		mockService.On("SendMessage", mock.Anything, mock.Anything).
+			Return(nil, inference.NewRateLimitError("rate limit exceeded", map[string]interface{}{"retry_after": 30}))
+
+		w := httptest.NewRecorder()
+		handler.HandleSendMessage(w, newRequest(`{"content":"hi"}`))
+
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		details := response["details"].(map[string]interface{})
+		assert.Equal(t, inference.ErrCodeRateLimitExceeded, details["code"])
+	})
+
+	t.Run("unknown conversation", func(t *testing.T) {
+		mockService := new(MockConversationService)
+		handler := NewConversationHandler(mockService, logger)
+
+		mockService.On("SendMessage", mock.Anything, mock.Anything).
+			Return(nil, services.NewDomainError(services.ErrorTypeNotFound, "conversation not found", nil))
+
+		w := httptest.NewRecorder()
+		handler.HandleSendMessage(w, newRequest(`{"content":"hi"}`))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestHandleListConversations(t *testing.T) {
+	orgID, appID, userID := uuid.New(), uuid.New(), uuid.New()
+
+	mockService := new(MockConversationService)
+	handler := NewConversationHandler(mockService, zap.NewNop())
+	mockService.On("ListConversations", mock.Anything, orgID, userID, 10, 20).Return([]*models.Conversation{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/conversations?limit=10&offset=20", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleListConversations(w, withConversationOwner(req, orgID, appID, &userID))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
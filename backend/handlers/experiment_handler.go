@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/experiment"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// CreateExperimentRequest represents a request to create an experiment
+type CreateExperimentRequest struct {
+	Name        string                 `json:"name" validate:"required,max=255"`
+	AppID       *uuid.UUID             `json:"app_id,omitempty"`
+	TargetModel string                 `json:"target_model" validate:"required"`
+	Arms        []models.ExperimentArm `json:"arms" validate:"required,min=2"`
+}
+
+// FeedbackRequest represents user feedback on an inference request
+type FeedbackRequest struct {
+	Score int `json:"score" validate:"gte=-1,lte=1"`
+}
+
+// ExperimentService defines the interface for experiment operations
+type ExperimentService interface {
+	// CreateExperiment creates a new experiment in draft state
+	CreateExperiment(ctx context.Context, req experiment.CreateExperimentRequest) (*models.Experiment, error)
+
+	// GetExperiment retrieves an experiment scoped to an organization
+	GetExperiment(ctx context.Context, id, orgID uuid.UUID) (*models.Experiment, error)
+
+	// ListExperiments lists all experiments for an organization
+	ListExperiments(ctx context.Context, orgID uuid.UUID) ([]*models.Experiment, error)
+
+	// StartExperiment starts assigning traffic to an experiment
+	StartExperiment(ctx context.Context, id, orgID uuid.UUID) (*models.Experiment, error)
+
+	// StopExperiment stops assigning traffic to an experiment
+	StopExperiment(ctx context.Context, id, orgID uuid.UUID) (*models.Experiment, error)
+
+	// GetReport compares metrics across experiment arms
+	GetReport(ctx context.Context, id, orgID uuid.UUID) (*experiment.Report, error)
+
+	// RecordFeedback records user feedback for an inference request
+	RecordFeedback(ctx context.Context, inferenceID, orgID uuid.UUID, score int) error
+}
+
+// ExperimentHandler handles experiment-related HTTP requests
+type ExperimentHandler struct {
+	service ExperimentService
+	logger  *zap.Logger
+}
+
+// NewExperimentHandler creates a new ExperimentHandler
+func NewExperimentHandler(service ExperimentService, logger *zap.Logger) *ExperimentHandler {
+	return &ExperimentHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleListExperiments handles GET /v1/experiments
+func (h *ExperimentHandler) HandleListExperiments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	experiments, err := h.service.ListExperiments(ctx, orgID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, experiments)
+}
+
+// HandleCreateExperiment handles POST /v1/experiments
+func (h *ExperimentHandler) HandleCreateExperiment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := middleware.GetRequestIDFromContext(ctx)
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	var req CreateExperimentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("failed to parse request body",
+			zap.String("request_id", requestID),
+			zap.Error(err))
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	created, err := h.service.CreateExperiment(ctx, experiment.CreateExperimentRequest{
+		OrgID:       orgID,
+		AppID:       req.AppID,
+		Name:        req.Name,
+		TargetModel: req.TargetModel,
+		Arms:        req.Arms,
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	h.logger.Info("experiment created",
+		zap.String("request_id", requestID),
+		zap.String("experiment_id", created.ID.String()))
+
+	_ = utils.WriteCreated(w, created)
+}
+
+// HandleGetExperiment handles GET /v1/experiments/{id}
+func (h *ExperimentHandler) HandleGetExperiment(w http.ResponseWriter, r *http.Request) {
+	h.withExperimentID(w, r, func(ctx context.Context, id, orgID uuid.UUID) (interface{}, error) {
+		return h.service.GetExperiment(ctx, id, orgID)
+	})
+}
+
+// HandleStartExperiment handles POST /v1/experiments/{id}/start
+func (h *ExperimentHandler) HandleStartExperiment(w http.ResponseWriter, r *http.Request) {
+	h.withExperimentID(w, r, func(ctx context.Context, id, orgID uuid.UUID) (interface{}, error) {
+		return h.service.StartExperiment(ctx, id, orgID)
+	})
+}
+
+// HandleStopExperiment handles POST /v1/experiments/{id}/stop
+func (h *ExperimentHandler) HandleStopExperiment(w http.ResponseWriter, r *http.Request) {
+	h.withExperimentID(w, r, func(ctx context.Context, id, orgID uuid.UUID) (interface{}, error) {
+		return h.service.StopExperiment(ctx, id, orgID)
+	})
+}
+
+// HandleGetReport handles GET /v1/experiments/{id}/report
+func (h *ExperimentHandler) HandleGetReport(w http.ResponseWriter, r *http.Request) {
+	h.withExperimentID(w, r, func(ctx context.Context, id, orgID uuid.UUID) (interface{}, error) {
+		return h.service.GetReport(ctx, id, orgID)
+	})
+}
+
+// HandleSubmitFeedback handles POST /v1/inference/requests/{id}/feedback
+func (h *ExperimentHandler) HandleSubmitFeedback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	inferenceID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid inference request ID format", nil)
+		return
+	}
+
+	var req FeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	if err := h.service.RecordFeedback(ctx, inferenceID, orgID, req.Score); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// withExperimentID parses tenant and experiment ID, then writes the result of fn
+func (h *ExperimentHandler) withExperimentID(w http.ResponseWriter, r *http.Request, fn func(ctx context.Context, id, orgID uuid.UUID) (interface{}, error)) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid experiment ID format", nil)
+		return
+	}
+
+	result, err := fn(ctx, id, orgID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, result)
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/experiment"
+	"go.uber.org/zap"
+)
+
+// MockExperimentService is a mock implementation of ExperimentService
+type MockExperimentService struct {
+	mock.Mock
+}
+
+func (m *MockExperimentService) CreateExperiment(ctx context.Context, req experiment.CreateExperimentRequest) (*models.Experiment, error) {
+	args := m.Called(ctx, req)
+	if exp := args.Get(0); exp != nil {
+		return exp.(*models.Experiment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockExperimentService) GetExperiment(ctx context.Context, id, orgID uuid.UUID) (*models.Experiment, error) {
+	args := m.Called(ctx, id, orgID)
+	if exp := args.Get(0); exp != nil {
+		return exp.(*models.Experiment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockExperimentService) ListExperiments(ctx context.Context, orgID uuid.UUID) ([]*models.Experiment, error) {
+	args := m.Called(ctx, orgID)
+	if exps := args.Get(0); exps != nil {
+		return exps.([]*models.Experiment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockExperimentService) StartExperiment(ctx context.Context, id, orgID uuid.UUID) (*models.Experiment, error) {
+	args := m.Called(ctx, id, orgID)
+	if exp := args.Get(0); exp != nil {
+		return exp.(*models.Experiment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockExperimentService) StopExperiment(ctx context.Context, id, orgID uuid.UUID) (*models.Experiment, error) {
+	args := m.Called(ctx, id, orgID)
+	if exp := args.Get(0); exp != nil {
+		return exp.(*models.Experiment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockExperimentService) GetReport(ctx context.Context, id, orgID uuid.UUID) (*experiment.Report, error) {
+	args := m.Called(ctx, id, orgID)
+	if report := args.Get(0); report != nil {
+		return report.(*experiment.Report), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockExperimentService) RecordFeedback(ctx context.Context, inferenceID, orgID uuid.UUID, score int) error {
+	args := m.Called(ctx, inferenceID, orgID, score)
+	return args.Error(0)
+}
+
+func withURLParam(req *http.Request, key, value string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add(key, value)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestHandleCreateExperiment(t *testing.T) {
+	logger := zap.NewNop()
+	orgID := uuid.New()
+
+	t.Run("successful creation", func(t *testing.T) {
+		mockService := new(MockExperimentService)
+		handler := NewExperimentHandler(mockService, logger)
+
+		arms := []models.ExperimentArm{
+			{Name: "control", Model: "gpt-4", Weight: 90},
+			{Name: "cheap", Model: "gpt-4o-mini", Weight: 10},
+		}
+		created, err := models.NewExperiment(orgID, "split", "gpt-4", arms)
+		require.NoError(t, err)
+
+		mockService.On("CreateExperiment", mock.Anything, mock.MatchedBy(func(r experiment.CreateExperimentRequest) bool {
+			return r.OrgID == orgID && r.TargetModel == "gpt-4" && len(r.Arms) == 2
+		})).Return(created, nil)
+
+		body, _ := json.Marshal(CreateExperimentRequest{Name: "split", TargetModel: "gpt-4", Arms: arms})
+		req := httptest.NewRequest(http.MethodPost, "/v1/experiments", bytes.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateExperiment(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("validation error from service", func(t *testing.T) {
+		mockService := new(MockExperimentService)
+		handler := NewExperimentHandler(mockService, logger)
+
+		mockService.On("CreateExperiment", mock.Anything, mock.Anything).
+			Return(nil, services.NewDomainError(services.ErrorTypeValidation, "arm weights must sum to 100, got 60", nil))
+
+		body := []byte(`{"name":"split","target_model":"gpt-4","arms":[{"name":"a","model":"gpt-4","weight":50},{"name":"b","model":"gpt-4o","weight":10}]}`)
+		req := httptest.NewRequest(http.MethodPost, "/v1/experiments", bytes.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateExperiment(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("missing org ID", func(t *testing.T) {
+		handler := NewExperimentHandler(new(MockExperimentService), logger)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/experiments", bytes.NewReader([]byte(`{}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateExperiment(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestHandleGetReport(t *testing.T) {
+	logger := zap.NewNop()
+	orgID := uuid.New()
+	experimentID := uuid.New()
+
+	t.Run("returns report", func(t *testing.T) {
+		mockService := new(MockExperimentService)
+		handler := NewExperimentHandler(mockService, logger)
+
+		mockService.On("GetReport", mock.Anything, experimentID, orgID).Return(&experiment.Report{
+			ExperimentID: experimentID,
+			Arms: []experiment.ArmReport{
+				{Model: "gpt-4", ErrorRate: 0.05},
+				{Model: "gpt-4o-mini", ErrorRate: 0.10},
+			},
+		}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/experiments/"+experimentID.String()+"/report", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		req = withURLParam(req, "id", experimentID.String())
+		w := httptest.NewRecorder()
+
+		handler.HandleGetReport(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		data := response["data"].(map[string]interface{})
+		assert.Len(t, data["arms"], 2)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService := new(MockExperimentService)
+		handler := NewExperimentHandler(mockService, logger)
+
+		mockService.On("GetReport", mock.Anything, experimentID, orgID).
+			Return(nil, services.NewDomainError(services.ErrorTypeNotFound, "experiment not found", nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/experiments/x/report", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		req = withURLParam(req, "id", experimentID.String())
+		w := httptest.NewRecorder()
+
+		handler.HandleGetReport(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		handler := NewExperimentHandler(new(MockExperimentService), logger)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/experiments/bad/report", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		req = withURLParam(req, "id", "bad")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetReport(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleSubmitFeedback(t *testing.T) {
+	logger := zap.NewNop()
+	orgID := uuid.New()
+	inferenceID := uuid.New()
+
+	mockService := new(MockExperimentService)
+	handler := NewExperimentHandler(mockService, logger)
+	mockService.On("RecordFeedback", mock.Anything, inferenceID, orgID, -1).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/inference/requests/x/feedback", bytes.NewReader([]byte(`{"score":-1}`)))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+	req = withURLParam(req, "id", inferenceID.String())
+	w := httptest.NewRecorder()
+
+	handler.HandleSubmitFeedback(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
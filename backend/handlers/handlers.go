@@ -60,86 +60,6 @@ func GetInferenceRequestHandler(deps *app.Dependencies) http.HandlerFunc {
 	}
 }
 
-// ListOrganizationsHandler lists organizations
-func ListOrganizationsHandler(deps *app.Dependencies) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement organization listing
-		respondError(w, http.StatusNotImplemented, "not_implemented", "List organizations endpoint not yet implemented")
-	}
-}
-
-// CreateOrganizationHandler creates a new organization
-func CreateOrganizationHandler(deps *app.Dependencies) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement organization creation
-		respondError(w, http.StatusNotImplemented, "not_implemented", "Create organization endpoint not yet implemented")
-	}
-}
-
-// GetOrganizationHandler gets a specific organization
-func GetOrganizationHandler(deps *app.Dependencies) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement organization retrieval
-		respondError(w, http.StatusNotImplemented, "not_implemented", "Get organization endpoint not yet implemented")
-	}
-}
-
-// UpdateOrganizationHandler updates an organization
-func UpdateOrganizationHandler(deps *app.Dependencies) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement organization update
-		respondError(w, http.StatusNotImplemented, "not_implemented", "Update organization endpoint not yet implemented")
-	}
-}
-
-// DeleteOrganizationHandler deletes an organization
-func DeleteOrganizationHandler(deps *app.Dependencies) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement organization deletion
-		respondError(w, http.StatusNotImplemented, "not_implemented", "Delete organization endpoint not yet implemented")
-	}
-}
-
-// ListApplicationsHandler lists applications
-func ListApplicationsHandler(deps *app.Dependencies) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement application listing
-		respondError(w, http.StatusNotImplemented, "not_implemented", "List applications endpoint not yet implemented")
-	}
-}
-
-// CreateApplicationHandler creates a new application
-func CreateApplicationHandler(deps *app.Dependencies) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement application creation
-		respondError(w, http.StatusNotImplemented, "not_implemented", "Create application endpoint not yet implemented")
-	}
-}
-
-// GetApplicationHandler gets a specific application
-func GetApplicationHandler(deps *app.Dependencies) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement application retrieval
-		respondError(w, http.StatusNotImplemented, "not_implemented", "Get application endpoint not yet implemented")
-	}
-}
-
-// UpdateApplicationHandler updates an application
-func UpdateApplicationHandler(deps *app.Dependencies) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement application update
-		respondError(w, http.StatusNotImplemented, "not_implemented", "Update application endpoint not yet implemented")
-	}
-}
-
-// DeleteApplicationHandler deletes an application
-func DeleteApplicationHandler(deps *app.Dependencies) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement application deletion
-		respondError(w, http.StatusNotImplemented, "not_implemented", "Delete application endpoint not yet implemented")
-	}
-}
-
 // ListPoliciesHandler lists policies
 func ListPoliciesHandler(deps *app.Dependencies) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -196,14 +116,6 @@ func GetAuditLogHandler(deps *app.Dependencies) http.HandlerFunc {
 	}
 }
 
-// ListUsersHandler lists users
-func ListUsersHandler(deps *app.Dependencies) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement user listing
-		respondError(w, http.StatusNotImplemented, "not_implemented", "List users endpoint not yet implemented")
-	}
-}
-
 // CurrentUserResponse is the response body for GET /api/v1/users/me
 type CurrentUserResponse struct {
 	Sub           string   `json:"sub"`
@@ -231,22 +143,6 @@ func GetCurrentUserHandler(deps *app.Dependencies) http.HandlerFunc {
 	}
 }
 
-// GetUserHandler gets a specific user
-func GetUserHandler(deps *app.Dependencies) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement user retrieval
-		respondError(w, http.StatusNotImplemented, "not_implemented", "Get user endpoint not yet implemented")
-	}
-}
-
-// UpdateUserHandler updates a user
-func UpdateUserHandler(deps *app.Dependencies) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement user update
-		respondError(w, http.StatusNotImplemented, "not_implemented", "Update user endpoint not yet implemented")
-	}
-}
-
 // GetInferenceMetricsHandler gets inference metrics
 func GetInferenceMetricsHandler(deps *app.Dependencies) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/services/impersonation"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// ImpersonateUserRequest represents a request to impersonate a user
+type ImpersonateUserRequest struct {
+	Reason          string `json:"reason" validate:"required,max=500"`
+	DurationSeconds int    `json:"duration_seconds,omitempty" validate:"omitempty,min=60,max=3600"`
+}
+
+// ImpersonationService defines the interface for impersonation operations
+type ImpersonationService interface {
+	// Start issues a time-boxed token acting as a user of the organization
+	Start(ctx context.Context, req impersonation.StartRequest) (*impersonation.Token, error)
+}
+
+// ImpersonationHandler handles impersonation HTTP requests
+type ImpersonationHandler struct {
+	service ImpersonationService
+	logger  *zap.Logger
+}
+
+// NewImpersonationHandler creates a new ImpersonationHandler
+func NewImpersonationHandler(service ImpersonationService, logger *zap.Logger) *ImpersonationHandler {
+	return &ImpersonationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleImpersonateUser handles POST /v1/users/{id}/impersonate
+// Requests made with the returned token act as the user and are audited
+// under the caller.
+func (h *ImpersonationHandler) HandleImpersonateUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	claims := middleware.GetClaimsFromContext(ctx)
+	if orgID == uuid.Nil || claims == nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+	if claims.IsImpersonated() {
+		_ = utils.WriteForbidden(w, "Operation not allowed while impersonating")
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid user ID format", nil)
+		return
+	}
+
+	var req ImpersonateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	token, err := h.service.Start(ctx, impersonation.StartRequest{
+		OrgID:    orgID,
+		UserID:   userID,
+		Reason:   req.Reason,
+		Duration: time.Duration(req.DurationSeconds) * time.Second,
+		Impersonator: impersonation.Impersonator{
+			UserID:  middleware.GetUserIDFromContext(ctx),
+			Subject: claims.Sub,
+			Roles:   claims.Groups,
+			Scopes:  claims.Scopes,
+		},
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteCreated(w, token)
+}
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/impersonation"
+	"go.uber.org/zap"
+)
+
+// MockImpersonationService is a mock implementation of ImpersonationService
+type MockImpersonationService struct {
+	mock.Mock
+}
+
+func (m *MockImpersonationService) Start(ctx context.Context, req impersonation.StartRequest) (*impersonation.Token, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*impersonation.Token), args.Error(1)
+}
+
+func TestHandleImpersonateUser(t *testing.T) {
+	orgID := uuid.New()
+	userID := uuid.New()
+	adminID := uuid.New()
+
+	withAdmin := func(req *http.Request) *http.Request {
+		req = withCaller(req, orgID, "admin")
+		return req.WithContext(middleware.WithUserID(req.Context(), &adminID))
+	}
+
+	t.Run("returns the token", func(t *testing.T) {
+		mockService := new(MockImpersonationService)
+		handler := NewImpersonationHandler(mockService, zap.NewNop())
+		mockService.On("Start", mock.Anything, mock.MatchedBy(func(req impersonation.StartRequest) bool {
+			return req.OrgID == orgID && req.UserID == userID && req.Reason == "ticket 4711" &&
+				req.Duration == 10*time.Minute && *req.Impersonator.UserID == adminID &&
+				req.Impersonator.Roles[0] == "admin"
+		})).Return(&impersonation.Token{AccessToken: "jwt", TokenType: "Bearer", ExpiresIn: 600, UserID: userID}, nil)
+
+		body := `{"reason":"ticket 4711","duration_seconds":600}`
+		req := withAdmin(httptest.NewRequest(http.MethodPost, "/api/v1/users/"+userID.String()+"/impersonate", strings.NewReader(body)))
+		w := httptest.NewRecorder()
+
+		handler.HandleImpersonateUser(w, withURLParam(req, "id", userID.String()))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"access_token":"jwt"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("missing reason", func(t *testing.T) {
+		handler := NewImpersonationHandler(new(MockImpersonationService), zap.NewNop())
+
+		req := withAdmin(httptest.NewRequest(http.MethodPost, "/api/v1/users/"+userID.String()+"/impersonate", strings.NewReader(`{}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleImpersonateUser(w, withURLParam(req, "id", userID.String()))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("escalation is forbidden", func(t *testing.T) {
+		mockService := new(MockImpersonationService)
+		handler := NewImpersonationHandler(mockService, zap.NewNop())
+		mockService.On("Start", mock.Anything, mock.Anything).
+			Return(nil, services.NewDomainError(services.ErrorTypeForbidden, "cannot impersonate a user with a permission you do not hold", nil))
+
+		req := withAdmin(httptest.NewRequest(http.MethodPost, "/api/v1/users/"+userID.String()+"/impersonate", strings.NewReader(`{"reason":"r"}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleImpersonateUser(w, withURLParam(req, "id", userID.String()))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("cannot impersonate while impersonating", func(t *testing.T) {
+		mockService := new(MockImpersonationService)
+		handler := NewImpersonationHandler(mockService, zap.NewNop())
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/"+userID.String()+"/impersonate", strings.NewReader(`{"reason":"r"}`))
+		req = withTenant(req, orgID, uuid.Nil)
+		req = req.WithContext(middleware.WithClaims(req.Context(), &middleware.Claims{
+			Sub: uuid.NewString(), OrgID: orgID.String(), Groups: []string{"admin"},
+			Impersonator: &middleware.Impersonator{Sub: adminID.String(), UserID: adminID.String()},
+		}))
+		w := httptest.NewRecorder()
+
+		handler.HandleImpersonateUser(w, withURLParam(req, "id", userID.String()))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "Start", mock.Anything, mock.Anything)
+	})
+}
@@ -8,6 +8,10 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/providers"
 	"github.com/upb/llm-control-plane/backend/utils"
 	"go.uber.org/zap"
 )
@@ -66,6 +70,8 @@ type InferenceRequest struct {
 	OrgID     uuid.UUID
 	AppID     uuid.UUID
 	UserID    *uuid.UUID
+	APIKey    *models.APIKey // Set when the application authenticated with an API key
+	RequestID string
 	Model     string
 	Provider  string // Optional: override routing
 	Messages  []ChatMessage
@@ -161,6 +167,8 @@ func (h *InferenceHandler) HandleChatCompletion(w http.ResponseWriter, r *http.R
 		OrgID:     orgID,
 		AppID:     appID,
 		UserID:    userID,
+		APIKey:    middleware.GetAPIKeyFromContext(ctx),
+		RequestID: requestID,
 		Model:     chatReq.Model,
 		Provider:  chatReq.Provider,
 		Messages:  chatReq.Messages,
@@ -181,7 +189,7 @@ func (h *InferenceHandler) HandleChatCompletion(w http.ResponseWriter, r *http.R
 		h.logger.Error("failed to process chat completion",
 			zap.String("request_id", requestID),
 			zap.Error(err))
-		HandleServiceError(w, err, h.logger)
+		HandleInferenceError(w, err, h.logger)
 		return
 	}
 	
@@ -225,6 +233,77 @@ func (h *InferenceHandler) HandleChatCompletion(w http.ResponseWriter, r *http.R
 	}
 }
 
+// PipelineInferenceService runs the chat completions of InferenceHandler
+// through the inference pipeline
+type PipelineInferenceService struct {
+	completer OpenAICompleter
+}
+
+// NewPipelineInferenceService creates a new PipelineInferenceService
+func NewPipelineInferenceService(completer OpenAICompleter) *PipelineInferenceService {
+	return &PipelineInferenceService{completer: completer}
+}
+
+// ProcessChatCompletion runs a chat completion through the pipeline. The
+// result's request ID is the inference ID, to poll or give feedback on.
+func (s *PipelineInferenceService) ProcessChatCompletion(ctx context.Context, req InferenceRequest) (*InferenceResult, error) {
+	if stream, _ := req.Params["stream"].(bool); stream {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "streaming is only supported by /v1/chat/completions", nil)
+	}
+
+	messages := make([]providers.Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		messages[i] = providers.Message{Role: msg.Role, Content: msg.Content}
+	}
+	completionReq := &inference.CompletionRequest{
+		OrgID:     req.OrgID,
+		AppID:     req.AppID,
+		UserID:    req.UserID,
+		APIKey:    req.APIKey,
+		Model:     req.Model,
+		Messages:  messages,
+		RequestID: req.RequestID,
+		IPAddress: req.IPAddress,
+		UserAgent: req.UserAgent,
+	}
+	if req.Provider != "" {
+		completionReq.Provider = &req.Provider
+	}
+	if maxTokens, ok := req.Params["max_tokens"].(int); ok {
+		completionReq.MaxTokens = maxTokens
+	}
+	if temperature, ok := req.Params["temperature"].(float64); ok {
+		completionReq.Temperature = temperature
+	}
+	if topP, ok := req.Params["top_p"].(float64); ok {
+		completionReq.TopP = topP
+	}
+	if stop, ok := req.Params["stop"].([]string); ok {
+		completionReq.Stop = stop
+	}
+
+	response, err := s.completer.ProcessChatCompletion(ctx, completionReq)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &InferenceResult{
+		RequestID:        response.ID.String(),
+		Provider:         response.Provider,
+		Model:            response.Model,
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+		LatencyMs:        response.LatencyMs,
+		Cost:             response.Cost,
+		PoliciesApplied:  response.PoliciesApplied,
+	}
+	if len(response.Choices) > 0 {
+		result.Response = response.Choices[0].Message.Content
+		result.FinishReason = response.Choices[0].FinishReason
+	}
+	return result, nil
+}
+
 // getClientIP extracts the client IP address from the request
 func getClientIP(r *http.Request) string {
 	// Try X-Forwarded-For first (for proxied requests)
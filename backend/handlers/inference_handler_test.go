@@ -14,6 +14,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/upb/llm-control-plane/backend/middleware"
 	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/providers"
 	"go.uber.org/zap"
 )
 
@@ -400,3 +402,46 @@ func TestGetClientIP(t *testing.T) {
 		})
 	}
 }
+
+func TestPipelineInferenceService(t *testing.T) {
+	orgID, appID := uuid.New(), uuid.New()
+	inferenceID := uuid.New()
+
+	t.Run("runs the pipeline", func(t *testing.T) {
+		completer := new(MockCompleter)
+		service := NewPipelineInferenceService(completer)
+		completer.On("ProcessChatCompletion", mock.Anything, mock.MatchedBy(func(r *inference.CompletionRequest) bool {
+			return r.OrgID == orgID && r.AppID == appID && r.Model == "gpt-4o" && r.MaxTokens == 100 &&
+				r.Temperature == 0.5 && r.Messages[0].Content == "hi"
+		})).Return(&inference.CompletionResponse{
+			ID:       inferenceID,
+			Provider: "openai",
+			Model:    "gpt-4o",
+			Choices:  []inference.Choice{{Message: providers.Message{Role: "assistant", Content: "hello"}, FinishReason: "stop"}},
+			Usage:    inference.Usage{PromptTokens: 3, CompletionTokens: 1, TotalTokens: 4},
+		}, nil)
+
+		result, err := service.ProcessChatCompletion(context.Background(), InferenceRequest{
+			OrgID:    orgID,
+			AppID:    appID,
+			Model:    "gpt-4o",
+			Messages: []ChatMessage{{Role: "user", Content: "hi"}},
+			Params:   map[string]interface{}{"max_tokens": 100, "temperature": 0.5},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, inferenceID.String(), result.RequestID, "the inference ID identifies the request")
+		assert.Equal(t, "hello", result.Response)
+		assert.Equal(t, 3, result.PromptTokens)
+		completer.AssertExpectations(t)
+	})
+
+	t.Run("rejects streaming", func(t *testing.T) {
+		service := NewPipelineInferenceService(new(MockCompleter))
+		_, err := service.ProcessChatCompletion(context.Background(), InferenceRequest{
+			OrgID:  orgID,
+			AppID:  appID,
+			Params: map[string]interface{}{"stream": true},
+		})
+		assert.True(t, services.IsValidationError(err))
+	})
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/upb/llm-control-plane/backend/services/serviceaccount"
+	"github.com/upb/llm-control-plane/backend/tokens"
+	"go.uber.org/zap"
+)
+
+// maxTokenRequestBytes bounds the body of token requests
+const maxTokenRequestBytes = 64 << 10
+
+// ClientCredentialsService issues access tokens to service accounts
+type ClientCredentialsService interface {
+	// IssueToken authenticates a service account and issues it a token limited to scopes
+	IssueToken(ctx context.Context, clientID, clientSecret string, scopes []string) (*serviceaccount.Token, error)
+}
+
+// KeySet publishes the keys gateway-issued tokens are verified with
+type KeySet interface {
+	JWKS() tokens.JWKS
+}
+
+// OAuthError is an OAuth2 error response (RFC 6749 section 5.2)
+type OAuthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// OAuthHandler handles the gateway's OAuth2 token endpoint and key set
+type OAuthHandler struct {
+	service ClientCredentialsService
+	keys    KeySet
+	logger  *zap.Logger
+}
+
+// NewOAuthHandler creates a new OAuthHandler
+func NewOAuthHandler(service ClientCredentialsService, keys KeySet, logger *zap.Logger) *OAuthHandler {
+	return &OAuthHandler{
+		service: service,
+		keys:    keys,
+		logger:  logger,
+	}
+}
+
+// HandleToken handles POST /oauth2/token with the client_credentials grant.
+// Clients authenticate with HTTP Basic auth or client_id and client_secret
+// form parameters; responses follow RFC 6749 rather than the API envelope.
+func (h *OAuthHandler) HandleToken(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxTokenRequestBytes)
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Invalid request body")
+		return
+	}
+
+	grantType := r.PostForm.Get("grant_type")
+	if grantType == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Missing grant_type")
+		return
+	}
+	if grantType != "client_credentials" {
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "Only the client_credentials grant is supported")
+		return
+	}
+
+	clientID, clientSecret, ok := clientCredentials(r)
+	if !ok {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Use exactly one client authentication method")
+		return
+	}
+	if clientID == "" || clientSecret == "" {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth2"`)
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "Missing client credentials")
+		return
+	}
+
+	token, err := h.service.IssueToken(r.Context(), clientID, clientSecret, strings.Fields(r.PostForm.Get("scope")))
+	if err != nil {
+		switch {
+		case errors.Is(err, serviceaccount.ErrInvalidClient):
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth2"`)
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+		case errors.Is(err, serviceaccount.ErrInvalidScope):
+			writeOAuthError(w, http.StatusBadRequest, "invalid_scope", err.Error())
+		default:
+			h.logger.Error("failed to issue service account token", zap.Error(err))
+			writeOAuthError(w, http.StatusInternalServerError, "server_error", "Failed to issue token")
+		}
+		return
+	}
+
+	writeOAuthJSON(w, http.StatusOK, token)
+}
+
+// HandleJWKS handles GET /.well-known/jwks.json
+func (h *OAuthHandler) HandleJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_ = json.NewEncoder(w).Encode(h.keys.JWKS())
+}
+
+// clientCredentials returns the client ID and secret of a token request from
+// the Authorization header or the form; using both is not allowed
+func clientCredentials(r *http.Request) (string, string, bool) {
+	formID, formSecret := r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+
+	basicID, basicSecret, hasBasic := r.BasicAuth()
+	if !hasBasic {
+		return formID, formSecret, true
+	}
+	if formSecret != "" {
+		return "", "", false
+	}
+	// Basic auth credentials are form-encoded (RFC 6749 section 2.3.1)
+	clientID, err := url.QueryUnescape(basicID)
+	if err != nil {
+		return "", "", false
+	}
+	clientSecret, err := url.QueryUnescape(basicSecret)
+	if err != nil {
+		return "", "", false
+	}
+	if formID != "" && formID != clientID {
+		return "", "", false
+	}
+	return clientID, clientSecret, true
+}
+
+// writeOAuthError writes an OAuth2 error response
+func writeOAuthError(w http.ResponseWriter, status int, code, description string) {
+	writeOAuthJSON(w, status, OAuthError{Error: code, ErrorDescription: description})
+}
+
+// writeOAuthJSON writes a token endpoint response, which must not be cached
+func writeOAuthJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
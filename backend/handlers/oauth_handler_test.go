@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/serviceaccount"
+	"github.com/upb/llm-control-plane/backend/tokens"
+	"go.uber.org/zap"
+)
+
+// MockClientCredentialsService is a mock implementation of ClientCredentialsService
+type MockClientCredentialsService struct {
+	mock.Mock
+}
+
+func (m *MockClientCredentialsService) IssueToken(ctx context.Context, clientID, clientSecret string, scopes []string) (*serviceaccount.Token, error) {
+	args := m.Called(ctx, clientID, clientSecret, scopes)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*serviceaccount.Token), args.Error(1)
+}
+
+// staticKeySet publishes a fixed key set
+type staticKeySet tokens.JWKS
+
+func (k staticKeySet) JWKS() tokens.JWKS {
+	return tokens.JWKS(k)
+}
+
+func tokenRequest(form url.Values) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/oauth2/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req
+}
+
+func TestHandleToken(t *testing.T) {
+	token := &serviceaccount.Token{AccessToken: "signed.jwt.token", TokenType: "Bearer", ExpiresIn: 900, Scope: "policies:read"}
+
+	t.Run("client credentials in the form", func(t *testing.T) {
+		mockService := new(MockClientCredentialsService)
+		handler := NewOAuthHandler(mockService, staticKeySet{}, zap.NewNop())
+		mockService.On("IssueToken", mock.Anything, "sa_1", "lcpcs_secret", []string{"policies:read"}).Return(token, nil)
+
+		w := httptest.NewRecorder()
+		handler.HandleToken(w, tokenRequest(url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {"sa_1"},
+			"client_secret": {"lcpcs_secret"},
+			"scope":         {"policies:read"},
+		}))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		var body serviceaccount.Token
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, *token, body)
+	})
+
+	t.Run("client credentials in basic auth", func(t *testing.T) {
+		mockService := new(MockClientCredentialsService)
+		handler := NewOAuthHandler(mockService, staticKeySet{}, zap.NewNop())
+		mockService.On("IssueToken", mock.Anything, "sa_1", "lcpcs_secret", []string{}).Return(token, nil)
+
+		req := tokenRequest(url.Values{"grant_type": {"client_credentials"}})
+		req.SetBasicAuth("sa_1", "lcpcs_secret")
+		w := httptest.NewRecorder()
+		handler.HandleToken(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid client", func(t *testing.T) {
+		mockService := new(MockClientCredentialsService)
+		handler := NewOAuthHandler(mockService, staticKeySet{}, zap.NewNop())
+		mockService.On("IssueToken", mock.Anything, "sa_1", "wrong", []string{}).
+			Return(nil, services.NewDomainError(services.ErrorTypeUnauthorized, "invalid client credentials", serviceaccount.ErrInvalidClient))
+
+		w := httptest.NewRecorder()
+		handler.HandleToken(w, tokenRequest(url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {"sa_1"},
+			"client_secret": {"wrong"},
+		}))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"error":"invalid_client","error_description":"Client authentication failed"}`, w.Body.String())
+	})
+
+	t.Run("invalid scope", func(t *testing.T) {
+		mockService := new(MockClientCredentialsService)
+		handler := NewOAuthHandler(mockService, staticKeySet{}, zap.NewNop())
+		mockService.On("IssueToken", mock.Anything, "sa_1", "lcpcs_secret", []string{"roles:write"}).
+			Return(nil, services.NewDomainError(services.ErrorTypeValidation, "scope not allowed", serviceaccount.ErrInvalidScope))
+
+		w := httptest.NewRecorder()
+		handler.HandleToken(w, tokenRequest(url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {"sa_1"},
+			"client_secret": {"lcpcs_secret"},
+			"scope":         {"roles:write"},
+		}))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"error":"invalid_scope"`)
+	})
+
+	t.Run("malformed requests", func(t *testing.T) {
+		handler := NewOAuthHandler(new(MockClientCredentialsService), staticKeySet{}, zap.NewNop())
+
+		cases := []struct {
+			form   url.Values
+			status int
+			code   string
+		}{
+			{url.Values{"client_id": {"sa_1"}, "client_secret": {"s"}}, http.StatusBadRequest, "invalid_request"},
+			{url.Values{"grant_type": {"password"}, "client_id": {"sa_1"}}, http.StatusBadRequest, "unsupported_grant_type"},
+			{url.Values{"grant_type": {"client_credentials"}, "client_id": {"sa_1"}}, http.StatusUnauthorized, "invalid_client"},
+		}
+		for _, c := range cases {
+			w := httptest.NewRecorder()
+			handler.HandleToken(w, tokenRequest(c.form))
+			assert.Equal(t, c.status, w.Code)
+			assert.Contains(t, w.Body.String(), `"error":"`+c.code+`"`)
+		}
+
+		// Both authentication methods at once
+		req := tokenRequest(url.Values{"grant_type": {"client_credentials"}, "client_secret": {"s"}})
+		req.SetBasicAuth("sa_1", "s")
+		w := httptest.NewRecorder()
+		handler.HandleToken(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleJWKS(t *testing.T) {
+	keys := staticKeySet{Keys: []tokens.JWK{{Kid: "key-1", Kty: "RSA", Alg: "RS256", Use: "sig", N: "n", E: "AQAB"}}}
+	handler := NewOAuthHandler(new(MockClientCredentialsService), keys, zap.NewNop())
+
+	w := httptest.NewRecorder()
+	handler.HandleJWKS(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"keys":[{"kid":"key-1","kty":"RSA","alg":"RS256","use":"sig","n":"n","e":"AQAB"}]}`, w.Body.String())
+}
@@ -0,0 +1,632 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/embedding"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+// OpenAI error types
+const (
+	openAIInvalidRequest    = "invalid_request_error"
+	openAIAuthentication    = "authentication_error"
+	openAIPermission        = "permission_error"
+	openAIRateLimit         = "requests"
+	openAIInsufficientQuota = "insufficient_quota"
+	openAIServerError       = "server_error"
+)
+
+// OpenAIContent is message content, sent by clients either as a string or as
+// an array of content parts. Only text parts are supported.
+type OpenAIContent string
+
+// UnmarshalJSON accepts a string, null or an array of text parts
+func (c *OpenAIContent) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = OpenAIContent(text)
+		return nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("content must be a string or an array of content parts")
+	}
+
+	var b strings.Builder
+	for _, part := range parts {
+		if part.Type != "text" {
+			return fmt.Errorf("content parts of type %q are not supported", part.Type)
+		}
+		b.WriteString(part.Text)
+	}
+	*c = OpenAIContent(b.String())
+	return nil
+}
+
+// OpenAIStop holds stop sequences, sent either as a string or an array
+type OpenAIStop []string
+
+// UnmarshalJSON accepts a string, null or an array of strings
+func (s *OpenAIStop) UnmarshalJSON(data []byte) error {
+	var stop string
+	if err := json.Unmarshal(data, &stop); err == nil {
+		*s = OpenAIStop{stop}
+		return nil
+	}
+
+	var stops []string
+	if err := json.Unmarshal(data, &stops); err != nil {
+		return fmt.Errorf("stop must be a string or an array of strings")
+	}
+	*s = stops
+	return nil
+}
+
+// OpenAIMessage is a message of an OpenAI chat completion request
+type OpenAIMessage struct {
+	Role    string        `json:"role"`
+	Content OpenAIContent `json:"content"`
+	Name    string        `json:"name,omitempty"`
+}
+
+// OpenAIChatRequest is the request body of POST /v1/chat/completions
+type OpenAIChatRequest struct {
+	Model               string          `json:"model"`
+	Messages            []OpenAIMessage `json:"messages"`
+	MaxTokens           *int            `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int            `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64        `json:"temperature,omitempty"`
+	TopP                *float64        `json:"top_p,omitempty"`
+	FrequencyPenalty    *float64        `json:"frequency_penalty,omitempty"`
+	PresencePenalty     *float64        `json:"presence_penalty,omitempty"`
+	Stop                OpenAIStop      `json:"stop,omitempty"`
+	N                   *int            `json:"n,omitempty"`
+	Stream              bool            `json:"stream,omitempty"`
+	StreamOptions       *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options,omitempty"`
+	Tools     json.RawMessage `json:"tools,omitempty"`
+	Functions json.RawMessage `json:"functions,omitempty"`
+	User      string          `json:"user,omitempty"`
+}
+
+// OpenAIResponseMessage is a completion message, or a delta when streaming
+type OpenAIResponseMessage struct {
+	Role    string  `json:"role,omitempty"`
+	Content *string `json:"content,omitempty"`
+}
+
+// OpenAIChoice is a choice of a chat completion or chunk
+type OpenAIChoice struct {
+	Index        int                    `json:"index"`
+	Message      *OpenAIResponseMessage `json:"message,omitempty"`
+	Delta        *OpenAIResponseMessage `json:"delta,omitempty"`
+	Logprobs     *json.RawMessage       `json:"logprobs"`
+	FinishReason *string                `json:"finish_reason"`
+}
+
+// OpenAIUsage reports token usage
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// OpenAIChatCompletion is a chat completion, or a chunk of one when streaming
+type OpenAIChatCompletion struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []OpenAIChoice `json:"choices"`
+	Usage   *OpenAIUsage   `json:"usage,omitempty"`
+}
+
+// OpenAIModel describes a model in GET /v1/models
+type OpenAIModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// OpenAIModelList is the response of GET /v1/models
+type OpenAIModelList struct {
+	Object string        `json:"object"`
+	Data   []OpenAIModel `json:"data"`
+}
+
+// OpenAIEmbeddingInput holds the texts to embed, sent either as a string or an
+// array of strings. Token array inputs are not supported.
+type OpenAIEmbeddingInput []string
+
+// UnmarshalJSON accepts a string or an array of strings
+func (in *OpenAIEmbeddingInput) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*in = OpenAIEmbeddingInput{text}
+		return nil
+	}
+
+	var texts []string
+	if err := json.Unmarshal(data, &texts); err != nil {
+		return fmt.Errorf("input must be a string or an array of strings; token arrays are not supported")
+	}
+	*in = texts
+	return nil
+}
+
+// OpenAIEmbeddingRequest is the request body of POST /v1/embeddings
+type OpenAIEmbeddingRequest struct {
+	Model          string               `json:"model"`
+	Input          OpenAIEmbeddingInput `json:"input"`
+	EncodingFormat string               `json:"encoding_format,omitempty"`
+	Dimensions     *int                 `json:"dimensions,omitempty"`
+	User           string               `json:"user,omitempty"`
+}
+
+// OpenAIEmbedding is one embedding of a POST /v1/embeddings response. The
+// vector is a float array, or a base64 string of little-endian float32s.
+type OpenAIEmbedding struct {
+	Object    string      `json:"object"`
+	Index     int         `json:"index"`
+	Embedding interface{} `json:"embedding"`
+}
+
+// OpenAIEmbeddingResponse is the response of POST /v1/embeddings
+type OpenAIEmbeddingResponse struct {
+	Object string            `json:"object"`
+	Data   []OpenAIEmbedding `json:"data"`
+	Model  string            `json:"model"`
+	Usage  struct {
+		PromptTokens int `json:"prompt_tokens"`
+		TotalTokens  int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// OpenAIErrorResponse is the error body of the OpenAI API
+type OpenAIErrorResponse struct {
+	Error OpenAIError `json:"error"`
+}
+
+// OpenAIError describes an error in the OpenAI API's shape
+type OpenAIError struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Param   *string `json:"param"`
+	Code    *string `json:"code"`
+}
+
+// OpenAICompleter runs chat completions through the inference pipeline
+type OpenAICompleter interface {
+	ProcessChatCompletion(ctx context.Context, req *inference.CompletionRequest) (*inference.CompletionResponse, error)
+}
+
+// ModelCatalog lists the models the inference pipeline routes to
+type ModelCatalog interface {
+	ListModels() []string
+	GetModelInfo(model string) (*providers.ModelInfo, error)
+}
+
+// OpenAIEmbedder embeds texts on behalf of applications
+type OpenAIEmbedder interface {
+	Model() string
+	EmbedForApp(ctx context.Context, req embedding.AppRequest) (*providers.EmbeddingResponse, error)
+}
+
+// OpenAIHandler serves an API surface that is wire-compatible with the
+// official OpenAI SDKs, so applications only need to change their base URL.
+// Requests authenticate with the application's API key.
+type OpenAIHandler struct {
+	completer OpenAICompleter
+	models    ModelCatalog
+	embedder  OpenAIEmbedder
+	logger    *zap.Logger
+}
+
+// NewOpenAIHandler creates a new OpenAIHandler; embedder may be nil, in which
+// case embedding requests are rejected
+func NewOpenAIHandler(completer OpenAICompleter, models ModelCatalog, embedder OpenAIEmbedder, logger *zap.Logger) *OpenAIHandler {
+	return &OpenAIHandler{
+		completer: completer,
+		models:    models,
+		embedder:  embedder,
+		logger:    logger,
+	}
+}
+
+// HandleChatCompletions handles POST /v1/chat/completions
+// Streamed responses are produced by the pipeline in full, so that output
+// policies apply to them, and then sent as server-sent event chunks.
+func (h *OpenAIHandler) HandleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var chatReq OpenAIChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&chatReq); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, openAIInvalidRequest, "", "", "Invalid request body: "+err.Error())
+		return
+	}
+
+	req, param, err := h.completionRequest(&chatReq, r)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, openAIInvalidRequest, param, "", err.Error())
+		return
+	}
+
+	resp, err := h.completer.ProcessChatCompletion(ctx, req)
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	w.Header().Set("X-Request-ID", resp.RequestID)
+	if chatReq.Stream {
+		includeUsage := chatReq.StreamOptions != nil && chatReq.StreamOptions.IncludeUsage
+		h.streamCompletion(w, resp, includeUsage)
+		return
+	}
+
+	completion := openAICompletion(resp, "chat.completion")
+	for i, choice := range resp.Choices {
+		content := choice.Message.Content
+		completion.Choices = append(completion.Choices, OpenAIChoice{
+			Index:        i,
+			Message:      &OpenAIResponseMessage{Role: "assistant", Content: &content},
+			FinishReason: finishReason(choice.FinishReason),
+		})
+	}
+	completion.Usage = &OpenAIUsage{
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		TotalTokens:      resp.Usage.TotalTokens,
+	}
+	writeUnwrappedJSON(w, http.StatusOK, completion)
+}
+
+// HandleListModels handles GET /v1/models
+// Only models the caller's API key may use are listed.
+func (h *OpenAIHandler) HandleListModels(w http.ResponseWriter, r *http.Request) {
+	names := h.models.ListModels()
+	sort.Strings(names)
+
+	models := make([]OpenAIModel, 0, len(names))
+	for _, name := range names {
+		if keyAllowsModel(r, name) {
+			models = append(models, h.model(name))
+		}
+	}
+	writeUnwrappedJSON(w, http.StatusOK, OpenAIModelList{Object: "list", Data: models})
+}
+
+// HandleGetModel handles GET /v1/models/{model}
+func (h *OpenAIHandler) HandleGetModel(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "model")
+	for _, model := range h.models.ListModels() {
+		if model == name && keyAllowsModel(r, name) {
+			writeUnwrappedJSON(w, http.StatusOK, h.model(name))
+			return
+		}
+	}
+	writeOpenAIError(w, http.StatusNotFound, openAIInvalidRequest, "model", "model_not_found",
+		fmt.Sprintf("The model '%s' does not exist", name))
+}
+
+// keyAllowsModel reports whether the request's API key may use a model;
+// keys with unreadable scopes may use none
+func keyAllowsModel(r *http.Request, model string) bool {
+	key := middleware.GetAPIKeyFromContext(r.Context())
+	if key == nil {
+		return true
+	}
+	scopes, err := key.GetScopes()
+	if err != nil {
+		return false
+	}
+	return scopes.AllowsModel(model)
+}
+
+// HandleEmbeddings handles POST /v1/embeddings
+func (h *OpenAIHandler) HandleEmbeddings(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var embedReq OpenAIEmbeddingRequest
+	if err := json.NewDecoder(r.Body).Decode(&embedReq); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, openAIInvalidRequest, "", "", "Invalid request body: "+err.Error())
+		return
+	}
+
+	switch {
+	case h.embedder == nil || embedReq.Model != h.embedder.Model():
+		writeOpenAIError(w, http.StatusNotFound, openAIInvalidRequest, "model", "model_not_found",
+			fmt.Sprintf("The model '%s' does not exist or is not available for embeddings", embedReq.Model))
+		return
+	case len(embedReq.Input) == 0:
+		writeOpenAIError(w, http.StatusBadRequest, openAIInvalidRequest, "input", "", "input must not be empty")
+		return
+	case embedReq.Dimensions != nil:
+		writeOpenAIError(w, http.StatusBadRequest, openAIInvalidRequest, "dimensions", "", "dimensions is not supported")
+		return
+	case embedReq.EncodingFormat != "" && embedReq.EncodingFormat != "float" && embedReq.EncodingFormat != "base64":
+		writeOpenAIError(w, http.StatusBadRequest, openAIInvalidRequest, "encoding_format", "", "encoding_format must be float or base64")
+		return
+	}
+
+	resp, err := h.embedder.EmbedForApp(ctx, embedding.AppRequest{
+		OrgID:     middleware.GetOrgIDFromContext(ctx),
+		AppID:     middleware.GetAppIDFromContext(ctx),
+		RequestID: middleware.GetRequestIDFromContext(ctx),
+		Input:     embedReq.Input,
+	})
+	if err != nil {
+		h.handleError(w, err)
+		return
+	}
+
+	data := make([]OpenAIEmbedding, len(resp.Embeddings))
+	for i, vector := range resp.Embeddings {
+		data[i] = OpenAIEmbedding{Object: "embedding", Index: i, Embedding: vector}
+		if embedReq.EncodingFormat == "base64" {
+			data[i].Embedding = encodeEmbedding(vector)
+		}
+	}
+	response := OpenAIEmbeddingResponse{Object: "list", Data: data, Model: resp.Model}
+	response.Usage.PromptTokens = resp.Usage.PromptTokens
+	response.Usage.TotalTokens = resp.Usage.TotalTokens
+	writeUnwrappedJSON(w, http.StatusOK, response)
+}
+
+// WriteOpenAIUnauthorized rejects a request without a valid API key in the
+// OpenAI API's error shape
+func WriteOpenAIUnauthorized(w http.ResponseWriter, message string) {
+	writeOpenAIError(w, http.StatusUnauthorized, openAIInvalidRequest, "", "invalid_api_key", message)
+}
+
+// completionRequest converts an OpenAI request into a pipeline request. On
+// failure it returns the offending parameter.
+func (h *OpenAIHandler) completionRequest(chatReq *OpenAIChatRequest, r *http.Request) (*inference.CompletionRequest, string, error) {
+	switch {
+	case chatReq.Model == "":
+		return nil, "model", fmt.Errorf("model is required")
+	case len(chatReq.Messages) == 0:
+		return nil, "messages", fmt.Errorf("messages must not be empty")
+	case chatReq.N != nil && *chatReq.N != 1:
+		return nil, "n", fmt.Errorf("only n=1 is supported")
+	case len(chatReq.Tools) > 0 || len(chatReq.Functions) > 0:
+		return nil, "tools", fmt.Errorf("tool calling is not supported")
+	}
+
+	messages := make([]providers.Message, len(chatReq.Messages))
+	for i, msg := range chatReq.Messages {
+		role := msg.Role
+		if role == "developer" {
+			role = "system"
+		}
+		if role != "system" && role != "user" && role != "assistant" {
+			return nil, fmt.Sprintf("messages[%d].role", i), fmt.Errorf("unsupported role %q", msg.Role)
+		}
+		messages[i] = providers.Message{Role: role, Content: string(msg.Content), Name: msg.Name}
+	}
+
+	ctx := r.Context()
+	req := &inference.CompletionRequest{
+		OrgID:     middleware.GetOrgIDFromContext(ctx),
+		AppID:     middleware.GetAppIDFromContext(ctx),
+		APIKey:    middleware.GetAPIKeyFromContext(ctx),
+		Model:     chatReq.Model,
+		Messages:  messages,
+		Stop:      chatReq.Stop,
+		RequestID: middleware.GetRequestIDFromContext(ctx),
+		IPAddress: getClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+	if chatReq.User != "" {
+		req.Metadata = map[string]string{"user": chatReq.User}
+	}
+	if chatReq.MaxCompletionTokens != nil {
+		req.MaxTokens = *chatReq.MaxCompletionTokens
+	} else if chatReq.MaxTokens != nil {
+		req.MaxTokens = *chatReq.MaxTokens
+	}
+	if chatReq.Temperature != nil {
+		req.Temperature = *chatReq.Temperature
+	}
+	if chatReq.TopP != nil {
+		req.TopP = *chatReq.TopP
+	}
+	if chatReq.FrequencyPenalty != nil {
+		req.FrequencyPenalty = *chatReq.FrequencyPenalty
+	}
+	if chatReq.PresencePenalty != nil {
+		req.PresencePenalty = *chatReq.PresencePenalty
+	}
+	return req, "", nil
+}
+
+// streamCompletion sends a completed response as chat.completion.chunk events
+func (h *OpenAIHandler) streamCompletion(w http.ResponseWriter, resp *inference.CompletionResponse, includeUsage bool) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	empty := ""
+	chunks := []OpenAIChoice{{Delta: &OpenAIResponseMessage{Role: "assistant", Content: &empty}}}
+	for i, choice := range resp.Choices {
+		content := choice.Message.Content
+		chunks = append(chunks,
+			OpenAIChoice{Index: i, Delta: &OpenAIResponseMessage{Content: &content}},
+			OpenAIChoice{Index: i, Delta: &OpenAIResponseMessage{}, FinishReason: finishReason(choice.FinishReason)})
+	}
+
+	controller := http.NewResponseController(w)
+	for _, choice := range chunks {
+		chunk := openAICompletion(resp, "chat.completion.chunk")
+		chunk.Choices = []OpenAIChoice{choice}
+		if err := writeEvent(w, chunk); err != nil {
+			h.logger.Warn("failed to stream chat completion", zap.Error(err))
+			return
+		}
+		_ = controller.Flush()
+	}
+
+	if includeUsage {
+		chunk := openAICompletion(resp, "chat.completion.chunk")
+		chunk.Choices = []OpenAIChoice{}
+		chunk.Usage = &OpenAIUsage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		}
+		_ = writeEvent(w, chunk)
+	}
+
+	_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	_ = controller.Flush()
+}
+
+// handleError writes a pipeline or service error in the OpenAI API's shape
+func (h *OpenAIHandler) handleError(w http.ResponseWriter, err error) {
+	var inferenceErr *inference.InferenceError
+	if errors.As(err, &inferenceErr) {
+		status := inferenceErr.StatusCode
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		if status >= http.StatusInternalServerError {
+			h.logger.Error("inference failed", zap.String("code", inferenceErr.Code), zap.Error(err))
+		}
+
+		errType := openAIErrorType(status)
+		if inferenceErr.Code == inference.ErrCodeBudgetExceeded {
+			// OpenAI reports exhausted quota as 429 insufficient_quota
+			status, errType = http.StatusTooManyRequests, openAIInsufficientQuota
+		}
+		writeOpenAIError(w, status, errType, "", strings.ToLower(inferenceErr.Code), inferenceErr.Message)
+		return
+	}
+
+	status := http.StatusInternalServerError
+	message := "The server had an error while processing your request"
+	switch {
+	case services.IsValidationError(err):
+		status, message = http.StatusBadRequest, err.Error()
+	case services.IsNotFoundError(err):
+		status, message = http.StatusNotFound, err.Error()
+	case services.IsRateLimitError(err), services.IsBudgetError(err):
+		status, message = http.StatusTooManyRequests, err.Error()
+	case services.IsForbiddenError(err), services.IsPolicyViolationError(err):
+		status, message = http.StatusForbidden, err.Error()
+	default:
+		h.logger.Error("request failed", zap.Error(err))
+	}
+	writeOpenAIError(w, status, openAIErrorType(status), "", "", message)
+}
+
+// model describes a model, owned by the provider that serves it
+func (h *OpenAIHandler) model(name string) OpenAIModel {
+	model := OpenAIModel{ID: name, Object: "model", OwnedBy: "system"}
+	if info, err := h.models.GetModelInfo(name); err == nil && info.Provider != "" {
+		model.OwnedBy = info.Provider
+	}
+	return model
+}
+
+// openAICompletion returns a completion or chunk without choices
+func openAICompletion(resp *inference.CompletionResponse, object string) OpenAIChatCompletion {
+	created := resp.CreatedAt
+	if created.IsZero() {
+		created = time.Now()
+	}
+	return OpenAIChatCompletion{
+		ID:      "chatcmpl-" + resp.ID.String(),
+		Object:  object,
+		Created: created.Unix(),
+		Model:   resp.Model,
+		Choices: []OpenAIChoice{},
+	}
+}
+
+// finishReason defaults a missing finish reason to "stop"
+func finishReason(reason string) *string {
+	if reason == "" {
+		reason = "stop"
+	}
+	return &reason
+}
+
+// openAIErrorType maps a status code to an OpenAI error type
+func openAIErrorType(status int) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return openAIAuthentication
+	case status == http.StatusForbidden:
+		return openAIPermission
+	case status == http.StatusTooManyRequests:
+		return openAIRateLimit
+	case status >= http.StatusInternalServerError:
+		return openAIServerError
+	default:
+		return openAIInvalidRequest
+	}
+}
+
+// encodeEmbedding encodes a vector as base64 of little-endian float32s
+func encodeEmbedding(vector []float64) string {
+	buf := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(float32(v)))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// writeEvent writes a server-sent event carrying data as JSON
+func writeEvent(w http.ResponseWriter, data interface{}) error {
+	var buf bytes.Buffer
+	buf.WriteString("data: ")
+	if err := json.NewEncoder(&buf).Encode(data); err != nil {
+		return err
+	}
+	buf.WriteString("\n")
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeUnwrappedJSON writes a JSON response without the gateway's envelope
+func writeUnwrappedJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+// writeOpenAIError writes an error in the OpenAI API's shape
+func writeOpenAIError(w http.ResponseWriter, status int, errType, param, code, message string) {
+	apiErr := OpenAIError{Message: message, Type: errType}
+	if param != "" {
+		apiErr.Param = &param
+	}
+	if code != "" {
+		apiErr.Code = &code
+	}
+	writeUnwrappedJSON(w, status, OpenAIErrorResponse{Error: apiErr})
+}
@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/services/embedding"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+// MockCompleter is a mock implementation of OpenAICompleter
+type MockCompleter struct {
+	mock.Mock
+}
+
+func (m *MockCompleter) ProcessChatCompletion(ctx context.Context, req *inference.CompletionRequest) (*inference.CompletionResponse, error) {
+	args := m.Called(ctx, req)
+	if r := args.Get(0); r != nil {
+		return r.(*inference.CompletionResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+// fakeModelCatalog serves model info from a map
+type fakeModelCatalog map[string]string
+
+func (c fakeModelCatalog) ListModels() []string {
+	models := make([]string, 0, len(c))
+	for model := range c {
+		models = append(models, model)
+	}
+	return models
+}
+
+func (c fakeModelCatalog) GetModelInfo(model string) (*providers.ModelInfo, error) {
+	if provider, ok := c[model]; ok {
+		return &providers.ModelInfo{ID: model, Provider: provider}, nil
+	}
+	return nil, fmt.Errorf("model not found: %s", model)
+}
+
+// fakeEmbedder embeds each input as [index, 0.5]
+type fakeEmbedder struct {
+	requests []embedding.AppRequest
+}
+
+func (e *fakeEmbedder) Model() string {
+	return "text-embedding-3-small"
+}
+
+func (e *fakeEmbedder) EmbedForApp(ctx context.Context, req embedding.AppRequest) (*providers.EmbeddingResponse, error) {
+	e.requests = append(e.requests, req)
+	resp := &providers.EmbeddingResponse{Provider: "openai", Model: e.Model()}
+	for i := range req.Input {
+		resp.Embeddings = append(resp.Embeddings, []float64{float64(i), 0.5})
+	}
+	resp.Usage.PromptTokens = len(req.Input)
+	resp.Usage.TotalTokens = len(req.Input)
+	return resp, nil
+}
+
+func completionResponse(content string) *inference.CompletionResponse {
+	return &inference.CompletionResponse{
+		ID:        uuid.New(),
+		RequestID: "req-1",
+		Provider:  "openai",
+		Model:     "gpt-4o",
+		Choices: []inference.Choice{{
+			Message:      providers.Message{Role: "assistant", Content: content},
+			FinishReason: "stop",
+		}},
+		Usage:     inference.Usage{PromptTokens: 5, CompletionTokens: 2, TotalTokens: 7},
+		CreatedAt: time.Unix(1700000000, 0),
+	}
+}
+
+func newOpenAIFixture() (*OpenAIHandler, *MockCompleter, *fakeEmbedder) {
+	completer := new(MockCompleter)
+	embedder := &fakeEmbedder{}
+	catalog := fakeModelCatalog{"gpt-4o": "openai", "claude-3-5-sonnet": "anthropic"}
+	return NewOpenAIHandler(completer, catalog, embedder, zap.NewNop()), completer, embedder
+}
+
+func TestOpenAIHandler_ChatCompletions(t *testing.T) {
+	orgID, appID := uuid.New(), uuid.New()
+
+	t.Run("completion", func(t *testing.T) {
+		handler, completer, _ := newOpenAIFixture()
+		completer.On("ProcessChatCompletion", mock.Anything, mock.MatchedBy(func(r *inference.CompletionRequest) bool {
+			return r.OrgID == orgID && r.AppID == appID && r.Model == "gpt-4o" &&
+				r.MaxTokens == 50 && r.Temperature == 0.2 &&
+				len(r.Messages) == 2 && r.Messages[0].Role == "system" && r.Messages[1].Content == "Hello world" &&
+				len(r.Stop) == 1 && r.Stop[0] == "END"
+		})).Return(completionResponse("Hi!"), nil)
+
+		body := `{
+			"model": "gpt-4o",
+			"messages": [
+				{"role": "developer", "content": "Be brief"},
+				{"role": "user", "content": [{"type": "text", "text": "Hello "}, {"type": "text", "text": "world"}]}
+			],
+			"max_completion_tokens": 50,
+			"temperature": 0.2,
+			"stop": "END"
+		}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.HandleChatCompletions(w, withTenant(req, orgID, appID))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var completion map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&completion))
+		assert.Equal(t, "chat.completion", completion["object"])
+		assert.Contains(t, completion["id"], "chatcmpl-")
+		assert.Equal(t, float64(1700000000), completion["created"])
+		choice := completion["choices"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{"role": "assistant", "content": "Hi!"}, choice["message"])
+		assert.Equal(t, "stop", choice["finish_reason"])
+		assert.Contains(t, choice, "logprobs")
+		assert.Equal(t, map[string]interface{}{"prompt_tokens": float64(5), "completion_tokens": float64(2), "total_tokens": float64(7)}, completion["usage"])
+		completer.AssertExpectations(t)
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		handler, completer, _ := newOpenAIFixture()
+		completer.On("ProcessChatCompletion", mock.Anything, mock.Anything).Return(completionResponse("Hi!"), nil)
+
+		body := `{"model":"gpt-4o","messages":[{"role":"user","content":"Hello"}],"stream":true,"stream_options":{"include_usage":true}}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.HandleChatCompletions(w, withTenant(req, orgID, appID))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+		var events []string
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "data: ") {
+				events = append(events, strings.TrimPrefix(line, "data: "))
+			}
+		}
+		require.Len(t, events, 5)
+		assert.Equal(t, "[DONE]", events[4])
+
+		var content strings.Builder
+		var finish interface{}
+		for _, event := range events[:3] {
+			var chunk map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(event), &chunk))
+			assert.Equal(t, "chat.completion.chunk", chunk["object"])
+			choice := chunk["choices"].([]interface{})[0].(map[string]interface{})
+			if text, ok := choice["delta"].(map[string]interface{})["content"].(string); ok {
+				content.WriteString(text)
+			}
+			if choice["finish_reason"] != nil {
+				finish = choice["finish_reason"]
+			}
+		}
+		assert.Equal(t, "Hi!", content.String())
+		assert.Equal(t, "stop", finish)
+
+		var usageChunk map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(events[3]), &usageChunk))
+		assert.Empty(t, usageChunk["choices"])
+		assert.Equal(t, float64(7), usageChunk["usage"].(map[string]interface{})["total_tokens"])
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		handler, _, _ := newOpenAIFixture()
+
+		for body, param := range map[string]string{
+			`{"messages":[{"role":"user","content":"hi"}]}`:                                    "model",
+			`{"model":"gpt-4o","messages":[]}`:                                                 "messages",
+			`{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}],"n":2}`:             "n",
+			`{"model":"gpt-4o","messages":[{"role":"tool","content":"hi"}]}`:                   "messages[0].role",
+			`{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}],"tools":[{"a":1}]}`: "tools",
+		} {
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+			w := httptest.NewRecorder()
+
+			handler.HandleChatCompletions(w, withTenant(req, orgID, appID))
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+			var resp OpenAIErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+			assert.Equal(t, "invalid_request_error", resp.Error.Type)
+			require.NotNil(t, resp.Error.Param, body)
+			assert.Equal(t, param, *resp.Error.Param)
+		}
+	})
+
+	t.Run("pipeline errors", func(t *testing.T) {
+		tests := []struct {
+			err     error
+			status  int
+			errType string
+			code    string
+		}{
+			{inference.NewContextLengthError("too long", nil), http.StatusBadRequest, "invalid_request_error", "context_length_exceeded"},
+			{inference.NewRateLimitError("slow down", nil), http.StatusTooManyRequests, "requests", "rate_limit_exceeded"},
+			{inference.NewBudgetError("budget exceeded", nil), http.StatusTooManyRequests, "insufficient_quota", "budget_exceeded"},
+			{inference.NewProviderError("upstream failed", nil, true), http.StatusBadGateway, "server_error", "provider_error"},
+		}
+
+		for _, tt := range tests {
+			handler, completer, _ := newOpenAIFixture()
+			completer.On("ProcessChatCompletion", mock.Anything, mock.Anything).Return(nil, tt.err)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions",
+				strings.NewReader(`{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`))
+			w := httptest.NewRecorder()
+
+			handler.HandleChatCompletions(w, withTenant(req, orgID, appID))
+
+			var resp OpenAIErrorResponse
+			require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+			assert.Equal(t, tt.errType, resp.Error.Type, tt.code)
+			require.NotNil(t, resp.Error.Code)
+			assert.Equal(t, tt.code, *resp.Error.Code)
+			assert.Equal(t, tt.status, w.Code, tt.code)
+		}
+	})
+}
+
+func TestOpenAIHandler_Models(t *testing.T) {
+	handler, _, _ := newOpenAIFixture()
+
+	w := httptest.NewRecorder()
+	handler.HandleListModels(w, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var list OpenAIModelList
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&list))
+	assert.Equal(t, "list", list.Object)
+	require.Len(t, list.Data, 2)
+	assert.Equal(t, OpenAIModel{ID: "claude-3-5-sonnet", Object: "model", OwnedBy: "anthropic"}, list.Data[0])
+
+	w = httptest.NewRecorder()
+	handler.HandleGetModel(w, withURLParam(httptest.NewRequest(http.MethodGet, "/v1/models/gpt-4o", nil), "model", "gpt-4o"))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.HandleGetModel(w, withURLParam(httptest.NewRequest(http.MethodGet, "/v1/models/x", nil), "model", "x"))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"model_not_found"`)
+}
+
+func TestOpenAIHandler_Embeddings(t *testing.T) {
+	orgID, appID := uuid.New(), uuid.New()
+
+	t.Run("float", func(t *testing.T) {
+		handler, _, embedder := newOpenAIFixture()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/embeddings",
+			strings.NewReader(`{"model":"text-embedding-3-small","input":["a","b"]}`))
+		w := httptest.NewRecorder()
+
+		handler.HandleEmbeddings(w, withTenant(req, orgID, appID))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Object string `json:"object"`
+			Data   []struct {
+				Object    string    `json:"object"`
+				Index     int       `json:"index"`
+				Embedding []float64 `json:"embedding"`
+			} `json:"data"`
+			Usage map[string]int `json:"usage"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Data, 2)
+		assert.Equal(t, []float64{1, 0.5}, resp.Data[1].Embedding)
+		assert.Equal(t, 2, resp.Usage["total_tokens"])
+
+		require.Len(t, embedder.requests, 1)
+		assert.Equal(t, appID, embedder.requests[0].AppID)
+	})
+
+	t.Run("base64", func(t *testing.T) {
+		handler, _, _ := newOpenAIFixture()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/embeddings",
+			strings.NewReader(`{"model":"text-embedding-3-small","input":"a","encoding_format":"base64"}`))
+		w := httptest.NewRecorder()
+
+		handler.HandleEmbeddings(w, withTenant(req, orgID, appID))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data []struct {
+				Embedding string `json:"embedding"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		raw, err := base64.StdEncoding.DecodeString(resp.Data[0].Embedding)
+		require.NoError(t, err)
+		vector := make([]float32, len(raw)/4)
+		require.NoError(t, binary.Read(bytes.NewReader(raw), binary.LittleEndian, vector))
+		assert.Equal(t, []float32{0, 0.5}, vector)
+	})
+
+	t.Run("unknown model", func(t *testing.T) {
+		handler, _, _ := newOpenAIFixture()
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(`{"model":"other","input":"a"}`))
+		w := httptest.NewRecorder()
+
+		handler.HandleEmbeddings(w, withTenant(req, orgID, appID))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestWriteOpenAIUnauthorized(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteOpenAIUnauthorized(w, "Invalid API key")
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error":{"message":"Invalid API key","type":"invalid_request_error","param":null,"code":"invalid_api_key"}}`, w.Body.String())
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/services/ingestion"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// IngestDocumentRequest represents a request to add or replace a RAG document
+type IngestDocumentRequest struct {
+	DocumentID   string                 `json:"document_id" validate:"required,max=255"`
+	Content      string                 `json:"content" validate:"required"`
+	ContentType  string                 `json:"content_type,omitempty" validate:"omitempty,oneof=text markdown html"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	ChunkSize    int                    `json:"chunk_size,omitempty" validate:"gte=0,lte=8000"`
+	ChunkOverlap *int                   `json:"chunk_overlap,omitempty" validate:"omitempty,gte=0"`
+}
+
+// IngestionService defines the interface for RAG document ingestion
+type IngestionService interface {
+	// Ingest chunks, embeds and stores a document
+	Ingest(ctx context.Context, req ingestion.IngestRequest) (*ingestion.IngestResult, error)
+
+	// Delete removes a document from an index
+	Delete(ctx context.Context, orgID uuid.UUID, indexName, documentID string) error
+}
+
+// RAGHandler handles RAG index HTTP requests
+type RAGHandler struct {
+	service IngestionService
+	logger  *zap.Logger
+}
+
+// NewRAGHandler creates a new RAGHandler
+func NewRAGHandler(service IngestionService, logger *zap.Logger) *RAGHandler {
+	return &RAGHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleIngestDocument handles POST /v1/rag/indexes/{name}/documents
+func (h *RAGHandler) HandleIngestDocument(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := middleware.GetRequestIDFromContext(ctx)
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	var req IngestDocumentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("failed to parse request body",
+			zap.String("request_id", requestID),
+			zap.Error(err))
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	result, err := h.service.Ingest(ctx, ingestion.IngestRequest{
+		OrgID:        orgID,
+		IndexName:    chi.URLParam(r, "name"),
+		DocumentID:   req.DocumentID,
+		Content:      req.Content,
+		ContentType:  req.ContentType,
+		Metadata:     req.Metadata,
+		ChunkSize:    req.ChunkSize,
+		ChunkOverlap: req.ChunkOverlap,
+		RequestID:    requestID,
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	if result.Unchanged {
+		_ = utils.WriteOK(w, result)
+		return
+	}
+	_ = utils.WriteCreated(w, result)
+}
+
+// HandleDeleteDocument handles DELETE /v1/rag/indexes/{name}/documents/{documentID}
+func (h *RAGHandler) HandleDeleteDocument(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	if err := h.service.Delete(ctx, orgID, chi.URLParam(r, "name"), chi.URLParam(r, "documentID")); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
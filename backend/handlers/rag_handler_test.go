@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/ingestion"
+	"go.uber.org/zap"
+)
+
+// MockIngestionService is a mock implementation of IngestionService
+type MockIngestionService struct {
+	mock.Mock
+}
+
+func (m *MockIngestionService) Ingest(ctx context.Context, req ingestion.IngestRequest) (*ingestion.IngestResult, error) {
+	args := m.Called(ctx, req)
+	if r := args.Get(0); r != nil {
+		return r.(*ingestion.IngestResult), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockIngestionService) Delete(ctx context.Context, orgID uuid.UUID, indexName, documentID string) error {
+	args := m.Called(ctx, orgID, indexName, documentID)
+	return args.Error(0)
+}
+
+func TestHandleIngestDocument(t *testing.T) {
+	logger := zap.NewNop()
+	orgID := uuid.New()
+
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/v1/rag/indexes/handbook/documents", bytes.NewReader([]byte(body)))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		return withURLParam(req, "name", "handbook")
+	}
+
+	t.Run("ingests document", func(t *testing.T) {
+		mockService := new(MockIngestionService)
+		handler := NewRAGHandler(mockService, logger)
+
+		mockService.On("Ingest", mock.Anything, mock.MatchedBy(func(r ingestion.IngestRequest) bool {
+			return r.OrgID == orgID && r.IndexName == "handbook" && r.DocumentID == "faq" &&
+				r.ContentType == "markdown" && r.ChunkOverlap != nil && *r.ChunkOverlap == 0
+		})).Return(&ingestion.IngestResult{Document: &models.RAGDocument{DocumentID: "faq"}}, nil)
+
+		w := httptest.NewRecorder()
+		handler.HandleIngestDocument(w, newRequest(`{"document_id":"faq","content":"# FAQ","content_type":"markdown","chunk_overlap":0}`))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("unchanged document", func(t *testing.T) {
+		mockService := new(MockIngestionService)
+		handler := NewRAGHandler(mockService, logger)
+
+		mockService.On("Ingest", mock.Anything, mock.Anything).
+			Return(&ingestion.IngestResult{Document: &models.RAGDocument{DocumentID: "faq"}, Unchanged: true}, nil)
+
+		w := httptest.NewRecorder()
+		handler.HandleIngestDocument(w, newRequest(`{"document_id":"faq","content":"FAQ"}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("unsupported content type", func(t *testing.T) {
+		handler := NewRAGHandler(new(MockIngestionService), logger)
+
+		w := httptest.NewRecorder()
+		handler.HandleIngestDocument(w, newRequest(`{"document_id":"faq","content":"FAQ","content_type":"pdf"}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleDeleteDocument(t *testing.T) {
+	logger := zap.NewNop()
+	orgID := uuid.New()
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodDelete, "/v1/rag/indexes/handbook/documents/faq", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		return withURLParams(req, map[string]string{"name": "handbook", "documentID": "faq"})
+	}
+
+	t.Run("deletes document", func(t *testing.T) {
+		mockService := new(MockIngestionService)
+		handler := NewRAGHandler(mockService, logger)
+		mockService.On("Delete", mock.Anything, orgID, "handbook", "faq").Return(nil)
+
+		w := httptest.NewRecorder()
+		handler.HandleDeleteDocument(w, newRequest())
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		mockService := new(MockIngestionService)
+		handler := NewRAGHandler(mockService, logger)
+		mockService.On("Delete", mock.Anything, orgID, "handbook", "faq").
+			Return(services.NewDomainError(services.ErrorTypeNotFound, "document not found", nil))
+
+		w := httptest.NewRecorder()
+		handler.HandleDeleteDocument(w, newRequest())
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/rbac"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// CreateRoleRequest represents a request to create a custom role
+type CreateRoleRequest struct {
+	Name        string              `json:"name" validate:"required,max=50"`
+	Description string              `json:"description,omitempty"`
+	Permissions []models.Permission `json:"permissions" validate:"required"`
+}
+
+// UpdateRoleRequest represents a request to replace a role's permissions
+type UpdateRoleRequest struct {
+	Description *string             `json:"description,omitempty"`
+	Permissions []models.Permission `json:"permissions" validate:"required"`
+}
+
+// RoleService defines the interface for role operations
+type RoleService interface {
+	// ListRoles lists the organization's effective roles
+	ListRoles(ctx context.Context, orgID uuid.UUID) ([]*models.Role, error)
+
+	// CreateRole creates a custom role
+	CreateRole(ctx context.Context, req rbac.CreateRoleRequest) (*models.Role, error)
+
+	// UpdateRole replaces a role's permissions, overriding built-in roles
+	UpdateRole(ctx context.Context, req rbac.UpdateRoleRequest) (*models.Role, error)
+
+	// DeleteRole deletes a custom role or the override of a built-in role
+	DeleteRole(ctx context.Context, orgID uuid.UUID, name string, deletedBy *uuid.UUID) error
+}
+
+// RoleHandler handles role HTTP requests
+type RoleHandler struct {
+	service RoleService
+	logger  *zap.Logger
+}
+
+// NewRoleHandler creates a new RoleHandler
+func NewRoleHandler(service RoleService, logger *zap.Logger) *RoleHandler {
+	return &RoleHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleListRoles handles GET /v1/roles
+func (h *RoleHandler) HandleListRoles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	roles, err := h.service.ListRoles(ctx, orgID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, roles)
+}
+
+// HandleListPermissions handles GET /v1/roles/permissions
+func (h *RoleHandler) HandleListPermissions(w http.ResponseWriter, r *http.Request) {
+	_ = utils.WriteOK(w, models.Permissions)
+}
+
+// HandleCreateRole handles POST /v1/roles
+func (h *RoleHandler) HandleCreateRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	var req CreateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	role, err := h.service.CreateRole(ctx, rbac.CreateRoleRequest{
+		OrgID:       orgID,
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+		CreatedBy:   middleware.GetActorIDFromContext(ctx),
+		Grantor:     roleGrantor(r),
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteCreated(w, role)
+}
+
+// HandleUpdateRole handles PUT /v1/roles/{name}
+func (h *RoleHandler) HandleUpdateRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	role, err := h.service.UpdateRole(ctx, rbac.UpdateRoleRequest{
+		OrgID:       orgID,
+		Name:        chi.URLParam(r, "name"),
+		Description: req.Description,
+		Permissions: req.Permissions,
+		UpdatedBy:   middleware.GetActorIDFromContext(ctx),
+		Grantor:     roleGrantor(r),
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, role)
+}
+
+// HandleDeleteRole handles DELETE /v1/roles/{name}
+func (h *RoleHandler) HandleDeleteRole(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	if err := h.service.DeleteRole(ctx, orgID, chi.URLParam(r, "name"), middleware.GetActorIDFromContext(ctx)); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// roleGrantor returns the caller's roles and scopes, which bound the
+// permissions it may grant a role
+func roleGrantor(r *http.Request) rbac.Grantor {
+	claims := middleware.GetClaimsFromContext(r.Context())
+	if claims == nil {
+		return rbac.Grantor{}
+	}
+	return rbac.Grantor{Roles: claims.Groups, Scopes: claims.Scopes}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/rbac"
+	"go.uber.org/zap"
+)
+
+// MockRoleService is a mock implementation of RoleService
+type MockRoleService struct {
+	mock.Mock
+}
+
+func (m *MockRoleService) ListRoles(ctx context.Context, orgID uuid.UUID) ([]*models.Role, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Role), args.Error(1)
+}
+
+func (m *MockRoleService) CreateRole(ctx context.Context, req rbac.CreateRoleRequest) (*models.Role, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+
+func (m *MockRoleService) UpdateRole(ctx context.Context, req rbac.UpdateRoleRequest) (*models.Role, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Role), args.Error(1)
+}
+
+func (m *MockRoleService) DeleteRole(ctx context.Context, orgID uuid.UUID, name string, deletedBy *uuid.UUID) error {
+	args := m.Called(ctx, orgID, name, deletedBy)
+	return args.Error(0)
+}
+
+func TestHandleCreateRole(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("creates a custom role", func(t *testing.T) {
+		mockService := new(MockRoleService)
+		handler := NewRoleHandler(mockService, zap.NewNop())
+		role, _ := models.NewRole(&orgID, "auditor", "", []models.Permission{models.PermissionAuditRead})
+		mockService.On("CreateRole", mock.Anything, mock.MatchedBy(func(req rbac.CreateRoleRequest) bool {
+			return req.OrgID == orgID && req.Name == "auditor" && len(req.Permissions) == 1 && req.Permissions[0] == models.PermissionAuditRead
+		})).Return(role, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/roles", bytes.NewReader([]byte(`{"name":"auditor","permissions":["audit:read"]}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateRole(w, withTenant(req, orgID, uuid.Nil))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"audit:read"`)
+	})
+
+	t.Run("non-admin cannot grant all permissions", func(t *testing.T) {
+		mockService := new(MockRoleService)
+		handler := NewRoleHandler(mockService, zap.NewNop())
+		mockService.On("CreateRole", mock.Anything, mock.MatchedBy(func(req rbac.CreateRoleRequest) bool {
+			return len(req.Grantor.Roles) == 1 && req.Grantor.Roles[0] == "member"
+		})).Return(nil, services.NewDomainError(services.ErrorTypeForbidden, "cannot grant a permission you do not hold: *", nil))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/roles", bytes.NewReader([]byte(`{"name":"superuser","permissions":["*"]}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateRole(w, withCaller(req, orgID, "member"))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("requires permissions", func(t *testing.T) {
+		handler := NewRoleHandler(new(MockRoleService), zap.NewNop())
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/roles", bytes.NewReader([]byte(`{"name":"auditor"}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateRole(w, withTenant(req, orgID, uuid.Nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleUpdateRole(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("admin cannot be changed", func(t *testing.T) {
+		mockService := new(MockRoleService)
+		handler := NewRoleHandler(mockService, zap.NewNop())
+		mockService.On("UpdateRole", mock.Anything, mock.MatchedBy(func(req rbac.UpdateRoleRequest) bool {
+			return req.Name == "admin"
+		})).Return(nil, services.NewDomainError(services.ErrorTypeForbidden, "the admin role cannot be changed", nil))
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/roles/admin", bytes.NewReader([]byte(`{"permissions":[]}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleUpdateRole(w, withURLParam(withTenant(req, orgID, uuid.Nil), "name", "admin"))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestHandleDeleteRole(t *testing.T) {
+	orgID := uuid.New()
+	mockService := new(MockRoleService)
+	handler := NewRoleHandler(mockService, zap.NewNop())
+	mockService.On("DeleteRole", mock.Anything, orgID, "auditor", mock.Anything).Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/roles/auditor", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleDeleteRole(w, withURLParam(withTenant(req, orgID, uuid.Nil), "name", "auditor"))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
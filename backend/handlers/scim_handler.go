@@ -0,0 +1,978 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/scim"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// SCIM schema URNs (RFC 7643, RFC 7644)
+const (
+	SCIMUserSchema                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMGroupSchema                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMListResponseSchema          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMPatchOpSchema               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMErrorSchema                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	SCIMServiceProviderConfigSchema = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+)
+
+const (
+	// scimContentType is the media type of SCIM responses
+	scimContentType = "application/scim+json"
+
+	// scimDefaultCount and scimMaxCount bound the resources of a list response
+	scimDefaultCount = 100
+	scimMaxCount     = 1000
+)
+
+// scimFilterPattern matches the equality filters identity providers use to
+// look up resources, e.g. userName eq "ada@example.com"
+var scimFilterPattern = regexp.MustCompile(`(?i)^\s*([a-z]+)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// scimMemberFilterPattern matches the member path of a group PATCH removing
+// one member, e.g. members[value eq "2819c223-..."]
+var scimMemberFilterPattern = regexp.MustCompile(`(?i)^members\[\s*value\s+eq\s+"([^"]*)"\s*\]$`)
+
+// SCIMUser is a SCIM User resource. The user name is the user's email.
+type SCIMUser struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId,omitempty"`
+	UserName    string      `json:"userName"`
+	Name        *SCIMName   `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Emails      []SCIMEmail `json:"emails,omitempty"`
+	Active      *bool       `json:"active,omitempty"`
+	Meta        *SCIMMeta   `json:"meta,omitempty"`
+}
+
+// SCIMName is the name of a SCIM User; only used to derive a display name
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// SCIMEmail is an email address of a SCIM User
+type SCIMEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMGroup is a SCIM Group resource
+type SCIMGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []SCIMMember `json:"members"`
+	Meta        *SCIMMeta    `json:"meta,omitempty"`
+}
+
+// SCIMMember is a member of a SCIM Group
+type SCIMMember struct {
+	Value   string `json:"value"`
+	Ref     string `json:"$ref,omitempty"`
+	Display string `json:"display,omitempty"`
+}
+
+// SCIMMeta is the metadata of a SCIM resource
+type SCIMMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+// SCIMListResponse is a page of SCIM resources
+type SCIMListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// SCIMPatchRequest is a SCIM PATCH request
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+// SCIMPatchOperation is an operation of a SCIM PATCH request
+type SCIMPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// SCIMError is a SCIM error response
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// SCIMConfigResponse is an organization's SCIM configuration
+type SCIMConfigResponse struct {
+	Enabled        bool                       `json:"enabled"`
+	TokenCreatedAt *time.Time                 `json:"token_created_at,omitempty"`
+	GroupRoles     map[string]models.UserRole `json:"group_roles"`
+	UpdatedAt      *time.Time                 `json:"updated_at,omitempty"`
+}
+
+// SetSCIMGroupRolesRequest represents a request to map SCIM groups to roles
+type SetSCIMGroupRolesRequest struct {
+	GroupRoles map[string]models.UserRole `json:"group_roles" validate:"required"`
+}
+
+// SCIMService defines the interface for SCIM configuration and provisioning
+type SCIMService interface {
+	// GetConfig returns an organization's SCIM configuration
+	GetConfig(ctx context.Context, orgID uuid.UUID) (*models.SCIMConfig, error)
+
+	// RotateToken issues a new bearer token for the organization's identity provider
+	RotateToken(ctx context.Context, orgID uuid.UUID, rotatedBy *uuid.UUID) (*scim.CreatedToken, error)
+
+	// RevokeToken revokes the organization's bearer token
+	RevokeToken(ctx context.Context, orgID uuid.UUID, revokedBy *uuid.UUID) error
+
+	// SetGroupRoles maps group display names to roles
+	SetGroupRoles(ctx context.Context, orgID uuid.UUID, groupRoles map[string]models.UserRole, updatedBy *uuid.UUID) (*models.SCIMConfig, error)
+
+	// Authenticate resolves a bearer token to the organization it provisions
+	Authenticate(ctx context.Context, token string) (uuid.UUID, error)
+
+	ListUsers(ctx context.Context, orgID uuid.UUID, filter scim.UserFilter) ([]*models.User, error)
+	GetUser(ctx context.Context, orgID, id uuid.UUID) (*models.User, error)
+	CreateUser(ctx context.Context, orgID uuid.UUID, input scim.UserInput) (*models.User, error)
+	ReplaceUser(ctx context.Context, orgID, id uuid.UUID, input scim.UserInput) (*models.User, error)
+	DeleteUser(ctx context.Context, orgID, id uuid.UUID) error
+
+	ListGroups(ctx context.Context, orgID uuid.UUID, displayName string) ([]*models.SCIMGroup, error)
+	GetGroup(ctx context.Context, orgID, id uuid.UUID) (*models.SCIMGroup, error)
+	CreateGroup(ctx context.Context, orgID uuid.UUID, input scim.GroupInput) (*models.SCIMGroup, error)
+	ReplaceGroup(ctx context.Context, orgID, id uuid.UUID, input scim.GroupInput) (*models.SCIMGroup, error)
+	DeleteGroup(ctx context.Context, orgID, id uuid.UUID) error
+}
+
+// SCIMHandler handles the SCIM 2.0 provisioning API and the organizations'
+// SCIM configuration
+type SCIMHandler struct {
+	service SCIMService
+	logger  *zap.Logger
+}
+
+// NewSCIMHandler creates a new SCIMHandler
+func NewSCIMHandler(service SCIMService, logger *zap.Logger) *SCIMHandler {
+	return &SCIMHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleGetConfig handles GET /v1/scim
+func (h *SCIMHandler) HandleGetConfig(w http.ResponseWriter, r *http.Request) {
+	orgID := middleware.GetOrgIDFromContext(r.Context())
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	config, err := h.service.GetConfig(r.Context(), orgID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	h.writeConfig(w, config)
+}
+
+// HandleRotateToken handles POST /v1/scim/token
+// The token is only returned by this call; the previous token stops working.
+func (h *SCIMHandler) HandleRotateToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	token, err := h.service.RotateToken(ctx, orgID, middleware.GetActorIDFromContext(ctx))
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteCreated(w, token)
+}
+
+// HandleRevokeToken handles DELETE /v1/scim/token
+func (h *SCIMHandler) HandleRevokeToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	if err := h.service.RevokeToken(ctx, orgID, middleware.GetActorIDFromContext(ctx)); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// HandleSetGroupRoles handles PUT /v1/scim/group-roles
+func (h *SCIMHandler) HandleSetGroupRoles(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	var req SetSCIMGroupRolesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	config, err := h.service.SetGroupRoles(ctx, orgID, req.GroupRoles, middleware.GetActorIDFromContext(ctx))
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	h.writeConfig(w, config)
+}
+
+// RequireToken is a middleware that authenticates the identity provider by
+// its organization's SCIM bearer token
+func (h *SCIMHandler) RequireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := ""
+		if auth := r.Header.Get("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+			token = strings.TrimSpace(auth[7:])
+		}
+		if token == "" {
+			writeSCIMError(w, http.StatusUnauthorized, "", "Missing bearer token")
+			return
+		}
+
+		orgID, err := h.service.Authenticate(r.Context(), token)
+		if err != nil {
+			h.logger.Warn("scim authentication failed",
+				zap.String("request_id", middleware.GetRequestIDFromContext(r.Context())),
+				zap.Error(err))
+			writeSCIMError(w, http.StatusUnauthorized, "", "Invalid bearer token")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(middleware.WithOrgID(r.Context(), orgID)))
+	})
+}
+
+// HandleServiceProviderConfig handles GET /scim/v2/ServiceProviderConfig
+func (h *SCIMHandler) HandleServiceProviderConfig(w http.ResponseWriter, r *http.Request) {
+	writeSCIM(w, http.StatusOK, map[string]interface{}{
+		"schemas":        []string{SCIMServiceProviderConfigSchema},
+		"patch":          map[string]bool{"supported": true},
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": scimMaxCount},
+		"changePassword": map[string]bool{"supported": false},
+		"sort":           map[string]bool{"supported": false},
+		"etag":           map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "The organization's SCIM token",
+			"primary":     true,
+		}},
+	})
+}
+
+// HandleListUsers handles GET /scim/v2/Users
+// Supports userName and externalId equality filters.
+func (h *SCIMHandler) HandleListUsers(w http.ResponseWriter, r *http.Request) {
+	var filter scim.UserFilter
+	if raw := r.URL.Query().Get("filter"); raw != "" {
+		attribute, value, ok := parseSCIMFilter(raw)
+		switch {
+		case ok && strings.EqualFold(attribute, "userName"):
+			filter.UserName = value
+		case ok && strings.EqualFold(attribute, "externalId"):
+			filter.ExternalID = value
+		default:
+			writeSCIMError(w, http.StatusBadRequest, "invalidFilter", "Only userName and externalId eq filters are supported")
+			return
+		}
+	}
+
+	users, err := h.service.ListUsers(r.Context(), middleware.GetOrgIDFromContext(r.Context()), filter)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	resources := make([]interface{}, len(users))
+	for i, user := range users {
+		resources[i] = toSCIMUser(user)
+	}
+	writeSCIMList(w, r, resources)
+}
+
+// HandleGetUser handles GET /scim/v2/Users/{id}
+func (h *SCIMHandler) HandleGetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseSCIMID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.GetUser(r.Context(), middleware.GetOrgIDFromContext(r.Context()), id)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, toSCIMUser(user))
+}
+
+// HandleCreateUser handles POST /scim/v2/Users
+func (h *SCIMHandler) HandleCreateUser(w http.ResponseWriter, r *http.Request) {
+	var resource SCIMUser
+	if err := json.NewDecoder(r.Body).Decode(&resource); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+		return
+	}
+
+	user, err := h.service.CreateUser(r.Context(), middleware.GetOrgIDFromContext(r.Context()), resource.input())
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	created := toSCIMUser(user)
+	w.Header().Set("Location", created.Meta.Location)
+	writeSCIM(w, http.StatusCreated, created)
+}
+
+// HandleReplaceUser handles PUT /scim/v2/Users/{id}
+// Setting active to false deactivates the user and revokes the user's
+// sessions and API keys.
+func (h *SCIMHandler) HandleReplaceUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseSCIMID(w, r)
+	if !ok {
+		return
+	}
+
+	var resource SCIMUser
+	if err := json.NewDecoder(r.Body).Decode(&resource); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+		return
+	}
+
+	user, err := h.service.ReplaceUser(r.Context(), middleware.GetOrgIDFromContext(r.Context()), id, resource.input())
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, toSCIMUser(user))
+}
+
+// HandlePatchUser handles PATCH /scim/v2/Users/{id}
+// Unsupported attributes are ignored, as identity providers send more
+// attributes than the gateway keeps.
+func (h *SCIMHandler) HandlePatchUser(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := middleware.GetOrgIDFromContext(ctx)
+
+	id, ok := parseSCIMID(w, r)
+	if !ok {
+		return
+	}
+
+	var patch SCIMPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+		return
+	}
+
+	user, err := h.service.GetUser(ctx, orgID, id)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	resource := toSCIMUser(user)
+	for _, op := range patch.Operations {
+		if err := resource.apply(op); err != nil {
+			writeSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+	}
+
+	user, err = h.service.ReplaceUser(ctx, orgID, id, resource.input())
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, toSCIMUser(user))
+}
+
+// HandleDeleteUser handles DELETE /scim/v2/Users/{id}
+func (h *SCIMHandler) HandleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseSCIMID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteUser(r.Context(), middleware.GetOrgIDFromContext(r.Context()), id); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// HandleListGroups handles GET /scim/v2/Groups
+// Supports displayName equality filters.
+func (h *SCIMHandler) HandleListGroups(w http.ResponseWriter, r *http.Request) {
+	var displayName string
+	if raw := r.URL.Query().Get("filter"); raw != "" {
+		attribute, value, ok := parseSCIMFilter(raw)
+		if !ok || !strings.EqualFold(attribute, "displayName") {
+			writeSCIMError(w, http.StatusBadRequest, "invalidFilter", "Only displayName eq filters are supported")
+			return
+		}
+		displayName = value
+	}
+
+	groups, err := h.service.ListGroups(r.Context(), middleware.GetOrgIDFromContext(r.Context()), displayName)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	// Identity providers exclude members when they only look a group up
+	excludeMembers := strings.Contains(r.URL.Query().Get("excludedAttributes"), "members")
+	resources := make([]interface{}, len(groups))
+	for i, group := range groups {
+		resource := toSCIMGroup(group)
+		if excludeMembers {
+			resource.Members = nil
+		}
+		resources[i] = resource
+	}
+	writeSCIMList(w, r, resources)
+}
+
+// HandleGetGroup handles GET /scim/v2/Groups/{id}
+func (h *SCIMHandler) HandleGetGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseSCIMID(w, r)
+	if !ok {
+		return
+	}
+
+	group, err := h.service.GetGroup(r.Context(), middleware.GetOrgIDFromContext(r.Context()), id)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, toSCIMGroup(group))
+}
+
+// HandleCreateGroup handles POST /scim/v2/Groups
+func (h *SCIMHandler) HandleCreateGroup(w http.ResponseWriter, r *http.Request) {
+	var resource SCIMGroup
+	if err := json.NewDecoder(r.Body).Decode(&resource); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+		return
+	}
+	input, err := resource.input()
+	if err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+
+	group, err := h.service.CreateGroup(r.Context(), middleware.GetOrgIDFromContext(r.Context()), input)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	created := toSCIMGroup(group)
+	w.Header().Set("Location", created.Meta.Location)
+	writeSCIM(w, http.StatusCreated, created)
+}
+
+// HandleReplaceGroup handles PUT /scim/v2/Groups/{id}
+func (h *SCIMHandler) HandleReplaceGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseSCIMID(w, r)
+	if !ok {
+		return
+	}
+
+	var resource SCIMGroup
+	if err := json.NewDecoder(r.Body).Decode(&resource); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+		return
+	}
+	input, err := resource.input()
+	if err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+
+	group, err := h.service.ReplaceGroup(r.Context(), middleware.GetOrgIDFromContext(r.Context()), id, input)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	writeSCIM(w, http.StatusOK, toSCIMGroup(group))
+}
+
+// HandlePatchGroup handles PATCH /scim/v2/Groups/{id}
+// Supports adding, removing and replacing members and renaming the group.
+func (h *SCIMHandler) HandlePatchGroup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	orgID := middleware.GetOrgIDFromContext(ctx)
+
+	id, ok := parseSCIMID(w, r)
+	if !ok {
+		return
+	}
+
+	var patch SCIMPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "Invalid request body")
+		return
+	}
+
+	group, err := h.service.GetGroup(ctx, orgID, id)
+	if err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	resource := toSCIMGroup(group)
+	for _, op := range patch.Operations {
+		if err := resource.apply(op); err != nil {
+			writeSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+	}
+	input, err := resource.input()
+	if err != nil {
+		writeSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+
+	if _, err := h.service.ReplaceGroup(ctx, orgID, id, input); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	// Identity providers do not need the group back after a PATCH
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleDeleteGroup handles DELETE /scim/v2/Groups/{id}
+func (h *SCIMHandler) HandleDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseSCIMID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteGroup(r.Context(), middleware.GetOrgIDFromContext(r.Context()), id); err != nil {
+		h.writeServiceError(w, err)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// writeConfig writes an organization's SCIM configuration
+func (h *SCIMHandler) writeConfig(w http.ResponseWriter, config *models.SCIMConfig) {
+	groupRoles, err := config.GetGroupRoles()
+	if err != nil {
+		HandleServiceError(w, services.NewDomainError(services.ErrorTypeInternal, "invalid scim configuration", err), h.logger)
+		return
+	}
+
+	response := SCIMConfigResponse{
+		Enabled:        config.Enabled(),
+		TokenCreatedAt: config.TokenCreatedAt,
+		GroupRoles:     groupRoles,
+	}
+	if !config.UpdatedAt.IsZero() {
+		response.UpdatedAt = &config.UpdatedAt
+	}
+	_ = utils.WriteOK(w, response)
+}
+
+// writeServiceError maps domain errors to SCIM error responses
+func (h *SCIMHandler) writeServiceError(w http.ResponseWriter, err error) {
+	detail := err.Error()
+	var domainErr *services.DomainError
+	if errors.As(err, &domainErr) {
+		detail = domainErr.Message
+	}
+
+	switch {
+	case services.IsNotFoundError(err):
+		writeSCIMError(w, http.StatusNotFound, "", detail)
+	case services.IsValidationError(err):
+		writeSCIMError(w, http.StatusBadRequest, "invalidValue", detail)
+	case services.IsConflictError(err):
+		writeSCIMError(w, http.StatusConflict, "uniqueness", detail)
+	case services.IsUnauthorizedError(err):
+		writeSCIMError(w, http.StatusUnauthorized, "", detail)
+	default:
+		h.logger.Error("scim request failed", zap.Error(err))
+		writeSCIMError(w, http.StatusInternalServerError, "", "Internal server error")
+	}
+}
+
+// input returns the user the resource describes. Users are active unless
+// the resource says otherwise, and the user name falls back to the primary
+// email.
+func (u *SCIMUser) input() scim.UserInput {
+	input := scim.UserInput{
+		UserName:    u.UserName,
+		ExternalID:  u.ExternalID,
+		DisplayName: u.DisplayName,
+		Active:      u.Active == nil || *u.Active,
+	}
+	if input.UserName == "" {
+		for _, email := range u.Emails {
+			if email.Primary || input.UserName == "" {
+				input.UserName = email.Value
+			}
+		}
+	}
+	if input.DisplayName == "" && u.Name != nil {
+		input.DisplayName = u.Name.Formatted
+		if input.DisplayName == "" {
+			input.DisplayName = strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+		}
+	}
+	return input
+}
+
+// apply applies a PATCH operation to the user's attributes
+func (u *SCIMUser) apply(op SCIMPatchOperation) error {
+	switch strings.ToLower(op.Op) {
+	case "add", "replace":
+	case "remove":
+		// Only externalId and displayName can be cleared
+		switch strings.ToLower(op.Path) {
+		case "externalid":
+			u.ExternalID = ""
+		case "displayname":
+			u.DisplayName = ""
+		}
+		return nil
+	default:
+		return errors.New("unsupported operation " + op.Op)
+	}
+
+	if op.Path == "" {
+		var values map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &values); err != nil {
+			return errors.New("value must be an object when no path is given")
+		}
+		for path, value := range values {
+			if err := u.set(path, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return u.set(op.Path, op.Value)
+}
+
+// set sets one of the user's attributes from a PATCH value; unsupported
+// attributes are ignored
+func (u *SCIMUser) set(path string, value json.RawMessage) error {
+	switch strings.ToLower(path) {
+	case "active":
+		active, err := parseSCIMBool(value)
+		if err != nil {
+			return err
+		}
+		u.Active = &active
+	case "username":
+		return json.Unmarshal(value, &u.UserName)
+	case "externalid":
+		return json.Unmarshal(value, &u.ExternalID)
+	case "displayname":
+		return json.Unmarshal(value, &u.DisplayName)
+	}
+	return nil
+}
+
+// input returns the group the resource describes
+func (g *SCIMGroup) input() (scim.GroupInput, error) {
+	input := scim.GroupInput{
+		DisplayName: g.DisplayName,
+		ExternalID:  g.ExternalID,
+		Members:     make([]uuid.UUID, 0, len(g.Members)),
+	}
+	for _, member := range g.Members {
+		userID, err := uuid.Parse(member.Value)
+		if err != nil {
+			return input, errors.New("invalid member " + member.Value)
+		}
+		input.Members = append(input.Members, userID)
+	}
+	return input, nil
+}
+
+// apply applies a PATCH operation to the group's name and members
+func (g *SCIMGroup) apply(op SCIMPatchOperation) error {
+	path := strings.TrimSpace(op.Path)
+	switch strings.ToLower(op.Op) {
+	case "add", "replace":
+		switch {
+		case path == "":
+			var values struct {
+				DisplayName *string       `json:"displayName"`
+				ExternalID  *string       `json:"externalId"`
+				Members     *[]SCIMMember `json:"members"`
+			}
+			if err := json.Unmarshal(op.Value, &values); err != nil {
+				return errors.New("value must be an object when no path is given")
+			}
+			if values.DisplayName != nil {
+				g.DisplayName = *values.DisplayName
+			}
+			if values.ExternalID != nil {
+				g.ExternalID = *values.ExternalID
+			}
+			if values.Members != nil {
+				g.addMembers(*values.Members, strings.EqualFold(op.Op, "replace"))
+			}
+		case strings.EqualFold(path, "displayName"):
+			return json.Unmarshal(op.Value, &g.DisplayName)
+		case strings.EqualFold(path, "externalId"):
+			return json.Unmarshal(op.Value, &g.ExternalID)
+		case strings.EqualFold(path, "members"):
+			var members []SCIMMember
+			if err := json.Unmarshal(op.Value, &members); err != nil {
+				return errors.New("members must be a list")
+			}
+			g.addMembers(members, strings.EqualFold(op.Op, "replace"))
+		default:
+			return errors.New("unsupported path " + path)
+		}
+	case "remove":
+		switch {
+		case strings.EqualFold(path, "members"):
+			var members []SCIMMember
+			if len(op.Value) == 0 {
+				g.Members = nil
+				return nil
+			}
+			if err := json.Unmarshal(op.Value, &members); err != nil {
+				return errors.New("members must be a list")
+			}
+			for _, member := range members {
+				g.removeMember(member.Value)
+			}
+		case scimMemberFilterPattern.MatchString(path):
+			g.removeMember(scimMemberFilterPattern.FindStringSubmatch(path)[1])
+		case strings.EqualFold(path, "externalId"):
+			g.ExternalID = ""
+		default:
+			return errors.New("unsupported path " + path)
+		}
+	default:
+		return errors.New("unsupported operation " + op.Op)
+	}
+	return nil
+}
+
+// addMembers adds members to the group, or replaces its members
+func (g *SCIMGroup) addMembers(members []SCIMMember, replace bool) {
+	if replace {
+		g.Members = nil
+	}
+	for _, member := range members {
+		g.removeMember(member.Value)
+		g.Members = append(g.Members, member)
+	}
+}
+
+// removeMember removes a member from the group
+func (g *SCIMGroup) removeMember(value string) {
+	kept := g.Members[:0]
+	for _, member := range g.Members {
+		if !strings.EqualFold(member.Value, value) {
+			kept = append(kept, member)
+		}
+	}
+	g.Members = kept
+}
+
+// toSCIMUser converts a user to a SCIM User resource
+func toSCIMUser(user *models.User) *SCIMUser {
+	active := user.IsActive()
+	location := "/scim/v2/Users/" + user.ID.String()
+	return &SCIMUser{
+		Schemas:     []string{SCIMUserSchema},
+		ID:          user.ID.String(),
+		ExternalID:  user.ExternalID,
+		UserName:    user.Email,
+		DisplayName: user.DisplayName,
+		Emails:      []SCIMEmail{{Value: user.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &SCIMMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     location,
+		},
+	}
+}
+
+// toSCIMGroup converts a group to a SCIM Group resource
+func toSCIMGroup(group *models.SCIMGroup) *SCIMGroup {
+	members := make([]SCIMMember, len(group.Members))
+	for i, userID := range group.Members {
+		members[i] = SCIMMember{Value: userID.String(), Ref: "/scim/v2/Users/" + userID.String()}
+	}
+	return &SCIMGroup{
+		Schemas:     []string{SCIMGroupSchema},
+		ID:          group.ID.String(),
+		ExternalID:  group.ExternalID,
+		DisplayName: group.DisplayName,
+		Members:     members,
+		Meta: &SCIMMeta{
+			ResourceType: "Group",
+			Created:      group.CreatedAt,
+			LastModified: group.UpdatedAt,
+			Location:     "/scim/v2/Groups/" + group.ID.String(),
+		},
+	}
+}
+
+// parseSCIMFilter parses an equality filter into its attribute and value
+func parseSCIMFilter(filter string) (string, string, bool) {
+	match := scimFilterPattern.FindStringSubmatch(filter)
+	if match == nil {
+		return "", "", false
+	}
+	value, err := strconv.Unquote(`"` + match[2] + `"`)
+	if err != nil {
+		return "", "", false
+	}
+	return match[1], value, true
+}
+
+// parseSCIMBool parses a boolean PATCH value; some identity providers send
+// booleans as strings
+func parseSCIMBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(value, &s); err == nil {
+		if parsed, err := strconv.ParseBool(s); err == nil {
+			return parsed, nil
+		}
+	}
+	return false, errors.New("active must be a boolean")
+}
+
+// parseSCIMID parses the resource ID of the URL
+func parseSCIMID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		// Resources are only identified by UUIDs, so other IDs do not exist
+		writeSCIMError(w, http.StatusNotFound, "", "Resource not found")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// writeSCIMList writes the page of resources startIndex and count select
+func writeSCIMList(w http.ResponseWriter, r *http.Request, resources []interface{}) {
+	startIndex, err := strconv.Atoi(r.URL.Query().Get("startIndex"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count < 0 {
+		count = scimDefaultCount
+	}
+	if count > scimMaxCount {
+		count = scimMaxCount
+	}
+
+	total := len(resources)
+	start := startIndex - 1
+	if start > total {
+		start = total
+	}
+	end := start + count
+	if end > total {
+		end = total
+	}
+	page := resources[start:end]
+
+	writeSCIM(w, http.StatusOK, SCIMListResponse{
+		Schemas:      []string{SCIMListResponseSchema},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(page),
+		Resources:    page,
+	})
+}
+
+// writeSCIMError writes a SCIM error response
+func writeSCIMError(w http.ResponseWriter, status int, scimType, detail string) {
+	writeSCIM(w, status, SCIMError{
+		Schemas:  []string{SCIMErrorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+// writeSCIM writes a SCIM response
+func writeSCIM(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
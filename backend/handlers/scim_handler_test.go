@@ -0,0 +1,434 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/scim"
+	"go.uber.org/zap"
+)
+
+// MockSCIMService is a mock implementation of SCIMService
+type MockSCIMService struct {
+	mock.Mock
+}
+
+func (m *MockSCIMService) GetConfig(ctx context.Context, orgID uuid.UUID) (*models.SCIMConfig, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMConfig), args.Error(1)
+}
+
+func (m *MockSCIMService) RotateToken(ctx context.Context, orgID uuid.UUID, rotatedBy *uuid.UUID) (*scim.CreatedToken, error) {
+	args := m.Called(ctx, orgID, rotatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*scim.CreatedToken), args.Error(1)
+}
+
+func (m *MockSCIMService) RevokeToken(ctx context.Context, orgID uuid.UUID, revokedBy *uuid.UUID) error {
+	args := m.Called(ctx, orgID, revokedBy)
+	return args.Error(0)
+}
+
+func (m *MockSCIMService) SetGroupRoles(ctx context.Context, orgID uuid.UUID, groupRoles map[string]models.UserRole, updatedBy *uuid.UUID) (*models.SCIMConfig, error) {
+	args := m.Called(ctx, orgID, groupRoles, updatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMConfig), args.Error(1)
+}
+
+func (m *MockSCIMService) Authenticate(ctx context.Context, token string) (uuid.UUID, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockSCIMService) ListUsers(ctx context.Context, orgID uuid.UUID, filter scim.UserFilter) ([]*models.User, error) {
+	args := m.Called(ctx, orgID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+func (m *MockSCIMService) GetUser(ctx context.Context, orgID, id uuid.UUID) (*models.User, error) {
+	args := m.Called(ctx, orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockSCIMService) CreateUser(ctx context.Context, orgID uuid.UUID, input scim.UserInput) (*models.User, error) {
+	args := m.Called(ctx, orgID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockSCIMService) ReplaceUser(ctx context.Context, orgID, id uuid.UUID, input scim.UserInput) (*models.User, error) {
+	args := m.Called(ctx, orgID, id, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockSCIMService) DeleteUser(ctx context.Context, orgID, id uuid.UUID) error {
+	args := m.Called(ctx, orgID, id)
+	return args.Error(0)
+}
+
+func (m *MockSCIMService) ListGroups(ctx context.Context, orgID uuid.UUID, displayName string) ([]*models.SCIMGroup, error) {
+	args := m.Called(ctx, orgID, displayName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SCIMGroup), args.Error(1)
+}
+
+func (m *MockSCIMService) GetGroup(ctx context.Context, orgID, id uuid.UUID) (*models.SCIMGroup, error) {
+	args := m.Called(ctx, orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMGroup), args.Error(1)
+}
+
+func (m *MockSCIMService) CreateGroup(ctx context.Context, orgID uuid.UUID, input scim.GroupInput) (*models.SCIMGroup, error) {
+	args := m.Called(ctx, orgID, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMGroup), args.Error(1)
+}
+
+func (m *MockSCIMService) ReplaceGroup(ctx context.Context, orgID, id uuid.UUID, input scim.GroupInput) (*models.SCIMGroup, error) {
+	args := m.Called(ctx, orgID, id, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SCIMGroup), args.Error(1)
+}
+
+func (m *MockSCIMService) DeleteGroup(ctx context.Context, orgID, id uuid.UUID) error {
+	args := m.Called(ctx, orgID, id)
+	return args.Error(0)
+}
+
+func TestSCIMRequireToken(t *testing.T) {
+	orgID := uuid.New()
+	mockService := new(MockSCIMService)
+	handler := NewSCIMHandler(mockService, zap.NewNop())
+	mockService.On("Authenticate", mock.Anything, "lcpscim_good").Return(orgID, nil)
+	mockService.On("Authenticate", mock.Anything, "lcpscim_bad").
+		Return(uuid.Nil, services.NewDomainError(services.ErrorTypeUnauthorized, "invalid scim token", nil))
+
+	var gotOrgID uuid.UUID
+	next := handler.RequireToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotOrgID = middleware.GetOrgIDFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"valid token", "Bearer lcpscim_good", http.StatusOK},
+		{"invalid token", "Bearer lcpscim_bad", http.StatusUnauthorized},
+		{"missing token", "", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/scim/v2/Users", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			next.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusUnauthorized {
+				assert.Equal(t, scimContentType, w.Header().Get("Content-Type"))
+				assert.Contains(t, w.Body.String(), SCIMErrorSchema)
+			}
+		})
+	}
+	assert.Equal(t, orgID, gotOrgID)
+}
+
+func TestHandleSCIMListUsers(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("filters by userName", func(t *testing.T) {
+		mockService := new(MockSCIMService)
+		handler := NewSCIMHandler(mockService, zap.NewNop())
+		user := &models.User{ID: uuid.New(), OrgID: orgID, Email: "ada@example.com", Role: models.RoleViewer}
+		mockService.On("ListUsers", mock.Anything, orgID, scim.UserFilter{UserName: "ada@example.com"}).
+			Return([]*models.User{user}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, `/scim/v2/Users?filter=userName%20eq%20%22ada%40example.com%22`, nil)
+		req = req.WithContext(middleware.WithOrgID(req.Context(), orgID))
+		w := httptest.NewRecorder()
+
+		handler.HandleListUsers(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"totalResults":1`)
+		assert.Contains(t, w.Body.String(), `"userName":"ada@example.com"`)
+		assert.Contains(t, w.Body.String(), `"active":true`)
+	})
+
+	t.Run("unsupported filter", func(t *testing.T) {
+		handler := NewSCIMHandler(new(MockSCIMService), zap.NewNop())
+
+		req := httptest.NewRequest(http.MethodGet, `/scim/v2/Users?filter=title%20co%20%22eng%22`, nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleListUsers(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), `"scimType":"invalidFilter"`)
+	})
+}
+
+func TestHandleSCIMCreateUser(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("creates the user", func(t *testing.T) {
+		mockService := new(MockSCIMService)
+		handler := NewSCIMHandler(mockService, zap.NewNop())
+		id := uuid.New()
+		mockService.On("CreateUser", mock.Anything, orgID, scim.UserInput{
+			UserName: "ada@example.com", ExternalID: "00u1", DisplayName: "Ada Lovelace", Active: true,
+		}).Return(&models.User{ID: id, OrgID: orgID, Email: "ada@example.com", ExternalID: "00u1", DisplayName: "Ada Lovelace"}, nil)
+
+		body := `{"schemas":["` + SCIMUserSchema + `"],"userName":"ada@example.com","externalId":"00u1",
+			"name":{"givenName":"Ada","familyName":"Lovelace"},"emails":[{"value":"ada@example.com","primary":true}]}`
+		req := httptest.NewRequest(http.MethodPost, "/scim/v2/Users", strings.NewReader(body))
+		req = req.WithContext(middleware.WithOrgID(req.Context(), orgID))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateUser(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "/scim/v2/Users/"+id.String(), w.Header().Get("Location"))
+		assert.Contains(t, w.Body.String(), `"id":"`+id.String()+`"`)
+	})
+
+	t.Run("duplicate user name", func(t *testing.T) {
+		mockService := new(MockSCIMService)
+		handler := NewSCIMHandler(mockService, zap.NewNop())
+		mockService.On("CreateUser", mock.Anything, orgID, mock.Anything).
+			Return(nil, services.NewDomainError(services.ErrorTypeConflict, "user already exists", nil))
+
+		req := httptest.NewRequest(http.MethodPost, "/scim/v2/Users", strings.NewReader(`{"userName":"ada@example.com"}`))
+		req = req.WithContext(middleware.WithOrgID(req.Context(), orgID))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateUser(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), `"scimType":"uniqueness"`)
+		assert.Contains(t, w.Body.String(), `"detail":"user already exists"`)
+	})
+}
+
+func TestHandleSCIMPatchUser(t *testing.T) {
+	orgID := uuid.New()
+	id := uuid.New()
+	user := &models.User{ID: id, OrgID: orgID, Email: "ada@example.com", ExternalID: "00u1", DisplayName: "Ada"}
+
+	tests := []struct {
+		name  string
+		body  string
+		input scim.UserInput
+	}{
+		{
+			name:  "deactivates with a path",
+			body:  `{"Operations":[{"op":"replace","path":"active","value":false}]}`,
+			input: scim.UserInput{UserName: "ada@example.com", ExternalID: "00u1", DisplayName: "Ada", Active: false},
+		},
+		{
+			name:  "deactivates with a string value and no path",
+			body:  `{"Operations":[{"op":"Replace","value":{"active":"False","title":"ignored"}}]}`,
+			input: scim.UserInput{UserName: "ada@example.com", ExternalID: "00u1", DisplayName: "Ada", Active: false},
+		},
+		{
+			name:  "renames",
+			body:  `{"Operations":[{"op":"replace","path":"displayName","value":"Ada Lovelace"}]}`,
+			input: scim.UserInput{UserName: "ada@example.com", ExternalID: "00u1", DisplayName: "Ada Lovelace", Active: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockSCIMService)
+			handler := NewSCIMHandler(mockService, zap.NewNop())
+			mockService.On("GetUser", mock.Anything, orgID, id).Return(user, nil)
+			mockService.On("ReplaceUser", mock.Anything, orgID, id, tt.input).Return(user, nil)
+
+			req := httptest.NewRequest(http.MethodPatch, "/scim/v2/Users/"+id.String(), strings.NewReader(tt.body))
+			req = req.WithContext(middleware.WithOrgID(req.Context(), orgID))
+			w := httptest.NewRecorder()
+
+			handler.HandlePatchUser(w, withURLParam(req, "id", id.String()))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("unknown user", func(t *testing.T) {
+		mockService := new(MockSCIMService)
+		handler := NewSCIMHandler(mockService, zap.NewNop())
+		mockService.On("GetUser", mock.Anything, orgID, id).
+			Return(nil, services.NewDomainError(services.ErrorTypeNotFound, "user not found", nil))
+
+		req := httptest.NewRequest(http.MethodPatch, "/scim/v2/Users/"+id.String(), strings.NewReader(`{"Operations":[]}`))
+		req = req.WithContext(middleware.WithOrgID(req.Context(), orgID))
+		w := httptest.NewRecorder()
+
+		handler.HandlePatchUser(w, withURLParam(req, "id", id.String()))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertNotCalled(t, "ReplaceUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleSCIMPatchGroup(t *testing.T) {
+	orgID := uuid.New()
+	id := uuid.New()
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	group := &models.SCIMGroup{ID: id, OrgID: orgID, DisplayName: "Engineering", Members: []uuid.UUID{alice, bob}}
+
+	tests := []struct {
+		name    string
+		body    string
+		members []uuid.UUID
+		display string
+	}{
+		{
+			name:    "adds members",
+			body:    `{"Operations":[{"op":"add","path":"members","value":[{"value":"` + carol.String() + `"}]}]}`,
+			members: []uuid.UUID{alice, bob, carol},
+			display: "Engineering",
+		},
+		{
+			name:    "removes a member by filter",
+			body:    `{"Operations":[{"op":"remove","path":"members[value eq \"` + alice.String() + `\"]"}]}`,
+			members: []uuid.UUID{bob},
+			display: "Engineering",
+		},
+		{
+			name:    "replaces members and renames",
+			body:    `{"Operations":[{"op":"replace","value":{"displayName":"Platform","members":[{"value":"` + carol.String() + `"}]}}]}`,
+			members: []uuid.UUID{carol},
+			display: "Platform",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockSCIMService)
+			handler := NewSCIMHandler(mockService, zap.NewNop())
+			mockService.On("GetGroup", mock.Anything, orgID, id).Return(group, nil)
+			mockService.On("ReplaceGroup", mock.Anything, orgID, id, scim.GroupInput{
+				DisplayName: tt.display, Members: tt.members,
+			}).Return(group, nil)
+
+			req := httptest.NewRequest(http.MethodPatch, "/scim/v2/Groups/"+id.String(), strings.NewReader(tt.body))
+			req = req.WithContext(middleware.WithOrgID(req.Context(), orgID))
+			w := httptest.NewRecorder()
+
+			handler.HandlePatchGroup(w, withURLParam(req, "id", id.String()))
+
+			assert.Equal(t, http.StatusNoContent, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+
+	t.Run("invalid member", func(t *testing.T) {
+		mockService := new(MockSCIMService)
+		handler := NewSCIMHandler(mockService, zap.NewNop())
+		mockService.On("GetGroup", mock.Anything, orgID, id).Return(group, nil)
+
+		body := `{"Operations":[{"op":"add","path":"members","value":[{"value":"not-a-user"}]}]}`
+		req := httptest.NewRequest(http.MethodPatch, "/scim/v2/Groups/"+id.String(), strings.NewReader(body))
+		req = req.WithContext(middleware.WithOrgID(req.Context(), orgID))
+		w := httptest.NewRecorder()
+
+		handler.HandlePatchGroup(w, withURLParam(req, "id", id.String()))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ReplaceGroup", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleSCIMTokenAndGroupRoles(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("rotates the token", func(t *testing.T) {
+		mockService := new(MockSCIMService)
+		handler := NewSCIMHandler(mockService, zap.NewNop())
+		mockService.On("RotateToken", mock.Anything, orgID, (*uuid.UUID)(nil)).
+			Return(&scim.CreatedToken{Token: "lcpscim_new", CreatedAt: time.Now()}, nil)
+
+		req := withCaller(httptest.NewRequest(http.MethodPost, "/api/v1/scim/token", nil), orgID, "admin")
+		w := httptest.NewRecorder()
+
+		handler.HandleRotateToken(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), "lcpscim_new")
+	})
+
+	t.Run("sets group roles", func(t *testing.T) {
+		mockService := new(MockSCIMService)
+		handler := NewSCIMHandler(mockService, zap.NewNop())
+		groupRoles := map[string]models.UserRole{"Engineering": models.RoleMember}
+		config := models.NewSCIMConfig(orgID)
+		assert.NoError(t, config.SetGroupRoles(groupRoles))
+		mockService.On("SetGroupRoles", mock.Anything, orgID, groupRoles, (*uuid.UUID)(nil)).Return(config, nil)
+
+		body := `{"group_roles":{"Engineering":"member"}}`
+		req := withCaller(httptest.NewRequest(http.MethodPut, "/api/v1/scim/group-roles", strings.NewReader(body)), orgID, "admin")
+		w := httptest.NewRecorder()
+
+		handler.HandleSetGroupRoles(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"Engineering":"member"`)
+		assert.Contains(t, w.Body.String(), `"enabled":false`)
+	})
+
+	t.Run("rejects unknown roles", func(t *testing.T) {
+		mockService := new(MockSCIMService)
+		handler := NewSCIMHandler(mockService, zap.NewNop())
+		mockService.On("SetGroupRoles", mock.Anything, orgID, mock.Anything, (*uuid.UUID)(nil)).
+			Return(nil, services.NewDomainError(services.ErrorTypeValidation, "invalid role for group Engineering", nil))
+
+		body := `{"group_roles":{"Engineering":"owner"}}`
+		req := withCaller(httptest.NewRequest(http.MethodPut, "/api/v1/scim/group-roles", strings.NewReader(body)), orgID, "admin")
+		w := httptest.NewRecorder()
+
+		handler.HandleSetGroupRoles(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
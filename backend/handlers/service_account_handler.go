@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/serviceaccount"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// CreateServiceAccountRequest represents a request to create a service account
+type CreateServiceAccountRequest struct {
+	Name        string              `json:"name" validate:"required,max=255"`
+	Description string              `json:"description,omitempty"`
+	Roles       []string            `json:"roles" validate:"required"`
+	Scopes      []models.Permission `json:"scopes,omitempty"`
+}
+
+// UpdateServiceAccountRequest represents a request to change a service
+// account; omitted fields are left unchanged
+type UpdateServiceAccountRequest struct {
+	Name        *string              `json:"name,omitempty" validate:"omitempty,max=255"`
+	Description *string              `json:"description,omitempty"`
+	Roles       *[]string            `json:"roles,omitempty"`
+	Scopes      *[]models.Permission `json:"scopes,omitempty"`
+	Disabled    *bool                `json:"disabled,omitempty"`
+}
+
+// ServiceAccountService defines the interface for service account operations
+type ServiceAccountService interface {
+	// Create creates a service account; the client secret is only returned here and by RotateSecret
+	Create(ctx context.Context, req serviceaccount.CreateRequest) (*serviceaccount.CreatedServiceAccount, error)
+
+	// List lists the organization's service accounts
+	List(ctx context.Context, orgID uuid.UUID) ([]*models.ServiceAccount, error)
+
+	// Get returns a service account
+	Get(ctx context.Context, orgID, id uuid.UUID) (*models.ServiceAccount, error)
+
+	// Update changes a service account's name, description, roles, scopes or status
+	Update(ctx context.Context, req serviceaccount.UpdateRequest) (*models.ServiceAccount, error)
+
+	// RotateSecret replaces a service account's client secret
+	RotateSecret(ctx context.Context, orgID, id uuid.UUID, rotatedBy *uuid.UUID) (*serviceaccount.CreatedServiceAccount, error)
+
+	// Delete deletes a service account
+	Delete(ctx context.Context, orgID, id uuid.UUID, deletedBy *uuid.UUID) error
+}
+
+// ServiceAccountHandler handles service account HTTP requests
+type ServiceAccountHandler struct {
+	service ServiceAccountService
+	logger  *zap.Logger
+}
+
+// NewServiceAccountHandler creates a new ServiceAccountHandler
+func NewServiceAccountHandler(service ServiceAccountService, logger *zap.Logger) *ServiceAccountHandler {
+	return &ServiceAccountHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleCreateServiceAccount handles POST /v1/service-accounts
+// The client secret is only returned by this call.
+func (h *ServiceAccountHandler) HandleCreateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	var req CreateServiceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	created, err := h.service.Create(ctx, serviceaccount.CreateRequest{
+		OrgID:       orgID,
+		Name:        req.Name,
+		Description: req.Description,
+		Roles:       req.Roles,
+		Scopes:      req.Scopes,
+		CreatedBy:   middleware.GetActorIDFromContext(ctx),
+		Grantor:     grantor(r),
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteCreated(w, created)
+}
+
+// HandleListServiceAccounts handles GET /v1/service-accounts
+func (h *ServiceAccountHandler) HandleListServiceAccounts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	accounts, err := h.service.List(ctx, orgID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, accounts)
+}
+
+// HandleGetServiceAccount handles GET /v1/service-accounts/{id}
+func (h *ServiceAccountHandler) HandleGetServiceAccount(w http.ResponseWriter, r *http.Request) {
+	orgID, id, ok := h.parseAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	account, err := h.service.Get(r.Context(), orgID, id)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, account)
+}
+
+// HandleUpdateServiceAccount handles PATCH /v1/service-accounts/{id}
+func (h *ServiceAccountHandler) HandleUpdateServiceAccount(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID, id, ok := h.parseAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	var req UpdateServiceAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	account, err := h.service.Update(ctx, serviceaccount.UpdateRequest{
+		OrgID:       orgID,
+		ID:          id,
+		Name:        req.Name,
+		Description: req.Description,
+		Roles:       req.Roles,
+		Scopes:      req.Scopes,
+		Disabled:    req.Disabled,
+		UpdatedBy:   middleware.GetActorIDFromContext(ctx),
+		Grantor:     grantor(r),
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, account)
+}
+
+// HandleRotateSecret handles POST /v1/service-accounts/{id}/rotate-secret
+// The new client secret is only returned by this call.
+func (h *ServiceAccountHandler) HandleRotateSecret(w http.ResponseWriter, r *http.Request) {
+	orgID, id, ok := h.parseAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	rotated, err := h.service.RotateSecret(r.Context(), orgID, id, middleware.GetActorIDFromContext(r.Context()))
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, rotated)
+}
+
+// HandleDeleteServiceAccount handles DELETE /v1/service-accounts/{id}
+func (h *ServiceAccountHandler) HandleDeleteServiceAccount(w http.ResponseWriter, r *http.Request) {
+	orgID, id, ok := h.parseAccountID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), orgID, id, middleware.GetActorIDFromContext(r.Context())); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// parseAccountID returns the organization and the service account ID of the URL
+func (h *ServiceAccountHandler) parseAccountID(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	orgID := middleware.GetOrgIDFromContext(r.Context())
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid service account ID format", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return orgID, id, true
+}
+
+// grantor returns the caller's roles and scopes, which bound the
+// permissions it may grant
+func grantor(r *http.Request) serviceaccount.Grantor {
+	claims := middleware.GetClaimsFromContext(r.Context())
+	if claims == nil {
+		return serviceaccount.Grantor{}
+	}
+	return serviceaccount.Grantor{Roles: claims.Groups, Scopes: claims.Scopes}
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/serviceaccount"
+	"go.uber.org/zap"
+)
+
+// MockServiceAccountService is a mock implementation of ServiceAccountService
+type MockServiceAccountService struct {
+	mock.Mock
+}
+
+func (m *MockServiceAccountService) Create(ctx context.Context, req serviceaccount.CreateRequest) (*serviceaccount.CreatedServiceAccount, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*serviceaccount.CreatedServiceAccount), args.Error(1)
+}
+
+func (m *MockServiceAccountService) List(ctx context.Context, orgID uuid.UUID) ([]*models.ServiceAccount, error) {
+	args := m.Called(ctx, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.ServiceAccount), args.Error(1)
+}
+
+func (m *MockServiceAccountService) Get(ctx context.Context, orgID, id uuid.UUID) (*models.ServiceAccount, error) {
+	args := m.Called(ctx, orgID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ServiceAccount), args.Error(1)
+}
+
+func (m *MockServiceAccountService) Update(ctx context.Context, req serviceaccount.UpdateRequest) (*models.ServiceAccount, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ServiceAccount), args.Error(1)
+}
+
+func (m *MockServiceAccountService) RotateSecret(ctx context.Context, orgID, id uuid.UUID, rotatedBy *uuid.UUID) (*serviceaccount.CreatedServiceAccount, error) {
+	args := m.Called(ctx, orgID, id, rotatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*serviceaccount.CreatedServiceAccount), args.Error(1)
+}
+
+func (m *MockServiceAccountService) Delete(ctx context.Context, orgID, id uuid.UUID, deletedBy *uuid.UUID) error {
+	args := m.Called(ctx, orgID, id, deletedBy)
+	return args.Error(0)
+}
+
+func TestHandleCreateServiceAccount(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("returns the client secret", func(t *testing.T) {
+		mockService := new(MockServiceAccountService)
+		handler := NewServiceAccountHandler(mockService, zap.NewNop())
+		account := &models.ServiceAccount{ID: uuid.New(), OrgID: orgID, Name: "ci", ClientID: "sa_1", SecretHash: "secret-hash"}
+		mockService.On("Create", mock.Anything, mock.MatchedBy(func(req serviceaccount.CreateRequest) bool {
+			return req.OrgID == orgID && req.Name == "ci" && req.Roles[0] == "member" &&
+				len(req.Grantor.Roles) == 1 && req.Grantor.Roles[0] == "admin"
+		})).Return(&serviceaccount.CreatedServiceAccount{ServiceAccount: account, ClientSecret: "lcpcs_secret"}, nil)
+
+		body := `{"name":"ci","roles":["member"],"scopes":["policies:read"]}`
+		req := withCaller(httptest.NewRequest(http.MethodPost, "/api/v1/service-accounts", strings.NewReader(body)), orgID, "admin")
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateServiceAccount(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"client_secret":"lcpcs_secret"`)
+		assert.NotContains(t, w.Body.String(), "secret-hash")
+	})
+
+	t.Run("escalation is forbidden", func(t *testing.T) {
+		mockService := new(MockServiceAccountService)
+		handler := NewServiceAccountHandler(mockService, zap.NewNop())
+		mockService.On("Create", mock.Anything, mock.Anything).
+			Return(nil, services.NewDomainError(services.ErrorTypeForbidden, "cannot grant a permission you do not hold", nil))
+
+		body := `{"name":"ci","roles":["admin"]}`
+		req := withCaller(httptest.NewRequest(http.MethodPost, "/api/v1/service-accounts", strings.NewReader(body)), orgID, "member")
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateServiceAccount(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		handler := NewServiceAccountHandler(new(MockServiceAccountService), zap.NewNop())
+
+		req := withCaller(httptest.NewRequest(http.MethodPost, "/api/v1/service-accounts", strings.NewReader(`{"roles":[]}`)), orgID, "admin")
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateServiceAccount(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleUpdateServiceAccount(t *testing.T) {
+	orgID := uuid.New()
+	id := uuid.New()
+	mockService := new(MockServiceAccountService)
+	handler := NewServiceAccountHandler(mockService, zap.NewNop())
+	mockService.On("Update", mock.Anything, mock.MatchedBy(func(req serviceaccount.UpdateRequest) bool {
+		return req.OrgID == orgID && req.ID == id && req.Disabled != nil && *req.Disabled && req.Roles == nil &&
+			len(req.Grantor.Scopes) == 1
+	})).Return(&models.ServiceAccount{ID: id, OrgID: orgID}, nil)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/service-accounts/"+id.String(), strings.NewReader(`{"disabled":true}`))
+	req = withTenant(req, orgID, uuid.Nil)
+	req = req.WithContext(middleware.WithClaims(req.Context(), &middleware.Claims{
+		Sub: "serviceaccount:" + uuid.NewString(), OrgID: orgID.String(), Groups: []string{"admin"}, Scopes: []string{"service_accounts:write"},
+	}))
+	w := httptest.NewRecorder()
+
+	handler.HandleUpdateServiceAccount(w, withURLParam(req, "id", id.String()))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandleRotateServiceAccountSecret(t *testing.T) {
+	orgID := uuid.New()
+	id := uuid.New()
+	mockService := new(MockServiceAccountService)
+	handler := NewServiceAccountHandler(mockService, zap.NewNop())
+	mockService.On("RotateSecret", mock.Anything, orgID, id, (*uuid.UUID)(nil)).
+		Return(&serviceaccount.CreatedServiceAccount{ServiceAccount: &models.ServiceAccount{ID: id}, ClientSecret: "lcpcs_new"}, nil)
+
+	req := withCaller(httptest.NewRequest(http.MethodPost, "/api/v1/service-accounts/"+id.String()+"/rotate-secret", nil), orgID, "admin")
+	w := httptest.NewRecorder()
+
+	handler.HandleRotateSecret(w, withURLParam(req, "id", id.String()))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"client_secret":"lcpcs_new"`)
+}
+
+func TestHandleDeleteServiceAccount(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("deletes", func(t *testing.T) {
+		id := uuid.New()
+		mockService := new(MockServiceAccountService)
+		handler := NewServiceAccountHandler(mockService, zap.NewNop())
+		mockService.On("Delete", mock.Anything, orgID, id, (*uuid.UUID)(nil)).Return(nil)
+
+		req := withCaller(httptest.NewRequest(http.MethodDelete, "/api/v1/service-accounts/"+id.String(), nil), orgID, "admin")
+		w := httptest.NewRecorder()
+
+		handler.HandleDeleteServiceAccount(w, withURLParam(req, "id", id.String()))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		handler := NewServiceAccountHandler(new(MockServiceAccountService), zap.NewNop())
+
+		req := withCaller(httptest.NewRequest(http.MethodDelete, "/api/v1/service-accounts/nope", nil), orgID, "admin")
+		w := httptest.NewRecorder()
+
+		handler.HandleDeleteServiceAccount(w, withURLParam(req, "id", "nope"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/inference"
 	"github.com/upb/llm-control-plane/backend/utils"
 	"go.uber.org/zap"
 )
@@ -99,6 +101,28 @@ func HandleServiceError(w http.ResponseWriter, err error, logger *zap.Logger) {
 	}
 }
 
+// HandleInferenceError writes inference pipeline errors with their own status
+// code and error code; other errors are handled by HandleServiceError
+func HandleInferenceError(w http.ResponseWriter, err error, logger *zap.Logger) {
+	var inferenceErr *inference.InferenceError
+	if !errors.As(err, &inferenceErr) {
+		HandleServiceError(w, err, logger)
+		return
+	}
+
+	if inferenceErr.StatusCode >= http.StatusInternalServerError {
+		logger.Error("inference failed", zap.String("code", inferenceErr.Code), zap.Error(err))
+	}
+
+	details := map[string]interface{}{"code": inferenceErr.Code}
+	for k, v := range inferenceErr.Details {
+		details[k] = v
+	}
+	if err := utils.WriteError(w, inferenceErr.StatusCode, inferenceErr.Message, details); err != nil {
+		logger.Error("failed to write inference error response", zap.Error(err))
+	}
+}
+
 // HandleValidationError handles validation errors from request parsing
 func HandleValidationError(w http.ResponseWriter, err error, logger *zap.Logger) {
 	if utils.IsValidationError(err) {
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// SessionService defines the interface for the signed-in user's session operations
+type SessionService interface {
+	// List returns a user's active sessions, newest first
+	List(ctx context.Context, orgID uuid.UUID, subject string) ([]*models.Session, error)
+
+	// Revoke ends one of a user's sessions
+	Revoke(ctx context.Context, orgID uuid.UUID, subject string, id uuid.UUID) error
+
+	// RevokeAll ends all of a user's sessions
+	RevokeAll(ctx context.Context, orgID uuid.UUID, subject string) (int64, error)
+}
+
+// SessionResponse is a session as listed to its user
+type SessionResponse struct {
+	*models.Session
+	Current bool `json:"current"` // Whether the listing request was made with the session
+}
+
+// RevokeAllSessionsResponse reports how many sessions were ended
+type RevokeAllSessionsResponse struct {
+	Revoked int64 `json:"revoked"`
+}
+
+// SessionHandler handles the signed-in user's session HTTP requests
+type SessionHandler struct {
+	service SessionService
+	logger  *zap.Logger
+}
+
+// NewSessionHandler creates a new SessionHandler
+func NewSessionHandler(service SessionService, logger *zap.Logger) *SessionHandler {
+	return &SessionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleListSessions handles GET /v1/sessions
+func (h *SessionHandler) HandleListSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID, subject, ok := sessionOwner(w, r)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.service.List(ctx, orgID, subject)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	current := middleware.GetSessionFromContext(ctx)
+	response := make([]SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, SessionResponse{
+			Session: session,
+			Current: current != nil && current.ID == session.ID,
+		})
+	}
+
+	_ = utils.WriteOK(w, response)
+}
+
+// HandleRevokeSession handles DELETE /v1/sessions/{id}
+func (h *SessionHandler) HandleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID, subject, ok := sessionOwner(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid session ID format", nil)
+		return
+	}
+
+	if err := h.service.Revoke(ctx, orgID, subject, id); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// HandleRevokeAllSessions handles POST /v1/sessions/revoke-all, signing the
+// user out everywhere, including the session of the request
+func (h *SessionHandler) HandleRevokeAllSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID, subject, ok := sessionOwner(w, r)
+	if !ok {
+		return
+	}
+
+	revoked, err := h.service.RevokeAll(ctx, orgID, subject)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, RevokeAllSessionsResponse{Revoked: revoked})
+}
+
+// sessionOwner returns the organization and subject of the signed-in user,
+// whose sessions the request is about
+func sessionOwner(w http.ResponseWriter, r *http.Request) (uuid.UUID, string, bool) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return uuid.Nil, "", false
+	}
+
+	claims := middleware.GetClaimsFromContext(ctx)
+	if claims == nil || claims.Sub == "" {
+		_ = utils.WriteUnauthorized(w, "Authentication required")
+		return uuid.Nil, "", false
+	}
+	// API keys have no sessions
+	if middleware.GetAPIKeyFromContext(ctx) != nil {
+		_ = utils.WriteForbidden(w, "Sessions are only available to signed-in users")
+		return uuid.Nil, "", false
+	}
+
+	return orgID, claims.Sub, true
+}
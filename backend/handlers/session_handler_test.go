@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"go.uber.org/zap"
+)
+
+// MockSessionService is a mock implementation of SessionService
+type MockSessionService struct {
+	mock.Mock
+}
+
+func (m *MockSessionService) List(ctx context.Context, orgID uuid.UUID, subject string) ([]*models.Session, error) {
+	args := m.Called(ctx, orgID, subject)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Session), args.Error(1)
+}
+
+func (m *MockSessionService) Revoke(ctx context.Context, orgID uuid.UUID, subject string, id uuid.UUID) error {
+	args := m.Called(ctx, orgID, subject, id)
+	return args.Error(0)
+}
+
+func (m *MockSessionService) RevokeAll(ctx context.Context, orgID uuid.UUID, subject string) (int64, error) {
+	args := m.Called(ctx, orgID, subject)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestHandleListSessions(t *testing.T) {
+	orgID := uuid.New()
+	current := &models.Session{ID: uuid.New(), OrgID: orgID, Subject: "user-123", TokenHash: "secret-hash", RefreshToken: "secret-refresh", ExpiresAt: time.Now().Add(time.Hour)}
+	other := &models.Session{ID: uuid.New(), OrgID: orgID, Subject: "user-123", ExpiresAt: time.Now().Add(time.Hour)}
+
+	mockService := new(MockSessionService)
+	handler := NewSessionHandler(mockService, zap.NewNop())
+	mockService.On("List", mock.Anything, orgID, "user-123").Return([]*models.Session{current, other}, nil)
+
+	req := withCaller(httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil), orgID)
+	req = req.WithContext(middleware.WithSession(req.Context(), current))
+	w := httptest.NewRecorder()
+
+	handler.HandleListSessions(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"current":true`)
+	assert.Contains(t, w.Body.String(), `"current":false`)
+	assert.NotContains(t, w.Body.String(), "secret-hash")
+	assert.NotContains(t, w.Body.String(), "secret-refresh")
+}
+
+func TestHandleRevokeSession(t *testing.T) {
+	orgID := uuid.New()
+	sessionID := uuid.New()
+
+	t.Run("revokes the caller's session", func(t *testing.T) {
+		mockService := new(MockSessionService)
+		handler := NewSessionHandler(mockService, zap.NewNop())
+		mockService.On("Revoke", mock.Anything, orgID, "user-123", sessionID).Return(nil)
+
+		req := withCaller(httptest.NewRequest(http.MethodDelete, "/api/v1/sessions/"+sessionID.String(), nil), orgID)
+		w := httptest.NewRecorder()
+
+		handler.HandleRevokeSession(w, withURLParam(req, "id", sessionID.String()))
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("other users' sessions are not found", func(t *testing.T) {
+		mockService := new(MockSessionService)
+		handler := NewSessionHandler(mockService, zap.NewNop())
+		mockService.On("Revoke", mock.Anything, orgID, "user-123", sessionID).
+			Return(services.NewDomainError(services.ErrorTypeNotFound, "session not found", nil))
+
+		req := withCaller(httptest.NewRequest(http.MethodDelete, "/api/v1/sessions/"+sessionID.String(), nil), orgID)
+		w := httptest.NewRecorder()
+
+		handler.HandleRevokeSession(w, withURLParam(req, "id", sessionID.String()))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("API keys have no sessions", func(t *testing.T) {
+		handler := NewSessionHandler(new(MockSessionService), zap.NewNop())
+		key := models.NewAPIKey(orgID, uuid.New(), "server", "lcp_0123abcd", "hash", nil)
+
+		req := withCaller(httptest.NewRequest(http.MethodDelete, "/api/v1/sessions/"+sessionID.String(), nil), orgID)
+		req = req.WithContext(middleware.WithAPIKey(req.Context(), key))
+		w := httptest.NewRecorder()
+
+		handler.HandleRevokeSession(w, withURLParam(req, "id", sessionID.String()))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestHandleRevokeAllSessions(t *testing.T) {
+	orgID := uuid.New()
+	mockService := new(MockSessionService)
+	handler := NewSessionHandler(mockService, zap.NewNop())
+	mockService.On("RevokeAll", mock.Anything, orgID, "user-123").Return(int64(3), nil)
+
+	req := withCaller(httptest.NewRequest(http.MethodPost, "/api/v1/sessions/revoke-all", nil), orgID)
+	w := httptest.NewRecorder()
+
+	handler.HandleRevokeAllSessions(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"revoked":3`)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// ShadowService defines the interface for reading shadow traffic results
+type ShadowService interface {
+	// GetShadowResponses retrieves the shadow results of an organization's inference request
+	GetShadowResponses(ctx context.Context, inferenceID, orgID uuid.UUID) ([]*models.ShadowResponse, error)
+}
+
+// ShadowHandler handles shadow traffic HTTP requests
+type ShadowHandler struct {
+	service ShadowService
+	logger  *zap.Logger
+}
+
+// NewShadowHandler creates a new ShadowHandler
+func NewShadowHandler(service ShadowService, logger *zap.Logger) *ShadowHandler {
+	return &ShadowHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleGetShadowResponses handles GET /v1/inference/requests/{id}/shadow
+func (h *ShadowHandler) HandleGetShadowResponses(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	inferenceID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid inference request ID format", nil)
+		return
+	}
+
+	results, err := h.service.GetShadowResponses(ctx, inferenceID, orgID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, results)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"go.uber.org/zap"
+)
+
+// MockShadowService is a mock implementation of ShadowService
+type MockShadowService struct {
+	mock.Mock
+}
+
+func (m *MockShadowService) GetShadowResponses(ctx context.Context, inferenceID, orgID uuid.UUID) ([]*models.ShadowResponse, error) {
+	args := m.Called(ctx, inferenceID, orgID)
+	if results := args.Get(0); results != nil {
+		return results.([]*models.ShadowResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func TestHandleGetShadowResponses(t *testing.T) {
+	logger := zap.NewNop()
+	orgID := uuid.New()
+	inferenceID := uuid.New()
+
+	t.Run("returns shadow responses", func(t *testing.T) {
+		mockService := new(MockShadowService)
+		handler := NewShadowHandler(mockService, logger)
+
+		result := models.NewShadowResponse(inferenceID, orgID, uuid.New(), "shadow", "shadow-model")
+		result.MarkAsCompleted("shadow answer", 10, 5, 120, 0.01)
+		mockService.On("GetShadowResponses", mock.Anything, inferenceID, orgID).
+			Return([]*models.ShadowResponse{result}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/inference/requests/"+inferenceID.String()+"/shadow", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		req = withURLParam(req, "id", inferenceID.String())
+		w := httptest.NewRecorder()
+
+		handler.HandleGetShadowResponses(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		data := response["data"].([]interface{})
+		require.Len(t, data, 1)
+		assert.Equal(t, "shadow answer", data[0].(map[string]interface{})["response"])
+	})
+
+	t.Run("service error", func(t *testing.T) {
+		mockService := new(MockShadowService)
+		handler := NewShadowHandler(mockService, logger)
+
+		mockService.On("GetShadowResponses", mock.Anything, inferenceID, orgID).Return(nil, errors.New("db down"))
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/inference/requests/x/shadow", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		req = withURLParam(req, "id", inferenceID.String())
+		w := httptest.NewRecorder()
+
+		handler.HandleGetShadowResponses(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		handler := NewShadowHandler(new(MockShadowService), logger)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/inference/requests/bad/shadow", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		req = withURLParam(req, "id", "bad")
+		w := httptest.NewRecorder()
+
+		handler.HandleGetShadowResponses(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("missing organization", func(t *testing.T) {
+		handler := NewShadowHandler(new(MockShadowService), logger)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/inference/requests/x/shadow", nil)
+		req = withURLParam(req, "id", inferenceID.String())
+		w := httptest.NewRecorder()
+
+		handler.HandleGetShadowResponses(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
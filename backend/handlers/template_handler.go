@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/template"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// CreateTemplateRequest represents a request to create a prompt template
+type CreateTemplateRequest struct {
+	Name        string                    `json:"name" validate:"required,max=255"`
+	Description string                    `json:"description,omitempty"`
+	Messages    []models.TemplateMessage  `json:"messages" validate:"required,min=1"`
+	Variables   []models.TemplateVariable `json:"variables,omitempty"`
+}
+
+// CreateTemplateVersionRequest represents a request to add a template version
+type CreateTemplateVersionRequest struct {
+	Messages  []models.TemplateMessage  `json:"messages" validate:"required,min=1"`
+	Variables []models.TemplateVariable `json:"variables,omitempty"`
+	Activate  bool                      `json:"activate"`
+}
+
+// RollbackTemplateRequest represents a request to change a template's active version.
+// A zero version rolls back to the version before the active one.
+type RollbackTemplateRequest struct {
+	Version int `json:"version" validate:"gte=0"`
+}
+
+// PinTemplateRequest represents a request to pin an application to a version
+type PinTemplateRequest struct {
+	Version int `json:"version" validate:"required,gt=0"`
+}
+
+// TemplateService defines the interface for prompt template operations
+type TemplateService interface {
+	// CreateTemplate creates a template with its first version
+	CreateTemplate(ctx context.Context, req template.CreateTemplateRequest) (*models.PromptTemplate, error)
+
+	// GetTemplate retrieves a template scoped to an organization
+	GetTemplate(ctx context.Context, id, orgID uuid.UUID) (*models.PromptTemplate, error)
+
+	// ListTemplates lists all templates for an organization
+	ListTemplates(ctx context.Context, orgID uuid.UUID) ([]*models.PromptTemplate, error)
+
+	// DeleteTemplate deletes a template with its versions and pins
+	DeleteTemplate(ctx context.Context, id, orgID uuid.UUID) error
+
+	// CreateVersion adds a version to a template
+	CreateVersion(ctx context.Context, id, orgID uuid.UUID, req template.CreateVersionRequest) (*models.PromptTemplateVersion, error)
+
+	// ListVersions lists all versions of a template
+	ListVersions(ctx context.Context, id, orgID uuid.UUID) ([]*models.PromptTemplateVersion, error)
+
+	// GetVersion retrieves a specific version of a template
+	GetVersion(ctx context.Context, id, orgID uuid.UUID, version int) (*models.PromptTemplateVersion, error)
+
+	// Rollback changes a template's active version
+	Rollback(ctx context.Context, id, orgID uuid.UUID, version int) (*models.PromptTemplate, error)
+
+	// PinVersion pins an application to a template version
+	PinVersion(ctx context.Context, id, orgID, appID uuid.UUID, version int) (*models.PromptTemplatePin, error)
+
+	// UnpinVersion removes an application's pin
+	UnpinVersion(ctx context.Context, id, orgID, appID uuid.UUID) error
+}
+
+// TemplateHandler handles prompt template HTTP requests
+type TemplateHandler struct {
+	service TemplateService
+	logger  *zap.Logger
+}
+
+// NewTemplateHandler creates a new TemplateHandler
+func NewTemplateHandler(service TemplateService, logger *zap.Logger) *TemplateHandler {
+	return &TemplateHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleListTemplates handles GET /v1/templates
+func (h *TemplateHandler) HandleListTemplates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	templates, err := h.service.ListTemplates(ctx, orgID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, templates)
+}
+
+// HandleCreateTemplate handles POST /v1/templates
+func (h *TemplateHandler) HandleCreateTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := middleware.GetRequestIDFromContext(ctx)
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	var req CreateTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.Warn("failed to parse request body",
+			zap.String("request_id", requestID),
+			zap.Error(err))
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	created, err := h.service.CreateTemplate(ctx, template.CreateTemplateRequest{
+		OrgID:       orgID,
+		Name:        req.Name,
+		Description: req.Description,
+		Messages:    req.Messages,
+		Variables:   req.Variables,
+		CreatedBy:   middleware.GetUserIDFromContext(ctx),
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	h.logger.Info("prompt template created",
+		zap.String("request_id", requestID),
+		zap.String("template_id", created.ID.String()))
+
+	_ = utils.WriteCreated(w, created)
+}
+
+// HandleGetTemplate handles GET /v1/templates/{id}
+func (h *TemplateHandler) HandleGetTemplate(w http.ResponseWriter, r *http.Request) {
+	h.withTemplateID(w, r, func(ctx context.Context, id, orgID uuid.UUID) (interface{}, error) {
+		return h.service.GetTemplate(ctx, id, orgID)
+	})
+}
+
+// HandleDeleteTemplate handles DELETE /v1/templates/{id}
+func (h *TemplateHandler) HandleDeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid template ID format", nil)
+		return
+	}
+
+	if err := h.service.DeleteTemplate(ctx, id, orgID); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// HandleListVersions handles GET /v1/templates/{id}/versions
+func (h *TemplateHandler) HandleListVersions(w http.ResponseWriter, r *http.Request) {
+	h.withTemplateID(w, r, func(ctx context.Context, id, orgID uuid.UUID) (interface{}, error) {
+		return h.service.ListVersions(ctx, id, orgID)
+	})
+}
+
+// HandleGetVersion handles GET /v1/templates/{id}/versions/{version}
+func (h *TemplateHandler) HandleGetVersion(w http.ResponseWriter, r *http.Request) {
+	version, err := strconv.Atoi(chi.URLParam(r, "version"))
+	if err != nil || version <= 0 {
+		_ = utils.WriteBadRequest(w, "Invalid template version", nil)
+		return
+	}
+
+	h.withTemplateID(w, r, func(ctx context.Context, id, orgID uuid.UUID) (interface{}, error) {
+		return h.service.GetVersion(ctx, id, orgID, version)
+	})
+}
+
+// HandleCreateVersion handles POST /v1/templates/{id}/versions
+func (h *TemplateHandler) HandleCreateVersion(w http.ResponseWriter, r *http.Request) {
+	var req CreateTemplateVersionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	ctx := r.Context()
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid template ID format", nil)
+		return
+	}
+
+	version, err := h.service.CreateVersion(ctx, id, orgID, template.CreateVersionRequest{
+		Messages:  req.Messages,
+		Variables: req.Variables,
+		CreatedBy: middleware.GetUserIDFromContext(ctx),
+		Activate:  req.Activate,
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteCreated(w, version)
+}
+
+// HandleRollback handles POST /v1/templates/{id}/rollback
+func (h *TemplateHandler) HandleRollback(w http.ResponseWriter, r *http.Request) {
+	var req RollbackTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	h.withTemplateID(w, r, func(ctx context.Context, id, orgID uuid.UUID) (interface{}, error) {
+		return h.service.Rollback(ctx, id, orgID, req.Version)
+	})
+}
+
+// HandlePinVersion handles PUT /v1/templates/{id}/pins/{appID}
+func (h *TemplateHandler) HandlePinVersion(w http.ResponseWriter, r *http.Request) {
+	appID, err := uuid.Parse(chi.URLParam(r, "appID"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid application ID format", nil)
+		return
+	}
+
+	var req PinTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	h.withTemplateID(w, r, func(ctx context.Context, id, orgID uuid.UUID) (interface{}, error) {
+		return h.service.PinVersion(ctx, id, orgID, appID, req.Version)
+	})
+}
+
+// HandleUnpinVersion handles DELETE /v1/templates/{id}/pins/{appID}
+func (h *TemplateHandler) HandleUnpinVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid template ID format", nil)
+		return
+	}
+
+	appID, err := uuid.Parse(chi.URLParam(r, "appID"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid application ID format", nil)
+		return
+	}
+
+	if err := h.service.UnpinVersion(ctx, id, orgID, appID); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// withTemplateID parses tenant and template ID, then writes the result of fn
+func (h *TemplateHandler) withTemplateID(w http.ResponseWriter, r *http.Request, fn func(ctx context.Context, id, orgID uuid.UUID) (interface{}, error)) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid template ID format", nil)
+		return
+	}
+
+	result, err := fn(ctx, id, orgID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, result)
+}
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/template"
+	"go.uber.org/zap"
+)
+
+// MockTemplateService is a mock implementation of TemplateService
+type MockTemplateService struct {
+	mock.Mock
+}
+
+func (m *MockTemplateService) CreateTemplate(ctx context.Context, req template.CreateTemplateRequest) (*models.PromptTemplate, error) {
+	args := m.Called(ctx, req)
+	if t := args.Get(0); t != nil {
+		return t.(*models.PromptTemplate), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTemplateService) GetTemplate(ctx context.Context, id, orgID uuid.UUID) (*models.PromptTemplate, error) {
+	args := m.Called(ctx, id, orgID)
+	if t := args.Get(0); t != nil {
+		return t.(*models.PromptTemplate), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTemplateService) ListTemplates(ctx context.Context, orgID uuid.UUID) ([]*models.PromptTemplate, error) {
+	args := m.Called(ctx, orgID)
+	if t := args.Get(0); t != nil {
+		return t.([]*models.PromptTemplate), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTemplateService) DeleteTemplate(ctx context.Context, id, orgID uuid.UUID) error {
+	args := m.Called(ctx, id, orgID)
+	return args.Error(0)
+}
+
+func (m *MockTemplateService) CreateVersion(ctx context.Context, id, orgID uuid.UUID, req template.CreateVersionRequest) (*models.PromptTemplateVersion, error) {
+	args := m.Called(ctx, id, orgID, req)
+	if v := args.Get(0); v != nil {
+		return v.(*models.PromptTemplateVersion), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTemplateService) ListVersions(ctx context.Context, id, orgID uuid.UUID) ([]*models.PromptTemplateVersion, error) {
+	args := m.Called(ctx, id, orgID)
+	if v := args.Get(0); v != nil {
+		return v.([]*models.PromptTemplateVersion), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTemplateService) GetVersion(ctx context.Context, id, orgID uuid.UUID, version int) (*models.PromptTemplateVersion, error) {
+	args := m.Called(ctx, id, orgID, version)
+	if v := args.Get(0); v != nil {
+		return v.(*models.PromptTemplateVersion), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTemplateService) Rollback(ctx context.Context, id, orgID uuid.UUID, version int) (*models.PromptTemplate, error) {
+	args := m.Called(ctx, id, orgID, version)
+	if t := args.Get(0); t != nil {
+		return t.(*models.PromptTemplate), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTemplateService) PinVersion(ctx context.Context, id, orgID, appID uuid.UUID, version int) (*models.PromptTemplatePin, error) {
+	args := m.Called(ctx, id, orgID, appID, version)
+	if p := args.Get(0); p != nil {
+		return p.(*models.PromptTemplatePin), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockTemplateService) UnpinVersion(ctx context.Context, id, orgID, appID uuid.UUID) error {
+	args := m.Called(ctx, id, orgID, appID)
+	return args.Error(0)
+}
+
+func TestHandleCreateTemplate(t *testing.T) {
+	logger := zap.NewNop()
+	orgID := uuid.New()
+
+	t.Run("successful creation", func(t *testing.T) {
+		mockService := new(MockTemplateService)
+		handler := NewTemplateHandler(mockService, logger)
+
+		created := models.NewPromptTemplate(orgID, "support", "")
+		created.SetActiveVersion(1)
+		mockService.On("CreateTemplate", mock.Anything, mock.MatchedBy(func(r template.CreateTemplateRequest) bool {
+			return r.OrgID == orgID && r.Name == "support" && len(r.Messages) == 1
+		})).Return(created, nil)
+
+		body := []byte(`{"name":"support","messages":[{"role":"system","content":"You support {{product}}."}]}`)
+		req := httptest.NewRequest(http.MethodPost, "/v1/templates", bytes.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateTemplate(w, req)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("missing messages", func(t *testing.T) {
+		handler := NewTemplateHandler(new(MockTemplateService), logger)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/templates", bytes.NewReader([]byte(`{"name":"support"}`)))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateTemplate(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("duplicate name", func(t *testing.T) {
+		mockService := new(MockTemplateService)
+		handler := NewTemplateHandler(mockService, logger)
+
+		mockService.On("CreateTemplate", mock.Anything, mock.Anything).
+			Return(nil, services.NewDomainError(services.ErrorTypeConflict, `template "support" already exists`, nil))
+
+		body := []byte(`{"name":"support","messages":[{"role":"system","content":"hi"}]}`)
+		req := httptest.NewRequest(http.MethodPost, "/v1/templates", bytes.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateTemplate(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func TestHandleGetTemplateVersion(t *testing.T) {
+	logger := zap.NewNop()
+	orgID := uuid.New()
+	templateID := uuid.New()
+
+	t.Run("returns version", func(t *testing.T) {
+		mockService := new(MockTemplateService)
+		handler := NewTemplateHandler(mockService, logger)
+
+		version, err := models.NewPromptTemplateVersion(templateID, []models.TemplateMessage{{Role: "user", Content: "hi"}}, nil)
+		require.NoError(t, err)
+		version.Version = 2
+		mockService.On("GetVersion", mock.Anything, templateID, orgID, 2).Return(version, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/templates/x/versions/2", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		req = withURLParams(req, map[string]string{"id": templateID.String(), "version": "2"})
+		w := httptest.NewRecorder()
+
+		handler.HandleGetVersion(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		data := response["data"].(map[string]interface{})
+		assert.Equal(t, float64(2), data["version"])
+	})
+
+	t.Run("invalid version", func(t *testing.T) {
+		handler := NewTemplateHandler(new(MockTemplateService), logger)
+
+		req := httptest.NewRequest(http.MethodGet, "/v1/templates/x/versions/latest", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		req = withURLParams(req, map[string]string{"id": templateID.String(), "version": "latest"})
+		w := httptest.NewRecorder()
+
+		handler.HandleGetVersion(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleRollbackTemplate(t *testing.T) {
+	logger := zap.NewNop()
+	orgID := uuid.New()
+	templateID := uuid.New()
+
+	mockService := new(MockTemplateService)
+	handler := NewTemplateHandler(mockService, logger)
+
+	rolled := models.NewPromptTemplate(orgID, "support", "")
+	rolled.SetActiveVersion(1)
+	mockService.On("Rollback", mock.Anything, templateID, orgID, 0).Return(rolled, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/templates/x/rollback", bytes.NewReader([]byte(`{}`)))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+	req = withURLParam(req, "id", templateID.String())
+	w := httptest.NewRecorder()
+
+	handler.HandleRollback(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandlePinTemplateVersion(t *testing.T) {
+	logger := zap.NewNop()
+	orgID := uuid.New()
+	templateID := uuid.New()
+	appID := uuid.New()
+
+	t.Run("pins version", func(t *testing.T) {
+		mockService := new(MockTemplateService)
+		handler := NewTemplateHandler(mockService, logger)
+
+		mockService.On("PinVersion", mock.Anything, templateID, orgID, appID, 3).
+			Return(&models.PromptTemplatePin{TemplateID: templateID, AppID: appID, Version: 3}, nil)
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/templates/x/pins/y", bytes.NewReader([]byte(`{"version":3}`)))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		req = withURLParams(req, map[string]string{"id": templateID.String(), "appID": appID.String()})
+		w := httptest.NewRecorder()
+
+		handler.HandlePinVersion(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("version required", func(t *testing.T) {
+		handler := NewTemplateHandler(new(MockTemplateService), logger)
+
+		req := httptest.NewRequest(http.MethodPut, "/v1/templates/x/pins/y", bytes.NewReader([]byte(`{}`)))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.OrgIDKey, orgID))
+		req = withURLParams(req, map[string]string{"id": templateID.String(), "appID": appID.String()})
+		w := httptest.NewRecorder()
+
+		handler.HandlePinVersion(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func withURLParams(req *http.Request, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for key, value := range params {
+		rctx.URLParams.Add(key, value)
+	}
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
@@ -0,0 +1,538 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/tenant"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// CreateOrganizationRequest represents a request to create an organization
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+	Slug string `json:"slug" validate:"required,min=3,max=63"`
+}
+
+// UpdateOrganizationRequest represents a request to rename an organization or
+// change its slug
+type UpdateOrganizationRequest struct {
+	Name *string `json:"name,omitempty" validate:"omitempty,max=255"`
+	Slug *string `json:"slug,omitempty" validate:"omitempty,min=3,max=63"`
+}
+
+// CreateApplicationRequest represents a request to create an application;
+// only superadmins may create applications in other organizations
+type CreateApplicationRequest struct {
+	OrgID *uuid.UUID `json:"org_id,omitempty"`
+	Name  string     `json:"name" validate:"required,max=255"`
+}
+
+// UpdateApplicationRequest represents a request to rename an application
+type UpdateApplicationRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+// UpdateUserRequest represents a request to change a user's role or display name
+type UpdateUserRequest struct {
+	Role        *string `json:"role,omitempty" validate:"omitempty,max=50"`
+	DisplayName *string `json:"display_name,omitempty" validate:"omitempty,max=255"`
+}
+
+// TenantService defines the interface for tenant-scoped management
+type TenantService interface {
+	// GetOrganization retrieves an organization
+	GetOrganization(ctx context.Context, scope tenant.Scope, id uuid.UUID) (*models.Organization, error)
+
+	// ListOrganizations lists the organizations visible to the caller
+	ListOrganizations(ctx context.Context, scope tenant.Scope, limit, offset int) ([]*models.Organization, error)
+
+	// CreateOrganization creates an organization
+	CreateOrganization(ctx context.Context, scope tenant.Scope, req tenant.CreateOrganizationRequest) (*models.Organization, error)
+
+	// UpdateOrganization renames an organization or changes its slug
+	UpdateOrganization(ctx context.Context, scope tenant.Scope, req tenant.UpdateOrganizationRequest) (*models.Organization, error)
+
+	// DeleteOrganization soft-deletes an organization with its applications
+	DeleteOrganization(ctx context.Context, scope tenant.Scope, id uuid.UUID) error
+
+	// RestoreOrganization restores a deleted organization with its applications
+	RestoreOrganization(ctx context.Context, scope tenant.Scope, id uuid.UUID) (*models.Organization, error)
+
+	// GetApplication retrieves an application
+	GetApplication(ctx context.Context, scope tenant.Scope, id uuid.UUID) (*models.Application, error)
+
+	// ListApplications lists the applications of an organization
+	ListApplications(ctx context.Context, scope tenant.Scope, orgID uuid.UUID) ([]*models.Application, error)
+
+	// CreateApplication creates an application with an initial API key
+	CreateApplication(ctx context.Context, scope tenant.Scope, req tenant.CreateApplicationRequest) (*tenant.CreatedApplication, error)
+
+	// UpdateApplication renames an application
+	UpdateApplication(ctx context.Context, scope tenant.Scope, req tenant.UpdateApplicationRequest) (*models.Application, error)
+
+	// DeleteApplication soft-deletes an application
+	DeleteApplication(ctx context.Context, scope tenant.Scope, id uuid.UUID) error
+
+	// RestoreApplication restores a deleted application
+	RestoreApplication(ctx context.Context, scope tenant.Scope, id uuid.UUID) (*models.Application, error)
+
+	// GetUser retrieves a user
+	GetUser(ctx context.Context, scope tenant.Scope, id uuid.UUID) (*models.User, error)
+
+	// ListUsers lists the users of an organization
+	ListUsers(ctx context.Context, scope tenant.Scope, orgID uuid.UUID) ([]*models.User, error)
+
+	// UpdateUser changes a user's role or display name
+	UpdateUser(ctx context.Context, scope tenant.Scope, req tenant.UpdateUserRequest) (*models.User, error)
+}
+
+// TenantHandler handles organization, application and user HTTP requests.
+// IDs of other organizations are not found; only superadmins cross tenants.
+type TenantHandler struct {
+	service TenantService
+	logger  *zap.Logger
+}
+
+// NewTenantHandler creates a new TenantHandler
+func NewTenantHandler(service TenantService, logger *zap.Logger) *TenantHandler {
+	return &TenantHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleListOrganizations handles GET /api/v1/organizations
+func (h *TenantHandler) HandleListOrganizations(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	orgs, err := h.service.ListOrganizations(r.Context(), scope, limit, offset)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, orgs)
+}
+
+// HandleGetOrganization handles GET /api/v1/organizations/{id}
+func (h *TenantHandler) HandleGetOrganization(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid organization ID", nil)
+		return
+	}
+
+	org, err := h.service.GetOrganization(r.Context(), scope, id)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, org)
+}
+
+// HandleCreateOrganization handles POST /api/v1/organizations
+func (h *TenantHandler) HandleCreateOrganization(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	org, err := h.service.CreateOrganization(r.Context(), scope, tenant.CreateOrganizationRequest{
+		Name: req.Name,
+		Slug: req.Slug,
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteCreated(w, org)
+}
+
+// HandleUpdateOrganization handles PUT /api/v1/organizations/{id}
+func (h *TenantHandler) HandleUpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid organization ID", nil)
+		return
+	}
+
+	var req UpdateOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	org, err := h.service.UpdateOrganization(r.Context(), scope, tenant.UpdateOrganizationRequest{
+		ID:   id,
+		Name: req.Name,
+		Slug: req.Slug,
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, org)
+}
+
+// HandleDeleteOrganization handles DELETE /api/v1/organizations/{id}
+func (h *TenantHandler) HandleDeleteOrganization(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid organization ID", nil)
+		return
+	}
+
+	if err := h.service.DeleteOrganization(r.Context(), scope, id); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// HandleRestoreOrganization handles POST /api/v1/organizations/{id}/restore
+func (h *TenantHandler) HandleRestoreOrganization(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid organization ID", nil)
+		return
+	}
+
+	org, err := h.service.RestoreOrganization(r.Context(), scope, id)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, org)
+}
+
+// HandleListApplications handles GET /api/v1/applications; superadmins may
+// pass ?org_id= to list another organization's applications
+func (h *TenantHandler) HandleListApplications(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	orgID, ok := queryOrgID(w, r)
+	if !ok {
+		return
+	}
+
+	apps, err := h.service.ListApplications(r.Context(), scope, orgID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, apps)
+}
+
+// HandleGetApplication handles GET /api/v1/applications/{id}
+func (h *TenantHandler) HandleGetApplication(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid application ID", nil)
+		return
+	}
+
+	app, err := h.service.GetApplication(r.Context(), scope, id)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, app)
+}
+
+// HandleCreateApplication handles POST /api/v1/applications. The response
+// holds the application's initial API key, which is not shown again.
+func (h *TenantHandler) HandleCreateApplication(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	var req CreateApplicationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	orgID := uuid.Nil
+	if req.OrgID != nil {
+		orgID = *req.OrgID
+	}
+
+	created, err := h.service.CreateApplication(r.Context(), scope, tenant.CreateApplicationRequest{
+		OrgID: orgID,
+		Name:  req.Name,
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteCreated(w, created)
+}
+
+// HandleUpdateApplication handles PUT /api/v1/applications/{id}
+func (h *TenantHandler) HandleUpdateApplication(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid application ID", nil)
+		return
+	}
+
+	var req UpdateApplicationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	app, err := h.service.UpdateApplication(r.Context(), scope, tenant.UpdateApplicationRequest{
+		ID:   id,
+		Name: req.Name,
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, app)
+}
+
+// HandleDeleteApplication handles DELETE /api/v1/applications/{id}
+func (h *TenantHandler) HandleDeleteApplication(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid application ID", nil)
+		return
+	}
+
+	if err := h.service.DeleteApplication(r.Context(), scope, id); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// HandleRestoreApplication handles POST /api/v1/applications/{id}/restore
+func (h *TenantHandler) HandleRestoreApplication(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid application ID", nil)
+		return
+	}
+
+	app, err := h.service.RestoreApplication(r.Context(), scope, id)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, app)
+}
+
+// HandleListUsers handles GET /api/v1/users; superadmins may pass ?org_id=
+// to list another organization's users
+func (h *TenantHandler) HandleListUsers(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	orgID, ok := queryOrgID(w, r)
+	if !ok {
+		return
+	}
+
+	users, err := h.service.ListUsers(r.Context(), scope, orgID)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, users)
+}
+
+// HandleGetUser handles GET /api/v1/users/{id}
+func (h *TenantHandler) HandleGetUser(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid user ID", nil)
+		return
+	}
+
+	user, err := h.service.GetUser(r.Context(), scope, id)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, user)
+}
+
+// HandleUpdateUser handles PUT /api/v1/users/{id}
+func (h *TenantHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request) {
+	scope, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid user ID", nil)
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	user, err := h.service.UpdateUser(r.Context(), scope, tenant.UpdateUserRequest{
+		ID:          id,
+		Role:        req.Role,
+		DisplayName: req.DisplayName,
+	})
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, user)
+}
+
+// scope builds the caller's tenant scope from the request context. Changes
+// are attributed to the impersonating admin when impersonating.
+func (h *TenantHandler) scope(w http.ResponseWriter, r *http.Request) (tenant.Scope, bool) {
+	ctx := r.Context()
+
+	orgID := middleware.GetOrgIDFromContext(ctx)
+	claims := middleware.GetClaimsFromContext(ctx)
+	if orgID == uuid.Nil || claims == nil {
+		_ = utils.WriteUnauthorized(w, "Missing organization information")
+		return tenant.Scope{}, false
+	}
+
+	return tenant.Scope{
+		OrgID:      orgID,
+		UserID:     middleware.GetActorIDFromContext(ctx),
+		Subject:    claims.Sub,
+		SuperAdmin: claims.IsSuperAdmin(),
+		Roles:      claims.Groups,
+		Scopes:     claims.Scopes,
+	}, true
+}
+
+// queryOrgID parses the optional ?org_id= query parameter
+func queryOrgID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	value := r.URL.Query().Get("org_id")
+	if value == "" {
+		return uuid.Nil, true
+	}
+
+	orgID, err := uuid.Parse(value)
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid org_id", nil)
+		return uuid.Nil, false
+	}
+	return orgID, true
+}
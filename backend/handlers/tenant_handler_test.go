@@ -0,0 +1,377 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/apikey"
+	"github.com/upb/llm-control-plane/backend/services/tenant"
+	"go.uber.org/zap"
+)
+
+// MockTenantService is a mock implementation of TenantService
+type MockTenantService struct {
+	mock.Mock
+}
+
+func (m *MockTenantService) GetOrganization(ctx context.Context, scope tenant.Scope, id uuid.UUID) (*models.Organization, error) {
+	args := m.Called(ctx, scope, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockTenantService) ListOrganizations(ctx context.Context, scope tenant.Scope, limit, offset int) ([]*models.Organization, error) {
+	args := m.Called(ctx, scope, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Organization), args.Error(1)
+}
+
+func (m *MockTenantService) CreateOrganization(ctx context.Context, scope tenant.Scope, req tenant.CreateOrganizationRequest) (*models.Organization, error) {
+	args := m.Called(ctx, scope, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockTenantService) UpdateOrganization(ctx context.Context, scope tenant.Scope, req tenant.UpdateOrganizationRequest) (*models.Organization, error) {
+	args := m.Called(ctx, scope, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockTenantService) DeleteOrganization(ctx context.Context, scope tenant.Scope, id uuid.UUID) error {
+	args := m.Called(ctx, scope, id)
+	return args.Error(0)
+}
+
+func (m *MockTenantService) RestoreOrganization(ctx context.Context, scope tenant.Scope, id uuid.UUID) (*models.Organization, error) {
+	args := m.Called(ctx, scope, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Organization), args.Error(1)
+}
+
+func (m *MockTenantService) CreateApplication(ctx context.Context, scope tenant.Scope, req tenant.CreateApplicationRequest) (*tenant.CreatedApplication, error) {
+	args := m.Called(ctx, scope, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*tenant.CreatedApplication), args.Error(1)
+}
+
+func (m *MockTenantService) UpdateApplication(ctx context.Context, scope tenant.Scope, req tenant.UpdateApplicationRequest) (*models.Application, error) {
+	args := m.Called(ctx, scope, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Application), args.Error(1)
+}
+
+func (m *MockTenantService) DeleteApplication(ctx context.Context, scope tenant.Scope, id uuid.UUID) error {
+	args := m.Called(ctx, scope, id)
+	return args.Error(0)
+}
+
+func (m *MockTenantService) RestoreApplication(ctx context.Context, scope tenant.Scope, id uuid.UUID) (*models.Application, error) {
+	args := m.Called(ctx, scope, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Application), args.Error(1)
+}
+
+func (m *MockTenantService) GetApplication(ctx context.Context, scope tenant.Scope, id uuid.UUID) (*models.Application, error) {
+	args := m.Called(ctx, scope, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Application), args.Error(1)
+}
+
+func (m *MockTenantService) ListApplications(ctx context.Context, scope tenant.Scope, orgID uuid.UUID) ([]*models.Application, error) {
+	args := m.Called(ctx, scope, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Application), args.Error(1)
+}
+
+func (m *MockTenantService) GetUser(ctx context.Context, scope tenant.Scope, id uuid.UUID) (*models.User, error) {
+	args := m.Called(ctx, scope, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockTenantService) UpdateUser(ctx context.Context, scope tenant.Scope, req tenant.UpdateUserRequest) (*models.User, error) {
+	args := m.Called(ctx, scope, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.User), args.Error(1)
+}
+
+func (m *MockTenantService) ListUsers(ctx context.Context, scope tenant.Scope, orgID uuid.UUID) ([]*models.User, error) {
+	args := m.Called(ctx, scope, orgID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.User), args.Error(1)
+}
+
+// withCaller adds a caller's claims and tenant to a request
+func withCaller(req *http.Request, orgID uuid.UUID, groups ...string) *http.Request {
+	req = withTenant(req, orgID, uuid.Nil)
+	return req.WithContext(middleware.WithClaims(req.Context(), &middleware.Claims{
+		Sub:    "user-123",
+		OrgID:  orgID.String(),
+		Groups: groups,
+	}))
+}
+
+func TestHandleGetApplication(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("other tenant's application is not found", func(t *testing.T) {
+		mockService := new(MockTenantService)
+		handler := NewTenantHandler(mockService, zap.NewNop())
+		appID := uuid.New()
+		mockService.On("GetApplication", mock.Anything, mock.MatchedBy(func(scope tenant.Scope) bool {
+			return scope.OrgID == orgID && !scope.SuperAdmin
+		}), appID).Return(nil, services.NewDomainError(services.ErrorTypeNotFound, "application not found", nil))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/applications/"+appID.String(), nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleGetApplication(w, withURLParam(withCaller(req, orgID, "admin"), "id", appID.String()))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("superadmin scope", func(t *testing.T) {
+		mockService := new(MockTenantService)
+		handler := NewTenantHandler(mockService, zap.NewNop())
+		app := models.NewApplication(uuid.New(), "other", "")
+		mockService.On("GetApplication", mock.Anything, mock.MatchedBy(func(scope tenant.Scope) bool {
+			return scope.OrgID == orgID && scope.SuperAdmin && scope.Subject == "user-123"
+		}), app.ID).Return(app, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/applications/"+app.ID.String(), nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleGetApplication(w, withURLParam(withCaller(req, orgID, "superadmin"), "id", app.ID.String()))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("requires tenant", func(t *testing.T) {
+		handler := NewTenantHandler(new(MockTenantService), zap.NewNop())
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/applications/x", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleGetApplication(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+func TestHandleListUsers(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("lists the caller's organization", func(t *testing.T) {
+		mockService := new(MockTenantService)
+		handler := NewTenantHandler(mockService, zap.NewNop())
+		user := models.NewUser("a@acme.test", "sub", orgID, models.RoleMember)
+		mockService.On("ListUsers", mock.Anything, mock.Anything, uuid.Nil).Return([]*models.User{user}, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleListUsers(w, withCaller(req, orgID, "viewer"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "a@acme.test")
+	})
+
+	t.Run("invalid org_id", func(t *testing.T) {
+		handler := NewTenantHandler(new(MockTenantService), zap.NewNop())
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users?org_id=acme", nil)
+		w := httptest.NewRecorder()
+
+		handler.HandleListUsers(w, withCaller(req, orgID, "viewer"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleCreateOrganization(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("slug conflict", func(t *testing.T) {
+		mockService := new(MockTenantService)
+		handler := NewTenantHandler(mockService, zap.NewNop())
+		mockService.On("CreateOrganization", mock.Anything, mock.Anything, tenant.CreateOrganizationRequest{Name: "Acme", Slug: "acme"}).
+			Return(nil, services.NewDomainError(services.ErrorTypeConflict, `slug "acme" is taken`, nil))
+
+		body, _ := json.Marshal(CreateOrganizationRequest{Name: "Acme", Slug: "acme"})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/organizations", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateOrganization(w, withCaller(req, orgID, "superadmin"))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("missing slug", func(t *testing.T) {
+		handler := NewTenantHandler(new(MockTenantService), zap.NewNop())
+
+		body, _ := json.Marshal(CreateOrganizationRequest{Name: "Acme"})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/organizations", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateOrganization(w, withCaller(req, orgID, "superadmin"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleCreateApplication(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("returns the initial key", func(t *testing.T) {
+		mockService := new(MockTenantService)
+		handler := NewTenantHandler(mockService, zap.NewNop())
+		app := models.NewApplication(orgID, "chat", "")
+		key := models.NewAPIKey(orgID, app.ID, "Default", "sk-abc", "hash", nil)
+		mockService.On("CreateApplication", mock.Anything, mock.Anything, tenant.CreateApplicationRequest{Name: "chat"}).
+			Return(&tenant.CreatedApplication{Application: app, APIKey: &apikey.IssuedKey{APIKey: key, Key: "sk-abc-secret"}}, nil)
+
+		body, _ := json.Marshal(CreateApplicationRequest{Name: "chat"})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/applications", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateApplication(w, withCaller(req, orgID, "admin"))
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), "sk-abc-secret")
+		assert.NotContains(t, w.Body.String(), `"hash"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		handler := NewTenantHandler(new(MockTenantService), zap.NewNop())
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/applications", bytes.NewReader([]byte(`{}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleCreateApplication(w, withCaller(req, orgID, "admin"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestHandleDeleteOrganization(t *testing.T) {
+	orgID := uuid.New()
+	mockService := new(MockTenantService)
+	handler := NewTenantHandler(mockService, zap.NewNop())
+	mockService.On("DeleteOrganization", mock.Anything, mock.Anything, orgID).Return(nil)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/organizations/"+orgID.String(), nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleDeleteOrganization(w, withURLParam(withCaller(req, orgID, "admin"), "id", orgID.String()))
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandleRestoreApplication(t *testing.T) {
+	orgID := uuid.New()
+	appID := uuid.New()
+	mockService := new(MockTenantService)
+	handler := NewTenantHandler(mockService, zap.NewNop())
+	mockService.On("RestoreApplication", mock.Anything, mock.Anything, appID).
+		Return(nil, services.NewDomainError(services.ErrorTypeConflict, "restore the organization instead", nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/applications/"+appID.String()+"/restore", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleRestoreApplication(w, withURLParam(withCaller(req, orgID, "admin"), "id", appID.String()))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandleUpdateUser(t *testing.T) {
+	orgID := uuid.New()
+	userID := uuid.New()
+
+	t.Run("assigns the role", func(t *testing.T) {
+		mockService := new(MockTenantService)
+		handler := NewTenantHandler(mockService, zap.NewNop())
+		role := "developer"
+		user := &models.User{ID: userID, OrgID: orgID, Role: models.UserRole(role)}
+		mockService.On("UpdateUser", mock.Anything, mock.Anything, tenant.UpdateUserRequest{ID: userID, Role: &role}).
+			Return(user, nil)
+
+		body, _ := json.Marshal(UpdateUserRequest{Role: &role})
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/users/"+userID.String(), bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		handler.HandleUpdateUser(w, withURLParam(withCaller(req, orgID, "admin"), "id", userID.String()))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"developer"`)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		handler := NewTenantHandler(new(MockTenantService), zap.NewNop())
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/users/nope", bytes.NewReader([]byte(`{}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleUpdateUser(w, withURLParam(withCaller(req, orgID, "admin"), "id", "nope"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("role above the caller's is forbidden", func(t *testing.T) {
+		mockService := new(MockTenantService)
+		handler := NewTenantHandler(mockService, zap.NewNop())
+		mockService.On("UpdateUser", mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, services.NewDomainError(services.ErrorTypeForbidden, "cannot assign a role with a permission you do not hold", nil))
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/users/"+userID.String(), bytes.NewReader([]byte(`{"role":"admin"}`)))
+		w := httptest.NewRecorder()
+
+		handler.HandleUpdateUser(w, withURLParam(withCaller(req, orgID, "developer"), "id", userID.String()))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
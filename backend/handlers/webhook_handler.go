@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/services/webhook"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// ConfigureWebhookRequest represents a request to set an application's webhook
+type ConfigureWebhookRequest struct {
+	URL string `json:"url" validate:"required,url"`
+}
+
+// WebhookService defines the interface for application webhook operations
+type WebhookService interface {
+	// Configure sets the webhook URL and issues a new signing secret
+	Configure(ctx context.Context, orgID, appID uuid.UUID, url string) (*webhook.Registration, error)
+
+	// Remove stops deliveries to the application
+	Remove(ctx context.Context, orgID, appID uuid.UUID) error
+}
+
+// WebhookHandler handles application webhook HTTP requests
+type WebhookHandler struct {
+	service WebhookService
+	logger  *zap.Logger
+}
+
+// NewWebhookHandler creates a new WebhookHandler
+func NewWebhookHandler(service WebhookService, logger *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// HandleConfigureWebhook handles PUT /v1/applications/{id}/webhook
+// The signing secret is only returned by this call; calling it again rotates the secret.
+func (h *WebhookHandler) HandleConfigureWebhook(w http.ResponseWriter, r *http.Request) {
+	orgID, appID, ok := h.parseAppID(w, r)
+	if !ok {
+		return
+	}
+
+	var req ConfigureWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid request body", nil)
+		return
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		HandleValidationError(w, err, h.logger)
+		return
+	}
+
+	registration, err := h.service.Configure(r.Context(), orgID, appID, req.URL)
+	if err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	_ = utils.WriteOK(w, registration)
+}
+
+// HandleRemoveWebhook handles DELETE /v1/applications/{id}/webhook
+func (h *WebhookHandler) HandleRemoveWebhook(w http.ResponseWriter, r *http.Request) {
+	orgID, appID, ok := h.parseAppID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Remove(r.Context(), orgID, appID); err != nil {
+		HandleServiceError(w, err, h.logger)
+		return
+	}
+
+	utils.WriteNoContent(w)
+}
+
+// parseAppID extracts the organization and the application ID
+func (h *WebhookHandler) parseAppID(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	orgID := middleware.GetOrgIDFromContext(r.Context())
+	if orgID == uuid.Nil {
+		_ = utils.WriteUnauthorized(w, "Missing tenant information")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	appID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		_ = utils.WriteBadRequest(w, "Invalid application ID format", nil)
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return orgID, appID, true
+}
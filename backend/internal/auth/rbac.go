@@ -1,17 +1,22 @@
 package auth
 
-// TODO: RBAC model and checks. Role/permission mapping should be data-driven.
+import "github.com/upb/llm-control-plane/backend/models"
+
+// Role names a set of permissions. The role-to-permission mappings are data:
+// built-in defaults in models.BuiltInRoles, overridden and extended per
+// organization in the roles table (see services/rbac).
 
 type Role string
 
 const (
-	RoleAdmin  Role = "admin"
-	RoleUser   Role = "user"
-	RoleViewer Role = "viewer"
+	RoleAdmin  Role = Role(models.RoleAdmin)
+	RoleMember Role = Role(models.RoleMember)
+	RoleViewer Role = Role(models.RoleViewer)
 )
 
+// HasPermission reports whether any of the principal's roles grants
+// permission by the built-in mappings. Requests are authorized per
+// organization by middleware.AuthMiddleware.RequirePermission.
 func HasPermission(principal Principal, permission string) bool {
-	// TODO: implement permission lookup
-	return true
+	return models.BuiltInRolesGrant(principal.Roles, models.Permission(permission))
 }
-
@@ -0,0 +1,46 @@
+package rag
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Citation links a numbered context block to the document it came from.
+type Citation struct {
+	Index      int     `json:"index"`
+	DocumentID string  `json:"document_id"`
+	Score      float64 `json:"score"`
+}
+
+// FormatContext renders retrieved documents as numbered context blocks that
+// the model is asked to cite, and returns the matching citations.
+func FormatContext(docs []Document) (string, []Citation) {
+	if len(docs) == 0 {
+		return "", nil
+	}
+
+	var b strings.Builder
+	b.WriteString("Answer using the context below when it is relevant. ")
+	b.WriteString("Cite the sources you use by their number, for example [1].\n")
+
+	citations := make([]Citation, len(docs))
+	for i, doc := range docs {
+		citations[i] = Citation{Index: i + 1, DocumentID: doc.ID, Score: doc.Score}
+		fmt.Fprintf(&b, "\n[%d] (source: %s)\n%s\n", i+1, doc.ID, strings.TrimSpace(doc.Content))
+	}
+
+	return b.String(), citations
+}
+
+// DocumentIDs returns the distinct document IDs of docs in retrieval order.
+func DocumentIDs(docs []Document) []string {
+	var ids []string
+	seen := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		if !seen[doc.ID] {
+			seen[doc.ID] = true
+			ids = append(ids, doc.ID)
+		}
+	}
+	return ids
+}
@@ -6,6 +6,7 @@
 //   - Semantic search over organizational knowledge bases
 //   - Context injection into prompts
 //
-// RAG capabilities are optional and can be enabled per-organization.
-// This is a Phase 2+ feature and currently contains placeholders.
+// RAG capabilities are optional and can be enabled per-organization through
+// a RAG policy. PGVectorRetriever serves retrieval from Postgres with the
+// pgvector extension, and FormatContext renders results for injection.
 package rag
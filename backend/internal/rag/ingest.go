@@ -0,0 +1,109 @@
+package rag
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Supported content types for ingestion.
+const (
+	ContentTypeText     = "text"
+	ContentTypeMarkdown = "markdown"
+	ContentTypeHTML     = "html"
+)
+
+// Default chunking parameters, in characters.
+const (
+	DefaultChunkSize    = 1000
+	DefaultChunkOverlap = 200
+)
+
+var (
+	htmlHiddenPattern = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>|<!--.*?-->`)
+	htmlBreakPattern  = regexp.MustCompile(`(?i)<(br|/p|/div|/li|/h[1-6]|/tr|/section|/article|/blockquote|/pre)\b[^>]*>`)
+	htmlTagPattern    = regexp.MustCompile(`<[^>]*>`)
+	markdownImage     = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink      = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	blankLines        = regexp.MustCompile(`\n{3,}`)
+)
+
+// ExtractText converts content of the given type to plain text for chunking.
+// Markdown keeps its structure but drops link and image targets; HTML is
+// reduced to its visible text with block elements as line breaks.
+func ExtractText(content, contentType string) (string, error) {
+	var text string
+	switch contentType {
+	case "", ContentTypeText:
+		text = content
+	case ContentTypeMarkdown:
+		text = markdownImage.ReplaceAllString(content, "$1")
+		text = markdownLink.ReplaceAllString(text, "$1")
+	case ContentTypeHTML:
+		text = htmlHiddenPattern.ReplaceAllString(content, "")
+		text = htmlBreakPattern.ReplaceAllString(text, "\n")
+		text = htmlTagPattern.ReplaceAllString(text, " ")
+		text = html.UnescapeString(text)
+	default:
+		return "", fmt.Errorf("unsupported content type %q", contentType)
+	}
+
+	return normalizeWhitespace(text), nil
+}
+
+// normalizeWhitespace trims each line and collapses runs of blank lines
+func normalizeWhitespace(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// Chunk splits text into chunks of at most size characters, each starting
+// with roughly the last overlap characters of the previous one. Chunks end
+// and start at whitespace where possible so words are not cut.
+func Chunk(text string, size, overlap int) ([]string, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("chunk size must be positive")
+	}
+	if overlap < 0 || overlap >= size {
+		return nil, fmt.Errorf("chunk overlap must be between 0 and the chunk size")
+	}
+
+	runes := []rune(text)
+	var chunks []string
+	for start := 0; start < len(runes); {
+		end := min(start+size, len(runes))
+		if end < len(runes) {
+			// Prefer to break at whitespace in the second half of the window
+			for i := end; i > start+size/2; i-- {
+				if unicode.IsSpace(runes[i]) {
+					end = i
+					break
+				}
+			}
+		}
+
+		if chunk := strings.TrimSpace(string(runes[start:end])); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		if end == len(runes) {
+			break
+		}
+
+		next := end - overlap
+		if next <= start {
+			next = end
+		}
+		// Move forward to the start of a word
+		for next < end && !unicode.IsSpace(runes[next-1]) {
+			next++
+		}
+		start = next
+	}
+
+	return chunks, nil
+}
@@ -0,0 +1,70 @@
+package rag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractText(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		contentType string
+		want        string
+	}{
+		{"text", "  hello   world \n\n\n\nbye ", ContentTypeText, "hello world\n\nbye"},
+		{"markdown", "See [the docs](https://x.test) and ![logo](logo.png).", ContentTypeMarkdown, "See the docs and logo."},
+		{
+			"html",
+			`<html><head><title>T</title></head><body><script>x()</script><p>Fish &amp; chips</p><!-- c --><div>Second</div></body></html>`,
+			ContentTypeHTML,
+			"Fish & chips\nSecond",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractText(tt.content, tt.contentType)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := ExtractText("x", "pdf")
+	assert.Error(t, err)
+}
+
+func TestChunk(t *testing.T) {
+	words := make([]string, 100)
+	for i := range words {
+		words[i] = "word"
+	}
+	text := strings.Join(words, " ")
+
+	chunks, err := Chunk(text, 50, 10)
+	require.NoError(t, err)
+	require.Greater(t, len(chunks), 1)
+	for i, chunk := range chunks {
+		assert.LessOrEqual(t, len(chunk), 50)
+		assert.False(t, strings.HasPrefix(chunk, "ord"), "chunk %d starts mid-word", i)
+	}
+	assert.True(t, strings.HasSuffix(text, chunks[len(chunks)-1]))
+
+	t.Run("short text is one chunk", func(t *testing.T) {
+		chunks, err := Chunk("short text", 50, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"short text"}, chunks)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		_, err := Chunk(text, 0, 0)
+		assert.Error(t, err)
+		_, err = Chunk(text, 50, 50)
+		assert.Error(t, err)
+		_, err = Chunk(text, 50, -1)
+		assert.Error(t, err)
+	})
+}
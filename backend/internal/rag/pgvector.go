@@ -0,0 +1,107 @@
+package rag
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Filter keys understood by PGVectorRetriever.
+const (
+	FilterOrgID = "org_id" // Required: tenant that owns the index
+	FilterIndex = "index"  // Required: index name within the tenant
+)
+
+// DefaultTopK is used when RetrievalOptions.TopK is not set.
+const DefaultTopK = 4
+
+// PGVectorRetriever retrieves chunks from the rag_chunks table by cosine
+// similarity using the pgvector extension.
+type PGVectorRetriever struct {
+	db       *sql.DB
+	embedder Embedder
+}
+
+// NewPGVectorRetriever creates a retriever that embeds queries with embedder.
+func NewPGVectorRetriever(db *sql.DB, embedder Embedder) *PGVectorRetriever {
+	return &PGVectorRetriever{
+		db:       db,
+		embedder: embedder,
+	}
+}
+
+// Retrieve returns up to opts.TopK chunks whose similarity to query is at
+// least opts.Threshold, most similar first. The org_id and index filters are
+// required so that retrieval never crosses tenants.
+func (r *PGVectorRetriever) Retrieve(ctx context.Context, query string, opts RetrievalOptions) ([]Document, error) {
+	orgID, index := opts.Filters[FilterOrgID], opts.Filters[FilterIndex]
+	if orgID == "" || index == "" {
+		return nil, fmt.Errorf("retrieval requires %q and %q filters", FilterOrgID, FilterIndex)
+	}
+
+	topK := opts.TopK
+	if topK <= 0 {
+		topK = DefaultTopK
+	}
+
+	embedding, err := r.embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT document_id, chunk_index, content, metadata, 1 - (embedding <=> $1::vector) AS score
+		FROM rag_chunks
+		WHERE org_id = $2 AND index_name = $3
+		  AND 1 - (embedding <=> $1::vector) >= $4
+		ORDER BY embedding <=> $1::vector
+		LIMIT $5
+	`, VectorLiteral(embedding), orgID, index, opts.Threshold, topK)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rag chunks: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []Document
+	for rows.Next() {
+		var (
+			doc        Document
+			chunkIndex int
+			metadata   []byte
+		)
+		if err := rows.Scan(&doc.ID, &chunkIndex, &doc.Content, &metadata, &doc.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan rag chunk: %w", err)
+		}
+		doc.Metadata = map[string]interface{}{}
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &doc.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal rag chunk metadata: %w", err)
+			}
+		}
+		doc.Metadata["chunk_index"] = chunkIndex
+		docs = append(docs, doc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rag chunk rows: %w", err)
+	}
+
+	return docs, nil
+}
+
+// VectorLiteral formats an embedding in pgvector's text representation.
+func VectorLiteral(v []float64) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(x, 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticEmbedder struct {
+	vector []float64
+	err    error
+}
+
+func (e staticEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	return e.vector, e.err
+}
+
+func TestPGVectorRetriever_Retrieve(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"document_id", "chunk_index", "content", "metadata", "score"}).
+		AddRow("handbook", 2, "Tap Settings.", []byte(`{"title":"Handbook"}`), 0.91).
+		AddRow("faq", 0, "Passwords expire.", []byte(`{}`), 0.82)
+	mock.ExpectQuery("FROM rag_chunks").
+		WithArgs("[0.5,-0.25]", "org-1", "support", 0.7, 2).
+		WillReturnRows(rows)
+
+	retriever := NewPGVectorRetriever(db, staticEmbedder{vector: []float64{0.5, -0.25}})
+	docs, err := retriever.Retrieve(context.Background(), "reset password", RetrievalOptions{
+		TopK:      2,
+		Threshold: 0.7,
+		Filters:   map[string]string{FilterOrgID: "org-1", FilterIndex: "support"},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	assert.Equal(t, "handbook", docs[0].ID)
+	assert.Equal(t, 0.91, docs[0].Score)
+	assert.Equal(t, "Handbook", docs[0].Metadata["title"])
+	assert.Equal(t, 2, docs[0].Metadata["chunk_index"])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPGVectorRetriever_RequiresTenantFilters(t *testing.T) {
+	retriever := NewPGVectorRetriever(nil, staticEmbedder{vector: []float64{1}})
+
+	_, err := retriever.Retrieve(context.Background(), "q", RetrievalOptions{
+		Filters: map[string]string{FilterIndex: "support"},
+	})
+	assert.Error(t, err)
+}
+
+func TestPGVectorRetriever_EmbedError(t *testing.T) {
+	retriever := NewPGVectorRetriever(nil, staticEmbedder{err: errors.New("unavailable")})
+
+	_, err := retriever.Retrieve(context.Background(), "q", RetrievalOptions{
+		Filters: map[string]string{FilterOrgID: "org-1", FilterIndex: "support"},
+	})
+	assert.ErrorContains(t, err, "failed to embed query")
+}
+
+func TestFormatContext(t *testing.T) {
+	content, citations := FormatContext([]Document{
+		{ID: "handbook", Content: "  Tap Settings.\n", Score: 0.9},
+		{ID: "faq", Content: "Passwords expire.", Score: 0.8},
+	})
+
+	assert.Contains(t, content, "[1] (source: handbook)\nTap Settings.\n")
+	assert.Contains(t, content, "[2] (source: faq)\nPasswords expire.\n")
+	assert.Equal(t, []Citation{
+		{Index: 1, DocumentID: "handbook", Score: 0.9},
+		{Index: 2, DocumentID: "faq", Score: 0.8},
+	}, citations)
+
+	content, citations = FormatContext(nil)
+	assert.Empty(t, content)
+	assert.Nil(t, citations)
+}
+
+func TestDocumentIDs(t *testing.T) {
+	ids := DocumentIDs([]Document{{ID: "a"}, {ID: "b"}, {ID: "a"}})
+	assert.Equal(t, []string{"a", "b"}, ids)
+}
+
+func TestVectorLiteral(t *testing.T) {
+	assert.Equal(t, "[1,0.5,-2]", VectorLiteral([]float64{1, 0.5, -2}))
+	assert.Equal(t, "[]", VectorLiteral(nil))
+}
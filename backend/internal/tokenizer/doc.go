@@ -0,0 +1,22 @@
+// Package tokenizer provides byte-level BPE token counting for the LLM Control Plane.
+//
+// This package provides:
+//   - tiktoken-compatible rank files embedded in the binary, approximating
+//     the upstream vocabularies
+//   - cl100k_base and o200k_base style encodings selected per model
+//   - Chat message counting with per-message overhead
+//
+// Counts are used for budget pre-checks and token-based rate limits, so they
+// only need to be close to what the provider bills, not byte-identical.
+//
+// The rank files in vocab/ use tiktoken's format. The bundled
+// cl100k_approx.tiktoken and o200k_approx.tiktoken are not OpenAI's files but
+// approximations: compact 16k and 32k vocabularies trained by cmd/vocabgen
+// on a mix of prose, code and CJK text, so token IDs do not match the
+// upstream encodings and counts run high. Measured against upstream tiktoken,
+// they never undercount; English text comes out close, other Latin-script
+// text up to about 2.5x and Chinese, Japanese and Korean text up to 2.6x with
+// cl100k and 4.5x with o200k, whose upstream vocabulary covers CJK far better.
+// Adding the upstream cl100k_base.tiktoken and o200k_base.tiktoken files to
+// vocab/ gives exact counts without code changes.
+package tokenizer
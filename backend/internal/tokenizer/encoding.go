@@ -0,0 +1,225 @@
+package tokenizer
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// Encoding names, matching the names used by tiktoken
+const (
+	CL100kBase = "cl100k_base"
+	O200kBase  = "o200k_base"
+)
+
+// DefaultEncoding is used when a model does not declare its tokenizer
+const DefaultEncoding = CL100kBase
+
+// maxPieceBytes bounds the pre-tokens BPE merges run on. Merging is
+// quadratic in the piece length and letter runs are unbounded, so longer
+// pieces are encoded in chunks; real text has no pre-tokens this long.
+const maxPieceBytes = 256
+
+//go:embed vocab/*.tiktoken
+var vocabFS embed.FS
+
+// approximateVocabs are the bundled stand-ins for the upstream rank files:
+// smaller vocabularies trained here, not OpenAI's. An upstream rank file
+// dropped into vocab/ under the encoding's name takes precedence.
+var approximateVocabs = map[string]string{
+	CL100kBase: "cl100k_approx.tiktoken",
+	O200kBase:  "o200k_approx.tiktoken",
+}
+
+// Encoding is a byte-level BPE encoding loaded from a tiktoken rank file
+type Encoding struct {
+	name        string
+	ranks       map[string]int
+	approximate bool
+}
+
+var (
+	encodingsMu sync.Mutex
+	encodings   = make(map[string]*Encoding)
+)
+
+// GetEncoding returns the named encoding, loading its rank file on first use
+func GetEncoding(name string) (*Encoding, error) {
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+
+	if enc, ok := encodings[name]; ok {
+		return enc, nil
+	}
+
+	approximate := false
+	data, err := vocabFS.ReadFile("vocab/" + name + ".tiktoken")
+	if err != nil {
+		file, ok := approximateVocabs[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown encoding %q", name)
+		}
+		if data, err = vocabFS.ReadFile("vocab/" + file); err != nil {
+			return nil, fmt.Errorf("failed to load encoding %q: %w", name, err)
+		}
+		approximate = true
+	}
+
+	ranks, err := parseRanks(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load encoding %q: %w", name, err)
+	}
+
+	enc := &Encoding{name: name, ranks: ranks, approximate: approximate}
+	encodings[name] = enc
+	return enc, nil
+}
+
+// Name returns the encoding name
+func (e *Encoding) Name() string {
+	return e.name
+}
+
+// Approximate reports whether the encoding uses a bundled stand-in
+// vocabulary, whose token IDs and counts differ from the upstream encoding's
+func (e *Encoding) Approximate() bool {
+	return e.approximate
+}
+
+// Encode converts text into token ranks
+func (e *Encoding) Encode(text string) []int {
+	var tokens []int
+	for _, piece := range splitPieces(text) {
+		tokens = append(tokens, e.encodePiece([]byte(piece))...)
+	}
+	return tokens
+}
+
+// Count returns the number of tokens in text
+func (e *Encoding) Count(text string) int {
+	count := 0
+	for _, piece := range splitPieces(text) {
+		if _, ok := e.ranks[piece]; ok {
+			count++
+			continue
+		}
+		count += len(e.encodePiece([]byte(piece)))
+	}
+	return count
+}
+
+// encodePiece applies BPE merges to a single pre-token. Adjacent parts are
+// merged lowest-rank first until no adjacent pair is in the vocabulary.
+func (e *Encoding) encodePiece(piece []byte) []int {
+	if rank, ok := e.ranks[string(piece)]; ok {
+		return []int{rank}
+	}
+	if len(piece) > maxPieceBytes {
+		return e.encodeChunks(piece)
+	}
+
+	// parts[i] is the start of part i and the rank of merging it with the
+	// next part; the last entry marks len(piece). Only the neighbours of a
+	// merge need their ranks looked up again.
+	type part struct {
+		start int
+		rank  int
+	}
+	const noRank = math.MaxInt
+	parts := make([]part, len(piece)+1)
+	for i := range parts {
+		parts[i] = part{start: i, rank: noRank}
+	}
+	pairRank := func(i int) int {
+		if i+2 < len(parts) {
+			if rank, ok := e.ranks[string(piece[parts[i].start:parts[i+2].start])]; ok {
+				return rank
+			}
+		}
+		return noRank
+	}
+	for i := 0; i < len(parts)-2; i++ {
+		parts[i].rank = pairRank(i)
+	}
+
+	for len(parts) > 2 {
+		best := -1
+		for i := 0; i < len(parts)-2; i++ {
+			if parts[i].rank != noRank && (best < 0 || parts[i].rank < parts[best].rank) {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts = append(parts[:best+1], parts[best+2:]...)
+		parts[best].rank = pairRank(best)
+		if best > 0 {
+			parts[best-1].rank = pairRank(best - 1)
+		}
+	}
+
+	tokens := make([]int, 0, len(parts)-1)
+	for i := 0; i < len(parts)-1; i++ {
+		rank, ok := e.ranks[string(piece[parts[i].start:parts[i+1].start])]
+		if !ok {
+			// Rank files cover every single byte; this only guards against a bad file
+			rank = -1
+		}
+		tokens = append(tokens, rank)
+	}
+	return tokens
+}
+
+// encodeChunks encodes an overlong piece in chunks of at most maxPieceBytes,
+// split on rune boundaries
+func (e *Encoding) encodeChunks(piece []byte) []int {
+	var tokens []int
+	for len(piece) > 0 {
+		n := len(piece)
+		if n > maxPieceBytes {
+			n = maxPieceBytes
+			for n > maxPieceBytes-utf8.UTFMax && !utf8.RuneStart(piece[n]) {
+				n--
+			}
+		}
+		tokens = append(tokens, e.encodePiece(piece[:n])...)
+		piece = piece[n:]
+	}
+	return tokens
+}
+
+// parseRanks parses the tiktoken rank file format: one "<base64 token> <rank>" per line
+func parseRanks(data []byte) (map[string]int, error) {
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected token and rank", line)
+		}
+
+		token, err := base64.StdEncoding.DecodeString(string(fields[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid token: %w", line, err)
+		}
+		rank, err := strconv.Atoi(string(fields[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rank: %w", line, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ranks, nil
+}
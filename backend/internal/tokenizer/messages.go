@@ -0,0 +1,63 @@
+package tokenizer
+
+import "strings"
+
+// Chat formatting overhead, as documented for OpenAI chat models: every message
+// is wrapped in role/separator tokens and every reply is primed with an
+// assistant header.
+const (
+	TokensPerMessage = 3
+	TokensPerName    = 1
+	TokensPerReply   = 3
+)
+
+// Message is the subset of a chat message that contributes to its token count
+type Message struct {
+	Role    string
+	Name    string
+	Content string
+}
+
+// CountMessages returns the prompt tokens for a chat request, including
+// per-message formatting overhead
+func (e *Encoding) CountMessages(messages []Message) int {
+	if len(messages) == 0 {
+		return 0
+	}
+
+	total := TokensPerReply
+	for _, msg := range messages {
+		total += TokensPerMessage
+		total += e.Count(msg.Role)
+		total += e.Count(msg.Content)
+		if msg.Name != "" {
+			total += e.Count(msg.Name) + TokensPerName
+		}
+	}
+	return total
+}
+
+// EncodingNameForModel infers the encoding for a model that does not declare one
+func EncodingNameForModel(model string) string {
+	for _, prefix := range []string{"gpt-4o", "gpt-4.1", "o1", "o3", "o4"} {
+		if strings.HasPrefix(model, prefix) {
+			return O200kBase
+		}
+	}
+	return DefaultEncoding
+}
+
+// ForModel returns the encoding to use for a model. name is the tokenizer
+// declared by the provider's model info and may be empty.
+func ForModel(model, name string) *Encoding {
+	if name == "" {
+		name = EncodingNameForModel(model)
+	}
+	if enc, err := GetEncoding(name); err == nil {
+		return enc
+	}
+
+	// The default encoding is embedded, so this cannot fail
+	enc, _ := GetEncoding(DefaultEncoding)
+	return enc
+}
@@ -0,0 +1,127 @@
+package tokenizer
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// splitPieces splits text into pre-tokens the same way the cl100k pattern does:
+//
+//	(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}|
+//	 ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+
+//
+// Go's regexp package has no lookahead, so the pattern is implemented by hand.
+// BPE merges never cross piece boundaries.
+func splitPieces(text string) []string {
+	var pieces []string
+	for i := 0; i < len(text); {
+		n := matchPiece(text[i:])
+		pieces = append(pieces, text[i:i+n])
+		i += n
+	}
+	return pieces
+}
+
+// matchPiece returns the byte length of the pre-token at the start of s
+func matchPiece(s string) int {
+	r, size := utf8.DecodeRuneInString(s)
+
+	// Contractions
+	if r == '\'' {
+		if n := matchContraction(s[size:]); n > 0 {
+			return size + n
+		}
+	}
+
+	// Optional leading non-letter followed by letters
+	if isLetter(r) {
+		return size + scanWhile(s[size:], isLetter, -1)
+	}
+	if r != '\r' && r != '\n' && !isNumber(r) {
+		if next, _ := utf8.DecodeRuneInString(s[size:]); size < len(s) && isLetter(next) {
+			return size + scanWhile(s[size:], isLetter, -1)
+		}
+	}
+
+	// Up to three digits
+	if isNumber(r) {
+		return size + scanWhile(s[size:], isNumber, 2)
+	}
+
+	// Optional space followed by punctuation and trailing newlines
+	if isPunct(r) {
+		n := size + scanWhile(s[size:], isPunct, -1)
+		return n + scanWhile(s[n:], isNewline, -1)
+	}
+	if r == ' ' {
+		if next, _ := utf8.DecodeRuneInString(s[size:]); size < len(s) && isPunct(next) {
+			n := size + scanWhile(s[size:], isPunct, -1)
+			return n + scanWhile(s[n:], isNewline, -1)
+		}
+	}
+
+	// Whitespace runs
+	if unicode.IsSpace(r) {
+		run := size + scanWhile(s[size:], unicode.IsSpace, -1)
+
+		// \s*[\r\n]+ ends at the last newline in the run
+		for j := run - 1; j >= 0; j-- {
+			if s[j] == '\r' || s[j] == '\n' {
+				return j + 1
+			}
+		}
+
+		// \s+(?!\S) leaves the last space to prefix the next word
+		if run < len(s) {
+			_, last := utf8.DecodeLastRuneInString(s[:run])
+			if run-last > 0 {
+				return run - last
+			}
+		}
+		return run
+	}
+
+	return size
+}
+
+// matchContraction matches the suffix of an English contraction after the apostrophe
+func matchContraction(s string) int {
+	for _, suffix := range []string{"re", "ve", "ll", "s", "t", "m", "d"} {
+		if len(s) >= len(suffix) && equalFoldASCII(s[:len(suffix)], suffix) {
+			return len(suffix)
+		}
+	}
+	return 0
+}
+
+// scanWhile returns the byte length of the leading runes of s matching fn,
+// stopping after max runes if max is not negative
+func scanWhile(s string, fn func(rune) bool, max int) int {
+	n, count := 0, 0
+	for n < len(s) && (max < 0 || count < max) {
+		r, size := utf8.DecodeRuneInString(s[n:])
+		if !fn(r) {
+			break
+		}
+		n += size
+		count++
+	}
+	return n
+}
+
+func equalFoldASCII(a, b string) bool {
+	for i := 0; i < len(a); i++ {
+		if a[i]|0x20 != b[i]|0x20 {
+			return false
+		}
+	}
+	return true
+}
+
+func isLetter(r rune) bool { return unicode.IsLetter(r) }
+
+func isNumber(r rune) bool { return unicode.IsNumber(r) }
+
+func isNewline(r rune) bool { return r == '\r' || r == '\n' }
+
+func isPunct(r rune) bool { return !unicode.IsSpace(r) && !isLetter(r) && !isNumber(r) }
@@ -0,0 +1,286 @@
+package tokenizer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitPieces(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "words keep leading space",
+			input:    "Hello world",
+			expected: []string{"Hello", " world"},
+		},
+		{
+			name:     "contractions",
+			input:    "I'm sure they'LL go",
+			expected: []string{"I", "'m", " sure", " they", "'LL", " go"},
+		},
+		{
+			name:     "digits split in threes",
+			input:    "12345",
+			expected: []string{"123", "45"},
+		},
+		{
+			name:     "punctuation absorbs trailing newline",
+			input:    "foo();\nbar",
+			expected: []string{"foo", "();\n", "bar"},
+		},
+		{
+			name:     "whitespace run leaves last space for next word",
+			input:    "a   b",
+			expected: []string{"a", "  ", " b"},
+		},
+		{
+			name:     "newlines grouped",
+			input:    "a\n\n  b",
+			expected: []string{"a", "\n\n", " ", " b"},
+		},
+		{
+			name:     "trailing whitespace",
+			input:    "end  ",
+			expected: []string{"end", "  "},
+		},
+		{
+			name:     "CJK runs",
+			input:    "你好，世界",
+			expected: []string{"你好", "，世界"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitPieces(tt.input)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("splitPieces(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+			if strings.Join(got, "") != tt.input {
+				t.Errorf("pieces do not reassemble input")
+			}
+		})
+	}
+}
+
+func TestGetEncoding(t *testing.T) {
+	for _, name := range []string{CL100kBase, O200kBase} {
+		enc, err := GetEncoding(name)
+		if err != nil {
+			t.Fatalf("GetEncoding(%q) error = %v", name, err)
+		}
+		if enc.Name() != name {
+			t.Errorf("Name() = %q, want %q", enc.Name(), name)
+		}
+		if !enc.Approximate() {
+			t.Errorf("%s: expected the bundled approximation", name)
+		}
+
+		// Every single byte must be encodable
+		for b := 0; b < 256; b++ {
+			if _, ok := enc.ranks[string([]byte{byte(b)})]; !ok {
+				t.Fatalf("%s: byte %#x has no rank", name, b)
+			}
+		}
+	}
+
+	if _, err := GetEncoding("p50k_unknown"); err == nil {
+		t.Error("expected error for unknown encoding")
+	}
+}
+
+func TestEncodeAndCount(t *testing.T) {
+	enc, err := GetEncoding(CL100kBase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inputs := []string{
+		"",
+		"The quick brown fox jumps over the lazy dog.",
+		"func main() {\n\tfmt.Println(\"hello\")\n}\n",
+		"你好，世界。今天天气很好。",
+		"emoji 🚀 and accents: café naïve",
+		string([]byte{0xff, 0xfe, 'a'}),
+	}
+
+	for _, input := range inputs {
+		tokens := enc.Encode(input)
+		if enc.Count(input) != len(tokens) {
+			t.Errorf("Count(%q) = %d, Encode gave %d tokens", input, enc.Count(input), len(tokens))
+		}
+		for _, tok := range tokens {
+			if tok < 0 {
+				t.Errorf("Encode(%q) produced an unknown token", input)
+			}
+		}
+		if len(input) > 0 && len(tokens) == 0 {
+			t.Errorf("Encode(%q) produced no tokens", input)
+		}
+		if len(tokens) > len(input) {
+			t.Errorf("Encode(%q) produced more tokens than bytes", input)
+		}
+	}
+
+	// Common English words are single tokens
+	if n := enc.Count(" the"); n != 1 {
+		t.Errorf("Count(\" the\") = %d, want 1", n)
+	}
+}
+
+func TestEncode_LongPieces(t *testing.T) {
+	enc, err := GetEncoding(CL100kBase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A single unbounded letter run must not make merging quadratic in its length
+	for _, input := range []string{strings.Repeat("a", 1<<20), strings.Repeat("é", 1<<19)} {
+		start := time.Now()
+		tokens := enc.Encode(input)
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Encode of %d bytes took %s", len(input), elapsed)
+		}
+		if enc.Count(input) != len(tokens) {
+			t.Errorf("Count = %d, Encode gave %d tokens", enc.Count(input), len(tokens))
+		}
+		for _, tok := range tokens {
+			if tok < 0 {
+				t.Fatal("Encode split a rune across chunks")
+			}
+		}
+	}
+}
+
+func TestCountMessages(t *testing.T) {
+	enc, err := GetEncoding(CL100kBase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := enc.CountMessages(nil); n != 0 {
+		t.Errorf("CountMessages(nil) = %d, want 0", n)
+	}
+
+	msgs := []Message{
+		{Role: "system", Content: "You are helpful."},
+		{Role: "user", Name: "bob", Content: "Hi"},
+	}
+	expected := TokensPerReply +
+		TokensPerMessage + enc.Count("system") + enc.Count("You are helpful.") +
+		TokensPerMessage + enc.Count("user") + enc.Count("Hi") + enc.Count("bob") + TokensPerName
+
+	if n := enc.CountMessages(msgs); n != expected {
+		t.Errorf("CountMessages() = %d, want %d", n, expected)
+	}
+}
+
+func TestForModel(t *testing.T) {
+	tests := []struct {
+		model     string
+		tokenizer string
+		expected  string
+	}{
+		{model: "gpt-4", expected: CL100kBase},
+		{model: "gpt-3.5-turbo", expected: CL100kBase},
+		{model: "gpt-4o-mini", expected: O200kBase},
+		{model: "claude-3-haiku", expected: DefaultEncoding},
+		{model: "gpt-4", tokenizer: O200kBase, expected: O200kBase},
+		{model: "gpt-4o", tokenizer: "missing", expected: DefaultEncoding},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model+"/"+tt.tokenizer, func(t *testing.T) {
+			if got := ForModel(tt.model, tt.tokenizer).Name(); got != tt.expected {
+				t.Errorf("ForModel(%q, %q) = %q, want %q", tt.model, tt.tokenizer, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseRanks_Invalid(t *testing.T) {
+	if _, err := parseRanks([]byte("not-base64! 1\n")); err == nil {
+		t.Error("expected error for invalid base64")
+	}
+	if _, err := parseRanks([]byte("YQ== x\n")); err == nil {
+		t.Error("expected error for invalid rank")
+	}
+	if _, err := parseRanks([]byte("YQ==\n")); err == nil {
+		t.Error("expected error for missing rank")
+	}
+}
+
+func TestApproximateCounts(t *testing.T) {
+	// Reference counts from upstream tiktoken
+	tests := []struct {
+		text   string
+		cl100k int
+		o200k  int
+	}{
+		{text: "hallo world!", cl100k: 4, o200k: 4},
+		{text: "Hallo Welt!", cl100k: 3, o200k: 3},
+		{text: "Bonjour le monde!", cl100k: 4, o200k: 4},
+		{text: "¡Hola mundo!", cl100k: 4, o200k: 4},
+		{text: "Hej världen!", cl100k: 7, o200k: 3},
+		{text: "Привет мир!", cl100k: 6, o200k: 4},
+		{text: "你好世界！", cl100k: 6, o200k: 3},
+		{text: "こんにちは世界！", cl100k: 5, o200k: 3},
+		{text: "안녕하세요 세계!", cl100k: 10, o200k: 4},
+	}
+
+	// The bundled approximations never undercount and stay within these factors
+	bounds := map[string]float64{CL100kBase: 3, O200kBase: 5}
+
+	for _, name := range []string{CL100kBase, O200kBase} {
+		enc, err := GetEncoding(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range tests {
+			want := tt.cl100k
+			if name == O200kBase {
+				want = tt.o200k
+			}
+			got := enc.Count(tt.text)
+			if !enc.Approximate() {
+				if got != want {
+					t.Errorf("%s: Count(%q) = %d, want %d", name, tt.text, got, want)
+				}
+				continue
+			}
+			if got < want || float64(got) > float64(want)*bounds[name] {
+				t.Errorf("%s: Count(%q) = %d, want between %d and %.0fx", name, tt.text, got, want, bounds[name])
+			}
+		}
+	}
+}
+
+func TestTrain(t *testing.T) {
+	text := strings.Repeat("the cat sat on the mat. ", 50)
+	vocab := Train(text, 300)
+	if len(vocab) <= 256 || len(vocab) > 300 {
+		t.Fatalf("Train() gave %d tokens, want between 257 and 300", len(vocab))
+	}
+	if !reflect.DeepEqual(vocab, Train(text, 300)) {
+		t.Error("Train() is not deterministic")
+	}
+
+	var buf strings.Builder
+	if err := WriteRanks(&buf, vocab); err != nil {
+		t.Fatal(err)
+	}
+	ranks, err := parseRanks([]byte(buf.String()))
+	if err != nil {
+		t.Fatalf("parseRanks() error = %v", err)
+	}
+	for rank, token := range vocab {
+		if ranks[string(token)] != rank {
+			t.Errorf("token %q has rank %d, want %d", token, ranks[string(token)], rank)
+		}
+	}
+}
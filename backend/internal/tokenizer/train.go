@@ -0,0 +1,162 @@
+package tokenizer
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// Train learns a byte-level BPE vocabulary of up to vocabSize tokens from
+// text, pre-tokenized like Encode. The first 256 tokens are the single bytes;
+// each further token merges the most frequent adjacent pair, ties broken by
+// the lower pair of ranks, so training is deterministic. Training stops early
+// once no pair occurs twice.
+func Train(text string, vocabSize int) [][]byte {
+	freq := make(map[string]int)
+	for _, piece := range splitPieces(text) {
+		freq[piece]++
+	}
+
+	// Merges depend only on pair counts, not on the order words are visited
+	words := make([]trainWord, 0, len(freq))
+	for piece, f := range freq {
+		symbols := make([]int, len(piece))
+		for i := 0; i < len(piece); i++ {
+			symbols[i] = int(piece[i])
+		}
+		words = append(words, trainWord{symbols: symbols, freq: f})
+	}
+
+	vocab := make([][]byte, 256)
+	for i := range vocab {
+		vocab[i] = []byte{byte(i)}
+	}
+
+	counts := make(map[trainPair]int)
+	where := make(map[trainPair]map[int]struct{})
+	for wi, w := range words {
+		for i := 0; i+1 < len(w.symbols); i++ {
+			p := trainPair{w.symbols[i], w.symbols[i+1]}
+			counts[p] += w.freq
+			if where[p] == nil {
+				where[p] = make(map[int]struct{})
+			}
+			where[p][wi] = struct{}{}
+		}
+	}
+
+	h := &pairHeap{}
+	for p, c := range counts {
+		*h = append(*h, pairCount{p, c})
+	}
+	heap.Init(h)
+
+	for len(vocab) < vocabSize && h.Len() > 0 {
+		best := heap.Pop(h).(pairCount)
+		count := counts[best.pair]
+		if count != best.count {
+			// Stale entry; the pair's current count was pushed when it changed
+			if count > 0 {
+				heap.Push(h, pairCount{best.pair, count})
+			}
+			continue
+		}
+		if count < 2 {
+			break
+		}
+
+		id := len(vocab)
+		merged := append(append([]byte{}, vocab[best.pair[0]]...), vocab[best.pair[1]]...)
+		vocab = append(vocab, merged)
+
+		touched := make(map[trainPair]bool)
+		for wi := range where[best.pair] {
+			w := &words[wi]
+			for i := 0; i+1 < len(w.symbols); i++ {
+				counts[trainPair{w.symbols[i], w.symbols[i+1]}] -= w.freq
+			}
+			symbols := make([]int, 0, len(w.symbols))
+			for i := 0; i < len(w.symbols); i++ {
+				if i+1 < len(w.symbols) && w.symbols[i] == best.pair[0] && w.symbols[i+1] == best.pair[1] {
+					symbols = append(symbols, id)
+					i++
+				} else {
+					symbols = append(symbols, w.symbols[i])
+				}
+			}
+			w.symbols = symbols
+			for i := 0; i+1 < len(w.symbols); i++ {
+				p := trainPair{w.symbols[i], w.symbols[i+1]}
+				counts[p] += w.freq
+				touched[p] = true
+				if where[p] == nil {
+					where[p] = make(map[int]struct{})
+				}
+				where[p][wi] = struct{}{}
+			}
+		}
+		delete(where, best.pair)
+		delete(counts, best.pair)
+		for p := range touched {
+			if c := counts[p]; c > 0 {
+				heap.Push(h, pairCount{p, c})
+			}
+		}
+	}
+
+	return vocab
+}
+
+// WriteRanks writes a vocabulary in the tiktoken rank file format parseRanks
+// reads, ranking tokens by their position
+func WriteRanks(w io.Writer, vocab [][]byte) error {
+	bw := bufio.NewWriter(w)
+	for rank, token := range vocab {
+		if _, err := fmt.Fprintf(bw, "%s %d\n", base64.StdEncoding.EncodeToString(token), rank); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// trainWord is a distinct pre-token as its current symbols
+type trainWord struct {
+	symbols []int
+	freq    int
+}
+
+// trainPair is a pair of adjacent symbols
+type trainPair [2]int
+
+type pairCount struct {
+	pair  trainPair
+	count int
+}
+
+// pairHeap orders pairs by count, highest first, then by their symbols
+type pairHeap []pairCount
+
+func (h pairHeap) Len() int { return len(h) }
+
+func (h pairHeap) Less(i, j int) bool {
+	if h[i].count != h[j].count {
+		return h[i].count > h[j].count
+	}
+	if h[i].pair[0] != h[j].pair[0] {
+		return h[i].pair[0] < h[j].pair[0]
+	}
+	return h[i].pair[1] < h[j].pair[1]
+}
+
+func (h pairHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *pairHeap) Push(x interface{}) { *h = append(*h, x.(pairCount)) }
+
+func (h *pairHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
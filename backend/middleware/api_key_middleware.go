@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+// APIKeyAuthenticator defines the interface for resolving an API key to the
+// active application key it matches
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, apiKey string) (*models.APIKey, error)
+}
+
+// UnauthorizedWriter writes the response for a missing or unknown API key.
+// Vendor-compatible API surfaces answer in the vendor's error shape.
+type UnauthorizedWriter func(w http.ResponseWriter, message string)
+
+// APIKeyMiddleware authenticates applications by their API key
+type APIKeyMiddleware struct {
+	keys   APIKeyAuthenticator
+	logger *zap.Logger
+}
+
+// NewAPIKeyMiddleware creates a new APIKeyMiddleware
+func NewAPIKeyMiddleware(keys APIKeyAuthenticator, logger *zap.Logger) *APIKeyMiddleware {
+	return &APIKeyMiddleware{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+// RequireAPIKey is a middleware that requires an application API key, as a
+// Bearer token or in the X-API-Key header, and sets the application's org and
+// app IDs and the key in the context.
+// A nil onUnauthorized writes the gateway's own error shape.
+func (m *APIKeyMiddleware) RequireAPIKey(onUnauthorized UnauthorizedWriter) func(http.Handler) http.Handler {
+	if onUnauthorized == nil {
+		onUnauthorized = func(w http.ResponseWriter, message string) {
+			_ = utils.WriteUnauthorized(w, message)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			requestID := GetRequestIDFromContext(ctx)
+
+			apiKey := extractAPIKey(r)
+			if apiKey == "" {
+				m.logger.Warn("missing api key",
+					zap.String("request_id", requestID))
+				onUnauthorized(w, "Missing API key")
+				return
+			}
+
+			key, err := m.keys.Authenticate(ctx, apiKey)
+			if err != nil {
+				m.logger.Warn("api key authentication failed",
+					zap.String("request_id", requestID),
+					zap.Error(err))
+				onUnauthorized(w, "Invalid API key")
+				return
+			}
+			if !allowsClient(key, r) {
+				m.logger.Warn("api key used from a disallowed address",
+					zap.String("request_id", requestID),
+					zap.String("key_id", key.ID.String()),
+					zap.String("remote_addr", r.RemoteAddr))
+				onUnauthorized(w, "API key is not allowed from this address")
+				return
+			}
+
+			ctx = WithOrgID(ctx, key.OrgID)
+			ctx = WithAppID(ctx, key.AppID)
+			ctx = WithAPIKey(ctx, key)
+
+			m.logger.Debug("api key authentication successful",
+				zap.String("request_id", requestID),
+				zap.String("org_id", key.OrgID.String()),
+				zap.String("app_id", key.AppID.String()),
+				zap.String("key_id", key.ID.String()))
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// extractAPIKey extracts the API key from the Authorization header ("Bearer KEY")
+// or the X-API-Key header
+func extractAPIKey(r *http.Request) string {
+	if apiKey := extractBearerToken(r); apiKey != "" {
+		return apiKey
+	}
+	return r.Header.Get("X-API-Key")
+}
+
+// allowsClient reports whether the key's CIDR allowlist admits the client.
+// The client is as resolved by the ClientIP middleware, which only believes
+// forwarded headers from trusted proxies; without it, the TCP peer.
+func allowsClient(key *models.APIKey, r *http.Request) bool {
+	scopes, err := key.GetScopes()
+	if err != nil {
+		return false
+	}
+
+	ip := GetClientIPFromContext(r.Context())
+	if ip == nil {
+		ip = hostIP(r.RemoteAddr)
+	}
+	return scopes.AllowsIP(ip)
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/upb/llm-control-plane/backend/models"
+	"go.uber.org/zap"
+)
+
+// fakeAPIKeyAuthenticator resolves keys from a map of plaintext keys
+type fakeAPIKeyAuthenticator map[string]*models.APIKey
+
+func (f fakeAPIKeyAuthenticator) Authenticate(ctx context.Context, apiKey string) (*models.APIKey, error) {
+	if key, ok := f[apiKey]; ok {
+		return key, nil
+	}
+	return nil, errors.New("invalid api key")
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	key := models.NewAPIKey(uuid.New(), uuid.New(), "default", "sk-valid", "hash", nil)
+	m := NewAPIKeyMiddleware(fakeAPIKeyAuthenticator{"sk-valid": key}, zap.NewNop())
+
+	var orgID, appID uuid.UUID
+	var authenticated *models.APIKey
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgID = GetOrgIDFromContext(r.Context())
+		appID = GetAppIDFromContext(r.Context())
+		authenticated = GetAPIKeyFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("valid key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+		req.Header.Set("Authorization", "Bearer sk-valid")
+		w := httptest.NewRecorder()
+
+		m.RequireAPIKey(nil)(next).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, key.OrgID, orgID)
+		assert.Equal(t, key.AppID, appID)
+		assert.Equal(t, key, authenticated)
+	})
+
+	t.Run("x-api-key header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/messages", nil)
+		req.Header.Set("X-API-Key", "sk-valid")
+		w := httptest.NewRecorder()
+
+		m.RequireAPIKey(nil)(next).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, key.AppID, appID)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+		req.Header.Set("Authorization", "Bearer sk-other")
+		w := httptest.NewRecorder()
+
+		m.RequireAPIKey(nil)(next).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("custom error shape", func(t *testing.T) {
+		var message string
+		onUnauthorized := func(w http.ResponseWriter, msg string) {
+			message = msg
+			w.WriteHeader(http.StatusTeapot)
+		}
+		w := httptest.NewRecorder()
+
+		m.RequireAPIKey(onUnauthorized)(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+
+		assert.Equal(t, http.StatusTeapot, w.Code)
+		assert.Equal(t, "Missing API key", message)
+	})
+
+	t.Run("client outside the key's networks", func(t *testing.T) {
+		scoped := models.NewAPIKey(uuid.New(), uuid.New(), "ci", "sk-scoped", "hash", nil)
+		assert.NoError(t, scoped.SetScopes(&models.APIKeyScopes{AllowedCIDRs: []string{"10.0.0.0/8"}}))
+		m := NewAPIKeyMiddleware(fakeAPIKeyAuthenticator{"sk-scoped": scoped}, zap.NewNop())
+
+		for addr, status := range map[string]int{
+			"10.1.2.3:4567":  http.StatusOK,
+			"192.0.2.1:4567": http.StatusUnauthorized,
+			"10.1.2.3":       http.StatusOK,
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+			req.Header.Set("Authorization", "Bearer sk-scoped")
+			req.RemoteAddr = addr
+			w := httptest.NewRecorder()
+
+			m.RequireAPIKey(nil)(next).ServeHTTP(w, req)
+
+			assert.Equal(t, status, w.Code, addr)
+		}
+	})
+	t.Run("forwarded address from an untrusted peer", func(t *testing.T) {
+		scoped := models.NewAPIKey(uuid.New(), uuid.New(), "ci", "sk-scoped", "hash", nil)
+		assert.NoError(t, scoped.SetScopes(&models.APIKeyScopes{AllowedCIDRs: []string{"10.0.0.0/8"}}))
+		m := NewAPIKeyMiddleware(fakeAPIKeyAuthenticator{"sk-scoped": scoped}, zap.NewNop())
+
+		for _, header := range []string{"X-Forwarded-For", "X-Real-IP"} {
+			req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+			req.Header.Set("Authorization", "Bearer sk-scoped")
+			req.Header.Set(header, "10.1.2.3")
+			req.RemoteAddr = "192.0.2.1:4567"
+			w := httptest.NewRecorder()
+
+			ClientIP([]string{"172.16.0.0/12"})(m.RequireAPIKey(nil)(next)).ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code, header)
+		}
+	})
+}
@@ -2,10 +2,14 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/apikey"
+	"github.com/upb/llm-control-plane/backend/services/session"
 	"github.com/upb/llm-control-plane/backend/utils"
 	"go.uber.org/zap"
 )
@@ -16,10 +20,37 @@ type TokenValidator interface {
 	ValidateToken(ctx context.Context, token string) (*Claims, error)
 }
 
+// PermissionChecker resolves the permissions of roles within an organization
+type PermissionChecker interface {
+	// HasPermission reports whether any of the roles grants permission
+	HasPermission(ctx context.Context, orgID uuid.UUID, roles []string, permission models.Permission) (bool, error)
+}
+
+// SessionAuthenticator resolves a session token to the active session it identifies
+type SessionAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (*models.Session, error)
+}
+
+// ImpersonationAuditor records the requests admins make while impersonating users
+type ImpersonationAuditor interface {
+	LogImpersonatedRequest(orgID uuid.UUID, userID, impersonatorID *uuid.UUID, impersonatorSub, method, path, requestID string) error
+}
+
+// UserFinder finds the users JWTs are issued to
+type UserFinder interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetByCognitoSub(ctx context.Context, cognitoSub string) (*models.User, error)
+}
+
 // AuthMiddleware provides authentication middleware functionality
 type AuthMiddleware struct {
-	validator TokenValidator
-	logger    *zap.Logger
+	validator   TokenValidator
+	apiKeys     APIKeyAuthenticator
+	sessions    SessionAuthenticator
+	permissions PermissionChecker
+	auditor     ImpersonationAuditor
+	users       UserFinder
+	logger      *zap.Logger
 }
 
 // NewAuthMiddleware creates a new AuthMiddleware
@@ -30,17 +61,56 @@ func NewAuthMiddleware(validator TokenValidator, logger *zap.Logger) *AuthMiddle
 	}
 }
 
+// SetAPIKeyAuthenticator lets server-to-server callers authenticate with an
+// application API key instead of a JWT
+func (m *AuthMiddleware) SetAPIKeyAuthenticator(apiKeys APIKeyAuthenticator) {
+	m.apiKeys = apiKeys
+}
+
+// SetSessionAuthenticator lets browsers authenticate with a server-side
+// session token; session cookies holding a JWT are still validated as one
+func (m *AuthMiddleware) SetSessionAuthenticator(sessions SessionAuthenticator) {
+	m.sessions = sessions
+}
+
+// SetPermissionChecker resolves permissions from the organizations' role
+// mappings; without one, RequirePermission uses the built-in roles
+func (m *AuthMiddleware) SetPermissionChecker(permissions PermissionChecker) {
+	m.permissions = permissions
+}
+
+// SetImpersonationAuditor records every request made with an impersonation
+// token in the audit log, under the impersonating admin
+func (m *AuthMiddleware) SetImpersonationAuditor(auditor ImpersonationAuditor) {
+	m.auditor = auditor
+}
+
+// SetUserFinder rejects JWTs of deactivated users. Deactivation revokes
+// sessions and API keys, but JWTs stay valid until they expire, so their
+// user is looked up on every request.
+func (m *AuthMiddleware) SetUserFinder(users UserFinder) {
+	m.users = users
+}
+
 // authTokenCookieName is the cookie name for JWT tokens (Authorization header takes precedence)
 // sessionCookieName is set by auth handler after OAuth callback
 const authTokenCookieName = "auth_token"
 const sessionCookieName = "session"
 
-// RequireAuth is a middleware that requires a valid JWT token
+// RequireAuth is a middleware that requires a valid JWT token, an application
+// API key when an APIKeyAuthenticator is set, or an active session when a
+// SessionAuthenticator is set
 func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		requestID := GetRequestIDFromContext(ctx)
 		
+		// API keys come in the X-API-Key header, or as Bearer tokens with the key prefix
+		if apiKey := extractGatewayAPIKey(r); apiKey != "" && m.apiKeys != nil {
+			m.authenticateAPIKey(w, r, next, apiKey)
+			return
+		}
+		
 		// Extract token from cookie ("auth_token") or Authorization header ("Bearer TOKEN")
 		token := extractToken(r)
 		if token == "" {
@@ -50,6 +120,11 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 			return
 		}
 		
+		if strings.HasPrefix(token, session.TokenPrefix) && m.sessions != nil {
+			m.authenticateSession(w, r, next, token)
+			return
+		}
+		
 		// Validate token
 		claims, err := m.validator.ValidateToken(ctx, token)
 		if err != nil {
@@ -60,9 +135,24 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 			return
 		}
 		
+		if !m.usersActive(ctx, claims) {
+			m.logger.Warn("token of a deactivated user",
+				zap.String("request_id", requestID),
+				zap.String("sub", claims.Sub),
+				zap.String("user_id", claims.UserID))
+			_ = utils.WriteUnauthorized(w, "Invalid or expired token")
+			return
+		}
+		
 		// Add claims to context
 		ctx = WithClaims(ctx, claims)
 		
+		// Impersonation tokens carry the impersonating admin besides the user;
+		// every route behind RequireAuth records them, with or without ExtractTenant
+		if claims.Impersonator != nil {
+			ctx = m.withImpersonator(ctx, r, claims)
+		}
+		
 		m.logger.Debug("authentication successful",
 			zap.String("request_id", requestID),
 			zap.String("sub", claims.Sub),
@@ -73,6 +163,133 @@ func (m *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 	})
 }
 
+// usersActive reports whether the users behind a JWT may still use it. Users
+// the gateway does not know, such as identity provider users never
+// provisioned, pass. Impersonation tokens are issued for known users only, so
+// both the user and the impersonating admin must exist and be active.
+func (m *AuthMiddleware) usersActive(ctx context.Context, claims *Claims) bool {
+	if m.users == nil {
+		return true
+	}
+
+	if claims.Impersonator != nil {
+		user, err := m.findUser(ctx, claims.UserID, "")
+		if err != nil || !user.IsActive() {
+			return false
+		}
+		impersonator, err := m.findUser(ctx, claims.Impersonator.UserID, "")
+		return err == nil && impersonator.IsActive()
+	}
+
+	user, err := m.findUser(ctx, claims.UserID, claims.Sub)
+	return err != nil || user.IsActive()
+}
+
+// findUser finds a user by ID or, failing that, by identity provider subject
+func (m *AuthMiddleware) findUser(ctx context.Context, userID, sub string) (*models.User, error) {
+	err := fmt.Errorf("user not found: %q", userID)
+	if id, parseErr := uuid.Parse(userID); parseErr == nil {
+		var user *models.User
+		if user, err = m.users.GetByID(ctx, id); err == nil {
+			return user, nil
+		}
+	}
+	if sub == "" {
+		return nil, err
+	}
+	return m.users.GetByCognitoSub(ctx, sub)
+}
+
+// authenticateAPIKey authenticates a request by application API key. The key
+// is presented to later middleware as claims of its application without groups,
+// so API keys pass ExtractTenant but no RequireRole or RequirePermission check.
+func (m *AuthMiddleware) authenticateAPIKey(w http.ResponseWriter, r *http.Request, next http.Handler, apiKey string) {
+	ctx := r.Context()
+	requestID := GetRequestIDFromContext(ctx)
+
+	key, err := m.apiKeys.Authenticate(ctx, apiKey)
+	if err != nil {
+		m.logger.Warn("api key authentication failed",
+			zap.String("request_id", requestID),
+			zap.Error(err))
+		_ = utils.WriteUnauthorized(w, "Invalid or revoked API key")
+		return
+	}
+	if !allowsClient(key, r) {
+		m.logger.Warn("api key used from a disallowed address",
+			zap.String("request_id", requestID),
+			zap.String("key_id", key.ID.String()),
+			zap.String("remote_addr", r.RemoteAddr))
+		_ = utils.WriteUnauthorized(w, "API key is not allowed from this address")
+		return
+	}
+
+	ctx = WithClaims(ctx, &Claims{
+		Sub:   "apikey:" + key.ID.String(),
+		OrgID: key.OrgID.String(),
+		AppID: key.AppID.String(),
+	})
+	ctx = WithAPIKey(ctx, key)
+
+	m.logger.Debug("api key authentication successful",
+		zap.String("request_id", requestID),
+		zap.String("key_id", key.ID.String()),
+		zap.String("app_id", key.AppID.String()))
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// authenticateSession authenticates a request by server-side session. The
+// session is looked up on every request, so revoked sessions are rejected
+// immediately; its identity is presented to later middleware as claims.
+func (m *AuthMiddleware) authenticateSession(w http.ResponseWriter, r *http.Request, next http.Handler, token string) {
+	ctx := r.Context()
+	requestID := GetRequestIDFromContext(ctx)
+
+	sess, err := m.sessions.Authenticate(ctx, token)
+	if err != nil {
+		m.logger.Warn("session authentication failed",
+			zap.String("request_id", requestID),
+			zap.Error(err))
+		_ = utils.WriteUnauthorized(w, "Invalid or expired session")
+		return
+	}
+	roles, err := sess.GetRoles()
+	if err != nil {
+		m.logger.Error("invalid session roles",
+			zap.String("request_id", requestID),
+			zap.String("session_id", sess.ID.String()),
+			zap.Error(err))
+		_ = utils.WriteUnauthorized(w, "Invalid or expired session")
+		return
+	}
+
+	claims := &Claims{
+		Sub:           sess.Subject,
+		Email:         sess.Email,
+		EmailVerified: sess.EmailVerified,
+		Groups:        roles,
+		OrgID:         sess.OrgID.String(),
+		Iss:           sess.Issuer,
+		Exp:           sess.IdentityExpiresAt.Unix(),
+	}
+	if sess.AppID != nil {
+		claims.AppID = sess.AppID.String()
+	}
+	if sess.UserID != nil {
+		claims.UserID = sess.UserID.String()
+	}
+	ctx = WithClaims(ctx, claims)
+	ctx = WithSession(ctx, sess)
+
+	m.logger.Debug("session authentication successful",
+		zap.String("request_id", requestID),
+		zap.String("session_id", sess.ID.String()),
+		zap.String("sub", sess.Subject))
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // ExtractTenant is a middleware that extracts tenant information from claims
 // This should be called after RequireAuth
 func (m *AuthMiddleware) ExtractTenant(next http.Handler) http.Handler {
@@ -142,6 +359,61 @@ func (m *AuthMiddleware) ExtractTenant(next http.Handler) http.Handler {
 	})
 }
 
+// withImpersonator adds the impersonating admin to the context and records
+// the request in the audit log
+func (m *AuthMiddleware) withImpersonator(ctx context.Context, r *http.Request, claims *Claims) context.Context {
+	requestID := GetRequestIDFromContext(ctx)
+
+	// Impersonation tokens are issued by the gateway, so their tenant and
+	// user are well-formed; unparsable values are audited as nil
+	orgID, _ := uuid.Parse(claims.OrgID)
+	var userID *uuid.UUID
+	if parsed, err := uuid.Parse(claims.UserID); err == nil {
+		userID = &parsed
+	}
+
+	var impersonatorID *uuid.UUID
+	if parsed, err := uuid.Parse(claims.Impersonator.UserID); err == nil {
+		impersonatorID = &parsed
+		ctx = WithImpersonatorID(ctx, impersonatorID)
+	}
+
+	m.logger.Info("impersonated request",
+		zap.String("request_id", requestID),
+		zap.String("sub", claims.Sub),
+		zap.String("impersonator_sub", claims.Impersonator.Sub),
+		zap.String("method", r.Method),
+		zap.String("path", r.URL.Path))
+
+	if m.auditor != nil {
+		if err := m.auditor.LogImpersonatedRequest(orgID, userID, impersonatorID, claims.Impersonator.Sub, r.Method, r.URL.Path, requestID); err != nil {
+			m.logger.Error("failed to log impersonated request",
+				zap.String("request_id", requestID),
+				zap.Error(err))
+		}
+	}
+	return ctx
+}
+
+// DenyImpersonation is a middleware that rejects requests made with an
+// impersonation token, for destructive operations admins must perform as
+// themselves
+func (m *AuthMiddleware) DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if claims := GetClaimsFromContext(r.Context()); claims != nil && claims.IsImpersonated() {
+			m.logger.Warn("operation denied while impersonating",
+				zap.String("request_id", GetRequestIDFromContext(r.Context())),
+				zap.String("sub", claims.Sub),
+				zap.String("impersonator_sub", claims.Impersonator.Sub),
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path))
+			_ = utils.WriteForbidden(w, "Operation not allowed while impersonating")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 // RequireRole is a middleware that requires a specific role
 func (m *AuthMiddleware) RequireRole(role string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -186,6 +458,83 @@ func (m *AuthMiddleware) RequireRole(role string) func(http.Handler) http.Handle
 	}
 }
 
+// RequirePermission is a middleware that requires one of the user's roles to
+// grant a permission within the user's organization
+func (m *AuthMiddleware) RequirePermission(permission models.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			requestID := GetRequestIDFromContext(ctx)
+
+			// Get claims from context
+			claims := GetClaimsFromContext(ctx)
+			if claims == nil {
+				m.logger.Error("claims not found in context",
+					zap.String("request_id", requestID))
+				_ = utils.WriteUnauthorized(w, "Authentication required")
+				return
+			}
+
+			allowed, err := m.Authorize(r, permission)
+			if err != nil {
+				m.logger.Error("permission check failed",
+					zap.String("request_id", requestID),
+					zap.String("permission", string(permission)),
+					zap.Error(err))
+				_ = utils.WriteInternalServerError(w, "Failed to check permissions")
+				return
+			}
+
+			if !allowed {
+				m.logger.Warn("insufficient permissions",
+					zap.String("request_id", requestID),
+					zap.String("required_permission", string(permission)),
+					zap.Strings("user_groups", claims.Groups))
+				_ = utils.WriteForbidden(w, "Insufficient permissions")
+				return
+			}
+
+			m.logger.Debug("permission check passed",
+				zap.String("request_id", requestID),
+				zap.String("required_permission", string(permission)))
+
+			// Call next handler
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Authorize reports whether the authenticated user's roles grant permission,
+// for checks that depend on the request body. Tokens limited to scopes must
+// also be scoped to the permission.
+func (m *AuthMiddleware) Authorize(r *http.Request, permission models.Permission) (bool, error) {
+	claims := GetClaimsFromContext(r.Context())
+	if claims == nil || len(claims.Groups) == 0 {
+		return false, nil
+	}
+	if len(claims.Scopes) > 0 && !scopesGrant(claims.Scopes, permission) {
+		return false, nil
+	}
+	if m.permissions == nil {
+		return models.BuiltInRolesGrant(claims.Groups, permission), nil
+	}
+
+	orgID, err := uuid.Parse(claims.OrgID)
+	if err != nil {
+		return false, nil
+	}
+	return m.permissions.HasPermission(r.Context(), orgID, claims.Groups, permission)
+}
+
+// scopesGrant reports whether a token's scopes include permission
+func scopesGrant(scopes []string, permission models.Permission) bool {
+	permissions := make([]models.Permission, 0, len(scopes))
+	for _, scope := range scopes {
+		permissions = append(permissions, models.Permission(scope))
+	}
+	return models.Grants(permissions, permission)
+}
+
 // extractToken extracts JWT from cookie ("auth_token") or Authorization header ("Bearer TOKEN").
 // Authorization header takes precedence when both are present.
 func extractToken(r *http.Request) string {
@@ -202,6 +551,18 @@ func extractToken(r *http.Request) string {
 	return ""
 }
 
+// extractGatewayAPIKey extracts an API key issued by the gateway from the
+// X-API-Key header, or from the Authorization header ("Bearer lcp_...")
+func extractGatewayAPIKey(r *http.Request) string {
+	if apiKey := r.Header.Get("X-API-Key"); apiKey != "" {
+		return apiKey
+	}
+	if token := extractBearerToken(r); strings.HasPrefix(token, apikey.KeyPrefix) {
+		return token
+	}
+	return ""
+}
+
 // extractBearerToken extracts the Bearer token from the Authorization header
 func extractBearerToken(r *http.Request) string {
 	authHeader := r.Header.Get("Authorization")
@@ -6,11 +6,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/upb/llm-control-plane/backend/cognito"
+	"github.com/upb/llm-control-plane/backend/models"
 	"go.uber.org/zap"
 )
 
@@ -210,6 +212,163 @@ func TestRequireAuth(t *testing.T) {
 	})
 }
 
+func TestRequireAuth_APIKey(t *testing.T) {
+	logger := zap.NewNop()
+	key := models.NewAPIKey(uuid.New(), uuid.New(), "server", "lcp_0123abcd", "hash", nil)
+	keys := fakeAPIKeyAuthenticator{"lcp_0123abcdef": key}
+
+	var claims *Claims
+	var authenticated *models.APIKey
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims = GetClaimsFromContext(r.Context())
+		authenticated = GetAPIKeyFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+	}{
+		{"x-api-key header", "X-API-Key", "lcp_0123abcdef"},
+		{"bearer token with key prefix", "Authorization", "Bearer lcp_0123abcdef"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockValidator := new(MockTokenValidator)
+			m := NewAuthMiddleware(mockValidator, logger)
+			m.SetAPIKeyAuthenticator(keys)
+			claims, authenticated = nil, nil
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set(tt.header, tt.value)
+			w := httptest.NewRecorder()
+
+			m.RequireAuth(next).ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, key, authenticated)
+			assert.Equal(t, key.OrgID.String(), claims.OrgID)
+			assert.Equal(t, key.AppID.String(), claims.AppID)
+			assert.Empty(t, claims.Groups)
+			mockValidator.AssertNotCalled(t, "ValidateToken", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("unknown key returns 401", func(t *testing.T) {
+		m := NewAuthMiddleware(new(MockTokenValidator), logger)
+		m.SetAPIKeyAuthenticator(keys)
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("X-API-Key", "lcp_revoked")
+		w := httptest.NewRecorder()
+
+		m.RequireAuth(next).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("without authenticator keys are not accepted", func(t *testing.T) {
+		m := NewAuthMiddleware(new(MockTokenValidator), logger)
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("X-API-Key", "lcp_0123abcdef")
+		w := httptest.NewRecorder()
+
+		m.RequireAuth(next).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+// fakeSessionAuthenticator resolves sessions from a map of tokens
+type fakeSessionAuthenticator map[string]*models.Session
+
+func (f fakeSessionAuthenticator) Authenticate(ctx context.Context, token string) (*models.Session, error) {
+	if session, ok := f[token]; ok && session.RevokedAt == nil {
+		return session, nil
+	}
+	return nil, errors.New("invalid session")
+}
+
+func TestRequireAuth_Session(t *testing.T) {
+	logger := zap.NewNop()
+	userID := uuid.New()
+	session := &models.Session{
+		ID:                uuid.New(),
+		OrgID:             uuid.New(),
+		UserID:            &userID,
+		Provider:          "cognito",
+		Subject:           userID.String(),
+		Email:             "user@example.com",
+		IdentityExpiresAt: time.Now().Add(time.Hour),
+		ExpiresAt:         time.Now().Add(time.Hour),
+	}
+	assert.NoError(t, session.SetRoles([]string{"admin"}))
+	sessions := fakeSessionAuthenticator{"lcps_current": session}
+
+	var claims *Claims
+	var authenticated *models.Session
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims = GetClaimsFromContext(r.Context())
+		authenticated = GetSessionFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	t.Run("session cookie authenticates as the session's identity", func(t *testing.T) {
+		mockValidator := new(MockTokenValidator)
+		m := NewAuthMiddleware(mockValidator, logger)
+		m.SetSessionAuthenticator(sessions)
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.AddCookie(&http.Cookie{Name: "session", Value: "lcps_current"})
+		w := httptest.NewRecorder()
+
+		m.RequireAuth(next).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, session, authenticated)
+		assert.Equal(t, session.OrgID.String(), claims.OrgID)
+		assert.Equal(t, userID.String(), claims.UserID)
+		assert.Equal(t, []string{"admin"}, claims.Groups)
+		mockValidator.AssertNotCalled(t, "ValidateToken", mock.Anything, mock.Anything)
+	})
+
+	t.Run("revoked session returns 401", func(t *testing.T) {
+		revokedAt := time.Now()
+		revoked := *session
+		revoked.RevokedAt = &revokedAt
+		m := NewAuthMiddleware(new(MockTokenValidator), logger)
+		m.SetSessionAuthenticator(fakeSessionAuthenticator{"lcps_revoked": &revoked})
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.AddCookie(&http.Cookie{Name: "session", Value: "lcps_revoked"})
+		w := httptest.NewRecorder()
+
+		m.RequireAuth(next).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("legacy session cookies are validated as JWTs", func(t *testing.T) {
+		mockValidator := new(MockTokenValidator)
+		mockValidator.On("ValidateToken", mock.Anything, "legacy-id-token").Return(&Claims{Sub: "user-123"}, nil)
+		m := NewAuthMiddleware(mockValidator, logger)
+		m.SetSessionAuthenticator(sessions)
+		authenticated = nil
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.AddCookie(&http.Cookie{Name: "session", Value: "legacy-id-token"})
+		w := httptest.NewRecorder()
+
+		m.RequireAuth(next).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Nil(t, authenticated)
+		mockValidator.AssertExpectations(t)
+	})
+}
+
 func TestExtractTenant(t *testing.T) {
 	logger := zap.NewNop()
 	
@@ -365,6 +524,193 @@ func TestExtractTenant(t *testing.T) {
 	})
 }
 
+// fakeImpersonationAuditor records impersonated requests
+type fakeImpersonationAuditor struct {
+	orgID          uuid.UUID
+	userID         *uuid.UUID
+	impersonatorID *uuid.UUID
+	method, path   string
+}
+
+func (f *fakeImpersonationAuditor) LogImpersonatedRequest(orgID uuid.UUID, userID, impersonatorID *uuid.UUID, impersonatorSub, method, path, requestID string) error {
+	f.orgID, f.userID, f.impersonatorID, f.method, f.path = orgID, userID, impersonatorID, method, path
+	return nil
+}
+
+func TestRequireAuth_Impersonation(t *testing.T) {
+	orgID := uuid.New()
+	userID := uuid.New()
+	adminID := uuid.New()
+	claims := &Claims{
+		Sub:          userID.String(),
+		OrgID:        orgID.String(),
+		UserID:       userID.String(),
+		Groups:       []string{"member"},
+		Impersonator: &Impersonator{Sub: adminID.String(), UserID: adminID.String()},
+	}
+
+	t.Run("with tenant extraction", func(t *testing.T) {
+		auditor := &fakeImpersonationAuditor{}
+		validator := new(MockTokenValidator)
+		validator.On("ValidateToken", mock.Anything, "impersonation-token").Return(claims, nil)
+		m := NewAuthMiddleware(validator, zap.NewNop())
+		m.SetImpersonationAuditor(auditor)
+
+		var gotUserID, gotImpersonatorID, gotActorID *uuid.UUID
+		handler := m.RequireAuth(m.ExtractTenant(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotUserID = GetUserIDFromContext(r.Context())
+			gotImpersonatorID = GetImpersonatorIDFromContext(r.Context())
+			gotActorID = GetActorIDFromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		})))
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/templates", nil)
+		req.Header.Set("Authorization", "Bearer impersonation-token")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, userID, *gotUserID)
+		assert.Equal(t, adminID, *gotImpersonatorID)
+		assert.Equal(t, adminID, *gotActorID)
+		assert.Equal(t, orgID, auditor.orgID)
+		assert.Equal(t, userID, *auditor.userID)
+		assert.Equal(t, adminID, *auditor.impersonatorID)
+		assert.Equal(t, http.MethodPost, auditor.method)
+		assert.Equal(t, "/api/v1/templates", auditor.path)
+	})
+
+	t.Run("without tenant extraction", func(t *testing.T) {
+		auditor := &fakeImpersonationAuditor{}
+		validator := new(MockTokenValidator)
+		validator.On("ValidateToken", mock.Anything, "impersonation-token").Return(claims, nil)
+		m := NewAuthMiddleware(validator, zap.NewNop())
+		m.SetImpersonationAuditor(auditor)
+
+		var gotActorID *uuid.UUID
+		handler := m.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotActorID = GetActorIDFromContext(r.Context())
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users/me", nil)
+		req.Header.Set("Authorization", "Bearer impersonation-token")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, adminID, *gotActorID)
+		assert.Equal(t, orgID, auditor.orgID)
+		assert.Equal(t, "/api/v1/users/me", auditor.path)
+	})
+}
+
+// fakeUserFinder finds users by ID and Cognito subject
+type fakeUserFinder []*models.User
+
+func (f fakeUserFinder) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	for _, user := range f {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (f fakeUserFinder) GetByCognitoSub(ctx context.Context, cognitoSub string) (*models.User, error) {
+	for _, user := range f {
+		if user.CognitoSub == cognitoSub {
+			return user, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func TestRequireAuth_DeactivatedUsers(t *testing.T) {
+	orgID := uuid.New()
+	deactivatedAt := time.Now()
+	active := models.NewUser("active@example.com", "cognito-active", orgID, models.RoleMember)
+	deactivated := models.NewUser("gone@example.com", "cognito-gone", orgID, models.RoleMember)
+	deactivated.DeactivatedAt = &deactivatedAt
+	admin := models.NewUser("admin@example.com", "cognito-admin", orgID, models.RoleAdmin)
+	deactivatedAdmin := models.NewUser("former-admin@example.com", "cognito-former-admin", orgID, models.RoleAdmin)
+	deactivatedAdmin.DeactivatedAt = &deactivatedAt
+	users := fakeUserFinder{active, deactivated, admin, deactivatedAdmin}
+
+	impersonating := func(user, impersonator *models.User) *Claims {
+		return &Claims{
+			Sub:          user.ID.String(),
+			OrgID:        orgID.String(),
+			UserID:       user.ID.String(),
+			Impersonator: &Impersonator{Sub: impersonator.CognitoSub, UserID: impersonator.ID.String()},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		claims   *Claims
+		expected int
+	}{
+		{"active user by ID", &Claims{Sub: "other", UserID: active.ID.String()}, http.StatusOK},
+		{"active user by subject", &Claims{Sub: "cognito-active", UserID: "cognito-active"}, http.StatusOK},
+		{"user unknown to the gateway", &Claims{Sub: "never-provisioned"}, http.StatusOK},
+		{"deactivated user by ID", &Claims{Sub: "other", UserID: deactivated.ID.String()}, http.StatusUnauthorized},
+		{"deactivated user by subject", &Claims{Sub: "cognito-gone"}, http.StatusUnauthorized},
+		{"impersonating an active user", impersonating(active, admin), http.StatusOK},
+		{"impersonating a deactivated user", impersonating(deactivated, admin), http.StatusUnauthorized},
+		{"impersonating by a deactivated admin", impersonating(active, deactivatedAdmin), http.StatusUnauthorized},
+		{"impersonating an unknown user", &Claims{Sub: "x", UserID: uuid.New().String(), Impersonator: &Impersonator{Sub: "cognito-admin", UserID: admin.ID.String()}}, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := new(MockTokenValidator)
+			validator.On("ValidateToken", mock.Anything, "token").Return(tt.claims, nil)
+			m := NewAuthMiddleware(validator, zap.NewNop())
+			m.SetUserFinder(users)
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.Header.Set("Authorization", "Bearer token")
+			w := httptest.NewRecorder()
+
+			m.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestDenyImpersonation(t *testing.T) {
+	m := NewAuthMiddleware(new(MockTokenValidator), zap.NewNop())
+	handler := m.DenyImpersonation(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name   string
+		claims *Claims
+		status int
+	}{
+		{"own token", &Claims{Sub: "admin", Groups: []string{"admin"}}, http.StatusOK},
+		{"impersonation token", &Claims{Sub: "user", Groups: []string{"admin"}, Impersonator: &Impersonator{Sub: "admin"}}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/policies/1", nil)
+			req = req.WithContext(WithClaims(req.Context(), tt.claims))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
 func TestRequireRole(t *testing.T) {
 	logger := zap.NewNop()
 	
@@ -433,6 +779,96 @@ func TestRequireRole(t *testing.T) {
 	})
 }
 
+// fakePermissionChecker grants permissions from per-role lists
+type fakePermissionChecker struct {
+	roles map[string][]models.Permission
+	err   error
+	orgID uuid.UUID
+}
+
+func (f *fakePermissionChecker) HasPermission(ctx context.Context, orgID uuid.UUID, roles []string, permission models.Permission) (bool, error) {
+	f.orgID = orgID
+	if f.err != nil {
+		return false, f.err
+	}
+	for _, role := range roles {
+		if models.Grants(f.roles[role], permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestRequirePermission(t *testing.T) {
+	logger := zap.NewNop()
+	orgID := uuid.New()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(m *AuthMiddleware, claims *Claims, permission models.Permission) int {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		if claims != nil {
+			req = req.WithContext(WithClaims(req.Context(), claims))
+		}
+		w := httptest.NewRecorder()
+		m.RequirePermission(permission)(ok).ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("built-in roles without a checker", func(t *testing.T) {
+		m := NewAuthMiddleware(new(MockTokenValidator), logger)
+
+		assert.Equal(t, http.StatusOK, serve(m, &Claims{Groups: []string{"viewer"}}, models.PermissionPoliciesRead))
+		assert.Equal(t, http.StatusForbidden, serve(m, &Claims{Groups: []string{"viewer"}}, models.PermissionPoliciesWrite))
+		assert.Equal(t, http.StatusOK, serve(m, &Claims{Groups: []string{"admin"}}, models.PermissionBudgetsOverride))
+	})
+
+	t.Run("custom role of the organization", func(t *testing.T) {
+		checker := &fakePermissionChecker{roles: map[string][]models.Permission{
+			"auditor": {models.PermissionAuditRead},
+		}}
+		m := NewAuthMiddleware(new(MockTokenValidator), logger)
+		m.SetPermissionChecker(checker)
+		claims := &Claims{OrgID: orgID.String(), Groups: []string{"auditor"}}
+
+		assert.Equal(t, http.StatusOK, serve(m, claims, models.PermissionAuditRead))
+		assert.Equal(t, orgID, checker.orgID)
+		assert.Equal(t, http.StatusForbidden, serve(m, claims, models.PermissionPoliciesRead))
+	})
+
+	t.Run("scoped tokens", func(t *testing.T) {
+		m := NewAuthMiddleware(new(MockTokenValidator), logger)
+		claims := &Claims{Groups: []string{"admin"}, Scopes: []string{"policies:read", "templates:*"}}
+
+		assert.Equal(t, http.StatusOK, serve(m, claims, models.PermissionPoliciesRead))
+		assert.Equal(t, http.StatusOK, serve(m, claims, models.PermissionTemplatesWrite))
+		assert.Equal(t, http.StatusForbidden, serve(m, claims, models.PermissionPoliciesWrite))
+		// Scopes never widen what the roles grant
+		assert.Equal(t, http.StatusForbidden, serve(m, &Claims{Groups: []string{"viewer"}, Scopes: []string{"policies:write"}}, models.PermissionPoliciesWrite))
+	})
+
+	t.Run("api keys have no roles", func(t *testing.T) {
+		m := NewAuthMiddleware(new(MockTokenValidator), logger)
+		m.SetPermissionChecker(&fakePermissionChecker{})
+
+		assert.Equal(t, http.StatusForbidden, serve(m, &Claims{OrgID: orgID.String()}, models.PermissionPoliciesRead))
+	})
+
+	t.Run("checker failure", func(t *testing.T) {
+		m := NewAuthMiddleware(new(MockTokenValidator), logger)
+		m.SetPermissionChecker(&fakePermissionChecker{err: errors.New("database down")})
+
+		assert.Equal(t, http.StatusInternalServerError, serve(m, &Claims{OrgID: orgID.String(), Groups: []string{"admin"}}, models.PermissionPoliciesRead))
+	})
+
+	t.Run("missing claims in context", func(t *testing.T) {
+		m := NewAuthMiddleware(new(MockTokenValidator), logger)
+
+		assert.Equal(t, http.StatusUnauthorized, serve(m, nil, models.PermissionPoliciesRead))
+	})
+}
+
 func TestExtractToken(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP resolves the client's address and records it in the request
+// context. X-Forwarded-For and X-Real-IP are only honoured when the TCP peer
+// is one of trustedProxies (IPs or CIDRs); otherwise the peer is the client,
+// so callers cannot spoof their address with a header. RemoteAddr is
+// rewritten to the resolved address for request logging.
+func ClientIP(trustedProxies []string) func(http.Handler) http.Handler {
+	trusted := parseNetworks(trustedProxies)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolveClientIP(r, trusted)
+			if ip != nil {
+				r.RemoteAddr = ip.String()
+				r = r.WithContext(WithClientIP(r.Context(), ip))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// resolveClientIP walks X-Forwarded-For from the right, skipping trusted
+// proxies, so only entries appended by trusted proxies are believed
+func resolveClientIP(r *http.Request, trusted []*net.IPNet) net.IP {
+	peer := hostIP(r.RemoteAddr)
+	if peer == nil || !containsIP(trusted, peer) {
+		return peer
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break
+			}
+			if !containsIP(trusted, ip) {
+				return ip
+			}
+			peer = ip
+		}
+		return peer
+	}
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip
+	}
+	return peer
+}
+
+// hostIP parses the IP of a host or host:port address
+func hostIP(addr string) net.IP {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return net.ParseIP(addr)
+}
+
+// parseNetworks parses IPs and CIDRs, skipping invalid entries; the config
+// rejects those at startup
+func parseNetworks(entries []string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			networks = append(networks, network)
+			continue
+		}
+		if ip := net.ParseIP(entry); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		}
+	}
+	return networks
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []string{"172.16.0.0/12", "192.0.2.10"}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"peer without headers", "198.51.100.7:4567", nil, "198.51.100.7"},
+		{"untrusted peer spoofing forwarded for", "198.51.100.7:4567", map[string]string{"X-Forwarded-For": "10.1.2.3"}, "198.51.100.7"},
+		{"untrusted peer spoofing real ip", "198.51.100.7:4567", map[string]string{"X-Real-IP": "10.1.2.3"}, "198.51.100.7"},
+		{"trusted proxy", "172.16.0.5:4567", map[string]string{"X-Forwarded-For": "203.0.113.9"}, "203.0.113.9"},
+		{"trusted proxy with a single ip", "192.0.2.10:4567", map[string]string{"X-Real-IP": "203.0.113.9"}, "203.0.113.9"},
+		{"spoofed hop before the proxy", "172.16.0.5:4567", map[string]string{"X-Forwarded-For": "10.1.2.3, 203.0.113.9"}, "203.0.113.9"},
+		{"chain of trusted proxies", "172.16.0.5:4567", map[string]string{"X-Forwarded-For": "203.0.113.9, 172.16.0.6"}, "203.0.113.9"},
+		{"trusted proxy without headers", "172.16.0.5:4567", nil, "172.16.0.5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			var remoteAddr string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = GetClientIPFromContext(r.Context()).String()
+				remoteAddr = r.RemoteAddr
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			ClientIP(trusted)(next).ServeHTTP(httptest.NewRecorder(), req)
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.want, remoteAddr)
+		})
+	}
+}
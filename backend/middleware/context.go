@@ -2,8 +2,10 @@ package middleware
 
 import (
 	"context"
+	"net"
 
 	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
 )
 
 // Context key type to avoid collisions
@@ -24,6 +26,20 @@ const (
 	
 	// UserIDKey is the context key for user ID
 	UserIDKey contextKey = "user_id"
+	
+	// APIKeyKey is the context key for the API key a request authenticated with
+	APIKeyKey contextKey = "api_key"
+	
+	// SessionKey is the context key for the session a request authenticated with
+	SessionKey contextKey = "session"
+
+	// ImpersonatorIDKey is the context key for the user ID of the admin
+	// impersonating the authenticated user
+	ImpersonatorIDKey contextKey = "impersonator_id"
+
+	// ClientIPKey is the context key for the client's address as resolved
+	// by the ClientIP middleware
+	ClientIPKey contextKey = "client_ip"
 )
 
 // Claims represents JWT claims extracted from the token
@@ -38,6 +54,26 @@ type Claims struct {
 	Iss           string   `json:"iss"`            // Issuer
 	Exp           int64    `json:"exp"`            // Expiration
 	Iat           int64    `json:"iat"`            // Issued at
+	Scopes        []string `json:"scopes,omitempty"` // Permissions the token is limited to; none limits it by its groups only
+	Impersonator  *Impersonator `json:"act,omitempty"` // Admin acting as the subject; nil unless impersonating
+}
+
+// Impersonator identifies the admin behind an impersonation token
+type Impersonator struct {
+	Sub    string `json:"sub"`
+	UserID string `json:"user_id,omitempty"`
+}
+
+// IsImpersonated reports whether the claims were issued to an admin acting
+// as their subject
+func (c *Claims) IsImpersonated() bool {
+	return c.Impersonator != nil
+}
+
+// IsSuperAdmin reports whether the claims carry the platform superadmin role,
+// the only role allowed to access other organizations' resources
+func (c *Claims) IsSuperAdmin() bool {
+	return models.HasSuperAdminRole(c.Groups)
 }
 
 // GetRequestIDFromContext retrieves the request ID from context
@@ -114,3 +150,77 @@ func GetUserIDFromContext(ctx context.Context) *uuid.UUID {
 func WithUserID(ctx context.Context, userID *uuid.UUID) context.Context {
 	return context.WithValue(ctx, UserIDKey, userID)
 }
+
+// GetImpersonatorIDFromContext retrieves the user ID of the admin
+// impersonating the authenticated user, or nil when not impersonating
+func GetImpersonatorIDFromContext(ctx context.Context) *uuid.UUID {
+	if val := ctx.Value(ImpersonatorIDKey); val != nil {
+		if impersonatorID, ok := val.(*uuid.UUID); ok {
+			return impersonatorID
+		}
+	}
+	return nil
+}
+
+// WithImpersonatorID adds the user ID of the impersonating admin to the context
+func WithImpersonatorID(ctx context.Context, impersonatorID *uuid.UUID) context.Context {
+	return context.WithValue(ctx, ImpersonatorIDKey, impersonatorID)
+}
+
+// GetActorIDFromContext retrieves the user ID of whoever is really making
+// the request: the impersonating admin when impersonating, else the user.
+// Audit entries record this actor.
+func GetActorIDFromContext(ctx context.Context) *uuid.UUID {
+	if impersonatorID := GetImpersonatorIDFromContext(ctx); impersonatorID != nil {
+		return impersonatorID
+	}
+	return GetUserIDFromContext(ctx)
+}
+
+// GetAPIKeyFromContext retrieves the API key a request authenticated with,
+// or nil for requests authenticated otherwise
+func GetAPIKeyFromContext(ctx context.Context) *models.APIKey {
+	if val := ctx.Value(APIKeyKey); val != nil {
+		if key, ok := val.(*models.APIKey); ok {
+			return key
+		}
+	}
+	return nil
+}
+
+// WithAPIKey adds the API key a request authenticated with to the context
+func WithAPIKey(ctx context.Context, key *models.APIKey) context.Context {
+	return context.WithValue(ctx, APIKeyKey, key)
+}
+
+// GetSessionFromContext retrieves the session a request authenticated with,
+// or nil for requests authenticated otherwise
+func GetSessionFromContext(ctx context.Context) *models.Session {
+	if val := ctx.Value(SessionKey); val != nil {
+		if session, ok := val.(*models.Session); ok {
+			return session
+		}
+	}
+	return nil
+}
+
+// WithSession adds the session a request authenticated with to the context
+func WithSession(ctx context.Context, session *models.Session) context.Context {
+	return context.WithValue(ctx, SessionKey, session)
+}
+
+// GetClientIPFromContext retrieves the client's address as resolved by the
+// ClientIP middleware, or nil when it did not run
+func GetClientIPFromContext(ctx context.Context) net.IP {
+	if val := ctx.Value(ClientIPKey); val != nil {
+		if ip, ok := val.(net.IP); ok {
+			return ip
+		}
+	}
+	return nil
+}
+
+// WithClientIP adds the client's resolved address to the context
+func WithClientIP(ctx context.Context, ip net.IP) context.Context {
+	return context.WithValue(ctx, ClientIPKey, ip)
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/idempotency"
+	"github.com/upb/llm-control-plane/backend/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client's idempotency key
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader marks responses replayed from an earlier request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// replayedHeaders are the response headers stored with an outcome
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// IdempotencyStore defines the interface for idempotency key tracking
+type IdempotencyStore interface {
+	Begin(ctx context.Context, orgID, appID uuid.UUID, key, fingerprint string) (*idempotency.Claim, *idempotency.Response, error)
+}
+
+// IdempotencyMiddleware answers retried requests with the outcome of the
+// first request carrying the same Idempotency-Key
+type IdempotencyMiddleware struct {
+	store  IdempotencyStore
+	logger *zap.Logger
+}
+
+// NewIdempotencyMiddleware creates a new IdempotencyMiddleware
+func NewIdempotencyMiddleware(store IdempotencyStore, logger *zap.Logger) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// Handle is a middleware that makes requests with an Idempotency-Key header
+// safe to retry. The first request runs; its outcome is stored per application
+// and key and replayed to later requests without running the handler again.
+// Duplicates arriving while it runs wait for it. Retryable outcomes (429 and
+// 5xx) are not stored, so that a retry runs the request again.
+// This should be called after auth and tenant extraction middleware
+func (m *IdempotencyMiddleware) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		orgID := GetOrgIDFromContext(ctx)
+		appID := GetAppIDFromContext(ctx)
+		if orgID == uuid.Nil || appID == uuid.Nil {
+			_ = utils.WriteUnauthorized(w, "Missing tenant information")
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			_ = utils.WriteBadRequest(w, "Failed to read request body", nil)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		claim, stored, err := m.store.Begin(ctx, orgID, appID, key, fingerprint(r, body))
+		switch {
+		case services.IsValidationError(err):
+			_ = utils.WriteBadRequest(w, err.Error(), nil)
+			return
+		case services.IsConflictError(err):
+			_ = utils.WriteError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request", nil)
+			return
+		case err != nil:
+			// The client went away while waiting for the original request
+			m.logger.Debug("stopped waiting for idempotent request",
+				zap.String("request_id", GetRequestIDFromContext(ctx)),
+				zap.Error(err))
+			return
+		}
+
+		if stored != nil {
+			m.logger.Debug("replaying idempotent response",
+				zap.String("request_id", GetRequestIDFromContext(ctx)),
+				zap.String("app_id", appID.String()))
+			replay(w, stored)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		completed := false
+		defer func() {
+			// Let waiting duplicates retry if the handler panicked
+			if !completed {
+				claim.Release()
+			}
+		}()
+
+		next.ServeHTTP(recorder, r)
+
+		completed = true
+		if recorder.status == http.StatusTooManyRequests || recorder.status >= http.StatusInternalServerError {
+			claim.Release()
+			return
+		}
+
+		header := make(http.Header)
+		for _, name := range replayedHeaders {
+			if value := w.Header().Get(name); value != "" {
+				header.Set(name, value)
+			}
+		}
+		claim.Complete(&idempotency.Response{
+			StatusCode: recorder.status,
+			Header:     header,
+			Body:       recorder.body.Bytes(),
+		})
+	})
+}
+
+// fingerprint identifies the request a key was first used with
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replay writes a stored response
+func replay(w http.ResponseWriter, stored *idempotency.Response) {
+	for name, values := range stored.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(stored.StatusCode)
+	_, _ = w.Write(stored.Body)
+}
+
+// responseRecorder passes a response through while keeping a copy of it
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap exposes the underlying writer, e.g. for flushing streamed responses
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/upb/llm-control-plane/backend/services/idempotency"
+	"go.uber.org/zap"
+)
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	orgID := uuid.New()
+	appID := uuid.New()
+
+	// The handler echoes the body with a sequence number and answers with status
+	newHandler := func(status int) (http.Handler, *atomic.Int32) {
+		calls := &atomic.Int32{}
+		store := idempotency.NewIdempotencyService(zap.NewNop(), idempotency.DefaultConfig())
+		m := NewIdempotencyMiddleware(store, zap.NewNop())
+		return m.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := calls.Add(1)
+			body, _ := io.ReadAll(r.Body)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"call":` + strconv.Itoa(int(n)) + `,"body":` + string(body) + `}`))
+		})), calls
+	}
+
+	send := func(handler http.Handler, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/inference/chat", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		ctx := WithAppID(WithOrgID(req.Context(), orgID), appID)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req.WithContext(ctx))
+		return w
+	}
+
+	t.Run("replays the first outcome", func(t *testing.T) {
+		handler, calls := newHandler(http.StatusOK)
+
+		first := send(handler, "key-1", `{"n":1}`)
+		second := send(handler, "key-1", `{"n":1}`)
+
+		assert.Equal(t, int32(1), calls.Load(), "the handler runs once")
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+		assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+		assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+	})
+
+	t.Run("rejects reuse with a different body", func(t *testing.T) {
+		handler, calls := newHandler(http.StatusOK)
+
+		send(handler, "key-1", `{"n":1}`)
+		w := send(handler, "key-1", `{"n":2}`)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("retryable outcomes are not stored", func(t *testing.T) {
+		handler, calls := newHandler(http.StatusBadGateway)
+
+		send(handler, "key-1", `{"n":1}`)
+		w := send(handler, "key-1", `{"n":1}`)
+
+		assert.Equal(t, int32(2), calls.Load(), "a retry runs the request again")
+		assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+	})
+
+	t.Run("requests without a key are not tracked", func(t *testing.T) {
+		handler, calls := newHandler(http.StatusOK)
+
+		send(handler, "", `{"n":1}`)
+		send(handler, "", `{"n":1}`)
+
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("missing tenant", func(t *testing.T) {
+		handler, calls := newHandler(http.StatusOK)
+
+		req := httptest.NewRequest(http.MethodPost, "/v1/inference/chat", strings.NewReader(`{}`))
+		req.Header.Set(IdempotencyKeyHeader, "key-1")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, int32(0), calls.Load())
+	})
+}
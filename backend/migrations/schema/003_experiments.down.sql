@@ -0,0 +1,7 @@
+-- Drop experiment columns and table
+DROP INDEX IF EXISTS idx_inference_requests_experiment;
+ALTER TABLE inference_requests DROP COLUMN IF EXISTS feedback_score;
+ALTER TABLE inference_requests DROP COLUMN IF EXISTS experiment_arm;
+ALTER TABLE inference_requests DROP COLUMN IF EXISTS experiment_id;
+
+DROP TABLE IF EXISTS experiments;
@@ -0,0 +1,25 @@
+-- Experiments table for A/B model testing
+CREATE TABLE experiments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    app_id UUID REFERENCES applications(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    target_model VARCHAR(100) NOT NULL,
+    arms JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('draft', 'running', 'stopped')),
+    started_at TIMESTAMP,
+    ended_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_experiments_org_id ON experiments(org_id);
+CREATE INDEX idx_experiments_app_id ON experiments(app_id);
+CREATE INDEX idx_experiments_running ON experiments(org_id, target_model) WHERE status = 'running';
+
+-- Experiment assignment and user feedback on inference requests
+ALTER TABLE inference_requests ADD COLUMN IF NOT EXISTS experiment_id UUID REFERENCES experiments(id) ON DELETE SET NULL;
+ALTER TABLE inference_requests ADD COLUMN IF NOT EXISTS experiment_arm VARCHAR(100);
+ALTER TABLE inference_requests ADD COLUMN IF NOT EXISTS feedback_score SMALLINT CHECK (feedback_score BETWEEN -1 AND 1);
+
+CREATE INDEX idx_inference_requests_experiment ON inference_requests(experiment_id, experiment_arm);
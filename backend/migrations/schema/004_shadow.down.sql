@@ -0,0 +1,9 @@
+-- Drop shadow responses and restore the original policy types
+DROP TABLE IF EXISTS shadow_responses;
+
+DELETE FROM policies WHERE policy_type = 'shadow';
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_policy_type_check;
+ALTER TABLE policies ADD CONSTRAINT policies_policy_type_check CHECK (policy_type IN (
+    'rate_limit', 'budget', 'routing', 'pii_detection',
+    'injection_guard', 'rag', 'retry', 'fallback', 'load_balance'
+));
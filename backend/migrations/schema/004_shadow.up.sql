@@ -0,0 +1,27 @@
+-- Allow shadow traffic policies
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_policy_type_check;
+ALTER TABLE policies ADD CONSTRAINT policies_policy_type_check CHECK (policy_type IN (
+    'rate_limit', 'budget', 'routing', 'pii_detection',
+    'injection_guard', 'rag', 'retry', 'fallback', 'load_balance', 'shadow'
+));
+
+-- Shadow responses stored alongside the primary inference request
+CREATE TABLE shadow_responses (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    inference_id UUID NOT NULL,
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    app_id UUID NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    provider VARCHAR(100) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    response TEXT,
+    prompt_tokens INTEGER NOT NULL DEFAULT 0,
+    completion_tokens INTEGER NOT NULL DEFAULT 0,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    cost DECIMAL(10, 6) NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_shadow_responses_inference_id ON shadow_responses(inference_id);
+CREATE INDEX idx_shadow_responses_org_id ON shadow_responses(org_id, created_at);
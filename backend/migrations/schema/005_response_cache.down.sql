@@ -0,0 +1,7 @@
+-- Restore policy types without response cache policies
+DELETE FROM policies WHERE policy_type = 'cache';
+ALTER TABLE policies DROP CONSTRAINT IF EXISTS policies_policy_type_check;
+ALTER TABLE policies ADD CONSTRAINT policies_policy_type_check CHECK (policy_type IN (
+    'rate_limit', 'budget', 'routing', 'pii_detection',
+    'injection_guard', 'rag', 'retry', 'fallback', 'load_balance', 'shadow'
+));
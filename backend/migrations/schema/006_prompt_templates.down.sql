@@ -0,0 +1,8 @@
+-- Drop template columns and tables
+DROP INDEX IF EXISTS idx_inference_requests_template;
+ALTER TABLE inference_requests DROP COLUMN IF EXISTS template_version;
+ALTER TABLE inference_requests DROP COLUMN IF EXISTS template_id;
+
+DROP TABLE IF EXISTS prompt_template_pins;
+DROP TABLE IF EXISTS prompt_template_versions;
+DROP TABLE IF EXISTS prompt_templates;
@@ -0,0 +1,40 @@
+-- Prompt templates with immutable numbered versions
+CREATE TABLE prompt_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    active_version INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(org_id, name)
+);
+
+CREATE TABLE prompt_template_versions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    template_id UUID NOT NULL REFERENCES prompt_templates(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL CHECK (version > 0),
+    messages JSONB NOT NULL,
+    variables JSONB NOT NULL DEFAULT '[]',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(template_id, version)
+);
+
+-- Per-application version pins
+CREATE TABLE prompt_template_pins (
+    template_id UUID NOT NULL REFERENCES prompt_templates(id) ON DELETE CASCADE,
+    app_id UUID NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (template_id, app_id)
+);
+
+CREATE INDEX idx_prompt_templates_org_id ON prompt_templates(org_id);
+
+-- Template version rendered into each inference request
+ALTER TABLE inference_requests ADD COLUMN IF NOT EXISTS template_id UUID REFERENCES prompt_templates(id) ON DELETE SET NULL;
+ALTER TABLE inference_requests ADD COLUMN IF NOT EXISTS template_version INTEGER;
+
+CREATE INDEX idx_inference_requests_template ON inference_requests(template_id, template_version);
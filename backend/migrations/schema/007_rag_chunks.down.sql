@@ -0,0 +1,3 @@
+-- Drop RAG chunks
+DROP INDEX IF EXISTS idx_rag_chunks_org_index;
+DROP TABLE IF EXISTS rag_chunks;
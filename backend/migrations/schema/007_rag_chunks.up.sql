@@ -0,0 +1,18 @@
+-- Tenant-scoped RAG index chunks with pgvector embeddings
+CREATE EXTENSION IF NOT EXISTS vector;
+
+CREATE TABLE rag_chunks (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    index_name VARCHAR(255) NOT NULL,
+    document_id VARCHAR(255) NOT NULL,
+    chunk_index INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    -- Dimensionless so indexes can use any embedding model; the model is fixed per index
+    embedding vector NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(org_id, index_name, document_id, chunk_index)
+);
+
+CREATE INDEX idx_rag_chunks_org_index ON rag_chunks(org_id, index_name);
@@ -0,0 +1,2 @@
+-- Drop RAG documents
+DROP TABLE IF EXISTS rag_documents;
@@ -0,0 +1,16 @@
+-- Ingested RAG documents; chunks are replaced whenever a document is re-ingested
+CREATE TABLE rag_documents (
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    index_name VARCHAR(255) NOT NULL,
+    document_id VARCHAR(255) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    content_hash VARCHAR(64) NOT NULL,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    chunk_count INTEGER NOT NULL DEFAULT 0,
+    embedding_model VARCHAR(100) NOT NULL,
+    tokens_used INTEGER NOT NULL DEFAULT 0,
+    cost DECIMAL(10, 6) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, index_name, document_id)
+);
@@ -0,0 +1,3 @@
+-- Drop conversation threads
+DROP TABLE IF EXISTS conversation_messages;
+DROP TABLE IF EXISTS conversations;
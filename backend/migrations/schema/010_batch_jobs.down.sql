@@ -0,0 +1,3 @@
+-- Drop batch inference jobs
+DROP TABLE IF EXISTS batch_items;
+DROP TABLE IF EXISTS batch_jobs;
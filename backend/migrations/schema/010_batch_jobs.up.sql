@@ -0,0 +1,38 @@
+-- Asynchronous batch inference jobs
+CREATE TABLE batch_jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    app_id UUID NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(50) NOT NULL,
+    total_items INTEGER NOT NULL,
+    completed_items INTEGER NOT NULL DEFAULT 0,
+    failed_items INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One row per input line; workers claim items with a lease so that items of
+-- a crashed worker are picked up again once the lease expires
+CREATE TABLE batch_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    job_id UUID NOT NULL REFERENCES batch_jobs(id) ON DELETE CASCADE,
+    line_number INTEGER NOT NULL,
+    custom_id VARCHAR(255) NOT NULL,
+    request JSONB NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response JSONB,
+    error_code VARCHAR(100),
+    error_message TEXT,
+    available_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    leased_until TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(job_id, line_number),
+    UNIQUE(job_id, custom_id)
+);
+
+CREATE INDEX idx_batch_jobs_org_id ON batch_jobs(org_id, created_at DESC);
+CREATE INDEX idx_batch_items_claimable ON batch_items(status, available_at);
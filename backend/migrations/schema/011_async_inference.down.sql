@@ -0,0 +1,7 @@
+-- Drop async inference columns
+ALTER TABLE applications DROP COLUMN IF EXISTS webhook_secret;
+ALTER TABLE applications DROP COLUMN IF EXISTS webhook_url;
+
+ALTER TABLE inference_requests DROP COLUMN IF EXISTS error_code;
+ALTER TABLE inference_requests DROP COLUMN IF EXISTS finish_reason;
+ALTER TABLE inference_requests DROP COLUMN IF EXISTS response;
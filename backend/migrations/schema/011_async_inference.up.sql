@@ -0,0 +1,8 @@
+-- Results of inference requests, read back by callers of async requests
+ALTER TABLE inference_requests ADD COLUMN IF NOT EXISTS response TEXT;
+ALTER TABLE inference_requests ADD COLUMN IF NOT EXISTS finish_reason VARCHAR(50);
+ALTER TABLE inference_requests ADD COLUMN IF NOT EXISTS error_code VARCHAR(100);
+
+-- Signed completion webhooks registered per application
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS webhook_url TEXT;
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS webhook_secret VARCHAR(255);
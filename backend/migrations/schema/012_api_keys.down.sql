@@ -0,0 +1,2 @@
+-- Drop application API keys
+DROP TABLE IF EXISTS api_keys;
@@ -0,0 +1,23 @@
+-- Application API keys; an application may hold several keys so that keys
+-- can be rotated with a grace period during which both are accepted
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    app_id UUID NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    key_hash VARCHAR(255) NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys(key_hash);
+CREATE INDEX idx_api_keys_app_id ON api_keys(app_id, created_at DESC);
+
+-- Existing application keys become the applications' first keys; their
+-- plaintext, and so their prefix, is unknown
+INSERT INTO api_keys (org_id, app_id, name, prefix, key_hash, created_at)
+SELECT org_id, id, 'Default', '', api_key_hash, created_at
+FROM applications;
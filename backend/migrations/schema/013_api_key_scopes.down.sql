@@ -0,0 +1,5 @@
+-- Drop API key scopes and usage
+DROP TABLE IF EXISTS api_key_usage;
+
+ALTER TABLE api_keys DROP COLUMN IF EXISTS last_used_at;
+ALTER TABLE api_keys DROP COLUMN IF EXISTS scopes;
@@ -0,0 +1,15 @@
+-- Restrictions of API keys: model, provider and client network allowlists,
+-- and rate limit and budget overrides (JSONB APIKeyScopes)
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP;
+
+-- Requests, tokens and cost per key and day
+CREATE TABLE api_key_usage (
+    key_id UUID NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    requests INTEGER NOT NULL DEFAULT 0,
+    failed_requests INTEGER NOT NULL DEFAULT 0,
+    tokens BIGINT NOT NULL DEFAULT 0,
+    cost DECIMAL(12, 6) NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, day)
+);
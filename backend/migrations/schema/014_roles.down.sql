@@ -0,0 +1,2 @@
+-- Drop role-to-permission mappings
+DROP TABLE IF EXISTS roles;
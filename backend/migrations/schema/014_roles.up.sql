@@ -0,0 +1,21 @@
+-- Role-to-permission mappings. Built-in roles have no organization; an
+-- organization's role of the same name overrides the built-in one
+CREATE TABLE roles (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    permissions JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_roles_builtin_name ON roles(name) WHERE org_id IS NULL;
+CREATE UNIQUE INDEX idx_roles_org_name ON roles(org_id, name) WHERE org_id IS NOT NULL;
+
+INSERT INTO roles (name, description, permissions) VALUES
+    ('admin', 'Full access', '["*"]'),
+    ('member', 'Manages policies, experiments, templates and documents',
+     '["policies:read", "policies:write", "experiments:read", "experiments:write", "templates:read", "templates:write", "rag:write", "api_keys:read", "roles:read"]'),
+    ('viewer', 'Read-only access',
+     '["policies:read", "experiments:read", "templates:read", "api_keys:read", "roles:read"]');
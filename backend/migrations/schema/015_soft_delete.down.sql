@@ -0,0 +1,6 @@
+-- Drop soft deletion of organizations and applications
+DROP INDEX IF EXISTS idx_applications_deleted_at;
+DROP INDEX IF EXISTS idx_organizations_deleted_at;
+
+ALTER TABLE applications DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE organizations DROP COLUMN IF EXISTS deleted_at;
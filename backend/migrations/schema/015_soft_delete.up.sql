@@ -0,0 +1,7 @@
+-- Deleted organizations and applications are kept, restorable, for a while;
+-- an organization's applications are deleted and restored with it
+ALTER TABLE organizations ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+ALTER TABLE applications ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_organizations_deleted_at ON organizations(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_applications_deleted_at ON applications(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Drop server-side sessions
+DROP TABLE IF EXISTS sessions;
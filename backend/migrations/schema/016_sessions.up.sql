@@ -0,0 +1,26 @@
+-- Server-side browser sessions. The session cookie carries an opaque token of
+-- which only the hash is stored, so sessions can be listed and revoked
+CREATE TABLE sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    token_hash VARCHAR(255) NOT NULL,
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    app_id UUID,
+    user_id UUID,
+    provider VARCHAR(100) NOT NULL,
+    issuer VARCHAR(500) NOT NULL DEFAULT '',
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    email_verified BOOLEAN NOT NULL DEFAULT false,
+    roles JSONB NOT NULL DEFAULT '[]',
+    refresh_token TEXT NOT NULL DEFAULT '',
+    identity_expires_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP,
+    user_agent VARCHAR(500) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX idx_sessions_token_hash ON sessions(token_hash);
+CREATE INDEX idx_sessions_subject ON sessions(org_id, subject, created_at DESC);
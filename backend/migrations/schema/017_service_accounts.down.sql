@@ -0,0 +1,2 @@
+-- Drop service accounts
+DROP TABLE IF EXISTS service_accounts;
@@ -0,0 +1,20 @@
+-- Machine identities of organizations. Service accounts obtain short-lived
+-- gateway-signed JWTs with their client ID and secret (OAuth2 client
+-- credentials); only the hash of the secret is stored
+CREATE TABLE service_accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    client_id VARCHAR(100) NOT NULL,
+    secret_hash VARCHAR(255) NOT NULL,
+    roles JSONB NOT NULL DEFAULT '[]',
+    scopes JSONB NOT NULL DEFAULT '[]',
+    created_by UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    disabled_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_service_accounts_client_id ON service_accounts(client_id);
+CREATE INDEX idx_service_accounts_org_id ON service_accounts(org_id, created_at DESC);
@@ -0,0 +1,2 @@
+-- Limit users to the built-in roles again; fails while users hold custom roles
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('admin', 'member', 'viewer'));
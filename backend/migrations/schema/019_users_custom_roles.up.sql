@@ -0,0 +1,3 @@
+-- Users may hold an organization's custom roles, so their role is no longer
+-- limited to the built-in ones (the check from 001_initial)
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
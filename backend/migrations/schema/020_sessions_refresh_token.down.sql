@@ -0,0 +1,2 @@
+-- Plaintext refresh tokens cannot be restored; encrypted ones are kept
+SELECT 1;
@@ -0,0 +1,3 @@
+-- Refresh tokens are now encrypted at rest (v1: prefix); drop those stored in
+-- plaintext by 016_sessions, so their sessions end with their identity
+UPDATE sessions SET refresh_token = '' WHERE refresh_token <> '' AND refresh_token NOT LIKE 'v1:%';
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey is a credential an application authenticates with. Only a hash of
+// the key is stored; the prefix identifies the key in listings.
+type APIKey struct {
+	ID         uuid.UUID       `json:"id" db:"id"`
+	OrgID      uuid.UUID       `json:"org_id" db:"org_id"`
+	AppID      uuid.UUID       `json:"app_id" db:"app_id"`
+	Name       string          `json:"name" db:"name"`
+	Prefix     string          `json:"prefix" db:"prefix"`
+	KeyHash    string          `json:"-" db:"key_hash"`    // Never expose in JSON
+	Scopes     json.RawMessage `json:"scopes" db:"scopes"` // JSONB APIKeyScopes
+	CreatedBy  *uuid.UUID      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	ExpiresAt  *time.Time      `json:"expires_at,omitempty" db:"expires_at"` // Set at issue or when the key is rotated out
+	RevokedAt  *time.Time      `json:"revoked_at,omitempty" db:"revoked_at"`
+	LastUsedAt *time.Time      `json:"last_used_at,omitempty" db:"last_used_at"`
+}
+
+// APIKeyScopes restricts what a key may be used for. Empty allowlists allow
+// everything; rate limit and budget overrides replace the application's
+// policies for requests made with the key, counted per key.
+type APIKeyScopes struct {
+	AllowedModels    []string         `json:"allowed_models,omitempty"` // Exact names, or prefixes ending in "*"
+	AllowedProviders []string         `json:"allowed_providers,omitempty"`
+	AllowedCIDRs     []string         `json:"allowed_cidrs,omitempty"` // Client networks, e.g. "10.0.0.0/8"
+	RateLimit        *RateLimitConfig `json:"rate_limit,omitempty"`
+	Budget           *BudgetConfig    `json:"budget,omitempty"`
+}
+
+// TableName returns the table name for the APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// IsActive reports whether the key may be used at the given time
+func (k *APIKey) IsActive(at time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || at.Before(*k.ExpiresAt)
+}
+
+// GetScopes unmarshals the key's scopes
+func (k *APIKey) GetScopes() (*APIKeyScopes, error) {
+	scopes := &APIKeyScopes{}
+	if len(k.Scopes) == 0 {
+		return scopes, nil
+	}
+	if err := json.Unmarshal(k.Scopes, scopes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal api key scopes: %w", err)
+	}
+	return scopes, nil
+}
+
+// SetScopes marshals the key's scopes
+func (k *APIKey) SetScopes(scopes *APIKeyScopes) error {
+	if scopes == nil {
+		scopes = &APIKeyScopes{}
+	}
+	data, err := json.Marshal(scopes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal api key scopes: %w", err)
+	}
+	k.Scopes = data
+	return nil
+}
+
+// Validate checks that the CIDRs parse and that limits are not negative
+func (s *APIKeyScopes) Validate() error {
+	for _, cidr := range s.AllowedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid CIDR: %s", cidr)
+		}
+	}
+	for _, model := range s.AllowedModels {
+		if strings.TrimSpace(model) == "" {
+			return fmt.Errorf("allowed models must not be empty")
+		}
+	}
+	for _, provider := range s.AllowedProviders {
+		if strings.TrimSpace(provider) == "" {
+			return fmt.Errorf("allowed providers must not be empty")
+		}
+	}
+	if rl := s.RateLimit; rl != nil {
+		if rl.RequestsPerMinute < 0 || rl.RequestsPerHour < 0 || rl.RequestsPerDay < 0 ||
+			rl.TokensPerMinute < 0 || rl.TokensPerHour < 0 || rl.TokensPerDay < 0 {
+			return fmt.Errorf("rate limits must not be negative")
+		}
+	}
+	if b := s.Budget; b != nil {
+		if b.MaxCostPerRequest < 0 || b.MaxDailyCost < 0 || b.MaxMonthlyCost < 0 {
+			return fmt.Errorf("budget limits must not be negative")
+		}
+	}
+	return nil
+}
+
+// AllowsModel reports whether the key may request the model
+func (s *APIKeyScopes) AllowsModel(model string) bool {
+	if len(s.AllowedModels) == 0 {
+		return true
+	}
+	for _, allowed := range s.AllowedModels {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok {
+			if strings.HasPrefix(strings.ToLower(model), strings.ToLower(prefix)) {
+				return true
+			}
+		} else if strings.EqualFold(allowed, model) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsProvider reports whether the key's requests may be served by the provider
+func (s *APIKeyScopes) AllowsProvider(provider string) bool {
+	if len(s.AllowedProviders) == 0 {
+		return true
+	}
+	for _, allowed := range s.AllowedProviders {
+		if strings.EqualFold(allowed, provider) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsIP reports whether the key may be used from the client address
+func (s *APIKeyScopes) AllowsIP(ip net.IP) bool {
+	if len(s.AllowedCIDRs) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	for _, cidr := range s.AllowedCIDRs {
+		if _, network, err := net.ParseCIDR(cidr); err == nil && network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewAPIKey creates a new APIKey instance without scopes
+func NewAPIKey(orgID, appID uuid.UUID, name, prefix, keyHash string, createdBy *uuid.UUID) *APIKey {
+	return &APIKey{
+		ID:        uuid.New(),
+		OrgID:     orgID,
+		AppID:     appID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		Scopes:    json.RawMessage(`{}`),
+		CreatedBy: createdBy,
+		CreatedAt: time.Now(),
+	}
+}
+
+// APIKeyUsage aggregates the requests made with a key on one day
+type APIKeyUsage struct {
+	KeyID          uuid.UUID `json:"key_id" db:"key_id"`
+	Day            time.Time `json:"day" db:"day"`
+	Requests       int       `json:"requests" db:"requests"`
+	FailedRequests int       `json:"failed_requests" db:"failed_requests"`
+	Tokens         int       `json:"tokens" db:"tokens"`
+	Cost           float64   `json:"cost" db:"cost"`
+}
+
+// TableName returns the table name for the APIKeyUsage model
+func (APIKeyUsage) TableName() string {
+	return "api_key_usage"
+}
@@ -8,12 +8,20 @@ import (
 
 // Application represents a client application that uses the LLM Control Plane
 type Application struct {
-	ID         uuid.UUID `json:"id" db:"id"`
-	OrgID      uuid.UUID `json:"org_id" db:"org_id"`
-	Name       string    `json:"name" db:"name"`
-	APIKeyHash string    `json:"-" db:"api_key_hash"` // Never expose in JSON
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	ID            uuid.UUID  `json:"id" db:"id"`
+	OrgID         uuid.UUID  `json:"org_id" db:"org_id"`
+	Name          string     `json:"name" db:"name"`
+	APIKeyHash    string     `json:"-" db:"api_key_hash"`                    // Never expose in JSON
+	WebhookURL    *string    `json:"webhook_url,omitempty" db:"webhook_url"` // Receives async inference results
+	WebhookSecret *string    `json:"-" db:"webhook_secret"`                  // Signs webhook deliveries; never expose in JSON
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty" db:"deleted_at"` // Soft-deleted; restorable for a while
+}
+
+// HasWebhook reports whether the application has a completion webhook
+func (a *Application) HasWebhook() bool {
+	return a.WebhookURL != nil && a.WebhookSecret != nil
 }
 
 // TableName returns the table name for the Application model
@@ -11,16 +11,44 @@ import (
 type AuditAction string
 
 const (
-	AuditActionInferenceRequest  AuditAction = "inference_request"
-	AuditActionInferenceResponse AuditAction = "inference_response"
-	AuditActionPolicyViolation   AuditAction = "policy_violation"
-	AuditActionPolicyCreated     AuditAction = "policy_created"
-	AuditActionPolicyUpdated     AuditAction = "policy_updated"
-	AuditActionPolicyDeleted     AuditAction = "policy_deleted"
-	AuditActionUserCreated       AuditAction = "user_created"
-	AuditActionUserUpdated       AuditAction = "user_updated"
-	AuditActionAppCreated        AuditAction = "app_created"
-	AuditActionAppUpdated        AuditAction = "app_updated"
+	AuditActionInferenceRequest      AuditAction = "inference_request"
+	AuditActionInferenceResponse     AuditAction = "inference_response"
+	AuditActionInferenceHedge        AuditAction = "inference_hedge"
+	AuditActionInferenceCacheHit     AuditAction = "inference_cache_hit"
+	AuditActionPolicyViolation       AuditAction = "policy_violation"
+	AuditActionPolicyCreated         AuditAction = "policy_created"
+	AuditActionPolicyUpdated         AuditAction = "policy_updated"
+	AuditActionPolicyDeleted         AuditAction = "policy_deleted"
+	AuditActionUserCreated           AuditAction = "user_created"
+	AuditActionUserUpdated           AuditAction = "user_updated"
+	AuditActionAppCreated            AuditAction = "app_created"
+	AuditActionAppUpdated            AuditAction = "app_updated"
+	AuditActionAppDeleted            AuditAction = "app_deleted"
+	AuditActionAppRestored           AuditAction = "app_restored"
+	AuditActionOrgCreated            AuditAction = "org_created"
+	AuditActionOrgUpdated            AuditAction = "org_updated"
+	AuditActionOrgDeleted            AuditAction = "org_deleted"
+	AuditActionOrgRestored           AuditAction = "org_restored"
+	AuditActionAPIKeyIssued          AuditAction = "api_key_issued"
+	AuditActionAPIKeyRotated         AuditAction = "api_key_rotated"
+	AuditActionAPIKeyUpdated         AuditAction = "api_key_updated"
+	AuditActionAPIKeyRevoked         AuditAction = "api_key_revoked"
+	AuditActionRoleCreated           AuditAction = "role_created"
+	AuditActionRoleUpdated           AuditAction = "role_updated"
+	AuditActionRoleDeleted           AuditAction = "role_deleted"
+	AuditActionServiceAccountCreated AuditAction = "service_account_created"
+	AuditActionServiceAccountUpdated AuditAction = "service_account_updated"
+	AuditActionServiceAccountRotated AuditAction = "service_account_secret_rotated"
+	AuditActionServiceAccountDeleted AuditAction = "service_account_deleted"
+	AuditActionUserDeactivated       AuditAction = "user_deactivated"
+	AuditActionUserReactivated       AuditAction = "user_reactivated"
+	AuditActionUserDeleted           AuditAction = "user_deleted"
+	AuditActionSCIMTokenRotated      AuditAction = "scim_token_rotated"
+	AuditActionSCIMTokenRevoked      AuditAction = "scim_token_revoked"
+	AuditActionSCIMGroupRolesUpdated AuditAction = "scim_group_roles_updated"
+	AuditActionTenantCrossed         AuditAction = "tenant_crossed"
+	AuditActionImpersonationStarted  AuditAction = "impersonation_started"
+	AuditActionImpersonatedRequest   AuditAction = "impersonated_request"
 )
 
 // AuditLog represents an audit trail entry
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BatchJobStatus represents the status of a batch inference job
+type BatchJobStatus string
+
+const (
+	BatchJobStatusPending   BatchJobStatus = "pending"
+	BatchJobStatusRunning   BatchJobStatus = "running"
+	BatchJobStatusCompleted BatchJobStatus = "completed" // Every item completed or failed
+	BatchJobStatusCancelled BatchJobStatus = "cancelled"
+)
+
+// BatchItemStatus represents the status of one request of a batch job
+type BatchItemStatus string
+
+const (
+	BatchItemStatusPending   BatchItemStatus = "pending"
+	BatchItemStatusRunning   BatchItemStatus = "running"
+	BatchItemStatusCompleted BatchItemStatus = "completed"
+	BatchItemStatusFailed    BatchItemStatus = "failed"
+)
+
+// BatchJob represents an asynchronous batch of chat completion requests
+type BatchJob struct {
+	ID             uuid.UUID      `json:"id" db:"id"`
+	OrgID          uuid.UUID      `json:"org_id" db:"org_id"`
+	AppID          uuid.UUID      `json:"app_id" db:"app_id"` // Rate limits and budget are applied to this application
+	UserID         *uuid.UUID     `json:"user_id,omitempty" db:"user_id"`
+	Status         BatchJobStatus `json:"status" db:"status"`
+	TotalItems     int            `json:"total_items" db:"total_items"`
+	CompletedItems int            `json:"completed_items" db:"completed_items"`
+	FailedItems    int            `json:"failed_items" db:"failed_items"`
+	CreatedAt      time.Time      `json:"created_at" db:"created_at"`
+	StartedAt      *time.Time     `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt    *time.Time     `json:"completed_at,omitempty" db:"completed_at"`
+	UpdatedAt      time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// TableName returns the table name for the BatchJob model
+func (BatchJob) TableName() string {
+	return "batch_jobs"
+}
+
+// NewBatchJob creates a new pending BatchJob
+func NewBatchJob(orgID, appID uuid.UUID, userID *uuid.UUID, totalItems int) *BatchJob {
+	now := time.Now()
+	return &BatchJob{
+		ID:         uuid.New(),
+		OrgID:      orgID,
+		AppID:      appID,
+		UserID:     userID,
+		Status:     BatchJobStatusPending,
+		TotalItems: totalItems,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+// IsFinished reports whether the job will process no more items
+func (j *BatchJob) IsFinished() bool {
+	return j.Status == BatchJobStatusCompleted || j.Status == BatchJobStatusCancelled
+}
+
+// Progress returns the fraction of items that completed or failed
+func (j *BatchJob) Progress() float64 {
+	if j.TotalItems == 0 {
+		return 1
+	}
+	return float64(j.CompletedItems+j.FailedItems) / float64(j.TotalItems)
+}
+
+// BatchItem represents one line of a batch job's input
+type BatchItem struct {
+	ID           uuid.UUID       `json:"id" db:"id"`
+	JobID        uuid.UUID       `json:"job_id" db:"job_id"`
+	LineNumber   int             `json:"line_number" db:"line_number"` // 1-based line in the uploaded file
+	CustomID     string          `json:"custom_id" db:"custom_id"`
+	Request      json.RawMessage `json:"request" db:"request"` // JSONB chat request body
+	Status       BatchItemStatus `json:"status" db:"status"`
+	Attempts     int             `json:"attempts" db:"attempts"`
+	Response     json.RawMessage `json:"response,omitempty" db:"response"` // JSONB completion response
+	ErrorCode    *string         `json:"error_code,omitempty" db:"error_code"`
+	ErrorMessage *string         `json:"error_message,omitempty" db:"error_message"`
+	AvailableAt  time.Time       `json:"available_at" db:"available_at"` // Earliest time the item may be (re)tried
+	LeasedUntil  *time.Time      `json:"leased_until,omitempty" db:"leased_until"`
+	UpdatedAt    time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// TableName returns the table name for the BatchItem model
+func (BatchItem) TableName() string {
+	return "batch_items"
+}
+
+// NewBatchItem creates a new pending BatchItem
+func NewBatchItem(jobID uuid.UUID, lineNumber int, customID string, request json.RawMessage) *BatchItem {
+	now := time.Now()
+	return &BatchItem{
+		ID:          uuid.New(),
+		JobID:       jobID,
+		LineNumber:  lineNumber,
+		CustomID:    customID,
+		Request:     request,
+		Status:      BatchItemStatusPending,
+		AvailableAt: now,
+		UpdatedAt:   now,
+	}
+}
+
+// MarkAsCompleted records the completion response of the item
+func (i *BatchItem) MarkAsCompleted(response json.RawMessage) {
+	i.Status = BatchItemStatusCompleted
+	i.Response = response
+	i.ErrorCode = nil
+	i.ErrorMessage = nil
+	i.LeasedUntil = nil
+	i.UpdatedAt = time.Now()
+}
+
+// MarkAsFailed records a permanent failure of the item
+func (i *BatchItem) MarkAsFailed(code, message string) {
+	i.Status = BatchItemStatusFailed
+	i.ErrorCode = &code
+	i.ErrorMessage = &message
+	i.LeasedUntil = nil
+	i.UpdatedAt = time.Now()
+}
+
+// Retry returns the item to the queue to be tried again at availableAt
+func (i *BatchItem) Retry(code, message string, availableAt time.Time) {
+	i.Status = BatchItemStatusPending
+	i.ErrorCode = &code
+	i.ErrorMessage = &message
+	i.AvailableAt = availableAt
+	i.LeasedUntil = nil
+	i.UpdatedAt = time.Now()
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Conversation represents a server-side chat thread owned by a user. Clients
+// post only new user messages; the gateway stores the turns and assembles the
+// history sent to the model.
+type Conversation struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	OrgID        uuid.UUID  `json:"org_id" db:"org_id"`
+	AppID        *uuid.UUID `json:"app_id,omitempty" db:"app_id"` // Application the conversation was started from
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	Title        string     `json:"title" db:"title"`
+	Model        string     `json:"model" db:"model"`                           // Default model for new turns
+	SystemPrompt string     `json:"system_prompt,omitempty" db:"system_prompt"` // Sent ahead of every turn
+	MessageCount int        `json:"message_count" db:"message_count"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// TableName returns the table name for the Conversation model
+func (Conversation) TableName() string {
+	return "conversations"
+}
+
+// NewConversation creates a new, empty Conversation
+func NewConversation(orgID uuid.UUID, appID *uuid.UUID, userID uuid.UUID, title, model, systemPrompt string) *Conversation {
+	now := time.Now()
+	return &Conversation{
+		ID:           uuid.New(),
+		OrgID:        orgID,
+		AppID:        appID,
+		UserID:       userID,
+		Title:        title,
+		Model:        model,
+		SystemPrompt: systemPrompt,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// IsOwnedBy reports whether the conversation belongs to the user in the organization
+func (c *Conversation) IsOwnedBy(orgID, userID uuid.UUID) bool {
+	return c.OrgID == orgID && c.UserID == userID
+}
+
+// ConversationMessage represents one stored turn of a conversation
+type ConversationMessage struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	ConversationID uuid.UUID  `json:"conversation_id" db:"conversation_id"`
+	Role           string     `json:"role" db:"role"` // user or assistant
+	Content        string     `json:"content" db:"content"`
+	Tokens         int        `json:"tokens" db:"tokens"`                       // Prompt tokens the turn costs when replayed
+	InferenceID    *uuid.UUID `json:"inference_id,omitempty" db:"inference_id"` // Inference request that produced an assistant turn
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TableName returns the table name for the ConversationMessage model
+func (ConversationMessage) TableName() string {
+	return "conversation_messages"
+}
+
+// NewConversationMessage creates a new ConversationMessage
+func NewConversationMessage(conversationID uuid.UUID, role, content string, tokens int) *ConversationMessage {
+	return &ConversationMessage{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		Role:           role,
+		Content:        content,
+		Tokens:         tokens,
+		CreatedAt:      time.Now(),
+	}
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExperimentStatus represents the lifecycle state of an experiment
+type ExperimentStatus string
+
+const (
+	ExperimentStatusDraft   ExperimentStatus = "draft"
+	ExperimentStatusRunning ExperimentStatus = "running"
+	ExperimentStatusStopped ExperimentStatus = "stopped"
+)
+
+// ExperimentArm represents a single variant of an experiment
+type ExperimentArm struct {
+	Name     string `json:"name"`
+	Model    string `json:"model"`
+	Provider string `json:"provider,omitempty"` // Optional - routed by model if empty
+	Weight   int    `json:"weight"`             // Percentage of traffic (0-100)
+}
+
+// Experiment represents an A/B test that splits an app's traffic across models
+type Experiment struct {
+	ID          uuid.UUID        `json:"id" db:"id"`
+	OrgID       uuid.UUID        `json:"org_id" db:"org_id"`
+	AppID       *uuid.UUID       `json:"app_id,omitempty" db:"app_id"` // Null if org-wide
+	Name        string           `json:"name" db:"name"`
+	TargetModel string           `json:"target_model" db:"target_model"` // Model requested by the caller
+	Arms        json.RawMessage  `json:"arms" db:"arms"`                 // JSONB list of ExperimentArm
+	Status      ExperimentStatus `json:"status" db:"status"`
+	StartedAt   *time.Time       `json:"started_at,omitempty" db:"started_at"`
+	EndedAt     *time.Time       `json:"ended_at,omitempty" db:"ended_at"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+// TableName returns the table name for the Experiment model
+func (Experiment) TableName() string {
+	return "experiments"
+}
+
+// NewExperiment creates a new Experiment instance in draft state
+func NewExperiment(orgID uuid.UUID, name, targetModel string, arms []ExperimentArm) (*Experiment, error) {
+	data, err := json.Marshal(arms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal experiment arms: %w", err)
+	}
+
+	now := time.Now()
+	return &Experiment{
+		ID:          uuid.New(),
+		OrgID:       orgID,
+		Name:        name,
+		TargetModel: targetModel,
+		Arms:        data,
+		Status:      ExperimentStatusDraft,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// GetArms unmarshals the experiment arms
+func (e *Experiment) GetArms() ([]ExperimentArm, error) {
+	var arms []ExperimentArm
+	if len(e.Arms) == 0 {
+		return arms, nil
+	}
+	if err := json.Unmarshal(e.Arms, &arms); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal experiment arms: %w", err)
+	}
+	return arms, nil
+}
+
+// ValidateArms checks that arms are named uniquely and weights sum to 100
+func ValidateArms(arms []ExperimentArm) error {
+	if len(arms) < 2 {
+		return fmt.Errorf("experiment requires at least 2 arms")
+	}
+
+	total := 0
+	seen := make(map[string]bool, len(arms))
+	for _, arm := range arms {
+		if arm.Name == "" {
+			return fmt.Errorf("arm name is required")
+		}
+		if seen[arm.Name] {
+			return fmt.Errorf("duplicate arm name: %s", arm.Name)
+		}
+		seen[arm.Name] = true
+		if arm.Model == "" {
+			return fmt.Errorf("arm %s: model is required", arm.Name)
+		}
+		if arm.Weight < 0 || arm.Weight > 100 {
+			return fmt.Errorf("arm %s: weight must be between 0 and 100", arm.Name)
+		}
+		total += arm.Weight
+	}
+
+	if total != 100 {
+		return fmt.Errorf("arm weights must sum to 100, got %d", total)
+	}
+	return nil
+}
+
+// Bucket returns a deterministic bucket in [0, 100) for a subject key.
+// The experiment ID is mixed in so the same user lands in independent
+// buckets across experiments.
+func (e *Experiment) Bucket(subjectKey string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(e.ID.String()))
+	_, _ = h.Write([]byte(":"))
+	_, _ = h.Write([]byte(subjectKey))
+	return int(h.Sum32() % 100)
+}
+
+// AssignArm deterministically assigns a subject key to an arm
+func (e *Experiment) AssignArm(subjectKey string) (*ExperimentArm, error) {
+	arms, err := e.GetArms()
+	if err != nil {
+		return nil, err
+	}
+	if len(arms) == 0 {
+		return nil, fmt.Errorf("experiment has no arms")
+	}
+
+	bucket := e.Bucket(subjectKey)
+	cumulative := 0
+	for i := range arms {
+		cumulative += arms[i].Weight
+		if bucket < cumulative {
+			return &arms[i], nil
+		}
+	}
+
+	// Weights below 100 leave the remainder on the last arm
+	return &arms[len(arms)-1], nil
+}
+
+// IsRunning returns true if the experiment is currently assigning traffic
+func (e *Experiment) IsRunning() bool {
+	return e.Status == ExperimentStatusRunning
+}
+
+// Start marks the experiment as running
+func (e *Experiment) Start() {
+	now := time.Now()
+	e.Status = ExperimentStatusRunning
+	e.StartedAt = &now
+	e.UpdatedAt = now
+}
+
+// Stop marks the experiment as stopped
+func (e *Experiment) Stop() {
+	now := time.Now()
+	e.Status = ExperimentStatusStopped
+	e.EndedAt = &now
+	e.UpdatedAt = now
+}
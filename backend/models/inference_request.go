@@ -51,6 +51,21 @@ type InferenceRequest struct {
 	PoliciesApplied  json.RawMessage `json:"policies_applied" db:"policies_applied"` // List of policy IDs
 	PolicyViolations json.RawMessage `json:"policy_violations,omitempty" db:"policy_violations"`
 	
+	// Experiment assignment
+	ExperimentID     *uuid.UUID      `json:"experiment_id,omitempty" db:"experiment_id"`
+	ExperimentArm    *string         `json:"experiment_arm,omitempty" db:"experiment_arm"`
+	FeedbackScore    *int            `json:"feedback_score,omitempty" db:"feedback_score"` // -1 (negative) to 1 (positive)
+	
+	// Prompt template rendered into the request
+	TemplateID       *uuid.UUID      `json:"template_id,omitempty" db:"template_id"`
+	TemplateVersion  *int            `json:"template_version,omitempty" db:"template_version"`
+	
+	// RAG documents injected as context; recorded in the audit trail only
+	RetrievedDocuments []string      `json:"retrieved_documents,omitempty" db:"-"`
+	
+	// API key the request was made with; recorded in the audit trail and key usage only
+	APIKeyID         *uuid.UUID      `json:"api_key_id,omitempty" db:"-"`
+	
 	// Timestamps
 	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
 	StartedAt        *time.Time      `json:"started_at,omitempty" db:"started_at"`
@@ -143,3 +158,15 @@ func (ir *InferenceRequest) SetRequestMetadata(ipAddress, userAgent string) {
 	ir.IPAddress = ipAddress
 	ir.UserAgent = userAgent
 }
+
+// SetExperiment records the experiment arm the request was assigned to
+func (ir *InferenceRequest) SetExperiment(experimentID uuid.UUID, arm string) {
+	ir.ExperimentID = &experimentID
+	ir.ExperimentArm = &arm
+}
+
+// SetTemplate records the prompt template version rendered into the request
+func (ir *InferenceRequest) SetTemplate(templateID uuid.UUID, version int) {
+	ir.TemplateID = &templateID
+	ir.TemplateVersion = &version
+}
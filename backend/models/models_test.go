@@ -2,6 +2,8 @@ package models
 
 import (
 	"encoding/json"
+	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -380,3 +382,299 @@ func TestInferenceRequest_SetRequestMetadata(t *testing.T) {
 	assert.Equal(t, ipAddress, req.IPAddress)
 	assert.Equal(t, userAgent, req.UserAgent)
 }
+
+// Experiment tests
+func TestNewExperiment(t *testing.T) {
+	orgID := uuid.New()
+	arms := []ExperimentArm{
+		{Name: "control", Model: "gpt-4", Weight: 90},
+		{Name: "cheap", Model: "gpt-4o-mini", Weight: 10},
+	}
+
+	exp, err := NewExperiment(orgID, "cheaper model", "gpt-4", arms)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, uuid.Nil, exp.ID)
+	assert.Equal(t, orgID, exp.OrgID)
+	assert.Equal(t, ExperimentStatusDraft, exp.Status)
+	assert.False(t, exp.IsRunning())
+
+	decoded, err := exp.GetArms()
+	require.NoError(t, err)
+	assert.Equal(t, arms, decoded)
+}
+
+func TestValidateArms(t *testing.T) {
+	tests := []struct {
+		name    string
+		arms    []ExperimentArm
+		wantErr bool
+	}{
+		{
+			name: "valid split",
+			arms: []ExperimentArm{
+				{Name: "a", Model: "gpt-4", Weight: 50},
+				{Name: "b", Model: "gpt-4o", Weight: 50},
+			},
+		},
+		{
+			name:    "single arm",
+			arms:    []ExperimentArm{{Name: "a", Model: "gpt-4", Weight: 100}},
+			wantErr: true,
+		},
+		{
+			name: "weights do not sum to 100",
+			arms: []ExperimentArm{
+				{Name: "a", Model: "gpt-4", Weight: 50},
+				{Name: "b", Model: "gpt-4o", Weight: 40},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate names",
+			arms: []ExperimentArm{
+				{Name: "a", Model: "gpt-4", Weight: 50},
+				{Name: "a", Model: "gpt-4o", Weight: 50},
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing model",
+			arms: []ExperimentArm{
+				{Name: "a", Model: "gpt-4", Weight: 50},
+				{Name: "b", Weight: 50},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateArms(tt.arms)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestExperiment_AssignArm(t *testing.T) {
+	exp, err := NewExperiment(uuid.New(), "split", "gpt-4", []ExperimentArm{
+		{Name: "control", Model: "gpt-4", Weight: 90},
+		{Name: "cheap", Model: "gpt-4o-mini", Weight: 10},
+	})
+	require.NoError(t, err)
+
+	t.Run("deterministic per subject", func(t *testing.T) {
+		first, err := exp.AssignArm("user-1")
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			again, err := exp.AssignArm("user-1")
+			require.NoError(t, err)
+			assert.Equal(t, first.Name, again.Name)
+		}
+	})
+
+	t.Run("respects weights", func(t *testing.T) {
+		counts := map[string]int{}
+		for i := 0; i < 10000; i++ {
+			arm, err := exp.AssignArm(uuid.New().String())
+			require.NoError(t, err)
+			counts[arm.Name]++
+		}
+		assert.InDelta(t, 1000, counts["cheap"], 200)
+		assert.InDelta(t, 9000, counts["control"], 200)
+	})
+
+	t.Run("bucket in range", func(t *testing.T) {
+		bucket := exp.Bucket("user-2")
+		assert.GreaterOrEqual(t, bucket, 0)
+		assert.Less(t, bucket, 100)
+	})
+}
+
+func TestExperiment_StartStop(t *testing.T) {
+	exp, err := NewExperiment(uuid.New(), "split", "gpt-4", nil)
+	require.NoError(t, err)
+
+	exp.Start()
+	assert.True(t, exp.IsRunning())
+	assert.NotNil(t, exp.StartedAt)
+
+	exp.Stop()
+	assert.False(t, exp.IsRunning())
+	assert.Equal(t, ExperimentStatusStopped, exp.Status)
+	assert.NotNil(t, exp.EndedAt)
+}
+
+func TestInferenceRequest_SetExperiment(t *testing.T) {
+	req := NewInferenceRequest(uuid.New(), uuid.New(), "openai", "gpt-4", "hi")
+	experimentID := uuid.New()
+
+	req.SetExperiment(experimentID, "cheap")
+
+	require.NotNil(t, req.ExperimentID)
+	assert.Equal(t, experimentID, *req.ExperimentID)
+	require.NotNil(t, req.ExperimentArm)
+	assert.Equal(t, "cheap", *req.ExperimentArm)
+}
+
+func TestPromptTemplateVersion_Render(t *testing.T) {
+	tone := "friendly"
+	version, err := NewPromptTemplateVersion(uuid.New(), []TemplateMessage{
+		{Role: "system", Content: "Be {{ tone }} about {{product}}."},
+		{Role: "user", Content: "Tell me about {{product}}"},
+	}, []TemplateVariable{{Name: "tone", Default: &tone}})
+	require.NoError(t, err)
+
+	variables, err := version.GetVariables()
+	require.NoError(t, err)
+	require.Len(t, variables, 2)
+	assert.Equal(t, "product", variables[1].Name, "undeclared placeholders become required variables")
+	assert.Nil(t, variables[1].Default)
+
+	t.Run("defaults and values", func(t *testing.T) {
+		messages, err := version.Render(map[string]string{"product": "Widget"})
+		require.NoError(t, err)
+		assert.Equal(t, "Be friendly about Widget.", messages[0].Content)
+		assert.Equal(t, "Tell me about Widget", messages[1].Content)
+	})
+
+	t.Run("missing required", func(t *testing.T) {
+		_, err := version.Render(nil)
+		assert.ErrorContains(t, err, "missing template variables: product")
+	})
+
+	t.Run("unknown variable", func(t *testing.T) {
+		_, err := version.Render(map[string]string{"product": "Widget", "lang": "de"})
+		assert.ErrorContains(t, err, "unknown template variables: lang")
+	})
+}
+
+func TestNewPromptTemplateVersion_Invalid(t *testing.T) {
+	_, err := NewPromptTemplateVersion(uuid.New(), nil, nil)
+	assert.Error(t, err)
+
+	_, err = NewPromptTemplateVersion(uuid.New(), []TemplateMessage{{Role: "tool", Content: "x"}}, nil)
+	assert.Error(t, err)
+
+	_, err = NewPromptTemplateVersion(uuid.New(), []TemplateMessage{{Role: "user", Content: "x"}},
+		[]TemplateVariable{{Name: "a"}, {Name: "a"}})
+	assert.Error(t, err)
+}
+
+func TestParseTemplateRef(t *testing.T) {
+	ref, err := ParseTemplateRef("support@3")
+	require.NoError(t, err)
+	assert.Equal(t, TemplateRef{Template: "support", Version: 3}, ref)
+
+	ref, err = ParseTemplateRef("support")
+	require.NoError(t, err)
+	assert.Equal(t, TemplateRef{Template: "support"}, ref)
+
+	for _, invalid := range []string{"", "@2", "support@", "support@0", "support@v2"} {
+		_, err := ParseTemplateRef(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestAPIKey_IsActive(t *testing.T) {
+	now := time.Now()
+	key := NewAPIKey(uuid.New(), uuid.New(), "server", "lcp_0123abcd", "hash", nil)
+	assert.True(t, key.IsActive(now))
+
+	expiresAt := now.Add(time.Hour)
+	key.ExpiresAt = &expiresAt
+	assert.True(t, key.IsActive(now))
+	assert.False(t, key.IsActive(expiresAt))
+
+	key.ExpiresAt = nil
+	key.RevokedAt = &now
+	assert.False(t, key.IsActive(now))
+}
+
+func TestAPIKey_JSONMarshaling(t *testing.T) {
+	key := NewAPIKey(uuid.New(), uuid.New(), "server", "lcp_0123abcd", "secret-hash", nil)
+
+	data, err := json.Marshal(key)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-hash")
+	assert.Contains(t, string(data), "lcp_0123abcd")
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	key := NewAPIKey(uuid.New(), uuid.New(), "ci", "lcp_0123abcd", "hash", nil)
+	scopes, err := key.GetScopes()
+	require.NoError(t, err)
+	assert.True(t, scopes.AllowsModel("gpt-4o"))
+	assert.True(t, scopes.AllowsProvider("openai"))
+	assert.True(t, scopes.AllowsIP(net.ParseIP("192.0.2.1")))
+
+	require.NoError(t, key.SetScopes(&APIKeyScopes{
+		AllowedModels:    []string{"gpt-4o*", "claude-3-haiku"},
+		AllowedProviders: []string{"OpenAI"},
+		AllowedCIDRs:     []string{"10.0.0.0/8"},
+	}))
+	scopes, err = key.GetScopes()
+	require.NoError(t, err)
+
+	assert.True(t, scopes.AllowsModel("gpt-4o-mini"))
+	assert.True(t, scopes.AllowsModel("Claude-3-Haiku"))
+	assert.False(t, scopes.AllowsModel("gpt-3.5-turbo"))
+	assert.True(t, scopes.AllowsProvider("openai"))
+	assert.False(t, scopes.AllowsProvider("anthropic"))
+	assert.True(t, scopes.AllowsIP(net.ParseIP("10.1.2.3")))
+	assert.False(t, scopes.AllowsIP(net.ParseIP("192.0.2.1")))
+	assert.False(t, scopes.AllowsIP(nil))
+}
+
+func TestAPIKeyScopes_Validate(t *testing.T) {
+	assert.NoError(t, (&APIKeyScopes{AllowedCIDRs: []string{"10.0.0.0/8", "2001:db8::/32"}}).Validate())
+	assert.Error(t, (&APIKeyScopes{AllowedCIDRs: []string{"10.0.0.1"}}).Validate())
+	assert.Error(t, (&APIKeyScopes{AllowedModels: []string{" "}}).Validate())
+	assert.Error(t, (&APIKeyScopes{RateLimit: &RateLimitConfig{RequestsPerMinute: -1}}).Validate())
+	assert.Error(t, (&APIKeyScopes{Budget: &BudgetConfig{MaxDailyCost: -1}}).Validate())
+}
+
+func TestRole_Permissions(t *testing.T) {
+	orgID := uuid.New()
+	role, err := NewRole(&orgID, "auditor", "Reads the audit log", []Permission{PermissionAuditRead, "policies:*"})
+	require.NoError(t, err)
+	assert.False(t, role.IsBuiltIn())
+
+	permissions, err := role.GetPermissions()
+	require.NoError(t, err)
+	assert.True(t, Grants(permissions, PermissionAuditRead))
+	assert.True(t, Grants(permissions, PermissionPoliciesWrite))
+	assert.False(t, Grants(permissions, PermissionTemplatesRead))
+	assert.True(t, Grants([]Permission{PermissionAll}, PermissionBudgetsOverride))
+
+	_, err = NewRole(&orgID, "auditor", "", []Permission{"audit:delete"})
+	assert.Error(t, err)
+	_, err = NewRole(&orgID, "auditor", "", []Permission{"unknown:*"})
+	assert.Error(t, err)
+	_, err = NewRole(&orgID, "Auditor", "", nil)
+	assert.Error(t, err)
+	_, err = NewRole(&orgID, "superadmin", "", nil)
+	assert.Error(t, err, "superadmin is reserved for platform operators")
+}
+
+func TestBuiltInRolesGrant(t *testing.T) {
+	assert.True(t, BuiltInRolesGrant([]string{"admin"}, PermissionRolesWrite))
+	assert.True(t, BuiltInRolesGrant([]string{"viewer", "member"}, PermissionPoliciesWrite))
+	assert.False(t, BuiltInRolesGrant([]string{"viewer"}, PermissionPoliciesWrite))
+	assert.False(t, BuiltInRolesGrant([]string{"auditor"}, PermissionAuditRead))
+	assert.False(t, BuiltInRolesGrant(nil, PermissionPoliciesRead))
+	assert.True(t, BuiltInRolesGrant([]string{"superadmin"}, PermissionOrganizationsWrite))
+}
+
+func TestValidateSlug(t *testing.T) {
+	for _, slug := range []string{"acme", "acme-corp", "team-42"} {
+		assert.NoError(t, ValidateSlug(slug), slug)
+	}
+	for _, slug := range []string{"", "ab", "Acme", "acme--corp", "-acme", "acme-", "acme_corp", strings.Repeat("a", 64)} {
+		assert.Error(t, ValidateSlug(slug), slug)
+	}
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
@@ -8,11 +10,26 @@ import (
 
 // Organization represents a tenant in the multi-tenant system
 type Organization struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	Name      string    `json:"name" db:"name"`
-	Slug      string    `json:"slug" db:"slug"` // URL-friendly identifier
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	Slug      string     `json:"slug" db:"slug"` // URL-friendly identifier
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"` // Soft-deleted; restorable for a while
+}
+
+// slugPattern matches slugs: lowercase words of letters and digits joined by hyphens
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ValidateSlug checks that a slug is URL-friendly and 3 to 63 characters long
+func ValidateSlug(slug string) error {
+	if len(slug) < 3 || len(slug) > 63 {
+		return fmt.Errorf("slug must be between 3 and 63 characters")
+	}
+	if !slugPattern.MatchString(slug) {
+		return fmt.Errorf("invalid slug %q: use lowercase letters and digits separated by single hyphens", slug)
+	}
+	return nil
 }
 
 // TableName returns the table name for the Organization model
@@ -20,6 +20,8 @@ const (
 	PolicyTypeRetry       PolicyType = "retry"
 	PolicyTypeFallback    PolicyType = "fallback"
 	PolicyTypeLoadBalance PolicyType = "load_balance"
+	PolicyTypeShadow      PolicyType = "shadow"
+	PolicyTypeCache       PolicyType = "cache"
 )
 
 // Policy represents a policy configuration for controlling LLM behavior
@@ -28,6 +30,7 @@ type Policy struct {
 	OrgID      uuid.UUID       `json:"org_id" db:"org_id"`
 	AppID      *uuid.UUID      `json:"app_id,omitempty" db:"app_id"`   // Null if org-wide
 	UserID     *uuid.UUID      `json:"user_id,omitempty" db:"user_id"` // Null if not user-specific
+	APIKeyID   *uuid.UUID      `json:"api_key_id,omitempty" db:"-"`    // Set on policies derived from an API key's overrides, which are not stored
 	PolicyType PolicyType      `json:"policy_type" db:"policy_type"`
 	Config     json.RawMessage `json:"config" db:"config"` // JSONB configuration
 	Priority   int             `json:"priority" db:"priority"`
@@ -79,6 +82,16 @@ type RoutingConfig struct {
 	PrimaryProvider  string   `json:"primary_provider"`
 	FallbackProviders []string `json:"fallback_providers"`
 	Strategy         string   `json:"strategy"` // least_latency, cost_optimized, etc.
+	Hedge            *HedgeConfig `json:"hedge,omitempty"`
+	ContextStrategy  string   `json:"context_strategy,omitempty"` // error, drop_oldest, middle_out
+}
+
+// HedgeConfig represents request hedging configuration for latency-critical apps
+type HedgeConfig struct {
+	Enabled    bool    `json:"enabled"`
+	Percentile float64 `json:"percentile"`   // Hedge after this percentile of primary latency (e.g. 95)
+	MinDelayMs int     `json:"min_delay_ms"` // Lower bound, also used until latency is observed
+	Provider   string  `json:"provider"`     // Hedge target (defaults to first fallback provider)
 }
 
 // PIIConfig represents PII detection policy configuration
@@ -102,3 +115,23 @@ type RAGConfig struct {
 	TopK           int    `json:"top_k"`
 	ScoreThreshold float64 `json:"score_threshold"`
 }
+
+// ShadowConfig represents shadow traffic mirroring policy configuration
+type ShadowConfig struct {
+	Enabled        bool    `json:"enabled"`
+	Provider       string  `json:"provider"`         // Shadow provider (optional if model is set)
+	Model          string  `json:"model"`            // Shadow model (defaults to the primary model)
+	SampleRate     float64 `json:"sample_rate"`      // Fraction of requests to mirror (0-1)
+	MaxDailyCost   float64 `json:"max_daily_cost"`   // Shadow budget scope limits
+	MaxMonthlyCost float64 `json:"max_monthly_cost"`
+	TimeoutSeconds int     `json:"timeout_seconds"`
+}
+
+// CacheConfig represents response cache policy configuration
+type CacheConfig struct {
+	Enabled             bool    `json:"enabled"`
+	TTLSeconds          int     `json:"ttl_seconds"`          // Entry lifetime (defaults to one hour)
+	Semantic            bool    `json:"semantic"`             // Also match near-identical prompts by embedding
+	SimilarityThreshold float64 `json:"similarity_threshold"` // Minimum cosine similarity for a semantic hit (0-1)
+	Shared              bool    `json:"shared"`               // Serve entries to every caller of the application, not only the user or API key that created them
+}
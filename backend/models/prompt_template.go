@@ -0,0 +1,268 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// templateVariablePattern matches {{variable}} placeholders in template content
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// PromptTemplate represents a named prompt template owned by an organization.
+// Content lives in immutable, numbered versions.
+type PromptTemplate struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	OrgID         uuid.UUID `json:"org_id" db:"org_id"`
+	Name          string    `json:"name" db:"name"`
+	Description   string    `json:"description,omitempty" db:"description"`
+	ActiveVersion int       `json:"active_version" db:"active_version"` // Version used when a caller does not name one
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TableName returns the table name for the PromptTemplate model
+func (PromptTemplate) TableName() string {
+	return "prompt_templates"
+}
+
+// NewPromptTemplate creates a new PromptTemplate instance without versions
+func NewPromptTemplate(orgID uuid.UUID, name, description string) *PromptTemplate {
+	now := time.Now()
+	return &PromptTemplate{
+		ID:          uuid.New(),
+		OrgID:       orgID,
+		Name:        name,
+		Description: description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// SetActiveVersion makes a version the default for callers that do not pin one
+func (t *PromptTemplate) SetActiveVersion(version int) {
+	t.ActiveVersion = version
+	t.UpdatedAt = time.Now()
+}
+
+// TemplateMessage represents one message of a prompt template
+type TemplateMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// TemplateVariable declares a variable used by a template version
+type TemplateVariable struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Default     *string `json:"default,omitempty"` // Variables without a default are required
+}
+
+// PromptTemplateVersion represents an immutable version of a prompt template
+type PromptTemplateVersion struct {
+	ID         uuid.UUID       `json:"id" db:"id"`
+	TemplateID uuid.UUID       `json:"template_id" db:"template_id"`
+	Version    int             `json:"version" db:"version"`
+	Messages   json.RawMessage `json:"messages" db:"messages"`   // JSONB list of TemplateMessage
+	Variables  json.RawMessage `json:"variables" db:"variables"` // JSONB list of TemplateVariable
+	CreatedBy  *uuid.UUID      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// TableName returns the table name for the PromptTemplateVersion model
+func (PromptTemplateVersion) TableName() string {
+	return "prompt_template_versions"
+}
+
+// NewPromptTemplateVersion creates a new version; the version number is assigned
+// when it is stored. Variables referenced in the messages but not declared are
+// added as required variables.
+func NewPromptTemplateVersion(templateID uuid.UUID, messages []TemplateMessage, variables []TemplateVariable) (*PromptTemplateVersion, error) {
+	if err := ValidateTemplateMessages(messages); err != nil {
+		return nil, err
+	}
+
+	declared := make(map[string]bool, len(variables))
+	for _, v := range variables {
+		if v.Name == "" {
+			return nil, fmt.Errorf("variable name is required")
+		}
+		if declared[v.Name] {
+			return nil, fmt.Errorf("duplicate variable: %s", v.Name)
+		}
+		declared[v.Name] = true
+	}
+	for _, name := range TemplatePlaceholders(messages) {
+		if !declared[name] {
+			variables = append(variables, TemplateVariable{Name: name})
+		}
+	}
+
+	messagesJSON, err := json.Marshal(messages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal template messages: %w", err)
+	}
+	variablesJSON, err := json.Marshal(variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal template variables: %w", err)
+	}
+
+	return &PromptTemplateVersion{
+		ID:         uuid.New(),
+		TemplateID: templateID,
+		Messages:   messagesJSON,
+		Variables:  variablesJSON,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// GetMessages unmarshals the template messages
+func (v *PromptTemplateVersion) GetMessages() ([]TemplateMessage, error) {
+	var messages []TemplateMessage
+	if len(v.Messages) == 0 {
+		return messages, nil
+	}
+	if err := json.Unmarshal(v.Messages, &messages); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal template messages: %w", err)
+	}
+	return messages, nil
+}
+
+// GetVariables unmarshals the template variables
+func (v *PromptTemplateVersion) GetVariables() ([]TemplateVariable, error) {
+	var variables []TemplateVariable
+	if len(v.Variables) == 0 {
+		return variables, nil
+	}
+	if err := json.Unmarshal(v.Variables, &variables); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal template variables: %w", err)
+	}
+	return variables, nil
+}
+
+// Render substitutes variables into the template messages. Missing required
+// variables and variables the version does not declare are errors.
+func (v *PromptTemplateVersion) Render(values map[string]string) ([]TemplateMessage, error) {
+	messages, err := v.GetMessages()
+	if err != nil {
+		return nil, err
+	}
+	variables, err := v.GetVariables()
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]string, len(variables))
+	var missing []string
+	for _, variable := range variables {
+		if value, ok := values[variable.Name]; ok {
+			resolved[variable.Name] = value
+		} else if variable.Default != nil {
+			resolved[variable.Name] = *variable.Default
+		} else {
+			missing = append(missing, variable.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing template variables: %s", strings.Join(missing, ", "))
+	}
+
+	var unknown []string
+	for name := range values {
+		if _, ok := resolved[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("unknown template variables: %s", strings.Join(unknown, ", "))
+	}
+
+	rendered := make([]TemplateMessage, len(messages))
+	for i, msg := range messages {
+		rendered[i] = TemplateMessage{
+			Role: msg.Role,
+			Content: templateVariablePattern.ReplaceAllStringFunc(msg.Content, func(placeholder string) string {
+				name := templateVariablePattern.FindStringSubmatch(placeholder)[1]
+				return resolved[name]
+			}),
+		}
+	}
+	return rendered, nil
+}
+
+// PromptTemplatePin pins an application to a specific template version
+type PromptTemplatePin struct {
+	TemplateID uuid.UUID `json:"template_id" db:"template_id"`
+	AppID      uuid.UUID `json:"app_id" db:"app_id"`
+	Version    int       `json:"version" db:"version"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TableName returns the table name for the PromptTemplatePin model
+func (PromptTemplatePin) TableName() string {
+	return "prompt_template_pins"
+}
+
+// ValidateTemplateMessages checks that a template has messages with known roles
+func ValidateTemplateMessages(messages []TemplateMessage) error {
+	if len(messages) == 0 {
+		return fmt.Errorf("template requires at least one message")
+	}
+	for i, msg := range messages {
+		switch msg.Role {
+		case "system", "user", "assistant":
+		default:
+			return fmt.Errorf("message %d: invalid role %q", i, msg.Role)
+		}
+		if msg.Content == "" {
+			return fmt.Errorf("message %d: content is required", i)
+		}
+	}
+	return nil
+}
+
+// TemplatePlaceholders returns the distinct variable names used in the messages, in order of appearance
+func TemplatePlaceholders(messages []TemplateMessage) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, msg := range messages {
+		for _, match := range templateVariablePattern.FindAllStringSubmatch(msg.Content, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				names = append(names, match[1])
+			}
+		}
+	}
+	return names
+}
+
+// TemplateRef identifies a template and optionally a version, written as "template@version"
+type TemplateRef struct {
+	Template string // Template ID or name
+	Version  int    // Zero selects the app's pinned version or the template's active version
+}
+
+// ParseTemplateRef parses a "template@version" reference; the version is optional
+func ParseTemplateRef(ref string) (TemplateRef, error) {
+	name, version, hasVersion := strings.Cut(strings.TrimSpace(ref), "@")
+	if name == "" {
+		return TemplateRef{}, fmt.Errorf("template reference is empty")
+	}
+	if !hasVersion {
+		return TemplateRef{Template: name}, nil
+	}
+
+	n, err := strconv.Atoi(version)
+	if err != nil || n <= 0 {
+		return TemplateRef{}, fmt.Errorf("invalid template version %q", version)
+	}
+	return TemplateRef{Template: name, Version: n}, nil
+}
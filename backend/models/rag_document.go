@@ -0,0 +1,48 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RAGDocument represents a document ingested into an organization's RAG index.
+// Its content lives in RAGChunk rows.
+type RAGDocument struct {
+	OrgID          uuid.UUID       `json:"org_id" db:"org_id"`
+	IndexName      string          `json:"index_name" db:"index_name"`
+	DocumentID     string          `json:"document_id" db:"document_id"` // Caller-assigned, unique within the index
+	ContentType    string          `json:"content_type" db:"content_type"`
+	ContentHash    string          `json:"content_hash" db:"content_hash"` // Detects unchanged re-ingestion
+	Metadata       json.RawMessage `json:"metadata,omitempty" db:"metadata"`
+	ChunkCount     int             `json:"chunk_count" db:"chunk_count"`
+	EmbeddingModel string          `json:"embedding_model" db:"embedding_model"`
+	TokensUsed     int             `json:"tokens_used" db:"tokens_used"`
+	Cost           float64         `json:"cost" db:"cost"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// TableName returns the table name for the RAGDocument model
+func (RAGDocument) TableName() string {
+	return "rag_documents"
+}
+
+// RAGChunk represents an embedded chunk of a RAG document
+type RAGChunk struct {
+	ID         uuid.UUID       `json:"id" db:"id"`
+	OrgID      uuid.UUID       `json:"org_id" db:"org_id"`
+	IndexName  string          `json:"index_name" db:"index_name"`
+	DocumentID string          `json:"document_id" db:"document_id"`
+	ChunkIndex int             `json:"chunk_index" db:"chunk_index"`
+	Content    string          `json:"content" db:"content"`
+	Metadata   json.RawMessage `json:"metadata,omitempty" db:"metadata"`
+	Embedding  []float64       `json:"-" db:"embedding"` // pgvector column
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// TableName returns the table name for the RAGChunk model
+func (RAGChunk) TableName() string {
+	return "rag_chunks"
+}
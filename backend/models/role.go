@@ -0,0 +1,259 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Permission is an action on a kind of resource, written "resource:action"
+type Permission string
+
+const (
+	// PermissionAll grants every permission
+	PermissionAll Permission = "*"
+
+	PermissionPoliciesRead     Permission = "policies:read"
+	PermissionPoliciesWrite    Permission = "policies:write"
+	PermissionAuditRead        Permission = "audit:read"
+	PermissionBudgetsOverride  Permission = "budgets:override"
+	PermissionExperimentsRead  Permission = "experiments:read"
+	PermissionExperimentsWrite Permission = "experiments:write"
+	PermissionTemplatesRead    Permission = "templates:read"
+	PermissionTemplatesWrite   Permission = "templates:write"
+	PermissionRAGWrite         Permission = "rag:write"
+	PermissionWebhooksWrite    Permission = "webhooks:write"
+	PermissionAPIKeysRead      Permission = "api_keys:read"
+	PermissionAPIKeysWrite     Permission = "api_keys:write"
+	PermissionRolesRead        Permission = "roles:read"
+	PermissionRolesWrite       Permission = "roles:write"
+
+	PermissionServiceAccountsRead  Permission = "service_accounts:read"
+	PermissionServiceAccountsWrite Permission = "service_accounts:write"
+
+	PermissionSCIMRead  Permission = "scim:read"
+	PermissionSCIMWrite Permission = "scim:write"
+
+	PermissionUsersWrite       Permission = "users:write"
+	PermissionUsersImpersonate Permission = "users:impersonate"
+
+	PermissionOrganizationsWrite Permission = "organizations:write"
+	PermissionApplicationsWrite  Permission = "applications:write"
+)
+
+// Permissions lists every permission a role may be granted, besides "*" and
+// "resource:*" wildcards
+var Permissions = []Permission{
+	PermissionPoliciesRead,
+	PermissionPoliciesWrite,
+	PermissionAuditRead,
+	PermissionBudgetsOverride,
+	PermissionExperimentsRead,
+	PermissionExperimentsWrite,
+	PermissionTemplatesRead,
+	PermissionTemplatesWrite,
+	PermissionRAGWrite,
+	PermissionWebhooksWrite,
+	PermissionAPIKeysRead,
+	PermissionAPIKeysWrite,
+	PermissionRolesRead,
+	PermissionRolesWrite,
+	PermissionOrganizationsWrite,
+	PermissionApplicationsWrite,
+	PermissionServiceAccountsRead,
+	PermissionServiceAccountsWrite,
+	PermissionSCIMRead,
+	PermissionSCIMWrite,
+	PermissionUsersWrite,
+	PermissionUsersImpersonate,
+}
+
+// BuiltInRole is a default role-to-permission mapping
+type BuiltInRole struct {
+	Name        UserRole
+	Description string
+	Permissions []Permission
+}
+
+// BuiltInRoles are the role-to-permission mappings every organization starts
+// with; organizations may override them, except admin
+var BuiltInRoles = []BuiltInRole{
+	{
+		Name:        RoleAdmin,
+		Description: "Full access",
+		Permissions: []Permission{PermissionAll},
+	},
+	{
+		Name:        RoleMember,
+		Description: "Manages policies, experiments, templates and documents",
+		Permissions: []Permission{
+			PermissionPoliciesRead,
+			PermissionPoliciesWrite,
+			PermissionExperimentsRead,
+			PermissionExperimentsWrite,
+			PermissionTemplatesRead,
+			PermissionTemplatesWrite,
+			PermissionRAGWrite,
+			PermissionAPIKeysRead,
+			PermissionRolesRead,
+		},
+	},
+	{
+		Name:        RoleViewer,
+		Description: "Read-only access",
+		Permissions: []Permission{
+			PermissionPoliciesRead,
+			PermissionExperimentsRead,
+			PermissionTemplatesRead,
+			PermissionAPIKeysRead,
+			PermissionRolesRead,
+		},
+	},
+}
+
+// BuiltInRolesGrant reports whether any of the named roles grants permission
+// by the built-in mappings alone
+func BuiltInRolesGrant(roles []string, permission Permission) bool {
+	if HasSuperAdminRole(roles) {
+		return true
+	}
+	for _, builtIn := range BuiltInRoles {
+		for _, role := range roles {
+			if role == string(builtIn.Name) && Grants(builtIn.Permissions, permission) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasSuperAdminRole reports whether the roles include the platform superadmin
+// role, which grants every permission in every organization
+func HasSuperAdminRole(roles []string) bool {
+	for _, role := range roles {
+		if role == string(RoleSuperAdmin) {
+			return true
+		}
+	}
+	return false
+}
+
+// roleNamePattern matches role names, which are stored in users.role
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+
+// Role maps a role name to the permissions it grants. Built-in roles have no
+// organization; an organization's role of the same name overrides one.
+type Role struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	OrgID       *uuid.UUID      `json:"org_id,omitempty" db:"org_id"` // Nil for built-in roles
+	Name        string          `json:"name" db:"name"`
+	Description string          `json:"description" db:"description"`
+	Permissions json.RawMessage `json:"permissions" db:"permissions"` // JSONB []Permission
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// TableName returns the table name for the Role model
+func (Role) TableName() string {
+	return "roles"
+}
+
+// NewRole creates a new Role instance; a nil orgID creates a built-in role
+func NewRole(orgID *uuid.UUID, name, description string, permissions []Permission) (*Role, error) {
+	if err := ValidateRoleName(name); err != nil {
+		return nil, err
+	}
+	if name == string(RoleSuperAdmin) {
+		return nil, fmt.Errorf("role name %q is reserved", name)
+	}
+	now := time.Now()
+	role := &Role{
+		ID:          uuid.New(),
+		OrgID:       orgID,
+		Name:        name,
+		Description: description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := role.SetPermissions(permissions); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// IsBuiltIn returns true if the role is a default shared by all organizations
+func (r *Role) IsBuiltIn() bool {
+	return r.OrgID == nil
+}
+
+// GetPermissions returns the permissions the role grants
+func (r *Role) GetPermissions() ([]Permission, error) {
+	var permissions []Permission
+	if len(r.Permissions) == 0 {
+		return permissions, nil
+	}
+	if err := json.Unmarshal(r.Permissions, &permissions); err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+// SetPermissions validates and sets the permissions the role grants
+func (r *Role) SetPermissions(permissions []Permission) error {
+	for _, permission := range permissions {
+		if err := ValidatePermission(permission); err != nil {
+			return err
+		}
+	}
+	if permissions == nil {
+		permissions = []Permission{}
+	}
+	data, err := json.Marshal(permissions)
+	if err != nil {
+		return err
+	}
+	r.Permissions = data
+	return nil
+}
+
+// ValidateRoleName checks that a role name is a lowercase slug of at most 50 characters
+func ValidateRoleName(name string) error {
+	if !roleNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid role name %q: use lowercase letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// ValidatePermission checks that a permission is known, "*", or a known
+// resource's "resource:*" wildcard
+func ValidatePermission(permission Permission) error {
+	if permission == PermissionAll {
+		return nil
+	}
+	resource, action, ok := strings.Cut(string(permission), ":")
+	for _, known := range Permissions {
+		if known == permission {
+			return nil
+		}
+		if ok && action == "*" && strings.HasPrefix(string(known), resource+":") {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown permission %q", permission)
+}
+
+// Grants reports whether a set of permissions includes permission, directly
+// or through "*" and "resource:*" wildcards
+func Grants(permissions []Permission, permission Permission) bool {
+	resource, _, _ := strings.Cut(string(permission), ":")
+	for _, granted := range permissions {
+		if granted == permission || granted == PermissionAll || granted == Permission(resource+":*") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is a signed-in user's browser session. The session cookie carries
+// an opaque token of which only a hash is stored; the identity the user
+// signed in with is kept on the session and renewed with the identity
+// provider's refresh token when it expires.
+type Session struct {
+	ID                uuid.UUID       `json:"id" db:"id"`
+	TokenHash         string          `json:"-" db:"token_hash"` // Never expose in JSON
+	OrgID             uuid.UUID       `json:"org_id" db:"org_id"`
+	AppID             *uuid.UUID      `json:"app_id,omitempty" db:"app_id"`
+	UserID            *uuid.UUID      `json:"user_id,omitempty" db:"user_id"`
+	Provider          string          `json:"provider" db:"provider"` // "cognito" or an OIDC provider's name
+	Issuer            string          `json:"issuer,omitempty" db:"issuer"`
+	Subject           string          `json:"subject" db:"subject"`
+	Email             string          `json:"email,omitempty" db:"email"`
+	EmailVerified     bool            `json:"email_verified" db:"email_verified"`
+	Roles             json.RawMessage `json:"roles" db:"roles"`     // JSONB []string
+	RefreshToken      string          `json:"-" db:"refresh_token"` // Never expose in JSON
+	IdentityExpiresAt time.Time       `json:"identity_expires_at" db:"identity_expires_at"`
+	ExpiresAt         time.Time       `json:"expires_at" db:"expires_at"`
+	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
+	LastSeenAt        time.Time       `json:"last_seen_at" db:"last_seen_at"`
+	RevokedAt         *time.Time      `json:"revoked_at,omitempty" db:"revoked_at"`
+	UserAgent         string          `json:"user_agent,omitempty" db:"user_agent"`
+	IPAddress         string          `json:"ip_address,omitempty" db:"ip_address"`
+}
+
+// TableName returns the table name for the Session model
+func (Session) TableName() string {
+	return "sessions"
+}
+
+// IsActive reports whether the session may be used at the given time
+func (s *Session) IsActive(at time.Time) bool {
+	return s.RevokedAt == nil && at.Before(s.ExpiresAt)
+}
+
+// IdentityExpired reports whether the session's identity must be renewed
+// before the session is used at the given time
+func (s *Session) IdentityExpired(at time.Time) bool {
+	return !at.Before(s.IdentityExpiresAt)
+}
+
+// GetRoles returns the roles of the session's identity
+func (s *Session) GetRoles() ([]string, error) {
+	roles := []string{}
+	if len(s.Roles) == 0 {
+		return roles, nil
+	}
+	if err := json.Unmarshal(s.Roles, &roles); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session roles: %w", err)
+	}
+	return roles, nil
+}
+
+// SetRoles sets the roles of the session's identity
+func (s *Session) SetRoles(roles []string) error {
+	if roles == nil {
+		roles = []string{}
+	}
+	data, err := json.Marshal(roles)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session roles: %w", err)
+	}
+	s.Roles = data
+	return nil
+}
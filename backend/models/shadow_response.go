@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShadowResponse stores the result of a mirrored request for offline comparison
+// against the primary inference request it shadows
+type ShadowResponse struct {
+	ID               uuid.UUID       `json:"id" db:"id"`
+	InferenceID      uuid.UUID       `json:"inference_id" db:"inference_id"` // Primary InferenceRequest
+	OrgID            uuid.UUID       `json:"org_id" db:"org_id"`
+	AppID            uuid.UUID       `json:"app_id" db:"app_id"`
+	Provider         string          `json:"provider" db:"provider"`
+	Model            string          `json:"model" db:"model"`
+	Status           InferenceStatus `json:"status" db:"status"`
+	Response         *string         `json:"response,omitempty" db:"response"`
+	PromptTokens     int             `json:"prompt_tokens" db:"prompt_tokens"`
+	CompletionTokens int             `json:"completion_tokens" db:"completion_tokens"`
+	LatencyMs        int             `json:"latency_ms" db:"latency_ms"`
+	Cost             float64         `json:"cost" db:"cost"`
+	ErrorMessage     *string         `json:"error_message,omitempty" db:"error_message"`
+	CreatedAt        time.Time       `json:"created_at" db:"created_at"`
+}
+
+// TableName returns the table name for the ShadowResponse model
+func (ShadowResponse) TableName() string {
+	return "shadow_responses"
+}
+
+// NewShadowResponse creates a new ShadowResponse for a primary inference request
+func NewShadowResponse(inferenceID, orgID, appID uuid.UUID, provider, model string) *ShadowResponse {
+	return &ShadowResponse{
+		ID:          uuid.New(),
+		InferenceID: inferenceID,
+		OrgID:       orgID,
+		AppID:       appID,
+		Provider:    provider,
+		Model:       model,
+		Status:      InferenceStatusPending,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// MarkAsCompleted records a successful shadow response
+func (sr *ShadowResponse) MarkAsCompleted(response string, promptTokens, completionTokens, latencyMs int, cost float64) {
+	sr.Status = InferenceStatusCompleted
+	sr.Response = &response
+	sr.PromptTokens = promptTokens
+	sr.CompletionTokens = completionTokens
+	sr.LatencyMs = latencyMs
+	sr.Cost = cost
+}
+
+// MarkAsFailed records a failed shadow attempt
+func (sr *ShadowResponse) MarkAsFailed(errorMessage string, latencyMs int) {
+	sr.Status = InferenceStatusFailed
+	sr.ErrorMessage = &errorMessage
+	sr.LatencyMs = latencyMs
+}
+
+// MarkAsRejected records a shadow request skipped by the shadow budget
+func (sr *ShadowResponse) MarkAsRejected(reason string) {
+	sr.Status = InferenceStatusRejected
+	sr.ErrorMessage = &reason
+}
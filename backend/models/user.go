@@ -13,17 +13,39 @@ const (
 	RoleAdmin  UserRole = "admin"
 	RoleMember UserRole = "member"
 	RoleViewer UserRole = "viewer"
+
+	// RoleSuperAdmin is a platform role granted by the identity provider, not
+	// within an organization; it is the only role that may access other
+	// organizations' resources
+	RoleSuperAdmin UserRole = "superadmin"
 )
 
+// OrgRoles are the roles a user can hold within an organization, from the
+// least to the most privileged
+var OrgRoles = []UserRole{RoleViewer, RoleMember, RoleAdmin}
+
+// IsOrgRole reports whether the role can be held within an organization
+func (r UserRole) IsOrgRole() bool {
+	for _, role := range OrgRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 // User represents a user in the system authenticated via Cognito
 type User struct {
-	ID         uuid.UUID `json:"id" db:"id"`
-	Email      string    `json:"email" db:"email"`
-	CognitoSub string    `json:"cognito_sub" db:"cognito_sub"` // Cognito user identifier
-	OrgID      uuid.UUID `json:"org_id" db:"org_id"`
-	Role       UserRole  `json:"role" db:"role"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	ID            uuid.UUID  `json:"id" db:"id"`
+	Email         string     `json:"email" db:"email"`
+	CognitoSub    string     `json:"cognito_sub" db:"cognito_sub"` // Cognito user identifier
+	OrgID         uuid.UUID  `json:"org_id" db:"org_id"`
+	Role          UserRole   `json:"role" db:"role"`
+	ExternalID    string     `json:"external_id,omitempty" db:"external_id"` // The provisioning identity provider's ID
+	DisplayName   string     `json:"display_name,omitempty" db:"display_name"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty" db:"deactivated_at"`
 }
 
 // TableName returns the table name for the User model
@@ -45,6 +67,11 @@ func NewUser(email, cognitoSub string, orgID uuid.UUID, role UserRole) *User {
 	}
 }
 
+// IsActive reports whether the user has not been deactivated
+func (u *User) IsActive() bool {
+	return u.DeactivatedAt == nil
+}
+
 // IsAdmin returns true if the user has admin role
 func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
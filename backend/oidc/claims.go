@@ -0,0 +1,159 @@
+package oidc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+)
+
+// Identity is a validated token's user, mapped to the gateway's tenants and roles
+type Identity struct {
+	Provider      string
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	OrgID         uuid.UUID
+	AppID         *uuid.UUID // Optional - may be nil
+	Roles         []string
+	Nonce         string
+	IssuedAt      time.Time
+	ExpiresAt     time.Time
+}
+
+// mapClaims maps a token's claims to an identity by the provider's claim mappings
+func (p *Provider) mapClaims(claims jwt.MapClaims) (*Identity, error) {
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrInvalidToken)
+	}
+
+	orgID, err := p.mapTenant(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider: p.cfg.Name,
+		Issuer:   p.cfg.Issuer,
+		Subject:  sub,
+		OrgID:    orgID,
+		Roles:    p.mapRoles(claims),
+	}
+	identity.Email, _ = lookupClaim(claims, orDefault(p.cfg.EmailClaim, defaultEmailClaim)).(string)
+	identity.EmailVerified, _ = claims["email_verified"].(bool)
+	identity.Nonce, _ = claims["nonce"].(string)
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		identity.IssuedAt = iat.Time
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		identity.ExpiresAt = exp.Time
+	}
+
+	if p.cfg.AppClaim != "" {
+		if value, _ := lookupClaim(claims, p.cfg.AppClaim).(string); value != "" {
+			appID, err := uuid.Parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid %s: %v", ErrInvalidToken, p.cfg.AppClaim, err)
+			}
+			identity.AppID = &appID
+		}
+	}
+
+	return identity, nil
+}
+
+// mapTenant resolves the organization a token's user belongs to
+func (p *Provider) mapTenant(claims jwt.MapClaims) (uuid.UUID, error) {
+	if p.orgID != uuid.Nil {
+		return p.orgID, nil
+	}
+
+	tenantClaim := orDefault(p.cfg.TenantClaim, defaultTenantClaim)
+	value, _ := lookupClaim(claims, tenantClaim).(string)
+	if value == "" {
+		return uuid.Nil, fmt.Errorf("%w: missing %s", ErrInvalidToken, tenantClaim)
+	}
+
+	if len(p.tenantMappings) > 0 {
+		orgID, ok := p.tenantMappings[value]
+		if !ok {
+			return uuid.Nil, fmt.Errorf("%w: no organization for %s %q", ErrInvalidToken, tenantClaim, value)
+		}
+		return orgID, nil
+	}
+
+	orgID, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: invalid %s: %v", ErrInvalidToken, tenantClaim, err)
+	}
+	return orgID, nil
+}
+
+// mapRoles reads a token's roles, translated through the role mappings. The
+// platform superadmin role is only honoured from issuers allowed to grant it.
+func (p *Provider) mapRoles(claims jwt.MapClaims) []string {
+	var values []string
+	switch v := lookupClaim(claims, orDefault(p.cfg.RoleClaim, defaultRoleClaim)).(type) {
+	case string:
+		values = strings.Fields(v)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	roles := []string{}
+	seen := make(map[string]bool)
+	for _, value := range values {
+		role := value
+		if len(p.cfg.RoleMappings) > 0 {
+			mapped, ok := p.cfg.RoleMappings[value]
+			if !ok {
+				continue
+			}
+			role = mapped
+		}
+		if role == string(models.RoleSuperAdmin) && !p.cfg.AllowSuperAdmin {
+			continue
+		}
+		if !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// lookupClaim returns a claim by name or by a dotted path into nested claims,
+// such as Keycloak's realm_access.roles. Names that contain dots themselves,
+// such as namespaced Auth0 claims, are matched whole first.
+func lookupClaim(claims map[string]interface{}, path string) interface{} {
+	if value, ok := claims[path]; ok {
+		return value
+	}
+
+	head, rest, found := strings.Cut(path, ".")
+	if !found {
+		return nil
+	}
+	nested, ok := claims[head].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return lookupClaim(nested, rest)
+}
+
+// orDefault returns value, or fallback when value is empty
+func orDefault(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
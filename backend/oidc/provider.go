@@ -0,0 +1,447 @@
+// Package oidc validates tokens from, and signs users in with, generic OpenID
+// Connect identity providers such as Okta, Auth0 and Keycloak.
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/config"
+)
+
+var (
+	// ErrInvalidToken is returned when the token is invalid
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrTokenExpired is returned when the token has expired
+	ErrTokenExpired = errors.New("token expired")
+
+	// ErrUnknownIssuer is returned when no provider is configured for the token's issuer
+	ErrUnknownIssuer = errors.New("unknown issuer")
+
+	// ErrDiscoveryFailed is returned when the provider configuration cannot be discovered
+	ErrDiscoveryFailed = errors.New("failed to discover provider configuration")
+
+	// ErrJWKSFetchFailed is returned when JWKS fetching fails
+	ErrJWKSFetchFailed = errors.New("failed to fetch JWKS")
+
+	// ErrInvalidGrant is returned when the issuer rejects an authorization
+	// code or refresh token
+	ErrInvalidGrant = errors.New("invalid grant")
+)
+
+const (
+	// DefaultCacheTTL is how long discovery documents and key sets are cached
+	DefaultCacheTTL = time.Hour
+
+	// minJWKSRefresh limits refetching the key set for unknown key IDs, so
+	// tokens with made-up key IDs cannot hammer the issuer
+	minJWKSRefresh = time.Minute
+
+	defaultTenantClaim = "org_id"
+	defaultRoleClaim   = "groups"
+	defaultEmailClaim  = "email"
+)
+
+// Discovery is the subset of an issuer's .well-known/openid-configuration the
+// gateway uses
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint,omitempty"`
+}
+
+// JWKS represents the JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK represents a JSON Web Key
+type JWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// Provider validates tokens of, and exchanges authorization codes with, one
+// OpenID Connect issuer
+type Provider struct {
+	cfg            config.OIDCProviderConfig
+	orgID          uuid.UUID
+	tenantMappings map[string]uuid.UUID
+	httpClient     *http.Client
+	cacheTTL       time.Duration
+
+	mu           sync.Mutex
+	discovery    *Discovery
+	discoveryExp time.Time
+	keys         map[string]*rsa.PublicKey
+	keysExp      time.Time
+	keysFetched  time.Time
+}
+
+// NewProvider creates a provider from its configuration; a nil httpClient
+// uses one with a 10 second timeout
+func NewProvider(cfg config.OIDCProviderConfig, httpClient *http.Client) (*Provider, error) {
+	if cfg.Name == "" || cfg.Issuer == "" || cfg.ClientID == "" {
+		return nil, fmt.Errorf("oidc provider requires a name, issuer and client ID")
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	p := &Provider{
+		cfg:            cfg,
+		tenantMappings: make(map[string]uuid.UUID),
+		httpClient:     httpClient,
+		cacheTTL:       DefaultCacheTTL,
+	}
+	if cfg.OrgID != "" {
+		orgID, err := uuid.Parse(cfg.OrgID)
+		if err != nil {
+			return nil, fmt.Errorf("oidc provider %s: invalid org_id: %w", cfg.Name, err)
+		}
+		p.orgID = orgID
+	}
+	for value, org := range cfg.TenantMappings {
+		orgID, err := uuid.Parse(org)
+		if err != nil {
+			return nil, fmt.Errorf("oidc provider %s: invalid tenant mapping for %q: %w", cfg.Name, value, err)
+		}
+		p.tenantMappings[value] = orgID
+	}
+
+	return p, nil
+}
+
+// Name returns the provider's name
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// Issuer returns the provider's issuer
+func (p *Provider) Issuer() string {
+	return p.cfg.Issuer
+}
+
+// RedirectURI returns the provider's OAuth2 callback URL
+func (p *Provider) RedirectURI() string {
+	return p.cfg.RedirectURI
+}
+
+// Discover fetches the issuer's .well-known/openid-configuration, cached for the cache TTL
+func (p *Provider) Discover(ctx context.Context) (*Discovery, error) {
+	p.mu.Lock()
+	if p.discovery != nil && time.Now().Before(p.discoveryExp) {
+		defer p.mu.Unlock()
+		return p.discovery, nil
+	}
+	p.mu.Unlock()
+
+	discoveryURL := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	var discovery Discovery
+	if err := p.getJSON(ctx, discoveryURL, &discovery); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDiscoveryFailed, err)
+	}
+	// The issuer must identify itself as configured, or its tokens would not validate
+	if discovery.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("%w: issuer %s does not match %s", ErrDiscoveryFailed, discovery.Issuer, p.cfg.Issuer)
+	}
+	if discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("%w: no jwks_uri", ErrDiscoveryFailed)
+	}
+
+	p.mu.Lock()
+	p.discovery = &discovery
+	p.discoveryExp = time.Now().Add(p.cacheTTL)
+	p.mu.Unlock()
+
+	return &discovery, nil
+}
+
+// ValidateToken validates an ID or access token of the issuer and maps its
+// claims to an identity
+func (p *Provider) ValidateToken(ctx context.Context, tokenString string) (*Identity, error) {
+	audiences := append([]string{p.cfg.ClientID}, p.cfg.Audiences...)
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, errors.New("kid header not found")
+		}
+		return p.getPublicKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512"}),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	tokenAudiences, err := claims.GetAudience()
+	if err != nil || !containsAny(tokenAudiences, audiences) {
+		return nil, fmt.Errorf("%w: audience not accepted", ErrInvalidToken)
+	}
+
+	return p.mapClaims(claims)
+}
+
+// AuthCodeURL returns the URL of the issuer's authorization endpoint to
+// redirect users to for signing in
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce string) (string, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := p.cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	params := url.Values{
+		"response_type": {"code"},
+		"client_id":     {p.cfg.ClientID},
+		"redirect_uri":  {p.cfg.RedirectURI},
+		"scope":         {strings.Join(scopes, " ")},
+		"state":         {state},
+		"nonce":         {nonce},
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Tokens are the tokens of a token endpoint response the gateway uses
+type Tokens struct {
+	IDToken      string `json:"id_token"`
+	RefreshToken string `json:"refresh_token,omitempty"` // Only issued for offline access scopes
+}
+
+// ExchangeCode exchanges an authorization code for the user's ID token and,
+// when the issuer grants one, a refresh token
+func (p *Provider) ExchangeCode(ctx context.Context, code string) (*Tokens, error) {
+	return p.requestTokens(ctx, url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {p.cfg.RedirectURI},
+	})
+}
+
+// Refresh exchanges a refresh token for a new ID token. The refresh token in
+// the result is "" unless the issuer rotates refresh tokens.
+func (p *Provider) Refresh(ctx context.Context, refreshToken string) (*Tokens, error) {
+	return p.requestTokens(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+// requestTokens posts a grant to the issuer's token endpoint
+func (p *Provider) requestTokens(ctx context.Context, data url.Values) (*Tokens, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data.Set("client_id", p.cfg.ClientID)
+	if p.cfg.ClientSecret != "" {
+		data.Set("client_secret", p.cfg.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("%w: status %d", ErrInvalidGrant, resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token exchange failed: status %d", resp.StatusCode)
+	}
+
+	var tokens Tokens
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("parse token response: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, fmt.Errorf("no id_token in response")
+	}
+
+	return &tokens, nil
+}
+
+// EndSessionURL returns the URL of the issuer's end session endpoint to
+// sign users out at, or "" when the issuer has none
+func (p *Provider) EndSessionURL(ctx context.Context, idTokenHint, postLogoutRedirectURI string) (string, error) {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	if discovery.EndSessionEndpoint == "" {
+		return "", nil
+	}
+
+	params := url.Values{"client_id": {p.cfg.ClientID}}
+	if idTokenHint != "" {
+		params.Set("id_token_hint", idTokenHint)
+	}
+	if postLogoutRedirectURI != "" {
+		params.Set("post_logout_redirect_uri", postLogoutRedirectURI)
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.EndSessionEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.EndSessionEndpoint + separator + params.Encode(), nil
+}
+
+// getPublicKey retrieves the public key for a key ID, refetching the key set
+// when the issuer may have rotated its keys
+func (p *Provider) getPublicKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	fresh := time.Now().Before(p.keysExp)
+	recentlyFetched := time.Since(p.keysFetched) < minJWKSRefresh
+	p.mu.Unlock()
+
+	if ok && fresh {
+		return key, nil
+	}
+	if !ok && fresh && recentlyFetched {
+		return nil, fmt.Errorf("key with kid %s not found in JWKS", kid)
+	}
+
+	if err := p.fetchKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("key with kid %s not found in JWKS", kid)
+}
+
+// fetchKeys fetches and caches the issuer's signing keys
+func (p *Provider) fetchKeys(ctx context.Context) error {
+	discovery, err := p.Discover(ctx)
+	if err != nil {
+		return err
+	}
+
+	var jwks JWKS
+	if err := p.getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return fmt.Errorf("%w: %v", ErrJWKSFetchFailed, err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for i := range jwks.Keys {
+		jwk := &jwks.Keys[i]
+		if jwk.Kty != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwkToRSAPublicKey(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+
+	now := time.Now()
+	p.mu.Lock()
+	p.keys = keys
+	p.keysExp = now.Add(p.cacheTTL)
+	p.keysFetched = now
+	p.mu.Unlock()
+
+	return nil
+}
+
+// getJSON fetches a JSON document from the issuer
+func (p *Provider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// jwkToRSAPublicKey converts a JWK to an RSA public key
+func jwkToRSAPublicKey(jwk *JWK) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode exponent: %w", err)
+	}
+
+	var e int
+	for _, b := range eBytes {
+		e = e*256 + int(b)
+	}
+
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(nBytes), E: e}, nil
+}
+
+// containsAny reports whether any of the values is accepted
+func containsAny(values, accepted []string) bool {
+	for _, value := range values {
+		for _, a := range accepted {
+			if value == a {
+				return true
+			}
+		}
+	}
+	return false
+}
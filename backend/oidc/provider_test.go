@@ -0,0 +1,331 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/config"
+)
+
+// stubIssuer is a local OpenID Connect issuer serving discovery, JWKS and a
+// token endpoint that returns idToken for the code "good-code" and the
+// refresh token "good-refresh"
+type stubIssuer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    map[string]*rsa.PrivateKey
+	idToken string
+	fetches int
+}
+
+func newStubIssuer(t *testing.T) *stubIssuer {
+	s := &stubIssuer{keys: make(map[string]*rsa.PrivateKey)}
+	s.rotate(t, "key-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Discovery{
+			Issuer:                s.URL,
+			AuthorizationEndpoint: s.URL + "/authorize",
+			TokenEndpoint:         s.URL + "/token",
+			JWKSURI:               s.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.fetches++
+		jwks := JWKS{}
+		for kid, key := range s.keys {
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kid: kid,
+				Kty: "RSA",
+				Alg: "RS256",
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(jwks)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		valid := r.FormValue("code") == "good-code"
+		if r.FormValue("grant_type") == "refresh_token" {
+			valid = r.FormValue("refresh_token") == "good-refresh"
+		}
+		if !valid || r.FormValue("client_id") != "gateway" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": s.idToken, "refresh_token": "good-refresh"})
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// rotate adds a signing key
+func (s *stubIssuer) rotate(t *testing.T, kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	s.mu.Lock()
+	s.keys[kid] = key
+	s.mu.Unlock()
+}
+
+// sign issues a token of the issuer with the given claims on top of defaults
+func (s *stubIssuer) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	now := time.Now()
+	all := jwt.MapClaims{
+		"iss": s.URL,
+		"sub": "00u1abcd",
+		"aud": "gateway",
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+	for k, v := range claims {
+		all[k] = v
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, all)
+	token.Header["kid"] = kid
+	s.mu.Lock()
+	key := s.keys[kid]
+	s.mu.Unlock()
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func newTestProvider(t *testing.T, issuer *stubIssuer, cfg config.OIDCProviderConfig) *Provider {
+	cfg.Name = "okta"
+	cfg.Issuer = issuer.URL
+	cfg.ClientID = "gateway"
+	cfg.RedirectURI = "https://gateway.test/auth/oidc/okta/callback"
+	p, err := NewProvider(cfg, nil)
+	require.NoError(t, err)
+	return p
+}
+
+func TestProvider_ValidateToken(t *testing.T) {
+	ctx := context.Background()
+	issuer := newStubIssuer(t)
+	orgID := uuid.New()
+	p := newTestProvider(t, issuer, config.OIDCProviderConfig{})
+
+	t.Run("valid token", func(t *testing.T) {
+		token := issuer.sign(t, "key-1", jwt.MapClaims{
+			"org_id":         orgID.String(),
+			"groups":         []string{"admin", "viewer"},
+			"email":          "ada@acme.test",
+			"email_verified": true,
+		})
+
+		identity, err := p.ValidateToken(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, "okta", identity.Provider)
+		assert.Equal(t, "00u1abcd", identity.Subject)
+		assert.Equal(t, orgID, identity.OrgID)
+		assert.Equal(t, []string{"admin", "viewer"}, identity.Roles)
+		assert.Equal(t, "ada@acme.test", identity.Email)
+		assert.True(t, identity.EmailVerified)
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		token := issuer.sign(t, "key-1", jwt.MapClaims{"org_id": orgID.String(), "aud": "other-client"})
+		_, err := p.ValidateToken(ctx, token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("wrong issuer", func(t *testing.T) {
+		token := issuer.sign(t, "key-1", jwt.MapClaims{"org_id": orgID.String(), "iss": "https://evil.test"})
+		_, err := p.ValidateToken(ctx, token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		token := issuer.sign(t, "key-1", jwt.MapClaims{"org_id": orgID.String(), "exp": time.Now().Add(-time.Minute).Unix()})
+		_, err := p.ValidateToken(ctx, token)
+		assert.ErrorIs(t, err, ErrTokenExpired)
+	})
+
+	t.Run("missing tenant", func(t *testing.T) {
+		token := issuer.sign(t, "key-1", jwt.MapClaims{})
+		_, err := p.ValidateToken(ctx, token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("signed by another key", func(t *testing.T) {
+		other := newStubIssuer(t)
+		token := other.sign(t, "key-1", jwt.MapClaims{"org_id": orgID.String(), "iss": issuer.URL})
+		_, err := p.ValidateToken(ctx, token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestProvider_KeyRotation(t *testing.T) {
+	ctx := context.Background()
+	issuer := newStubIssuer(t)
+	orgID := uuid.New()
+	p := newTestProvider(t, issuer, config.OIDCProviderConfig{})
+
+	_, err := p.ValidateToken(ctx, issuer.sign(t, "key-1", jwt.MapClaims{"org_id": orgID.String()}))
+	require.NoError(t, err)
+
+	// A new key is picked up once the cached key set is old enough to refetch
+	issuer.rotate(t, "key-2")
+	p.mu.Lock()
+	p.keysFetched = time.Now().Add(-2 * minJWKSRefresh)
+	p.mu.Unlock()
+
+	_, err = p.ValidateToken(ctx, issuer.sign(t, "key-2", jwt.MapClaims{"org_id": orgID.String()}))
+	require.NoError(t, err)
+
+	// Unknown key IDs do not refetch again right away
+	_, err = p.ValidateToken(ctx, issuer.sign(t, "key-2", jwt.MapClaims{"org_id": orgID.String()}))
+	require.NoError(t, err)
+	issuer.rotate(t, "key-3")
+	_, err = p.ValidateToken(ctx, issuer.sign(t, "key-3", jwt.MapClaims{"org_id": orgID.String()}))
+	assert.Error(t, err)
+	assert.Equal(t, 2, issuer.fetches)
+}
+
+func TestProvider_ClaimMappings(t *testing.T) {
+	ctx := context.Background()
+	issuer := newStubIssuer(t)
+	acme := uuid.New()
+
+	t.Run("keycloak nested roles and mapped tenant", func(t *testing.T) {
+		p := newTestProvider(t, issuer, config.OIDCProviderConfig{
+			TenantClaim:    "tenant",
+			TenantMappings: map[string]string{"acme": acme.String()},
+			RoleClaim:      "realm_access.roles",
+			RoleMappings:   map[string]string{"gateway-admin": "admin", "superadmin": "superadmin"},
+		})
+		token := issuer.sign(t, "key-1", jwt.MapClaims{
+			"tenant":       "acme",
+			"realm_access": map[string]interface{}{"roles": []string{"gateway-admin", "offline_access", "superadmin"}},
+		})
+
+		identity, err := p.ValidateToken(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, acme, identity.OrgID)
+		assert.Equal(t, []string{"admin"}, identity.Roles, "unmapped roles and superadmin are dropped")
+
+		_, err = p.ValidateToken(ctx, issuer.sign(t, "key-1", jwt.MapClaims{"tenant": "globex"}))
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("fixed organization and superadmin allowed", func(t *testing.T) {
+		p := newTestProvider(t, issuer, config.OIDCProviderConfig{
+			OrgID:           acme.String(),
+			AllowSuperAdmin: true,
+		})
+		token := issuer.sign(t, "key-1", jwt.MapClaims{
+			"org_id": uuid.New().String(),
+			"groups": "superadmin viewer",
+		})
+
+		identity, err := p.ValidateToken(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, acme, identity.OrgID, "the issuer's organization wins over the claim")
+		assert.Equal(t, []string{"superadmin", "viewer"}, identity.Roles)
+	})
+
+	t.Run("invalid mapping", func(t *testing.T) {
+		_, err := NewProvider(config.OIDCProviderConfig{
+			Name:           "okta",
+			Issuer:         issuer.URL,
+			ClientID:       "gateway",
+			TenantMappings: map[string]string{"acme": "not-a-uuid"},
+		}, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestProvider_Discovery(t *testing.T) {
+	ctx := context.Background()
+	issuer := newStubIssuer(t)
+	p := newTestProvider(t, issuer, config.OIDCProviderConfig{})
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1")
+	require.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/authorize", parsed.Path)
+	assert.Equal(t, "gateway", parsed.Query().Get("client_id"))
+	assert.Equal(t, "nonce-1", parsed.Query().Get("nonce"))
+	assert.Equal(t, "openid email profile", parsed.Query().Get("scope"))
+
+	issuer.idToken = "id-token"
+	tokens, err := p.ExchangeCode(ctx, "good-code")
+	require.NoError(t, err)
+	assert.Equal(t, "id-token", tokens.IDToken)
+	assert.Equal(t, "good-refresh", tokens.RefreshToken)
+
+	_, err = p.ExchangeCode(ctx, "bad-code")
+	assert.ErrorIs(t, err, ErrInvalidGrant)
+
+	tokens, err = p.Refresh(ctx, "good-refresh")
+	require.NoError(t, err)
+	assert.Equal(t, "id-token", tokens.IDToken)
+
+	_, err = p.Refresh(ctx, "revoked-refresh")
+	assert.ErrorIs(t, err, ErrInvalidGrant)
+
+	// Discovery documents naming another issuer are rejected
+	mismatched, err := NewProvider(config.OIDCProviderConfig{Name: "x", Issuer: issuer.URL + "/", ClientID: "gateway"}, nil)
+	require.NoError(t, err)
+	_, err = mismatched.Discover(ctx)
+	assert.ErrorIs(t, err, ErrDiscoveryFailed)
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	okta := newStubIssuer(t)
+	keycloak := newStubIssuer(t)
+	orgA, orgB := uuid.New(), uuid.New()
+
+	registry, err := NewRegistryFromConfig(config.OIDCConfig{Providers: []config.OIDCProviderConfig{
+		{Name: "okta", Issuer: okta.URL, ClientID: "gateway", OrgID: orgA.String()},
+		{Name: "keycloak", Issuer: keycloak.URL, ClientID: "gateway", OrgID: orgB.String()},
+	}}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, registry.Len())
+
+	identity, err := registry.ValidateToken(ctx, okta.sign(t, "key-1", nil))
+	require.NoError(t, err)
+	assert.Equal(t, orgA, identity.OrgID)
+
+	identity, err = registry.ValidateToken(ctx, keycloak.sign(t, "key-1", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "keycloak", identity.Provider)
+	assert.Equal(t, orgB, identity.OrgID)
+
+	unknown := newStubIssuer(t)
+	token := unknown.sign(t, "key-1", nil)
+	assert.False(t, registry.Handles(token))
+	_, err = registry.ValidateToken(ctx, token)
+	assert.ErrorIs(t, err, ErrUnknownIssuer)
+
+	_, err = NewRegistryFromConfig(config.OIDCConfig{Providers: []config.OIDCProviderConfig{
+		{Name: "okta", Issuer: okta.URL, ClientID: "a"},
+		{Name: "okta", Issuer: keycloak.URL, ClientID: "b"},
+	}}, nil)
+	assert.Error(t, err)
+}
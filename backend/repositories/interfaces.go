@@ -108,9 +108,18 @@ type OrganizationRepository interface {
 	// Update updates an organization
 	Update(ctx context.Context, org *models.Organization) error
 	
-	// Delete deletes an organization
+	// Delete permanently deletes an organization
 	Delete(ctx context.Context, id uuid.UUID) error
 	
+	// GetDeleted retrieves a deleted organization by ID
+	GetDeleted(ctx context.Context, id uuid.UUID) (*models.Organization, error)
+	
+	// SoftDelete marks an organization deleted
+	SoftDelete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error
+	
+	// Restore clears the deletion of an organization
+	Restore(ctx context.Context, id uuid.UUID) error
+	
 	// WithTx returns a new repository instance bound to the transaction
 	WithTx(tx Transaction) OrganizationRepository
 }
@@ -123,6 +132,9 @@ type ApplicationRepository interface {
 	// GetByID retrieves an application by ID
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Application, error)
 	
+	// GetByOrgAndID retrieves an application by ID within an organization
+	GetByOrgAndID(ctx context.Context, orgID, id uuid.UUID) (*models.Application, error)
+	
 	// GetByAPIKeyHash retrieves an application by API key hash
 	GetByAPIKeyHash(ctx context.Context, apiKeyHash string) (*models.Application, error)
 	
@@ -132,9 +144,24 @@ type ApplicationRepository interface {
 	// Update updates an application
 	Update(ctx context.Context, app *models.Application) error
 	
-	// Delete deletes an application
+	// Delete permanently deletes an application
 	Delete(ctx context.Context, id uuid.UUID) error
 	
+	// GetDeleted retrieves a deleted application by ID
+	GetDeleted(ctx context.Context, id uuid.UUID) (*models.Application, error)
+	
+	// SoftDelete marks an application deleted
+	SoftDelete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error
+	
+	// SoftDeleteByOrg marks the applications of an organization deleted
+	SoftDeleteByOrg(ctx context.Context, orgID uuid.UUID, deletedAt time.Time) error
+	
+	// Restore clears the deletion of an application
+	Restore(ctx context.Context, id uuid.UUID) error
+	
+	// RestoreByOrg clears the deletion of the applications of an organization deleted at deletedAt
+	RestoreByOrg(ctx context.Context, orgID uuid.UUID, deletedAt time.Time) error
+	
 	// WithTx returns a new repository instance bound to the transaction
 	WithTx(tx Transaction) ApplicationRepository
 }
@@ -147,16 +174,27 @@ type UserRepository interface {
 	// GetByID retrieves a user by ID
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	
+	// GetByOrgAndID retrieves a user by ID within an organization
+	GetByOrgAndID(ctx context.Context, orgID, id uuid.UUID) (*models.User, error)
+	
 	// GetByCognitoSub retrieves a user by Cognito subject
 	GetByCognitoSub(ctx context.Context, cognitoSub string) (*models.User, error)
 	
 	// GetByEmail retrieves a user by email
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	
+	// GetByOrgAndEmail retrieves a user by email within an organization
+	GetByOrgAndEmail(ctx context.Context, orgID uuid.UUID, email string) (*models.User, error)
+	
+	// GetByOrgAndExternalID retrieves a user by the provisioning identity
+	// provider's ID within an organization
+	GetByOrgAndExternalID(ctx context.Context, orgID uuid.UUID, externalID string) (*models.User, error)
+	
 	// GetByOrgID retrieves all users for an organization
 	GetByOrgID(ctx context.Context, orgID uuid.UUID) ([]*models.User, error)
 	
-	// Update updates a user
+	// Update updates a user's email, role, external ID, display name and
+	// deactivation time
 	Update(ctx context.Context, user *models.User) error
 	
 	// Delete deletes a user
@@ -192,12 +230,23 @@ type InferenceRequestRepository interface {
 	// GetByDateRange retrieves inference requests within a date range
 	GetByDateRange(ctx context.Context, orgID uuid.UUID, start, end time.Time, limit, offset int) ([]*models.InferenceRequest, error)
 	
-	// Update updates an inference request
+	// Update updates an inference request; experiment and template
+	// assignments are kept when the update has none
 	Update(ctx context.Context, req *models.InferenceRequest) error
 	
 	// GetMetrics retrieves aggregate metrics for an organization
 	GetMetrics(ctx context.Context, orgID uuid.UUID, start, end time.Time) (*InferenceMetrics, error)
 	
+	// GetExperimentMetrics retrieves aggregate metrics per arm for an experiment
+	GetExperimentMetrics(ctx context.Context, experimentID uuid.UUID) ([]*ArmMetrics, error)
+	
+	// SetFeedback records user feedback for an inference request
+	SetFeedback(ctx context.Context, id uuid.UUID, score int) error
+	
+	// FailUnfinished marks pending and processing requests created before a
+	// time as failed and returns how many were failed
+	FailUnfinished(ctx context.Context, before time.Time, errorCode, message string) (int64, error)
+	
 	// WithTx returns a new repository instance bound to the transaction
 	WithTx(tx Transaction) InferenceRequestRepository
 }
@@ -213,6 +262,328 @@ type InferenceMetrics struct {
 	AvgLatencyMs      float64 `json:"avg_latency_ms"`
 }
 
+// ArmMetrics represents aggregated inference metrics for one experiment arm
+type ArmMetrics struct {
+	Arm               string  `json:"arm"`
+	TotalRequests     int     `json:"total_requests"`
+	CompletedRequests int     `json:"completed_requests"`
+	FailedRequests    int     `json:"failed_requests"`
+	TotalTokens       int     `json:"total_tokens"`
+	TotalCost         float64 `json:"total_cost"`
+	AvgLatencyMs      float64 `json:"avg_latency_ms"`
+	P95LatencyMs      float64 `json:"p95_latency_ms"`
+	FeedbackCount     int     `json:"feedback_count"`
+	PositiveFeedback  int     `json:"positive_feedback"`
+	NegativeFeedback  int     `json:"negative_feedback"`
+}
+
+// ExperimentRepository handles experiment data operations
+type ExperimentRepository interface {
+	// Create creates a new experiment
+	Create(ctx context.Context, experiment *models.Experiment) error
+	
+	// GetByID retrieves an experiment by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Experiment, error)
+	
+	// GetByOrgID retrieves all experiments for an organization
+	GetByOrgID(ctx context.Context, orgID uuid.UUID) ([]*models.Experiment, error)
+	
+	// GetRunning retrieves running experiments applicable to an app and model,
+	// app-specific experiments first
+	GetRunning(ctx context.Context, orgID, appID uuid.UUID, model string) ([]*models.Experiment, error)
+	
+	// Update updates an experiment
+	Update(ctx context.Context, experiment *models.Experiment) error
+	
+	// Delete deletes an experiment
+	Delete(ctx context.Context, id uuid.UUID) error
+	
+	// WithTx returns a new repository instance bound to the transaction
+	WithTx(tx Transaction) ExperimentRepository
+}
+
+// ShadowResponseRepository handles shadow response data operations
+type ShadowResponseRepository interface {
+	// Create stores a shadow response
+	Create(ctx context.Context, resp *models.ShadowResponse) error
+	
+	// GetByInferenceID retrieves shadow responses for a primary inference request
+	GetByInferenceID(ctx context.Context, inferenceID uuid.UUID) ([]*models.ShadowResponse, error)
+	
+	// WithTx returns a new repository instance bound to the transaction
+	WithTx(tx Transaction) ShadowResponseRepository
+}
+
+// PromptTemplateRepository handles prompt template, version and pin data operations
+type PromptTemplateRepository interface {
+	// Create creates a new prompt template
+	Create(ctx context.Context, template *models.PromptTemplate) error
+	
+	// GetByID retrieves a prompt template by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.PromptTemplate, error)
+	
+	// GetByName retrieves a prompt template by name within an organization
+	GetByName(ctx context.Context, orgID uuid.UUID, name string) (*models.PromptTemplate, error)
+	
+	// GetByOrgID retrieves all prompt templates for an organization
+	GetByOrgID(ctx context.Context, orgID uuid.UUID) ([]*models.PromptTemplate, error)
+	
+	// Update updates a prompt template
+	Update(ctx context.Context, template *models.PromptTemplate) error
+	
+	// Delete deletes a prompt template with its versions and pins
+	Delete(ctx context.Context, id uuid.UUID) error
+	
+	// CreateVersion stores a new version and assigns it the next version number
+	CreateVersion(ctx context.Context, version *models.PromptTemplateVersion) error
+	
+	// GetVersion retrieves a specific version of a template
+	GetVersion(ctx context.Context, templateID uuid.UUID, version int) (*models.PromptTemplateVersion, error)
+	
+	// ListVersions retrieves all versions of a template, newest first
+	ListVersions(ctx context.Context, templateID uuid.UUID) ([]*models.PromptTemplateVersion, error)
+	
+	// SetPin creates or replaces an application's pinned version
+	SetPin(ctx context.Context, pin *models.PromptTemplatePin) error
+	
+	// GetPin retrieves an application's pinned version, or nil if it has none
+	GetPin(ctx context.Context, templateID, appID uuid.UUID) (*models.PromptTemplatePin, error)
+	
+	// DeletePin removes an application's pinned version
+	DeletePin(ctx context.Context, templateID, appID uuid.UUID) error
+	
+	// WithTx returns a new repository instance bound to the transaction
+	WithTx(tx Transaction) PromptTemplateRepository
+}
+
+// RAGDocumentRepository handles RAG document and chunk data operations
+type RAGDocumentRepository interface {
+	// GetDocument retrieves a document, or nil if the index has none with that ID
+	GetDocument(ctx context.Context, orgID uuid.UUID, indexName, documentID string) (*models.RAGDocument, error)
+	
+	// ReplaceDocument stores a document and replaces all of its chunks
+	ReplaceDocument(ctx context.Context, doc *models.RAGDocument, chunks []*models.RAGChunk) error
+	
+	// DeleteDocument deletes a document and its chunks
+	DeleteDocument(ctx context.Context, orgID uuid.UUID, indexName, documentID string) error
+	
+	// WithTx returns a new repository instance bound to the transaction
+	WithTx(tx Transaction) RAGDocumentRepository
+}
+
+// ConversationRepository handles conversation thread and message data operations
+type ConversationRepository interface {
+	// Create creates a new conversation
+	Create(ctx context.Context, conversation *models.Conversation) error
+	
+	// GetByID retrieves a conversation by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Conversation, error)
+	
+	// ListByUser retrieves a user's conversations in an organization, most recently active first
+	ListByUser(ctx context.Context, orgID, userID uuid.UUID, limit, offset int) ([]*models.Conversation, error)
+	
+	// Update updates a conversation's title, message count and activity time
+	Update(ctx context.Context, conversation *models.Conversation) error
+	
+	// Delete deletes a conversation and its messages
+	Delete(ctx context.Context, id uuid.UUID) error
+	
+	// AddMessage appends a message to a conversation
+	AddMessage(ctx context.Context, message *models.ConversationMessage) error
+	
+	// ListMessages retrieves all messages of a conversation, oldest first
+	ListMessages(ctx context.Context, conversationID uuid.UUID) ([]*models.ConversationMessage, error)
+	
+	// WithTx returns a new repository instance bound to the transaction
+	WithTx(tx Transaction) ConversationRepository
+}
+
+// BatchRepository handles batch job and item data operations
+type BatchRepository interface {
+	// CreateJob stores a job with all of its items
+	CreateJob(ctx context.Context, job *models.BatchJob, items []*models.BatchItem) error
+	
+	// GetJob retrieves a batch job by ID
+	GetJob(ctx context.Context, id uuid.UUID) (*models.BatchJob, error)
+	
+	// ListJobs retrieves batch jobs for an organization, newest first
+	ListJobs(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.BatchJob, error)
+	
+	// UpdateJobStatus sets a job's status if it is currently in one of the from statuses
+	UpdateJobStatus(ctx context.Context, id uuid.UUID, status models.BatchJobStatus, from ...models.BatchJobStatus) (bool, error)
+	
+	// RefreshJobProgress recounts a job's finished items and completes the job once none remain
+	RefreshJobProgress(ctx context.Context, id uuid.UUID) error
+	
+	// ClaimItem leases the next runnable item of an active job, or returns nil if there is none.
+	// Items whose lease has expired are claimed again.
+	ClaimItem(ctx context.Context, lease time.Duration) (*models.BatchItem, error)
+	
+	// UpdateItem stores the outcome of an item and releases its lease; fails if the item was claimed again since
+	UpdateItem(ctx context.Context, item *models.BatchItem) error
+	
+	// ListItems retrieves up to limit items of a job after a line number, in line order
+	ListItems(ctx context.Context, jobID uuid.UUID, afterLine, limit int) ([]*models.BatchItem, error)
+	
+	// WithTx returns a new repository instance bound to the transaction
+	WithTx(tx Transaction) BatchRepository
+}
+
+// APIKeyRepository handles application API key data operations
+type APIKeyRepository interface {
+	// Create creates a new API key
+	Create(ctx context.Context, key *models.APIKey) error
+	
+	// GetByID retrieves an API key by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.APIKey, error)
+	
+	// GetByHash retrieves an API key by the hash of the key
+	GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	
+	// ListByApp retrieves all keys of an application, newest first
+	ListByApp(ctx context.Context, appID uuid.UUID) ([]*models.APIKey, error)
+	
+	// Update updates a key's name, scopes, expiry and revocation time
+	Update(ctx context.Context, key *models.APIKey) error
+	
+	// RecordUsage adds a request to the key's usage of the day and marks the key as used
+	RecordUsage(ctx context.Context, usage *models.APIKeyUsage, usedAt time.Time) error
+	
+	// GetUsage retrieves a key's daily usage between two days, oldest first
+	GetUsage(ctx context.Context, keyID uuid.UUID, start, end time.Time) ([]*models.APIKeyUsage, error)
+	
+	// RevokeByCreator revokes all active keys a user created within an
+	// organization and returns how many were revoked
+	RevokeByCreator(ctx context.Context, orgID, userID uuid.UUID, at time.Time) (int64, error)
+	
+	// WithTx returns a new repository instance bound to the transaction
+	WithTx(tx Transaction) APIKeyRepository
+}
+
+// RoleRepository handles role-to-permission mapping data operations
+type RoleRepository interface {
+	// Create creates a new role
+	Create(ctx context.Context, role *models.Role) error
+	
+	// ListByOrg retrieves the built-in roles and the organization's roles, by name
+	ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*models.Role, error)
+	
+	// Update updates a role's description and permissions
+	Update(ctx context.Context, role *models.Role) error
+	
+	// Delete deletes a role
+	Delete(ctx context.Context, id uuid.UUID) error
+	
+	// WithTx returns a new repository instance bound to the transaction
+	WithTx(tx Transaction) RoleRepository
+}
+
+// SessionRepository handles server-side session data operations
+type SessionRepository interface {
+	// Create creates a new session
+	Create(ctx context.Context, session *models.Session) error
+	
+	// GetByID retrieves a session by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error)
+	
+	// GetByHash retrieves a session by the hash of its token
+	GetByHash(ctx context.Context, tokenHash string) (*models.Session, error)
+	
+	// ListBySubject retrieves a user's sessions that are neither revoked nor
+	// expired at the given time, newest first
+	ListBySubject(ctx context.Context, orgID uuid.UUID, subject string, at time.Time) ([]*models.Session, error)
+	
+	// Refresh updates a session's identity and refresh token, provided it is
+	// not revoked and still holds previousRefreshToken; it returns false
+	// otherwise, so concurrent refreshes and revocations are not overwritten
+	Refresh(ctx context.Context, session *models.Session, previousRefreshToken string) (bool, error)
+	
+	// Revoke revokes a session unless it is already revoked
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+	
+	// Touch records the session being used
+	Touch(ctx context.Context, id uuid.UUID, at time.Time) error
+	
+	// RevokeBySubject revokes all of a user's active sessions and returns how many were revoked
+	RevokeBySubject(ctx context.Context, orgID uuid.UUID, subject string, at time.Time) (int64, error)
+	
+	// RevokeByUser revokes all active sessions of a gateway user and returns how many were revoked
+	RevokeByUser(ctx context.Context, orgID, userID uuid.UUID, at time.Time) (int64, error)
+	
+	// WithTx returns a new repository instance bound to the transaction
+	WithTx(tx Transaction) SessionRepository
+}
+
+// ServiceAccountRepository handles service account data operations
+type ServiceAccountRepository interface {
+	// Create creates a new service account
+	Create(ctx context.Context, account *models.ServiceAccount) error
+	
+	// GetByID retrieves a service account by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error)
+	
+	// GetByClientID retrieves a service account by its client ID; accounts
+	// of deleted organizations are not found
+	GetByClientID(ctx context.Context, clientID string) (*models.ServiceAccount, error)
+	
+	// ListByOrg retrieves an organization's service accounts, newest first
+	ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*models.ServiceAccount, error)
+	
+	// Update updates a service account's name, description, secret, roles,
+	// scopes and disabled time
+	Update(ctx context.Context, account *models.ServiceAccount) error
+	
+	// Delete deletes a service account
+	Delete(ctx context.Context, id uuid.UUID) error
+	
+	// WithTx returns a new repository instance bound to the transaction
+	WithTx(tx Transaction) ServiceAccountRepository
+}
+
+// SCIMConfigRepository handles SCIM provisioning configuration data operations
+type SCIMConfigRepository interface {
+	// Get retrieves an organization's SCIM configuration
+	Get(ctx context.Context, orgID uuid.UUID) (*models.SCIMConfig, error)
+	
+	// GetByTokenHash retrieves the SCIM configuration of a bearer token's
+	// hash; configurations of deleted organizations are not found
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.SCIMConfig, error)
+	
+	// Upsert creates or replaces an organization's SCIM configuration
+	Upsert(ctx context.Context, config *models.SCIMConfig) error
+	
+	// WithTx returns a new repository instance bound to the transaction
+	WithTx(tx Transaction) SCIMConfigRepository
+}
+
+// SCIMGroupRepository handles SCIM group data operations. Groups are returned
+// with their members.
+type SCIMGroupRepository interface {
+	// Create creates a new group with its members
+	Create(ctx context.Context, group *models.SCIMGroup) error
+	
+	// GetByID retrieves a group by ID
+	GetByID(ctx context.Context, id uuid.UUID) (*models.SCIMGroup, error)
+	
+	// GetByOrgAndName retrieves a group by display name within an organization
+	GetByOrgAndName(ctx context.Context, orgID uuid.UUID, displayName string) (*models.SCIMGroup, error)
+	
+	// ListByOrg retrieves an organization's groups, by display name
+	ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*models.SCIMGroup, error)
+	
+	// ListByMember retrieves the groups a user is a member of
+	ListByMember(ctx context.Context, userID uuid.UUID) ([]*models.SCIMGroup, error)
+	
+	// Update updates a group's display name, external ID and members
+	Update(ctx context.Context, group *models.SCIMGroup) error
+	
+	// Delete deletes a group
+	Delete(ctx context.Context, id uuid.UUID) error
+	
+	// WithTx returns a new repository instance bound to the transaction
+	WithTx(tx Transaction) SCIMGroupRepository
+}
+
 // Repositories aggregates all repository interfaces
 type Repositories struct {
 	Organizations     OrganizationRepository
@@ -221,4 +592,16 @@ type Repositories struct {
 	Policies          PolicyRepository
 	AuditLogs         AuditRepository
 	InferenceRequests InferenceRequestRepository
+	Experiments       ExperimentRepository
+	ShadowResponses   ShadowResponseRepository
+	PromptTemplates   PromptTemplateRepository
+	RAGDocuments      RAGDocumentRepository
+	Conversations     ConversationRepository
+	Batches           BatchRepository
+	APIKeys           APIKeyRepository
+	Roles             RoleRepository
+	Sessions          SessionRepository
+	ServiceAccounts   ServiceAccountRepository
+	SCIMConfigs       SCIMConfigRepository
+	SCIMGroups        SCIMGroupRepository
 }
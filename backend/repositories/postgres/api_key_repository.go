@@ -0,0 +1,291 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"go.uber.org/zap"
+)
+
+// APIKeyRepository implements the repositories.APIKeyRepository interface
+type APIKeyRepository struct {
+	db     *DB
+	logger *zap.Logger
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *DB, logger *zap.Logger) repositories.APIKeyRepository {
+	return &APIKeyRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new API key
+func (r *APIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	query := `
+		INSERT INTO api_keys (
+			id, org_id, app_id, name, prefix, key_hash, scopes, created_by,
+			created_at, expires_at, revoked_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		key.ID,
+		key.OrgID,
+		key.AppID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.Scopes,
+		key.CreatedBy,
+		key.CreatedAt,
+		key.ExpiresAt,
+		key.RevokedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	r.logger.Debug("api key created", zap.String("id", key.ID.String()), zap.String("app_id", key.AppID.String()))
+	return nil
+}
+
+// GetByID retrieves an API key by ID
+func (r *APIKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	query := `
+		SELECT id, org_id, app_id, name, prefix, key_hash, scopes, created_by,
+		       created_at, expires_at, revoked_at, last_used_at
+		FROM api_keys
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	key, err := scanAPIKey(executor.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("api key not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// GetByHash retrieves an API key by the hash of the key; keys of deleted
+// applications are not found
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	query := `
+		SELECT id, org_id, app_id, name, prefix, key_hash, scopes, created_by,
+		       created_at, expires_at, revoked_at, last_used_at
+		FROM api_keys
+		WHERE key_hash = $1
+		  AND EXISTS (SELECT 1 FROM applications a WHERE a.id = api_keys.app_id AND a.deleted_at IS NULL)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	key, err := scanAPIKey(executor.QueryRowContext(ctx, query, keyHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("api key not found")
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+// ListByApp retrieves all keys of an application, newest first
+func (r *APIKeyRepository) ListByApp(ctx context.Context, appID uuid.UUID) ([]*models.APIKey, error) {
+	query := `
+		SELECT id, org_id, app_id, name, prefix, key_hash, scopes, created_by,
+		       created_at, expires_at, revoked_at, last_used_at
+		FROM api_keys
+		WHERE app_id = $1
+		ORDER BY created_at DESC
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api key rows: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Update updates a key's name, scopes, expiry and revocation time
+func (r *APIKeyRepository) Update(ctx context.Context, key *models.APIKey) error {
+	query := `
+		UPDATE api_keys
+		SET name = $2, scopes = $3, expires_at = $4, revoked_at = $5
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query,
+		key.ID,
+		key.Name,
+		key.Scopes,
+		key.ExpiresAt,
+		key.RevokedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update api key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("api key not found: %s", key.ID)
+	}
+
+	r.logger.Debug("api key updated", zap.String("id", key.ID.String()))
+	return nil
+}
+
+// RecordUsage adds a request to the key's usage of the day and marks the key as used
+func (r *APIKeyRepository) RecordUsage(ctx context.Context, usage *models.APIKeyUsage, usedAt time.Time) error {
+	query := `
+		INSERT INTO api_key_usage (key_id, day, requests, failed_requests, tokens, cost)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (key_id, day)
+		DO UPDATE SET requests = api_key_usage.requests + EXCLUDED.requests,
+		              failed_requests = api_key_usage.failed_requests + EXCLUDED.failed_requests,
+		              tokens = api_key_usage.tokens + EXCLUDED.tokens,
+		              cost = api_key_usage.cost + EXCLUDED.cost
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		usage.KeyID,
+		usage.Day,
+		usage.Requests,
+		usage.FailedRequests,
+		usage.Tokens,
+		usage.Cost,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record api key usage: %w", err)
+	}
+
+	_, err = executor.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, usage.KeyID, usedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update api key last use: %w", err)
+	}
+
+	return nil
+}
+
+// GetUsage retrieves a key's daily usage between two days, oldest first
+func (r *APIKeyRepository) GetUsage(ctx context.Context, keyID uuid.UUID, start, end time.Time) ([]*models.APIKeyUsage, error) {
+	query := `
+		SELECT key_id, day, requests, failed_requests, tokens, cost
+		FROM api_key_usage
+		WHERE key_id = $1 AND day >= $2 AND day <= $3
+		ORDER BY day
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, keyID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query api key usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []*models.APIKeyUsage
+	for rows.Next() {
+		u := &models.APIKeyUsage{}
+		if err := rows.Scan(&u.KeyID, &u.Day, &u.Requests, &u.FailedRequests, &u.Tokens, &u.Cost); err != nil {
+			return nil, fmt.Errorf("failed to scan api key usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api key usage rows: %w", err)
+	}
+
+	return usage, nil
+}
+
+// RevokeByCreator revokes all active keys a user created within an
+// organization and returns how many were revoked
+func (r *APIKeyRepository) RevokeByCreator(ctx context.Context, orgID, userID uuid.UUID, at time.Time) (int64, error) {
+	query := `
+		UPDATE api_keys
+		SET revoked_at = $3
+		WHERE org_id = $1 AND created_by = $2 AND revoked_at IS NULL
+		  AND (expires_at IS NULL OR expires_at > $3)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, orgID, userID, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke api keys: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	r.logger.Debug("api keys revoked", zap.String("org_id", orgID.String()), zap.String("created_by", userID.String()), zap.Int64("count", rowsAffected))
+	return rowsAffected, nil
+}
+
+// WithTx returns a new repository instance bound to the transaction
+func (r *APIKeyRepository) WithTx(tx repositories.Transaction) repositories.APIKeyRepository {
+	return &APIKeyRepository{
+		db:     r.db,
+		logger: r.logger,
+	}
+}
+
+// scanAPIKey scans an API key row
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	err := row.Scan(
+		&key.ID,
+		&key.OrgID,
+		&key.AppID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&key.Scopes,
+		&key.CreatedBy,
+		&key.CreatedAt,
+		&key.ExpiresAt,
+		&key.RevokedAt,
+		&key.LastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/upb/llm-control-plane/backend/models"
@@ -28,8 +29,8 @@ func NewApplicationRepository(db *DB, logger *zap.Logger) repositories.Applicati
 // Create creates a new application
 func (r *ApplicationRepository) Create(ctx context.Context, app *models.Application) error {
 	query := `
-		INSERT INTO applications (id, org_id, name, api_key_hash, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO applications (id, org_id, name, api_key_hash, webhook_url, webhook_secret, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 
 	executor := GetExecutor(ctx, r.db)
@@ -38,6 +39,8 @@ func (r *ApplicationRepository) Create(ctx context.Context, app *models.Applicat
 		app.OrgID,
 		app.Name,
 		app.APIKeyHash,
+		app.WebhookURL,
+		app.WebhookSecret,
 		app.CreatedAt,
 		app.UpdatedAt,
 	)
@@ -50,25 +53,37 @@ func (r *ApplicationRepository) Create(ctx context.Context, app *models.Applicat
 	return nil
 }
 
-// GetByID retrieves an application by ID
+// GetByID retrieves an application by ID, unless it is deleted
 func (r *ApplicationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Application, error) {
 	query := `
-		SELECT id, org_id, name, api_key_hash, created_at, updated_at
+		SELECT id, org_id, name, api_key_hash, webhook_url, webhook_secret, created_at, updated_at, deleted_at
 		FROM applications
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	executor := GetExecutor(ctx, r.db)
-	app := &models.Application{}
+	app, err := scanApplication(executor.QueryRowContext(ctx, query, id))
 
-	err := executor.QueryRowContext(ctx, query, id).Scan(
-		&app.ID,
-		&app.OrgID,
-		&app.Name,
-		&app.APIKeyHash,
-		&app.CreatedAt,
-		&app.UpdatedAt,
-	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("application not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get application: %w", err)
+	}
+
+	return app, nil
+}
+
+// GetByOrgAndID retrieves an application by ID within an organization
+func (r *ApplicationRepository) GetByOrgAndID(ctx context.Context, orgID, id uuid.UUID) (*models.Application, error) {
+	query := `
+		SELECT id, org_id, name, api_key_hash, webhook_url, webhook_secret, created_at, updated_at, deleted_at
+		FROM applications
+		WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	app, err := scanApplication(executor.QueryRowContext(ctx, query, id, orgID))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -83,22 +98,13 @@ func (r *ApplicationRepository) GetByID(ctx context.Context, id uuid.UUID) (*mod
 // GetByAPIKeyHash retrieves an application by API key hash
 func (r *ApplicationRepository) GetByAPIKeyHash(ctx context.Context, apiKeyHash string) (*models.Application, error) {
 	query := `
-		SELECT id, org_id, name, api_key_hash, created_at, updated_at
+		SELECT id, org_id, name, api_key_hash, webhook_url, webhook_secret, created_at, updated_at, deleted_at
 		FROM applications
-		WHERE api_key_hash = $1
+		WHERE api_key_hash = $1 AND deleted_at IS NULL
 	`
 
 	executor := GetExecutor(ctx, r.db)
-	app := &models.Application{}
-
-	err := executor.QueryRowContext(ctx, query, apiKeyHash).Scan(
-		&app.ID,
-		&app.OrgID,
-		&app.Name,
-		&app.APIKeyHash,
-		&app.CreatedAt,
-		&app.UpdatedAt,
-	)
+	app, err := scanApplication(executor.QueryRowContext(ctx, query, apiKeyHash))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -110,12 +116,12 @@ func (r *ApplicationRepository) GetByAPIKeyHash(ctx context.Context, apiKeyHash
 	return app, nil
 }
 
-// GetByOrgID retrieves all applications for an organization
+// GetByOrgID retrieves the applications of an organization that are not deleted
 func (r *ApplicationRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID) ([]*models.Application, error) {
 	query := `
-		SELECT id, org_id, name, api_key_hash, created_at, updated_at
+		SELECT id, org_id, name, api_key_hash, webhook_url, webhook_secret, created_at, updated_at, deleted_at
 		FROM applications
-		WHERE org_id = $1
+		WHERE org_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
@@ -128,15 +134,7 @@ func (r *ApplicationRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID)
 
 	var apps []*models.Application
 	for rows.Next() {
-		app := &models.Application{}
-		err := rows.Scan(
-			&app.ID,
-			&app.OrgID,
-			&app.Name,
-			&app.APIKeyHash,
-			&app.CreatedAt,
-			&app.UpdatedAt,
-		)
+		app, err := scanApplication(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan application: %w", err)
 		}
@@ -156,8 +154,10 @@ func (r *ApplicationRepository) Update(ctx context.Context, app *models.Applicat
 		UPDATE applications
 		SET name = $2,
 		    api_key_hash = $3,
-		    updated_at = $4
-		WHERE id = $1
+		    webhook_url = $4,
+		    webhook_secret = $5,
+		    updated_at = $6
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	executor := GetExecutor(ctx, r.db)
@@ -165,6 +165,8 @@ func (r *ApplicationRepository) Update(ctx context.Context, app *models.Applicat
 		app.ID,
 		app.Name,
 		app.APIKeyHash,
+		app.WebhookURL,
+		app.WebhookSecret,
 		app.UpdatedAt,
 	)
 
@@ -185,7 +187,7 @@ func (r *ApplicationRepository) Update(ctx context.Context, app *models.Applicat
 	return nil
 }
 
-// Delete deletes an application
+// Delete permanently deletes an application
 func (r *ApplicationRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM applications WHERE id = $1`
 
@@ -208,6 +210,100 @@ func (r *ApplicationRepository) Delete(ctx context.Context, id uuid.UUID) error
 	return nil
 }
 
+// GetDeleted retrieves a deleted application by ID
+func (r *ApplicationRepository) GetDeleted(ctx context.Context, id uuid.UUID) (*models.Application, error) {
+	query := `
+		SELECT id, org_id, name, api_key_hash, webhook_url, webhook_secret, created_at, updated_at, deleted_at
+		FROM applications
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	app, err := scanApplication(executor.QueryRowContext(ctx, query, id))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("deleted application not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get application: %w", err)
+	}
+
+	return app, nil
+}
+
+// SoftDelete marks an application deleted
+func (r *ApplicationRepository) SoftDelete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	query := `UPDATE applications SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, id, deletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to delete application: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("application not found: %s", id)
+	}
+
+	r.logger.Debug("application soft-deleted", zap.String("id", id.String()))
+	return nil
+}
+
+// SoftDeleteByOrg marks the applications of an organization deleted
+func (r *ApplicationRepository) SoftDeleteByOrg(ctx context.Context, orgID uuid.UUID, deletedAt time.Time) error {
+	query := `UPDATE applications SET deleted_at = $2 WHERE org_id = $1 AND deleted_at IS NULL`
+
+	executor := GetExecutor(ctx, r.db)
+	if _, err := executor.ExecContext(ctx, query, orgID, deletedAt); err != nil {
+		return fmt.Errorf("failed to delete applications: %w", err)
+	}
+
+	r.logger.Debug("applications soft-deleted", zap.String("org_id", orgID.String()))
+	return nil
+}
+
+// Restore clears the deletion of an application
+func (r *ApplicationRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE applications SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore application: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("deleted application not found: %s", id)
+	}
+
+	r.logger.Debug("application restored", zap.String("id", id.String()))
+	return nil
+}
+
+// RestoreByOrg clears the deletion of the applications of an organization
+// deleted at deletedAt, along with the organization
+func (r *ApplicationRepository) RestoreByOrg(ctx context.Context, orgID uuid.UUID, deletedAt time.Time) error {
+	query := `UPDATE applications SET deleted_at = NULL WHERE org_id = $1 AND deleted_at = $2`
+
+	executor := GetExecutor(ctx, r.db)
+	if _, err := executor.ExecContext(ctx, query, orgID, deletedAt); err != nil {
+		return fmt.Errorf("failed to restore applications: %w", err)
+	}
+
+	r.logger.Debug("applications restored", zap.String("org_id", orgID.String()))
+	return nil
+}
+
 // WithTx returns a new repository instance bound to the transaction
 func (r *ApplicationRepository) WithTx(tx repositories.Transaction) repositories.ApplicationRepository {
 	return &ApplicationRepository{
@@ -215,3 +311,23 @@ func (r *ApplicationRepository) WithTx(tx repositories.Transaction) repositories
 		logger: r.logger,
 	}
 }
+
+// scanApplication scans an application row
+func scanApplication(row rowScanner) (*models.Application, error) {
+	app := &models.Application{}
+	err := row.Scan(
+		&app.ID,
+		&app.OrgID,
+		&app.Name,
+		&app.APIKeyHash,
+		&app.WebhookURL,
+		&app.WebhookSecret,
+		&app.CreatedAt,
+		&app.UpdatedAt,
+		&app.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return app, nil
+}
@@ -0,0 +1,396 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"go.uber.org/zap"
+)
+
+// batchItemInsertSize is the number of items inserted per statement
+const batchItemInsertSize = 500
+
+// BatchRepository implements the repositories.BatchRepository interface
+type BatchRepository struct {
+	db     *DB
+	logger *zap.Logger
+}
+
+// NewBatchRepository creates a new batch repository
+func NewBatchRepository(db *DB, logger *zap.Logger) repositories.BatchRepository {
+	return &BatchRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// CreateJob stores a job with all of its items
+func (r *BatchRepository) CreateJob(ctx context.Context, job *models.BatchJob, items []*models.BatchItem) error {
+	query := `
+		INSERT INTO batch_jobs (
+			id, org_id, app_id, user_id, status, total_items,
+			completed_items, failed_items, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		job.ID,
+		job.OrgID,
+		job.AppID,
+		job.UserID,
+		job.Status,
+		job.TotalItems,
+		job.CompletedItems,
+		job.FailedItems,
+		job.CreatedAt,
+		job.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create batch job: %w", err)
+	}
+
+	for start := 0; start < len(items); start += batchItemInsertSize {
+		if err := r.insertItems(ctx, items[start:min(start+batchItemInsertSize, len(items))]); err != nil {
+			return err
+		}
+	}
+
+	r.logger.Debug("batch job created", zap.String("id", job.ID.String()), zap.Int("items", len(items)))
+	return nil
+}
+
+// insertItems inserts items with a single multi-row statement
+func (r *BatchRepository) insertItems(ctx context.Context, items []*models.BatchItem) error {
+	const columns = 8
+	var query strings.Builder
+	query.WriteString(`
+		INSERT INTO batch_items (
+			id, job_id, line_number, custom_id, request, status, available_at, updated_at
+		) VALUES `)
+
+	args := make([]interface{}, 0, len(items)*columns)
+	for i, item := range items {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(")
+		for c := 1; c <= columns; c++ {
+			if c > 1 {
+				query.WriteString(", ")
+			}
+			fmt.Fprintf(&query, "$%d", i*columns+c)
+		}
+		query.WriteString(")")
+		args = append(args, item.ID, item.JobID, item.LineNumber, item.CustomID, []byte(item.Request), item.Status, item.AvailableAt, item.UpdatedAt)
+	}
+
+	executor := GetExecutor(ctx, r.db)
+	if _, err := executor.ExecContext(ctx, query.String(), args...); err != nil {
+		return fmt.Errorf("failed to create batch items: %w", err)
+	}
+	return nil
+}
+
+// GetJob retrieves a batch job by ID
+func (r *BatchRepository) GetJob(ctx context.Context, id uuid.UUID) (*models.BatchJob, error) {
+	query := `
+		SELECT id, org_id, app_id, user_id, status, total_items, completed_items,
+		       failed_items, created_at, started_at, completed_at, updated_at
+		FROM batch_jobs
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	job, err := scanBatchJob(executor.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("batch job not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get batch job: %w", err)
+	}
+
+	return job, nil
+}
+
+// ListJobs retrieves batch jobs for an organization, newest first
+func (r *BatchRepository) ListJobs(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.BatchJob, error) {
+	query := `
+		SELECT id, org_id, app_id, user_id, status, total_items, completed_items,
+		       failed_items, created_at, started_at, completed_at, updated_at
+		FROM batch_jobs
+		WHERE org_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, orgID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*models.BatchJob
+	for rows.Next() {
+		job, err := scanBatchJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan batch job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating batch job rows: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// UpdateJobStatus sets a job's status if it is currently in one of the from statuses
+func (r *BatchRepository) UpdateJobStatus(ctx context.Context, id uuid.UUID, status models.BatchJobStatus, from ...models.BatchJobStatus) (bool, error) {
+	args := []interface{}{id, status}
+	placeholders := make([]string, len(from))
+	for i, s := range from {
+		args = append(args, s)
+		placeholders[i] = fmt.Sprintf("$%d", i+3)
+	}
+
+	query := `
+		UPDATE batch_jobs
+		SET status = $2::VARCHAR,
+		    started_at = CASE WHEN $2::VARCHAR = 'running' THEN COALESCE(started_at, NOW()) ELSE started_at END,
+		    completed_at = CASE WHEN $2::VARCHAR IN ('completed', 'cancelled') THEN NOW() ELSE completed_at END,
+		    updated_at = NOW()
+		WHERE id = $1 AND status IN (` + strings.Join(placeholders, ", ") + `)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to update batch job status: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// RefreshJobProgress recounts a job's finished items and completes the job once none remain
+func (r *BatchRepository) RefreshJobProgress(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE batch_jobs j
+		SET completed_items = c.completed,
+		    failed_items = c.failed,
+		    status = CASE WHEN j.status = 'running' AND c.completed + c.failed >= j.total_items
+		                  THEN 'completed' ELSE j.status END,
+		    completed_at = CASE WHEN j.status = 'running' AND c.completed + c.failed >= j.total_items
+		                        THEN NOW() ELSE j.completed_at END,
+		    updated_at = NOW()
+		FROM (
+			SELECT COUNT(*) FILTER (WHERE status = 'completed') AS completed,
+			       COUNT(*) FILTER (WHERE status = 'failed') AS failed
+			FROM batch_items
+			WHERE job_id = $1
+		) c
+		WHERE j.id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	if _, err := executor.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to refresh batch job progress: %w", err)
+	}
+	return nil
+}
+
+// ClaimItem leases the next runnable item of an active job, or returns nil if there is none
+func (r *BatchRepository) ClaimItem(ctx context.Context, lease time.Duration) (*models.BatchItem, error) {
+	query := `
+		UPDATE batch_items
+		SET status = 'running',
+		    attempts = attempts + 1,
+		    leased_until = NOW() + $1::bigint * INTERVAL '1 millisecond',
+		    updated_at = NOW()
+		WHERE id = (
+			SELECT i.id
+			FROM batch_items i
+			JOIN batch_jobs j ON j.id = i.job_id
+			WHERE j.status IN ('pending', 'running')
+			  AND ((i.status = 'pending' AND i.available_at <= NOW())
+			    OR (i.status = 'running' AND i.leased_until < NOW()))
+			ORDER BY j.created_at, i.line_number
+			LIMIT 1
+			FOR UPDATE OF i SKIP LOCKED
+		)
+		RETURNING id, job_id, line_number, custom_id, request, status, attempts,
+		          response, error_code, error_message, available_at, leased_until, updated_at
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	item, err := scanBatchItem(executor.QueryRowContext(ctx, query, lease.Milliseconds()))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim batch item: %w", err)
+	}
+
+	return item, nil
+}
+
+// UpdateItem stores the outcome of an item and releases its lease. It fails if
+// the item has been claimed again since it was claimed by the caller.
+func (r *BatchRepository) UpdateItem(ctx context.Context, item *models.BatchItem) error {
+	query := `
+		UPDATE batch_items
+		SET status = $2,
+		    response = $3,
+		    error_code = $4,
+		    error_message = $5,
+		    available_at = $6,
+		    leased_until = $7,
+		    updated_at = $8
+		WHERE id = $1 AND attempts = $9
+	`
+
+	var response []byte
+	if len(item.Response) > 0 {
+		response = item.Response
+	}
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query,
+		item.ID,
+		item.Status,
+		response,
+		item.ErrorCode,
+		item.ErrorMessage,
+		item.AvailableAt,
+		item.LeasedUntil,
+		item.UpdatedAt,
+		item.Attempts,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update batch item: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	// Another worker claimed the item after this worker's lease expired
+	if rowsAffected == 0 {
+		return fmt.Errorf("batch item lease lost: %s", item.ID)
+	}
+
+	return nil
+}
+
+// ListItems retrieves up to limit items of a job after a line number, in line order
+func (r *BatchRepository) ListItems(ctx context.Context, jobID uuid.UUID, afterLine, limit int) ([]*models.BatchItem, error) {
+	query := `
+		SELECT id, job_id, line_number, custom_id, request, status, attempts,
+		       response, error_code, error_message, available_at, leased_until, updated_at
+		FROM batch_items
+		WHERE job_id = $1 AND line_number > $2
+		ORDER BY line_number ASC
+		LIMIT $3
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, jobID, afterLine, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query batch items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []*models.BatchItem
+	for rows.Next() {
+		item, err := scanBatchItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan batch item: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating batch item rows: %w", err)
+	}
+
+	return items, nil
+}
+
+// WithTx returns a new repository instance bound to the transaction
+func (r *BatchRepository) WithTx(tx repositories.Transaction) repositories.BatchRepository {
+	return &BatchRepository{
+		db:     r.db,
+		logger: r.logger,
+	}
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanBatchJob scans a batch job row
+func scanBatchJob(row rowScanner) (*models.BatchJob, error) {
+	job := &models.BatchJob{}
+	err := row.Scan(
+		&job.ID,
+		&job.OrgID,
+		&job.AppID,
+		&job.UserID,
+		&job.Status,
+		&job.TotalItems,
+		&job.CompletedItems,
+		&job.FailedItems,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.CompletedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// scanBatchItem scans a batch item row
+func scanBatchItem(row rowScanner) (*models.BatchItem, error) {
+	item := &models.BatchItem{}
+	var request, response []byte
+	err := row.Scan(
+		&item.ID,
+		&item.JobID,
+		&item.LineNumber,
+		&item.CustomID,
+		&request,
+		&item.Status,
+		&item.Attempts,
+		&response,
+		&item.ErrorCode,
+		&item.ErrorMessage,
+		&item.AvailableAt,
+		&item.LeasedUntil,
+		&item.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	item.Request = request
+	item.Response = response
+	return item, nil
+}
@@ -8,6 +8,7 @@ import (
 
 	_ "github.com/lib/pq" // PostgreSQL driver
 	"github.com/upb/llm-control-plane/backend/config"
+	"github.com/upb/llm-control-plane/backend/models"
 	"go.uber.org/zap"
 )
 
@@ -108,7 +109,8 @@ func (db *DB) InitSchema(ctx context.Context) error {
 			name VARCHAR(255) NOT NULL,
 			slug VARCHAR(100) NOT NULL UNIQUE,
 			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			deleted_at TIMESTAMP
 		);
 
 		-- Applications table
@@ -117,8 +119,11 @@ func (db *DB) InitSchema(ctx context.Context) error {
 			org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
 			name VARCHAR(255) NOT NULL,
 			api_key_hash VARCHAR(255) NOT NULL UNIQUE,
+			webhook_url TEXT,
+			webhook_secret VARCHAR(255),
 			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			deleted_at TIMESTAMP
 		);
 
 		-- Users table
@@ -128,8 +133,11 @@ func (db *DB) InitSchema(ctx context.Context) error {
 			cognito_sub VARCHAR(255) NOT NULL UNIQUE,
 			org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
 			role VARCHAR(50) NOT NULL,
+			external_id VARCHAR(255) NOT NULL DEFAULT '',
+			display_name VARCHAR(255) NOT NULL DEFAULT '',
 			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			deactivated_at TIMESTAMP,
 			UNIQUE(email, org_id)
 		);
 
@@ -170,6 +178,70 @@ func (db *DB) InitSchema(ctx context.Context) error {
 			error_message TEXT
 		);
 
+		-- Experiments table
+		CREATE TABLE IF NOT EXISTS experiments (
+			id UUID PRIMARY KEY,
+			org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			app_id UUID REFERENCES applications(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			target_model VARCHAR(100) NOT NULL,
+			arms JSONB NOT NULL,
+			status VARCHAR(20) NOT NULL,
+			started_at TIMESTAMP,
+			ended_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		-- Prompt templates tables
+		CREATE TABLE IF NOT EXISTS prompt_templates (
+			id UUID PRIMARY KEY,
+			org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			active_version INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(org_id, name)
+		);
+
+		CREATE TABLE IF NOT EXISTS prompt_template_versions (
+			id UUID PRIMARY KEY,
+			template_id UUID NOT NULL REFERENCES prompt_templates(id) ON DELETE CASCADE,
+			version INTEGER NOT NULL,
+			messages JSONB NOT NULL,
+			variables JSONB NOT NULL,
+			created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(template_id, version)
+		);
+
+		CREATE TABLE IF NOT EXISTS prompt_template_pins (
+			template_id UUID NOT NULL REFERENCES prompt_templates(id) ON DELETE CASCADE,
+			app_id UUID NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+			version INTEGER NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (template_id, app_id)
+		);
+
+		-- RAG documents table
+		CREATE TABLE IF NOT EXISTS rag_documents (
+			org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			index_name VARCHAR(255) NOT NULL,
+			document_id VARCHAR(255) NOT NULL,
+			content_type VARCHAR(50) NOT NULL,
+			content_hash VARCHAR(64) NOT NULL,
+			metadata JSONB NOT NULL DEFAULT '{}',
+			chunk_count INTEGER NOT NULL DEFAULT 0,
+			embedding_model VARCHAR(100) NOT NULL,
+			tokens_used INTEGER NOT NULL DEFAULT 0,
+			cost DECIMAL(10, 6) NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (org_id, index_name, document_id)
+		);
+
 		-- Inference requests table
 		CREATE TABLE IF NOT EXISTS inference_requests (
 			id UUID PRIMARY KEY,
@@ -187,11 +259,201 @@ func (db *DB) InitSchema(ctx context.Context) error {
 			status VARCHAR(50) NOT NULL,
 			error_message TEXT,
 			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			completed_at TIMESTAMP
+			completed_at TIMESTAMP,
+			experiment_id UUID REFERENCES experiments(id) ON DELETE SET NULL,
+			experiment_arm VARCHAR(100),
+			feedback_score SMALLINT,
+			template_id UUID REFERENCES prompt_templates(id) ON DELETE SET NULL,
+			template_version INTEGER,
+			response TEXT,
+			finish_reason VARCHAR(50),
+			error_code VARCHAR(100)
+		);
+
+		-- Conversation threads tables
+		CREATE TABLE IF NOT EXISTS conversations (
+			id UUID PRIMARY KEY,
+			org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			app_id UUID REFERENCES applications(id) ON DELETE SET NULL,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			title VARCHAR(255) NOT NULL DEFAULT '',
+			model VARCHAR(100) NOT NULL,
+			system_prompt TEXT NOT NULL DEFAULT '',
+			message_count INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS conversation_messages (
+			id UUID PRIMARY KEY,
+			conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+			role VARCHAR(20) NOT NULL,
+			content TEXT NOT NULL,
+			tokens INTEGER NOT NULL DEFAULT 0,
+			inference_id UUID,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		-- Batch inference tables
+		CREATE TABLE IF NOT EXISTS batch_jobs (
+			id UUID PRIMARY KEY,
+			org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			app_id UUID NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+			user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+			status VARCHAR(50) NOT NULL,
+			total_items INTEGER NOT NULL,
+			completed_items INTEGER NOT NULL DEFAULT 0,
+			failed_items INTEGER NOT NULL DEFAULT 0,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			started_at TIMESTAMP,
+			completed_at TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		CREATE TABLE IF NOT EXISTS batch_items (
+			id UUID PRIMARY KEY,
+			job_id UUID NOT NULL REFERENCES batch_jobs(id) ON DELETE CASCADE,
+			line_number INTEGER NOT NULL,
+			custom_id VARCHAR(255) NOT NULL,
+			request JSONB NOT NULL,
+			status VARCHAR(50) NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			response JSONB,
+			error_code VARCHAR(100),
+			error_message TEXT,
+			available_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			leased_until TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(job_id, line_number),
+			UNIQUE(job_id, custom_id)
+		);
+
+		-- Application API keys table
+		CREATE TABLE IF NOT EXISTS api_keys (
+			id UUID PRIMARY KEY,
+			org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			app_id UUID NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			prefix VARCHAR(20) NOT NULL,
+			key_hash VARCHAR(255) NOT NULL UNIQUE,
+			scopes JSONB NOT NULL DEFAULT '{}',
+			created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP,
+			revoked_at TIMESTAMP,
+			last_used_at TIMESTAMP
+		);
+
+		-- API key usage table
+		CREATE TABLE IF NOT EXISTS api_key_usage (
+			key_id UUID NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+			day DATE NOT NULL,
+			requests INTEGER NOT NULL DEFAULT 0,
+			failed_requests INTEGER NOT NULL DEFAULT 0,
+			tokens BIGINT NOT NULL DEFAULT 0,
+			cost DECIMAL(12, 6) NOT NULL DEFAULT 0,
+			PRIMARY KEY (key_id, day)
+		);
+
+		-- Roles table (built-in roles have no organization)
+		CREATE TABLE IF NOT EXISTS roles (
+			id UUID PRIMARY KEY,
+			org_id UUID REFERENCES organizations(id) ON DELETE CASCADE,
+			name VARCHAR(50) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			permissions JSONB NOT NULL DEFAULT '[]',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		-- Sessions table (the session cookie carries an opaque token; only its hash is stored)
+		CREATE TABLE IF NOT EXISTS sessions (
+			id UUID PRIMARY KEY,
+			token_hash VARCHAR(255) NOT NULL UNIQUE,
+			org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			app_id UUID,
+			user_id UUID,
+			provider VARCHAR(100) NOT NULL,
+			issuer VARCHAR(500) NOT NULL DEFAULT '',
+			subject VARCHAR(255) NOT NULL,
+			email VARCHAR(255) NOT NULL DEFAULT '',
+			email_verified BOOLEAN NOT NULL DEFAULT false,
+			roles JSONB NOT NULL DEFAULT '[]',
+			refresh_token TEXT NOT NULL DEFAULT '',
+			identity_expires_at TIMESTAMP NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			last_seen_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			revoked_at TIMESTAMP,
+			user_agent VARCHAR(500) NOT NULL DEFAULT '',
+			ip_address VARCHAR(45) NOT NULL DEFAULT ''
+		);
+
+		-- Service accounts table (machine identities; only the hash of the client secret is stored)
+		CREATE TABLE IF NOT EXISTS service_accounts (
+			id UUID PRIMARY KEY,
+			org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			name VARCHAR(255) NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			client_id VARCHAR(100) NOT NULL UNIQUE,
+			secret_hash VARCHAR(255) NOT NULL,
+			roles JSONB NOT NULL DEFAULT '[]',
+			scopes JSONB NOT NULL DEFAULT '[]',
+			created_by UUID,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			disabled_at TIMESTAMP
+		);
+
+		-- SCIM configurations table (only the hash of the bearer token is stored)
+		CREATE TABLE IF NOT EXISTS scim_configs (
+			org_id UUID PRIMARY KEY REFERENCES organizations(id) ON DELETE CASCADE,
+			token_hash VARCHAR(255) NOT NULL DEFAULT '',
+			token_created_at TIMESTAMP,
+			group_roles JSONB NOT NULL DEFAULT '{}',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		-- SCIM groups and their members
+		CREATE TABLE IF NOT EXISTS scim_groups (
+			id UUID PRIMARY KEY,
+			org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			display_name VARCHAR(255) NOT NULL,
+			external_id VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(org_id, display_name)
+		);
+
+		CREATE TABLE IF NOT EXISTS scim_group_members (
+			group_id UUID NOT NULL REFERENCES scim_groups(id) ON DELETE CASCADE,
+			user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+			PRIMARY KEY (group_id, user_id)
+		);
+
+		-- Shadow responses table
+		CREATE TABLE IF NOT EXISTS shadow_responses (
+			id UUID PRIMARY KEY,
+			inference_id UUID NOT NULL,
+			org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+			app_id UUID NOT NULL REFERENCES applications(id) ON DELETE CASCADE,
+			provider VARCHAR(100) NOT NULL,
+			model VARCHAR(100) NOT NULL,
+			status VARCHAR(50) NOT NULL,
+			response TEXT,
+			prompt_tokens INTEGER NOT NULL DEFAULT 0,
+			completion_tokens INTEGER NOT NULL DEFAULT 0,
+			latency_ms INTEGER NOT NULL DEFAULT 0,
+			cost DECIMAL(10, 6) NOT NULL DEFAULT 0,
+			error_message TEXT,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 		);
 
 		-- Indexes for performance
 		CREATE INDEX IF NOT EXISTS idx_applications_org_id ON applications(org_id);
+		CREATE INDEX IF NOT EXISTS idx_organizations_deleted_at ON organizations(deleted_at) WHERE deleted_at IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_applications_deleted_at ON applications(deleted_at) WHERE deleted_at IS NOT NULL;
 		CREATE INDEX IF NOT EXISTS idx_users_org_id ON users(org_id);
 		CREATE INDEX IF NOT EXISTS idx_users_cognito_sub ON users(cognito_sub);
 		CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
@@ -215,16 +477,92 @@ func (db *DB) InitSchema(ctx context.Context) error {
 		CREATE INDEX IF NOT EXISTS idx_inference_requests_status ON inference_requests(status);
 		CREATE INDEX IF NOT EXISTS idx_inference_requests_created_at ON inference_requests(created_at);
 		CREATE INDEX IF NOT EXISTS idx_inference_requests_request_id ON inference_requests(request_id);
+		CREATE INDEX IF NOT EXISTS idx_inference_requests_experiment ON inference_requests(experiment_id, experiment_arm);
+
+		CREATE INDEX IF NOT EXISTS idx_experiments_org_id ON experiments(org_id);
+		CREATE INDEX IF NOT EXISTS idx_shadow_responses_inference_id ON shadow_responses(inference_id);
+		CREATE INDEX IF NOT EXISTS idx_prompt_templates_org_id ON prompt_templates(org_id);
+		CREATE INDEX IF NOT EXISTS idx_inference_requests_template ON inference_requests(template_id, template_version);
+		CREATE INDEX IF NOT EXISTS idx_conversations_org_user ON conversations(org_id, user_id, updated_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_conversation_messages_conversation ON conversation_messages(conversation_id, created_at);
+		CREATE INDEX IF NOT EXISTS idx_batch_jobs_org_id ON batch_jobs(org_id, created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_batch_items_claimable ON batch_items(status, available_at);
+		CREATE INDEX IF NOT EXISTS idx_api_keys_app_id ON api_keys(app_id, created_at DESC);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_builtin_name ON roles(name) WHERE org_id IS NULL;
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_org_name ON roles(org_id, name) WHERE org_id IS NOT NULL;
+		CREATE INDEX IF NOT EXISTS idx_sessions_subject ON sessions(org_id, subject, created_at DESC);
+		CREATE INDEX IF NOT EXISTS idx_service_accounts_org_id ON service_accounts(org_id, created_at DESC);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_scim_configs_token_hash ON scim_configs(token_hash) WHERE token_hash <> '';
+		CREATE INDEX IF NOT EXISTS idx_scim_group_members_user_id ON scim_group_members(user_id);
+		CREATE INDEX IF NOT EXISTS idx_users_external_id ON users(org_id, external_id) WHERE external_id <> '';
+		CREATE INDEX IF NOT EXISTS idx_api_keys_created_by ON api_keys(created_by) WHERE revoked_at IS NULL;
+		CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(org_id, user_id) WHERE user_id IS NOT NULL;
 	`
 
 	if _, err := db.ExecContext(ctx, schema); err != nil {
 		return fmt.Errorf("failed to initialize schema: %w", err)
 	}
 
+	// RAG chunks need pgvector, which is installed by migration 007 and not
+	// here; without it the rest of the schema works and RAG stays disabled
+	vector, err := db.VectorAvailable(ctx)
+	if err != nil {
+		return err
+	}
+	if vector {
+		ragSchema := `
+			CREATE TABLE IF NOT EXISTS rag_chunks (
+				id UUID PRIMARY KEY,
+				org_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+				index_name VARCHAR(255) NOT NULL,
+				document_id VARCHAR(255) NOT NULL,
+				chunk_index INTEGER NOT NULL,
+				content TEXT NOT NULL,
+				metadata JSONB NOT NULL DEFAULT '{}',
+				embedding vector NOT NULL,
+				created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				UNIQUE(org_id, index_name, document_id, chunk_index)
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_rag_chunks_org_index ON rag_chunks(org_id, index_name);
+		`
+		if _, err := db.ExecContext(ctx, ragSchema); err != nil {
+			return fmt.Errorf("failed to initialize RAG schema: %w", err)
+		}
+	} else {
+		db.logger.Warn("pgvector extension not installed, skipping the RAG chunks table")
+	}
+
+	// Seed the built-in roles
+	for _, builtIn := range models.BuiltInRoles {
+		role, err := models.NewRole(nil, string(builtIn.Name), builtIn.Description, builtIn.Permissions)
+		if err != nil {
+			return fmt.Errorf("failed to seed role %s: %w", builtIn.Name, err)
+		}
+		_, err = db.ExecContext(ctx, `
+			INSERT INTO roles (id, name, description, permissions, created_at, updated_at)
+			SELECT $1, $2, $3, $4, $5, $5
+			WHERE NOT EXISTS (SELECT 1 FROM roles WHERE org_id IS NULL AND name = $2)
+		`, role.ID, role.Name, role.Description, role.Permissions, role.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to seed role %s: %w", builtIn.Name, err)
+		}
+	}
+
 	db.logger.Info("database schema initialized successfully")
 	return nil
 }
 
+// VectorAvailable reports whether the pgvector extension is installed
+func (db *DB) VectorAvailable(ctx context.Context) (bool, error) {
+	var available bool
+	err := db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'vector')").Scan(&available)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for pgvector: %w", err)
+	}
+	return available, nil
+}
+
 // InitAuditSchema initializes the audit database schema (audit_logs only, no FK).
 // Use for the separate audit database when DATABASE_URL_AUDIT is set.
 func (db *DB) InitAuditSchema(ctx context.Context) error {
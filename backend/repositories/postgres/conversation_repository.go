@@ -0,0 +1,270 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"go.uber.org/zap"
+)
+
+// ConversationRepository implements the repositories.ConversationRepository interface
+type ConversationRepository struct {
+	db     *DB
+	logger *zap.Logger
+}
+
+// NewConversationRepository creates a new conversation repository
+func NewConversationRepository(db *DB, logger *zap.Logger) repositories.ConversationRepository {
+	return &ConversationRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new conversation
+func (r *ConversationRepository) Create(ctx context.Context, conversation *models.Conversation) error {
+	query := `
+		INSERT INTO conversations (
+			id, org_id, app_id, user_id, title, model, system_prompt,
+			message_count, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		conversation.ID,
+		conversation.OrgID,
+		conversation.AppID,
+		conversation.UserID,
+		conversation.Title,
+		conversation.Model,
+		conversation.SystemPrompt,
+		conversation.MessageCount,
+		conversation.CreatedAt,
+		conversation.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	r.logger.Debug("conversation created", zap.String("id", conversation.ID.String()))
+	return nil
+}
+
+// GetByID retrieves a conversation by ID
+func (r *ConversationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Conversation, error) {
+	query := `
+		SELECT id, org_id, app_id, user_id, title, model, system_prompt,
+		       message_count, created_at, updated_at
+		FROM conversations
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	conversation := &models.Conversation{}
+
+	err := executor.QueryRowContext(ctx, query, id).Scan(
+		&conversation.ID,
+		&conversation.OrgID,
+		&conversation.AppID,
+		&conversation.UserID,
+		&conversation.Title,
+		&conversation.Model,
+		&conversation.SystemPrompt,
+		&conversation.MessageCount,
+		&conversation.CreatedAt,
+		&conversation.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("conversation not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	return conversation, nil
+}
+
+// ListByUser retrieves a user's conversations in an organization, most recently active first
+func (r *ConversationRepository) ListByUser(ctx context.Context, orgID, userID uuid.UUID, limit, offset int) ([]*models.Conversation, error) {
+	query := `
+		SELECT id, org_id, app_id, user_id, title, model, system_prompt,
+		       message_count, created_at, updated_at
+		FROM conversations
+		WHERE org_id = $1 AND user_id = $2
+		ORDER BY updated_at DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, orgID, userID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversations: %w", err)
+	}
+	defer rows.Close()
+
+	var conversations []*models.Conversation
+	for rows.Next() {
+		conversation := &models.Conversation{}
+		err := rows.Scan(
+			&conversation.ID,
+			&conversation.OrgID,
+			&conversation.AppID,
+			&conversation.UserID,
+			&conversation.Title,
+			&conversation.Model,
+			&conversation.SystemPrompt,
+			&conversation.MessageCount,
+			&conversation.CreatedAt,
+			&conversation.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan conversation: %w", err)
+		}
+		conversations = append(conversations, conversation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating conversation rows: %w", err)
+	}
+
+	return conversations, nil
+}
+
+// Update updates a conversation's title, message count and activity time
+func (r *ConversationRepository) Update(ctx context.Context, conversation *models.Conversation) error {
+	query := `
+		UPDATE conversations
+		SET title = $2,
+		    message_count = $3,
+		    updated_at = $4
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query,
+		conversation.ID,
+		conversation.Title,
+		conversation.MessageCount,
+		conversation.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update conversation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("conversation not found: %s", conversation.ID)
+	}
+
+	return nil
+}
+
+// Delete deletes a conversation and its messages
+func (r *ConversationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM conversations WHERE id = $1`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete conversation: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("conversation not found: %s", id)
+	}
+
+	r.logger.Debug("conversation deleted", zap.String("id", id.String()))
+	return nil
+}
+
+// AddMessage appends a message to a conversation
+func (r *ConversationRepository) AddMessage(ctx context.Context, message *models.ConversationMessage) error {
+	query := `
+		INSERT INTO conversation_messages (
+			id, conversation_id, role, content, tokens, inference_id, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		message.ID,
+		message.ConversationID,
+		message.Role,
+		message.Content,
+		message.Tokens,
+		message.InferenceID,
+		message.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to add conversation message: %w", err)
+	}
+
+	return nil
+}
+
+// ListMessages retrieves all messages of a conversation, oldest first
+func (r *ConversationRepository) ListMessages(ctx context.Context, conversationID uuid.UUID) ([]*models.ConversationMessage, error) {
+	query := `
+		SELECT id, conversation_id, role, content, tokens, inference_id, created_at
+		FROM conversation_messages
+		WHERE conversation_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversation messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*models.ConversationMessage
+	for rows.Next() {
+		message := &models.ConversationMessage{}
+		err := rows.Scan(
+			&message.ID,
+			&message.ConversationID,
+			&message.Role,
+			&message.Content,
+			&message.Tokens,
+			&message.InferenceID,
+			&message.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan conversation message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating conversation message rows: %w", err)
+	}
+
+	return messages, nil
+}
+
+// WithTx returns a new repository instance bound to the transaction
+func (r *ConversationRepository) WithTx(tx repositories.Transaction) repositories.ConversationRepository {
+	return &ConversationRepository{
+		db:     r.db,
+		logger: r.logger,
+	}
+}
@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"go.uber.org/zap"
+)
+
+// ExperimentRepository implements the repositories.ExperimentRepository interface
+type ExperimentRepository struct {
+	db     *DB
+	logger *zap.Logger
+}
+
+// NewExperimentRepository creates a new experiment repository
+func NewExperimentRepository(db *DB, logger *zap.Logger) repositories.ExperimentRepository {
+	return &ExperimentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new experiment
+func (r *ExperimentRepository) Create(ctx context.Context, experiment *models.Experiment) error {
+	query := `
+		INSERT INTO experiments (
+			id, org_id, app_id, name, target_model, arms, status,
+			started_at, ended_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		experiment.ID,
+		experiment.OrgID,
+		experiment.AppID,
+		experiment.Name,
+		experiment.TargetModel,
+		experiment.Arms,
+		experiment.Status,
+		experiment.StartedAt,
+		experiment.EndedAt,
+		experiment.CreatedAt,
+		experiment.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create experiment: %w", err)
+	}
+
+	r.logger.Debug("experiment created", zap.String("id", experiment.ID.String()), zap.String("name", experiment.Name))
+	return nil
+}
+
+// GetByID retrieves an experiment by ID
+func (r *ExperimentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Experiment, error) {
+	query := `
+		SELECT id, org_id, app_id, name, target_model, arms, status,
+		       started_at, ended_at, created_at, updated_at
+		FROM experiments
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	experiment := &models.Experiment{}
+
+	err := executor.QueryRowContext(ctx, query, id).Scan(
+		&experiment.ID,
+		&experiment.OrgID,
+		&experiment.AppID,
+		&experiment.Name,
+		&experiment.TargetModel,
+		&experiment.Arms,
+		&experiment.Status,
+		&experiment.StartedAt,
+		&experiment.EndedAt,
+		&experiment.CreatedAt,
+		&experiment.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("experiment not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get experiment: %w", err)
+	}
+
+	return experiment, nil
+}
+
+// GetByOrgID retrieves all experiments for an organization
+func (r *ExperimentRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID) ([]*models.Experiment, error) {
+	query := `
+		SELECT id, org_id, app_id, name, target_model, arms, status,
+		       started_at, ended_at, created_at, updated_at
+		FROM experiments
+		WHERE org_id = $1
+		ORDER BY created_at DESC
+	`
+
+	return r.queryExperiments(ctx, query, orgID)
+}
+
+// GetRunning retrieves running experiments applicable to an app and model
+func (r *ExperimentRepository) GetRunning(ctx context.Context, orgID, appID uuid.UUID, model string) ([]*models.Experiment, error) {
+	query := `
+		SELECT id, org_id, app_id, name, target_model, arms, status,
+		       started_at, ended_at, created_at, updated_at
+		FROM experiments
+		WHERE org_id = $1
+			AND (app_id = $2 OR app_id IS NULL)
+			AND target_model = $3
+			AND status = 'running'
+		ORDER BY
+			-- App-specific experiments take precedence over org-wide ones
+			CASE WHEN app_id IS NOT NULL THEN 1 ELSE 2 END,
+			started_at ASC
+	`
+
+	return r.queryExperiments(ctx, query, orgID, appID, model)
+}
+
+// Update updates an experiment
+func (r *ExperimentRepository) Update(ctx context.Context, experiment *models.Experiment) error {
+	query := `
+		UPDATE experiments
+		SET name = $2,
+		    target_model = $3,
+		    arms = $4,
+		    status = $5,
+		    started_at = $6,
+		    ended_at = $7,
+		    updated_at = $8
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query,
+		experiment.ID,
+		experiment.Name,
+		experiment.TargetModel,
+		experiment.Arms,
+		experiment.Status,
+		experiment.StartedAt,
+		experiment.EndedAt,
+		experiment.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update experiment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("experiment not found: %s", experiment.ID)
+	}
+
+	r.logger.Debug("experiment updated", zap.String("id", experiment.ID.String()))
+	return nil
+}
+
+// Delete deletes an experiment
+func (r *ExperimentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM experiments WHERE id = $1`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete experiment: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("experiment not found: %s", id)
+	}
+
+	r.logger.Debug("experiment deleted", zap.String("id", id.String()))
+	return nil
+}
+
+// WithTx returns a new repository instance bound to the transaction
+func (r *ExperimentRepository) WithTx(tx repositories.Transaction) repositories.ExperimentRepository {
+	return &ExperimentRepository{
+		db:     r.db,
+		logger: r.logger,
+	}
+}
+
+// queryExperiments is a helper method to query multiple experiments
+func (r *ExperimentRepository) queryExperiments(ctx context.Context, query string, args ...interface{}) ([]*models.Experiment, error) {
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query experiments: %w", err)
+	}
+	defer rows.Close()
+
+	var experiments []*models.Experiment
+	for rows.Next() {
+		experiment := &models.Experiment{}
+		err := rows.Scan(
+			&experiment.ID,
+			&experiment.OrgID,
+			&experiment.AppID,
+			&experiment.Name,
+			&experiment.TargetModel,
+			&experiment.Arms,
+			&experiment.Status,
+			&experiment.StartedAt,
+			&experiment.EndedAt,
+			&experiment.CreatedAt,
+			&experiment.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan experiment: %w", err)
+		}
+		experiments = append(experiments, experiment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating experiment rows: %w", err)
+	}
+
+	return experiments, nil
+}
@@ -57,6 +57,18 @@ func (f *RepositoryFactory) NewRepositories() *repositories.Repositories {
 		Policies:          NewPolicyRepository(f.db, f.logger),
 		AuditLogs:         NewAuditRepository(auditDB, f.logger),
 		InferenceRequests: NewInferenceRequestRepository(f.db, f.logger),
+		Experiments:       NewExperimentRepository(f.db, f.logger),
+		ShadowResponses:   NewShadowResponseRepository(f.db, f.logger),
+		PromptTemplates:   NewPromptTemplateRepository(f.db, f.logger),
+		RAGDocuments:      NewRAGDocumentRepository(f.db, f.logger),
+		Conversations:     NewConversationRepository(f.db, f.logger),
+		Batches:           NewBatchRepository(f.db, f.logger),
+		APIKeys:           NewAPIKeyRepository(f.db, f.logger),
+		Roles:             NewRoleRepository(f.db, f.logger),
+		Sessions:          NewSessionRepository(f.db, f.logger),
+		ServiceAccounts:   NewServiceAccountRepository(f.db, f.logger),
+		SCIMConfigs:       NewSCIMConfigRepository(f.db, f.logger),
+		SCIMGroups:        NewSCIMGroupRepository(f.db, f.logger),
 	}
 }
 
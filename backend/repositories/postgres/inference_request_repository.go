@@ -32,9 +32,12 @@ func (r *InferenceRequestRepository) Create(ctx context.Context, req *models.Inf
 		INSERT INTO inference_requests (
 			id, request_id, org_id, app_id, user_id, model, provider,
 			prompt_tokens, completion_tokens, total_tokens, cost, latency_ms,
-			status, error_message, created_at, completed_at
+			status, error_message, created_at, completed_at,
+			experiment_id, experiment_arm, template_id, template_version,
+			response, finish_reason, error_code
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+			$21, $22, $23
 		)
 	`
 
@@ -56,6 +59,13 @@ func (r *InferenceRequestRepository) Create(ctx context.Context, req *models.Inf
 		req.ErrorMessage,
 		req.CreatedAt,
 		req.CompletedAt,
+		req.ExperimentID,
+		req.ExperimentArm,
+		req.TemplateID,
+		req.TemplateVersion,
+		req.Response,
+		req.FinishReason,
+		req.ErrorCode,
 	)
 
 	if err != nil {
@@ -71,7 +81,10 @@ func (r *InferenceRequestRepository) GetByID(ctx context.Context, id uuid.UUID)
 	query := `
 		SELECT id, request_id, org_id, app_id, user_id, model, provider,
 		       prompt_tokens, completion_tokens, total_tokens, cost, latency_ms,
-		       status, error_message, created_at, completed_at
+		       status, error_message, created_at, completed_at,
+		       experiment_id, experiment_arm, feedback_score,
+		       template_id, template_version,
+		       response, finish_reason, error_code
 		FROM inference_requests
 		WHERE id = $1
 	`
@@ -96,6 +109,14 @@ func (r *InferenceRequestRepository) GetByID(ctx context.Context, id uuid.UUID)
 		&req.ErrorMessage,
 		&req.CreatedAt,
 		&req.CompletedAt,
+		&req.ExperimentID,
+		&req.ExperimentArm,
+		&req.FeedbackScore,
+		&req.TemplateID,
+		&req.TemplateVersion,
+		&req.Response,
+		&req.FinishReason,
+		&req.ErrorCode,
 	)
 
 	if err != nil {
@@ -113,7 +134,10 @@ func (r *InferenceRequestRepository) GetByRequestID(ctx context.Context, request
 	query := `
 		SELECT id, request_id, org_id, app_id, user_id, model, provider,
 		       prompt_tokens, completion_tokens, total_tokens, cost, latency_ms,
-		       status, error_message, created_at, completed_at
+		       status, error_message, created_at, completed_at,
+		       experiment_id, experiment_arm, feedback_score,
+		       template_id, template_version,
+		       response, finish_reason, error_code
 		FROM inference_requests
 		WHERE request_id = $1
 	`
@@ -138,6 +162,14 @@ func (r *InferenceRequestRepository) GetByRequestID(ctx context.Context, request
 		&req.ErrorMessage,
 		&req.CreatedAt,
 		&req.CompletedAt,
+		&req.ExperimentID,
+		&req.ExperimentArm,
+		&req.FeedbackScore,
+		&req.TemplateID,
+		&req.TemplateVersion,
+		&req.Response,
+		&req.FinishReason,
+		&req.ErrorCode,
 	)
 
 	if err != nil {
@@ -155,7 +187,10 @@ func (r *InferenceRequestRepository) GetByOrgID(ctx context.Context, orgID uuid.
 	query := `
 		SELECT id, request_id, org_id, app_id, user_id, model, provider,
 		       prompt_tokens, completion_tokens, total_tokens, cost, latency_ms,
-		       status, error_message, created_at, completed_at
+		       status, error_message, created_at, completed_at,
+		       experiment_id, experiment_arm, feedback_score,
+		       template_id, template_version,
+		       response, finish_reason, error_code
 		FROM inference_requests
 		WHERE org_id = $1
 		ORDER BY created_at DESC
@@ -170,7 +205,10 @@ func (r *InferenceRequestRepository) GetByAppID(ctx context.Context, appID uuid.
 	query := `
 		SELECT id, request_id, org_id, app_id, user_id, model, provider,
 		       prompt_tokens, completion_tokens, total_tokens, cost, latency_ms,
-		       status, error_message, created_at, completed_at
+		       status, error_message, created_at, completed_at,
+		       experiment_id, experiment_arm, feedback_score,
+		       template_id, template_version,
+		       response, finish_reason, error_code
 		FROM inference_requests
 		WHERE app_id = $1
 		ORDER BY created_at DESC
@@ -185,7 +223,10 @@ func (r *InferenceRequestRepository) GetByUserID(ctx context.Context, userID uui
 	query := `
 		SELECT id, request_id, org_id, app_id, user_id, model, provider,
 		       prompt_tokens, completion_tokens, total_tokens, cost, latency_ms,
-		       status, error_message, created_at, completed_at
+		       status, error_message, created_at, completed_at,
+		       experiment_id, experiment_arm, feedback_score,
+		       template_id, template_version,
+		       response, finish_reason, error_code
 		FROM inference_requests
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -200,7 +241,10 @@ func (r *InferenceRequestRepository) GetByStatus(ctx context.Context, orgID uuid
 	query := `
 		SELECT id, request_id, org_id, app_id, user_id, model, provider,
 		       prompt_tokens, completion_tokens, total_tokens, cost, latency_ms,
-		       status, error_message, created_at, completed_at
+		       status, error_message, created_at, completed_at,
+		       experiment_id, experiment_arm, feedback_score,
+		       template_id, template_version,
+		       response, finish_reason, error_code
 		FROM inference_requests
 		WHERE org_id = $1 AND status = $2
 		ORDER BY created_at DESC
@@ -215,7 +259,10 @@ func (r *InferenceRequestRepository) GetByDateRange(ctx context.Context, orgID u
 	query := `
 		SELECT id, request_id, org_id, app_id, user_id, model, provider,
 		       prompt_tokens, completion_tokens, total_tokens, cost, latency_ms,
-		       status, error_message, created_at, completed_at
+		       status, error_message, created_at, completed_at,
+		       experiment_id, experiment_arm, feedback_score,
+		       template_id, template_version,
+		       response, finish_reason, error_code
 		FROM inference_requests
 		WHERE org_id = $1 AND created_at >= $2 AND created_at <= $3
 		ORDER BY created_at DESC
@@ -238,7 +285,14 @@ func (r *InferenceRequestRepository) Update(ctx context.Context, req *models.Inf
 		    cost = $8,
 		    latency_ms = $9,
 		    error_message = $10,
-		    completed_at = $11
+		    completed_at = $11,
+		    response = $12,
+		    finish_reason = $13,
+		    error_code = $14,
+		    experiment_id = COALESCE($15, experiment_id),
+		    experiment_arm = COALESCE($16, experiment_arm),
+		    template_id = COALESCE($17, template_id),
+		    template_version = COALESCE($18, template_version)
 		WHERE id = $1
 	`
 
@@ -255,6 +309,13 @@ func (r *InferenceRequestRepository) Update(ctx context.Context, req *models.Inf
 		req.LatencyMs,
 		req.ErrorMessage,
 		req.CompletedAt,
+		req.Response,
+		req.FinishReason,
+		req.ErrorCode,
+		req.ExperimentID,
+		req.ExperimentArm,
+		req.TemplateID,
+		req.TemplateVersion,
 	)
 
 	if err != nil {
@@ -309,6 +370,123 @@ func (r *InferenceRequestRepository) GetMetrics(ctx context.Context, orgID uuid.
 	return metrics, nil
 }
 
+// GetExperimentMetrics retrieves aggregate metrics per arm for an experiment
+func (r *InferenceRequestRepository) GetExperimentMetrics(ctx context.Context, experimentID uuid.UUID) ([]*repositories.ArmMetrics, error) {
+	query := `
+		SELECT 
+			experiment_arm,
+			COUNT(*) as total_requests,
+			COUNT(CASE WHEN status = 'completed' THEN 1 END) as completed_requests,
+			COUNT(CASE WHEN status = 'failed' THEN 1 END) as failed_requests,
+			COALESCE(SUM(total_tokens), 0) as total_tokens,
+			COALESCE(SUM(cost), 0) as total_cost,
+			COALESCE(AVG(latency_ms), 0) as avg_latency_ms,
+			COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY latency_ms), 0) as p95_latency_ms,
+			COUNT(feedback_score) as feedback_count,
+			COUNT(CASE WHEN feedback_score > 0 THEN 1 END) as positive_feedback,
+			COUNT(CASE WHEN feedback_score < 0 THEN 1 END) as negative_feedback
+		FROM inference_requests
+		WHERE experiment_id = $1
+		GROUP BY experiment_arm
+		ORDER BY experiment_arm
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, experimentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experiment metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var metrics []*repositories.ArmMetrics
+	for rows.Next() {
+		m := &repositories.ArmMetrics{}
+		err := rows.Scan(
+			&m.Arm,
+			&m.TotalRequests,
+			&m.CompletedRequests,
+			&m.FailedRequests,
+			&m.TotalTokens,
+			&m.TotalCost,
+			&m.AvgLatencyMs,
+			&m.P95LatencyMs,
+			&m.FeedbackCount,
+			&m.PositiveFeedback,
+			&m.NegativeFeedback,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan experiment metrics: %w", err)
+		}
+		metrics = append(metrics, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating experiment metrics rows: %w", err)
+	}
+
+	return metrics, nil
+}
+
+// SetFeedback records user feedback for an inference request
+func (r *InferenceRequestRepository) SetFeedback(ctx context.Context, id uuid.UUID, score int) error {
+	query := `
+		UPDATE inference_requests
+		SET feedback_score = $2
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, id, score)
+	if err != nil {
+		return fmt.Errorf("failed to set feedback: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("inference request not found: %s", id)
+	}
+
+	return nil
+}
+
+// FailUnfinished marks pending and processing requests created before a
+// time as failed and returns how many were failed
+func (r *InferenceRequestRepository) FailUnfinished(ctx context.Context, before time.Time, errorCode, message string) (int64, error) {
+	query := `
+		UPDATE inference_requests
+		SET status = $2,
+		    error_code = $3,
+		    error_message = $4,
+		    completed_at = $5
+		WHERE status IN ($6, $7) AND created_at < $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query,
+		before,
+		models.InferenceStatusFailed,
+		errorCode,
+		message,
+		time.Now(),
+		models.InferenceStatusPending,
+		models.InferenceStatusProcessing,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail unfinished inference requests: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
 // WithTx returns a new repository instance bound to the transaction
 func (r *InferenceRequestRepository) WithTx(tx repositories.Transaction) repositories.InferenceRequestRepository {
 	return &InferenceRequestRepository{
@@ -346,6 +524,14 @@ func (r *InferenceRequestRepository) queryInferenceRequests(ctx context.Context,
 			&req.ErrorMessage,
 			&req.CreatedAt,
 			&req.CompletedAt,
+			&req.ExperimentID,
+			&req.ExperimentArm,
+			&req.FeedbackScore,
+			&req.TemplateID,
+			&req.TemplateVersion,
+			&req.Response,
+			&req.FinishReason,
+			&req.ErrorCode,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan inference request: %w", err)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/upb/llm-control-plane/backend/models"
@@ -49,24 +50,16 @@ func (r *OrganizationRepository) Create(ctx context.Context, org *models.Organiz
 	return nil
 }
 
-// GetByID retrieves an organization by ID
+// GetByID retrieves an organization by ID, unless it is deleted
 func (r *OrganizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
 	query := `
-		SELECT id, name, slug, created_at, updated_at
+		SELECT id, name, slug, created_at, updated_at, deleted_at
 		FROM organizations
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	executor := GetExecutor(ctx, r.db)
-	org := &models.Organization{}
-
-	err := executor.QueryRowContext(ctx, query, id).Scan(
-		&org.ID,
-		&org.Name,
-		&org.Slug,
-		&org.CreatedAt,
-		&org.UpdatedAt,
-	)
+	org, err := scanOrganization(executor.QueryRowContext(ctx, query, id))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -78,24 +71,17 @@ func (r *OrganizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*mo
 	return org, nil
 }
 
-// GetBySlug retrieves an organization by slug
+// GetBySlug retrieves an organization by slug, deleted or not; the slugs of
+// deleted organizations stay taken so that they can be restored
 func (r *OrganizationRepository) GetBySlug(ctx context.Context, slug string) (*models.Organization, error) {
 	query := `
-		SELECT id, name, slug, created_at, updated_at
+		SELECT id, name, slug, created_at, updated_at, deleted_at
 		FROM organizations
 		WHERE slug = $1
 	`
 
 	executor := GetExecutor(ctx, r.db)
-	org := &models.Organization{}
-
-	err := executor.QueryRowContext(ctx, query, slug).Scan(
-		&org.ID,
-		&org.Name,
-		&org.Slug,
-		&org.CreatedAt,
-		&org.UpdatedAt,
-	)
+	org, err := scanOrganization(executor.QueryRowContext(ctx, query, slug))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -107,11 +93,12 @@ func (r *OrganizationRepository) GetBySlug(ctx context.Context, slug string) (*m
 	return org, nil
 }
 
-// List retrieves all organizations with pagination
+// List retrieves all organizations that are not deleted with pagination
 func (r *OrganizationRepository) List(ctx context.Context, limit, offset int) ([]*models.Organization, error) {
 	query := `
-		SELECT id, name, slug, created_at, updated_at
+		SELECT id, name, slug, created_at, updated_at, deleted_at
 		FROM organizations
+		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
 	`
@@ -125,14 +112,7 @@ func (r *OrganizationRepository) List(ctx context.Context, limit, offset int) ([
 
 	var orgs []*models.Organization
 	for rows.Next() {
-		org := &models.Organization{}
-		err := rows.Scan(
-			&org.ID,
-			&org.Name,
-			&org.Slug,
-			&org.CreatedAt,
-			&org.UpdatedAt,
-		)
+		org, err := scanOrganization(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan organization: %w", err)
 		}
@@ -153,7 +133,7 @@ func (r *OrganizationRepository) Update(ctx context.Context, org *models.Organiz
 		SET name = $2,
 		    slug = $3,
 		    updated_at = $4
-		WHERE id = $1
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	executor := GetExecutor(ctx, r.db)
@@ -181,7 +161,7 @@ func (r *OrganizationRepository) Update(ctx context.Context, org *models.Organiz
 	return nil
 }
 
-// Delete deletes an organization
+// Delete permanently deletes an organization with everything it owns
 func (r *OrganizationRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM organizations WHERE id = $1`
 
@@ -204,6 +184,73 @@ func (r *OrganizationRepository) Delete(ctx context.Context, id uuid.UUID) error
 	return nil
 }
 
+// GetDeleted retrieves a deleted organization by ID
+func (r *OrganizationRepository) GetDeleted(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	query := `
+		SELECT id, name, slug, created_at, updated_at, deleted_at
+		FROM organizations
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	org, err := scanOrganization(executor.QueryRowContext(ctx, query, id))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("deleted organization not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return org, nil
+}
+
+// SoftDelete marks an organization deleted
+func (r *OrganizationRepository) SoftDelete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	query := `UPDATE organizations SET deleted_at = $2 WHERE id = $1 AND deleted_at IS NULL`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, id, deletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("organization not found: %s", id)
+	}
+
+	r.logger.Debug("organization soft-deleted", zap.String("id", id.String()))
+	return nil
+}
+
+// Restore clears the deletion of an organization
+func (r *OrganizationRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE organizations SET deleted_at = NULL WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to restore organization: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("deleted organization not found: %s", id)
+	}
+
+	r.logger.Debug("organization restored", zap.String("id", id.String()))
+	return nil
+}
+
 // WithTx returns a new repository instance bound to the transaction
 func (r *OrganizationRepository) WithTx(tx repositories.Transaction) repositories.OrganizationRepository {
 	return &OrganizationRepository{
@@ -211,3 +258,20 @@ func (r *OrganizationRepository) WithTx(tx repositories.Transaction) repositorie
 		logger: r.logger,
 	}
 }
+
+// scanOrganization scans an organization row
+func scanOrganization(row rowScanner) (*models.Organization, error) {
+	org := &models.Organization{}
+	err := row.Scan(
+		&org.ID,
+		&org.Name,
+		&org.Slug,
+		&org.CreatedAt,
+		&org.UpdatedAt,
+		&org.DeletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
@@ -0,0 +1,395 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"go.uber.org/zap"
+)
+
+// PromptTemplateRepository implements the repositories.PromptTemplateRepository interface
+type PromptTemplateRepository struct {
+	db     *DB
+	logger *zap.Logger
+}
+
+// NewPromptTemplateRepository creates a new prompt template repository
+func NewPromptTemplateRepository(db *DB, logger *zap.Logger) repositories.PromptTemplateRepository {
+	return &PromptTemplateRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new prompt template
+func (r *PromptTemplateRepository) Create(ctx context.Context, template *models.PromptTemplate) error {
+	query := `
+		INSERT INTO prompt_templates (
+			id, org_id, name, description, active_version, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		template.ID,
+		template.OrgID,
+		template.Name,
+		template.Description,
+		template.ActiveVersion,
+		template.CreatedAt,
+		template.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create prompt template: %w", err)
+	}
+
+	r.logger.Debug("prompt template created", zap.String("id", template.ID.String()), zap.String("name", template.Name))
+	return nil
+}
+
+// GetByID retrieves a prompt template by ID
+func (r *PromptTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PromptTemplate, error) {
+	query := `
+		SELECT id, org_id, name, description, active_version, created_at, updated_at
+		FROM prompt_templates
+		WHERE id = $1
+	`
+
+	return r.getTemplate(ctx, query, id)
+}
+
+// GetByName retrieves a prompt template by name within an organization
+func (r *PromptTemplateRepository) GetByName(ctx context.Context, orgID uuid.UUID, name string) (*models.PromptTemplate, error) {
+	query := `
+		SELECT id, org_id, name, description, active_version, created_at, updated_at
+		FROM prompt_templates
+		WHERE org_id = $1 AND name = $2
+	`
+
+	return r.getTemplate(ctx, query, orgID, name)
+}
+
+// GetByOrgID retrieves all prompt templates for an organization
+func (r *PromptTemplateRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID) ([]*models.PromptTemplate, error) {
+	query := `
+		SELECT id, org_id, name, description, active_version, created_at, updated_at
+		FROM prompt_templates
+		WHERE org_id = $1
+		ORDER BY name ASC
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prompt templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []*models.PromptTemplate
+	for rows.Next() {
+		template := &models.PromptTemplate{}
+		err := rows.Scan(
+			&template.ID,
+			&template.OrgID,
+			&template.Name,
+			&template.Description,
+			&template.ActiveVersion,
+			&template.CreatedAt,
+			&template.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan prompt template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating prompt template rows: %w", err)
+	}
+
+	return templates, nil
+}
+
+// Update updates a prompt template
+func (r *PromptTemplateRepository) Update(ctx context.Context, template *models.PromptTemplate) error {
+	query := `
+		UPDATE prompt_templates
+		SET name = $2,
+		    description = $3,
+		    active_version = $4,
+		    updated_at = $5
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query,
+		template.ID,
+		template.Name,
+		template.Description,
+		template.ActiveVersion,
+		template.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update prompt template: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("prompt template not found: %s", template.ID)
+	}
+
+	r.logger.Debug("prompt template updated", zap.String("id", template.ID.String()))
+	return nil
+}
+
+// Delete deletes a prompt template with its versions and pins
+func (r *PromptTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM prompt_templates WHERE id = $1`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete prompt template: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("prompt template not found: %s", id)
+	}
+
+	r.logger.Debug("prompt template deleted", zap.String("id", id.String()))
+	return nil
+}
+
+// CreateVersion stores a new version and assigns it the next version number.
+// Concurrent writers are serialized by the unique (template_id, version) constraint.
+func (r *PromptTemplateRepository) CreateVersion(ctx context.Context, version *models.PromptTemplateVersion) error {
+	query := `
+		INSERT INTO prompt_template_versions (
+			id, template_id, version, messages, variables, created_by, created_at
+		) VALUES (
+			$1, $2,
+			(SELECT COALESCE(MAX(version), 0) + 1 FROM prompt_template_versions WHERE template_id = $2),
+			$3, $4, $5, $6
+		)
+		RETURNING version
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	err := executor.QueryRowContext(ctx, query,
+		version.ID,
+		version.TemplateID,
+		version.Messages,
+		version.Variables,
+		version.CreatedBy,
+		version.CreatedAt,
+	).Scan(&version.Version)
+
+	if err != nil {
+		return fmt.Errorf("failed to create prompt template version: %w", err)
+	}
+
+	r.logger.Debug("prompt template version created",
+		zap.String("template_id", version.TemplateID.String()),
+		zap.Int("version", version.Version))
+	return nil
+}
+
+// GetVersion retrieves a specific version of a template
+func (r *PromptTemplateRepository) GetVersion(ctx context.Context, templateID uuid.UUID, version int) (*models.PromptTemplateVersion, error) {
+	query := `
+		SELECT id, template_id, version, messages, variables, created_by, created_at
+		FROM prompt_template_versions
+		WHERE template_id = $1 AND version = $2
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	v := &models.PromptTemplateVersion{}
+
+	err := executor.QueryRowContext(ctx, query, templateID, version).Scan(
+		&v.ID,
+		&v.TemplateID,
+		&v.Version,
+		&v.Messages,
+		&v.Variables,
+		&v.CreatedBy,
+		&v.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("prompt template version not found: %s@%d", templateID, version)
+		}
+		return nil, fmt.Errorf("failed to get prompt template version: %w", err)
+	}
+
+	return v, nil
+}
+
+// ListVersions retrieves all versions of a template, newest first
+func (r *PromptTemplateRepository) ListVersions(ctx context.Context, templateID uuid.UUID) ([]*models.PromptTemplateVersion, error) {
+	query := `
+		SELECT id, template_id, version, messages, variables, created_by, created_at
+		FROM prompt_template_versions
+		WHERE template_id = $1
+		ORDER BY version DESC
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prompt template versions: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []*models.PromptTemplateVersion
+	for rows.Next() {
+		v := &models.PromptTemplateVersion{}
+		err := rows.Scan(
+			&v.ID,
+			&v.TemplateID,
+			&v.Version,
+			&v.Messages,
+			&v.Variables,
+			&v.CreatedBy,
+			&v.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan prompt template version: %w", err)
+		}
+		versions = append(versions, v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating prompt template version rows: %w", err)
+	}
+
+	return versions, nil
+}
+
+// SetPin creates or replaces an application's pinned version
+func (r *PromptTemplateRepository) SetPin(ctx context.Context, pin *models.PromptTemplatePin) error {
+	query := `
+		INSERT INTO prompt_template_pins (template_id, app_id, version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (template_id, app_id)
+		DO UPDATE SET version = EXCLUDED.version, updated_at = EXCLUDED.updated_at
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		pin.TemplateID,
+		pin.AppID,
+		pin.Version,
+		pin.CreatedAt,
+		pin.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to pin prompt template version: %w", err)
+	}
+
+	r.logger.Debug("prompt template version pinned",
+		zap.String("template_id", pin.TemplateID.String()),
+		zap.String("app_id", pin.AppID.String()),
+		zap.Int("version", pin.Version))
+	return nil
+}
+
+// GetPin retrieves an application's pinned version, or nil if it has none
+func (r *PromptTemplateRepository) GetPin(ctx context.Context, templateID, appID uuid.UUID) (*models.PromptTemplatePin, error) {
+	query := `
+		SELECT template_id, app_id, version, created_at, updated_at
+		FROM prompt_template_pins
+		WHERE template_id = $1 AND app_id = $2
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	pin := &models.PromptTemplatePin{}
+
+	err := executor.QueryRowContext(ctx, query, templateID, appID).Scan(
+		&pin.TemplateID,
+		&pin.AppID,
+		&pin.Version,
+		&pin.CreatedAt,
+		&pin.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get prompt template pin: %w", err)
+	}
+
+	return pin, nil
+}
+
+// DeletePin removes an application's pinned version
+func (r *PromptTemplateRepository) DeletePin(ctx context.Context, templateID, appID uuid.UUID) error {
+	query := `DELETE FROM prompt_template_pins WHERE template_id = $1 AND app_id = $2`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, templateID, appID)
+	if err != nil {
+		return fmt.Errorf("failed to delete prompt template pin: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("prompt template pin not found: %s", templateID)
+	}
+
+	return nil
+}
+
+// WithTx returns a new repository instance bound to the transaction
+func (r *PromptTemplateRepository) WithTx(tx repositories.Transaction) repositories.PromptTemplateRepository {
+	return &PromptTemplateRepository{
+		db:     r.db,
+		logger: r.logger,
+	}
+}
+
+// getTemplate is a helper method to query a single prompt template
+func (r *PromptTemplateRepository) getTemplate(ctx context.Context, query string, args ...interface{}) (*models.PromptTemplate, error) {
+	executor := GetExecutor(ctx, r.db)
+	template := &models.PromptTemplate{}
+
+	err := executor.QueryRowContext(ctx, query, args...).Scan(
+		&template.ID,
+		&template.OrgID,
+		&template.Name,
+		&template.Description,
+		&template.ActiveVersion,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("prompt template not found")
+		}
+		return nil, fmt.Errorf("failed to get prompt template: %w", err)
+	}
+
+	return template, nil
+}
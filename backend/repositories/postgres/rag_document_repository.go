@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/internal/rag"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"go.uber.org/zap"
+)
+
+// RAGDocumentRepository implements the repositories.RAGDocumentRepository interface
+type RAGDocumentRepository struct {
+	db     *DB
+	logger *zap.Logger
+}
+
+// NewRAGDocumentRepository creates a new RAG document repository
+func NewRAGDocumentRepository(db *DB, logger *zap.Logger) repositories.RAGDocumentRepository {
+	return &RAGDocumentRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// GetDocument retrieves a document, or nil if the index has none with that ID
+func (r *RAGDocumentRepository) GetDocument(ctx context.Context, orgID uuid.UUID, indexName, documentID string) (*models.RAGDocument, error) {
+	query := `
+		SELECT org_id, index_name, document_id, content_type, content_hash, metadata,
+		       chunk_count, embedding_model, tokens_used, cost, created_at, updated_at
+		FROM rag_documents
+		WHERE org_id = $1 AND index_name = $2 AND document_id = $3
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	doc := &models.RAGDocument{}
+
+	err := executor.QueryRowContext(ctx, query, orgID, indexName, documentID).Scan(
+		&doc.OrgID,
+		&doc.IndexName,
+		&doc.DocumentID,
+		&doc.ContentType,
+		&doc.ContentHash,
+		&doc.Metadata,
+		&doc.ChunkCount,
+		&doc.EmbeddingModel,
+		&doc.TokensUsed,
+		&doc.Cost,
+		&doc.CreatedAt,
+		&doc.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get rag document: %w", err)
+	}
+
+	return doc, nil
+}
+
+// ReplaceDocument upserts a document and replaces all of its chunks.
+// Callers should run it in a transaction so readers never see a partial document.
+func (r *RAGDocumentRepository) ReplaceDocument(ctx context.Context, doc *models.RAGDocument, chunks []*models.RAGChunk) error {
+	executor := GetExecutor(ctx, r.db)
+
+	_, err := executor.ExecContext(ctx, `
+		DELETE FROM rag_chunks WHERE org_id = $1 AND index_name = $2 AND document_id = $3
+	`, doc.OrgID, doc.IndexName, doc.DocumentID)
+	if err != nil {
+		return fmt.Errorf("failed to delete rag chunks: %w", err)
+	}
+
+	_, err = executor.ExecContext(ctx, `
+		INSERT INTO rag_documents (
+			org_id, index_name, document_id, content_type, content_hash, metadata,
+			chunk_count, embedding_model, tokens_used, cost, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (org_id, index_name, document_id)
+		DO UPDATE SET content_type = EXCLUDED.content_type,
+		              content_hash = EXCLUDED.content_hash,
+		              metadata = EXCLUDED.metadata,
+		              chunk_count = EXCLUDED.chunk_count,
+		              embedding_model = EXCLUDED.embedding_model,
+		              tokens_used = EXCLUDED.tokens_used,
+		              cost = EXCLUDED.cost,
+		              updated_at = EXCLUDED.updated_at
+	`,
+		doc.OrgID,
+		doc.IndexName,
+		doc.DocumentID,
+		doc.ContentType,
+		doc.ContentHash,
+		doc.Metadata,
+		doc.ChunkCount,
+		doc.EmbeddingModel,
+		doc.TokensUsed,
+		doc.Cost,
+		doc.CreatedAt,
+		doc.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to upsert rag document: %w", err)
+	}
+
+	for _, chunk := range chunks {
+		_, err := executor.ExecContext(ctx, `
+			INSERT INTO rag_chunks (
+				id, org_id, index_name, document_id, chunk_index, content, metadata, embedding, created_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8::vector, $9)
+		`,
+			chunk.ID,
+			chunk.OrgID,
+			chunk.IndexName,
+			chunk.DocumentID,
+			chunk.ChunkIndex,
+			chunk.Content,
+			chunk.Metadata,
+			rag.VectorLiteral(chunk.Embedding),
+			chunk.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert rag chunk %d: %w", chunk.ChunkIndex, err)
+		}
+	}
+
+	r.logger.Debug("rag document stored",
+		zap.String("org_id", doc.OrgID.String()),
+		zap.String("index", doc.IndexName),
+		zap.String("document_id", doc.DocumentID),
+		zap.Int("chunks", len(chunks)))
+	return nil
+}
+
+// DeleteDocument deletes a document and its chunks
+func (r *RAGDocumentRepository) DeleteDocument(ctx context.Context, orgID uuid.UUID, indexName, documentID string) error {
+	executor := GetExecutor(ctx, r.db)
+
+	_, err := executor.ExecContext(ctx, `
+		DELETE FROM rag_chunks WHERE org_id = $1 AND index_name = $2 AND document_id = $3
+	`, orgID, indexName, documentID)
+	if err != nil {
+		return fmt.Errorf("failed to delete rag chunks: %w", err)
+	}
+
+	result, err := executor.ExecContext(ctx, `
+		DELETE FROM rag_documents WHERE org_id = $1 AND index_name = $2 AND document_id = $3
+	`, orgID, indexName, documentID)
+	if err != nil {
+		return fmt.Errorf("failed to delete rag document: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("rag document not found: %s", documentID)
+	}
+
+	r.logger.Debug("rag document deleted",
+		zap.String("index", indexName),
+		zap.String("document_id", documentID))
+	return nil
+}
+
+// WithTx returns a new repository instance bound to the transaction
+func (r *RAGDocumentRepository) WithTx(tx repositories.Transaction) repositories.RAGDocumentRepository {
+	return &RAGDocumentRepository{
+		db:     r.db,
+		logger: r.logger,
+	}
+}
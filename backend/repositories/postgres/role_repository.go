@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"go.uber.org/zap"
+)
+
+// RoleRepository implements the repositories.RoleRepository interface
+type RoleRepository struct {
+	db     *DB
+	logger *zap.Logger
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *DB, logger *zap.Logger) repositories.RoleRepository {
+	return &RoleRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create creates a new role
+func (r *RoleRepository) Create(ctx context.Context, role *models.Role) error {
+	query := `
+		INSERT INTO roles (id, org_id, name, description, permissions, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		role.ID,
+		role.OrgID,
+		role.Name,
+		role.Description,
+		role.Permissions,
+		role.CreatedAt,
+		role.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create role: %w", err)
+	}
+
+	r.logger.Debug("role created", zap.String("id", role.ID.String()), zap.String("name", role.Name))
+	return nil
+}
+
+// ListByOrg retrieves the built-in roles and the organization's roles, by name
+func (r *RoleRepository) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*models.Role, error) {
+	query := `
+		SELECT id, org_id, name, description, permissions, created_at, updated_at
+		FROM roles
+		WHERE org_id IS NULL OR org_id = $1
+		ORDER BY name, org_id NULLS FIRST
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []*models.Role
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating role rows: %w", err)
+	}
+
+	return roles, nil
+}
+
+// Update updates a role's description and permissions
+func (r *RoleRepository) Update(ctx context.Context, role *models.Role) error {
+	query := `
+		UPDATE roles
+		SET description = $2, permissions = $3, updated_at = $4
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query,
+		role.ID,
+		role.Description,
+		role.Permissions,
+		role.UpdatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("role not found: %s", role.ID)
+	}
+
+	r.logger.Debug("role updated", zap.String("id", role.ID.String()))
+	return nil
+}
+
+// Delete deletes a role
+func (r *RoleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM roles WHERE id = $1`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("role not found: %s", id)
+	}
+
+	r.logger.Debug("role deleted", zap.String("id", id.String()))
+	return nil
+}
+
+// WithTx returns a new repository instance bound to the transaction
+func (r *RoleRepository) WithTx(tx repositories.Transaction) repositories.RoleRepository {
+	return &RoleRepository{
+		db:     r.db,
+		logger: r.logger,
+	}
+}
+
+// scanRole scans a role row
+func scanRole(row rowScanner) (*models.Role, error) {
+	role := &models.Role{}
+	err := row.Scan(
+		&role.ID,
+		&role.OrgID,
+		&role.Name,
+		&role.Description,
+		&role.Permissions,
+		&role.CreatedAt,
+		&role.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
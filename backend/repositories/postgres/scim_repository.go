@@ -0,0 +1,409 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"go.uber.org/zap"
+)
+
+// SCIMConfigRepository implements the repositories.SCIMConfigRepository interface
+type SCIMConfigRepository struct {
+	db     *DB
+	logger *zap.Logger
+}
+
+// NewSCIMConfigRepository creates a new SCIM configuration repository
+func NewSCIMConfigRepository(db *DB, logger *zap.Logger) repositories.SCIMConfigRepository {
+	return &SCIMConfigRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const scimConfigColumns = `org_id, token_hash, token_created_at, group_roles, created_at, updated_at`
+
+// Get retrieves an organization's SCIM configuration
+func (r *SCIMConfigRepository) Get(ctx context.Context, orgID uuid.UUID) (*models.SCIMConfig, error) {
+	query := `SELECT ` + scimConfigColumns + `
+		FROM scim_configs
+		WHERE org_id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	config, err := scanSCIMConfig(executor.QueryRowContext(ctx, query, orgID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("scim config not found: %s", orgID)
+		}
+		return nil, fmt.Errorf("failed to get scim config: %w", err)
+	}
+
+	return config, nil
+}
+
+// GetByTokenHash retrieves the SCIM configuration of a bearer token's hash;
+// configurations of deleted organizations are not found
+func (r *SCIMConfigRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.SCIMConfig, error) {
+	query := `SELECT ` + scimConfigColumns + `
+		FROM scim_configs
+		WHERE token_hash = $1 AND token_hash <> ''
+		  AND EXISTS (SELECT 1 FROM organizations o WHERE o.id = scim_configs.org_id AND o.deleted_at IS NULL)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	config, err := scanSCIMConfig(executor.QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("scim config not found")
+		}
+		return nil, fmt.Errorf("failed to get scim config: %w", err)
+	}
+
+	return config, nil
+}
+
+// Upsert creates or replaces an organization's SCIM configuration
+func (r *SCIMConfigRepository) Upsert(ctx context.Context, config *models.SCIMConfig) error {
+	query := `
+		INSERT INTO scim_configs (` + scimConfigColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (org_id)
+		DO UPDATE SET token_hash = EXCLUDED.token_hash,
+		              token_created_at = EXCLUDED.token_created_at,
+		              group_roles = EXCLUDED.group_roles,
+		              updated_at = EXCLUDED.updated_at
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		config.OrgID,
+		config.TokenHash,
+		config.TokenCreatedAt,
+		config.GroupRoles,
+		config.CreatedAt,
+		config.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save scim config: %w", err)
+	}
+
+	r.logger.Debug("scim config saved", zap.String("org_id", config.OrgID.String()))
+	return nil
+}
+
+// WithTx returns a new repository instance bound to the transaction
+func (r *SCIMConfigRepository) WithTx(tx repositories.Transaction) repositories.SCIMConfigRepository {
+	return &SCIMConfigRepository{
+		db:     r.db,
+		logger: r.logger,
+	}
+}
+
+// scanSCIMConfig scans a SCIM configuration row
+func scanSCIMConfig(row rowScanner) (*models.SCIMConfig, error) {
+	config := &models.SCIMConfig{}
+	err := row.Scan(
+		&config.OrgID,
+		&config.TokenHash,
+		&config.TokenCreatedAt,
+		&config.GroupRoles,
+		&config.CreatedAt,
+		&config.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// SCIMGroupRepository implements the repositories.SCIMGroupRepository interface
+type SCIMGroupRepository struct {
+	db     *DB
+	logger *zap.Logger
+}
+
+// NewSCIMGroupRepository creates a new SCIM group repository
+func NewSCIMGroupRepository(db *DB, logger *zap.Logger) repositories.SCIMGroupRepository {
+	return &SCIMGroupRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const scimGroupColumns = `id, org_id, display_name, external_id, created_at, updated_at`
+
+// Create creates a new group with its members
+func (r *SCIMGroupRepository) Create(ctx context.Context, group *models.SCIMGroup) error {
+	query := `
+		INSERT INTO scim_groups (` + scimGroupColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		group.ID,
+		group.OrgID,
+		group.DisplayName,
+		group.ExternalID,
+		group.CreatedAt,
+		group.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create scim group: %w", err)
+	}
+
+	if err := r.insertMembers(ctx, executor, group); err != nil {
+		return err
+	}
+
+	r.logger.Debug("scim group created", zap.String("id", group.ID.String()), zap.String("display_name", group.DisplayName))
+	return nil
+}
+
+// GetByID retrieves a group by ID
+func (r *SCIMGroupRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SCIMGroup, error) {
+	query := `SELECT ` + scimGroupColumns + `
+		FROM scim_groups
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	group, err := scanSCIMGroup(executor.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("scim group not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get scim group: %w", err)
+	}
+
+	if err := r.loadMembers(ctx, executor, []*models.SCIMGroup{group}, `WHERE m.group_id = $1`, id); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// GetByOrgAndName retrieves a group by display name within an organization
+func (r *SCIMGroupRepository) GetByOrgAndName(ctx context.Context, orgID uuid.UUID, displayName string) (*models.SCIMGroup, error) {
+	query := `SELECT ` + scimGroupColumns + `
+		FROM scim_groups
+		WHERE org_id = $1 AND display_name = $2
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	group, err := scanSCIMGroup(executor.QueryRowContext(ctx, query, orgID, displayName))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("scim group not found: %s", displayName)
+		}
+		return nil, fmt.Errorf("failed to get scim group: %w", err)
+	}
+
+	if err := r.loadMembers(ctx, executor, []*models.SCIMGroup{group}, `WHERE m.group_id = $1`, group.ID); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// ListByOrg retrieves an organization's groups, by display name
+func (r *SCIMGroupRepository) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*models.SCIMGroup, error) {
+	query := `SELECT ` + scimGroupColumns + `
+		FROM scim_groups
+		WHERE org_id = $1
+		ORDER BY display_name
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	groups, err := r.queryGroups(ctx, executor, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := `JOIN scim_groups g ON g.id = m.group_id WHERE g.org_id = $1`
+	if err := r.loadMembers(ctx, executor, groups, members, orgID); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// ListByMember retrieves the groups a user is a member of
+func (r *SCIMGroupRepository) ListByMember(ctx context.Context, userID uuid.UUID) ([]*models.SCIMGroup, error) {
+	query := `SELECT ` + scimGroupColumns + `
+		FROM scim_groups
+		WHERE id IN (SELECT group_id FROM scim_group_members WHERE user_id = $1)
+		ORDER BY display_name
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	groups, err := r.queryGroups(ctx, executor, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := `WHERE m.group_id IN (SELECT group_id FROM scim_group_members WHERE user_id = $1)`
+	if err := r.loadMembers(ctx, executor, groups, members, userID); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Update updates a group's display name, external ID and members
+func (r *SCIMGroupRepository) Update(ctx context.Context, group *models.SCIMGroup) error {
+	query := `
+		UPDATE scim_groups
+		SET display_name = $2, external_id = $3, updated_at = $4
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query,
+		group.ID,
+		group.DisplayName,
+		group.ExternalID,
+		group.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update scim group: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("scim group not found: %s", group.ID)
+	}
+
+	if _, err := executor.ExecContext(ctx, `DELETE FROM scim_group_members WHERE group_id = $1`, group.ID); err != nil {
+		return fmt.Errorf("failed to update scim group members: %w", err)
+	}
+	if err := r.insertMembers(ctx, executor, group); err != nil {
+		return err
+	}
+
+	r.logger.Debug("scim group updated", zap.String("id", group.ID.String()))
+	return nil
+}
+
+// Delete deletes a group
+func (r *SCIMGroupRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, `DELETE FROM scim_groups WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete scim group: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("scim group not found: %s", id)
+	}
+
+	r.logger.Debug("scim group deleted", zap.String("id", id.String()))
+	return nil
+}
+
+// WithTx returns a new repository instance bound to the transaction
+func (r *SCIMGroupRepository) WithTx(tx repositories.Transaction) repositories.SCIMGroupRepository {
+	return &SCIMGroupRepository{
+		db:     r.db,
+		logger: r.logger,
+	}
+}
+
+// queryGroups runs a query of group rows
+func (r *SCIMGroupRepository) queryGroups(ctx context.Context, executor Executor, query string, args ...interface{}) ([]*models.SCIMGroup, error) {
+	rows, err := executor.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query scim groups: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []*models.SCIMGroup
+	for rows.Next() {
+		group, err := scanSCIMGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan scim group: %w", err)
+		}
+		groups = append(groups, group)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scim group rows: %w", err)
+	}
+
+	return groups, nil
+}
+
+// loadMembers sets the members of groups from the membership rows a
+// condition on scim_group_members m selects
+func (r *SCIMGroupRepository) loadMembers(ctx context.Context, executor Executor, groups []*models.SCIMGroup, condition string, args ...interface{}) error {
+	byID := make(map[uuid.UUID]*models.SCIMGroup, len(groups))
+	for _, group := range groups {
+		group.Members = []uuid.UUID{}
+		byID[group.ID] = group
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+
+	rows, err := executor.QueryContext(ctx, `SELECT m.group_id, m.user_id FROM scim_group_members m `+condition, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query scim group members: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var groupID, userID uuid.UUID
+		if err := rows.Scan(&groupID, &userID); err != nil {
+			return fmt.Errorf("failed to scan scim group member: %w", err)
+		}
+		if group, ok := byID[groupID]; ok {
+			group.Members = append(group.Members, userID)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating scim group member rows: %w", err)
+	}
+
+	return nil
+}
+
+// insertMembers adds a group's members
+func (r *SCIMGroupRepository) insertMembers(ctx context.Context, executor Executor, group *models.SCIMGroup) error {
+	for _, userID := range group.Members {
+		_, err := executor.ExecContext(ctx,
+			`INSERT INTO scim_group_members (group_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			group.ID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to add scim group member: %w", err)
+		}
+	}
+	return nil
+}
+
+// scanSCIMGroup scans a SCIM group row
+func scanSCIMGroup(row rowScanner) (*models.SCIMGroup, error) {
+	group := &models.SCIMGroup{}
+	err := row.Scan(
+		&group.ID,
+		&group.OrgID,
+		&group.DisplayName,
+		&group.ExternalID,
+		&group.CreatedAt,
+		&group.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return group, nil
+}
@@ -0,0 +1,223 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"go.uber.org/zap"
+)
+
+// ServiceAccountRepository implements the repositories.ServiceAccountRepository interface
+type ServiceAccountRepository struct {
+	db     *DB
+	logger *zap.Logger
+}
+
+// NewServiceAccountRepository creates a new service account repository
+func NewServiceAccountRepository(db *DB, logger *zap.Logger) repositories.ServiceAccountRepository {
+	return &ServiceAccountRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const serviceAccountColumns = `
+		id, org_id, name, description, client_id, secret_hash, roles, scopes,
+		created_by, created_at, updated_at, disabled_at`
+
+// Create creates a new service account
+func (r *ServiceAccountRepository) Create(ctx context.Context, account *models.ServiceAccount) error {
+	query := `
+		INSERT INTO service_accounts (` + serviceAccountColumns + `
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		account.ID,
+		account.OrgID,
+		account.Name,
+		account.Description,
+		account.ClientID,
+		account.SecretHash,
+		account.Roles,
+		account.Scopes,
+		account.CreatedBy,
+		account.CreatedAt,
+		account.UpdatedAt,
+		account.DisabledAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create service account: %w", err)
+	}
+
+	r.logger.Debug("service account created", zap.String("id", account.ID.String()), zap.String("client_id", account.ClientID))
+	return nil
+}
+
+// GetByID retrieves a service account by ID
+func (r *ServiceAccountRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error) {
+	query := `SELECT ` + serviceAccountColumns + `
+		FROM service_accounts
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	account, err := scanServiceAccount(executor.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("service account not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get service account: %w", err)
+	}
+
+	return account, nil
+}
+
+// GetByClientID retrieves a service account by its client ID; accounts of
+// deleted organizations are not found
+func (r *ServiceAccountRepository) GetByClientID(ctx context.Context, clientID string) (*models.ServiceAccount, error) {
+	query := `SELECT ` + serviceAccountColumns + `
+		FROM service_accounts
+		WHERE client_id = $1
+		  AND EXISTS (SELECT 1 FROM organizations o WHERE o.id = service_accounts.org_id AND o.deleted_at IS NULL)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	account, err := scanServiceAccount(executor.QueryRowContext(ctx, query, clientID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("service account not found")
+		}
+		return nil, fmt.Errorf("failed to get service account: %w", err)
+	}
+
+	return account, nil
+}
+
+// ListByOrg retrieves an organization's service accounts, newest first
+func (r *ServiceAccountRepository) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*models.ServiceAccount, error) {
+	query := `SELECT ` + serviceAccountColumns + `
+		FROM service_accounts
+		WHERE org_id = $1
+		ORDER BY created_at DESC
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query service accounts: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*models.ServiceAccount
+	for rows.Next() {
+		account, err := scanServiceAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan service account: %w", err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating service account rows: %w", err)
+	}
+
+	return accounts, nil
+}
+
+// Update updates a service account's name, description, secret, roles,
+// scopes and disabled time
+func (r *ServiceAccountRepository) Update(ctx context.Context, account *models.ServiceAccount) error {
+	query := `
+		UPDATE service_accounts
+		SET name = $2, description = $3, secret_hash = $4, roles = $5, scopes = $6,
+		    updated_at = $7, disabled_at = $8
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query,
+		account.ID,
+		account.Name,
+		account.Description,
+		account.SecretHash,
+		account.Roles,
+		account.Scopes,
+		account.UpdatedAt,
+		account.DisabledAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to update service account: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("service account not found: %s", account.ID)
+	}
+
+	r.logger.Debug("service account updated", zap.String("id", account.ID.String()))
+	return nil
+}
+
+// Delete deletes a service account
+func (r *ServiceAccountRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, `DELETE FROM service_accounts WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete service account: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("service account not found: %s", id)
+	}
+
+	r.logger.Debug("service account deleted", zap.String("id", id.String()))
+	return nil
+}
+
+// WithTx returns a new repository instance bound to the transaction
+func (r *ServiceAccountRepository) WithTx(tx repositories.Transaction) repositories.ServiceAccountRepository {
+	return &ServiceAccountRepository{
+		db:     r.db,
+		logger: r.logger,
+	}
+}
+
+// scanServiceAccount scans a service account row
+func scanServiceAccount(row rowScanner) (*models.ServiceAccount, error) {
+	account := &models.ServiceAccount{}
+	err := row.Scan(
+		&account.ID,
+		&account.OrgID,
+		&account.Name,
+		&account.Description,
+		&account.ClientID,
+		&account.SecretHash,
+		&account.Roles,
+		&account.Scopes,
+		&account.CreatedBy,
+		&account.CreatedAt,
+		&account.UpdatedAt,
+		&account.DisabledAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
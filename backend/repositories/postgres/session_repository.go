@@ -0,0 +1,283 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"go.uber.org/zap"
+)
+
+// SessionRepository implements the repositories.SessionRepository interface
+type SessionRepository struct {
+	db     *DB
+	logger *zap.Logger
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(db *DB, logger *zap.Logger) repositories.SessionRepository {
+	return &SessionRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+const sessionColumns = `
+		id, token_hash, org_id, app_id, user_id, provider, issuer, subject, email,
+		email_verified, roles, refresh_token, identity_expires_at, expires_at,
+		created_at, last_seen_at, revoked_at, user_agent, ip_address`
+
+// Create creates a new session
+func (r *SessionRepository) Create(ctx context.Context, session *models.Session) error {
+	query := `
+		INSERT INTO sessions (` + sessionColumns + `
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		session.ID,
+		session.TokenHash,
+		session.OrgID,
+		session.AppID,
+		session.UserID,
+		session.Provider,
+		session.Issuer,
+		session.Subject,
+		session.Email,
+		session.EmailVerified,
+		session.Roles,
+		session.RefreshToken,
+		session.IdentityExpiresAt,
+		session.ExpiresAt,
+		session.CreatedAt,
+		session.LastSeenAt,
+		session.RevokedAt,
+		session.UserAgent,
+		session.IPAddress,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	r.logger.Debug("session created", zap.String("id", session.ID.String()), zap.String("provider", session.Provider))
+	return nil
+}
+
+// GetByID retrieves a session by ID
+func (r *SessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	query := `SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE id = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	session, err := scanSession(executor.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return session, nil
+}
+
+// GetByHash retrieves a session by the hash of its token
+func (r *SessionRepository) GetByHash(ctx context.Context, tokenHash string) (*models.Session, error) {
+	query := `SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE token_hash = $1
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	session, err := scanSession(executor.QueryRowContext(ctx, query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session not found")
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return session, nil
+}
+
+// ListBySubject retrieves a user's sessions that are neither revoked nor
+// expired at the given time, newest first
+func (r *SessionRepository) ListBySubject(ctx context.Context, orgID uuid.UUID, subject string, at time.Time) ([]*models.Session, error) {
+	query := `SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE org_id = $1 AND subject = $2 AND revoked_at IS NULL AND expires_at > $3
+		ORDER BY created_at DESC
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, orgID, subject, at)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sessions: %w", err)
+	}
+	defer rows.Close()
+
+	var sessions []*models.Session
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating session rows: %w", err)
+	}
+
+	return sessions, nil
+}
+
+// Refresh updates a session's identity and refresh token, provided it is
+// not revoked and still holds the refresh token the identity was renewed
+// with; it returns false otherwise
+func (r *SessionRepository) Refresh(ctx context.Context, session *models.Session, previousRefreshToken string) (bool, error) {
+	query := `
+		UPDATE sessions
+		SET app_id = $2, user_id = $3, email = $4, email_verified = $5, roles = $6,
+		    refresh_token = $7, identity_expires_at = $8
+		WHERE id = $1 AND revoked_at IS NULL AND refresh_token = $9
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query,
+		session.ID,
+		session.AppID,
+		session.UserID,
+		session.Email,
+		session.EmailVerified,
+		session.Roles,
+		session.RefreshToken,
+		session.IdentityExpiresAt,
+		previousRefreshToken,
+	)
+
+	if err != nil {
+		return false, fmt.Errorf("failed to refresh session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	r.logger.Debug("session refreshed", zap.String("id", session.ID.String()), zap.Bool("updated", rowsAffected > 0))
+	return rowsAffected > 0, nil
+}
+
+// Revoke revokes a session unless it is already revoked
+func (r *SessionRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, `UPDATE sessions SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	r.logger.Debug("session revoked", zap.String("id", id.String()))
+	return nil
+}
+
+// Touch records the session being used
+func (r *SessionRepository) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, `UPDATE sessions SET last_seen_at = $2 WHERE id = $1`, id, at)
+	if err != nil {
+		return fmt.Errorf("failed to update session last use: %w", err)
+	}
+	return nil
+}
+
+// RevokeBySubject revokes all of a user's active sessions and returns how many were revoked
+func (r *SessionRepository) RevokeBySubject(ctx context.Context, orgID uuid.UUID, subject string, at time.Time) (int64, error) {
+	query := `
+		UPDATE sessions
+		SET revoked_at = $3
+		WHERE org_id = $1 AND subject = $2 AND revoked_at IS NULL AND expires_at > $3
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, orgID, subject, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	r.logger.Debug("sessions revoked", zap.String("org_id", orgID.String()), zap.Int64("count", rowsAffected))
+	return rowsAffected, nil
+}
+
+// RevokeByUser revokes all active sessions of a gateway user and returns how many were revoked
+func (r *SessionRepository) RevokeByUser(ctx context.Context, orgID, userID uuid.UUID, at time.Time) (int64, error) {
+	query := `
+		UPDATE sessions
+		SET revoked_at = $3
+		WHERE org_id = $1 AND user_id = $2 AND revoked_at IS NULL AND expires_at > $3
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	result, err := executor.ExecContext(ctx, query, orgID, userID, at)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	r.logger.Debug("sessions revoked", zap.String("org_id", orgID.String()), zap.String("user_id", userID.String()), zap.Int64("count", rowsAffected))
+	return rowsAffected, nil
+}
+
+// WithTx returns a new repository instance bound to the transaction
+func (r *SessionRepository) WithTx(tx repositories.Transaction) repositories.SessionRepository {
+	return &SessionRepository{
+		db:     r.db,
+		logger: r.logger,
+	}
+}
+
+// scanSession scans a session row
+func scanSession(row rowScanner) (*models.Session, error) {
+	session := &models.Session{}
+	err := row.Scan(
+		&session.ID,
+		&session.TokenHash,
+		&session.OrgID,
+		&session.AppID,
+		&session.UserID,
+		&session.Provider,
+		&session.Issuer,
+		&session.Subject,
+		&session.Email,
+		&session.EmailVerified,
+		&session.Roles,
+		&session.RefreshToken,
+		&session.IdentityExpiresAt,
+		&session.ExpiresAt,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&session.RevokedAt,
+		&session.UserAgent,
+		&session.IPAddress,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
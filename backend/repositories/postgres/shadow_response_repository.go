@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"go.uber.org/zap"
+)
+
+// ShadowResponseRepository implements the repositories.ShadowResponseRepository interface
+type ShadowResponseRepository struct {
+	db     *DB
+	logger *zap.Logger
+}
+
+// NewShadowResponseRepository creates a new shadow response repository
+func NewShadowResponseRepository(db *DB, logger *zap.Logger) repositories.ShadowResponseRepository {
+	return &ShadowResponseRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Create stores a shadow response
+func (r *ShadowResponseRepository) Create(ctx context.Context, resp *models.ShadowResponse) error {
+	query := `
+		INSERT INTO shadow_responses (
+			id, inference_id, org_id, app_id, provider, model, status, response,
+			prompt_tokens, completion_tokens, latency_ms, cost, error_message, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	_, err := executor.ExecContext(ctx, query,
+		resp.ID,
+		resp.InferenceID,
+		resp.OrgID,
+		resp.AppID,
+		resp.Provider,
+		resp.Model,
+		resp.Status,
+		resp.Response,
+		resp.PromptTokens,
+		resp.CompletionTokens,
+		resp.LatencyMs,
+		resp.Cost,
+		resp.ErrorMessage,
+		resp.CreatedAt,
+	)
+
+	if err != nil {
+		return fmt.Errorf("failed to create shadow response: %w", err)
+	}
+
+	r.logger.Debug("shadow response created",
+		zap.String("id", resp.ID.String()),
+		zap.String("inference_id", resp.InferenceID.String()))
+	return nil
+}
+
+// GetByInferenceID retrieves shadow responses for a primary inference request
+func (r *ShadowResponseRepository) GetByInferenceID(ctx context.Context, inferenceID uuid.UUID) ([]*models.ShadowResponse, error) {
+	query := `
+		SELECT id, inference_id, org_id, app_id, provider, model, status, response,
+		       prompt_tokens, completion_tokens, latency_ms, cost, error_message, created_at
+		FROM shadow_responses
+		WHERE inference_id = $1
+		ORDER BY created_at ASC
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	rows, err := executor.QueryContext(ctx, query, inferenceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query shadow responses: %w", err)
+	}
+	defer rows.Close()
+
+	var responses []*models.ShadowResponse
+	for rows.Next() {
+		resp := &models.ShadowResponse{}
+		err := rows.Scan(
+			&resp.ID,
+			&resp.InferenceID,
+			&resp.OrgID,
+			&resp.AppID,
+			&resp.Provider,
+			&resp.Model,
+			&resp.Status,
+			&resp.Response,
+			&resp.PromptTokens,
+			&resp.CompletionTokens,
+			&resp.LatencyMs,
+			&resp.Cost,
+			&resp.ErrorMessage,
+			&resp.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan shadow response: %w", err)
+		}
+		responses = append(responses, resp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating shadow response rows: %w", err)
+	}
+
+	return responses, nil
+}
+
+// WithTx returns a new repository instance bound to the transaction
+func (r *ShadowResponseRepository) WithTx(tx repositories.Transaction) repositories.ShadowResponseRepository {
+	return &ShadowResponseRepository{
+		db:     r.db,
+		logger: r.logger,
+	}
+}
@@ -25,11 +25,13 @@ func NewUserRepository(db *DB, logger *zap.Logger) repositories.UserRepository {
 	}
 }
 
+const userColumns = `id, email, cognito_sub, org_id, role, external_id, display_name, created_at, updated_at, deactivated_at`
+
 // Create creates a new user
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	query := `
-		INSERT INTO users (id, email, cognito_sub, org_id, role, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO users (` + userColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	executor := GetExecutor(ctx, r.db)
@@ -39,8 +41,11 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 		user.CognitoSub,
 		user.OrgID,
 		user.Role,
+		user.ExternalID,
+		user.DisplayName,
 		user.CreatedAt,
 		user.UpdatedAt,
+		user.DeactivatedAt,
 	)
 
 	if err != nil {
@@ -54,23 +59,34 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 // GetByID retrieves a user by ID
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	query := `
-		SELECT id, email, cognito_sub, org_id, role, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1
 	`
 
 	executor := GetExecutor(ctx, r.db)
-	user := &models.User{}
+	user, err := scanUser(executor.QueryRowContext(ctx, query, id))
 
-	err := executor.QueryRowContext(ctx, query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.CognitoSub,
-		&user.OrgID,
-		&user.Role,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByOrgAndID retrieves a user by ID within an organization
+func (r *UserRepository) GetByOrgAndID(ctx context.Context, orgID, id uuid.UUID) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = $1 AND org_id = $2
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	user, err := scanUser(executor.QueryRowContext(ctx, query, id, orgID))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -85,23 +101,13 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 // GetByCognitoSub retrieves a user by Cognito subject
 func (r *UserRepository) GetByCognitoSub(ctx context.Context, cognitoSub string) (*models.User, error) {
 	query := `
-		SELECT id, email, cognito_sub, org_id, role, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE cognito_sub = $1
 	`
 
 	executor := GetExecutor(ctx, r.db)
-	user := &models.User{}
-
-	err := executor.QueryRowContext(ctx, query, cognitoSub).Scan(
-		&user.ID,
-		&user.Email,
-		&user.CognitoSub,
-		&user.OrgID,
-		&user.Role,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := scanUser(executor.QueryRowContext(ctx, query, cognitoSub))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -116,23 +122,34 @@ func (r *UserRepository) GetByCognitoSub(ctx context.Context, cognitoSub string)
 // GetByEmail retrieves a user by email
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	query := `
-		SELECT id, email, cognito_sub, org_id, role, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE email = $1
 	`
 
 	executor := GetExecutor(ctx, r.db)
-	user := &models.User{}
+	user, err := scanUser(executor.QueryRowContext(ctx, query, email))
 
-	err := executor.QueryRowContext(ctx, query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.CognitoSub,
-		&user.OrgID,
-		&user.Role,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found for email: %s", email)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByOrgAndEmail retrieves a user by email within an organization
+func (r *UserRepository) GetByOrgAndEmail(ctx context.Context, orgID uuid.UUID, email string) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE org_id = $1 AND email = $2
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	user, err := scanUser(executor.QueryRowContext(ctx, query, orgID, email))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -144,10 +161,32 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 	return user, nil
 }
 
+// GetByOrgAndExternalID retrieves a user by the provisioning identity
+// provider's ID within an organization
+func (r *UserRepository) GetByOrgAndExternalID(ctx context.Context, orgID uuid.UUID, externalID string) (*models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE org_id = $1 AND external_id = $2 AND external_id <> ''
+	`
+
+	executor := GetExecutor(ctx, r.db)
+	user, err := scanUser(executor.QueryRowContext(ctx, query, orgID, externalID))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found for external_id: %s", externalID)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
 // GetByOrgID retrieves all users for an organization
 func (r *UserRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID) ([]*models.User, error) {
 	query := `
-		SELECT id, email, cognito_sub, org_id, role, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE org_id = $1
 		ORDER BY created_at DESC
@@ -162,16 +201,7 @@ func (r *UserRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID) ([]*mo
 
 	var users []*models.User
 	for rows.Next() {
-		user := &models.User{}
-		err := rows.Scan(
-			&user.ID,
-			&user.Email,
-			&user.CognitoSub,
-			&user.OrgID,
-			&user.Role,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-		)
+		user, err := scanUser(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan user: %w", err)
 		}
@@ -191,7 +221,10 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 		UPDATE users
 		SET email = $2,
 		    role = $3,
-		    updated_at = $4
+		    external_id = $4,
+		    display_name = $5,
+		    updated_at = $6,
+		    deactivated_at = $7
 		WHERE id = $1
 	`
 
@@ -200,7 +233,10 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 		user.ID,
 		user.Email,
 		user.Role,
+		user.ExternalID,
+		user.DisplayName,
 		user.UpdatedAt,
+		user.DeactivatedAt,
 	)
 
 	if err != nil {
@@ -250,3 +286,24 @@ func (r *UserRepository) WithTx(tx repositories.Transaction) repositories.UserRe
 		logger: r.logger,
 	}
 }
+
+// scanUser scans a user row
+func scanUser(row rowScanner) (*models.User, error) {
+	user := &models.User{}
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.CognitoSub,
+		&user.OrgID,
+		&user.Role,
+		&user.ExternalID,
+		&user.DisplayName,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.DeactivatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/go-chi/cors"
 	"github.com/upb/llm-control-plane/backend/app"
 	"github.com/upb/llm-control-plane/backend/handlers"
+	appmiddleware "github.com/upb/llm-control-plane/backend/middleware"
+	"github.com/upb/llm-control-plane/backend/models"
 )
 
 // SetupRoutes configures all application routes and middleware
@@ -17,7 +19,7 @@ func SetupRoutes(deps *app.Dependencies) http.Handler {
 
 	// Core middleware
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(appmiddleware.ClientIP(deps.Config.Server.TrustedProxies))
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(middleware.Timeout(60 * time.Second))
@@ -26,8 +28,8 @@ func SetupRoutes(deps *app.Dependencies) http.Handler {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:*", "https://*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key"},
-		ExposedHeaders:   []string{"Link", "X-Request-ID"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key", "Idempotency-Key", "anthropic-version"},
+		ExposedHeaders:   []string{"Link", "X-Request-ID", "Idempotent-Replayed"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -36,75 +38,293 @@ func SetupRoutes(deps *app.Dependencies) http.Handler {
 	r.Get("/healthz", handlers.HealthCheck(deps))
 	r.Get("/readyz", handlers.ReadinessCheck(deps))
 
-	// OAuth2 auth endpoints (Cognito)
+	// OAuth2 auth endpoints (Cognito, and generic OIDC providers by name)
 	r.Route("/auth", func(r chi.Router) {
 		r.Get("/login", handlers.AuthLoginHandler(deps))
 		r.Get("/callback", handlers.AuthCallbackHandler(deps))
 		r.Get("/logout", handlers.AuthLogoutHandler(deps))
+		r.Get("/oidc/{provider}/login", handlers.OIDCLoginHandler(deps))
+		r.Get("/oidc/{provider}/callback", handlers.OIDCCallbackHandler(deps))
+		r.Get("/oidc/{provider}/logout", handlers.OIDCLogoutHandler(deps))
 	})
 	// Cognito Hosted UI default callback path (also used by /auth/callback)
 	r.Get("/oauth2/idpresponse", handlers.AuthCallbackHandler(deps))
 
+	// Client credentials grant for service accounts, and the keys their tokens are signed with
+	oauthHandler := handlers.NewOAuthHandler(deps.ServiceAccountService, deps.TokenIssuer, deps.Logger)
+	r.Post("/oauth2/token", oauthHandler.HandleToken)
+	r.Get("/.well-known/jwks.json", oauthHandler.HandleJWKS)
+
+	// SCIM 2.0 provisioning by organizations' identity providers (SCIM bearer tokens)
+	scimHandler := handlers.NewSCIMHandler(deps.SCIMService, deps.Logger)
+	r.Route("/scim/v2", func(r chi.Router) {
+		r.Use(scimHandler.RequireToken)
+		r.Get("/ServiceProviderConfig", scimHandler.HandleServiceProviderConfig)
+		r.Route("/Users", func(r chi.Router) {
+			r.Get("/", scimHandler.HandleListUsers)
+			r.Post("/", scimHandler.HandleCreateUser)
+			r.Get("/{id}", scimHandler.HandleGetUser)
+			r.Put("/{id}", scimHandler.HandleReplaceUser)
+			r.Patch("/{id}", scimHandler.HandlePatchUser)
+			r.Delete("/{id}", scimHandler.HandleDeleteUser)
+		})
+		r.Route("/Groups", func(r chi.Router) {
+			r.Get("/", scimHandler.HandleListGroups)
+			r.Post("/", scimHandler.HandleCreateGroup)
+			r.Get("/{id}", scimHandler.HandleGetGroup)
+			r.Put("/{id}", scimHandler.HandleReplaceGroup)
+			r.Patch("/{id}", scimHandler.HandlePatchGroup)
+			r.Delete("/{id}", scimHandler.HandleDeleteGroup)
+		})
+	})
+
+	// OpenAI- and Anthropic-compatible APIs for the official SDKs (app API keys; only with an inference pipeline)
+	if deps.Pipeline != nil {
+		var embedder handlers.OpenAIEmbedder
+		if deps.Embedder != nil {
+			embedder = deps.Embedder
+		}
+		openAIHandler := handlers.NewOpenAIHandler(deps.Pipeline, deps.Models, embedder, deps.Logger)
+		anthropicHandler := handlers.NewAnthropicHandler(deps.Pipeline, deps.Logger)
+		r.Route("/v1", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(deps.APIKeyMiddleware.RequireAPIKey(handlers.WriteOpenAIUnauthorized))
+				r.With(deps.IdempotencyMiddleware.Handle).Post("/chat/completions", openAIHandler.HandleChatCompletions)
+				r.Post("/embeddings", openAIHandler.HandleEmbeddings)
+				r.Get("/models", openAIHandler.HandleListModels)
+				r.Get("/models/{model}", openAIHandler.HandleGetModel)
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(deps.APIKeyMiddleware.RequireAPIKey(handlers.WriteAnthropicUnauthorized))
+				r.With(deps.IdempotencyMiddleware.Handle).Post("/messages", anthropicHandler.HandleMessages)
+			})
+		})
+	}
+
+	experimentHandler := handlers.NewExperimentHandler(deps.ExperimentService, deps.Logger)
+	templateHandler := handlers.NewTemplateHandler(deps.TemplateService, deps.Logger)
+	roleHandler := handlers.NewRoleHandler(deps.RBACService, deps.Logger)
+	tenantHandler := handlers.NewTenantHandler(deps.TenantService, deps.Logger)
+	sessionHandler := handlers.NewSessionHandler(deps.SessionService, deps.Logger)
+	serviceAccountHandler := handlers.NewServiceAccountHandler(deps.ServiceAccountService, deps.Logger)
+	impersonationHandler := handlers.NewImpersonationHandler(deps.ImpersonationService, deps.Logger)
+	can := deps.AuthMiddleware.RequirePermission
+	// Destructive policy, key and credential operations are not allowed with impersonation tokens
+	notImpersonated := deps.AuthMiddleware.DenyImpersonation
+
 	// API v1 routes
 	r.Route("/api/v1", func(r chi.Router) {
 		// Public routes
 		r.Get("/status", handlers.StatusHandler(deps))
 
 		// Inference endpoints (require authentication)
+		chatHandler := handlers.ChatCompletionHandler(deps)
+		listRequestsHandler := handlers.ListInferenceRequestsHandler(deps)
+		getRequestHandler := handlers.GetInferenceRequestHandler(deps)
+		if deps.Pipeline != nil {
+			inferenceHandler := handlers.NewInferenceHandler(handlers.NewPipelineInferenceService(deps.Pipeline), deps.Logger)
+			chatHandler = inferenceHandler.HandleChatCompletion
+		}
+		if deps.AsyncInferenceService != nil {
+			asyncHandler := handlers.NewAsyncInferenceHandler(deps.AsyncInferenceService, deps.Logger)
+			chatHandler = handlers.WithAsyncMode(asyncHandler.HandleSubmitChatCompletion, chatHandler)
+			listRequestsHandler = asyncHandler.HandleListInferenceRequests
+			getRequestHandler = asyncHandler.HandleGetInferenceRequest
+		}
 		r.Route("/inference", func(r chi.Router) {
 			r.Use(deps.AuthMiddleware.RequireAuth)
 			r.Use(deps.AuthMiddleware.ExtractTenant)
-			r.Post("/chat", handlers.ChatCompletionHandler(deps))
-			r.Get("/requests", handlers.ListInferenceRequestsHandler(deps))
-			r.Get("/requests/{id}", handlers.GetInferenceRequestHandler(deps))
+			r.With(deps.IdempotencyMiddleware.Handle).Post("/chat", chatHandler)
+			r.Get("/requests", listRequestsHandler)
+			r.Get("/requests/{id}", getRequestHandler)
+			r.Post("/requests/{id}/feedback", experimentHandler.HandleSubmitFeedback)
+			if deps.ShadowService != nil {
+				shadowHandler := handlers.NewShadowHandler(deps.ShadowService, deps.Logger)
+				r.With(can(models.PermissionExperimentsRead)).Get("/requests/{id}/shadow", shadowHandler.HandleGetShadowResponses)
+			}
 		})
 
-		// Organization management
+		// Conversation threads (scoped to the authenticated user; only with an inference pipeline)
+		if deps.ConversationService != nil {
+			conversationHandler := handlers.NewConversationHandler(deps.ConversationService, deps.Logger)
+			r.Route("/conversations", func(r chi.Router) {
+				r.Use(deps.AuthMiddleware.RequireAuth)
+				r.Use(deps.AuthMiddleware.ExtractTenant)
+				r.Get("/", conversationHandler.HandleListConversations)
+				r.Post("/", conversationHandler.HandleCreateConversation)
+				r.Get("/{id}", conversationHandler.HandleGetConversation)
+				r.Delete("/{id}", conversationHandler.HandleDeleteConversation)
+				r.Get("/{id}/messages", conversationHandler.HandleListMessages)
+				r.Post("/{id}/messages", conversationHandler.HandleSendMessage)
+			})
+		}
+
+		// Batch inference jobs (only with an inference pipeline)
+		if deps.BatchService != nil {
+			batchHandler := handlers.NewBatchHandler(deps.BatchService, deps.Logger)
+			r.Route("/batches", func(r chi.Router) {
+				r.Use(deps.AuthMiddleware.RequireAuth)
+				r.Use(deps.AuthMiddleware.ExtractTenant)
+				r.Get("/", batchHandler.HandleListBatches)
+				r.Post("/", batchHandler.HandleCreateBatch)
+				r.Get("/{id}", batchHandler.HandleGetBatch)
+				r.Post("/{id}/cancel", batchHandler.HandleCancelBatch)
+				r.Get("/{id}/results", batchHandler.HandleGetBatchResults)
+			})
+		}
+
+		// A/B model experiments
+		r.Route("/experiments", func(r chi.Router) {
+			r.Use(deps.AuthMiddleware.RequireAuth)
+			r.Use(deps.AuthMiddleware.ExtractTenant)
+			r.With(can(models.PermissionExperimentsRead)).Get("/", experimentHandler.HandleListExperiments)
+			r.With(can(models.PermissionExperimentsWrite)).Post("/", experimentHandler.HandleCreateExperiment)
+			r.With(can(models.PermissionExperimentsRead)).Get("/{id}", experimentHandler.HandleGetExperiment)
+			r.With(can(models.PermissionExperimentsWrite)).Post("/{id}/start", experimentHandler.HandleStartExperiment)
+			r.With(can(models.PermissionExperimentsWrite)).Post("/{id}/stop", experimentHandler.HandleStopExperiment)
+			r.With(can(models.PermissionExperimentsRead)).Get("/{id}/report", experimentHandler.HandleGetReport)
+		})
+
+		// Versioned prompt templates
+		r.Route("/templates", func(r chi.Router) {
+			r.Use(deps.AuthMiddleware.RequireAuth)
+			r.Use(deps.AuthMiddleware.ExtractTenant)
+			r.With(can(models.PermissionTemplatesRead)).Get("/", templateHandler.HandleListTemplates)
+			r.With(can(models.PermissionTemplatesWrite)).Post("/", templateHandler.HandleCreateTemplate)
+			r.With(can(models.PermissionTemplatesRead)).Get("/{id}", templateHandler.HandleGetTemplate)
+			r.With(can(models.PermissionTemplatesWrite)).Delete("/{id}", templateHandler.HandleDeleteTemplate)
+			r.With(can(models.PermissionTemplatesRead)).Get("/{id}/versions", templateHandler.HandleListVersions)
+			r.With(can(models.PermissionTemplatesWrite)).Post("/{id}/versions", templateHandler.HandleCreateVersion)
+			r.With(can(models.PermissionTemplatesRead)).Get("/{id}/versions/{version}", templateHandler.HandleGetVersion)
+			r.With(can(models.PermissionTemplatesWrite)).Post("/{id}/rollback", templateHandler.HandleRollback)
+			r.With(can(models.PermissionTemplatesWrite)).Put("/{id}/pins/{appID}", templateHandler.HandlePinVersion)
+			r.With(can(models.PermissionTemplatesWrite)).Delete("/{id}/pins/{appID}", templateHandler.HandleUnpinVersion)
+		})
+
+		// RAG document ingestion (only with an embedder)
+		if deps.IngestionService != nil {
+			ragHandler := handlers.NewRAGHandler(deps.IngestionService, deps.Logger)
+			r.Route("/rag/indexes/{name}/documents", func(r chi.Router) {
+				r.Use(deps.AuthMiddleware.RequireAuth)
+				r.Use(deps.AuthMiddleware.ExtractTenant)
+				r.Use(can(models.PermissionRAGWrite))
+				r.Post("/", ragHandler.HandleIngestDocument)
+				r.Delete("/{documentID}", ragHandler.HandleDeleteDocument)
+			})
+		}
+
+		// Organization management; IDs of other organizations are not found,
+		// except for platform superadmins
 		r.Route("/organizations", func(r chi.Router) {
 			r.Use(deps.AuthMiddleware.RequireAuth)
-			r.Get("/", handlers.ListOrganizationsHandler(deps))
-			r.Post("/", handlers.CreateOrganizationHandler(deps))
-			r.Get("/{id}", handlers.GetOrganizationHandler(deps))
-			r.Put("/{id}", handlers.UpdateOrganizationHandler(deps))
-			r.Delete("/{id}", handlers.DeleteOrganizationHandler(deps))
+			r.Use(deps.AuthMiddleware.ExtractTenant)
+			r.Get("/", tenantHandler.HandleListOrganizations)
+			r.Post("/", tenantHandler.HandleCreateOrganization) // Superadmins only
+			r.Get("/{id}", tenantHandler.HandleGetOrganization)
+			r.With(can(models.PermissionOrganizationsWrite)).Put("/{id}", tenantHandler.HandleUpdateOrganization)
+			r.With(can(models.PermissionOrganizationsWrite), notImpersonated).Delete("/{id}", tenantHandler.HandleDeleteOrganization)
+			r.With(can(models.PermissionOrganizationsWrite)).Post("/{id}/restore", tenantHandler.HandleRestoreOrganization)
 		})
 
 		// Application management
 		r.Route("/applications", func(r chi.Router) {
 			r.Use(deps.AuthMiddleware.RequireAuth)
-			r.Get("/", handlers.ListApplicationsHandler(deps))
-			r.Post("/", handlers.CreateApplicationHandler(deps))
-			r.Get("/{id}", handlers.GetApplicationHandler(deps))
-			r.Put("/{id}", handlers.UpdateApplicationHandler(deps))
-			r.Delete("/{id}", handlers.DeleteApplicationHandler(deps))
+			r.Use(deps.AuthMiddleware.ExtractTenant)
+			r.Get("/", tenantHandler.HandleListApplications)
+			r.With(can(models.PermissionApplicationsWrite)).Post("/", tenantHandler.HandleCreateApplication)
+			r.Get("/{id}", tenantHandler.HandleGetApplication)
+			r.With(can(models.PermissionApplicationsWrite)).Put("/{id}", tenantHandler.HandleUpdateApplication)
+			r.With(can(models.PermissionApplicationsWrite), notImpersonated).Delete("/{id}", tenantHandler.HandleDeleteApplication)
+			r.With(can(models.PermissionApplicationsWrite)).Post("/{id}/restore", tenantHandler.HandleRestoreApplication)
+
+			// Completion webhooks and API keys; limit overrides on keys also
+			// require budgets:override
+			webhookHandler := handlers.NewWebhookHandler(deps.WebhookService, deps.Logger)
+			apiKeyHandler := handlers.NewAPIKeyHandler(deps.APIKeyService, deps.Logger)
+			apiKeyHandler.SetAuthorizer(deps.AuthMiddleware)
+			r.Group(func(r chi.Router) {
+				r.With(can(models.PermissionWebhooksWrite), notImpersonated).Put("/{id}/webhook", webhookHandler.HandleConfigureWebhook)
+				r.With(can(models.PermissionWebhooksWrite), notImpersonated).Delete("/{id}/webhook", webhookHandler.HandleRemoveWebhook)
+				r.With(can(models.PermissionAPIKeysRead)).Get("/{id}/keys", apiKeyHandler.HandleListKeys)
+				r.With(can(models.PermissionAPIKeysWrite), notImpersonated).Post("/{id}/keys", apiKeyHandler.HandleIssueKey)
+				r.With(can(models.PermissionAPIKeysWrite), notImpersonated).Post("/{id}/keys/{keyID}/rotate", apiKeyHandler.HandleRotateKey)
+				r.With(can(models.PermissionAPIKeysWrite), notImpersonated).Delete("/{id}/keys/{keyID}", apiKeyHandler.HandleRevokeKey)
+				r.With(can(models.PermissionAPIKeysWrite), notImpersonated).Put("/{id}/keys/{keyID}/scopes", apiKeyHandler.HandleUpdateScopes)
+				r.With(can(models.PermissionAPIKeysRead)).Get("/{id}/keys/{keyID}/usage", apiKeyHandler.HandleKeyUsage)
+			})
 		})
 
-		// Policy management (require admin role)
+		// Policy management
 		r.Route("/policies", func(r chi.Router) {
 			r.Use(deps.AuthMiddleware.RequireAuth)
-			r.Use(deps.AuthMiddleware.RequireRole("admin"))
-			r.Get("/", handlers.ListPoliciesHandler(deps))
-			r.Post("/", handlers.CreatePolicyHandler(deps))
-			r.Get("/{id}", handlers.GetPolicyHandler(deps))
-			r.Put("/{id}", handlers.UpdatePolicyHandler(deps))
-			r.Delete("/{id}", handlers.DeletePolicyHandler(deps))
+			r.With(can(models.PermissionPoliciesRead)).Get("/", handlers.ListPoliciesHandler(deps))
+			r.With(can(models.PermissionPoliciesWrite), notImpersonated).Post("/", handlers.CreatePolicyHandler(deps))
+			r.With(can(models.PermissionPoliciesRead)).Get("/{id}", handlers.GetPolicyHandler(deps))
+			r.With(can(models.PermissionPoliciesWrite), notImpersonated).Put("/{id}", handlers.UpdatePolicyHandler(deps))
+			r.With(can(models.PermissionPoliciesWrite), notImpersonated).Delete("/{id}", handlers.DeletePolicyHandler(deps))
 		})
 
-		// Audit logs (require admin role)
+		// Audit logs
 		r.Route("/audit", func(r chi.Router) {
 			r.Use(deps.AuthMiddleware.RequireAuth)
-			r.Use(deps.AuthMiddleware.RequireRole("admin"))
+			r.Use(can(models.PermissionAuditRead))
 			r.Get("/logs", handlers.ListAuditLogsHandler(deps))
 			r.Get("/logs/{id}", handlers.GetAuditLogHandler(deps))
 		})
 
+		// Role-to-permission mappings of the organization
+		r.Route("/roles", func(r chi.Router) {
+			r.Use(deps.AuthMiddleware.RequireAuth)
+			r.Use(deps.AuthMiddleware.ExtractTenant)
+			r.With(can(models.PermissionRolesRead)).Get("/", roleHandler.HandleListRoles)
+			r.With(can(models.PermissionRolesRead)).Get("/permissions", roleHandler.HandleListPermissions)
+			r.With(can(models.PermissionRolesWrite), notImpersonated).Post("/", roleHandler.HandleCreateRole)
+			r.With(can(models.PermissionRolesWrite), notImpersonated).Put("/{name}", roleHandler.HandleUpdateRole)
+			r.With(can(models.PermissionRolesWrite), notImpersonated).Delete("/{name}", roleHandler.HandleDeleteRole)
+		})
+
+		// Service accounts of the organization (OAuth2 client credentials)
+		r.Route("/service-accounts", func(r chi.Router) {
+			r.Use(deps.AuthMiddleware.RequireAuth)
+			r.Use(deps.AuthMiddleware.ExtractTenant)
+			r.With(can(models.PermissionServiceAccountsRead)).Get("/", serviceAccountHandler.HandleListServiceAccounts)
+			r.With(can(models.PermissionServiceAccountsWrite), notImpersonated).Post("/", serviceAccountHandler.HandleCreateServiceAccount)
+			r.With(can(models.PermissionServiceAccountsRead)).Get("/{id}", serviceAccountHandler.HandleGetServiceAccount)
+			r.With(can(models.PermissionServiceAccountsWrite), notImpersonated).Patch("/{id}", serviceAccountHandler.HandleUpdateServiceAccount)
+			r.With(can(models.PermissionServiceAccountsWrite), notImpersonated).Post("/{id}/rotate-secret", serviceAccountHandler.HandleRotateSecret)
+			r.With(can(models.PermissionServiceAccountsWrite), notImpersonated).Delete("/{id}", serviceAccountHandler.HandleDeleteServiceAccount)
+		})
+
+		// SCIM provisioning settings of the organization
+		r.Route("/scim", func(r chi.Router) {
+			r.Use(deps.AuthMiddleware.RequireAuth)
+			r.Use(deps.AuthMiddleware.ExtractTenant)
+			r.With(can(models.PermissionSCIMRead)).Get("/", scimHandler.HandleGetConfig)
+			r.With(can(models.PermissionSCIMWrite), notImpersonated).Post("/token", scimHandler.HandleRotateToken)
+			r.With(can(models.PermissionSCIMWrite), notImpersonated).Delete("/token", scimHandler.HandleRevokeToken)
+			r.With(can(models.PermissionSCIMWrite), notImpersonated).Put("/group-roles", scimHandler.HandleSetGroupRoles)
+		})
+
 		// User management
 		r.Route("/users", func(r chi.Router) {
 			r.Use(deps.AuthMiddleware.RequireAuth)
-			r.Get("/", handlers.ListUsersHandler(deps))
 			r.Get("/me", handlers.GetCurrentUserHandler(deps))
-			r.Get("/{id}", handlers.GetUserHandler(deps))
-			r.Put("/{id}", handlers.UpdateUserHandler(deps))
+			r.Group(func(r chi.Router) {
+				r.Use(deps.AuthMiddleware.ExtractTenant)
+				r.Get("/", tenantHandler.HandleListUsers)
+				r.Get("/{id}", tenantHandler.HandleGetUser)
+				r.With(can(models.PermissionUsersWrite), notImpersonated).Put("/{id}", tenantHandler.HandleUpdateUser)
+				r.With(can(models.PermissionUsersImpersonate), notImpersonated).Post("/{id}/impersonate", impersonationHandler.HandleImpersonateUser)
+			})
+		})
+
+		// The signed-in user's sessions
+		r.Route("/sessions", func(r chi.Router) {
+			r.Use(deps.AuthMiddleware.RequireAuth)
+			r.Use(deps.AuthMiddleware.ExtractTenant)
+			r.Get("/", sessionHandler.HandleListSessions)
+			r.With(notImpersonated).Post("/revoke-all", sessionHandler.HandleRevokeAllSessions)
+			r.With(notImpersonated).Delete("/{id}", sessionHandler.HandleRevokeSession)
 		})
 
 		// Metrics and analytics
@@ -0,0 +1,347 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/audit"
+	"go.uber.org/zap"
+)
+
+const (
+	// KeyPrefix starts every key issued by the gateway, telling keys apart
+	// from JWTs in an Authorization header
+	KeyPrefix = "lcp_"
+
+	// DisplayPrefixLength is the number of leading characters of a key that
+	// are stored and listed to identify it
+	DisplayPrefixLength = len(KeyPrefix) + 8
+
+	// DefaultGracePeriod is how long a rotated key remains valid by default
+	DefaultGracePeriod = 24 * time.Hour
+
+	// MaxGracePeriod bounds how long a rotated key remains valid
+	MaxGracePeriod = 30 * 24 * time.Hour
+
+	// MaxNameLength bounds key names
+	MaxNameLength = 255
+
+	// MaxUsageDays bounds the days of usage returned for a key
+	MaxUsageDays = 90
+
+	// keyBytes is the number of random bytes of a key
+	keyBytes = 24
+)
+
+// IssuedKey is returned when a key is issued or rotated; the plaintext key is
+// only shown at this point
+type IssuedKey struct {
+	*models.APIKey
+	Key string `json:"key"`
+}
+
+// IssueRequest represents a request to issue a key for an application
+type IssueRequest struct {
+	OrgID     uuid.UUID
+	AppID     uuid.UUID
+	Name      string
+	Scopes    *models.APIKeyScopes // Optional restrictions; nil allows everything
+	ExpiresAt *time.Time           // Optional; must be in the future
+	CreatedBy *uuid.UUID
+}
+
+// APIKeyService issues, rotates, revokes and authenticates application API keys
+type APIKeyService struct {
+	keyRepo      repositories.APIKeyRepository
+	appRepo      repositories.ApplicationRepository
+	txManager    repositories.TransactionManager
+	auditService *audit.AuditService
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewAPIKeyService creates a new APIKeyService instance
+func NewAPIKeyService(keyRepo repositories.APIKeyRepository, appRepo repositories.ApplicationRepository, txManager repositories.TransactionManager, logger *zap.Logger) *APIKeyService {
+	return &APIKeyService{
+		keyRepo:   keyRepo,
+		appRepo:   appRepo,
+		txManager: txManager,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// SetAuditService records key issuance, rotation, scope changes and revocation in the audit log
+func (s *APIKeyService) SetAuditService(auditService *audit.AuditService) {
+	s.auditService = auditService
+}
+
+// HashKey returns the hash under which a key is stored
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Issue creates a new key for an application of the organization
+func (s *APIKeyService) Issue(ctx context.Context, req IssueRequest) (*IssuedKey, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > MaxNameLength {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "name must be between 1 and 255 characters", nil)
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(s.now()) {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "expiry must be in the future", nil)
+	}
+	if err := validateScopes(req.Scopes); err != nil {
+		return nil, err
+	}
+	if err := s.checkApplication(ctx, req.OrgID, req.AppID); err != nil {
+		return nil, err
+	}
+
+	issued, err := s.newKey(req.OrgID, req.AppID, name, req.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	issued.ExpiresAt = req.ExpiresAt
+	if err := issued.SetScopes(req.Scopes); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to issue api key", err)
+	}
+	if err := s.keyRepo.Create(ctx, issued.APIKey); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to issue api key", err)
+	}
+
+	s.logger.Info("api key issued",
+		zap.String("key_id", issued.ID.String()),
+		zap.String("app_id", req.AppID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogAPIKeyIssued(issued.APIKey, req.CreatedBy); err != nil {
+			s.logger.Error("failed to log api key issuance", zap.Error(err))
+		}
+	}
+
+	return issued, nil
+}
+
+// UpdateScopes replaces the restrictions of a key
+func (s *APIKeyService) UpdateScopes(ctx context.Context, orgID, appID, keyID uuid.UUID, scopes *models.APIKeyScopes, updatedBy *uuid.UUID) (*models.APIKey, error) {
+	if err := validateScopes(scopes); err != nil {
+		return nil, err
+	}
+
+	key, err := s.getKey(ctx, orgID, appID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := key.SetScopes(scopes); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to update api key", err)
+	}
+	if err := s.keyRepo.Update(ctx, key); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to update api key", err)
+	}
+
+	s.logger.Info("api key scopes updated",
+		zap.String("key_id", keyID.String()),
+		zap.String("app_id", appID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogAPIKeyUpdated(key, updatedBy); err != nil {
+			s.logger.Error("failed to log api key update", zap.Error(err))
+		}
+	}
+
+	return key, nil
+}
+
+// List lists the keys of an application of the organization, newest first
+func (s *APIKeyService) List(ctx context.Context, orgID, appID uuid.UUID) ([]*models.APIKey, error) {
+	if err := s.checkApplication(ctx, orgID, appID); err != nil {
+		return nil, err
+	}
+
+	keys, err := s.keyRepo.ListByApp(ctx, appID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list api keys", err)
+	}
+	return keys, nil
+}
+
+// Rotate issues a replacement for a key. The old key remains valid for the
+// grace period, so that callers can switch over without downtime; a zero
+// grace period revokes it immediately.
+func (s *APIKeyService) Rotate(ctx context.Context, orgID, appID, keyID uuid.UUID, grace time.Duration, rotatedBy *uuid.UUID) (*IssuedKey, error) {
+	if grace < 0 || grace > MaxGracePeriod {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "grace period must be between 0 and 30 days", nil)
+	}
+
+	old, err := s.getKey(ctx, orgID, appID, keyID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if !old.IsActive(now) {
+		return nil, services.NewDomainError(services.ErrorTypeConflict, "api key is already revoked or expired", nil)
+	}
+
+	// The replacement keeps the old key's restrictions and expiry
+	issued, err := s.newKey(orgID, appID, old.Name, rotatedBy)
+	if err != nil {
+		return nil, err
+	}
+	issued.Scopes = old.Scopes
+	issued.ExpiresAt = old.ExpiresAt
+
+	if grace == 0 {
+		old.RevokedAt = &now
+	} else if expiresAt := now.Add(grace); old.ExpiresAt == nil || expiresAt.Before(*old.ExpiresAt) {
+		old.ExpiresAt = &expiresAt
+	}
+
+	err = s.txManager.InTransaction(ctx, func(ctx context.Context, tx repositories.Transaction) error {
+		if err := s.keyRepo.Create(ctx, issued.APIKey); err != nil {
+			return err
+		}
+		return s.keyRepo.Update(ctx, old)
+	})
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to rotate api key", err)
+	}
+
+	s.logger.Info("api key rotated",
+		zap.String("key_id", old.ID.String()),
+		zap.String("new_key_id", issued.ID.String()),
+		zap.String("app_id", appID.String()),
+		zap.Duration("grace_period", grace))
+	if s.auditService != nil {
+		if err := s.auditService.LogAPIKeyRotated(old, issued.APIKey, rotatedBy, grace); err != nil {
+			s.logger.Error("failed to log api key rotation", zap.Error(err))
+		}
+	}
+
+	return issued, nil
+}
+
+// Revoke invalidates a key immediately
+func (s *APIKeyService) Revoke(ctx context.Context, orgID, appID, keyID uuid.UUID, revokedBy *uuid.UUID) error {
+	key, err := s.getKey(ctx, orgID, appID, keyID)
+	if err != nil {
+		return err
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+
+	now := s.now()
+	key.RevokedAt = &now
+	if err := s.keyRepo.Update(ctx, key); err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to revoke api key", err)
+	}
+
+	s.logger.Info("api key revoked",
+		zap.String("key_id", keyID.String()),
+		zap.String("app_id", appID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogAPIKeyRevoked(key, revokedBy); err != nil {
+			s.logger.Error("failed to log api key revocation", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// Authenticate resolves a plaintext key to the active key it matches
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (*models.APIKey, error) {
+	apiKey, err := s.keyRepo.GetByHash(ctx, HashKey(key))
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "invalid api key", err)
+	}
+	if !apiKey.IsActive(s.now()) {
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "api key is revoked or expired", nil)
+	}
+	return apiKey, nil
+}
+
+// RecordUsage adds a request made with a key to the key's usage of the day
+func (s *APIKeyService) RecordUsage(ctx context.Context, keyID uuid.UUID, tokens int, cost float64, failed bool) error {
+	now := s.now().UTC()
+	usage := &models.APIKeyUsage{
+		KeyID:    keyID,
+		Day:      now.Truncate(24 * time.Hour),
+		Requests: 1,
+		Tokens:   tokens,
+		Cost:     cost,
+	}
+	if failed {
+		usage.FailedRequests = 1
+	}
+	if err := s.keyRepo.RecordUsage(ctx, usage, now); err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to record api key usage", err)
+	}
+	return nil
+}
+
+// Usage returns a key's daily usage over the last days, oldest first
+func (s *APIKeyService) Usage(ctx context.Context, orgID, appID, keyID uuid.UUID, days int) ([]*models.APIKeyUsage, error) {
+	if days < 1 || days > MaxUsageDays {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "days must be between 1 and 90", nil)
+	}
+	if _, err := s.getKey(ctx, orgID, appID, keyID); err != nil {
+		return nil, err
+	}
+
+	end := s.now().UTC().Truncate(24 * time.Hour)
+	start := end.AddDate(0, 0, -(days - 1))
+	usage, err := s.keyRepo.GetUsage(ctx, keyID, start, end)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to get api key usage", err)
+	}
+	return usage, nil
+}
+
+// validateScopes validates optional key restrictions
+func validateScopes(scopes *models.APIKeyScopes) error {
+	if scopes == nil {
+		return nil
+	}
+	if err := scopes.Validate(); err != nil {
+		return services.NewDomainError(services.ErrorTypeValidation, err.Error(), err)
+	}
+	return nil
+}
+
+// newKey generates a key and its stored record
+func (s *APIKeyService) newKey(orgID, appID uuid.UUID, name string, createdBy *uuid.UUID) (*IssuedKey, error) {
+	buf := make([]byte, keyBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to generate api key", err)
+	}
+
+	key := KeyPrefix + hex.EncodeToString(buf)
+	apiKey := models.NewAPIKey(orgID, appID, name, key[:DisplayPrefixLength], HashKey(key), createdBy)
+	apiKey.CreatedAt = s.now()
+	return &IssuedKey{APIKey: apiKey, Key: key}, nil
+}
+
+// getKey retrieves a key of an application of the organization
+func (s *APIKeyService) getKey(ctx context.Context, orgID, appID, keyID uuid.UUID) (*models.APIKey, error) {
+	key, err := s.keyRepo.GetByID(ctx, keyID)
+	if err != nil || key.OrgID != orgID || key.AppID != appID {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "api key not found", err)
+	}
+	return key, nil
+}
+
+// checkApplication verifies that the application belongs to the organization
+func (s *APIKeyService) checkApplication(ctx context.Context, orgID, appID uuid.UUID) error {
+	app, err := s.appRepo.GetByID(ctx, appID)
+	if err != nil || app.OrgID != orgID {
+		return services.NewDomainError(services.ErrorTypeNotFound, "application not found", err)
+	}
+	return nil
+}
@@ -0,0 +1,387 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"go.uber.org/zap"
+)
+
+// fakeKeyRepository stores keys in memory
+type fakeKeyRepository struct {
+	repositories.APIKeyRepository
+	keys  map[uuid.UUID]*models.APIKey
+	usage []*models.APIKeyUsage
+}
+
+func (r *fakeKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	copied := *key
+	r.keys[key.ID] = &copied
+	return nil
+}
+
+func (r *fakeKeyRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.APIKey, error) {
+	if key, ok := r.keys[id]; ok {
+		copied := *key
+		return &copied, nil
+	}
+	return nil, errors.New("api key not found")
+}
+
+func (r *fakeKeyRepository) GetByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	for _, key := range r.keys {
+		if key.KeyHash == keyHash {
+			copied := *key
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("api key not found")
+}
+
+func (r *fakeKeyRepository) ListByApp(ctx context.Context, appID uuid.UUID) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	for _, key := range r.keys {
+		if key.AppID == appID {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (r *fakeKeyRepository) Update(ctx context.Context, key *models.APIKey) error {
+	copied := *key
+	r.keys[key.ID] = &copied
+	return nil
+}
+
+func (r *fakeKeyRepository) RecordUsage(ctx context.Context, usage *models.APIKeyUsage, usedAt time.Time) error {
+	for _, u := range r.usage {
+		if u.KeyID == usage.KeyID && u.Day.Equal(usage.Day) {
+			u.Requests += usage.Requests
+			u.FailedRequests += usage.FailedRequests
+			u.Tokens += usage.Tokens
+			u.Cost += usage.Cost
+			return nil
+		}
+	}
+	copied := *usage
+	r.usage = append(r.usage, &copied)
+	if key, ok := r.keys[usage.KeyID]; ok {
+		key.LastUsedAt = &usedAt
+	}
+	return nil
+}
+
+func (r *fakeKeyRepository) GetUsage(ctx context.Context, keyID uuid.UUID, start, end time.Time) ([]*models.APIKeyUsage, error) {
+	var usage []*models.APIKeyUsage
+	for _, u := range r.usage {
+		if u.KeyID == keyID && !u.Day.Before(start) && !u.Day.After(end) {
+			usage = append(usage, u)
+		}
+	}
+	return usage, nil
+}
+
+// fakeAppRepository serves applications from a map
+type fakeAppRepository struct {
+	repositories.ApplicationRepository
+	apps map[uuid.UUID]*models.Application
+}
+
+func (r *fakeAppRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Application, error) {
+	if app, ok := r.apps[id]; ok {
+		return app, nil
+	}
+	return nil, errors.New("application not found")
+}
+
+// fakeTxManager runs transactional functions directly
+type fakeTxManager struct{}
+
+func (fakeTxManager) Begin(ctx context.Context) (repositories.Transaction, error) {
+	return nil, nil
+}
+
+func (fakeTxManager) InTransaction(ctx context.Context, fn func(ctx context.Context, tx repositories.Transaction) error) error {
+	return fn(ctx, nil)
+}
+
+type keyFixture struct {
+	service *APIKeyService
+	repo    *fakeKeyRepository
+	orgID   uuid.UUID
+	appID   uuid.UUID
+	now     time.Time
+}
+
+func newKeyFixture() *keyFixture {
+	f := &keyFixture{
+		repo:  &fakeKeyRepository{keys: map[uuid.UUID]*models.APIKey{}},
+		orgID: uuid.New(),
+		appID: uuid.New(),
+		now:   time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	apps := &fakeAppRepository{apps: map[uuid.UUID]*models.Application{
+		f.appID: {ID: f.appID, OrgID: f.orgID},
+	}}
+	f.service = NewAPIKeyService(f.repo, apps, fakeTxManager{}, zap.NewNop())
+	f.service.now = func() time.Time { return f.now }
+	return f
+}
+
+func (f *keyFixture) issue(t *testing.T) *IssuedKey {
+	issued, err := f.service.Issue(context.Background(), IssueRequest{OrgID: f.orgID, AppID: f.appID, Name: "server"})
+	require.NoError(t, err)
+	return issued
+}
+
+func TestAPIKeyService_Issue(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("issues a key stored by hash", func(t *testing.T) {
+		f := newKeyFixture()
+
+		issued := f.issue(t)
+
+		assert.True(t, strings.HasPrefix(issued.Key, KeyPrefix))
+		assert.Equal(t, issued.Key[:DisplayPrefixLength], issued.Prefix)
+		assert.Equal(t, HashKey(issued.Key), issued.KeyHash)
+
+		stored := f.repo.keys[issued.ID]
+		require.NotNil(t, stored)
+		assert.NotContains(t, stored.KeyHash, issued.Key)
+
+		key, err := f.service.Authenticate(ctx, issued.Key)
+		require.NoError(t, err)
+		assert.Equal(t, f.appID, key.AppID)
+		assert.Equal(t, f.orgID, key.OrgID)
+	})
+
+	t.Run("application of another organization", func(t *testing.T) {
+		f := newKeyFixture()
+
+		_, err := f.service.Issue(ctx, IssueRequest{OrgID: uuid.New(), AppID: f.appID, Name: "server"})
+
+		assert.True(t, services.IsNotFoundError(err))
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		f := newKeyFixture()
+
+		_, err := f.service.Issue(ctx, IssueRequest{OrgID: f.orgID, AppID: f.appID, Name: "  "})
+
+		assert.True(t, services.IsValidationError(err))
+	})
+
+	t.Run("with scopes and expiry", func(t *testing.T) {
+		f := newKeyFixture()
+		expiresAt := f.now.Add(24 * time.Hour)
+
+		issued, err := f.service.Issue(ctx, IssueRequest{
+			OrgID:     f.orgID,
+			AppID:     f.appID,
+			Name:      "ci",
+			Scopes:    &models.APIKeyScopes{AllowedModels: []string{"gpt-4o*"}},
+			ExpiresAt: &expiresAt,
+		})
+		require.NoError(t, err)
+
+		scopes, err := f.repo.keys[issued.ID].GetScopes()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"gpt-4o*"}, scopes.AllowedModels)
+		assert.Equal(t, expiresAt, *f.repo.keys[issued.ID].ExpiresAt)
+	})
+
+	t.Run("invalid scopes", func(t *testing.T) {
+		f := newKeyFixture()
+
+		_, err := f.service.Issue(ctx, IssueRequest{
+			OrgID:  f.orgID,
+			AppID:  f.appID,
+			Name:   "ci",
+			Scopes: &models.APIKeyScopes{AllowedCIDRs: []string{"not-a-network"}},
+		})
+
+		assert.True(t, services.IsValidationError(err))
+	})
+
+	t.Run("expiry in the past", func(t *testing.T) {
+		f := newKeyFixture()
+		expiresAt := f.now.Add(-time.Minute)
+
+		_, err := f.service.Issue(ctx, IssueRequest{OrgID: f.orgID, AppID: f.appID, Name: "ci", ExpiresAt: &expiresAt})
+
+		assert.True(t, services.IsValidationError(err))
+	})
+}
+
+func TestAPIKeyService_UpdateScopes(t *testing.T) {
+	ctx := context.Background()
+	f := newKeyFixture()
+	issued := f.issue(t)
+
+	key, err := f.service.UpdateScopes(ctx, f.orgID, f.appID, issued.ID, &models.APIKeyScopes{AllowedProviders: []string{"openai"}}, nil)
+	require.NoError(t, err)
+	scopes, err := key.GetScopes()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"openai"}, scopes.AllowedProviders)
+
+	t.Run("key of another application", func(t *testing.T) {
+		_, err := f.service.UpdateScopes(ctx, f.orgID, uuid.New(), issued.ID, &models.APIKeyScopes{}, nil)
+
+		assert.True(t, services.IsNotFoundError(err))
+	})
+}
+
+func TestAPIKeyService_Rotate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("old key stays valid for the grace period", func(t *testing.T) {
+		f := newKeyFixture()
+		old := f.issue(t)
+
+		replacement, err := f.service.Rotate(ctx, f.orgID, f.appID, old.ID, time.Hour, nil)
+		require.NoError(t, err)
+		assert.NotEqual(t, old.Key, replacement.Key)
+		assert.Equal(t, old.Name, replacement.Name)
+
+		_, err = f.service.Authenticate(ctx, old.Key)
+		assert.NoError(t, err)
+		_, err = f.service.Authenticate(ctx, replacement.Key)
+		assert.NoError(t, err)
+
+		f.now = f.now.Add(time.Hour)
+		_, err = f.service.Authenticate(ctx, old.Key)
+		assert.Error(t, err)
+		_, err = f.service.Authenticate(ctx, replacement.Key)
+		assert.NoError(t, err)
+	})
+
+	t.Run("zero grace period revokes immediately", func(t *testing.T) {
+		f := newKeyFixture()
+		old := f.issue(t)
+
+		_, err := f.service.Rotate(ctx, f.orgID, f.appID, old.ID, 0, nil)
+		require.NoError(t, err)
+
+		_, err = f.service.Authenticate(ctx, old.Key)
+		assert.Error(t, err)
+		assert.NotNil(t, f.repo.keys[old.ID].RevokedAt)
+	})
+
+	t.Run("revoked key cannot be rotated", func(t *testing.T) {
+		f := newKeyFixture()
+		old := f.issue(t)
+		require.NoError(t, f.service.Revoke(ctx, f.orgID, f.appID, old.ID, nil))
+
+		_, err := f.service.Rotate(ctx, f.orgID, f.appID, old.ID, time.Hour, nil)
+
+		assert.True(t, services.IsConflictError(err))
+	})
+
+	t.Run("replacement inherits scopes", func(t *testing.T) {
+		f := newKeyFixture()
+		old := f.issue(t)
+		_, err := f.service.UpdateScopes(ctx, f.orgID, f.appID, old.ID, &models.APIKeyScopes{AllowedModels: []string{"claude-*"}}, nil)
+		require.NoError(t, err)
+
+		replacement, err := f.service.Rotate(ctx, f.orgID, f.appID, old.ID, time.Hour, nil)
+		require.NoError(t, err)
+
+		scopes, err := f.repo.keys[replacement.ID].GetScopes()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"claude-*"}, scopes.AllowedModels)
+	})
+
+	t.Run("grace period out of range", func(t *testing.T) {
+		f := newKeyFixture()
+		old := f.issue(t)
+
+		_, err := f.service.Rotate(ctx, f.orgID, f.appID, old.ID, MaxGracePeriod+time.Second, nil)
+
+		assert.True(t, services.IsValidationError(err))
+	})
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("revoked key is rejected", func(t *testing.T) {
+		f := newKeyFixture()
+		issued := f.issue(t)
+
+		require.NoError(t, f.service.Revoke(ctx, f.orgID, f.appID, issued.ID, nil))
+
+		_, err := f.service.Authenticate(ctx, issued.Key)
+		assert.Error(t, err)
+
+		keys, err := f.service.List(ctx, f.orgID, f.appID)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.NotNil(t, keys[0].RevokedAt)
+	})
+
+	t.Run("key of another application", func(t *testing.T) {
+		f := newKeyFixture()
+		issued := f.issue(t)
+
+		err := f.service.Revoke(ctx, f.orgID, uuid.New(), issued.ID, nil)
+
+		assert.True(t, services.IsNotFoundError(err))
+	})
+}
+
+func TestAPIKeyService_Usage(t *testing.T) {
+	ctx := context.Background()
+	f := newKeyFixture()
+	issued := f.issue(t)
+
+	require.NoError(t, f.service.RecordUsage(ctx, issued.ID, 100, 0.01, false))
+	require.NoError(t, f.service.RecordUsage(ctx, issued.ID, 0, 0, true))
+	f.now = f.now.Add(24 * time.Hour)
+	require.NoError(t, f.service.RecordUsage(ctx, issued.ID, 50, 0.005, false))
+
+	usage, err := f.service.Usage(ctx, f.orgID, f.appID, issued.ID, 7)
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	assert.Equal(t, 2, usage[0].Requests)
+	assert.Equal(t, 1, usage[0].FailedRequests)
+	assert.Equal(t, 100, usage[0].Tokens)
+	assert.Equal(t, 1, usage[1].Requests)
+	assert.NotNil(t, f.repo.keys[issued.ID].LastUsedAt)
+
+	t.Run("today only", func(t *testing.T) {
+		usage, err := f.service.Usage(ctx, f.orgID, f.appID, issued.ID, 1)
+		require.NoError(t, err)
+		assert.Len(t, usage, 1)
+	})
+
+	t.Run("days out of range", func(t *testing.T) {
+		_, err := f.service.Usage(ctx, f.orgID, f.appID, issued.ID, MaxUsageDays+1)
+
+		assert.True(t, services.IsValidationError(err))
+	})
+
+	t.Run("key of another application", func(t *testing.T) {
+		_, err := f.service.Usage(ctx, f.orgID, uuid.New(), issued.ID, 7)
+
+		assert.True(t, services.IsNotFoundError(err))
+	})
+}
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	f := newKeyFixture()
+
+	_, err := f.service.Authenticate(context.Background(), "lcp_unknown")
+
+	assert.Error(t, err)
+}
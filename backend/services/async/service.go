@@ -0,0 +1,342 @@
+package async
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/webhook"
+	"go.uber.org/zap"
+)
+
+const (
+	// ErrCodeQueueFull is returned when no more requests can be accepted
+	ErrCodeQueueFull = "QUEUE_FULL"
+
+	// DefaultListLimit and MaxListLimit bound request listing
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+
+	// ErrCodeAbandoned marks requests that were still queued or running when
+	// the process stopped
+	ErrCodeAbandoned = "ABANDONED"
+)
+
+// Completer runs a chat completion through the inference pipeline, which
+// records the outcome on the request's reserved record
+type Completer interface {
+	ProcessChatCompletion(ctx context.Context, req *inference.CompletionRequest) (*inference.CompletionResponse, error)
+}
+
+// Notifier delivers an event to an application's webhook
+type Notifier interface {
+	Deliver(ctx context.Context, appID uuid.UUID, event *webhook.Event) error
+}
+
+// Config holds configuration for the AsyncService
+type Config struct {
+	WorkerCount    int           // Number of requests processed concurrently
+	QueueSize      int           // Accepted requests waiting for a worker
+	RequestTimeout time.Duration // Deadline for one completion
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() Config {
+	return Config{
+		WorkerCount:    8,
+		QueueSize:      1000,
+		RequestTimeout: 10 * time.Minute,
+	}
+}
+
+// task is an accepted request waiting for a worker
+type task struct {
+	record  *models.InferenceRequest
+	request *inference.CompletionRequest
+}
+
+// AsyncService accepts chat completions and runs them through the inference
+// pipeline in the background. Submit reserves the inference request record;
+// the pipeline records the outcome on it, and the service only records
+// requests it could not run. Completion is announced to the application's
+// webhook.
+//
+// Accepted requests are queued in memory: on start, requests a previous
+// process left pending or processing are failed, to be resubmitted.
+type AsyncService struct {
+	inferenceRepo repositories.InferenceRequestRepository
+	completer     Completer
+	notifier      Notifier
+	logger        *zap.Logger
+	config        Config
+
+	queue   chan *task
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mu      sync.RWMutex
+	started bool
+}
+
+// NewAsyncService creates a new AsyncService instance; notifier may be nil
+func NewAsyncService(inferenceRepo repositories.InferenceRequestRepository, completer Completer, notifier Notifier, logger *zap.Logger, config Config) *AsyncService {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &AsyncService{
+		inferenceRepo: inferenceRepo,
+		completer:     completer,
+		notifier:      notifier,
+		logger:        logger,
+		config:        config,
+		queue:         make(chan *task, config.QueueSize),
+		ctx:           ctx,
+		cancel:        cancel,
+	}
+}
+
+// Start fails the requests left unfinished by a previous process and starts
+// the background workers
+func (s *AsyncService) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("async service already started")
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
+	abandoned, err := s.inferenceRepo.FailUnfinished(ctx, time.Now(), ErrCodeAbandoned, "the gateway restarted before the request completed; resubmit it")
+	cancel()
+	if err != nil {
+		s.logger.Error("failed to fail abandoned inference requests", zap.Error(err))
+	} else if abandoned > 0 {
+		s.logger.Warn("failed abandoned inference requests", zap.Int64("count", abandoned))
+	}
+
+	for i := 0; i < s.config.WorkerCount; i++ {
+		s.wg.Add(1)
+		go s.worker(i)
+	}
+
+	s.started = true
+	s.logger.Info("started async inference service",
+		zap.Int("worker_count", s.config.WorkerCount),
+		zap.Int("queue_size", s.config.QueueSize))
+
+	return nil
+}
+
+// Stop stops accepting requests and waits for queued requests to finish
+func (s *AsyncService) Stop(timeout time.Duration) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return fmt.Errorf("async service not started")
+	}
+	s.started = false
+	close(s.queue)
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.logger.Info("async inference service stopped gracefully")
+		s.cancel()
+		return nil
+	case <-time.After(timeout):
+		s.cancel()
+		return fmt.Errorf("async service stop timeout after %v", timeout)
+	}
+}
+
+// Submit records a pending inference request and queues it. The returned
+// record's ID identifies the request when polling for its result.
+func (s *AsyncService) Submit(ctx context.Context, req *inference.CompletionRequest) (*models.InferenceRequest, error) {
+	req.InferenceID = uuid.New()
+	if req.RequestID == "" {
+		req.RequestID = req.InferenceID.String()
+	}
+
+	messages, _ := json.Marshal(req.Messages)
+	record := &models.InferenceRequest{
+		ID:        req.InferenceID,
+		OrgID:     req.OrgID,
+		AppID:     req.AppID,
+		UserID:    req.UserID,
+		RequestID: req.RequestID,
+		Status:    models.InferenceStatusPending,
+		Model:     req.Model,
+		Messages:  messages,
+		IPAddress: req.IPAddress,
+		UserAgent: req.UserAgent,
+		CreatedAt: time.Now(),
+	}
+	if req.Provider != nil {
+		record.Provider = *req.Provider
+	}
+	if req.APIKey != nil {
+		record.APIKeyID = &req.APIKey.ID
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.started {
+		return nil, queueFullError("async inference is not accepting requests")
+	}
+
+	if err := s.inferenceRepo.Create(ctx, record); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to record inference request", err)
+	}
+
+	// The worker owns the queued record; the caller gets a snapshot
+	accepted := *record
+
+	select {
+	case s.queue <- &task{record: record, request: req}:
+	default:
+		record.MarkAsFailed(ErrCodeQueueFull, "async inference queue is full")
+		if err := s.inferenceRepo.Update(ctx, record); err != nil {
+			s.logger.Error("failed to record rejected inference request", zap.Error(err))
+		}
+		return nil, queueFullError("async inference queue is full")
+	}
+
+	s.logger.Debug("async inference request queued",
+		zap.String("inference_id", record.ID.String()),
+		zap.String("app_id", req.AppID.String()))
+
+	return &accepted, nil
+}
+
+// GetRequest retrieves an inference request of the organization
+func (s *AsyncService) GetRequest(ctx context.Context, id, orgID uuid.UUID) (*models.InferenceRequest, error) {
+	record, err := s.inferenceRepo.GetByID(ctx, id)
+	if err != nil || record.OrgID != orgID {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "inference request not found", err)
+	}
+	return record, nil
+}
+
+// ListRequests lists the organization's inference requests, newest first
+func (s *AsyncService) ListRequests(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.InferenceRequest, error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	limit = min(limit, MaxListLimit)
+	offset = max(offset, 0)
+
+	records, err := s.inferenceRepo.GetByOrgID(ctx, orgID, limit, offset)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list inference requests", err)
+	}
+	if records == nil {
+		records = []*models.InferenceRequest{}
+	}
+	return records, nil
+}
+
+// worker processes queued requests until the queue is closed
+func (s *AsyncService) worker(id int) {
+	defer s.wg.Done()
+
+	for t := range s.queue {
+		s.process(t)
+	}
+
+	s.logger.Debug("async inference worker stopped", zap.Int("worker_id", id))
+}
+
+// process runs one request and announces its outcome, which the pipeline
+// has recorded
+func (s *AsyncService) process(t *task) {
+	record := t.record
+	record.MarkAsProcessing()
+	if err := s.inferenceRepo.Update(s.ctx, record); err != nil {
+		s.logger.Warn("failed to mark inference request as processing",
+			zap.String("inference_id", record.ID.String()),
+			zap.Error(err))
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.config.RequestTimeout)
+	response, err := s.completer.ProcessChatCompletion(ctx, t.request)
+	cancel()
+
+	eventType := webhook.EventInferenceCompleted
+	if err != nil {
+		eventType = webhook.EventInferenceFailed
+		applyError(record, err)
+	} else {
+		applyResponse(record, response)
+	}
+
+	// Deliver outside the worker so slow endpoints and retries don't hold up the queue
+	if s.notifier != nil {
+		s.wg.Add(1)
+		go s.notify(record, webhook.NewEvent(eventType, record))
+	}
+}
+
+// notify delivers the outcome of a request to its application's webhook
+func (s *AsyncService) notify(record *models.InferenceRequest, event *webhook.Event) {
+	defer s.wg.Done()
+
+	if err := s.notifier.Deliver(s.ctx, record.AppID, event); err != nil {
+		s.logger.Error("failed to deliver inference webhook",
+			zap.String("inference_id", record.ID.String()),
+			zap.String("app_id", record.AppID.String()),
+			zap.Error(err))
+	}
+}
+
+// applyResponse applies a completed request to the record announced
+func applyResponse(record *models.InferenceRequest, response *inference.CompletionResponse) {
+	record.Provider = response.Provider
+	record.Model = response.Model
+
+	var content, finishReason string
+	if len(response.Choices) > 0 {
+		content = response.Choices[0].Message.Content
+		finishReason = response.Choices[0].FinishReason
+	}
+	record.MarkAsCompleted(content, finishReason,
+		response.Usage.PromptTokens, response.Usage.CompletionTokens,
+		response.LatencyMs, response.Cost)
+}
+
+// applyError applies a failed request to the record announced
+func applyError(record *models.InferenceRequest, err error) {
+	var inferenceErr *inference.InferenceError
+	if errors.As(err, &inferenceErr) {
+		record.MarkAsFailed(inferenceErr.Code, inferenceErr.Message)
+		return
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		record.MarkAsFailed(inference.ErrCodeTimeout, "inference request timed out")
+		return
+	}
+	record.MarkAsFailed(inference.ErrCodeInternal, err.Error())
+}
+
+// queueFullError is returned when a request cannot be accepted; clients may retry
+func queueFullError(message string) *inference.InferenceError {
+	return &inference.InferenceError{
+		Code:       ErrCodeQueueFull,
+		Message:    message,
+		StatusCode: http.StatusServiceUnavailable,
+		Retryable:  true,
+	}
+}
@@ -0,0 +1,265 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"github.com/upb/llm-control-plane/backend/services/webhook"
+	"go.uber.org/zap"
+)
+
+// fakeInferenceRepository stores inference requests in a map; other methods are unused
+type fakeInferenceRepository struct {
+	repositories.InferenceRequestRepository
+	mu      sync.Mutex
+	records map[uuid.UUID]models.InferenceRequest
+}
+
+func newFakeInferenceRepository() *fakeInferenceRepository {
+	return &fakeInferenceRepository{records: map[uuid.UUID]models.InferenceRequest{}}
+}
+
+func (r *fakeInferenceRepository) Create(ctx context.Context, req *models.InferenceRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records[req.ID] = *req
+	return nil
+}
+
+func (r *fakeInferenceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.InferenceRequest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if record, ok := r.records[id]; ok {
+		return &record, nil
+	}
+	return nil, fmt.Errorf("inference request not found: %s", id)
+}
+
+func (r *fakeInferenceRepository) Update(ctx context.Context, req *models.InferenceRequest) error {
+	return r.Create(ctx, req)
+}
+
+func (r *fakeInferenceRepository) FailUnfinished(ctx context.Context, before time.Time, errorCode, message string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for id, record := range r.records {
+		unfinished := record.Status == models.InferenceStatusPending || record.Status == models.InferenceStatusProcessing
+		if unfinished && record.CreatedAt.Before(before) {
+			record.MarkAsFailed(errorCode, message)
+			r.records[id] = record
+			count++
+		}
+	}
+	return count, nil
+}
+
+// fakeCompleter answers with the last message, or fails with err, and records
+// the outcome on the reserved record like the pipeline
+type fakeCompleter struct {
+	repo     *fakeInferenceRepository
+	mu       sync.Mutex
+	requests []*inference.CompletionRequest
+	err      error
+	release  chan struct{} // When set, completions wait for it
+}
+
+func (c *fakeCompleter) ProcessChatCompletion(ctx context.Context, req *inference.CompletionRequest) (*inference.CompletionResponse, error) {
+	if c.release != nil {
+		<-c.release
+	}
+	c.mu.Lock()
+	c.requests = append(c.requests, req)
+	c.mu.Unlock()
+
+	record, err := c.repo.GetByID(ctx, req.InferenceID)
+	if err != nil {
+		return nil, err
+	}
+	arm := "control" // Only the pipeline knows the experiment arm
+	record.ExperimentArm = &arm
+	if c.err != nil {
+		applyError(record, c.err)
+		return nil, errors.Join(c.err, c.repo.Update(ctx, record))
+	}
+	response := &inference.CompletionResponse{
+		ID:       req.InferenceID,
+		Provider: "openai",
+		Model:    req.Model,
+		Choices: []inference.Choice{{
+			Message:      providers.Message{Role: "assistant", Content: "re: " + req.Messages[len(req.Messages)-1].Content},
+			FinishReason: "stop",
+		}},
+		Usage: inference.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5},
+		Cost:  0.01,
+	}
+	applyResponse(record, response)
+	return response, c.repo.Update(ctx, record)
+}
+
+// fakeNotifier records delivered events
+type fakeNotifier struct {
+	mu     sync.Mutex
+	events []*webhook.Event
+	appIDs []uuid.UUID
+}
+
+func (n *fakeNotifier) Deliver(ctx context.Context, appID uuid.UUID, event *webhook.Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.appIDs = append(n.appIDs, appID)
+	n.events = append(n.events, event)
+	return nil
+}
+
+type asyncFixture struct {
+	service   *AsyncService
+	repo      *fakeInferenceRepository
+	completer *fakeCompleter
+	notifier  *fakeNotifier
+	orgID     uuid.UUID
+	appID     uuid.UUID
+}
+
+func newAsyncFixture(config Config) *asyncFixture {
+	repo := newFakeInferenceRepository()
+	completer := &fakeCompleter{repo: repo}
+	notifier := &fakeNotifier{}
+	return &asyncFixture{
+		service:   NewAsyncService(repo, completer, notifier, zap.NewNop(), config),
+		repo:      repo,
+		completer: completer,
+		notifier:  notifier,
+		orgID:     uuid.New(),
+		appID:     uuid.New(),
+	}
+}
+
+func (f *asyncFixture) request(content string) *inference.CompletionRequest {
+	return &inference.CompletionRequest{
+		OrgID:    f.orgID,
+		AppID:    f.appID,
+		Model:    "gpt-4o",
+		Messages: []providers.Message{{Role: "user", Content: content}},
+	}
+}
+
+func TestAsyncService_Submit(t *testing.T) {
+	f := newAsyncFixture(DefaultConfig())
+	require.NoError(t, f.service.Start())
+
+	record, err := f.service.Submit(context.Background(), f.request("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, models.InferenceStatusPending, record.Status)
+	assert.Equal(t, record.ID.String(), record.RequestID, "request ID defaults to the inference ID")
+
+	require.NoError(t, f.service.Stop(time.Second))
+
+	stored, err := f.service.GetRequest(context.Background(), record.ID, f.orgID)
+	require.NoError(t, err)
+	assert.Equal(t, models.InferenceStatusCompleted, stored.Status)
+	assert.Equal(t, "re: hello", *stored.Response)
+	assert.Equal(t, "stop", *stored.FinishReason)
+	assert.Equal(t, 5, stored.TotalTokens)
+	assert.Equal(t, "openai", stored.Provider)
+	require.NotNil(t, stored.ExperimentArm, "the pipeline's record is not overwritten")
+
+	require.Len(t, f.completer.requests, 1)
+	assert.Equal(t, record.ID, f.completer.requests[0].InferenceID, "the pipeline uses the reserved inference ID")
+
+	require.Len(t, f.notifier.events, 1)
+	assert.Equal(t, webhook.EventInferenceCompleted, f.notifier.events[0].Type)
+	assert.Equal(t, f.appID, f.notifier.appIDs[0])
+}
+
+func TestAsyncService_Failure(t *testing.T) {
+	f := newAsyncFixture(DefaultConfig())
+	f.completer.err = inference.NewBudgetError("budget exceeded", nil)
+	require.NoError(t, f.service.Start())
+
+	record, err := f.service.Submit(context.Background(), f.request("hello"))
+	require.NoError(t, err)
+	require.NoError(t, f.service.Stop(time.Second))
+
+	stored, err := f.service.GetRequest(context.Background(), record.ID, f.orgID)
+	require.NoError(t, err)
+	assert.Equal(t, models.InferenceStatusFailed, stored.Status)
+	assert.Equal(t, inference.ErrCodeBudgetExceeded, *stored.ErrorCode)
+
+	require.Len(t, f.notifier.events, 1)
+	assert.Equal(t, webhook.EventInferenceFailed, f.notifier.events[0].Type)
+}
+
+func TestAsyncService_QueueFull(t *testing.T) {
+	f := newAsyncFixture(Config{WorkerCount: 1, QueueSize: 1, RequestTimeout: time.Second})
+	f.completer.release = make(chan struct{})
+	require.NoError(t, f.service.Start())
+	ctx := context.Background()
+
+	// The first request occupies the worker, the second fills the queue
+	_, err := f.service.Submit(ctx, f.request("1"))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(f.service.queue) == 0 }, time.Second, time.Millisecond)
+	_, err = f.service.Submit(ctx, f.request("2"))
+	require.NoError(t, err)
+
+	_, err = f.service.Submit(ctx, f.request("3"))
+	var inferenceErr *inference.InferenceError
+	require.ErrorAs(t, err, &inferenceErr)
+	assert.Equal(t, ErrCodeQueueFull, inferenceErr.Code)
+	assert.True(t, inferenceErr.Retryable)
+
+	close(f.completer.release)
+	require.NoError(t, f.service.Stop(time.Second))
+
+	_, err = f.service.Submit(ctx, f.request("4"))
+	assert.Error(t, err, "stopped service accepts no requests")
+}
+
+func TestAsyncService_GetRequest_TenantIsolation(t *testing.T) {
+	f := newAsyncFixture(DefaultConfig())
+	require.NoError(t, f.service.Start())
+
+	record, err := f.service.Submit(context.Background(), f.request("hello"))
+	require.NoError(t, err)
+	require.NoError(t, f.service.Stop(time.Second))
+
+	_, err = f.service.GetRequest(context.Background(), record.ID, uuid.New())
+	assert.True(t, services.IsNotFoundError(err))
+}
+
+func TestAsyncService_Start_FailsAbandonedRequests(t *testing.T) {
+	f := newAsyncFixture(DefaultConfig())
+	ctx := context.Background()
+	pending := models.NewInferenceRequest(f.orgID, f.appID, "openai", "gpt-4o", "hello")
+	pending.CreatedAt = time.Now().Add(-time.Minute)
+	require.NoError(t, f.repo.Create(ctx, pending))
+	completed := models.NewInferenceRequest(f.orgID, f.appID, "openai", "gpt-4o", "hello")
+	completed.CreatedAt = time.Now().Add(-time.Minute)
+	completed.MarkAsCompleted("hi", "stop", 1, 1, 10, 0.01)
+	require.NoError(t, f.repo.Create(ctx, completed))
+
+	require.NoError(t, f.service.Start())
+	require.NoError(t, f.service.Stop(time.Second))
+
+	stored, err := f.service.GetRequest(ctx, pending.ID, f.orgID)
+	require.NoError(t, err)
+	assert.Equal(t, models.InferenceStatusFailed, stored.Status)
+	assert.Equal(t, ErrCodeAbandoned, *stored.ErrorCode)
+
+	stored, err = f.service.GetRequest(ctx, completed.ID, f.orgID)
+	require.NoError(t, err)
+	assert.Equal(t, models.InferenceStatusCompleted, stored.Status)
+}
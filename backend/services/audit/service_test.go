@@ -2,6 +2,7 @@ package audit
 
 import (
 	"context"
+	"encoding/json"
 	"sync"
 	"testing"
 	"time"
@@ -311,6 +312,84 @@ func TestAuditService_LogInferenceRequest(t *testing.T) {
 	assert.Equal(t, "gpt-4", *insertedLogs[0].Model)
 }
 
+func TestAuditService_LogInferenceRequest_RetrievedDocuments(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	mockRepo := new(MockAuditRepository)
+	config := DefaultConfig()
+
+	service := NewAuditService(mockRepo, logger, config)
+	err := service.Start()
+	require.NoError(t, err)
+	defer service.Stop(5 * time.Second)
+
+	mockRepo.On("Insert", mock.Anything, mock.Anything).Return(nil)
+
+	inferenceReq := models.NewInferenceRequest(uuid.New(), uuid.New(), "openai", "gpt-4", "test prompt")
+	inferenceReq.RetrievedDocuments = []string{"handbook", "faq"}
+
+	err = service.LogInferenceRequest(inferenceReq)
+	require.NoError(t, err)
+
+	// Wait for processing
+	time.Sleep(100 * time.Millisecond)
+
+	insertedLogs := mockRepo.GetInsertedLogs()
+	require.Equal(t, 1, len(insertedLogs))
+
+	var details map[string]interface{}
+	require.NoError(t, json.Unmarshal(insertedLogs[0].Details, &details))
+	assert.Equal(t, []interface{}{"handbook", "faq"}, details["retrieved_documents"])
+}
+
+func TestAuditService_LogInferenceRequest_APIKey(t *testing.T) {
+	mockRepo := new(MockAuditRepository)
+	service := NewAuditService(mockRepo, zap.NewNop(), DefaultConfig())
+	require.NoError(t, service.Start())
+	defer service.Stop(5 * time.Second)
+
+	mockRepo.On("Insert", mock.Anything, mock.Anything).Return(nil)
+
+	keyID := uuid.New()
+	inferenceReq := models.NewInferenceRequest(uuid.New(), uuid.New(), "openai", "gpt-4", "test prompt")
+	inferenceReq.APIKeyID = &keyID
+
+	require.NoError(t, service.LogInferenceRequest(inferenceReq))
+
+	// Wait for processing
+	time.Sleep(100 * time.Millisecond)
+
+	insertedLogs := mockRepo.GetInsertedLogs()
+	require.Equal(t, 1, len(insertedLogs))
+
+	var details map[string]interface{}
+	require.NoError(t, json.Unmarshal(insertedLogs[0].Details, &details))
+	assert.Equal(t, keyID.String(), details["api_key_id"])
+}
+
+func TestAuditService_LogAPIKeyRevoked(t *testing.T) {
+	mockRepo := new(MockAuditRepository)
+	service := NewAuditService(mockRepo, zap.NewNop(), DefaultConfig())
+	require.NoError(t, service.Start())
+	defer service.Stop(5 * time.Second)
+
+	mockRepo.On("Insert", mock.Anything, mock.Anything).Return(nil)
+
+	key := models.NewAPIKey(uuid.New(), uuid.New(), "ci", "lcp_0123abcd", "hash", nil)
+	userID := uuid.New()
+
+	require.NoError(t, service.LogAPIKeyRevoked(key, &userID))
+
+	// Wait for processing
+	time.Sleep(100 * time.Millisecond)
+
+	insertedLogs := mockRepo.GetInsertedLogs()
+	require.Equal(t, 1, len(insertedLogs))
+	assert.Equal(t, models.AuditActionAPIKeyRevoked, insertedLogs[0].Action)
+	assert.Equal(t, key.ID, *insertedLogs[0].ResourceID)
+	assert.Equal(t, key.AppID, *insertedLogs[0].AppID)
+	assert.Equal(t, userID, *insertedLogs[0].UserID)
+}
+
 func TestAuditService_LogPolicyViolation(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	mockRepo := new(MockAuditRepository)
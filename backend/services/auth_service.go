@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,9 @@ import (
 	"github.com/upb/llm-control-plane/backend/config"
 )
 
+// ErrInvalidGrant is returned when Cognito rejects an authorization code or refresh token
+var ErrInvalidGrant = errors.New("invalid grant")
+
 // TokenResponse represents the OAuth2 token endpoint response from Cognito
 type TokenResponse struct {
 	IDToken      string `json:"id_token"`
@@ -37,53 +41,71 @@ func NewCognitoTokenExchanger(cfg config.CognitoConfig) *CognitoTokenExchanger {
 	}
 }
 
-// ExchangeCode exchanges an authorization code for ID and access tokens
-func (e *CognitoTokenExchanger) ExchangeCode(ctx context.Context, code, redirectURI, state string) (idToken string, err error) {
-	if e.cfg.Domain == "" || e.cfg.ClientID == "" {
-		return "", fmt.Errorf("cognito not configured")
-	}
-
-	tokenURL := strings.TrimSuffix(e.cfg.Domain, "/") + "/oauth2/token"
-	data := url.Values{
+// ExchangeCode exchanges an authorization code for ID, access and refresh tokens
+func (e *CognitoTokenExchanger) ExchangeCode(ctx context.Context, code, redirectURI, state string) (*TokenResponse, error) {
+	return e.requestTokens(ctx, url.Values{
 		"grant_type":   {"authorization_code"},
-		"client_id":    {e.cfg.ClientID},
 		"code":         {code},
 		"redirect_uri": {redirectURI},
+	})
+}
+
+// RefreshTokens exchanges a refresh token for new ID and access tokens.
+// Cognito only returns a new refresh token when refresh token rotation is
+// enabled for the app client.
+func (e *CognitoTokenExchanger) RefreshTokens(ctx context.Context, refreshToken string) (*TokenResponse, error) {
+	return e.requestTokens(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+// requestTokens posts a grant to the Cognito token endpoint
+func (e *CognitoTokenExchanger) requestTokens(ctx context.Context, data url.Values) (*TokenResponse, error) {
+	if e.cfg.Domain == "" || e.cfg.ClientID == "" {
+		return nil, fmt.Errorf("cognito not configured")
 	}
 
+	tokenURL := strings.TrimSuffix(e.cfg.Domain, "/") + "/oauth2/token"
+	data.Set("client_id", e.cfg.ClientID)
+
 	if e.cfg.ClientSecret != "" {
 		data.Set("client_secret", e.cfg.ClientSecret)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
-		return "", fmt.Errorf("create token request: %w", err)
+		return nil, fmt.Errorf("create token request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	resp, err := e.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("token request failed: %w", err)
+		return nil, fmt.Errorf("token request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("read token response: %w", err)
+		return nil, fmt.Errorf("read token response: %w", err)
 	}
 
+	// Cognito answers invalid or revoked codes and refresh tokens with 400 invalid_grant
+	if resp.StatusCode == http.StatusBadRequest {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidGrant, string(body))
+	}
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("token exchange failed: status %d, body: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("token exchange failed: status %d, body: %s", resp.StatusCode, string(body))
 	}
 
 	var tokenResp TokenResponse
 	if err := json.Unmarshal(body, &tokenResp); err != nil {
-		return "", fmt.Errorf("parse token response: %w", err)
+		return nil, fmt.Errorf("parse token response: %w", err)
 	}
 
 	if tokenResp.IDToken == "" {
-		return "", fmt.Errorf("no id_token in response")
+		return nil, fmt.Errorf("no id_token in response")
 	}
 
-	return tokenResp.IDToken, nil
+	return &tokenResp, nil
 }
@@ -0,0 +1,307 @@
+package batch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaxItems is the largest number of requests accepted in one job
+	MaxItems = 50000
+
+	// MaxLineBytes is the largest accepted input line
+	MaxLineBytes = 1 << 20
+
+	// DefaultListLimit and MaxListLimit bound job listing
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+
+	// resultPageSize is the number of items read per query when writing results
+	resultPageSize = 500
+)
+
+// Completer runs a chat completion through the inference pipeline
+type Completer interface {
+	ProcessChatCompletion(ctx context.Context, req *inference.CompletionRequest) (*inference.CompletionResponse, error)
+}
+
+// RequestLine is one line of a batch input file. Method and URL are accepted
+// for compatibility with OpenAI batch files; every line is a chat completion.
+type RequestLine struct {
+	CustomID string      `json:"custom_id"`
+	Method   string      `json:"method,omitempty"`
+	URL      string      `json:"url,omitempty"`
+	Body     RequestBody `json:"body"`
+}
+
+// RequestBody is the chat completion request of a batch line
+type RequestBody struct {
+	Model       string              `json:"model"`
+	Messages    []providers.Message `json:"messages"`
+	MaxTokens   int                 `json:"max_tokens,omitempty"`
+	Temperature float64             `json:"temperature,omitempty"`
+	TopP        float64             `json:"top_p,omitempty"`
+	Stop        []string            `json:"stop,omitempty"`
+}
+
+// ResultLine is one line of a batch result file
+type ResultLine struct {
+	CustomID string                        `json:"custom_id"`
+	Line     int                           `json:"line"`
+	Status   models.BatchItemStatus        `json:"status"`
+	Attempts int                           `json:"attempts"`
+	Response *inference.CompletionResponse `json:"response,omitempty"`
+	Error    *ResultError                  `json:"error,omitempty"`
+}
+
+// ResultError describes why an item failed, or why its last attempt did
+type ResultError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// CreateJobRequest represents a batch upload
+type CreateJobRequest struct {
+	OrgID  uuid.UUID
+	AppID  uuid.UUID
+	UserID *uuid.UUID
+	Input  io.Reader // JSONL, one RequestLine per line
+}
+
+// BatchService manages batch inference jobs and runs their items through the
+// inference pipeline with a pool of workers. Job state lives in Postgres, so
+// pending work survives restarts.
+type BatchService struct {
+	batchRepo repositories.BatchRepository
+	completer Completer
+	txManager repositories.TransactionManager
+	logger    *zap.Logger
+	config    Config
+
+	mu      sync.Mutex
+	started bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewBatchService creates a new BatchService instance
+func NewBatchService(batchRepo repositories.BatchRepository, completer Completer, txManager repositories.TransactionManager, logger *zap.Logger, config Config) *BatchService {
+	return &BatchService{
+		batchRepo: batchRepo,
+		completer: completer,
+		txManager: txManager,
+		logger:    logger,
+		config:    config,
+	}
+}
+
+// CreateJob validates a JSONL upload and stores it as a pending job
+func (s *BatchService) CreateJob(ctx context.Context, req CreateJobRequest) (*models.BatchJob, error) {
+	if req.AppID == uuid.Nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "batch jobs require an application", nil)
+	}
+
+	job := models.NewBatchJob(req.OrgID, req.AppID, req.UserID, 0)
+	items, err := ParseInput(job.ID, req.Input)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), errors.Unwrap(err))
+	}
+	job.TotalItems = len(items)
+
+	err = s.txManager.InTransaction(ctx, func(ctx context.Context, tx repositories.Transaction) error {
+		return s.batchRepo.CreateJob(ctx, job, items)
+	})
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create batch job", err)
+	}
+
+	s.logger.Info("batch job created",
+		zap.String("job_id", job.ID.String()),
+		zap.String("org_id", req.OrgID.String()),
+		zap.Int("items", job.TotalItems))
+
+	return job, nil
+}
+
+// GetJob retrieves a job of the organization
+func (s *BatchService) GetJob(ctx context.Context, id, orgID uuid.UUID) (*models.BatchJob, error) {
+	job, err := s.batchRepo.GetJob(ctx, id)
+	if err != nil || job.OrgID != orgID {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "batch job not found", err)
+	}
+	return job, nil
+}
+
+// ListJobs lists the organization's jobs, newest first
+func (s *BatchService) ListJobs(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.BatchJob, error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	limit = min(limit, MaxListLimit)
+	offset = max(offset, 0)
+
+	jobs, err := s.batchRepo.ListJobs(ctx, orgID, limit, offset)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list batch jobs", err)
+	}
+	return jobs, nil
+}
+
+// CancelJob stops a job; items already in flight finish, the rest are not run
+func (s *BatchService) CancelJob(ctx context.Context, id, orgID uuid.UUID) (*models.BatchJob, error) {
+	if _, err := s.GetJob(ctx, id, orgID); err != nil {
+		return nil, err
+	}
+
+	cancelled, err := s.batchRepo.UpdateJobStatus(ctx, id, models.BatchJobStatusCancelled, models.BatchJobStatusPending, models.BatchJobStatusRunning)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to cancel batch job", err)
+	}
+	if !cancelled {
+		return nil, services.NewDomainError(services.ErrorTypeConflict, "batch job has already finished", nil)
+	}
+
+	s.logger.Info("batch job cancelled", zap.String("job_id", id.String()))
+	return s.GetJob(ctx, id, orgID)
+}
+
+// WriteResults writes the job's result file as JSONL, one ResultLine per input
+// line in input order. Results can be read while the job runs; unfinished
+// items are reported with their current status.
+func (s *BatchService) WriteResults(ctx context.Context, id, orgID uuid.UUID, w io.Writer) error {
+	if _, err := s.GetJob(ctx, id, orgID); err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	for afterLine := 0; ; {
+		items, err := s.batchRepo.ListItems(ctx, id, afterLine, resultPageSize)
+		if err != nil {
+			return services.NewDomainError(services.ErrorTypeInternal, "failed to read batch results", err)
+		}
+
+		for _, item := range items {
+			if err := encoder.Encode(resultLine(item)); err != nil {
+				return fmt.Errorf("failed to write batch result: %w", err)
+			}
+			afterLine = item.LineNumber
+		}
+
+		if len(items) < resultPageSize {
+			return nil
+		}
+	}
+}
+
+// resultLine converts an item to its result file representation
+func resultLine(item *models.BatchItem) ResultLine {
+	line := ResultLine{
+		CustomID: item.CustomID,
+		Line:     item.LineNumber,
+		Status:   item.Status,
+		Attempts: item.Attempts,
+	}
+	if len(item.Response) > 0 {
+		var response inference.CompletionResponse
+		if err := json.Unmarshal(item.Response, &response); err == nil {
+			line.Response = &response
+		}
+	}
+	if item.ErrorCode != nil && item.Status != models.BatchItemStatusCompleted {
+		line.Error = &ResultError{Code: *item.ErrorCode}
+		if item.ErrorMessage != nil {
+			line.Error.Message = *item.ErrorMessage
+		}
+	}
+	return line
+}
+
+// ParseInput reads a JSONL batch file into items of the job. Blank lines are
+// skipped; every other line must be a valid chat request. Lines without a
+// custom_id are identified by their line number.
+func ParseInput(jobID uuid.UUID, input io.Reader) ([]*models.BatchItem, error) {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 64*1024), MaxLineBytes)
+
+	var items []*models.BatchItem
+	seen := make(map[string]int)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+		if len(items) == MaxItems {
+			return nil, fmt.Errorf("batch exceeds the maximum of %d requests", MaxItems)
+		}
+
+		var line RequestLine
+		if err := json.Unmarshal([]byte(raw), &line); err != nil {
+			return nil, fmt.Errorf("line %d: invalid JSON: %v", lineNumber, err)
+		}
+		if err := line.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+
+		if line.CustomID == "" {
+			line.CustomID = strconv.Itoa(lineNumber)
+		}
+		if previous, ok := seen[line.CustomID]; ok {
+			return nil, fmt.Errorf("line %d: custom_id %q already used on line %d", lineNumber, line.CustomID, previous)
+		}
+		seen[line.CustomID] = lineNumber
+
+		body, err := json.Marshal(line.Body)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", lineNumber, err)
+		}
+		items = append(items, models.NewBatchItem(jobID, lineNumber, line.CustomID, body))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch input: %w", err)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("batch input contains no requests")
+	}
+
+	return items, nil
+}
+
+// validate checks a request line before it is accepted into a job
+func (l *RequestLine) validate() error {
+	if l.URL != "" && l.URL != "/v1/chat/completions" {
+		return fmt.Errorf("unsupported url %q", l.URL)
+	}
+	if len(l.CustomID) > 255 {
+		return fmt.Errorf("custom_id is longer than 255 characters")
+	}
+	if l.Body.Model == "" {
+		return fmt.Errorf("body.model is required")
+	}
+	if len(l.Body.Messages) == 0 {
+		return fmt.Errorf("body.messages is required")
+	}
+	for _, msg := range l.Body.Messages {
+		switch msg.Role {
+		case "system", "user", "assistant":
+		default:
+			return fmt.Errorf("unsupported message role %q", msg.Role)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,391 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+// fakeBatchRepository is an in-memory BatchRepository
+type fakeBatchRepository struct {
+	jobs  map[uuid.UUID]*models.BatchJob
+	items []*models.BatchItem
+}
+
+func newFakeBatchRepository() *fakeBatchRepository {
+	return &fakeBatchRepository{jobs: map[uuid.UUID]*models.BatchJob{}}
+}
+
+func (r *fakeBatchRepository) CreateJob(ctx context.Context, job *models.BatchJob, items []*models.BatchItem) error {
+	r.jobs[job.ID] = job
+	r.items = append(r.items, items...)
+	return nil
+}
+
+func (r *fakeBatchRepository) GetJob(ctx context.Context, id uuid.UUID) (*models.BatchJob, error) {
+	if job, ok := r.jobs[id]; ok {
+		copied := *job
+		return &copied, nil
+	}
+	return nil, fmt.Errorf("batch job not found: %s", id)
+}
+
+func (r *fakeBatchRepository) ListJobs(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.BatchJob, error) {
+	var jobs []*models.BatchJob
+	for _, job := range r.jobs {
+		if job.OrgID == orgID {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (r *fakeBatchRepository) UpdateJobStatus(ctx context.Context, id uuid.UUID, status models.BatchJobStatus, from ...models.BatchJobStatus) (bool, error) {
+	job := r.jobs[id]
+	for _, s := range from {
+		if job.Status == s {
+			job.Status = status
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeBatchRepository) RefreshJobProgress(ctx context.Context, id uuid.UUID) error {
+	job := r.jobs[id]
+	job.CompletedItems, job.FailedItems = 0, 0
+	for _, item := range r.items {
+		if item.JobID != id {
+			continue
+		}
+		switch item.Status {
+		case models.BatchItemStatusCompleted:
+			job.CompletedItems++
+		case models.BatchItemStatusFailed:
+			job.FailedItems++
+		}
+	}
+	if job.Status == models.BatchJobStatusRunning && job.CompletedItems+job.FailedItems == job.TotalItems {
+		job.Status = models.BatchJobStatusCompleted
+	}
+	return nil
+}
+
+func (r *fakeBatchRepository) ClaimItem(ctx context.Context, lease time.Duration) (*models.BatchItem, error) {
+	for _, item := range r.items {
+		job := r.jobs[item.JobID]
+		if job.IsFinished() || item.Status != models.BatchItemStatusPending || item.AvailableAt.After(time.Now()) {
+			continue
+		}
+		item.Status = models.BatchItemStatusRunning
+		item.Attempts++
+		copied := *item
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (r *fakeBatchRepository) UpdateItem(ctx context.Context, item *models.BatchItem) error {
+	for i, stored := range r.items {
+		if stored.ID == item.ID {
+			if stored.Attempts != item.Attempts {
+				return fmt.Errorf("batch item lease lost")
+			}
+			r.items[i] = item
+			return nil
+		}
+	}
+	return fmt.Errorf("batch item not found: %s", item.ID)
+}
+
+func (r *fakeBatchRepository) ListItems(ctx context.Context, jobID uuid.UUID, afterLine, limit int) ([]*models.BatchItem, error) {
+	var items []*models.BatchItem
+	for _, item := range r.items {
+		if item.JobID == jobID && item.LineNumber > afterLine {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].LineNumber < items[j].LineNumber })
+	return items[:min(limit, len(items))], nil
+}
+
+func (r *fakeBatchRepository) WithTx(tx repositories.Transaction) repositories.BatchRepository {
+	return r
+}
+
+// fakeCompleter answers requests, failing those whose last message names an error
+type fakeCompleter struct {
+	requests []*inference.CompletionRequest
+	errors   map[string]error
+}
+
+func (c *fakeCompleter) ProcessChatCompletion(ctx context.Context, req *inference.CompletionRequest) (*inference.CompletionResponse, error) {
+	c.requests = append(c.requests, req)
+	last := req.Messages[len(req.Messages)-1].Content
+	if err, ok := c.errors[last]; ok {
+		return nil, err
+	}
+	return &inference.CompletionResponse{
+		ID:    uuid.New(),
+		Model: req.Model,
+		Choices: []inference.Choice{{
+			Message:      providers.Message{Role: "assistant", Content: "re: " + last},
+			FinishReason: "stop",
+		}},
+	}, nil
+}
+
+// fakeTxManager runs transactional functions directly
+type fakeTxManager struct{}
+
+func (fakeTxManager) Begin(ctx context.Context) (repositories.Transaction, error) {
+	return nil, nil
+}
+
+func (fakeTxManager) InTransaction(ctx context.Context, fn func(ctx context.Context, tx repositories.Transaction) error) error {
+	return fn(ctx, nil)
+}
+
+type batchFixture struct {
+	service   *BatchService
+	repo      *fakeBatchRepository
+	completer *fakeCompleter
+	orgID     uuid.UUID
+	appID     uuid.UUID
+}
+
+func newBatchFixture() *batchFixture {
+	repo := newFakeBatchRepository()
+	completer := &fakeCompleter{errors: map[string]error{}}
+	config := DefaultConfig()
+	config.MaxAttempts = 2
+	config.RetryDelay = 0
+	return &batchFixture{
+		service:   NewBatchService(repo, completer, fakeTxManager{}, zap.NewNop(), config),
+		repo:      repo,
+		completer: completer,
+		orgID:     uuid.New(),
+		appID:     uuid.New(),
+	}
+}
+
+func (f *batchFixture) create(t *testing.T, lines ...string) *models.BatchJob {
+	t.Helper()
+	job, err := f.service.CreateJob(context.Background(), CreateJobRequest{
+		OrgID: f.orgID,
+		AppID: f.appID,
+		Input: strings.NewReader(strings.Join(lines, "\n")),
+	})
+	require.NoError(t, err)
+	return job
+}
+
+// drain processes items until none is runnable
+func (f *batchFixture) drain(t *testing.T) {
+	t.Helper()
+	for {
+		processed, err := f.service.processNext(context.Background())
+		require.NoError(t, err)
+		if !processed {
+			return
+		}
+	}
+}
+
+func requestLine(customID, content string) string {
+	return fmt.Sprintf(`{"custom_id":%q,"method":"POST","url":"/v1/chat/completions","body":{"model":"gpt-4o","messages":[{"role":"user","content":%q}]}}`, customID, content)
+}
+
+func readResults(t *testing.T, f *batchFixture, jobID uuid.UUID) []ResultLine {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, f.service.WriteResults(context.Background(), jobID, f.orgID, &buf))
+
+	var results []ResultLine
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var line ResultLine
+		require.NoError(t, decoder.Decode(&line))
+		results = append(results, line)
+	}
+	return results
+}
+
+func TestParseInput(t *testing.T) {
+	jobID := uuid.New()
+
+	t.Run("valid input", func(t *testing.T) {
+		input := requestLine("a", "hi") + "\n\n" + `{"body":{"model":"gpt-4o","messages":[{"role":"user","content":"x"}]}}` + "\n"
+		items, err := ParseInput(jobID, strings.NewReader(input))
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Equal(t, "a", items[0].CustomID)
+		assert.Equal(t, 3, items[1].LineNumber, "blank lines keep their line numbers")
+		assert.Equal(t, "3", items[1].CustomID, "custom_id defaults to the line number")
+		assert.Equal(t, jobID, items[1].JobID)
+	})
+
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"empty", "\n\n", "no requests"},
+		{"invalid JSON", requestLine("a", "hi") + "\n{", "line 2: invalid JSON"},
+		{"missing model", `{"body":{"messages":[{"role":"user","content":"x"}]}}`, "line 1: body.model is required"},
+		{"missing messages", `{"body":{"model":"gpt-4o"}}`, "body.messages is required"},
+		{"unsupported url", `{"url":"/v1/embeddings","body":{"model":"m","messages":[{"role":"user","content":"x"}]}}`, "unsupported url"},
+		{"unsupported role", `{"body":{"model":"m","messages":[{"role":"tool","content":"x"}]}}`, "unsupported message role"},
+		{"duplicate custom_id", requestLine("a", "1") + "\n" + requestLine("a", "2"), `custom_id "a" already used on line 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseInput(jobID, strings.NewReader(tt.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestBatchService_CreateJob(t *testing.T) {
+	f := newBatchFixture()
+
+	job := f.create(t, requestLine("a", "one"), requestLine("b", "two"))
+	assert.Equal(t, models.BatchJobStatusPending, job.Status)
+	assert.Equal(t, 2, job.TotalItems)
+	assert.Len(t, f.repo.items, 2)
+
+	_, err := f.service.CreateJob(context.Background(), CreateJobRequest{OrgID: f.orgID, AppID: f.appID, Input: strings.NewReader("{")})
+	assert.True(t, services.IsValidationError(err))
+
+	_, err = f.service.CreateJob(context.Background(), CreateJobRequest{OrgID: f.orgID, Input: strings.NewReader(requestLine("a", "one"))})
+	assert.True(t, services.IsValidationError(err), "jobs need an application to charge")
+}
+
+func TestBatchService_ProcessItems(t *testing.T) {
+	f := newBatchFixture()
+	f.completer.errors["blocked"] = inference.NewPolicyViolationError("blocked by policy", nil)
+	f.completer.errors["throttled"] = inference.NewRateLimitError("rate limit exceeded", nil)
+
+	job := f.create(t, requestLine("ok", "hello"), requestLine("bad", "blocked"), requestLine("slow", "throttled"))
+	f.drain(t)
+
+	stored, err := f.service.GetJob(context.Background(), job.ID, f.orgID)
+	require.NoError(t, err)
+	assert.Equal(t, models.BatchJobStatusCompleted, stored.Status)
+	assert.Equal(t, 1, stored.CompletedItems)
+	assert.Equal(t, 2, stored.FailedItems)
+	assert.Equal(t, 1.0, stored.Progress())
+
+	// The rate-limited item was retried once before failing
+	require.Len(t, f.completer.requests, 4)
+	req := f.completer.requests[0]
+	assert.Equal(t, f.orgID, req.OrgID)
+	assert.Equal(t, f.appID, req.AppID, "items run under the job's application")
+	assert.Equal(t, job.ID.String(), req.Metadata["batch_job_id"])
+	assert.Equal(t, "ok", req.Metadata["custom_id"])
+	assert.NotEqual(t, f.completer.requests[2].RequestID, f.completer.requests[3].RequestID, "each attempt has its own request ID")
+
+	results := readResults(t, f, job.ID)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "ok", results[0].CustomID)
+	assert.Equal(t, models.BatchItemStatusCompleted, results[0].Status)
+	require.NotNil(t, results[0].Response)
+	assert.Equal(t, "re: hello", results[0].Response.Choices[0].Message.Content)
+	assert.Nil(t, results[0].Error)
+
+	assert.Equal(t, models.BatchItemStatusFailed, results[1].Status)
+	assert.Equal(t, inference.ErrCodePolicyViolation, results[1].Error.Code)
+	assert.Equal(t, 1, results[1].Attempts, "non-retryable errors fail immediately")
+
+	assert.Equal(t, models.BatchItemStatusFailed, results[2].Status)
+	assert.Equal(t, inference.ErrCodeRateLimitExceeded, results[2].Error.Code)
+	assert.Equal(t, 2, results[2].Attempts)
+}
+
+func TestBatchService_RetryThenSucceed(t *testing.T) {
+	f := newBatchFixture()
+	f.completer.errors["flaky"] = inference.NewProviderError("upstream unavailable", nil, true)
+
+	job := f.create(t, requestLine("a", "flaky"))
+
+	processed, err := f.service.processNext(context.Background())
+	require.NoError(t, err)
+	require.True(t, processed)
+
+	item := f.repo.items[0]
+	assert.Equal(t, models.BatchItemStatusPending, item.Status, "retryable errors requeue the item")
+	assert.Equal(t, inference.ErrCodeProviderError, *item.ErrorCode)
+
+	delete(f.completer.errors, "flaky")
+	f.drain(t)
+
+	results := readResults(t, f, job.ID)
+	require.Len(t, results, 1)
+	assert.Equal(t, models.BatchItemStatusCompleted, results[0].Status)
+	assert.Nil(t, results[0].Error, "errors of earlier attempts are cleared")
+	assert.Equal(t, 2, results[0].Attempts)
+}
+
+func TestBatchService_CancelJob(t *testing.T) {
+	f := newBatchFixture()
+	job := f.create(t, requestLine("a", "one"), requestLine("b", "two"))
+
+	cancelled, err := f.service.CancelJob(context.Background(), job.ID, f.orgID)
+	require.NoError(t, err)
+	assert.Equal(t, models.BatchJobStatusCancelled, cancelled.Status)
+
+	f.drain(t)
+	assert.Empty(t, f.completer.requests, "items of cancelled jobs are not run")
+
+	_, err = f.service.CancelJob(context.Background(), job.ID, f.orgID)
+	assert.True(t, services.IsConflictError(err))
+}
+
+func TestBatchService_TenantIsolation(t *testing.T) {
+	f := newBatchFixture()
+	job := f.create(t, requestLine("a", "one"))
+	ctx := context.Background()
+	otherOrg := uuid.New()
+
+	_, err := f.service.GetJob(ctx, job.ID, otherOrg)
+	assert.True(t, services.IsNotFoundError(err))
+
+	_, err = f.service.CancelJob(ctx, job.ID, otherOrg)
+	assert.True(t, services.IsNotFoundError(err))
+
+	assert.True(t, services.IsNotFoundError(f.service.WriteResults(ctx, job.ID, otherOrg, &bytes.Buffer{})))
+
+	jobs, err := f.service.ListJobs(ctx, otherOrg, 0, 0)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+	assert.Equal(t, models.BatchJobStatusPending, f.repo.jobs[job.ID].Status)
+}
+
+func TestBatchService_StartStop(t *testing.T) {
+	f := newBatchFixture()
+	f.service.config.WorkerCount = 2
+	f.service.config.PollInterval = 10 * time.Millisecond
+
+	require.NoError(t, f.service.Start())
+	assert.Error(t, f.service.Start())
+	require.NoError(t, f.service.Stop(time.Second))
+	assert.Error(t, f.service.Stop(time.Second))
+}
@@ -0,0 +1,228 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"go.uber.org/zap"
+)
+
+// Config holds configuration for the batch workers
+type Config struct {
+	WorkerCount   int           // Number of items processed concurrently
+	PollInterval  time.Duration // Wait between claims when no item is runnable
+	LeaseDuration time.Duration // How long a claimed item is reserved before another worker may take it
+	MaxAttempts   int           // Attempts per item before a retryable error becomes a failure
+	RetryDelay    time.Duration // Base delay before a retry, multiplied by the attempt number
+	ItemTimeout   time.Duration // Deadline for one completion
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() Config {
+	return Config{
+		WorkerCount:   4,
+		PollInterval:  2 * time.Second,
+		LeaseDuration: 5 * time.Minute,
+		MaxAttempts:   5,
+		RetryDelay:    30 * time.Second,
+		ItemTimeout:   2 * time.Minute,
+	}
+}
+
+// Start starts the background workers
+func (s *BatchService) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return fmt.Errorf("batch service already started")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for i := 0; i < s.config.WorkerCount; i++ {
+		s.wg.Add(1)
+		go s.worker(ctx, i)
+	}
+
+	s.started = true
+	s.logger.Info("started batch service", zap.Int("worker_count", s.config.WorkerCount))
+
+	return nil
+}
+
+// Stop stops the workers, waiting for in-flight items to finish. Items that
+// do not finish in time are picked up again once their lease expires.
+func (s *BatchService) Stop(timeout time.Duration) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return fmt.Errorf("batch service not started")
+	}
+	s.started = false
+	s.mu.Unlock()
+
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.logger.Info("batch service stopped gracefully")
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("batch service stop timeout after %v", timeout)
+	}
+}
+
+// worker claims and processes items until ctx is cancelled
+func (s *BatchService) worker(ctx context.Context, id int) {
+	defer s.wg.Done()
+
+	s.logger.Debug("batch worker started", zap.Int("worker_id", id))
+
+	for ctx.Err() == nil {
+		processed, err := s.processNext(ctx)
+		if err != nil {
+			s.logger.Error("failed to process batch item", zap.Int("worker_id", id), zap.Error(err))
+		}
+		if processed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(s.config.PollInterval):
+		}
+	}
+
+	s.logger.Debug("batch worker stopped", zap.Int("worker_id", id))
+}
+
+// processNext claims one item and runs it. It reports whether an item was claimed.
+func (s *BatchService) processNext(ctx context.Context) (bool, error) {
+	item, err := s.batchRepo.ClaimItem(ctx, s.config.LeaseDuration)
+	if err != nil {
+		return false, err
+	}
+	if item == nil {
+		return false, nil
+	}
+
+	return true, s.processItem(ctx, item)
+}
+
+// processItem runs a claimed item through the inference pipeline under its
+// job's application and records the outcome
+func (s *BatchService) processItem(ctx context.Context, item *models.BatchItem) error {
+	job, err := s.batchRepo.GetJob(ctx, item.JobID)
+	if err != nil {
+		return fmt.Errorf("failed to load batch job: %w", err)
+	}
+	if _, err := s.batchRepo.UpdateJobStatus(ctx, job.ID, models.BatchJobStatusRunning, models.BatchJobStatusPending); err != nil {
+		return fmt.Errorf("failed to start batch job: %w", err)
+	}
+
+	s.runItem(ctx, job, item)
+
+	// Record the outcome even if shutdown began while the completion ran
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	if err := s.batchRepo.UpdateItem(saveCtx, item); err != nil {
+		return err
+	}
+	if err := s.batchRepo.RefreshJobProgress(saveCtx, job.ID); err != nil {
+		return err
+	}
+
+	s.logger.Debug("batch item processed",
+		zap.String("job_id", job.ID.String()),
+		zap.Int("line", item.LineNumber),
+		zap.String("status", string(item.Status)),
+		zap.Int("attempts", item.Attempts))
+
+	return nil
+}
+
+// runItem executes the item's request and applies the result to the item
+func (s *BatchService) runItem(ctx context.Context, job *models.BatchJob, item *models.BatchItem) {
+	if item.Attempts > s.config.MaxAttempts {
+		// Claimed again after a lease expired, e.g. the process died mid-request
+		item.MarkAsFailed(inference.ErrCodeInternal, "batch item exceeded the maximum number of attempts")
+		return
+	}
+
+	var body RequestBody
+	if err := json.Unmarshal(item.Request, &body); err != nil {
+		item.MarkAsFailed(inference.ErrCodeValidation, "invalid batch request: "+err.Error())
+		return
+	}
+
+	itemCtx, cancel := context.WithTimeout(ctx, s.config.ItemTimeout)
+	defer cancel()
+
+	response, err := s.completer.ProcessChatCompletion(itemCtx, &inference.CompletionRequest{
+		OrgID:       job.OrgID,
+		AppID:       job.AppID,
+		UserID:      job.UserID,
+		Model:       body.Model,
+		Messages:    body.Messages,
+		MaxTokens:   body.MaxTokens,
+		Temperature: body.Temperature,
+		TopP:        body.TopP,
+		Stop:        body.Stop,
+		RequestID:   fmt.Sprintf("batch_%s_%d", item.ID, item.Attempts),
+		Metadata: map[string]string{
+			"batch_job_id": job.ID.String(),
+			"custom_id":    item.CustomID,
+		},
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			// Interrupted by shutdown; requeue for the next process to pick up
+			item.Retry(inference.ErrCodeTimeout, "interrupted by shutdown", time.Now())
+			return
+		}
+		s.applyError(item, err)
+		return
+	}
+
+	encoded, err := json.Marshal(response)
+	if err != nil {
+		item.MarkAsFailed(inference.ErrCodeInternal, "failed to encode completion response")
+		return
+	}
+	item.MarkAsCompleted(encoded)
+}
+
+// applyError retries retryable pipeline errors, such as rate limits and
+// provider outages, with a growing delay; other errors fail the item
+func (s *BatchService) applyError(item *models.BatchItem, err error) {
+	code, message, retryable := inference.ErrCodeInternal, err.Error(), false
+	if errors.Is(err, context.DeadlineExceeded) {
+		code, retryable = inference.ErrCodeTimeout, true
+	}
+
+	var inferenceErr *inference.InferenceError
+	if errors.As(err, &inferenceErr) {
+		code, message, retryable = inferenceErr.Code, inferenceErr.Message, inferenceErr.Retryable
+	}
+
+	if retryable && item.Attempts < s.config.MaxAttempts {
+		delay := s.config.RetryDelay * time.Duration(item.Attempts)
+		item.Retry(code, message, time.Now().Add(delay))
+		return
+	}
+	item.MarkAsFailed(code, message)
+}
@@ -19,11 +19,15 @@ const (
 	PeriodMonthly BudgetPeriod = "monthly"
 )
 
+// ScopeShadow isolates shadow traffic spend from production spend
+const ScopeShadow = "shadow"
+
 // BudgetCheckRequest represents a budget check request
 type BudgetCheckRequest struct {
 	OrgID  uuid.UUID
 	AppID  uuid.UUID
 	UserID *uuid.UUID
+	Scope  string // Optional sub-scope (e.g. ScopeShadow)
 	Config *models.BudgetConfig
 	Cost   float64
 }
@@ -44,6 +48,7 @@ type CostRecordRequest struct {
 	OrgID      uuid.UUID
 	AppID      uuid.UUID
 	UserID     *uuid.UUID
+	Scope      string // Optional sub-scope (e.g. ScopeShadow)
 	Cost       float64
 	Currency   string
 	Provider   string
@@ -75,7 +80,7 @@ func (s *BudgetService) CheckBudget(ctx context.Context, req BudgetCheckRequest)
 		}, nil
 	}
 
-	scopeKey := s.buildScopeKey(req.OrgID, req.AppID, req.UserID)
+	scopeKey := s.scopedKey(req.OrgID, req.AppID, req.UserID, req.Scope)
 	now := time.Now()
 
 	result := &BudgetCheckResult{
@@ -125,7 +130,7 @@ func (s *BudgetService) CheckBudget(ctx context.Context, req BudgetCheckRequest)
 
 // RecordCost records the cost of a request using upsert
 func (s *BudgetService) RecordCost(ctx context.Context, req CostRecordRequest) error {
-	scopeKey := s.buildScopeKey(req.OrgID, req.AppID, req.UserID)
+	scopeKey := s.scopedKey(req.OrgID, req.AppID, req.UserID, req.Scope)
 	now := time.Now()
 
 	// Get period keys
@@ -193,7 +198,7 @@ func (s *BudgetService) upsertCost(ctx context.Context, scopeKey, periodKey stri
 
 // recordTransaction records an individual transaction for auditing
 func (s *BudgetService) recordTransaction(ctx context.Context, req CostRecordRequest, timestamp time.Time) error {
-	scopeKey := s.buildScopeKey(req.OrgID, req.AppID, req.UserID)
+	scopeKey := s.scopedKey(req.OrgID, req.AppID, req.UserID, req.Scope)
 
 	query := `
 		INSERT INTO budget_transactions 
@@ -210,14 +215,27 @@ func (s *BudgetService) recordTransaction(ctx context.Context, req CostRecordReq
 	return nil
 }
 
-// buildScopeKey builds a unique key for the budget scope
+// buildScopeKey builds a unique key for the budget scope. A zero app ID
+// selects the organization-level scope, used for spend not tied to an app.
 func (s *BudgetService) buildScopeKey(orgID, appID uuid.UUID, userID *uuid.UUID) string {
+	if appID == uuid.Nil {
+		return fmt.Sprintf("org:%s", orgID.String())
+	}
 	if userID != nil {
 		return fmt.Sprintf("org:%s:app:%s:user:%s", orgID.String(), appID.String(), userID.String())
 	}
 	return fmt.Sprintf("org:%s:app:%s", orgID.String(), appID.String())
 }
 
+// scopedKey builds the scope key with an optional sub-scope suffix
+func (s *BudgetService) scopedKey(orgID, appID uuid.UUID, userID *uuid.UUID, scope string) string {
+	key := s.buildScopeKey(orgID, appID, userID)
+	if scope != "" {
+		key += ":" + scope
+	}
+	return key
+}
+
 // getPeriodKey returns a unique key for a time period
 func (s *BudgetService) getPeriodKey(now time.Time, period BudgetPeriod) string {
 	switch period {
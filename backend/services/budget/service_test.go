@@ -44,6 +44,30 @@ func TestBudgetService_BuildScopeKey(t *testing.T) {
 		expected := "org:" + orgID.String() + ":app:" + appID.String() + ":user:" + userID.String()
 		assert.Equal(t, expected, key)
 	})
+
+	t.Run("organization level", func(t *testing.T) {
+		key := service.buildScopeKey(orgID, uuid.Nil, &userID)
+		assert.Equal(t, "org:"+orgID.String(), key)
+	})
+}
+
+func TestBudgetService_ScopedKey(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	service := NewBudgetService(nil, logger)
+
+	orgID := uuid.New()
+	appID := uuid.New()
+
+	t.Run("without scope", func(t *testing.T) {
+		key := service.scopedKey(orgID, appID, nil, "")
+		assert.Equal(t, service.buildScopeKey(orgID, appID, nil), key)
+	})
+
+	t.Run("shadow scope", func(t *testing.T) {
+		key := service.scopedKey(orgID, appID, nil, ScopeShadow)
+		expected := "org:" + orgID.String() + ":app:" + appID.String() + ":shadow"
+		assert.Equal(t, expected, key)
+	})
 }
 
 func TestBudgetService_GetPeriodKey(t *testing.T) {
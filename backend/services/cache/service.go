@@ -0,0 +1,334 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/internal/rag"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultTTL applies when a cache policy does not set a TTL
+	DefaultTTL = time.Hour
+
+	// DefaultSimilarityThreshold applies when a semantic cache policy does not set a threshold
+	DefaultSimilarityThreshold = 0.95
+
+	// DefaultMaxEntries bounds the cache when no size is given
+	DefaultMaxEntries = 10000
+)
+
+// MatchType describes how a cached response was found
+type MatchType string
+
+const (
+	MatchExact    MatchType = "exact"
+	MatchSemantic MatchType = "semantic"
+)
+
+// Request identifies a cacheable request. Entries are scoped to the
+// organization and application and never shared across tenants. Unless the
+// cache policy is shared, they are also private to the user, or else the API
+// key, that made the request.
+type Request struct {
+	OrgID    uuid.UUID
+	AppID    uuid.UUID
+	UserID   *uuid.UUID
+	APIKeyID *uuid.UUID
+	Request  *providers.ChatRequest
+}
+
+// Hit represents a response served from the cache
+type Hit struct {
+	Response   *providers.ChatResponse
+	Match      MatchType
+	Similarity float64
+	CachedAt   time.Time
+}
+
+// Lookup is the result of a cache lookup. On a miss it carries the computed
+// keys and embedding so the response can be stored without recomputing them.
+type Lookup struct {
+	Hit *Hit
+
+	key       string
+	partition string
+	embedding []float64
+}
+
+// entry is a single cached response
+type entry struct {
+	key       string
+	partition string
+	response  *providers.ChatResponse
+	embedding []float64
+	cachedAt  time.Time
+	expiresAt time.Time
+	element   *list.Element
+}
+
+// ResponseCacheService caches provider responses in memory. Exact matches are
+// keyed by a hash of the caller, model, messages and parameters; semantic
+// matches compare prompt embeddings within the same caller, model and parameters.
+type ResponseCacheService struct {
+	mu         sync.Mutex
+	entries    map[string]*entry
+	partitions map[string]map[string]*entry // partition -> key -> entry, for semantic scans
+	lruList    *list.List
+	maxEntries int
+	embedder   rag.Embedder
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+// NewResponseCacheService creates a new ResponseCacheService instance.
+// The embedder may be nil, in which case semantic matching is disabled.
+func NewResponseCacheService(maxEntries int, embedder rag.Embedder, logger *zap.Logger) *ResponseCacheService {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &ResponseCacheService{
+		entries:    make(map[string]*entry),
+		partitions: make(map[string]map[string]*entry),
+		lruList:    list.New(),
+		maxEntries: maxEntries,
+		embedder:   embedder,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Enabled reports whether a cache policy opts the request into caching
+func Enabled(config *models.CacheConfig) bool {
+	return config != nil && config.Enabled
+}
+
+// Lookup searches the cache for a response to the request
+func (s *ResponseCacheService) Lookup(ctx context.Context, req Request, config *models.CacheConfig) *Lookup {
+	lookup := &Lookup{
+		key:       exactKey(req, config),
+		partition: partitionKey(req, config),
+	}
+
+	if !Enabled(config) {
+		return lookup
+	}
+
+	s.mu.Lock()
+	if e, ok := s.entries[lookup.key]; ok {
+		if s.now().Before(e.expiresAt) {
+			s.lruList.MoveToFront(e.element)
+			lookup.Hit = &Hit{Response: e.response, Match: MatchExact, Similarity: 1, CachedAt: e.cachedAt}
+			s.mu.Unlock()
+			return lookup
+		}
+		s.removeEntry(e)
+	}
+	s.mu.Unlock()
+
+	if !config.Semantic || s.embedder == nil {
+		return lookup
+	}
+
+	embedding, err := s.embedder.Embed(ctx, promptText(req.Request.Messages))
+	if err != nil {
+		s.logger.Warn("failed to embed prompt for semantic cache lookup", zap.Error(err))
+		return lookup
+	}
+	lookup.embedding = embedding
+
+	threshold := config.SimilarityThreshold
+	if threshold <= 0 {
+		threshold = DefaultSimilarityThreshold
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var best *entry
+	bestScore := threshold
+	now := s.now()
+	for _, e := range s.partitions[lookup.partition] {
+		if e.embedding == nil || !now.Before(e.expiresAt) {
+			continue
+		}
+		if score := cosineSimilarity(embedding, e.embedding); score >= bestScore {
+			best, bestScore = e, score
+		}
+	}
+
+	if best != nil {
+		s.lruList.MoveToFront(best.element)
+		lookup.Hit = &Hit{Response: best.response, Match: MatchSemantic, Similarity: bestScore, CachedAt: best.cachedAt}
+	}
+	return lookup
+}
+
+// Store caches a response for the request described by a previous lookup
+func (s *ResponseCacheService) Store(lookup *Lookup, resp *providers.ChatResponse, config *models.CacheConfig) {
+	if lookup == nil || resp == nil || !Enabled(config) {
+		return
+	}
+
+	ttl := time.Duration(config.TTLSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.entries[lookup.key]; ok {
+		s.removeEntry(existing)
+	}
+	if s.lruList.Len() >= s.maxEntries {
+		s.evictLRU()
+	}
+
+	now := s.now()
+	e := &entry{
+		key:       lookup.key,
+		partition: lookup.partition,
+		response:  resp,
+		embedding: lookup.embedding,
+		cachedAt:  now,
+		expiresAt: now.Add(ttl),
+	}
+	e.element = s.lruList.PushFront(e)
+	s.entries[e.key] = e
+
+	partition, ok := s.partitions[e.partition]
+	if !ok {
+		partition = make(map[string]*entry)
+		s.partitions[e.partition] = partition
+	}
+	partition[e.key] = e
+}
+
+// InvalidateApp removes all cached responses for an application
+func (s *ResponseCacheService) InvalidateApp(orgID, appID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prefix := orgID.String() + ":" + appID.String() + ":"
+	for partition, entries := range s.partitions {
+		if !strings.HasPrefix(partition, prefix) {
+			continue
+		}
+		for _, e := range entries {
+			s.removeEntry(e)
+		}
+	}
+}
+
+// Len returns the number of cached responses
+func (s *ResponseCacheService) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lruList.Len()
+}
+
+// removeEntry removes an entry from the cache (must be called with lock held)
+func (s *ResponseCacheService) removeEntry(e *entry) {
+	s.lruList.Remove(e.element)
+	delete(s.entries, e.key)
+	if partition, ok := s.partitions[e.partition]; ok {
+		delete(partition, e.key)
+		if len(partition) == 0 {
+			delete(s.partitions, e.partition)
+		}
+	}
+}
+
+// evictLRU evicts the least recently used entry (must be called with lock held)
+func (s *ResponseCacheService) evictLRU() {
+	if back := s.lruList.Back(); back != nil {
+		s.removeEntry(back.Value.(*entry))
+	}
+}
+
+// partitionKey scopes entries to a tenant, caller, model and parameter set
+func partitionKey(req Request, config *models.CacheConfig) string {
+	r := req.Request
+	params, _ := json.Marshal(struct {
+		MaxTokens        int      `json:"max_tokens"`
+		Temperature      float64  `json:"temperature"`
+		TopP             float64  `json:"top_p"`
+		Stop             []string `json:"stop"`
+		FrequencyPenalty float64  `json:"frequency_penalty"`
+		PresencePenalty  float64  `json:"presence_penalty"`
+	}{r.MaxTokens, r.Temperature, r.TopP, r.Stop, r.FrequencyPenalty, r.PresencePenalty})
+
+	return req.OrgID.String() + ":" + req.AppID.String() + ":" + callerScope(req, config) + ":" + r.Model + ":" + hash(params)
+}
+
+// callerScope names whose requests may share entries: all of the
+// application's under a shared policy, else only the same user's or API key's
+func callerScope(req Request, config *models.CacheConfig) string {
+	switch {
+	case config != nil && config.Shared:
+		return "shared"
+	case req.UserID != nil:
+		return "user-" + req.UserID.String()
+	case req.APIKeyID != nil:
+		return "key-" + req.APIKeyID.String()
+	default:
+		return "app"
+	}
+}
+
+// exactKey hashes the partition together with the full message list
+func exactKey(req Request, config *models.CacheConfig) string {
+	messages, _ := json.Marshal(req.Request.Messages)
+	return partitionKey(req, config) + ":" + hash(messages)
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// promptText flattens the conversation into the text used for embeddings
+func promptText(messages []providers.Message) string {
+	var b strings.Builder
+	for i, msg := range messages {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(msg.Role)
+		b.WriteString(": ")
+		b.WriteString(msg.Content)
+	}
+	return b.String()
+}
+
+// cosineSimilarity returns the cosine similarity of two vectors, or 0 if they
+// differ in length or either is zero
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,242 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+// fakeEmbedder maps prompts to fixed vectors by the keyword they contain
+type fakeEmbedder struct {
+	vectors map[string][]float64
+	err     error
+	calls   int
+}
+
+func (e *fakeEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	for keyword, vector := range e.vectors {
+		if strings.Contains(text, keyword) {
+			return vector, nil
+		}
+	}
+	return []float64{0, 0, 1}, nil
+}
+
+func newCacheRequest(orgID, appID uuid.UUID, content string) Request {
+	return Request{
+		OrgID: orgID,
+		AppID: appID,
+		Request: &providers.ChatRequest{
+			Model:       "gpt-4",
+			Temperature: 0.2,
+			Messages:    []providers.Message{{Role: "user", Content: content}},
+		},
+	}
+}
+
+func cachedResponse(content string) *providers.ChatResponse {
+	return &providers.ChatResponse{
+		Provider: "openai",
+		Model:    "gpt-4",
+		Choices:  []providers.Choice{{Message: providers.Message{Role: "assistant", Content: content}}},
+	}
+}
+
+func TestResponseCache_ExactMatch(t *testing.T) {
+	s := NewResponseCacheService(10, nil, zap.NewNop())
+	config := &models.CacheConfig{Enabled: true}
+	orgID, appID := uuid.New(), uuid.New()
+
+	lookup := s.Lookup(context.Background(), newCacheRequest(orgID, appID, "What is Go?"), config)
+	require.Nil(t, lookup.Hit)
+	s.Store(lookup, cachedResponse("A language"), config)
+
+	lookup = s.Lookup(context.Background(), newCacheRequest(orgID, appID, "What is Go?"), config)
+	require.NotNil(t, lookup.Hit)
+	assert.Equal(t, MatchExact, lookup.Hit.Match)
+	assert.Equal(t, "A language", lookup.Hit.Response.Choices[0].Message.Content)
+}
+
+func TestResponseCache_KeyIncludesModelParamsAndTenant(t *testing.T) {
+	s := NewResponseCacheService(10, nil, zap.NewNop())
+	config := &models.CacheConfig{Enabled: true}
+	orgID, appID := uuid.New(), uuid.New()
+
+	s.Store(s.Lookup(context.Background(), newCacheRequest(orgID, appID, "hi"), config), cachedResponse("hello"), config)
+
+	otherModel := newCacheRequest(orgID, appID, "hi")
+	otherModel.Request.Model = "gpt-3.5-turbo"
+	otherParams := newCacheRequest(orgID, appID, "hi")
+	otherParams.Request.Temperature = 0.9
+
+	for name, req := range map[string]Request{
+		"model":   otherModel,
+		"params":  otherParams,
+		"app":     newCacheRequest(orgID, uuid.New(), "hi"),
+		"org":     newCacheRequest(uuid.New(), appID, "hi"),
+		"message": newCacheRequest(orgID, appID, "hi!"),
+	} {
+		assert.Nil(t, s.Lookup(context.Background(), req, config).Hit, "different %s must not hit", name)
+	}
+}
+
+func TestResponseCache_PrivateToCaller(t *testing.T) {
+	embedder := &fakeEmbedder{vectors: map[string][]float64{"salary": {1, 0, 0}}}
+	s := NewResponseCacheService(10, embedder, zap.NewNop())
+	config := &models.CacheConfig{Enabled: true, Semantic: true}
+	orgID, appID := uuid.New(), uuid.New()
+	alice, bob, key := uuid.New(), uuid.New(), uuid.New()
+
+	asUser := func(userID uuid.UUID) Request {
+		req := newCacheRequest(orgID, appID, "What is my salary?")
+		req.UserID = &userID
+		return req
+	}
+	withKey := newCacheRequest(orgID, appID, "What is my salary?")
+	withKey.APIKeyID = &key
+
+	s.Store(s.Lookup(context.Background(), asUser(alice), config), cachedResponse("Alice's salary"), config)
+
+	assert.NotNil(t, s.Lookup(context.Background(), asUser(alice), config).Hit)
+	assert.Nil(t, s.Lookup(context.Background(), asUser(bob), config).Hit, "another user must not hit")
+	assert.Nil(t, s.Lookup(context.Background(), withKey, config).Hit, "an API key must not hit a user's entry")
+	assert.Nil(t, s.Lookup(context.Background(), newCacheRequest(orgID, appID, "What is my salary?"), config).Hit)
+
+	t.Run("shared policy", func(t *testing.T) {
+		shared := &models.CacheConfig{Enabled: true, Semantic: true, Shared: true}
+		s.Store(s.Lookup(context.Background(), asUser(alice), shared), cachedResponse("Public answer"), shared)
+
+		hit := s.Lookup(context.Background(), asUser(bob), shared).Hit
+		require.NotNil(t, hit)
+		assert.Equal(t, "Public answer", hit.Response.Choices[0].Message.Content)
+		assert.NotNil(t, s.Lookup(context.Background(), withKey, shared).Hit)
+
+		// Shared entries are not served once the policy is private again
+		assert.Nil(t, s.Lookup(context.Background(), asUser(bob), config).Hit)
+	})
+}
+
+func TestResponseCache_DisabledPolicy(t *testing.T) {
+	s := NewResponseCacheService(10, nil, zap.NewNop())
+	orgID, appID := uuid.New(), uuid.New()
+
+	enabled := &models.CacheConfig{Enabled: true}
+	s.Store(s.Lookup(context.Background(), newCacheRequest(orgID, appID, "hi"), enabled), cachedResponse("hello"), enabled)
+
+	assert.Nil(t, s.Lookup(context.Background(), newCacheRequest(orgID, appID, "hi"), &models.CacheConfig{}).Hit)
+	assert.Nil(t, s.Lookup(context.Background(), newCacheRequest(orgID, appID, "hi"), nil).Hit)
+
+	s.Store(s.Lookup(context.Background(), newCacheRequest(orgID, appID, "other"), nil), cachedResponse("x"), nil)
+	assert.Equal(t, 1, s.Len(), "disabled policy must not store")
+}
+
+func TestResponseCache_TTL(t *testing.T) {
+	s := NewResponseCacheService(10, nil, zap.NewNop())
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	config := &models.CacheConfig{Enabled: true, TTLSeconds: 60}
+	orgID, appID := uuid.New(), uuid.New()
+
+	s.Store(s.Lookup(context.Background(), newCacheRequest(orgID, appID, "hi"), config), cachedResponse("hello"), config)
+
+	now = now.Add(59 * time.Second)
+	assert.NotNil(t, s.Lookup(context.Background(), newCacheRequest(orgID, appID, "hi"), config).Hit)
+
+	now = now.Add(2 * time.Second)
+	assert.Nil(t, s.Lookup(context.Background(), newCacheRequest(orgID, appID, "hi"), config).Hit)
+	assert.Equal(t, 0, s.Len(), "expired entry should be removed")
+}
+
+func TestResponseCache_SemanticMatch(t *testing.T) {
+	embedder := &fakeEmbedder{vectors: map[string][]float64{
+		"capital of France":  {1, 0, 0},
+		"France's capital":   {0.99, 0.1, 0},
+		"population of Peru": {0, 1, 0},
+	}}
+	s := NewResponseCacheService(10, embedder, zap.NewNop())
+	config := &models.CacheConfig{Enabled: true, Semantic: true, SimilarityThreshold: 0.9}
+	orgID, appID := uuid.New(), uuid.New()
+
+	lookup := s.Lookup(context.Background(), newCacheRequest(orgID, appID, "What is the capital of France?"), config)
+	require.Nil(t, lookup.Hit)
+	s.Store(lookup, cachedResponse("Paris"), config)
+
+	lookup = s.Lookup(context.Background(), newCacheRequest(orgID, appID, "Tell me France's capital"), config)
+	require.NotNil(t, lookup.Hit)
+	assert.Equal(t, MatchSemantic, lookup.Hit.Match)
+	assert.InDelta(t, 0.995, lookup.Hit.Similarity, 0.001)
+	assert.Equal(t, "Paris", lookup.Hit.Response.Choices[0].Message.Content)
+
+	assert.Nil(t, s.Lookup(context.Background(), newCacheRequest(orgID, appID, "What is the population of Peru?"), config).Hit)
+	assert.Nil(t, s.Lookup(context.Background(), newCacheRequest(orgID, uuid.New(), "Tell me France's capital"), config).Hit,
+		"semantic matches must stay within the application")
+}
+
+func TestResponseCache_SemanticRequiresOptIn(t *testing.T) {
+	embedder := &fakeEmbedder{}
+	s := NewResponseCacheService(10, embedder, zap.NewNop())
+	config := &models.CacheConfig{Enabled: true}
+
+	s.Lookup(context.Background(), newCacheRequest(uuid.New(), uuid.New(), "hi"), config)
+	assert.Equal(t, 0, embedder.calls)
+}
+
+func TestResponseCache_EmbedderErrorIsAMiss(t *testing.T) {
+	s := NewResponseCacheService(10, &fakeEmbedder{err: errors.New("unavailable")}, zap.NewNop())
+	config := &models.CacheConfig{Enabled: true, Semantic: true}
+
+	lookup := s.Lookup(context.Background(), newCacheRequest(uuid.New(), uuid.New(), "hi"), config)
+	assert.Nil(t, lookup.Hit)
+}
+
+func TestResponseCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	s := NewResponseCacheService(2, nil, zap.NewNop())
+	config := &models.CacheConfig{Enabled: true}
+	orgID, appID := uuid.New(), uuid.New()
+
+	for _, prompt := range []string{"a", "b"} {
+		s.Store(s.Lookup(context.Background(), newCacheRequest(orgID, appID, prompt), config), cachedResponse(prompt), config)
+	}
+	// Touch "a" so "b" becomes least recently used
+	require.NotNil(t, s.Lookup(context.Background(), newCacheRequest(orgID, appID, "a"), config).Hit)
+
+	s.Store(s.Lookup(context.Background(), newCacheRequest(orgID, appID, "c"), config), cachedResponse("c"), config)
+
+	assert.Equal(t, 2, s.Len())
+	assert.NotNil(t, s.Lookup(context.Background(), newCacheRequest(orgID, appID, "a"), config).Hit)
+	assert.Nil(t, s.Lookup(context.Background(), newCacheRequest(orgID, appID, "b"), config).Hit)
+}
+
+func TestResponseCache_InvalidateApp(t *testing.T) {
+	s := NewResponseCacheService(10, nil, zap.NewNop())
+	config := &models.CacheConfig{Enabled: true}
+	orgID, appID, otherApp := uuid.New(), uuid.New(), uuid.New()
+
+	s.Store(s.Lookup(context.Background(), newCacheRequest(orgID, appID, "hi"), config), cachedResponse("hello"), config)
+	s.Store(s.Lookup(context.Background(), newCacheRequest(orgID, otherApp, "hi"), config), cachedResponse("hello"), config)
+
+	s.InvalidateApp(orgID, appID)
+
+	assert.Nil(t, s.Lookup(context.Background(), newCacheRequest(orgID, appID, "hi"), config).Hit)
+	assert.NotNil(t, s.Lookup(context.Background(), newCacheRequest(orgID, otherApp, "hi"), config).Hit)
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1.0, cosineSimilarity([]float64{1, 2}, []float64{2, 4}), 1e-9)
+	assert.InDelta(t, 0.0, cosineSimilarity([]float64{1, 0}, []float64{0, 1}), 1e-9)
+	assert.Equal(t, 0.0, cosineSimilarity([]float64{1}, []float64{1, 0}))
+	assert.Equal(t, 0.0, cosineSimilarity([]float64{0, 0}, []float64{1, 0}))
+}
@@ -0,0 +1,301 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultHistoryTokens bounds the replayed history when a request sets no budget
+	DefaultHistoryTokens = 4000
+
+	// DefaultListLimit and MaxListLimit bound conversation listing
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+
+	// maxTitleLength is the length of titles derived from the first message
+	maxTitleLength = 80
+)
+
+// Completer runs a chat completion through the inference pipeline
+type Completer interface {
+	ProcessChatCompletion(ctx context.Context, req *inference.CompletionRequest) (*inference.CompletionResponse, error)
+}
+
+// CreateConversationRequest represents a request to start a conversation
+type CreateConversationRequest struct {
+	OrgID        uuid.UUID
+	AppID        *uuid.UUID
+	UserID       uuid.UUID
+	Title        string
+	Model        string
+	SystemPrompt string
+}
+
+// SendMessageRequest represents a new user message posted to a conversation
+type SendMessageRequest struct {
+	ConversationID uuid.UUID
+	OrgID          uuid.UUID
+	AppID          uuid.UUID // Application the completion is accounted to
+	UserID         uuid.UUID
+	Content        string
+	Model          string // Overrides the conversation's model for this turn
+
+	// Model parameters
+	MaxTokens   int
+	Temperature float64
+
+	// HistoryTokens bounds the stored turns replayed to the model; defaults to DefaultHistoryTokens
+	HistoryTokens int
+
+	// Request metadata
+	RequestID string
+	IPAddress string
+	UserAgent string
+}
+
+// SendMessageResult represents the outcome of a conversation turn
+type SendMessageResult struct {
+	Conversation *models.Conversation        `json:"conversation"`
+	Message      *models.ConversationMessage `json:"message"` // Assistant reply
+
+	// HistoryMessages is the number of stored turns replayed with the new message
+	HistoryMessages int `json:"history_messages"`
+
+	Completion *inference.CompletionResponse `json:"completion"`
+}
+
+// ConversationService manages server-side conversation threads. Each turn is
+// sent through the inference pipeline with the stored history, so policies
+// see the full context rather than only the new message.
+type ConversationService struct {
+	conversationRepo repositories.ConversationRepository
+	completer        Completer
+	txManager        repositories.TransactionManager
+	logger           *zap.Logger
+}
+
+// NewConversationService creates a new ConversationService instance
+func NewConversationService(conversationRepo repositories.ConversationRepository, completer Completer, txManager repositories.TransactionManager, logger *zap.Logger) *ConversationService {
+	return &ConversationService{
+		conversationRepo: conversationRepo,
+		completer:        completer,
+		txManager:        txManager,
+		logger:           logger,
+	}
+}
+
+// CreateConversation starts an empty conversation owned by the user
+func (s *ConversationService) CreateConversation(ctx context.Context, req CreateConversationRequest) (*models.Conversation, error) {
+	if req.UserID == uuid.Nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "conversations require a user", nil)
+	}
+	if req.Model == "" {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "model is required", nil)
+	}
+
+	conversation := models.NewConversation(req.OrgID, req.AppID, req.UserID, req.Title, req.Model, req.SystemPrompt)
+	if err := s.conversationRepo.Create(ctx, conversation); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create conversation", err)
+	}
+
+	s.logger.Info("conversation created",
+		zap.String("conversation_id", conversation.ID.String()),
+		zap.String("org_id", req.OrgID.String()),
+		zap.String("user_id", req.UserID.String()))
+
+	return conversation, nil
+}
+
+// GetConversation retrieves a conversation owned by the user. Conversations of
+// other users or organizations are reported as not found.
+func (s *ConversationService) GetConversation(ctx context.Context, id, orgID, userID uuid.UUID) (*models.Conversation, error) {
+	conversation, err := s.conversationRepo.GetByID(ctx, id)
+	if err != nil || !conversation.IsOwnedBy(orgID, userID) {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "conversation not found", err)
+	}
+	return conversation, nil
+}
+
+// ListConversations lists the user's conversations, most recently active first
+func (s *ConversationService) ListConversations(ctx context.Context, orgID, userID uuid.UUID, limit, offset int) ([]*models.Conversation, error) {
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	limit = min(limit, MaxListLimit)
+	offset = max(offset, 0)
+
+	conversations, err := s.conversationRepo.ListByUser(ctx, orgID, userID, limit, offset)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list conversations", err)
+	}
+	return conversations, nil
+}
+
+// DeleteConversation deletes a conversation owned by the user with its messages
+func (s *ConversationService) DeleteConversation(ctx context.Context, id, orgID, userID uuid.UUID) error {
+	if _, err := s.GetConversation(ctx, id, orgID, userID); err != nil {
+		return err
+	}
+
+	if err := s.conversationRepo.Delete(ctx, id); err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to delete conversation", err)
+	}
+
+	s.logger.Info("conversation deleted",
+		zap.String("conversation_id", id.String()),
+		zap.String("org_id", orgID.String()))
+	return nil
+}
+
+// ListMessages lists the stored turns of a conversation owned by the user
+func (s *ConversationService) ListMessages(ctx context.Context, id, orgID, userID uuid.UUID) ([]*models.ConversationMessage, error) {
+	if _, err := s.GetConversation(ctx, id, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.conversationRepo.ListMessages(ctx, id)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list conversation messages", err)
+	}
+	return messages, nil
+}
+
+// SendMessage appends a user message to a conversation and returns the
+// model's reply. The request sent to the model is the conversation's system
+// prompt, the most recent stored turns that fit in the history token budget
+// and the new message. Both turns are stored only if the completion succeeds,
+// so a rejected or failed message can simply be retried.
+func (s *ConversationService) SendMessage(ctx context.Context, req SendMessageRequest) (*SendMessageResult, error) {
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "content is required", nil)
+	}
+
+	conversation, err := s.GetConversation(ctx, req.ConversationID, req.OrgID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.conversationRepo.ListMessages(ctx, conversation.ID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to load conversation history", err)
+	}
+
+	model := conversation.Model
+	if req.Model != "" {
+		model = req.Model
+	}
+	historyTokens := req.HistoryTokens
+	if historyTokens <= 0 {
+		historyTokens = DefaultHistoryTokens
+	}
+
+	history := selectHistory(stored, historyTokens)
+	messages := make([]providers.Message, 0, len(history)+2)
+	if conversation.SystemPrompt != "" {
+		messages = append(messages, providers.Message{Role: "system", Content: conversation.SystemPrompt})
+	}
+	for _, msg := range history {
+		messages = append(messages, providers.Message{Role: msg.Role, Content: msg.Content})
+	}
+	userMessage := providers.Message{Role: "user", Content: req.Content}
+	messages = append(messages, userMessage)
+
+	userID := req.UserID
+	completion, err := s.completer.ProcessChatCompletion(ctx, &inference.CompletionRequest{
+		OrgID:       req.OrgID,
+		AppID:       req.AppID,
+		UserID:      &userID,
+		Model:       model,
+		Messages:    messages,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		RequestID:   req.RequestID,
+		Metadata:    map[string]string{"conversation_id": conversation.ID.String()},
+		IPAddress:   req.IPAddress,
+		UserAgent:   req.UserAgent,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(completion.Choices) == 0 {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "completion returned no choices", nil)
+	}
+
+	reply := completion.Choices[0].Message
+	userTurn := models.NewConversationMessage(conversation.ID, "user", req.Content, countTokens(completion.Model, userMessage))
+	assistantTurn := models.NewConversationMessage(conversation.ID, "assistant", reply.Content, countTokens(completion.Model, reply))
+	assistantTurn.CreatedAt = userTurn.CreatedAt.Add(time.Microsecond) // Keep the turns ordered
+	inferenceID := completion.ID
+	assistantTurn.InferenceID = &inferenceID
+
+	if conversation.Title == "" {
+		conversation.Title = deriveTitle(req.Content)
+	}
+	conversation.MessageCount += 2
+	conversation.UpdatedAt = time.Now()
+
+	err = s.txManager.InTransaction(ctx, func(ctx context.Context, tx repositories.Transaction) error {
+		if err := s.conversationRepo.AddMessage(ctx, userTurn); err != nil {
+			return err
+		}
+		if err := s.conversationRepo.AddMessage(ctx, assistantTurn); err != nil {
+			return err
+		}
+		return s.conversationRepo.Update(ctx, conversation)
+	})
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to store conversation turn", err)
+	}
+
+	s.logger.Info("conversation turn completed",
+		zap.String("conversation_id", conversation.ID.String()),
+		zap.String("inference_id", completion.ID.String()),
+		zap.Int("history_messages", len(history)))
+
+	return &SendMessageResult{
+		Conversation:    conversation,
+		Message:         assistantTurn,
+		HistoryMessages: len(history),
+		Completion:      completion,
+	}, nil
+}
+
+// selectHistory returns the most recent messages whose stored token counts
+// fit in budget, oldest first. The window never starts with an assistant turn,
+// so the model does not see a reply without the message it answered.
+func selectHistory(messages []*models.ConversationMessage, budget int) []*models.ConversationMessage {
+	start, used := len(messages), 0
+	for start > 0 && used+messages[start-1].Tokens <= budget {
+		start--
+		used += messages[start].Tokens
+	}
+	for start < len(messages) && messages[start].Role != "user" {
+		start++
+	}
+	return messages[start:]
+}
+
+// countTokens returns the prompt tokens a message costs when it is replayed
+func countTokens(model string, message providers.Message) int {
+	counts, _ := providers.CountMessageTokens(nil, model, []providers.Message{message})
+	return counts[0]
+}
+
+// deriveTitle titles a conversation after the start of its first message
+func deriveTitle(content string) string {
+	title := strings.Join(strings.Fields(content), " ")
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		title = strings.TrimSpace(string(runes[:maxTitleLength])) + "…"
+	}
+	return title
+}
@@ -0,0 +1,292 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/inference"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+// fakeConversationRepository is an in-memory ConversationRepository
+type fakeConversationRepository struct {
+	conversations map[uuid.UUID]*models.Conversation
+	messages      map[uuid.UUID][]*models.ConversationMessage
+}
+
+func newFakeConversationRepository() *fakeConversationRepository {
+	return &fakeConversationRepository{
+		conversations: map[uuid.UUID]*models.Conversation{},
+		messages:      map[uuid.UUID][]*models.ConversationMessage{},
+	}
+}
+
+func (r *fakeConversationRepository) Create(ctx context.Context, conversation *models.Conversation) error {
+	r.conversations[conversation.ID] = conversation
+	return nil
+}
+
+func (r *fakeConversationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Conversation, error) {
+	if c, ok := r.conversations[id]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("conversation not found: %s", id)
+}
+
+func (r *fakeConversationRepository) ListByUser(ctx context.Context, orgID, userID uuid.UUID, limit, offset int) ([]*models.Conversation, error) {
+	var conversations []*models.Conversation
+	for _, c := range r.conversations {
+		if c.OrgID == orgID && c.UserID == userID {
+			conversations = append(conversations, c)
+		}
+	}
+	return conversations, nil
+}
+
+func (r *fakeConversationRepository) Update(ctx context.Context, conversation *models.Conversation) error {
+	r.conversations[conversation.ID] = conversation
+	return nil
+}
+
+func (r *fakeConversationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.conversations, id)
+	delete(r.messages, id)
+	return nil
+}
+
+func (r *fakeConversationRepository) AddMessage(ctx context.Context, message *models.ConversationMessage) error {
+	r.messages[message.ConversationID] = append(r.messages[message.ConversationID], message)
+	return nil
+}
+
+func (r *fakeConversationRepository) ListMessages(ctx context.Context, conversationID uuid.UUID) ([]*models.ConversationMessage, error) {
+	return r.messages[conversationID], nil
+}
+
+func (r *fakeConversationRepository) WithTx(tx repositories.Transaction) repositories.ConversationRepository {
+	return r
+}
+
+// fakeCompleter records requests and echoes the last message
+type fakeCompleter struct {
+	requests []*inference.CompletionRequest
+	err      error
+}
+
+func (c *fakeCompleter) ProcessChatCompletion(ctx context.Context, req *inference.CompletionRequest) (*inference.CompletionResponse, error) {
+	c.requests = append(c.requests, req)
+	if c.err != nil {
+		return nil, c.err
+	}
+	last := req.Messages[len(req.Messages)-1].Content
+	return &inference.CompletionResponse{
+		ID:    uuid.New(),
+		Model: req.Model,
+		Choices: []inference.Choice{{
+			Message:      providers.Message{Role: "assistant", Content: "re: " + last},
+			FinishReason: "stop",
+		}},
+	}, nil
+}
+
+// fakeTxManager runs transactional functions directly
+type fakeTxManager struct{}
+
+func (fakeTxManager) Begin(ctx context.Context) (repositories.Transaction, error) {
+	return nil, nil
+}
+
+func (fakeTxManager) InTransaction(ctx context.Context, fn func(ctx context.Context, tx repositories.Transaction) error) error {
+	return fn(ctx, nil)
+}
+
+type conversationFixture struct {
+	service   *ConversationService
+	repo      *fakeConversationRepository
+	completer *fakeCompleter
+	orgID     uuid.UUID
+	appID     uuid.UUID
+	userID    uuid.UUID
+}
+
+func newConversationFixture() *conversationFixture {
+	repo := newFakeConversationRepository()
+	completer := &fakeCompleter{}
+	return &conversationFixture{
+		service:   NewConversationService(repo, completer, fakeTxManager{}, zap.NewNop()),
+		repo:      repo,
+		completer: completer,
+		orgID:     uuid.New(),
+		appID:     uuid.New(),
+		userID:    uuid.New(),
+	}
+}
+
+func (f *conversationFixture) create(t *testing.T, systemPrompt string) *models.Conversation {
+	t.Helper()
+	conversation, err := f.service.CreateConversation(context.Background(), CreateConversationRequest{
+		OrgID:        f.orgID,
+		AppID:        &f.appID,
+		UserID:       f.userID,
+		Model:        "gpt-4o",
+		SystemPrompt: systemPrompt,
+	})
+	require.NoError(t, err)
+	return conversation
+}
+
+func (f *conversationFixture) send(t *testing.T, id uuid.UUID, content string, historyTokens int) *SendMessageResult {
+	t.Helper()
+	result, err := f.service.SendMessage(context.Background(), SendMessageRequest{
+		ConversationID: id,
+		OrgID:          f.orgID,
+		AppID:          f.appID,
+		UserID:         f.userID,
+		Content:        content,
+		HistoryTokens:  historyTokens,
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestConversationService_SendMessage(t *testing.T) {
+	f := newConversationFixture()
+	conversation := f.create(t, "You are terse.")
+
+	first := f.send(t, conversation.ID, "What is the capital of France?", 0)
+	assert.Equal(t, "re: What is the capital of France?", first.Message.Content)
+	assert.Equal(t, 0, first.HistoryMessages)
+	assert.Equal(t, "What is the capital of France?", first.Conversation.Title, "title is derived from the first message")
+	require.NotNil(t, first.Message.InferenceID)
+	assert.Equal(t, first.Completion.ID, *first.Message.InferenceID)
+
+	second := f.send(t, conversation.ID, "And of Spain?", 0)
+	assert.Equal(t, 2, second.HistoryMessages)
+	assert.Equal(t, 4, second.Conversation.MessageCount)
+
+	req := f.completer.requests[1]
+	require.Len(t, req.Messages, 4)
+	assert.Equal(t, "system", req.Messages[0].Role)
+	assert.Equal(t, "What is the capital of France?", req.Messages[1].Content)
+	assert.Equal(t, "assistant", req.Messages[2].Role)
+	assert.Equal(t, "And of Spain?", req.Messages[3].Content)
+	assert.Equal(t, f.appID, req.AppID)
+	assert.Equal(t, f.userID, *req.UserID)
+	assert.Equal(t, conversation.ID.String(), req.Metadata["conversation_id"])
+
+	stored := f.repo.messages[conversation.ID]
+	require.Len(t, stored, 4)
+	for _, msg := range stored {
+		assert.Positive(t, msg.Tokens)
+	}
+}
+
+func TestConversationService_SendMessage_HistoryWindow(t *testing.T) {
+	f := newConversationFixture()
+	conversation := f.create(t, "")
+
+	for i := 0; i < 5; i++ {
+		f.send(t, conversation.ID, fmt.Sprintf("message %d %s", i, strings.Repeat("word ", 20)), 0)
+	}
+
+	stored := f.repo.messages[conversation.ID]
+	budget := stored[8].Tokens + stored[9].Tokens + stored[7].Tokens // Fits the last turn and a dangling reply
+
+	result := f.send(t, conversation.ID, "latest", budget)
+	assert.Equal(t, 2, result.HistoryMessages, "window is trimmed to whole turns within the budget")
+
+	req := f.completer.requests[len(f.completer.requests)-1]
+	require.Len(t, req.Messages, 3)
+	assert.Equal(t, "user", req.Messages[0].Role, "window does not start with an assistant reply")
+	assert.Equal(t, stored[8].Content, req.Messages[0].Content)
+	assert.Equal(t, "latest", req.Messages[2].Content)
+}
+
+func TestConversationService_SendMessage_FailureNotStored(t *testing.T) {
+	f := newConversationFixture()
+	conversation := f.create(t, "")
+	f.completer.err = inference.NewPolicyViolationError("blocked", nil)
+
+	_, err := f.service.SendMessage(context.Background(), SendMessageRequest{
+		ConversationID: conversation.ID,
+		OrgID:          f.orgID,
+		AppID:          f.appID,
+		UserID:         f.userID,
+		Content:        "hello",
+	})
+	require.Error(t, err)
+
+	var inferenceErr *inference.InferenceError
+	assert.ErrorAs(t, err, &inferenceErr, "pipeline errors are passed through")
+	assert.Empty(t, f.repo.messages[conversation.ID])
+	assert.Equal(t, 0, f.repo.conversations[conversation.ID].MessageCount)
+}
+
+func TestConversationService_TenantIsolation(t *testing.T) {
+	f := newConversationFixture()
+	conversation := f.create(t, "")
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		orgID  uuid.UUID
+		userID uuid.UUID
+	}{
+		{"other user in the organization", f.orgID, uuid.New()},
+		{"same user ID in another organization", uuid.New(), f.userID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.service.GetConversation(ctx, conversation.ID, tt.orgID, tt.userID)
+			assert.True(t, services.IsNotFoundError(err))
+
+			_, err = f.service.ListMessages(ctx, conversation.ID, tt.orgID, tt.userID)
+			assert.True(t, services.IsNotFoundError(err))
+
+			_, err = f.service.SendMessage(ctx, SendMessageRequest{ConversationID: conversation.ID, OrgID: tt.orgID, UserID: tt.userID, Content: "hi"})
+			assert.True(t, services.IsNotFoundError(err))
+
+			assert.True(t, services.IsNotFoundError(f.service.DeleteConversation(ctx, conversation.ID, tt.orgID, tt.userID)))
+
+			listed, err := f.service.ListConversations(ctx, tt.orgID, tt.userID, 0, 0)
+			require.NoError(t, err)
+			assert.Empty(t, listed)
+		})
+	}
+
+	assert.Empty(t, f.completer.requests)
+	require.NoError(t, f.service.DeleteConversation(ctx, conversation.ID, f.orgID, f.userID))
+	assert.Empty(t, f.repo.conversations)
+}
+
+func TestSelectHistory(t *testing.T) {
+	turn := func(role string, tokens int) *models.ConversationMessage {
+		return &models.ConversationMessage{Role: role, Tokens: tokens}
+	}
+	messages := []*models.ConversationMessage{
+		turn("user", 10), turn("assistant", 10), turn("user", 10), turn("assistant", 10),
+	}
+
+	assert.Len(t, selectHistory(messages, 100), 4)
+	assert.Len(t, selectHistory(messages, 20), 2)
+	assert.Len(t, selectHistory(messages, 30), 2, "dangling assistant reply is dropped")
+	assert.Empty(t, selectHistory(messages, 5))
+	assert.Empty(t, selectHistory(nil, 100))
+}
+
+func TestDeriveTitle(t *testing.T) {
+	assert.Equal(t, "Plan a trip", deriveTitle("  Plan   a\ntrip "))
+
+	title := deriveTitle(strings.Repeat("a", 200))
+	assert.Equal(t, maxTitleLength+1, len([]rune(title)))
+}
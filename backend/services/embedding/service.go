@@ -0,0 +1,133 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/services/budget"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+// MaxBatchSize is the maximum number of inputs sent in one provider request
+const MaxBatchSize = 96
+
+// CostRecorder charges spend to a budget scope
+type CostRecorder interface {
+	RecordCost(ctx context.Context, req budget.CostRecordRequest) error
+}
+
+// AppRequest is an embedding request made by an application
+type AppRequest struct {
+	OrgID     uuid.UUID
+	AppID     uuid.UUID
+	RequestID string
+	Input     []string
+}
+
+// EmbeddingService is the gateway's embedder. It implements rag.Embedder for
+// retrieval and the semantic cache, and embeds batches for ingestion.
+type EmbeddingService struct {
+	provider     providers.EmbeddingProvider
+	model        string
+	costRecorder CostRecorder
+	logger       *zap.Logger
+}
+
+// NewEmbeddingService creates an embedder backed by provider and model
+func NewEmbeddingService(provider providers.EmbeddingProvider, model string, logger *zap.Logger) *EmbeddingService {
+	return &EmbeddingService{
+		provider: provider,
+		model:    model,
+		logger:   logger,
+	}
+}
+
+// SetCostRecorder enables charging application embedding requests to budgets
+func (s *EmbeddingService) SetCostRecorder(costRecorder CostRecorder) {
+	s.costRecorder = costRecorder
+}
+
+// Model returns the embedding model in use
+func (s *EmbeddingService) Model() string {
+	return s.model
+}
+
+// Embed generates the embedding for a single text
+func (s *EmbeddingService) Embed(ctx context.Context, text string) ([]float64, error) {
+	resp, err := s.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Embeddings[0], nil
+}
+
+// EmbedBatch generates embeddings for texts, splitting them into provider
+// requests of at most MaxBatchSize inputs. Usage and cost are summed.
+func (s *EmbeddingService) EmbedBatch(ctx context.Context, texts []string) (*providers.EmbeddingResponse, error) {
+	result := &providers.EmbeddingResponse{
+		Provider:   s.provider.Name(),
+		Model:      s.model,
+		Embeddings: make([][]float64, 0, len(texts)),
+	}
+
+	for start := 0; start < len(texts); start += MaxBatchSize {
+		end := min(start+MaxBatchSize, len(texts))
+
+		resp, err := s.provider.Embed(ctx, &providers.EmbeddingRequest{
+			Model: s.model,
+			Input: texts[start:end],
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed batch: %w", err)
+		}
+		if len(resp.Embeddings) != end-start {
+			return nil, fmt.Errorf("provider returned %d embeddings for %d inputs", len(resp.Embeddings), end-start)
+		}
+
+		result.Embeddings = append(result.Embeddings, resp.Embeddings...)
+		result.Usage.PromptTokens += resp.Usage.PromptTokens
+		result.Usage.TotalTokens += resp.Usage.TotalTokens
+		result.Cost += resp.Cost
+	}
+
+	s.logger.Debug("generated embeddings",
+		zap.String("model", s.model),
+		zap.Int("inputs", len(texts)),
+		zap.Int("tokens", result.Usage.TotalTokens))
+
+	return result, nil
+}
+
+// EmbedForApp embeds texts on behalf of an application and charges the cost
+// to its budget
+func (s *EmbeddingService) EmbedForApp(ctx context.Context, req AppRequest) (*providers.EmbeddingResponse, error) {
+	resp, err := s.EmbedBatch(ctx, req.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	// The embeddings are already paid for, so a failure to record them must
+	// not fail the request
+	if s.costRecorder != nil {
+		err := s.costRecorder.RecordCost(ctx, budget.CostRecordRequest{
+			OrgID:      req.OrgID,
+			AppID:      req.AppID,
+			Cost:       resp.Cost,
+			Currency:   "USD",
+			Provider:   resp.Provider,
+			Model:      resp.Model,
+			RequestID:  req.RequestID,
+			TokensUsed: resp.Usage.TotalTokens,
+		})
+		if err != nil {
+			s.logger.Error("failed to record embedding cost",
+				zap.String("app_id", req.AppID.String()),
+				zap.Float64("cost", resp.Cost),
+				zap.Error(err))
+		}
+	}
+
+	return resp, nil
+}
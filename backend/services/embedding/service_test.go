@@ -0,0 +1,93 @@
+package embedding
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/services/budget"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+// fakeProvider embeds each input as its batch position; chat methods are unused
+type fakeProvider struct {
+	providers.Provider
+	batches []int
+	err     error
+}
+
+func (p *fakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *fakeProvider) Embed(ctx context.Context, req *providers.EmbeddingRequest) (*providers.EmbeddingResponse, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.batches = append(p.batches, len(req.Input))
+	resp := &providers.EmbeddingResponse{Model: req.Model, Cost: 0.5}
+	for i := range req.Input {
+		resp.Embeddings = append(resp.Embeddings, []float64{float64(i)})
+	}
+	resp.Usage.TotalTokens = len(req.Input)
+	return resp, nil
+}
+
+func TestEmbeddingService_EmbedBatch(t *testing.T) {
+	provider := &fakeProvider{}
+	service := NewEmbeddingService(provider, "test-embedding", zap.NewNop())
+
+	texts := make([]string, MaxBatchSize+4)
+	resp, err := service.EmbedBatch(context.Background(), texts)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int{MaxBatchSize, 4}, provider.batches)
+	assert.Len(t, resp.Embeddings, len(texts))
+	assert.Equal(t, []float64{0}, resp.Embeddings[MaxBatchSize], "second batch follows the first")
+	assert.Equal(t, len(texts), resp.Usage.TotalTokens)
+	assert.Equal(t, 1.0, resp.Cost)
+	assert.Equal(t, "fake", resp.Provider)
+}
+
+func TestEmbeddingService_Embed(t *testing.T) {
+	service := NewEmbeddingService(&fakeProvider{}, "test-embedding", zap.NewNop())
+
+	vector, err := service.Embed(context.Background(), "hello")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{0}, vector)
+
+	service = NewEmbeddingService(&fakeProvider{err: errors.New("unavailable")}, "test-embedding", zap.NewNop())
+	_, err = service.Embed(context.Background(), "hello")
+	assert.Error(t, err)
+}
+
+// fakeCostRecorder records charged costs
+type fakeCostRecorder struct {
+	requests []budget.CostRecordRequest
+}
+
+func (r *fakeCostRecorder) RecordCost(ctx context.Context, req budget.CostRecordRequest) error {
+	r.requests = append(r.requests, req)
+	return nil
+}
+
+func TestEmbeddingService_EmbedForApp(t *testing.T) {
+	service := NewEmbeddingService(&fakeProvider{}, "test-embedding", zap.NewNop())
+	recorder := &fakeCostRecorder{}
+	service.SetCostRecorder(recorder)
+
+	orgID, appID := uuid.New(), uuid.New()
+	resp, err := service.EmbedForApp(context.Background(), AppRequest{OrgID: orgID, AppID: appID, RequestID: "req-1", Input: []string{"a", "b"}})
+	require.NoError(t, err)
+	assert.Len(t, resp.Embeddings, 2)
+
+	require.Len(t, recorder.requests, 1)
+	assert.Equal(t, orgID, recorder.requests[0].OrgID)
+	assert.Equal(t, appID, recorder.requests[0].AppID)
+	assert.Equal(t, 0.5, recorder.requests[0].Cost)
+	assert.Equal(t, 2, recorder.requests[0].TokensUsed)
+}
@@ -0,0 +1,325 @@
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"go.uber.org/zap"
+)
+
+// CreateExperimentRequest represents a request to create an experiment
+type CreateExperimentRequest struct {
+	OrgID       uuid.UUID
+	AppID       *uuid.UUID
+	Name        string
+	TargetModel string
+	Arms        []models.ExperimentArm
+}
+
+// AssignmentRequest represents a request to assign traffic to an experiment arm
+type AssignmentRequest struct {
+	OrgID     uuid.UUID
+	AppID     uuid.UUID
+	UserID    *uuid.UUID
+	RequestID string
+	Model     string
+}
+
+// Assignment represents the arm a request was bucketed into
+type Assignment struct {
+	ExperimentID uuid.UUID
+	Arm          models.ExperimentArm
+	Bucket       int
+}
+
+// ArmReport compares one experiment arm against the others
+type ArmReport struct {
+	repositories.ArmMetrics
+	Model                string  `json:"model"`
+	Provider             string  `json:"provider,omitempty"`
+	Weight               int     `json:"weight"`
+	ErrorRate            float64 `json:"error_rate"`
+	AvgCostPerRequest    float64 `json:"avg_cost_per_request"`
+	PositiveFeedbackRate float64 `json:"positive_feedback_rate"`
+}
+
+// Report represents the per-arm comparison for an experiment
+type Report struct {
+	ExperimentID uuid.UUID               `json:"experiment_id"`
+	Name         string                  `json:"name"`
+	Status       models.ExperimentStatus `json:"status"`
+	TargetModel  string                  `json:"target_model"`
+	Arms         []ArmReport             `json:"arms"`
+}
+
+// RunningCacheTTL bounds how long Assign reuses the running experiments of an
+// app and model. Starting or stopping an experiment invalidates them on this
+// instance; other instances pick up the change within the TTL.
+const RunningCacheTTL = 30 * time.Second
+
+// runningKey identifies the running experiments applicable to a request
+type runningKey struct {
+	orgID uuid.UUID
+	appID uuid.UUID
+	model string
+}
+
+// runningEntry holds cached running experiments until they expire
+type runningEntry struct {
+	experiments []*models.Experiment
+	expiresAt   time.Time
+}
+
+// ExperimentService manages A/B experiments and traffic assignment
+type ExperimentService struct {
+	experimentRepo repositories.ExperimentRepository
+	inferenceRepo  repositories.InferenceRequestRepository
+	logger         *zap.Logger
+
+	mu      sync.Mutex
+	running map[runningKey]runningEntry
+	now     func() time.Time
+}
+
+// NewExperimentService creates a new ExperimentService instance
+func NewExperimentService(experimentRepo repositories.ExperimentRepository, inferenceRepo repositories.InferenceRequestRepository, logger *zap.Logger) *ExperimentService {
+	return &ExperimentService{
+		experimentRepo: experimentRepo,
+		inferenceRepo:  inferenceRepo,
+		logger:         logger,
+		running:        make(map[runningKey]runningEntry),
+		now:            time.Now,
+	}
+}
+
+// CreateExperiment validates and stores a new experiment in draft state
+func (s *ExperimentService) CreateExperiment(ctx context.Context, req CreateExperimentRequest) (*models.Experiment, error) {
+	if req.Name == "" || req.TargetModel == "" {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "name and target_model are required", nil)
+	}
+	if err := models.ValidateArms(req.Arms); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), nil)
+	}
+
+	experiment, err := models.NewExperiment(req.OrgID, req.Name, req.TargetModel, req.Arms)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to build experiment", err)
+	}
+	experiment.AppID = req.AppID
+
+	if err := s.experimentRepo.Create(ctx, experiment); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create experiment", err)
+	}
+
+	s.logger.Info("experiment created",
+		zap.String("experiment_id", experiment.ID.String()),
+		zap.String("org_id", req.OrgID.String()),
+		zap.String("target_model", req.TargetModel))
+
+	return experiment, nil
+}
+
+// GetExperiment retrieves an experiment scoped to an organization
+func (s *ExperimentService) GetExperiment(ctx context.Context, id, orgID uuid.UUID) (*models.Experiment, error) {
+	experiment, err := s.experimentRepo.GetByID(ctx, id)
+	if err != nil || experiment.OrgID != orgID {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "experiment not found", err)
+	}
+	return experiment, nil
+}
+
+// ListExperiments lists all experiments for an organization
+func (s *ExperimentService) ListExperiments(ctx context.Context, orgID uuid.UUID) ([]*models.Experiment, error) {
+	experiments, err := s.experimentRepo.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list experiments", err)
+	}
+	return experiments, nil
+}
+
+// StartExperiment starts assigning traffic to an experiment
+func (s *ExperimentService) StartExperiment(ctx context.Context, id, orgID uuid.UUID) (*models.Experiment, error) {
+	experiment, err := s.GetExperiment(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if experiment.Status != models.ExperimentStatusDraft {
+		return nil, services.NewDomainError(services.ErrorTypeConflict, fmt.Sprintf("experiment is %s", experiment.Status), nil)
+	}
+
+	experiment.Start()
+	if err := s.experimentRepo.Update(ctx, experiment); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to start experiment", err)
+	}
+	s.invalidateRunning(orgID)
+	return experiment, nil
+}
+
+// StopExperiment stops assigning traffic to an experiment
+func (s *ExperimentService) StopExperiment(ctx context.Context, id, orgID uuid.UUID) (*models.Experiment, error) {
+	experiment, err := s.GetExperiment(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !experiment.IsRunning() {
+		return nil, services.NewDomainError(services.ErrorTypeConflict, fmt.Sprintf("experiment is %s", experiment.Status), nil)
+	}
+
+	experiment.Stop()
+	if err := s.experimentRepo.Update(ctx, experiment); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to stop experiment", err)
+	}
+	s.invalidateRunning(orgID)
+	return experiment, nil
+}
+
+// Assign buckets a request into a running experiment for its app and model.
+// Returns nil if no experiment applies. Requests with a user are bucketed by
+// user so the same user always sees the same arm; anonymous requests fall
+// back to the request ID.
+func (s *ExperimentService) Assign(ctx context.Context, req AssignmentRequest) (*Assignment, error) {
+	experiments, err := s.getRunning(ctx, req.OrgID, req.AppID, req.Model)
+	if err != nil {
+		return nil, err
+	}
+	if len(experiments) == 0 {
+		return nil, nil
+	}
+
+	// App-specific experiments are returned first
+	experiment := experiments[0]
+
+	subjectKey := req.RequestID
+	if req.UserID != nil {
+		subjectKey = req.UserID.String()
+	}
+
+	arm, err := experiment.AssignArm(subjectKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assign arm: %w", err)
+	}
+
+	return &Assignment{
+		ExperimentID: experiment.ID,
+		Arm:          *arm,
+		Bucket:       experiment.Bucket(subjectKey),
+	}, nil
+}
+
+// getRunning returns the running experiments of an app and model, from the
+// cache while fresh
+func (s *ExperimentService) getRunning(ctx context.Context, orgID, appID uuid.UUID, model string) ([]*models.Experiment, error) {
+	key := runningKey{orgID: orgID, appID: appID, model: model}
+
+	s.mu.Lock()
+	entry, ok := s.running[key]
+	s.mu.Unlock()
+	if ok && s.now().Before(entry.expiresAt) {
+		return entry.experiments, nil
+	}
+
+	experiments, err := s.experimentRepo.GetRunning(ctx, orgID, appID, model)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch running experiments: %w", err)
+	}
+
+	s.mu.Lock()
+	s.running[key] = runningEntry{experiments: experiments, expiresAt: s.now().Add(RunningCacheTTL)}
+	s.mu.Unlock()
+	return experiments, nil
+}
+
+// invalidateRunning drops the cached running experiments of an organization.
+// Org-wide experiments apply to every app, so all of the org's entries go.
+func (s *ExperimentService) invalidateRunning(orgID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.running {
+		if key.orgID == orgID {
+			delete(s.running, key)
+		}
+	}
+}
+
+// RecordFeedback records user feedback for an inference request
+func (s *ExperimentService) RecordFeedback(ctx context.Context, inferenceID, orgID uuid.UUID, score int) error {
+	if score < -1 || score > 1 {
+		return services.NewDomainError(services.ErrorTypeValidation, "feedback score must be -1, 0 or 1", nil)
+	}
+
+	req, err := s.inferenceRepo.GetByID(ctx, inferenceID)
+	if err != nil || req.OrgID != orgID {
+		return services.NewDomainError(services.ErrorTypeNotFound, "inference request not found", err)
+	}
+
+	if err := s.inferenceRepo.SetFeedback(ctx, inferenceID, score); err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to record feedback", err)
+	}
+	return nil
+}
+
+// GetReport compares latency, cost, error rate and feedback across arms
+func (s *ExperimentService) GetReport(ctx context.Context, id, orgID uuid.UUID) (*Report, error) {
+	experiment, err := s.GetExperiment(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	arms, err := experiment.GetArms()
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "invalid experiment arms", err)
+	}
+
+	metrics, err := s.inferenceRepo.GetExperimentMetrics(ctx, id)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to get experiment metrics", err)
+	}
+
+	return buildReport(experiment, arms, metrics), nil
+}
+
+// buildReport merges configured arms with their observed metrics
+func buildReport(experiment *models.Experiment, arms []models.ExperimentArm, metrics []*repositories.ArmMetrics) *Report {
+	byArm := make(map[string]*repositories.ArmMetrics, len(metrics))
+	for _, m := range metrics {
+		byArm[m.Arm] = m
+	}
+
+	report := &Report{
+		ExperimentID: experiment.ID,
+		Name:         experiment.Name,
+		Status:       experiment.Status,
+		TargetModel:  experiment.TargetModel,
+		Arms:         make([]ArmReport, 0, len(arms)),
+	}
+
+	for _, arm := range arms {
+		armReport := ArmReport{
+			ArmMetrics: repositories.ArmMetrics{Arm: arm.Name},
+			Model:      arm.Model,
+			Provider:   arm.Provider,
+			Weight:     arm.Weight,
+		}
+
+		if m, ok := byArm[arm.Name]; ok {
+			armReport.ArmMetrics = *m
+			if m.TotalRequests > 0 {
+				armReport.ErrorRate = float64(m.FailedRequests) / float64(m.TotalRequests)
+				armReport.AvgCostPerRequest = m.TotalCost / float64(m.TotalRequests)
+			}
+			if m.FeedbackCount > 0 {
+				armReport.PositiveFeedbackRate = float64(m.PositiveFeedback) / float64(m.FeedbackCount)
+			}
+		}
+
+		report.Arms = append(report.Arms, armReport)
+	}
+
+	return report
+}
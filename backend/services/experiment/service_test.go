@@ -0,0 +1,378 @@
+package experiment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"go.uber.org/zap"
+)
+
+// MockExperimentRepository is a mock implementation of ExperimentRepository
+type MockExperimentRepository struct {
+	mock.Mock
+}
+
+func (m *MockExperimentRepository) Create(ctx context.Context, experiment *models.Experiment) error {
+	args := m.Called(ctx, experiment)
+	return args.Error(0)
+}
+
+func (m *MockExperimentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Experiment, error) {
+	args := m.Called(ctx, id)
+	if exp := args.Get(0); exp != nil {
+		return exp.(*models.Experiment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockExperimentRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID) ([]*models.Experiment, error) {
+	args := m.Called(ctx, orgID)
+	if exps := args.Get(0); exps != nil {
+		return exps.([]*models.Experiment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockExperimentRepository) GetRunning(ctx context.Context, orgID, appID uuid.UUID, model string) ([]*models.Experiment, error) {
+	args := m.Called(ctx, orgID, appID, model)
+	if exps := args.Get(0); exps != nil {
+		return exps.([]*models.Experiment), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockExperimentRepository) Update(ctx context.Context, experiment *models.Experiment) error {
+	args := m.Called(ctx, experiment)
+	return args.Error(0)
+}
+
+func (m *MockExperimentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockExperimentRepository) WithTx(tx repositories.Transaction) repositories.ExperimentRepository {
+	return m
+}
+
+// MockInferenceRequestRepository is a mock implementation of InferenceRequestRepository
+type MockInferenceRequestRepository struct {
+	mock.Mock
+}
+
+func (m *MockInferenceRequestRepository) Create(ctx context.Context, req *models.InferenceRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockInferenceRequestRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.InferenceRequest, error) {
+	args := m.Called(ctx, id)
+	if req := args.Get(0); req != nil {
+		return req.(*models.InferenceRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInferenceRequestRepository) GetByRequestID(ctx context.Context, requestID string) (*models.InferenceRequest, error) {
+	args := m.Called(ctx, requestID)
+	if req := args.Get(0); req != nil {
+		return req.(*models.InferenceRequest), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInferenceRequestRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID, limit, offset int) ([]*models.InferenceRequest, error) {
+	args := m.Called(ctx, orgID, limit, offset)
+	return nil, args.Error(1)
+}
+
+func (m *MockInferenceRequestRepository) GetByAppID(ctx context.Context, appID uuid.UUID, limit, offset int) ([]*models.InferenceRequest, error) {
+	args := m.Called(ctx, appID, limit, offset)
+	return nil, args.Error(1)
+}
+
+func (m *MockInferenceRequestRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.InferenceRequest, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	return nil, args.Error(1)
+}
+
+func (m *MockInferenceRequestRepository) GetByStatus(ctx context.Context, orgID uuid.UUID, status models.InferenceStatus, limit, offset int) ([]*models.InferenceRequest, error) {
+	args := m.Called(ctx, orgID, status, limit, offset)
+	return nil, args.Error(1)
+}
+
+func (m *MockInferenceRequestRepository) GetByDateRange(ctx context.Context, orgID uuid.UUID, start, end time.Time, limit, offset int) ([]*models.InferenceRequest, error) {
+	args := m.Called(ctx, orgID, start, end, limit, offset)
+	return nil, args.Error(1)
+}
+
+func (m *MockInferenceRequestRepository) Update(ctx context.Context, req *models.InferenceRequest) error {
+	args := m.Called(ctx, req)
+	return args.Error(0)
+}
+
+func (m *MockInferenceRequestRepository) GetMetrics(ctx context.Context, orgID uuid.UUID, start, end time.Time) (*repositories.InferenceMetrics, error) {
+	args := m.Called(ctx, orgID, start, end)
+	if metrics := args.Get(0); metrics != nil {
+		return metrics.(*repositories.InferenceMetrics), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInferenceRequestRepository) GetExperimentMetrics(ctx context.Context, experimentID uuid.UUID) ([]*repositories.ArmMetrics, error) {
+	args := m.Called(ctx, experimentID)
+	if metrics := args.Get(0); metrics != nil {
+		return metrics.([]*repositories.ArmMetrics), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockInferenceRequestRepository) SetFeedback(ctx context.Context, id uuid.UUID, score int) error {
+	args := m.Called(ctx, id, score)
+	return args.Error(0)
+}
+
+func (m *MockInferenceRequestRepository) FailUnfinished(ctx context.Context, before time.Time, errorCode, message string) (int64, error) {
+	args := m.Called(ctx, before, errorCode, message)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockInferenceRequestRepository) WithTx(tx repositories.Transaction) repositories.InferenceRequestRepository {
+	return m
+}
+
+func newTestExperiment(t *testing.T, orgID uuid.UUID) *models.Experiment {
+	exp, err := models.NewExperiment(orgID, "cheaper model", "gpt-4", []models.ExperimentArm{
+		{Name: "control", Model: "gpt-4", Weight: 90},
+		{Name: "cheap", Model: "gpt-4o-mini", Provider: "openai", Weight: 10},
+	})
+	require.NoError(t, err)
+	return exp
+}
+
+func TestCreateExperiment(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+
+	t.Run("valid experiment", func(t *testing.T) {
+		repo := new(MockExperimentRepository)
+		service := NewExperimentService(repo, new(MockInferenceRequestRepository), zap.NewNop())
+
+		repo.On("Create", ctx, mock.AnythingOfType("*models.Experiment")).Return(nil)
+
+		exp, err := service.CreateExperiment(ctx, CreateExperimentRequest{
+			OrgID:       orgID,
+			Name:        "split",
+			TargetModel: "gpt-4",
+			Arms: []models.ExperimentArm{
+				{Name: "a", Model: "gpt-4", Weight: 50},
+				{Name: "b", Model: "gpt-4o", Weight: 50},
+			},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, models.ExperimentStatusDraft, exp.Status)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid weights", func(t *testing.T) {
+		repo := new(MockExperimentRepository)
+		service := NewExperimentService(repo, new(MockInferenceRequestRepository), zap.NewNop())
+
+		_, err := service.CreateExperiment(ctx, CreateExperimentRequest{
+			OrgID:       orgID,
+			Name:        "split",
+			TargetModel: "gpt-4",
+			Arms: []models.ExperimentArm{
+				{Name: "a", Model: "gpt-4", Weight: 50},
+				{Name: "b", Model: "gpt-4o", Weight: 10},
+			},
+		})
+
+		assert.True(t, services.IsValidationError(err))
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetExperiment_TenantIsolation(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockExperimentRepository)
+	service := NewExperimentService(repo, new(MockInferenceRequestRepository), zap.NewNop())
+
+	exp := newTestExperiment(t, uuid.New())
+	repo.On("GetByID", ctx, exp.ID).Return(exp, nil)
+
+	_, err := service.GetExperiment(ctx, exp.ID, uuid.New())
+	assert.True(t, services.IsNotFoundError(err))
+}
+
+func TestStartStopExperiment(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	repo := new(MockExperimentRepository)
+	service := NewExperimentService(repo, new(MockInferenceRequestRepository), zap.NewNop())
+
+	exp := newTestExperiment(t, orgID)
+	repo.On("GetByID", ctx, exp.ID).Return(exp, nil)
+	repo.On("Update", ctx, exp).Return(nil)
+
+	_, err := service.StopExperiment(ctx, exp.ID, orgID)
+	assert.True(t, services.IsConflictError(err))
+
+	started, err := service.StartExperiment(ctx, exp.ID, orgID)
+	require.NoError(t, err)
+	assert.True(t, started.IsRunning())
+
+	_, err = service.StartExperiment(ctx, exp.ID, orgID)
+	assert.True(t, services.IsConflictError(err))
+
+	stopped, err := service.StopExperiment(ctx, exp.ID, orgID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExperimentStatusStopped, stopped.Status)
+}
+
+func TestAssign(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	appID := uuid.New()
+
+	t.Run("no running experiment", func(t *testing.T) {
+		repo := new(MockExperimentRepository)
+		service := NewExperimentService(repo, new(MockInferenceRequestRepository), zap.NewNop())
+		repo.On("GetRunning", ctx, orgID, appID, "gpt-4").Return([]*models.Experiment{}, nil)
+
+		assignment, err := service.Assign(ctx, AssignmentRequest{OrgID: orgID, AppID: appID, Model: "gpt-4", RequestID: "r1"})
+		require.NoError(t, err)
+		assert.Nil(t, assignment)
+	})
+
+	t.Run("sticky per user", func(t *testing.T) {
+		repo := new(MockExperimentRepository)
+		service := NewExperimentService(repo, new(MockInferenceRequestRepository), zap.NewNop())
+		exp := newTestExperiment(t, orgID)
+		exp.Start()
+		repo.On("GetRunning", ctx, orgID, appID, "gpt-4").Return([]*models.Experiment{exp}, nil)
+
+		userID := uuid.New()
+		first, err := service.Assign(ctx, AssignmentRequest{OrgID: orgID, AppID: appID, UserID: &userID, Model: "gpt-4", RequestID: "r1"})
+		require.NoError(t, err)
+		require.NotNil(t, first)
+		assert.Equal(t, exp.ID, first.ExperimentID)
+
+		second, err := service.Assign(ctx, AssignmentRequest{OrgID: orgID, AppID: appID, UserID: &userID, Model: "gpt-4", RequestID: "r2"})
+		require.NoError(t, err)
+		assert.Equal(t, first.Arm.Name, second.Arm.Name)
+		assert.Equal(t, first.Bucket, second.Bucket)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		repo := new(MockExperimentRepository)
+		service := NewExperimentService(repo, new(MockInferenceRequestRepository), zap.NewNop())
+		repo.On("GetRunning", ctx, orgID, appID, "gpt-4").Return(nil, errors.New("db down"))
+
+		_, err := service.Assign(ctx, AssignmentRequest{OrgID: orgID, AppID: appID, Model: "gpt-4"})
+		assert.Error(t, err)
+	})
+}
+
+func TestAssign_CachesRunningExperiments(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	appID := uuid.New()
+	repo := new(MockExperimentRepository)
+	service := NewExperimentService(repo, new(MockInferenceRequestRepository), zap.NewNop())
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	exp := newTestExperiment(t, orgID)
+	repo.On("GetByID", ctx, exp.ID).Return(exp, nil)
+	repo.On("Update", ctx, exp).Return(nil)
+	repo.On("GetRunning", ctx, orgID, appID, "gpt-4").Return([]*models.Experiment{}, nil).Once()
+	req := AssignmentRequest{OrgID: orgID, AppID: appID, Model: "gpt-4", RequestID: "r1"}
+
+	for i := 0; i < 3; i++ {
+		assignment, err := service.Assign(ctx, req)
+		require.NoError(t, err)
+		assert.Nil(t, assignment)
+	}
+	repo.AssertNumberOfCalls(t, "GetRunning", 1)
+
+	// Starting the experiment invalidates the cached empty result
+	_, err := service.StartExperiment(ctx, exp.ID, orgID)
+	require.NoError(t, err)
+	repo.On("GetRunning", ctx, orgID, appID, "gpt-4").Return([]*models.Experiment{exp}, nil).Once()
+	assignment, err := service.Assign(ctx, req)
+	require.NoError(t, err)
+	require.NotNil(t, assignment)
+	assert.Equal(t, exp.ID, assignment.ExperimentID)
+	repo.AssertNumberOfCalls(t, "GetRunning", 2)
+
+	// Cached experiments expire after the TTL
+	now = now.Add(RunningCacheTTL)
+	repo.On("GetRunning", ctx, orgID, appID, "gpt-4").Return([]*models.Experiment{exp}, nil).Once()
+	_, err = service.Assign(ctx, req)
+	require.NoError(t, err)
+	repo.AssertNumberOfCalls(t, "GetRunning", 3)
+}
+
+func TestRecordFeedback(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	inferenceRepo := new(MockInferenceRequestRepository)
+	service := NewExperimentService(new(MockExperimentRepository), inferenceRepo, zap.NewNop())
+
+	req := models.NewInferenceRequest(orgID, uuid.New(), "openai", "gpt-4", "hi")
+	inferenceRepo.On("GetByID", ctx, req.ID).Return(req, nil)
+	inferenceRepo.On("SetFeedback", ctx, req.ID, 1).Return(nil)
+
+	assert.NoError(t, service.RecordFeedback(ctx, req.ID, orgID, 1))
+	assert.True(t, services.IsValidationError(service.RecordFeedback(ctx, req.ID, orgID, 5)))
+	assert.True(t, services.IsNotFoundError(service.RecordFeedback(ctx, req.ID, uuid.New(), 1)))
+}
+
+func TestGetReport(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	repo := new(MockExperimentRepository)
+	inferenceRepo := new(MockInferenceRequestRepository)
+	service := NewExperimentService(repo, inferenceRepo, zap.NewNop())
+
+	exp := newTestExperiment(t, orgID)
+	repo.On("GetByID", ctx, exp.ID).Return(exp, nil)
+	inferenceRepo.On("GetExperimentMetrics", ctx, exp.ID).Return([]*repositories.ArmMetrics{
+		{
+			Arm:              "control",
+			TotalRequests:    100,
+			FailedRequests:   5,
+			TotalCost:        2.0,
+			AvgLatencyMs:     800,
+			FeedbackCount:    10,
+			PositiveFeedback: 8,
+		},
+	}, nil)
+
+	report, err := service.GetReport(ctx, exp.ID, orgID)
+	require.NoError(t, err)
+	require.Len(t, report.Arms, 2)
+
+	control := report.Arms[0]
+	assert.Equal(t, "control", control.Arm)
+	assert.InDelta(t, 0.05, control.ErrorRate, 0.0001)
+	assert.InDelta(t, 0.02, control.AvgCostPerRequest, 0.0001)
+	assert.InDelta(t, 0.8, control.PositiveFeedbackRate, 0.0001)
+
+	// Arms without traffic are still reported with zero metrics
+	cheap := report.Arms[1]
+	assert.Equal(t, "cheap", cheap.Arm)
+	assert.Equal(t, "gpt-4o-mini", cheap.Model)
+	assert.Equal(t, 0, cheap.TotalRequests)
+}
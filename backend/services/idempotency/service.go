@@ -0,0 +1,189 @@
+package idempotency
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/services"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultTTL is how long a completed outcome is replayed
+	DefaultTTL = 24 * time.Hour
+
+	// DefaultMaxEntries bounds the number of stored outcomes
+	DefaultMaxEntries = 100000
+
+	// MaxKeyLength bounds client supplied keys
+	MaxKeyLength = 255
+)
+
+// Config holds configuration for the IdempotencyService
+type Config struct {
+	TTL        time.Duration // How long completed outcomes are kept
+	MaxEntries int           // Stored outcomes; the oldest are dropped first
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() Config {
+	return Config{
+		TTL:        DefaultTTL,
+		MaxEntries: DefaultMaxEntries,
+	}
+}
+
+// Response is the stored outcome of a request
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// entry tracks one idempotency key. done is closed once the first request
+// finishes; response is nil if its outcome was released instead of stored.
+type entry struct {
+	key         string
+	fingerprint string
+	done        chan struct{}
+	response    *Response
+	expiresAt   time.Time
+	element     *list.Element
+}
+
+// Claim is held by the request that runs for a key. Exactly one of Complete
+// or Release must be called.
+type Claim struct {
+	service *IdempotencyService
+	entry   *entry
+}
+
+// IdempotencyService remembers the outcome of requests by application and
+// Idempotency-Key so that retries are answered without running them again.
+// Duplicates that arrive while the first request runs wait for its outcome.
+type IdempotencyService struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	order   *list.List // Entries in claim order, for eviction
+	config  Config
+	logger  *zap.Logger
+	now     func() time.Time
+}
+
+// NewIdempotencyService creates a new IdempotencyService instance
+func NewIdempotencyService(logger *zap.Logger, config Config) *IdempotencyService {
+	if config.TTL <= 0 {
+		config.TTL = DefaultTTL
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultMaxEntries
+	}
+	return &IdempotencyService{
+		entries: make(map[string]*entry),
+		order:   list.New(),
+		config:  config,
+		logger:  logger,
+		now:     time.Now,
+	}
+}
+
+// Begin starts a request under an idempotency key. It returns a Claim when the
+// caller should run the request, or the stored Response of an earlier request
+// with the same key. A key reused with a different request is a conflict.
+func (s *IdempotencyService) Begin(ctx context.Context, orgID, appID uuid.UUID, key, fingerprint string) (*Claim, *Response, error) {
+	if key == "" || len(key) > MaxKeyLength {
+		return nil, nil, services.NewDomainError(services.ErrorTypeValidation, "idempotency key must be between 1 and 255 characters", nil)
+	}
+	scoped := orgID.String() + ":" + appID.String() + ":" + key
+
+	for {
+		s.mu.Lock()
+		e, ok := s.entries[scoped]
+		if ok && e.response != nil && !s.now().Before(e.expiresAt) {
+			s.remove(e)
+			ok = false
+		}
+
+		if !ok {
+			e = &entry{key: scoped, fingerprint: fingerprint, done: make(chan struct{})}
+			e.element = s.order.PushBack(e)
+			s.entries[scoped] = e
+			s.evict()
+			s.mu.Unlock()
+			return &Claim{service: s, entry: e}, nil, nil
+		}
+		s.mu.Unlock()
+
+		if e.fingerprint != fingerprint {
+			return nil, nil, services.NewDomainError(services.ErrorTypeConflict, "idempotency key was already used for a different request", nil)
+		}
+
+		// Join the in-flight request
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+
+		if e.response != nil {
+			return nil, e.response, nil
+		}
+		// The first request released the key; try to claim it
+	}
+}
+
+// Complete stores the outcome so later requests with the key replay it
+func (c *Claim) Complete(response *Response) {
+	s := c.service
+	s.mu.Lock()
+	c.entry.response = response
+	c.entry.expiresAt = s.now().Add(s.config.TTL)
+	s.mu.Unlock()
+
+	close(c.entry.done)
+}
+
+// Release forgets the key without storing an outcome, so that the request can
+// be retried. Waiting duplicates compete to run it again.
+func (c *Claim) Release() {
+	s := c.service
+	s.mu.Lock()
+	s.remove(c.entry)
+	s.mu.Unlock()
+
+	close(c.entry.done)
+}
+
+// Len returns the number of tracked keys
+func (s *IdempotencyService) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.entries)
+}
+
+// evict drops expired entries and, above the size bound, the oldest
+// completed ones. In-flight entries are kept (must be called with lock held).
+func (s *IdempotencyService) evict() {
+	now := s.now()
+	for el := s.order.Front(); el != nil; {
+		next := el.Next()
+		if e := el.Value.(*entry); e.response != nil {
+			if len(s.entries) <= s.config.MaxEntries && now.Before(e.expiresAt) {
+				return
+			}
+			s.remove(e)
+		}
+		el = next
+	}
+}
+
+// remove forgets an entry (must be called with lock held)
+func (s *IdempotencyService) remove(e *entry) {
+	s.order.Remove(e.element)
+	delete(s.entries, e.key)
+}
@@ -0,0 +1,148 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/services"
+	"go.uber.org/zap"
+)
+
+func TestIdempotencyService_Replay(t *testing.T) {
+	service := NewIdempotencyService(zap.NewNop(), DefaultConfig())
+	ctx := context.Background()
+	orgID, appID := uuid.New(), uuid.New()
+
+	claim, stored, err := service.Begin(ctx, orgID, appID, "key-1", "fp")
+	require.NoError(t, err)
+	require.NotNil(t, claim)
+	assert.Nil(t, stored)
+
+	claim.Complete(&Response{StatusCode: http.StatusOK, Body: []byte(`{"id":"1"}`)})
+
+	claim, stored, err = service.Begin(ctx, orgID, appID, "key-1", "fp")
+	require.NoError(t, err)
+	assert.Nil(t, claim, "a completed key is not run again")
+	require.NotNil(t, stored)
+	assert.Equal(t, `{"id":"1"}`, string(stored.Body))
+
+	_, _, err = service.Begin(ctx, orgID, appID, "key-1", "other")
+	assert.True(t, services.IsConflictError(err), "a key cannot be reused for a different request")
+
+	claim, _, err = service.Begin(ctx, orgID, uuid.New(), "key-1", "fp")
+	require.NoError(t, err)
+	assert.NotNil(t, claim, "keys are scoped to the application")
+	claim.Release()
+
+	_, _, err = service.Begin(ctx, orgID, appID, "", "fp")
+	assert.True(t, services.IsValidationError(err))
+}
+
+func TestIdempotencyService_Expiry(t *testing.T) {
+	service := NewIdempotencyService(zap.NewNop(), Config{TTL: time.Minute})
+	now := time.Now()
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+	orgID, appID := uuid.New(), uuid.New()
+
+	claim, _, err := service.Begin(ctx, orgID, appID, "key", "fp")
+	require.NoError(t, err)
+	claim.Complete(&Response{StatusCode: http.StatusOK})
+
+	now = now.Add(2 * time.Minute)
+	claim, stored, err := service.Begin(ctx, orgID, appID, "key", "other")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+	assert.NotNil(t, claim, "an expired key can be used again")
+}
+
+func TestIdempotencyService_ConcurrentDuplicates(t *testing.T) {
+	service := NewIdempotencyService(zap.NewNop(), DefaultConfig())
+	ctx := context.Background()
+	orgID, appID := uuid.New(), uuid.New()
+
+	claim, _, err := service.Begin(ctx, orgID, appID, "key", "fp")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	replays := make(chan *Response, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, stored, err := service.Begin(ctx, orgID, appID, "key", "fp")
+			assert.NoError(t, err)
+			assert.Nil(t, c)
+			replays <- stored
+		}()
+	}
+
+	claim.Complete(&Response{StatusCode: http.StatusCreated})
+	wg.Wait()
+	close(replays)
+
+	for stored := range replays {
+		require.NotNil(t, stored)
+		assert.Equal(t, http.StatusCreated, stored.StatusCode)
+	}
+}
+
+func TestIdempotencyService_Release(t *testing.T) {
+	service := NewIdempotencyService(zap.NewNop(), DefaultConfig())
+	ctx := context.Background()
+	orgID, appID := uuid.New(), uuid.New()
+
+	claim, _, err := service.Begin(ctx, orgID, appID, "key", "fp")
+	require.NoError(t, err)
+
+	retried := make(chan *Claim)
+	go func() {
+		c, _, _ := service.Begin(ctx, orgID, appID, "key", "fp")
+		retried <- c
+	}()
+
+	claim.Release()
+	retry := <-retried
+	require.NotNil(t, retry, "a waiting duplicate runs the request after a release")
+	retry.Release()
+	assert.Equal(t, 0, service.Len())
+
+	// Waiting stops with the caller's context
+	claim, _, err = service.Begin(ctx, orgID, appID, "key", "fp")
+	require.NoError(t, err)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, _, err = service.Begin(cancelled, orgID, appID, "key", "fp")
+	assert.ErrorIs(t, err, context.Canceled)
+	claim.Release()
+}
+
+func TestIdempotencyService_Eviction(t *testing.T) {
+	service := NewIdempotencyService(zap.NewNop(), Config{TTL: time.Hour, MaxEntries: 2})
+	ctx := context.Background()
+	orgID, appID := uuid.New(), uuid.New()
+
+	inFlight, _, err := service.Begin(ctx, orgID, appID, "in-flight", "fp")
+	require.NoError(t, err)
+	for _, key := range []string{"a", "b", "c"} {
+		claim, _, err := service.Begin(ctx, orgID, appID, key, "fp")
+		require.NoError(t, err)
+		claim.Complete(&Response{StatusCode: http.StatusOK})
+	}
+
+	assert.Equal(t, 2, service.Len())
+	_, stored, err := service.Begin(ctx, orgID, appID, "c", "fp")
+	require.NoError(t, err)
+	assert.NotNil(t, stored, "the newest outcome is kept")
+
+	// The in-flight request keeps its claim
+	_, _, err = service.Begin(ctx, orgID, appID, "in-flight", "other")
+	assert.True(t, services.IsConflictError(err))
+	inFlight.Release()
+}
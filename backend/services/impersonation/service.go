@@ -0,0 +1,197 @@
+package impersonation
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/audit"
+	"github.com/upb/llm-control-plane/backend/tokens"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultDuration is how long impersonation tokens are valid when no
+	// duration is requested
+	DefaultDuration = 15 * time.Minute
+
+	// MaxDuration bounds how long impersonation tokens are valid
+	MaxDuration = time.Hour
+
+	// MaxReasonLength bounds the reason recorded for an impersonation
+	MaxReasonLength = 500
+)
+
+// TokenIssuer signs impersonation tokens
+type TokenIssuer interface {
+	IssueFor(claims *tokens.Claims, ttl time.Duration) (string, time.Time, error)
+}
+
+// PermissionChecker resolves the permissions of roles within an organization
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, orgID uuid.UUID, roles []string, permission models.Permission) (bool, error)
+}
+
+// Impersonator is the admin asking to act as a user: the identity and, for
+// scoped tokens, the scopes of the admin's claims
+type Impersonator struct {
+	UserID  *uuid.UUID
+	Subject string
+	Roles   []string
+	Scopes  []string
+}
+
+// StartRequest represents a request to impersonate a user of an organization
+type StartRequest struct {
+	OrgID        uuid.UUID
+	UserID       uuid.UUID
+	Reason       string
+	Duration     time.Duration // Optional; DefaultDuration when zero
+	Impersonator Impersonator
+}
+
+// Token is an impersonation token, in the shape of an OAuth2 token response
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresIn   int       `json:"expires_in"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	UserID      uuid.UUID `json:"user_id"`
+}
+
+// ImpersonationService issues admins time-boxed tokens acting as users of
+// their organization, to reproduce the users' issues. Tokens carry the admin
+// as their actor and are not checked again once issued, so they stay valid
+// until they expire.
+type ImpersonationService struct {
+	users        repositories.UserRepository
+	issuer       TokenIssuer
+	permissions  PermissionChecker
+	auditService *audit.AuditService
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewImpersonationService creates a new ImpersonationService instance
+func NewImpersonationService(users repositories.UserRepository, issuer TokenIssuer, permissions PermissionChecker, logger *zap.Logger) *ImpersonationService {
+	return &ImpersonationService{
+		users:       users,
+		issuer:      issuer,
+		permissions: permissions,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// SetAuditService records impersonations in the audit log
+func (s *ImpersonationService) SetAuditService(auditService *audit.AuditService) {
+	s.auditService = auditService
+}
+
+// Start issues a token acting as a user. The admin must be a user, may not
+// impersonate themselves or deactivated users, and must hold every
+// permission the user has, so impersonation cannot escalate privileges.
+func (s *ImpersonationService) Start(ctx context.Context, req StartRequest) (*Token, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "reason is required", nil)
+	}
+	if len(reason) > MaxReasonLength {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "reason is too long", nil)
+	}
+
+	duration := req.Duration
+	if duration == 0 {
+		duration = DefaultDuration
+	}
+	if duration < 0 || duration > MaxDuration {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "duration must be at most "+MaxDuration.String(), nil)
+	}
+
+	impersonator := req.Impersonator
+	if impersonator.UserID == nil {
+		return nil, services.NewDomainError(services.ErrorTypeForbidden, "only users can impersonate", nil)
+	}
+
+	user, err := s.users.GetByOrgAndID(ctx, req.OrgID, req.UserID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "user not found", err)
+	}
+	if user.ID == *impersonator.UserID {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "cannot impersonate yourself", nil)
+	}
+	if !user.IsActive() {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "cannot impersonate a deactivated user", nil)
+	}
+	if err := s.checkPrivileges(ctx, user, impersonator); err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := s.issuer.IssueFor(&tokens.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: user.ID.String()},
+		OrgID:            user.OrgID.String(),
+		Roles:            []string{string(user.Role)},
+		Actor: &tokens.Actor{
+			Subject: impersonator.Subject,
+			UserID:  impersonator.UserID.String(),
+		},
+	}, duration)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to issue token", err)
+	}
+
+	s.logger.Info("impersonation started",
+		zap.String("user_id", user.ID.String()),
+		zap.String("impersonator_id", impersonator.UserID.String()),
+		zap.String("org_id", user.OrgID.String()),
+		zap.Time("expires_at", expiresAt))
+	if s.auditService != nil {
+		if err := s.auditService.LogImpersonationStarted(user, impersonator.UserID, impersonator.Subject, reason, expiresAt); err != nil {
+			s.logger.Error("failed to log impersonation", zap.Error(err))
+		}
+	}
+
+	return &Token{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(expiresAt.Sub(s.now()).Round(time.Second).Seconds()),
+		ExpiresAt:   expiresAt,
+		UserID:      user.ID,
+	}, nil
+}
+
+// checkPrivileges ensures the impersonator holds every permission of the user
+func (s *ImpersonationService) checkPrivileges(ctx context.Context, user *models.User, impersonator Impersonator) error {
+	roles := []string{string(user.Role)}
+	for _, permission := range models.Permissions {
+		granted, err := s.permissions.HasPermission(ctx, user.OrgID, roles, permission)
+		if err != nil {
+			return services.NewDomainError(services.ErrorTypeInternal, "failed to check permissions", err)
+		}
+		if !granted {
+			continue
+		}
+		held, err := s.permissions.HasPermission(ctx, user.OrgID, impersonator.Roles, permission)
+		if err != nil {
+			return services.NewDomainError(services.ErrorTypeInternal, "failed to check permissions", err)
+		}
+		if !held || (len(impersonator.Scopes) > 0 && !scopesGrant(impersonator.Scopes, permission)) {
+			return services.NewDomainError(services.ErrorTypeForbidden, "cannot impersonate a user with a permission you do not hold: "+string(permission), nil)
+		}
+	}
+	return nil
+}
+
+// scopesGrant reports whether a token's scopes include permission
+func scopesGrant(scopes []string, permission models.Permission) bool {
+	permissions := make([]models.Permission, 0, len(scopes))
+	for _, scope := range scopes {
+		permissions = append(permissions, models.Permission(scope))
+	}
+	return models.Grants(permissions, permission)
+}
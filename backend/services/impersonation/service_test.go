@@ -0,0 +1,127 @@
+package impersonation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/config"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/tokens"
+	"go.uber.org/zap"
+)
+
+// fakeUserRepository stores users in memory
+type fakeUserRepository struct {
+	repositories.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *fakeUserRepository) GetByOrgAndID(ctx context.Context, orgID, id uuid.UUID) (*models.User, error) {
+	if user, ok := r.users[id]; ok && user.OrgID == orgID {
+		copied := *user
+		return &copied, nil
+	}
+	return nil, errors.New("user not found")
+}
+
+// builtInPermissions resolves permissions by the built-in roles
+type builtInPermissions struct{}
+
+func (builtInPermissions) HasPermission(ctx context.Context, orgID uuid.UUID, roles []string, permission models.Permission) (bool, error) {
+	return models.BuiltInRolesGrant(roles, permission), nil
+}
+
+func newTestService(t *testing.T, users ...*models.User) (*ImpersonationService, *tokens.Issuer) {
+	issuer, err := tokens.NewIssuer(config.TokensConfig{Issuer: "https://gateway.test"})
+	require.NoError(t, err)
+	repo := &fakeUserRepository{users: make(map[uuid.UUID]*models.User)}
+	for _, user := range users {
+		repo.users[user.ID] = user
+	}
+	return NewImpersonationService(repo, issuer, builtInPermissions{}, zap.NewNop()), issuer
+}
+
+func newUser(orgID uuid.UUID, role models.UserRole) *models.User {
+	return &models.User{ID: uuid.New(), OrgID: orgID, Email: string(role) + "@example.com", Role: role}
+}
+
+func TestStart(t *testing.T) {
+	orgID := uuid.New()
+	adminID := uuid.New()
+	admin := Impersonator{UserID: &adminID, Subject: adminID.String(), Roles: []string{"admin"}}
+
+	t.Run("issues a token acting as the user", func(t *testing.T) {
+		target := newUser(orgID, models.RoleMember)
+		svc, issuer := newTestService(t, target)
+
+		token, err := svc.Start(context.Background(), StartRequest{
+			OrgID: orgID, UserID: target.ID, Reason: "ticket 4711", Duration: 10 * time.Minute, Impersonator: admin,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer", token.TokenType)
+		assert.Equal(t, 600, token.ExpiresIn)
+		assert.Equal(t, target.ID, token.UserID)
+
+		claims, err := issuer.ValidateToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, target.ID.String(), claims.Subject)
+		assert.Equal(t, orgID.String(), claims.OrgID)
+		assert.Equal(t, []string{"member"}, claims.Roles)
+		require.NotNil(t, claims.Actor)
+		assert.Equal(t, adminID.String(), claims.Actor.UserID)
+	})
+
+	t.Run("defaults the duration", func(t *testing.T) {
+		target := newUser(orgID, models.RoleViewer)
+		svc, _ := newTestService(t, target)
+
+		token, err := svc.Start(context.Background(), StartRequest{
+			OrgID: orgID, UserID: target.ID, Reason: "ticket 4711", Impersonator: admin,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int(DefaultDuration.Seconds()), token.ExpiresIn)
+	})
+
+	deactivated := newUser(orgID, models.RoleMember)
+	now := time.Now()
+	deactivated.DeactivatedAt = &now
+	target := newUser(orgID, models.RoleMember)
+	otherOrg := newUser(uuid.New(), models.RoleMember)
+	self := &models.User{ID: adminID, OrgID: orgID, Role: models.RoleAdmin}
+	otherAdmin := newUser(orgID, models.RoleAdmin)
+	memberID := uuid.New()
+
+	tests := []struct {
+		name    string
+		req     StartRequest
+		isError func(error) bool
+	}{
+		{"missing reason", StartRequest{OrgID: orgID, UserID: target.ID, Reason: " ", Impersonator: admin}, services.IsValidationError},
+		{"duration too long", StartRequest{OrgID: orgID, UserID: target.ID, Reason: "r", Duration: 2 * time.Hour, Impersonator: admin}, services.IsValidationError},
+		{"not a user", StartRequest{OrgID: orgID, UserID: target.ID, Reason: "r", Impersonator: Impersonator{Subject: "serviceaccount:1", Roles: []string{"admin"}}}, services.IsForbiddenError},
+		{"user of another organization", StartRequest{OrgID: orgID, UserID: otherOrg.ID, Reason: "r", Impersonator: admin}, services.IsNotFoundError},
+		{"yourself", StartRequest{OrgID: orgID, UserID: self.ID, Reason: "r", Impersonator: admin}, services.IsValidationError},
+		{"deactivated user", StartRequest{OrgID: orgID, UserID: deactivated.ID, Reason: "r", Impersonator: admin}, services.IsValidationError},
+		{"user with more permissions", StartRequest{OrgID: orgID, UserID: otherAdmin.ID, Reason: "r",
+			Impersonator: Impersonator{UserID: &memberID, Subject: memberID.String(), Roles: []string{"member"}}}, services.IsForbiddenError},
+		{"scoped token lacking permissions", StartRequest{OrgID: orgID, UserID: target.ID, Reason: "r",
+			Impersonator: Impersonator{UserID: &adminID, Roles: []string{"admin"}, Scopes: []string{"users:impersonate"}}}, services.IsForbiddenError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _ := newTestService(t, deactivated, target, otherOrg, self, otherAdmin)
+
+			token, err := svc.Start(context.Background(), tt.req)
+
+			assert.Nil(t, token)
+			assert.True(t, tt.isError(err), "unexpected error: %v", err)
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package inference
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/upb/llm-control-plane/backend/services/providers"
+)
+
+// ContextStrategy defines how requests that exceed the model's context window are handled
+type ContextStrategy string
+
+const (
+	// ContextStrategyError rejects oversized requests
+	ContextStrategyError ContextStrategy = "error"
+
+	// ContextStrategyDropOldest drops the oldest turns, keeping system messages
+	ContextStrategyDropOldest ContextStrategy = "drop_oldest"
+
+	// ContextStrategyMiddleOut drops turns from the middle of the conversation,
+	// keeping system messages, the opening turn and the most recent turns
+	ContextStrategyMiddleOut ContextStrategy = "middle_out"
+)
+
+// Response metadata keys describing context window handling
+const (
+	MetadataContextStrategy        = "context_strategy"
+	MetadataContextTruncated       = "context_truncated"
+	MetadataContextDroppedMessages = "context_dropped_messages"
+)
+
+// ContextFitResult describes how a request was fitted to the model's context window
+type ContextFitResult struct {
+	Strategy         ContextStrategy
+	Truncated        bool
+	DroppedMessages  int
+	DroppedRetrieval bool // The injected RAG context was dropped to fit
+	PromptTokens     int
+	ContextWindow    int
+}
+
+// ParseContextStrategy validates a strategy name; empty selects ContextStrategyError
+func ParseContextStrategy(name string) (ContextStrategy, error) {
+	switch ContextStrategy(name) {
+	case "", ContextStrategyError:
+		return ContextStrategyError, nil
+	case ContextStrategyDropOldest, ContextStrategyMiddleOut:
+		return ContextStrategy(name), nil
+	}
+	return "", fmt.Errorf("unknown context strategy %q", name)
+}
+
+// fitContextWindow checks the request against the model's limits and trims the
+// conversation if the strategy allows it. Completion tokens requested through
+// max_tokens are reserved out of the window. Whole turns are dropped so replies,
+// tool calls and tool results are never separated from the message they answer;
+// system messages and the final turn are never dropped.
+//
+// retrieved is the index of the injected RAG context message, or -1. It is
+// dropped under any strategy once no turn is left to drop, since the caller
+// did not send it; the request then proceeds without retrieved context.
+func fitContextWindow(info *providers.ModelInfo, req *providers.ChatRequest, strategy ContextStrategy, retrieved int) (*ContextFitResult, error) {
+	result := &ContextFitResult{Strategy: strategy}
+	if info == nil || info.ContextWindow <= 0 {
+		return result, nil
+	}
+	result.ContextWindow = info.ContextWindow
+
+	if info.MaxTokens > 0 && req.MaxTokens > info.MaxTokens {
+		return nil, NewValidationError(fmt.Sprintf("max_tokens %d exceeds the %d output tokens supported by %s", req.MaxTokens, info.MaxTokens, info.ID), map[string]interface{}{
+			"max_tokens":       req.MaxTokens,
+			"model_max_tokens": info.MaxTokens,
+		})
+	}
+
+	budget := info.ContextWindow - req.MaxTokens
+	counts, overhead := providers.CountMessageTokens(info, req.Model, req.Messages)
+	total := overhead
+	for _, c := range counts {
+		total += c
+	}
+	result.PromptTokens = total
+
+	if total <= budget {
+		return result, nil
+	}
+
+	keep := make([]bool, len(req.Messages))
+	for i := range keep {
+		keep[i] = true
+	}
+
+	turns := conversationTurns(req.Messages, retrieved)
+	dropped := make([]bool, len(turns))
+	for total > budget {
+		victim := -1
+		if strategy != ContextStrategyError {
+			victim = selectVictim(dropped, strategy)
+		}
+		if victim >= 0 {
+			dropped[victim] = true
+			for _, i := range turns[victim] {
+				keep[i] = false
+				total -= counts[i]
+				result.DroppedMessages++
+			}
+			continue
+		}
+
+		if retrieved < 0 || retrieved >= len(req.Messages) || !keep[retrieved] {
+			return nil, contextLengthError(total, budget, info)
+		}
+		keep[retrieved] = false
+		total -= counts[retrieved]
+		result.DroppedMessages++
+		result.DroppedRetrieval = true
+	}
+
+	trimmed := make([]providers.Message, 0, len(req.Messages)-result.DroppedMessages)
+	for i, msg := range req.Messages {
+		if keep[i] {
+			trimmed = append(trimmed, msg)
+		}
+	}
+	req.Messages = trimmed
+
+	result.Truncated = true
+	result.PromptTokens = total
+	return result, nil
+}
+
+// conversationTurns groups the droppable messages into turns, each a user
+// message with the replies, tool calls and tool results that follow it.
+// System messages, the RAG context at index retrieved and the turn holding
+// the final message are left out.
+func conversationTurns(messages []providers.Message, retrieved int) [][]int {
+	var turns [][]int
+	for i, msg := range messages {
+		if msg.Role == "system" || i == retrieved {
+			continue
+		}
+		if msg.Role == "user" || len(turns) == 0 {
+			turns = append(turns, nil)
+		}
+		turns[len(turns)-1] = append(turns[len(turns)-1], i)
+	}
+
+	if n := len(turns); n > 0 {
+		last := turns[n-1]
+		if last[len(last)-1] == len(messages)-1 {
+			turns = turns[:n-1]
+		}
+	}
+	return turns
+}
+
+// selectVictim picks the next turn to drop, or -1 if nothing can be dropped
+func selectVictim(dropped []bool, strategy ContextStrategy) int {
+	var candidates []int
+	for i := range dropped {
+		if !dropped[i] {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return -1
+	}
+
+	if strategy == ContextStrategyMiddleOut {
+		return candidates[len(candidates)/2]
+	}
+	return candidates[0]
+}
+
+func contextLengthError(promptTokens, budget int, info *providers.ModelInfo) *InferenceError {
+	return NewContextLengthError(fmt.Sprintf("request needs %d prompt tokens but %s allows %d", promptTokens, info.ID, budget), map[string]interface{}{
+		"prompt_tokens":  promptTokens,
+		"context_window": info.ContextWindow,
+		"available":      budget,
+	})
+}
+
+// metadata reports the applied strategy for inclusion in the response
+func (r *ContextFitResult) metadata() map[string]string {
+	return map[string]string{
+		MetadataContextStrategy:        string(r.Strategy),
+		MetadataContextTruncated:       strconv.FormatBool(r.Truncated),
+		MetadataContextDroppedMessages: strconv.Itoa(r.DroppedMessages),
+	}
+}
@@ -0,0 +1,262 @@
+package inference
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+)
+
+func contextTestConversation() []providers.Message {
+	turn := strings.Repeat("lorem ipsum dolor sit amet ", 10)
+	return []providers.Message{
+		{Role: "system", Content: "You are a helpful assistant."},
+		{Role: "user", Content: "first " + turn},
+		{Role: "assistant", Content: "second " + turn},
+		{Role: "user", Content: "third " + turn},
+		{Role: "assistant", Content: "fourth " + turn},
+		{Role: "user", Content: "fifth " + turn},
+		{Role: "assistant", Content: "sixth " + turn},
+		{Role: "user", Content: "latest question"},
+	}
+}
+
+// contextWindowFor returns a window that fits the conversation minus n dropped turns of the same size
+func contextWindowFor(t *testing.T, messages []providers.Message, dropped int) int {
+	t.Helper()
+	counts, overhead := providers.CountMessageTokens(nil, "gpt-4", messages)
+	total := overhead
+	for _, c := range counts {
+		total += c
+	}
+	return total - dropped*counts[1]
+}
+
+func contents(messages []providers.Message) []string {
+	out := make([]string, len(messages))
+	for i, msg := range messages {
+		out[i] = strings.Fields(msg.Content)[0]
+	}
+	return out
+}
+
+func TestFitContextWindow_Fits(t *testing.T) {
+	req := &providers.ChatRequest{Model: "gpt-4", Messages: contextTestConversation()}
+	info := &providers.ModelInfo{ID: "gpt-4", ContextWindow: contextWindowFor(t, req.Messages, 0)}
+
+	result, err := fitContextWindow(info, req, ContextStrategyError, -1)
+
+	require.NoError(t, err)
+	assert.False(t, result.Truncated)
+	assert.Equal(t, info.ContextWindow, result.PromptTokens)
+	assert.Len(t, req.Messages, 8)
+}
+
+func TestFitContextWindow_ErrorStrategy(t *testing.T) {
+	req := &providers.ChatRequest{Model: "gpt-4", Messages: contextTestConversation()}
+	info := &providers.ModelInfo{ID: "gpt-4", ContextWindow: contextWindowFor(t, req.Messages, 0) - 1}
+
+	result, err := fitContextWindow(info, req, ContextStrategyError, -1)
+
+	assert.Nil(t, result)
+	require.Error(t, err)
+	inferenceErr, ok := err.(*InferenceError)
+	require.True(t, ok)
+	assert.Equal(t, ErrCodeContextLength, inferenceErr.Code)
+	assert.Equal(t, 400, inferenceErr.StatusCode)
+	assert.Len(t, req.Messages, 8, "request must not be modified")
+}
+
+func TestFitContextWindow_ReservesMaxTokens(t *testing.T) {
+	req := &providers.ChatRequest{Model: "gpt-4", Messages: contextTestConversation(), MaxTokens: 10}
+	info := &providers.ModelInfo{ID: "gpt-4", ContextWindow: contextWindowFor(t, req.Messages, 0)}
+
+	_, err := fitContextWindow(info, req, ContextStrategyError, -1)
+
+	require.Error(t, err)
+	assert.Equal(t, ErrCodeContextLength, err.(*InferenceError).Code)
+}
+
+func TestFitContextWindow_MaxTokensAboveModelLimit(t *testing.T) {
+	req := &providers.ChatRequest{Model: "gpt-4", Messages: contextTestConversation(), MaxTokens: 5000}
+	info := &providers.ModelInfo{ID: "gpt-4", ContextWindow: 100000, MaxTokens: 4096}
+
+	_, err := fitContextWindow(info, req, ContextStrategyDropOldest, -1)
+
+	require.Error(t, err)
+	assert.Equal(t, ErrCodeValidation, err.(*InferenceError).Code)
+}
+
+func TestFitContextWindow_DropOldest(t *testing.T) {
+	req := &providers.ChatRequest{Model: "gpt-4", Messages: contextTestConversation()}
+	info := &providers.ModelInfo{ID: "gpt-4", ContextWindow: contextWindowFor(t, req.Messages, 2)}
+
+	result, err := fitContextWindow(info, req, ContextStrategyDropOldest, -1)
+
+	require.NoError(t, err)
+	assert.True(t, result.Truncated)
+	assert.LessOrEqual(t, result.PromptTokens, info.ContextWindow)
+	assert.Equal(t, 2, result.DroppedMessages)
+	assert.Equal(t, []string{"You", "third", "fourth", "fifth", "sixth", "latest"}, contents(req.Messages))
+}
+
+func TestFitContextWindow_MiddleOut(t *testing.T) {
+	req := &providers.ChatRequest{Model: "gpt-4", Messages: contextTestConversation()}
+	info := &providers.ModelInfo{ID: "gpt-4", ContextWindow: contextWindowFor(t, req.Messages, 2)}
+
+	result, err := fitContextWindow(info, req, ContextStrategyMiddleOut, -1)
+
+	require.NoError(t, err)
+	assert.True(t, result.Truncated)
+	assert.Equal(t, 2, result.DroppedMessages)
+	assert.Equal(t, []string{"You", "first", "second", "fifth", "sixth", "latest"}, contents(req.Messages))
+}
+
+func TestFitContextWindow_DropsWholeTurns(t *testing.T) {
+	turn := strings.Repeat("lorem ipsum dolor sit amet ", 10)
+	toolCall := map[string]interface{}{"name": "lookup", "arguments": `{"q":"x"}`}
+
+	t.Run("tool exchanges are dropped with their turn", func(t *testing.T) {
+		req := &providers.ChatRequest{Model: "gpt-4", Messages: []providers.Message{
+			{Role: "system", Content: "You are a helpful assistant."},
+			{Role: "user", Content: "first " + turn},
+			{Role: "assistant", Content: "second " + turn, FunctionCall: toolCall},
+			{Role: "tool", Content: "third " + turn},
+			{Role: "assistant", Content: "fourth " + turn},
+			{Role: "user", Content: "fifth " + turn},
+			{Role: "assistant", Content: "sixth " + turn},
+			{Role: "user", Content: "latest question"},
+		}}
+		info := &providers.ModelInfo{ID: "gpt-4", ContextWindow: contextWindowFor(t, req.Messages, 1)}
+
+		result, err := fitContextWindow(info, req, ContextStrategyDropOldest, -1)
+
+		require.NoError(t, err)
+		assert.Equal(t, 4, result.DroppedMessages)
+		assert.Equal(t, []string{"You", "fifth", "sixth", "latest"}, contents(req.Messages))
+	})
+
+	t.Run("the final turn is kept whole", func(t *testing.T) {
+		req := &providers.ChatRequest{Model: "gpt-4", Messages: []providers.Message{
+			{Role: "system", Content: "You are a helpful assistant."},
+			{Role: "user", Content: "first " + turn},
+			{Role: "assistant", Content: "second " + turn},
+			{Role: "user", Content: "third " + turn},
+			{Role: "assistant", Content: "fourth " + turn, FunctionCall: toolCall},
+			{Role: "tool", Content: "fifth " + turn},
+		}}
+		info := &providers.ModelInfo{ID: "gpt-4", ContextWindow: contextWindowFor(t, req.Messages, 2)}
+
+		result, err := fitContextWindow(info, req, ContextStrategyMiddleOut, -1)
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, result.DroppedMessages)
+		assert.Equal(t, []string{"You", "third", "fourth", "fifth"}, contents(req.Messages))
+
+		// Only the final turn is left, and it cannot be split
+		info.ContextWindow = contextWindowFor(t, req.Messages, 1)
+		_, err = fitContextWindow(info, req, ContextStrategyMiddleOut, -1)
+		require.Error(t, err)
+		assert.Equal(t, ErrCodeContextLength, err.(*InferenceError).Code)
+	})
+}
+
+func TestFitContextWindow_RetrievedContext(t *testing.T) {
+	retrieval := providers.Message{Role: "system", Content: "context " + strings.Repeat("lorem ipsum dolor sit amet ", 10)}
+	withRetrieval := func() []providers.Message {
+		messages := contextTestConversation()
+		return append(messages[:1], append([]providers.Message{retrieval}, messages[1:]...)...)
+	}
+
+	t.Run("dropped under the error strategy", func(t *testing.T) {
+		req := &providers.ChatRequest{Model: "gpt-4", Messages: withRetrieval()}
+		info := &providers.ModelInfo{ID: "gpt-4", ContextWindow: contextWindowFor(t, req.Messages, 1)}
+
+		result, err := fitContextWindow(info, req, ContextStrategyError, 1)
+
+		require.NoError(t, err)
+		assert.True(t, result.DroppedRetrieval)
+		assert.Equal(t, 1, result.DroppedMessages)
+		assert.Equal(t, contents(contextTestConversation()), contents(req.Messages))
+	})
+
+	t.Run("kept while turns can be dropped", func(t *testing.T) {
+		req := &providers.ChatRequest{Model: "gpt-4", Messages: withRetrieval()}
+		info := &providers.ModelInfo{ID: "gpt-4", ContextWindow: contextWindowFor(t, req.Messages, 1)}
+
+		result, err := fitContextWindow(info, req, ContextStrategyDropOldest, 1)
+
+		require.NoError(t, err)
+		assert.False(t, result.DroppedRetrieval)
+		assert.Equal(t, []string{"You", "context", "third", "fourth", "fifth", "sixth", "latest"}, contents(req.Messages))
+	})
+
+	t.Run("dropped once no turn is left", func(t *testing.T) {
+		req := &providers.ChatRequest{Model: "gpt-4", Messages: withRetrieval()}
+		info := &providers.ModelInfo{ID: "gpt-4", ContextWindow: contextWindowFor(t, req.Messages, 7)}
+
+		result, err := fitContextWindow(info, req, ContextStrategyDropOldest, 1)
+
+		require.NoError(t, err)
+		assert.True(t, result.DroppedRetrieval)
+		assert.Equal(t, []string{"You", "latest"}, contents(req.Messages))
+	})
+}
+
+func TestFitContextWindow_CannotFit(t *testing.T) {
+	req := &providers.ChatRequest{Model: "gpt-4", Messages: contextTestConversation()}
+	info := &providers.ModelInfo{ID: "gpt-4", ContextWindow: 20}
+
+	_, err := fitContextWindow(info, req, ContextStrategyDropOldest, -1)
+
+	require.Error(t, err)
+	assert.Equal(t, ErrCodeContextLength, err.(*InferenceError).Code)
+}
+
+func TestFitContextWindow_UnknownModelLimits(t *testing.T) {
+	req := &providers.ChatRequest{Model: "gpt-4", Messages: contextTestConversation()}
+
+	result, err := fitContextWindow(nil, req, ContextStrategyError, -1)
+
+	require.NoError(t, err)
+	assert.False(t, result.Truncated)
+	assert.Zero(t, result.ContextWindow)
+}
+
+func TestParseContextStrategy(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ContextStrategy
+		wantErr  bool
+	}{
+		{input: "", expected: ContextStrategyError},
+		{input: "error", expected: ContextStrategyError},
+		{input: "drop_oldest", expected: ContextStrategyDropOldest},
+		{input: "middle_out", expected: ContextStrategyMiddleOut},
+		{input: "truncate_everything", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseContextStrategy(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestContextFitResult_Metadata(t *testing.T) {
+	result := &ContextFitResult{Strategy: ContextStrategyMiddleOut, Truncated: true, DroppedMessages: 3}
+
+	assert.Equal(t, map[string]string{
+		MetadataContextStrategy:        "middle_out",
+		MetadataContextTruncated:       "true",
+		MetadataContextDroppedMessages: "3",
+	}, result.metadata())
+}
@@ -0,0 +1,110 @@
+package inference
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/internal/rag"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/policy"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+// fakeRetriever returns fixed documents and records the last call
+type fakeRetriever struct {
+	docs  []rag.Document
+	err   error
+	query string
+	opts  rag.RetrievalOptions
+}
+
+func (r *fakeRetriever) Retrieve(ctx context.Context, query string, opts rag.RetrievalOptions) ([]rag.Document, error) {
+	r.query = query
+	r.opts = opts
+	return r.docs, r.err
+}
+
+func newRAGRequest() *CompletionRequest {
+	return &CompletionRequest{
+		OrgID: uuid.New(),
+		AppID: uuid.New(),
+		Model: "gpt-4",
+		Messages: []providers.Message{
+			{Role: "system", Content: "You are a support agent."},
+			{Role: "user", Content: "How do I reset my password?"},
+			{Role: "assistant", Content: "Which product?"},
+			{Role: "user", Content: "The mobile app"},
+		},
+	}
+}
+
+func TestRetrieveContext(t *testing.T) {
+	retriever := &fakeRetriever{docs: []rag.Document{
+		{ID: "handbook", Content: "Tap Settings, then Reset password.", Score: 0.91},
+		{ID: "faq", Content: "Passwords expire after 90 days.", Score: 0.82},
+		{ID: "handbook", Content: "Reset links are valid for an hour.", Score: 0.80},
+	}}
+	service := &InferenceService{retriever: retriever, logger: zap.NewNop()}
+	req := newRAGRequest()
+	inferenceReq := service.createInferenceRequest(req, uuid.New())
+	pipelineCtx := &PipelineContext{InferenceID: uuid.New(), StartTime: time.Now()}
+	policyResult := &policy.EvaluationResult{RAGConfig: &models.RAGConfig{
+		Enabled: true, IndexName: "support", TopK: 3, ScoreThreshold: 0.75,
+	}}
+
+	service.retrieveContext(context.Background(), req, policyResult, inferenceReq, pipelineCtx)
+
+	assert.Equal(t, "The mobile app", retriever.query, "queries with the last user message")
+	assert.Equal(t, 3, retriever.opts.TopK)
+	assert.Equal(t, 0.75, retriever.opts.Threshold)
+	assert.Equal(t, req.OrgID.String(), retriever.opts.Filters[rag.FilterOrgID])
+	assert.Equal(t, "support", retriever.opts.Filters[rag.FilterIndex])
+
+	require.Len(t, req.Messages, 5)
+	assert.Equal(t, "You are a support agent.", req.Messages[0].Content)
+	assert.Equal(t, "system", req.Messages[1].Role)
+	assert.Contains(t, req.Messages[1].Content, "[1] (source: handbook)")
+	assert.Contains(t, req.Messages[1].Content, "[2] (source: faq)")
+	assert.Equal(t, "How do I reset my password?", req.Messages[2].Content)
+	assert.Equal(t, 1, pipelineCtx.RetrievedIndex)
+
+	assert.Equal(t, []string{"handbook", "faq"}, inferenceReq.RetrievedDocuments)
+	require.Len(t, pipelineCtx.Citations, 3)
+	assert.Equal(t, rag.Citation{Index: 2, DocumentID: "faq", Score: 0.82}, pipelineCtx.Citations[1])
+}
+
+func TestRetrieveContext_Skipped(t *testing.T) {
+	enabled := &models.RAGConfig{Enabled: true, IndexName: "support"}
+
+	tests := []struct {
+		name      string
+		retriever *fakeRetriever
+		config    *models.RAGConfig
+	}{
+		{"no policy", &fakeRetriever{docs: []rag.Document{{ID: "a", Content: "x"}}}, nil},
+		{"disabled policy", &fakeRetriever{docs: []rag.Document{{ID: "a", Content: "x"}}}, &models.RAGConfig{IndexName: "support"}},
+		{"no results", &fakeRetriever{}, enabled},
+		{"retrieval error", &fakeRetriever{err: errors.New("embedder unavailable")}, enabled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &InferenceService{retriever: tt.retriever, logger: zap.NewNop()}
+			req := newRAGRequest()
+			inferenceReq := service.createInferenceRequest(req, uuid.New())
+			pipelineCtx := &PipelineContext{InferenceID: uuid.New(), StartTime: time.Now()}
+
+			service.retrieveContext(context.Background(), req, &policy.EvaluationResult{RAGConfig: tt.config}, inferenceReq, pipelineCtx)
+
+			assert.Len(t, req.Messages, 4)
+			assert.Empty(t, inferenceReq.RetrievedDocuments)
+			assert.Nil(t, pipelineCtx.Citations)
+		})
+	}
+}
@@ -7,26 +7,45 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/internal/rag"
 	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/apikey"
 	"github.com/upb/llm-control-plane/backend/services/audit"
 	"github.com/upb/llm-control-plane/backend/services/budget"
+	"github.com/upb/llm-control-plane/backend/services/cache"
+	"github.com/upb/llm-control-plane/backend/services/experiment"
 	"github.com/upb/llm-control-plane/backend/services/policy"
 	"github.com/upb/llm-control-plane/backend/services/prompt"
 	"github.com/upb/llm-control-plane/backend/services/providers"
 	"github.com/upb/llm-control-plane/backend/services/ratelimit"
 	"github.com/upb/llm-control-plane/backend/services/routing"
+	"github.com/upb/llm-control-plane/backend/services/shadow"
+	"github.com/upb/llm-control-plane/backend/services/template"
 	"go.uber.org/zap"
 )
 
+// saveTimeout bounds recording a request's outcome, which happens after the
+// caller's context may have ended
+const saveTimeout = 10 * time.Second
+
 // InferenceService orchestrates the complete inference pipeline
 type InferenceService struct {
-	policyService    *policy.PolicyService
-	rateLimitService *ratelimit.RateLimitService
-	budgetService    *budget.BudgetService
-	promptService    *prompt.PromptService
-	routingService   *routing.RoutingService
-	auditService     *audit.AuditService
-	logger           *zap.Logger
+	policyService     *policy.PolicyService
+	rateLimitService  *ratelimit.RateLimitService
+	budgetService     *budget.BudgetService
+	promptService     *prompt.PromptService
+	routingService    *routing.RoutingService
+	auditService      *audit.AuditService
+	experimentService *experiment.ExperimentService
+	shadowService     *shadow.ShadowService
+	responseCache     *cache.ResponseCacheService
+	templateService   *template.TemplateService
+	retriever         rag.Retriever
+	apiKeyService     *apikey.APIKeyService
+	inferenceRepo     repositories.InferenceRequestRepository
+	logger            *zap.Logger
 }
 
 // NewInferenceService creates a new inference service with all dependencies
@@ -50,14 +69,53 @@ func NewInferenceService(
 	}
 }
 
+// SetInferenceRequestRepository records every request's outcome, which
+// experiment reports and feedback are built from
+func (s *InferenceService) SetInferenceRequestRepository(inferenceRepo repositories.InferenceRequestRepository) {
+	s.inferenceRepo = inferenceRepo
+}
+
+// SetExperimentService enables A/B experiment assignment during routing
+func (s *InferenceService) SetExperimentService(experimentService *experiment.ExperimentService) {
+	s.experimentService = experimentService
+}
+
+// SetShadowService enables mirroring of sampled traffic to shadow targets
+func (s *InferenceService) SetShadowService(shadowService *shadow.ShadowService) {
+	s.shadowService = shadowService
+}
+
+// SetResponseCache enables serving repeated prompts from the response cache
+func (s *InferenceService) SetResponseCache(responseCache *cache.ResponseCacheService) {
+	s.responseCache = responseCache
+}
+
+// SetTemplateService enables rendering prompt templates referenced by requests
+func (s *InferenceService) SetTemplateService(templateService *template.TemplateService) {
+	s.templateService = templateService
+}
+
+// SetRetriever enables injecting retrieved context for requests under a RAG policy
+func (s *InferenceService) SetRetriever(retriever rag.Retriever) {
+	s.retriever = retriever
+}
+
+// SetAPIKeyService enables per-key usage recording of requests made with API keys
+func (s *InferenceService) SetAPIKeyService(apiKeyService *apikey.APIKeyService) {
+	s.apiKeyService = apiKeyService
+}
+
 // ProcessChatCompletion processes a chat completion request through the full pipeline
 func (s *InferenceService) ProcessChatCompletion(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
 	// Initialize pipeline context
 	pipelineCtx := &PipelineContext{
 		Request:     req,
-		InferenceID: uuid.New(),
+		InferenceID: req.InferenceID,
 		StartTime:   time.Now(),
 	}
+	if pipelineCtx.InferenceID == uuid.Nil {
+		pipelineCtx.InferenceID = uuid.New()
+	}
 
 	s.logger.Info("starting inference pipeline",
 		zap.String("inference_id", pipelineCtx.InferenceID.String()),
@@ -68,11 +126,23 @@ func (s *InferenceService) ProcessChatCompletion(ctx context.Context, req *Compl
 	// Create inference request record
 	inferenceReq := s.createInferenceRequest(req, pipelineCtx.InferenceID)
 
+	// Render the referenced prompt template ahead of the caller's messages
+	if err := s.renderTemplate(ctx, req, inferenceReq, pipelineCtx); err != nil {
+		s.handleError(req, inferenceReq, err)
+		return nil, err
+	}
+
+	// Requests made with an API key are limited to the key's models and providers
+	if err := s.checkKeyScopes(req, req.Model, s.getRequestedProvider(req)); err != nil {
+		s.handleError(req, inferenceReq, err)
+		return nil, err
+	}
+
 	// Step 1: Evaluate policies
 	s.logger.Debug("step 1: evaluating policies", zap.String("inference_id", pipelineCtx.InferenceID.String()))
 	policyResult, err := s.evaluatePolicies(ctx, req, pipelineCtx)
 	if err != nil {
-		s.handleError(inferenceReq, err)
+		s.handleError(req, inferenceReq, err)
 		return nil, err
 	}
 	pipelineCtx.PolicyResult = policyResult
@@ -80,7 +150,7 @@ func (s *InferenceService) ProcessChatCompletion(ctx context.Context, req *Compl
 	// Step 2: Check rate limits
 	s.logger.Debug("step 2: checking rate limits", zap.String("inference_id", pipelineCtx.InferenceID.String()))
 	if err := s.checkRateLimit(ctx, req, policyResult, pipelineCtx); err != nil {
-		s.handleError(inferenceReq, err)
+		s.handleError(req, inferenceReq, err)
 		return nil, err
 	}
 	pipelineCtx.RateLimitPassed = true
@@ -88,43 +158,94 @@ func (s *InferenceService) ProcessChatCompletion(ctx context.Context, req *Compl
 	// Step 3: Validate prompt
 	s.logger.Debug("step 3: validating prompt", zap.String("inference_id", pipelineCtx.InferenceID.String()))
 	if err := s.validatePrompt(ctx, req, policyResult, pipelineCtx); err != nil {
-		s.handleError(inferenceReq, err)
+		s.handleError(req, inferenceReq, err)
 		return nil, err
 	}
 	pipelineCtx.PromptValidated = true
 
-	// Step 4: Estimate cost and check budget (pre-check)
-	s.logger.Debug("step 4: checking budget", zap.String("inference_id", pipelineCtx.InferenceID.String()))
-	if err := s.checkBudget(ctx, req, policyResult, pipelineCtx); err != nil {
-		s.handleError(inferenceReq, err)
-		return nil, err
+	// Assign the experiment arm first, since the arm's model is part of the cache key
+	s.assignExperiment(ctx, req, pipelineCtx)
+
+	// Serve from the response cache if a cache policy opts in
+	cacheLookup := s.lookupCache(ctx, req, policyResult, pipelineCtx)
+	if cacheLookup != nil && cacheLookup.Hit != nil {
+		return s.serveCached(ctx, req, inferenceReq, policyResult, cacheLookup.Hit, pipelineCtx), nil
 	}
-	pipelineCtx.BudgetPassed = true
 
-	// Step 5: Route to provider
-	s.logger.Debug("step 5: routing to provider", zap.String("inference_id", pipelineCtx.InferenceID.String()))
+	// Inject retrieved context if a RAG policy applies
+	s.retrieveContext(ctx, req, policyResult, inferenceReq, pipelineCtx)
+
+	// Step 4: Route to provider
+	s.logger.Debug("step 4: routing to provider", zap.String("inference_id", pipelineCtx.InferenceID.String()))
 	selectedProvider, providerReq, err := s.routeToProvider(ctx, req, pipelineCtx)
 	if err != nil {
-		s.handleError(inferenceReq, err)
+		s.handleError(req, inferenceReq, err)
 		return nil, err
 	}
 	pipelineCtx.SelectedProvider = selectedProvider.Name()
+	inferenceReq.Provider = selectedProvider.Name()
+	inferenceReq.Model = providerReq.Model
+	// Assign the arm before any later step can fail, so failures count against it
+	if pipelineCtx.ExperimentID != nil {
+		inferenceReq.SetExperiment(*pipelineCtx.ExperimentID, pipelineCtx.ExperimentArm)
+	}
+
+	// Routing and experiments may pick another model or provider than requested
+	if err := s.checkKeyScopes(req, providerReq.Model, selectedProvider.Name()); err != nil {
+		s.handleError(req, inferenceReq, err)
+		return nil, err
+	}
+
+	// Fit the conversation into the routed model's context window
+	if err := s.fitContext(req, selectedProvider, providerReq, policyResult, pipelineCtx); err != nil {
+		s.handleError(req, inferenceReq, err)
+		return nil, err
+	}
+	if pipelineCtx.ContextFit != nil && pipelineCtx.ContextFit.DroppedRetrieval {
+		inferenceReq.RetrievedDocuments = nil
+		pipelineCtx.Citations = nil
+	}
+
+	// Step 5: Estimate cost and check budget (pre-check) on the request as sent,
+	// after routing, trimming and including any retrieved context
+	s.logger.Debug("step 5: checking budget", zap.String("inference_id", pipelineCtx.InferenceID.String()))
+	if err := s.checkBudget(ctx, req, providerReq, policyResult, pipelineCtx); err != nil {
+		s.handleError(req, inferenceReq, err)
+		return nil, err
+	}
+	pipelineCtx.BudgetPassed = true
 
 	// Mark as processing
 	inferenceReq.MarkAsProcessing()
-	inferenceReq.Provider = selectedProvider.Name()
+
+	// Mirror to shadow target in the background; never affects the caller
+	if s.shadowService != nil && policyResult.ShadowConfig != nil {
+		pipelineCtx.Shadowed = s.shadowService.Mirror(shadow.MirrorRequest{
+			InferenceID: pipelineCtx.InferenceID,
+			OrgID:       req.OrgID,
+			AppID:       req.AppID,
+			RequestID:   req.RequestID,
+			Config:      policyResult.ShadowConfig,
+			Request:     providerReq,
+		})
+	}
 
 	// Step 6: Invoke LLM
 	s.logger.Debug("step 6: invoking LLM",
 		zap.String("inference_id", pipelineCtx.InferenceID.String()),
 		zap.String("provider", selectedProvider.Name()))
-	
-	providerResp, err := s.invokeLLM(ctx, selectedProvider, providerReq)
+
+	providerResp, winner, err := s.invokeLLMHedged(ctx, req, selectedProvider, providerReq, policyResult, inferenceReq, pipelineCtx)
 	if err != nil {
-		s.handleError(inferenceReq, err)
+		s.handleError(req, inferenceReq, err)
 		return nil, err
 	}
 	pipelineCtx.ProviderResponse = providerResp
+	if winner.Name() != selectedProvider.Name() {
+		selectedProvider = winner
+		pipelineCtx.SelectedProvider = winner.Name()
+		inferenceReq.Provider = winner.Name()
+	}
 
 	// Step 7: Validate response
 	s.logger.Debug("step 7: validating response", zap.String("inference_id", pipelineCtx.InferenceID.String()))
@@ -133,6 +254,12 @@ func (s *InferenceService) ProcessChatCompletion(ctx context.Context, req *Compl
 		// Don't fail the request, just log
 	}
 
+	// Cache the response for later identical or similar prompts. Requests in an
+	// experiment are keyed by their arm's model, so hits stay within the arm.
+	if cacheLookup != nil {
+		s.responseCache.Store(cacheLookup, providerResp, policyResult.CacheConfig)
+	}
+
 	// Step 8: Calculate actual cost
 	s.logger.Debug("step 8: calculating cost", zap.String("inference_id", pipelineCtx.InferenceID.String()))
 	actualCost, err := s.calculateCost(selectedProvider, providerResp)
@@ -144,13 +271,13 @@ func (s *InferenceService) ProcessChatCompletion(ctx context.Context, req *Compl
 
 	// Step 9: Update budget
 	s.logger.Debug("step 9: updating budget", zap.String("inference_id", pipelineCtx.InferenceID.String()))
-	if err := s.updateBudget(ctx, req, actualCost, providerResp); err != nil {
+	if err := s.updateBudget(ctx, req, policyResult, actualCost, providerResp); err != nil {
 		s.logger.Error("failed to update budget", zap.Error(err))
 		// Don't fail the request
 	}
 
 	// Step 10: Record rate limit
-	if err := s.recordRateLimit(ctx, req, policyResult, providerResp); err != nil {
+	if err := s.recordRateLimit(ctx, req, policyResult, providerResp.Usage.TotalTokens); err != nil {
 		s.logger.Error("failed to record rate limit", zap.Error(err))
 		// Don't fail the request
 	}
@@ -175,6 +302,8 @@ func (s *InferenceService) ProcessChatCompletion(ctx context.Context, req *Compl
 	// Step 11: Async audit logging
 	s.logger.Debug("step 10: logging audit event", zap.String("inference_id", pipelineCtx.InferenceID.String()))
 	go s.logAudit(inferenceReq, policyResult)
+	go s.recordKeyUsage(inferenceReq)
+	s.recordOutcome(req, inferenceReq)
 
 	s.logger.Info("inference pipeline completed",
 		zap.String("inference_id", pipelineCtx.InferenceID.String()),
@@ -185,6 +314,47 @@ func (s *InferenceService) ProcessChatCompletion(ctx context.Context, req *Compl
 	return response, nil
 }
 
+// renderTemplate renders req.Template and prepends the result to the conversation
+func (s *InferenceService) renderTemplate(ctx context.Context, req *CompletionRequest, inferenceReq *models.InferenceRequest, pipelineCtx *PipelineContext) error {
+	if req.Template == "" {
+		return nil
+	}
+	if s.templateService == nil {
+		return NewValidationError("prompt templates are not enabled", map[string]interface{}{
+			"template": req.Template,
+		})
+	}
+
+	rendered, err := s.templateService.Render(ctx, template.RenderRequest{
+		OrgID:     req.OrgID,
+		AppID:     req.AppID,
+		Ref:       req.Template,
+		Variables: req.TemplateVariables,
+	})
+	if err != nil {
+		details := map[string]interface{}{"template": req.Template}
+		if services.IsValidationError(err) || services.IsNotFoundError(err) {
+			return NewValidationError(err.Error(), details)
+		}
+		details["error"] = err.Error()
+		return NewInternalError("failed to render template", details)
+	}
+
+	messages := make([]providers.Message, 0, len(rendered.Messages)+len(req.Messages))
+	for _, msg := range rendered.Messages {
+		messages = append(messages, providers.Message{Role: msg.Role, Content: msg.Content})
+	}
+	req.Messages = append(messages, req.Messages...)
+
+	if messagesJSON, err := json.Marshal(req.Messages); err == nil {
+		inferenceReq.Messages = messagesJSON
+	}
+	inferenceReq.SetTemplate(rendered.TemplateID, rendered.Version)
+	pipelineCtx.Template = rendered
+
+	return nil
+}
+
 // evaluatePolicies evaluates all applicable policies
 func (s *InferenceService) evaluatePolicies(ctx context.Context, req *CompletionRequest, pipelineCtx *PipelineContext) (*policy.EvaluationResult, error) {
 	evalReq := policy.EvaluationRequest{
@@ -194,6 +364,7 @@ func (s *InferenceService) evaluatePolicies(ctx context.Context, req *Completion
 		Provider: s.getRequestedProvider(req),
 		Model:    req.Model,
 		Prompt:   s.combineMessages(req.Messages),
+		APIKey:   req.APIKey,
 	}
 
 	result, err := s.policyService.Evaluate(ctx, evalReq)
@@ -229,11 +400,12 @@ func (s *InferenceService) checkRateLimit(ctx context.Context, req *CompletionRe
 	}
 
 	rateLimitReq := ratelimit.RateLimitRequest{
-		OrgID:    req.OrgID,
-		AppID:    req.AppID,
-		UserID:   req.UserID,
-		Config:   policyResult.RateLimitConfig,
-		TokensUsed: s.estimatePromptTokens(req.Messages),
+		OrgID:      req.OrgID,
+		AppID:      req.AppID,
+		UserID:     req.UserID,
+		Config:     policyResult.RateLimitConfig,
+		TokensUsed: s.estimatePromptTokens(req.Model, req.Messages),
+		Scope:      policyResult.RateLimitScope,
 	}
 
 	result, err := s.rateLimitService.CheckLimit(ctx, rateLimitReq)
@@ -245,9 +417,9 @@ func (s *InferenceService) checkRateLimit(ctx context.Context, req *CompletionRe
 
 	if !result.Allowed {
 		return NewRateLimitError(result.ViolationReason, map[string]interface{}{
-			"window":     result.ViolatedWindow,
-			"reset_at":   result.ResetAt,
-			"remaining":  result.RequestsRemaining,
+			"window":    result.ViolatedWindow,
+			"reset_at":  result.ResetAt,
+			"remaining": result.RequestsRemaining,
 		})
 	}
 
@@ -287,15 +459,15 @@ func (s *InferenceService) validatePrompt(ctx context.Context, req *CompletionRe
 	return nil
 }
 
-// checkBudget performs pre-check on budget
-func (s *InferenceService) checkBudget(ctx context.Context, req *CompletionRequest, policyResult *policy.EvaluationResult, pipelineCtx *PipelineContext) error {
+// checkBudget performs pre-check on budget for the provider request
+func (s *InferenceService) checkBudget(ctx context.Context, req *CompletionRequest, providerReq *providers.ChatRequest, policyResult *policy.EvaluationResult, pipelineCtx *PipelineContext) error {
 	if policyResult.BudgetConfig == nil {
 		return nil // No budget configured
 	}
 
 	// Estimate cost based on model and estimated tokens
-	estimatedTokens := s.estimatePromptTokens(req.Messages) + (req.MaxTokens / 2) // Rough estimate
-	estimatedCost := s.estimateCostForTokens(req.Model, estimatedTokens)
+	estimatedTokens := s.estimatePromptTokens(providerReq.Model, providerReq.Messages) + (providerReq.MaxTokens / 2) // Rough estimate
+	estimatedCost := s.estimateCostForTokens(providerReq.Model, estimatedTokens)
 	pipelineCtx.EstimatedCost = estimatedCost
 
 	budgetReq := budget.BudgetCheckRequest{
@@ -304,6 +476,7 @@ func (s *InferenceService) checkBudget(ctx context.Context, req *CompletionReque
 		UserID: req.UserID,
 		Config: policyResult.BudgetConfig,
 		Cost:   estimatedCost,
+		Scope:  policyResult.BudgetScope,
 	}
 
 	result, err := s.budgetService.CheckBudget(ctx, budgetReq)
@@ -328,26 +501,23 @@ func (s *InferenceService) checkBudget(ctx context.Context, req *CompletionReque
 
 // routeToProvider selects and routes to the appropriate provider
 func (s *InferenceService) routeToProvider(ctx context.Context, req *CompletionRequest, pipelineCtx *PipelineContext) (providers.Provider, *providers.ChatRequest, error) {
-	// Build provider request
-	providerReq := &providers.ChatRequest{
-		Model:            req.Model,
-		Messages:         req.Messages,
-		MaxTokens:        req.MaxTokens,
-		Temperature:      req.Temperature,
-		TopP:             req.TopP,
-		FrequencyPenalty: req.FrequencyPenalty,
-		PresencePenalty:  req.PresencePenalty,
-		Stop:             req.Stop,
-		Stream:           req.Stream,
-		User:             req.UserID.String(),
-		Metadata:         req.Metadata,
+	// Build provider request, served by the experiment arm's model if assigned
+	providerReq := s.buildProviderRequest(req)
+	if pipelineCtx.ExperimentModel != "" {
+		providerReq.Model = pipelineCtx.ExperimentModel
 	}
 
 	// Use routing service to select provider
-	selectedProvider, err := s.routingService.GetProviderForModel(req.Model)
+	var selectedProvider providers.Provider
+	var err error
+	if pipelineCtx.ExperimentProvider != "" {
+		selectedProvider, err = s.routingService.GetProvider(pipelineCtx.ExperimentProvider)
+	} else {
+		selectedProvider, err = s.routingService.GetProviderForModel(providerReq.Model)
+	}
 	if err != nil {
 		return nil, nil, NewProviderError("failed to route request", map[string]interface{}{
-			"model": req.Model,
+			"model": providerReq.Model,
 			"error": err.Error(),
 		}, false)
 	}
@@ -355,9 +525,220 @@ func (s *InferenceService) routeToProvider(ctx context.Context, req *CompletionR
 	return selectedProvider, providerReq, nil
 }
 
-// invokeLLM calls the LLM provider
+// assignExperiment buckets the request into a running experiment for its
+// model, if any, and records the arm on the pipeline context
+func (s *InferenceService) assignExperiment(ctx context.Context, req *CompletionRequest, pipelineCtx *PipelineContext) {
+	if s.experimentService == nil {
+		return
+	}
+
+	assignment, err := s.experimentService.Assign(ctx, experiment.AssignmentRequest{
+		OrgID:     req.OrgID,
+		AppID:     req.AppID,
+		UserID:    req.UserID,
+		RequestID: req.RequestID,
+		Model:     req.Model,
+	})
+	if err != nil {
+		// Experiments must never block traffic
+		s.logger.Warn("experiment assignment failed", zap.Error(err))
+		return
+	}
+	if assignment == nil {
+		return
+	}
+
+	pipelineCtx.ExperimentID = &assignment.ExperimentID
+	pipelineCtx.ExperimentArm = assignment.Arm.Name
+	pipelineCtx.ExperimentModel = assignment.Arm.Model
+	pipelineCtx.ExperimentProvider = assignment.Arm.Provider
+}
+
+// lookupCache searches the response cache. Returns nil if caching does not apply.
+func (s *InferenceService) lookupCache(ctx context.Context, req *CompletionRequest, policyResult *policy.EvaluationResult, pipelineCtx *PipelineContext) *cache.Lookup {
+	if s.responseCache == nil || !cache.Enabled(policyResult.CacheConfig) || req.Stream {
+		return nil
+	}
+
+	cacheReq := cache.Request{
+		OrgID:   req.OrgID,
+		AppID:   req.AppID,
+		UserID:  req.UserID,
+		Request: s.buildProviderRequest(req),
+	}
+	// Experiment arms are cached apart from each other and keyed like the
+	// request their arm's model serves
+	if pipelineCtx.ExperimentModel != "" {
+		cacheReq.Request.Model = pipelineCtx.ExperimentModel
+	}
+	if req.APIKey != nil {
+		cacheReq.APIKeyID = &req.APIKey.ID
+	}
+	return s.responseCache.Lookup(ctx, cacheReq, policyResult.CacheConfig)
+}
+
+// serveCached completes the request from a cache hit. Cache hits are charged
+// at zero cost, still count towards request rate limits and are audited.
+func (s *InferenceService) serveCached(ctx context.Context, req *CompletionRequest, inferenceReq *models.InferenceRequest, policyResult *policy.EvaluationResult, hit *cache.Hit, pipelineCtx *PipelineContext) *CompletionResponse {
+	providerResp := hit.Response
+	pipelineCtx.CacheHit = hit
+	pipelineCtx.ProviderResponse = providerResp
+	pipelineCtx.SelectedProvider = providerResp.Provider
+	pipelineCtx.ActualCost = 0
+
+	if err := s.recordRateLimit(ctx, req, policyResult, 0); err != nil {
+		s.logger.Error("failed to record rate limit", zap.Error(err))
+	}
+
+	response := s.buildResponse(req, inferenceReq, providerResp, pipelineCtx)
+
+	latencyMs := int(time.Since(pipelineCtx.StartTime).Milliseconds())
+	inferenceReq.Provider = providerResp.Provider
+	inferenceReq.Model = providerResp.Model
+	if pipelineCtx.ExperimentID != nil {
+		inferenceReq.SetExperiment(*pipelineCtx.ExperimentID, pipelineCtx.ExperimentArm)
+	}
+	if len(providerResp.Choices) > 0 {
+		inferenceReq.MarkAsCompleted(
+			providerResp.Choices[0].Message.Content,
+			providerResp.Choices[0].FinishReason,
+			providerResp.Usage.PromptTokens,
+			providerResp.Usage.CompletionTokens,
+			latencyMs,
+			0,
+		)
+	}
+
+	go func() {
+		if err := s.auditService.LogCacheHit(inferenceReq, string(hit.Match), hit.Similarity); err != nil {
+			s.logger.Error("failed to log cache hit", zap.Error(err))
+		}
+	}()
+	go s.recordKeyUsage(inferenceReq)
+	s.recordOutcome(req, inferenceReq)
+
+	s.logger.Info("inference served from cache",
+		zap.String("inference_id", pipelineCtx.InferenceID.String()),
+		zap.String("match", string(hit.Match)),
+		zap.Float64("similarity", hit.Similarity),
+		zap.Int("latency_ms", latencyMs))
+
+	return response
+}
+
+// retrieveContext fetches the top chunks for the last user message and injects
+// them as a system message after any leading system messages. Retrieval
+// failures are logged and the request continues without context.
+func (s *InferenceService) retrieveContext(ctx context.Context, req *CompletionRequest, policyResult *policy.EvaluationResult, inferenceReq *models.InferenceRequest, pipelineCtx *PipelineContext) {
+	config := policyResult.RAGConfig
+	if s.retriever == nil || config == nil || !config.Enabled || config.IndexName == "" {
+		return
+	}
+
+	query := lastUserMessage(req.Messages)
+	if query == "" {
+		return
+	}
+
+	docs, err := s.retriever.Retrieve(ctx, query, rag.RetrievalOptions{
+		TopK:      config.TopK,
+		Threshold: config.ScoreThreshold,
+		Filters: map[string]string{
+			rag.FilterOrgID: req.OrgID.String(),
+			rag.FilterIndex: config.IndexName,
+		},
+	})
+	if err != nil {
+		s.logger.Warn("RAG retrieval failed, continuing without context",
+			zap.String("inference_id", pipelineCtx.InferenceID.String()),
+			zap.String("index", config.IndexName),
+			zap.Error(err))
+		return
+	}
+	if len(docs) == 0 {
+		return
+	}
+
+	content, citations := rag.FormatContext(docs)
+	insertAt := 0
+	for insertAt < len(req.Messages) && req.Messages[insertAt].Role == "system" {
+		insertAt++
+	}
+	messages := make([]providers.Message, 0, len(req.Messages)+1)
+	messages = append(messages, req.Messages[:insertAt]...)
+	messages = append(messages, providers.Message{Role: "system", Content: content})
+	req.Messages = append(messages, req.Messages[insertAt:]...)
+
+	inferenceReq.RetrievedDocuments = rag.DocumentIDs(docs)
+	pipelineCtx.Citations = citations
+	pipelineCtx.RetrievedIndex = insertAt
+
+	s.logger.Debug("injected RAG context",
+		zap.String("inference_id", pipelineCtx.InferenceID.String()),
+		zap.String("index", config.IndexName),
+		zap.Int("chunks", len(docs)))
+}
+
+// lastUserMessage returns the content of the most recent user message
+func lastUserMessage(messages []providers.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// fitContext rejects or trims requests that exceed the routed model's context window
+func (s *InferenceService) fitContext(req *CompletionRequest, provider providers.Provider, providerReq *providers.ChatRequest, policyResult *policy.EvaluationResult, pipelineCtx *PipelineContext) error {
+	strategyName := req.ContextStrategy
+	if strategyName == "" && policyResult != nil && policyResult.RoutingConfig != nil {
+		strategyName = policyResult.RoutingConfig.ContextStrategy
+	}
+
+	strategy, err := ParseContextStrategy(strategyName)
+	if err != nil {
+		return NewValidationError(err.Error(), map[string]interface{}{
+			"context_strategy": strategyName,
+		})
+	}
+
+	modelInfo, err := provider.GetModelInfo(providerReq.Model)
+	if err != nil {
+		// Without model limits there is nothing to check against
+		return nil
+	}
+
+	retrieved := -1
+	if len(pipelineCtx.Citations) > 0 {
+		retrieved = pipelineCtx.RetrievedIndex
+	}
+
+	result, err := fitContextWindow(modelInfo, providerReq, strategy, retrieved)
+	if err != nil {
+		return err
+	}
+
+	if result.Truncated {
+		s.logger.Info("conversation truncated to fit context window",
+			zap.String("inference_id", pipelineCtx.InferenceID.String()),
+			zap.String("strategy", string(result.Strategy)),
+			zap.Int("dropped_messages", result.DroppedMessages),
+			zap.Bool("dropped_retrieval", result.DroppedRetrieval),
+			zap.Int("prompt_tokens", result.PromptTokens))
+	}
+
+	pipelineCtx.ContextFit = result
+	return nil
+}
+
+// invokeLLM calls the LLM provider, recording its latency for hedge delays
 func (s *InferenceService) invokeLLM(ctx context.Context, provider providers.Provider, req *providers.ChatRequest) (*providers.ChatResponse, error) {
+	start := time.Now()
 	resp, err := provider.ChatCompletion(ctx, req)
+	if err == nil || ctx.Err() != nil {
+		s.routingService.ObserveLatency(provider.Name(), time.Since(start))
+	}
 	if err != nil {
 		// Check if retryable
 		retryable := providers.IsRetryable(err)
@@ -370,6 +751,126 @@ func (s *InferenceService) invokeLLM(ctx context.Context, provider providers.Pro
 	return resp, nil
 }
 
+// invokeLLMHedged calls the LLM provider, hedging to a second provider if the
+// routing policy enables it and the primary is slower than its observed latency
+// percentile. Returns the response and the provider that produced it.
+func (s *InferenceService) invokeLLMHedged(ctx context.Context, req *CompletionRequest, primary providers.Provider, providerReq *providers.ChatRequest, policyResult *policy.EvaluationResult, inferenceReq *models.InferenceRequest, pipelineCtx *PipelineContext) (*providers.ChatResponse, providers.Provider, error) {
+	hedgeProvider, hedgeConfig := s.selectHedgeProvider(primary, providerReq, policyResult)
+	if hedgeProvider == nil {
+		resp, err := s.invokeLLM(ctx, primary, providerReq)
+		return resp, primary, err
+	}
+
+	delay := s.routingService.HedgeDelay(primary.Name(), hedgeConfig.Percentile, time.Duration(hedgeConfig.MinDelayMs)*time.Millisecond)
+	result, err := s.routingService.ExecuteHedged(ctx, primary, hedgeProvider, providerReq, delay)
+
+	// Attempts that did not produce the response are still charged and audited
+	for _, attempt := range result.Failed {
+		go s.accountHedgeAttempt(req, inferenceReq, providerReq, policyResult, attempt)
+	}
+	if result.Pending != nil {
+		go func(pending <-chan *routing.HedgeAttempt) {
+			for attempt := range pending {
+				s.accountHedgeAttempt(req, inferenceReq, providerReq, policyResult, attempt)
+			}
+		}(result.Pending)
+	}
+
+	if err != nil {
+		return nil, nil, NewProviderError(fmt.Sprintf("LLM invocation failed: %v", err), map[string]interface{}{
+			"provider":       primary.Name(),
+			"hedge_provider": hedgeProvider.Name(),
+			"model":          providerReq.Model,
+		}, providers.IsRetryable(err))
+	}
+
+	pipelineCtx.Hedged = result.Winner.Hedged
+	return result.Winner.Response, result.Winner.Provider, nil
+}
+
+// selectHedgeProvider returns the hedge target if hedging applies to this request
+func (s *InferenceService) selectHedgeProvider(primary providers.Provider, providerReq *providers.ChatRequest, policyResult *policy.EvaluationResult) (providers.Provider, *models.HedgeConfig) {
+	if policyResult == nil || policyResult.RoutingConfig == nil || providerReq.Stream {
+		return nil, nil
+	}
+
+	hedgeConfig := policyResult.RoutingConfig.Hedge
+	if hedgeConfig == nil || !hedgeConfig.Enabled {
+		return nil, nil
+	}
+
+	name := hedgeConfig.Provider
+	if name == "" && len(policyResult.RoutingConfig.FallbackProviders) > 0 {
+		name = policyResult.RoutingConfig.FallbackProviders[0]
+	}
+	if name == "" || name == primary.Name() {
+		return nil, nil
+	}
+
+	hedgeProvider, err := s.routingService.GetProvider(name)
+	if err != nil {
+		s.logger.Warn("hedge provider not available", zap.String("provider", name), zap.Error(err))
+		return nil, nil
+	}
+	if err := hedgeProvider.ValidateModel(providerReq.Model); err != nil {
+		s.logger.Warn("hedge provider does not support model",
+			zap.String("provider", name),
+			zap.String("model", providerReq.Model))
+		return nil, nil
+	}
+
+	return hedgeProvider, hedgeConfig
+}
+
+// accountHedgeAttempt charges and audits an attempt whose response was not returned.
+// Completed attempts are charged for their actual usage; cancelled attempts are
+// charged for the prompt, which the provider has already processed.
+func (s *InferenceService) accountHedgeAttempt(req *CompletionRequest, inferenceReq *models.InferenceRequest, providerReq *providers.ChatRequest, policyResult *policy.EvaluationResult, attempt *routing.HedgeAttempt) {
+	if attempt == nil {
+		return
+	}
+
+	var cost float64
+	var tokens int
+	outcome := "failed"
+	switch {
+	case attempt.Err == nil && attempt.Response != nil:
+		outcome = "superseded"
+		tokens = attempt.Response.Usage.TotalTokens
+		if c, err := s.calculateCost(attempt.Provider, attempt.Response); err == nil {
+			cost = c
+		}
+	case attempt.Cancelled:
+		outcome = "cancelled"
+		tokens = s.estimatePromptTokens(providerReq.Model, providerReq.Messages)
+		if modelInfo, err := attempt.Provider.GetModelInfo(providerReq.Model); err == nil {
+			cost = float64(tokens) * modelInfo.PricingPerPromptToken
+		}
+	}
+
+	ctx := context.Background()
+	if cost > 0 {
+		if err := s.recordCost(ctx, policyResult, budget.CostRecordRequest{
+			OrgID:      req.OrgID,
+			AppID:      req.AppID,
+			UserID:     req.UserID,
+			Cost:       cost,
+			Currency:   "USD",
+			Provider:   attempt.Provider.Name(),
+			Model:      providerReq.Model,
+			RequestID:  req.RequestID,
+			TokensUsed: tokens,
+		}); err != nil {
+			s.logger.Error("failed to record hedge attempt cost", zap.Error(err))
+		}
+	}
+
+	if err := s.auditService.LogHedgeAttempt(inferenceReq, attempt.Provider.Name(), attempt.Hedged, outcome,
+		tokens, int(attempt.Latency.Milliseconds()), cost); err != nil {
+		s.logger.Error("failed to log hedge attempt", zap.Error(err))
+	}
+}
+
 // validateResponse validates the LLM response
 func (s *InferenceService) validateResponse(ctx context.Context, resp *providers.ChatResponse, pipelineCtx *PipelineContext) error {
 	if len(resp.Choices) == 0 {
@@ -407,7 +908,7 @@ func (s *InferenceService) calculateCost(provider providers.Provider, resp *prov
 }
 
 // updateBudget records the cost
-func (s *InferenceService) updateBudget(ctx context.Context, req *CompletionRequest, cost float64, resp *providers.ChatResponse) error {
+func (s *InferenceService) updateBudget(ctx context.Context, req *CompletionRequest, policyResult *policy.EvaluationResult, cost float64, resp *providers.ChatResponse) error {
 	budgetReq := budget.CostRecordRequest{
 		OrgID:      req.OrgID,
 		AppID:      req.AppID,
@@ -420,11 +921,25 @@ func (s *InferenceService) updateBudget(ctx context.Context, req *CompletionRequ
 		TokensUsed: resp.Usage.TotalTokens,
 	}
 
-	return s.budgetService.RecordCost(ctx, budgetReq)
+	return s.recordCost(ctx, policyResult, budgetReq)
+}
+
+// recordCost records spend for the application, and for the sub-scope of the
+// selected budget when it is counted separately (e.g. per API key)
+func (s *InferenceService) recordCost(ctx context.Context, policyResult *policy.EvaluationResult, costReq budget.CostRecordRequest) error {
+	if err := s.budgetService.RecordCost(ctx, costReq); err != nil {
+		return err
+	}
+	if policyResult == nil || policyResult.BudgetScope == "" {
+		return nil
+	}
+
+	costReq.Scope = policyResult.BudgetScope
+	return s.budgetService.RecordCost(ctx, costReq)
 }
 
 // recordRateLimit records the request for rate limiting
-func (s *InferenceService) recordRateLimit(ctx context.Context, req *CompletionRequest, policyResult *policy.EvaluationResult, resp *providers.ChatResponse) error {
+func (s *InferenceService) recordRateLimit(ctx context.Context, req *CompletionRequest, policyResult *policy.EvaluationResult, tokensUsed int) error {
 	if policyResult.RateLimitConfig == nil {
 		return nil
 	}
@@ -434,7 +949,8 @@ func (s *InferenceService) recordRateLimit(ctx context.Context, req *CompletionR
 		AppID:      req.AppID,
 		UserID:     req.UserID,
 		Config:     policyResult.RateLimitConfig,
-		TokensUsed: resp.Usage.TotalTokens,
+		TokensUsed: tokensUsed,
+		Scope:      policyResult.RateLimitScope,
 	}
 
 	return s.rateLimitService.RecordRequest(ctx, rateLimitReq)
@@ -447,11 +963,87 @@ func (s *InferenceService) logAudit(inferenceReq *models.InferenceRequest, polic
 	}
 }
 
+// recordKeyUsage adds the request to the usage of the API key it was made with
+func (s *InferenceService) recordKeyUsage(inferenceReq *models.InferenceRequest) {
+	if s.apiKeyService == nil || inferenceReq.APIKeyID == nil {
+		return
+	}
+
+	failed := inferenceReq.Status == models.InferenceStatusFailed
+	if err := s.apiKeyService.RecordUsage(context.Background(), *inferenceReq.APIKeyID, inferenceReq.TotalTokens, inferenceReq.Cost, failed); err != nil {
+		s.logger.Error("failed to record api key usage", zap.Error(err))
+	}
+}
+
+// recordOutcome saves the outcome of a request in the background. The
+// pipeline is the only writer of outcomes; those of requests reserved by async
+// submissions are saved before returning, so their record is final when the
+// submitter is notified.
+func (s *InferenceService) recordOutcome(req *CompletionRequest, inferenceReq *models.InferenceRequest) {
+	if req.InferenceID != uuid.Nil {
+		s.saveInferenceRequest(req, inferenceReq)
+		return
+	}
+	go s.saveInferenceRequest(req, inferenceReq)
+}
+
+// saveInferenceRequest records the outcome of a request. Requests reserved
+// ahead of the pipeline by async submissions already have a record.
+func (s *InferenceService) saveInferenceRequest(req *CompletionRequest, inferenceReq *models.InferenceRequest) {
+	if s.inferenceRepo == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), saveTimeout)
+	defer cancel()
+
+	var err error
+	if req.InferenceID != uuid.Nil {
+		err = s.inferenceRepo.Update(ctx, inferenceReq)
+	} else {
+		err = s.inferenceRepo.Create(ctx, inferenceReq)
+	}
+	if err != nil {
+		s.logger.Error("failed to record inference request",
+			zap.String("inference_id", inferenceReq.ID.String()),
+			zap.Error(err))
+	}
+}
+
+// checkKeyScopes rejects models and providers outside the allowlists of the
+// request's API key. An empty provider is not checked.
+func (s *InferenceService) checkKeyScopes(req *CompletionRequest, model, provider string) error {
+	if req.APIKey == nil {
+		return nil
+	}
+
+	scopes, err := req.APIKey.GetScopes()
+	if err != nil {
+		return NewInternalError("failed to read api key scopes", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	if !scopes.AllowsModel(model) {
+		return NewPolicyViolationError(fmt.Sprintf("model %s is not allowed for this API key", model), map[string]interface{}{
+			"model":          model,
+			"allowed_models": scopes.AllowedModels,
+		})
+	}
+	if provider != "" && !scopes.AllowsProvider(provider) {
+		return NewPolicyViolationError(fmt.Sprintf("provider %s is not allowed for this API key", provider), map[string]interface{}{
+			"provider":          provider,
+			"allowed_providers": scopes.AllowedProviders,
+		})
+	}
+	return nil
+}
+
 // Helper methods
 
 func (s *InferenceService) createInferenceRequest(req *CompletionRequest, inferenceID uuid.UUID) *models.InferenceRequest {
 	messagesJSON, _ := json.Marshal(req.Messages)
-	
+
 	inferenceReq := &models.InferenceRequest{
 		ID:        inferenceID,
 		OrgID:     req.OrgID,
@@ -465,10 +1057,29 @@ func (s *InferenceService) createInferenceRequest(req *CompletionRequest, infere
 		UserAgent: req.UserAgent,
 		CreatedAt: time.Now(),
 	}
+	if req.APIKey != nil {
+		inferenceReq.APIKeyID = &req.APIKey.ID
+	}
 
 	return inferenceReq
 }
 
+func (s *InferenceService) buildProviderRequest(req *CompletionRequest) *providers.ChatRequest {
+	return &providers.ChatRequest{
+		Model:            req.Model,
+		Messages:         req.Messages,
+		MaxTokens:        req.MaxTokens,
+		Temperature:      req.Temperature,
+		TopP:             req.TopP,
+		FrequencyPenalty: req.FrequencyPenalty,
+		PresencePenalty:  req.PresencePenalty,
+		Stop:             req.Stop,
+		Stream:           req.Stream,
+		User:             req.UserID.String(),
+		Metadata:         req.Metadata,
+	}
+}
+
 func (s *InferenceService) buildResponse(req *CompletionRequest, inferenceReq *models.InferenceRequest, providerResp *providers.ChatResponse, pipelineCtx *PipelineContext) *CompletionResponse {
 	choices := make([]Choice, len(providerResp.Choices))
 	for i, c := range providerResp.Choices {
@@ -479,7 +1090,7 @@ func (s *InferenceService) buildResponse(req *CompletionRequest, inferenceReq *m
 		}
 	}
 
-	return &CompletionResponse{
+	response := &CompletionResponse{
 		ID:        pipelineCtx.InferenceID,
 		RequestID: req.RequestID,
 		Provider:  providerResp.Provider,
@@ -496,11 +1107,35 @@ func (s *InferenceService) buildResponse(req *CompletionRequest, inferenceReq *m
 		CreatedAt:       pipelineCtx.StartTime,
 		CompletedAt:     time.Now(),
 		PoliciesApplied: pipelineCtx.AppliedPolicies,
-		Metadata:        req.Metadata,
+		Metadata:        s.responseMetadata(req, pipelineCtx),
+	}
+
+	if pipelineCtx.CacheHit != nil {
+		response.Cached = true
+		response.CacheMatch = string(pipelineCtx.CacheHit.Match)
 	}
+	response.Citations = pipelineCtx.Citations
+
+	return response
 }
 
-func (s *InferenceService) handleError(inferenceReq *models.InferenceRequest, err error) {
+// responseMetadata echoes request metadata and adds pipeline details
+func (s *InferenceService) responseMetadata(req *CompletionRequest, pipelineCtx *PipelineContext) map[string]string {
+	if pipelineCtx.ContextFit == nil || pipelineCtx.ContextFit.ContextWindow == 0 {
+		return req.Metadata
+	}
+
+	metadata := make(map[string]string, len(req.Metadata)+3)
+	for k, v := range req.Metadata {
+		metadata[k] = v
+	}
+	for k, v := range pipelineCtx.ContextFit.metadata() {
+		metadata[k] = v
+	}
+	return metadata
+}
+
+func (s *InferenceService) handleError(req *CompletionRequest, inferenceReq *models.InferenceRequest, err error) {
 	if inferenceErr, ok := err.(*InferenceError); ok {
 		inferenceReq.MarkAsFailed(inferenceErr.Code, inferenceErr.Message)
 	} else {
@@ -509,6 +1144,8 @@ func (s *InferenceService) handleError(inferenceReq *models.InferenceRequest, er
 
 	// Log audit event for failed request
 	go s.auditService.LogInferenceRequest(inferenceReq)
+	go s.recordKeyUsage(inferenceReq)
+	s.recordOutcome(req, inferenceReq)
 }
 
 func (s *InferenceService) combineMessages(messages []providers.Message) string {
@@ -529,19 +1166,19 @@ func (s *InferenceService) getRequestedProvider(req *CompletionRequest) string {
 	return ""
 }
 
-func (s *InferenceService) estimatePromptTokens(messages []providers.Message) int {
-	// Rough estimate: ~4 characters per token
-	totalChars := 0
-	for _, msg := range messages {
-		totalChars += len(msg.Content)
+func (s *InferenceService) estimatePromptTokens(model string, messages []providers.Message) int {
+	// Count with the model's tokenizer when the model is known to a provider
+	var modelInfo *providers.ModelInfo
+	if s.routingService != nil {
+		modelInfo, _ = s.routingService.GetModelInfo(model)
 	}
-	return totalChars / 4
+	return providers.CountPromptTokens(modelInfo, model, messages)
 }
 
 func (s *InferenceService) estimateCostForTokens(model string, tokens int) float64 {
 	// Rough estimates - should be replaced with actual pricing
 	pricePerToken := 0.00001 // Default estimate
-	
+
 	// Model-specific pricing (examples)
 	switch model {
 	case "gpt-4":
@@ -549,6 +1186,6 @@ func (s *InferenceService) estimateCostForTokens(model string, tokens int) float
 	case "gpt-3.5-turbo":
 		pricePerToken = 0.000001
 	}
-	
+
 	return float64(tokens) * pricePerToken
 }
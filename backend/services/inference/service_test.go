@@ -1,10 +1,17 @@
 package inference
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/upb/llm-control-plane/backend/internal/tokenizer"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services/cache"
+	"github.com/upb/llm-control-plane/backend/services/policy"
 	"github.com/upb/llm-control-plane/backend/services/providers"
 	"go.uber.org/zap"
 )
@@ -43,6 +50,13 @@ func TestCreateTestRequest(t *testing.T) {
 
 func TestEstimatePromptTokens(t *testing.T) {
 	service := &InferenceService{}
+	enc := tokenizer.ForModel("gpt-4", "")
+
+	// Every message costs its role and content plus fixed formatting overhead,
+	// and the reply is primed once per request
+	message := func(role, content string) int {
+		return tokenizer.TokensPerMessage + enc.Count(role) + enc.Count(content)
+	}
 
 	tests := []struct {
 		name     string
@@ -54,7 +68,7 @@ func TestEstimatePromptTokens(t *testing.T) {
 			messages: []providers.Message{
 				{Role: "user", Content: "Hello"},
 			},
-			expected: 1, // 5 chars / 4 = 1.25 ~ 1
+			expected: tokenizer.TokensPerReply + message("user", "Hello"),
 		},
 		{
 			name: "multiple messages",
@@ -62,14 +76,14 @@ func TestEstimatePromptTokens(t *testing.T) {
 				{Role: "user", Content: "Hello"},
 				{Role: "assistant", Content: "Hi there!"},
 			},
-			expected: 3, // 14 chars / 4 = 3.5 ~ 3
+			expected: tokenizer.TokensPerReply + message("user", "Hello") + message("assistant", "Hi there!"),
 		},
 		{
-			name: "long message",
+			name: "named message",
 			messages: []providers.Message{
-				{Role: "user", Content: "This is a longer message with more content to test token estimation"},
+				{Role: "user", Name: "alice", Content: "Hello"},
 			},
-			expected: 16, // 67 chars / 4 = 16.75 ~ 16
+			expected: tokenizer.TokensPerReply + message("user", "Hello") + enc.Count("alice") + tokenizer.TokensPerName,
 		},
 		{
 			name:     "empty messages",
@@ -80,12 +94,21 @@ func TestEstimatePromptTokens(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			tokens := service.estimatePromptTokens(tt.messages)
+			tokens := service.estimatePromptTokens("gpt-4", tt.messages)
 			assert.Equal(t, tt.expected, tokens)
 		})
 	}
 }
 
+func TestEstimatePromptTokens_NotCharacterBased(t *testing.T) {
+	service := &InferenceService{}
+
+	// CJK text is ~3 bytes per character and rarely merges into long tokens,
+	// so a chars/4 heuristic badly undercounts it
+	cjk := []providers.Message{{Role: "user", Content: "你好，世界。今天天气很好，我们去公园散步吧。"}}
+	assert.Greater(t, service.estimatePromptTokens("gpt-4", cjk), len(cjk[0].Content)/4)
+}
+
 func TestCombineMessages(t *testing.T) {
 	service := &InferenceService{}
 
@@ -317,6 +340,57 @@ func TestCompletionResponse(t *testing.T) {
 	assert.Greater(t, resp.Cost, 0.0)
 }
 
+func TestBuildResponse_CacheHit(t *testing.T) {
+	service := &InferenceService{
+		logger: zap.NewNop(),
+	}
+
+	req := &CompletionRequest{OrgID: uuid.New(), AppID: uuid.New(), Model: "gpt-4"}
+	providerResp := &providers.ChatResponse{
+		Provider: "openai",
+		Model:    "gpt-4",
+		Choices:  []providers.Choice{{Message: providers.Message{Role: "assistant", Content: "Paris"}, FinishReason: "stop"}},
+		Usage:    providers.Usage{PromptTokens: 12, CompletionTokens: 1, TotalTokens: 13},
+	}
+	pipelineCtx := &PipelineContext{
+		InferenceID: uuid.New(),
+		StartTime:   time.Now(),
+		CacheHit:    &cache.Hit{Response: providerResp, Match: cache.MatchSemantic, Similarity: 0.98},
+	}
+
+	resp := service.buildResponse(req, service.createInferenceRequest(req, pipelineCtx.InferenceID), providerResp, pipelineCtx)
+
+	assert.True(t, resp.Cached)
+	assert.Equal(t, "semantic", resp.CacheMatch)
+	assert.Equal(t, 0.0, resp.Cost)
+	assert.Equal(t, "Paris", resp.Choices[0].Message.Content)
+}
+
+func TestLookupCache_KeyedByExperimentArm(t *testing.T) {
+	service := &InferenceService{logger: zap.NewNop()}
+	service.SetResponseCache(cache.NewResponseCacheService(10, nil, zap.NewNop()))
+	policyResult := &policy.EvaluationResult{CacheConfig: &models.CacheConfig{Enabled: true}}
+	ctx := context.Background()
+
+	userID := uuid.New()
+	req := &CompletionRequest{
+		OrgID:    uuid.New(),
+		AppID:    uuid.New(),
+		UserID:   &userID,
+		Model:    "gpt-4",
+		Messages: []providers.Message{{Role: "user", Content: "What is the capital of France?"}},
+	}
+	experimentID := uuid.New()
+	treatment := &PipelineContext{ExperimentID: &experimentID, ExperimentArm: "treatment", ExperimentModel: "gpt-4o-mini"}
+
+	lookup := service.lookupCache(ctx, req, policyResult, treatment)
+	assert.Nil(t, lookup.Hit)
+	service.responseCache.Store(lookup, &providers.ChatResponse{Provider: "openai", Model: "gpt-4o-mini"}, policyResult.CacheConfig)
+
+	assert.NotNil(t, service.lookupCache(ctx, req, policyResult, treatment).Hit, "the same arm is served from the cache")
+	assert.Nil(t, service.lookupCache(ctx, req, policyResult, &PipelineContext{}).Hit, "requests outside the arm are not")
+}
+
 func TestCreateInferenceRequest(t *testing.T) {
 	service := &InferenceService{
 		logger: zap.NewNop(),
@@ -347,3 +421,75 @@ func TestCreateInferenceRequest(t *testing.T) {
 	assert.Equal(t, req.IPAddress, inferenceReq.IPAddress)
 	assert.Equal(t, req.UserAgent, inferenceReq.UserAgent)
 }
+
+// recordingInferenceRepository records which inference requests were created and updated
+type recordingInferenceRepository struct {
+	repositories.InferenceRequestRepository
+	created []*models.InferenceRequest
+	updated []*models.InferenceRequest
+}
+
+func (r *recordingInferenceRepository) Create(ctx context.Context, req *models.InferenceRequest) error {
+	r.created = append(r.created, req)
+	return nil
+}
+
+func (r *recordingInferenceRepository) Update(ctx context.Context, req *models.InferenceRequest) error {
+	r.updated = append(r.updated, req)
+	return nil
+}
+
+func TestSaveInferenceRequest(t *testing.T) {
+	repo := &recordingInferenceRepository{}
+	service := &InferenceService{logger: zap.NewNop()}
+	service.SetInferenceRequestRepository(repo)
+	experimentID := uuid.New()
+
+	t.Run("failed requests keep their experiment arm", func(t *testing.T) {
+		req := &CompletionRequest{OrgID: uuid.New(), AppID: uuid.New(), Model: "gpt-4"}
+		inferenceReq := service.createInferenceRequest(req, uuid.New())
+		inferenceReq.SetExperiment(experimentID, "treatment")
+
+		service.saveInferenceRequest(req, inferenceReq)
+
+		if assert.Len(t, repo.created, 1) {
+			assert.Equal(t, &experimentID, repo.created[0].ExperimentID)
+			assert.Equal(t, "treatment", *repo.created[0].ExperimentArm)
+		}
+	})
+
+	t.Run("requests reserved by async submissions are updated", func(t *testing.T) {
+		req := &CompletionRequest{OrgID: uuid.New(), AppID: uuid.New(), Model: "gpt-4", InferenceID: uuid.New()}
+
+		service.saveInferenceRequest(req, service.createInferenceRequest(req, req.InferenceID))
+
+		assert.Len(t, repo.created, 1)
+		assert.Len(t, repo.updated, 1)
+	})
+}
+
+func TestCheckKeyScopes(t *testing.T) {
+	service := &InferenceService{
+		logger: zap.NewNop(),
+	}
+
+	key := models.NewAPIKey(uuid.New(), uuid.New(), "ci", "lcp_0123abcd", "hash", nil)
+	assert.NoError(t, key.SetScopes(&models.APIKeyScopes{
+		AllowedModels:    []string{"gpt-4o*"},
+		AllowedProviders: []string{"openai"},
+	}))
+	req := &CompletionRequest{APIKey: key}
+
+	assert.NoError(t, service.checkKeyScopes(req, "gpt-4o-mini", "openai"))
+	assert.NoError(t, service.checkKeyScopes(req, "gpt-4o-mini", ""))
+
+	err := service.checkKeyScopes(req, "claude-3-opus", "")
+	if assert.Error(t, err) {
+		assert.Equal(t, ErrCodePolicyViolation, err.(*InferenceError).Code)
+	}
+
+	err = service.checkKeyScopes(req, "gpt-4o", "azure")
+	assert.Error(t, err)
+
+	assert.NoError(t, service.checkKeyScopes(&CompletionRequest{}, "claude-3-opus", "anthropic"))
+}
@@ -4,15 +4,20 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/internal/rag"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/services/cache"
 	"github.com/upb/llm-control-plane/backend/services/providers"
+	"github.com/upb/llm-control-plane/backend/services/template"
 )
 
 // CompletionRequest represents an inference request from the client
 type CompletionRequest struct {
 	// Authentication context
-	OrgID  uuid.UUID  `json:"org_id"`
-	AppID  uuid.UUID  `json:"app_id"`
-	UserID *uuid.UUID `json:"user_id,omitempty"`
+	OrgID  uuid.UUID      `json:"org_id"`
+	AppID  uuid.UUID      `json:"app_id"`
+	UserID *uuid.UUID     `json:"user_id,omitempty"`
+	APIKey *models.APIKey `json:"-"` // Key the request was made with; scopes and overrides apply
 
 	// Model and provider
 	Model    string  `json:"model"`
@@ -21,22 +26,28 @@ type CompletionRequest struct {
 	// Messages for chat completion
 	Messages []providers.Message `json:"messages"`
 
+	// Prompt template rendered ahead of the messages, as "template@version"
+	Template          string            `json:"template,omitempty"`
+	TemplateVariables map[string]string `json:"template_variables,omitempty"`
+
 	// Model parameters
-	MaxTokens        int     `json:"max_tokens,omitempty"`
-	Temperature      float64 `json:"temperature,omitempty"`
-	TopP             float64 `json:"top_p,omitempty"`
-	FrequencyPenalty float64 `json:"frequency_penalty,omitempty"`
-	PresencePenalty  float64 `json:"presence_penalty,omitempty"`
+	MaxTokens        int      `json:"max_tokens,omitempty"`
+	Temperature      float64  `json:"temperature,omitempty"`
+	TopP             float64  `json:"top_p,omitempty"`
+	FrequencyPenalty float64  `json:"frequency_penalty,omitempty"`
+	PresencePenalty  float64  `json:"presence_penalty,omitempty"`
 	Stop             []string `json:"stop,omitempty"`
 
 	// Request metadata
-	RequestID string            `json:"request_id,omitempty"`
-	Metadata  map[string]string `json:"metadata,omitempty"`
-	IPAddress string            `json:"ip_address,omitempty"`
-	UserAgent string            `json:"user_agent,omitempty"`
+	InferenceID uuid.UUID         `json:"-"` // Reserved ahead of the pipeline by async submissions; generated when nil
+	RequestID   string            `json:"request_id,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	IPAddress   string            `json:"ip_address,omitempty"`
+	UserAgent   string            `json:"user_agent,omitempty"`
 
 	// Options
-	Stream bool `json:"stream,omitempty"`
+	Stream          bool   `json:"stream,omitempty"`
+	ContextStrategy string `json:"context_strategy,omitempty"` // Overrides the routing policy's strategy
 }
 
 // CompletionResponse represents the response from an inference request
@@ -71,13 +82,20 @@ type CompletionResponse struct {
 
 	// Metadata
 	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Cache information
+	Cached     bool   `json:"cached"`
+	CacheMatch string `json:"cache_match,omitempty"` // exact or semantic
+
+	// RAG context injected into the prompt, numbered as cited by the model
+	Citations []rag.Citation `json:"citations,omitempty"`
 }
 
 // Choice represents a completion choice
 type Choice struct {
-	Index        int              `json:"index"`
+	Index        int               `json:"index"`
 	Message      providers.Message `json:"message"`
-	FinishReason string           `json:"finish_reason"`
+	FinishReason string            `json:"finish_reason"`
 }
 
 // Usage represents token usage statistics
@@ -106,11 +124,11 @@ type ValidationError struct {
 
 // InferenceError represents an error during inference
 type InferenceError struct {
-	Code       string            `json:"code"`
-	Message    string            `json:"message"`
+	Code       string                 `json:"code"`
+	Message    string                 `json:"message"`
 	Details    map[string]interface{} `json:"details,omitempty"`
-	StatusCode int               `json:"status_code"`
-	Retryable  bool              `json:"retryable"`
+	StatusCode int                    `json:"status_code"`
+	Retryable  bool                   `json:"retryable"`
 }
 
 // Error implements the error interface
@@ -120,13 +138,14 @@ func (e *InferenceError) Error() string {
 
 // Common error codes
 const (
-	ErrCodeValidation       = "VALIDATION_ERROR"
+	ErrCodeValidation        = "VALIDATION_ERROR"
 	ErrCodeRateLimitExceeded = "RATE_LIMIT_EXCEEDED"
-	ErrCodeBudgetExceeded   = "BUDGET_EXCEEDED"
-	ErrCodePolicyViolation  = "POLICY_VIOLATION"
-	ErrCodeProviderError    = "PROVIDER_ERROR"
-	ErrCodeTimeout          = "TIMEOUT"
-	ErrCodeInternal         = "INTERNAL_ERROR"
+	ErrCodeBudgetExceeded    = "BUDGET_EXCEEDED"
+	ErrCodePolicyViolation   = "POLICY_VIOLATION"
+	ErrCodeProviderError     = "PROVIDER_ERROR"
+	ErrCodeTimeout           = "TIMEOUT"
+	ErrCodeInternal          = "INTERNAL_ERROR"
+	ErrCodeContextLength     = "CONTEXT_LENGTH_EXCEEDED"
 )
 
 // NewValidationError creates a validation error
@@ -199,33 +218,72 @@ func NewInternalError(message string, details map[string]interface{}) *Inference
 	}
 }
 
+// NewContextLengthError creates an error for requests that exceed the model's context window
+func NewContextLengthError(message string, details map[string]interface{}) *InferenceError {
+	return &InferenceError{
+		Code:       ErrCodeContextLength,
+		Message:    message,
+		Details:    details,
+		StatusCode: 400,
+		Retryable:  false,
+	}
+}
+
 // PipelineContext holds context for the inference pipeline
 type PipelineContext struct {
-	Request       *CompletionRequest
-	InferenceID   uuid.UUID
-	StartTime     time.Time
-	
+	Request     *CompletionRequest
+	InferenceID uuid.UUID
+	StartTime   time.Time
+
 	// Policy evaluation results
-	PolicyResult  interface{}
+	PolicyResult    interface{}
 	AppliedPolicies []uuid.UUID
-	
+
 	// Rate limiting
 	RateLimitPassed bool
-	
+
 	// Budget
-	EstimatedCost   float64
-	BudgetPassed    bool
-	
+	EstimatedCost float64
+	BudgetPassed  bool
+
 	// Prompt validation
 	PromptValidated bool
 	SanitizedPrompt string
-	
+
 	// Routing
 	SelectedProvider string
-	
+
+	// Experiment assignment
+	ExperimentID       *uuid.UUID
+	ExperimentArm      string
+	ExperimentModel    string // Model the arm serves the request with
+	ExperimentProvider string // Provider the arm pins, if any
+
+	// Shadowed is true if the request was mirrored to a shadow target
+	Shadowed bool
+
+	// Hedged is true if the response came from the hedge provider
+	Hedged bool
+
+	// Context window fitting applied to the routed request
+	ContextFit *ContextFitResult
+
+	// CacheHit is set if the response was served from the response cache
+	CacheHit *cache.Hit
+
+	// Template is the prompt template version rendered into the request
+	Template *template.Rendered
+
+	// Citations for the RAG context injected into the request
+	Citations []rag.Citation
+
+	// RetrievedIndex is the position of the injected RAG context among the
+	// messages; only meaningful while Citations is set
+	RetrievedIndex int
+
 	// Provider response
 	ProviderResponse *providers.ChatResponse
-	
+
 	// Final cost
-	ActualCost      float64
+	ActualCost float64
 }
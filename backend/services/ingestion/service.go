@@ -0,0 +1,221 @@
+package ingestion
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/internal/rag"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/budget"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+// Embedder embeds batches of texts and reports usage and cost
+type Embedder interface {
+	Model() string
+	EmbedBatch(ctx context.Context, texts []string) (*providers.EmbeddingResponse, error)
+}
+
+// CostRecorder charges spend to a budget scope
+type CostRecorder interface {
+	RecordCost(ctx context.Context, req budget.CostRecordRequest) error
+}
+
+// IngestRequest represents a request to add or replace a document in an index
+type IngestRequest struct {
+	OrgID        uuid.UUID
+	IndexName    string
+	DocumentID   string
+	Content      string
+	ContentType  string // text, markdown or html
+	Metadata     map[string]interface{}
+	ChunkSize    int  // Characters per chunk; defaults to rag.DefaultChunkSize
+	ChunkOverlap *int // Characters shared by adjacent chunks; defaults to rag.DefaultChunkOverlap
+	RequestID    string
+}
+
+// IngestResult describes the stored document
+type IngestResult struct {
+	Document *models.RAGDocument `json:"document"`
+
+	// Unchanged is true if identical content was already ingested, in which
+	// case nothing was embedded or charged
+	Unchanged bool `json:"unchanged"`
+}
+
+// IngestionService chunks, embeds and stores documents in tenant-scoped RAG indexes
+type IngestionService struct {
+	documentRepo repositories.RAGDocumentRepository
+	embedder     Embedder
+	costRecorder CostRecorder
+	txManager    repositories.TransactionManager
+	logger       *zap.Logger
+}
+
+// NewIngestionService creates a new IngestionService instance
+func NewIngestionService(documentRepo repositories.RAGDocumentRepository, embedder Embedder, costRecorder CostRecorder, txManager repositories.TransactionManager, logger *zap.Logger) *IngestionService {
+	return &IngestionService{
+		documentRepo: documentRepo,
+		embedder:     embedder,
+		costRecorder: costRecorder,
+		txManager:    txManager,
+		logger:       logger,
+	}
+}
+
+// Ingest chunks and embeds a document and stores it, replacing any earlier
+// version with the same document ID. Re-ingesting identical content with the
+// same chunking is a no-op. Embedding cost is charged to the org's budget.
+func (s *IngestionService) Ingest(ctx context.Context, req IngestRequest) (*IngestResult, error) {
+	if req.IndexName == "" || req.DocumentID == "" {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "index name and document ID are required", nil)
+	}
+	if req.ContentType == "" {
+		req.ContentType = rag.ContentTypeText
+	}
+	if req.ChunkSize == 0 {
+		req.ChunkSize = rag.DefaultChunkSize
+	}
+	overlap := min(rag.DefaultChunkOverlap, req.ChunkSize/2)
+	if req.ChunkOverlap != nil {
+		overlap = *req.ChunkOverlap
+	}
+
+	text, err := rag.ExtractText(req.Content, req.ContentType)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), nil)
+	}
+	if text == "" {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "document has no text content", nil)
+	}
+	texts, err := rag.Chunk(text, req.ChunkSize, overlap)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), nil)
+	}
+
+	metadata := []byte("{}")
+	if req.Metadata != nil {
+		if metadata, err = json.Marshal(req.Metadata); err != nil {
+			return nil, services.NewDomainError(services.ErrorTypeValidation, "invalid metadata", err)
+		}
+	}
+
+	hash := contentHash(req, overlap, text, metadata, s.embedder.Model())
+	existing, err := s.documentRepo.GetDocument(ctx, req.OrgID, req.IndexName, req.DocumentID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to get document", err)
+	}
+	if existing != nil && existing.ContentHash == hash {
+		return &IngestResult{Document: existing, Unchanged: true}, nil
+	}
+
+	embeddings, err := s.embedder.EmbedBatch(ctx, texts)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeExternal, "failed to embed document", err)
+	}
+
+	now := time.Now()
+	doc := &models.RAGDocument{
+		OrgID:          req.OrgID,
+		IndexName:      req.IndexName,
+		DocumentID:     req.DocumentID,
+		ContentType:    req.ContentType,
+		ContentHash:    hash,
+		Metadata:       metadata,
+		ChunkCount:     len(texts),
+		EmbeddingModel: embeddings.Model,
+		TokensUsed:     embeddings.Usage.TotalTokens,
+		Cost:           embeddings.Cost,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if existing != nil {
+		doc.CreatedAt = existing.CreatedAt
+	}
+
+	chunks := make([]*models.RAGChunk, len(texts))
+	for i, content := range texts {
+		chunks[i] = &models.RAGChunk{
+			ID:         uuid.New(),
+			OrgID:      req.OrgID,
+			IndexName:  req.IndexName,
+			DocumentID: req.DocumentID,
+			ChunkIndex: i,
+			Content:    content,
+			Metadata:   metadata,
+			Embedding:  embeddings.Embeddings[i],
+			CreatedAt:  now,
+		}
+	}
+
+	err = s.txManager.InTransaction(ctx, func(ctx context.Context, tx repositories.Transaction) error {
+		return s.documentRepo.ReplaceDocument(ctx, doc, chunks)
+	})
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to store document", err)
+	}
+
+	// The embeddings are already paid for, so a failure to record them must
+	// not fail the ingestion
+	if s.costRecorder != nil {
+		err := s.costRecorder.RecordCost(ctx, budget.CostRecordRequest{
+			OrgID:      req.OrgID,
+			Cost:       embeddings.Cost,
+			Currency:   "USD",
+			Provider:   embeddings.Provider,
+			Model:      embeddings.Model,
+			RequestID:  req.RequestID,
+			TokensUsed: embeddings.Usage.TotalTokens,
+		})
+		if err != nil {
+			s.logger.Error("failed to record embedding cost",
+				zap.String("org_id", req.OrgID.String()),
+				zap.Float64("cost", embeddings.Cost),
+				zap.Error(err))
+		}
+	}
+
+	s.logger.Info("rag document ingested",
+		zap.String("org_id", req.OrgID.String()),
+		zap.String("index", req.IndexName),
+		zap.String("document_id", req.DocumentID),
+		zap.Int("chunks", len(chunks)),
+		zap.Int("tokens", embeddings.Usage.TotalTokens),
+		zap.Float64("cost", embeddings.Cost))
+
+	return &IngestResult{Document: doc}, nil
+}
+
+// Delete removes a document and its chunks from an index
+func (s *IngestionService) Delete(ctx context.Context, orgID uuid.UUID, indexName, documentID string) error {
+	err := s.txManager.InTransaction(ctx, func(ctx context.Context, tx repositories.Transaction) error {
+		return s.documentRepo.DeleteDocument(ctx, orgID, indexName, documentID)
+	})
+	if err != nil {
+		return services.NewDomainError(services.ErrorTypeNotFound, "document not found", err)
+	}
+
+	s.logger.Info("rag document deleted",
+		zap.String("org_id", orgID.String()),
+		zap.String("index", indexName),
+		zap.String("document_id", documentID))
+	return nil
+}
+
+// contentHash identifies everything that determines the stored chunks
+func contentHash(req IngestRequest, overlap int, text string, metadata []byte, model string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%d\x00%d\x00", model, req.ContentType, req.ChunkSize, overlap)
+	h.Write(metadata)
+	h.Write([]byte{0})
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil))
+}
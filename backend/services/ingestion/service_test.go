@@ -0,0 +1,221 @@
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/budget"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"go.uber.org/zap"
+)
+
+// fakeDocumentRepository is an in-memory RAGDocumentRepository
+type fakeDocumentRepository struct {
+	docs   map[string]*models.RAGDocument
+	chunks map[string][]*models.RAGChunk
+}
+
+func docKey(orgID uuid.UUID, indexName, documentID string) string {
+	return orgID.String() + "/" + indexName + "/" + documentID
+}
+
+func (r *fakeDocumentRepository) GetDocument(ctx context.Context, orgID uuid.UUID, indexName, documentID string) (*models.RAGDocument, error) {
+	return r.docs[docKey(orgID, indexName, documentID)], nil
+}
+
+func (r *fakeDocumentRepository) ReplaceDocument(ctx context.Context, doc *models.RAGDocument, chunks []*models.RAGChunk) error {
+	key := docKey(doc.OrgID, doc.IndexName, doc.DocumentID)
+	r.docs[key] = doc
+	r.chunks[key] = chunks
+	return nil
+}
+
+func (r *fakeDocumentRepository) DeleteDocument(ctx context.Context, orgID uuid.UUID, indexName, documentID string) error {
+	key := docKey(orgID, indexName, documentID)
+	if _, ok := r.docs[key]; !ok {
+		return fmt.Errorf("rag document not found")
+	}
+	delete(r.docs, key)
+	delete(r.chunks, key)
+	return nil
+}
+
+func (r *fakeDocumentRepository) WithTx(tx repositories.Transaction) repositories.RAGDocumentRepository {
+	return r
+}
+
+// fakeEmbedder returns one fixed vector per input and counts calls
+type fakeEmbedder struct {
+	calls int
+	err   error
+}
+
+func (e *fakeEmbedder) Model() string {
+	return "test-embedding"
+}
+
+func (e *fakeEmbedder) EmbedBatch(ctx context.Context, texts []string) (*providers.EmbeddingResponse, error) {
+	e.calls++
+	if e.err != nil {
+		return nil, e.err
+	}
+	resp := &providers.EmbeddingResponse{Provider: "openai", Model: e.Model(), Cost: 0.01}
+	for range texts {
+		resp.Embeddings = append(resp.Embeddings, []float64{1, 0})
+	}
+	resp.Usage.TotalTokens = 10 * len(texts)
+	return resp, nil
+}
+
+// fakeCostRecorder captures recorded costs
+type fakeCostRecorder struct {
+	records []budget.CostRecordRequest
+}
+
+func (r *fakeCostRecorder) RecordCost(ctx context.Context, req budget.CostRecordRequest) error {
+	r.records = append(r.records, req)
+	return nil
+}
+
+// fakeTxManager runs transactional functions directly
+type fakeTxManager struct{}
+
+func (fakeTxManager) Begin(ctx context.Context) (repositories.Transaction, error) {
+	return nil, nil
+}
+
+func (fakeTxManager) InTransaction(ctx context.Context, fn func(ctx context.Context, tx repositories.Transaction) error) error {
+	return fn(ctx, nil)
+}
+
+type ingestionFixture struct {
+	service  *IngestionService
+	repo     *fakeDocumentRepository
+	embedder *fakeEmbedder
+	costs    *fakeCostRecorder
+	orgID    uuid.UUID
+}
+
+func newIngestionFixture() *ingestionFixture {
+	repo := &fakeDocumentRepository{docs: map[string]*models.RAGDocument{}, chunks: map[string][]*models.RAGChunk{}}
+	embedder := &fakeEmbedder{}
+	costs := &fakeCostRecorder{}
+	return &ingestionFixture{
+		service:  NewIngestionService(repo, embedder, costs, fakeTxManager{}, zap.NewNop()),
+		repo:     repo,
+		embedder: embedder,
+		costs:    costs,
+		orgID:    uuid.New(),
+	}
+}
+
+func (f *ingestionFixture) request(content string) IngestRequest {
+	return IngestRequest{
+		OrgID:      f.orgID,
+		IndexName:  "handbook",
+		DocumentID: "onboarding",
+		Content:    content,
+		ChunkSize:  40,
+	}
+}
+
+func TestIngestionService_Ingest(t *testing.T) {
+	f := newIngestionFixture()
+
+	result, err := f.service.Ingest(context.Background(), f.request("New hires get a laptop on day one and meet their buddy on day two."))
+	require.NoError(t, err)
+	assert.False(t, result.Unchanged)
+	assert.Equal(t, "test-embedding", result.Document.EmbeddingModel)
+
+	key := docKey(f.orgID, "handbook", "onboarding")
+	chunks := f.repo.chunks[key]
+	require.Greater(t, len(chunks), 1)
+	assert.Equal(t, len(chunks), result.Document.ChunkCount)
+	for i, chunk := range chunks {
+		assert.Equal(t, i, chunk.ChunkIndex)
+		assert.Equal(t, f.orgID, chunk.OrgID)
+	}
+
+	require.Len(t, f.costs.records, 1)
+	assert.Equal(t, f.orgID, f.costs.records[0].OrgID)
+	assert.Equal(t, uuid.Nil, f.costs.records[0].AppID, "embedding cost is charged at the org level")
+	assert.Equal(t, 0.01, f.costs.records[0].Cost)
+}
+
+func TestIngestionService_Ingest_Idempotent(t *testing.T) {
+	f := newIngestionFixture()
+	req := f.request("Expense reports are due on the fifth of each month.")
+
+	first, err := f.service.Ingest(context.Background(), req)
+	require.NoError(t, err)
+
+	second, err := f.service.Ingest(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, second.Unchanged)
+	assert.Equal(t, first.Document.ContentHash, second.Document.ContentHash)
+	assert.Equal(t, 1, f.embedder.calls, "unchanged content is not embedded again")
+	assert.Len(t, f.costs.records, 1, "unchanged content is not charged again")
+
+	req.Content = "Expense reports are due on the tenth of each month."
+	third, err := f.service.Ingest(context.Background(), req)
+	require.NoError(t, err)
+	assert.False(t, third.Unchanged)
+	assert.Equal(t, first.Document.CreatedAt, third.Document.CreatedAt)
+	assert.Equal(t, 2, f.embedder.calls)
+}
+
+func TestIngestionService_Ingest_Errors(t *testing.T) {
+	zero := 0
+	tests := []struct {
+		name   string
+		modify func(*IngestRequest)
+		check  func(error) bool
+	}{
+		{"missing document ID", func(r *IngestRequest) { r.DocumentID = "" }, services.IsValidationError},
+		{"unsupported content type", func(r *IngestRequest) { r.ContentType = "pdf" }, services.IsValidationError},
+		{"no text", func(r *IngestRequest) { r.Content, r.ContentType = "<p> </p>", "html" }, services.IsValidationError},
+		{"overlap not below size", func(r *IngestRequest) { r.ChunkOverlap = &r.ChunkSize }, services.IsValidationError},
+		{"explicit zero overlap", func(r *IngestRequest) { r.ChunkOverlap = &zero }, func(err error) bool { return err == nil }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newIngestionFixture()
+			req := f.request("Some content to ingest.")
+			tt.modify(&req)
+			_, err := f.service.Ingest(context.Background(), req)
+			assert.True(t, tt.check(err), "unexpected error: %v", err)
+		})
+	}
+
+	t.Run("embedder failure", func(t *testing.T) {
+		f := newIngestionFixture()
+		f.embedder.err = errors.New("rate limited")
+		_, err := f.service.Ingest(context.Background(), f.request("Some content."))
+		assert.True(t, services.IsExternalError(err))
+		assert.Empty(t, f.repo.docs)
+		assert.Empty(t, f.costs.records)
+	})
+}
+
+func TestIngestionService_Delete(t *testing.T) {
+	f := newIngestionFixture()
+	_, err := f.service.Ingest(context.Background(), f.request("Some content."))
+	require.NoError(t, err)
+
+	assert.True(t, services.IsNotFoundError(f.service.Delete(context.Background(), uuid.New(), "handbook", "onboarding")),
+		"documents of another org are not visible")
+
+	require.NoError(t, f.service.Delete(context.Background(), f.orgID, "handbook", "onboarding"))
+	assert.Empty(t, f.repo.docs)
+
+	assert.True(t, services.IsNotFoundError(f.service.Delete(context.Background(), f.orgID, "handbook", "onboarding")))
+}
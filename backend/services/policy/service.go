@@ -21,6 +21,7 @@ type EvaluationRequest struct {
 	Provider string
 	Model    string
 	Prompt   string
+	APIKey   *models.APIKey // Key the request was made with; its overrides form the key level
 }
 
 // EvaluationResult represents the result of policy evaluation
@@ -34,6 +35,13 @@ type EvaluationResult struct {
 	PIIConfig        *models.PIIConfig
 	InjectionConfig  *models.InjectionGuardConfig
 	RAGConfig        *models.RAGConfig
+	ShadowConfig     *models.ShadowConfig
+	CacheConfig      *models.CacheConfig
+
+	// Sub-scopes the rate limit and budget count usage under; set when the
+	// selected policy is a key-level override, so that it counts per key
+	RateLimitScope string
+	BudgetScope    string
 }
 
 // PolicyViolation represents a policy violation
@@ -68,6 +76,15 @@ func (s *PolicyService) Evaluate(ctx context.Context, req EvaluationRequest) (*E
 		return nil, fmt.Errorf("failed to fetch policies: %w", err)
 	}
 
+	// The key level is derived per request and never cached
+	if req.APIKey != nil {
+		keyPolicies, err := s.keyPolicies(req.APIKey)
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies[:len(policies):len(policies)], keyPolicies...)
+	}
+
 	// Merge policies by type with priority
 	mergedPolicies := s.mergePolicies(policies)
 
@@ -94,6 +111,9 @@ func (s *PolicyService) Evaluate(ctx context.Context, req EvaluationRequest) (*E
 				continue
 			}
 			result.RateLimitConfig = &config
+			if policy.APIKeyID != nil {
+				result.RateLimitScope = KeyScope(*policy.APIKeyID)
+			}
 
 		case models.PolicyTypeBudget:
 			var config models.BudgetConfig
@@ -104,6 +124,9 @@ func (s *PolicyService) Evaluate(ctx context.Context, req EvaluationRequest) (*E
 				continue
 			}
 			result.BudgetConfig = &config
+			if policy.APIKeyID != nil {
+				result.BudgetScope = KeyScope(*policy.APIKeyID)
+			}
 
 		case models.PolicyTypeRouting:
 			var config models.RoutingConfig
@@ -144,6 +167,26 @@ func (s *PolicyService) Evaluate(ctx context.Context, req EvaluationRequest) (*E
 				continue
 			}
 			result.RAGConfig = &config
+
+		case models.PolicyTypeShadow:
+			var config models.ShadowConfig
+			if err := json.Unmarshal(policy.Config, &config); err != nil {
+				s.logger.Error("failed to unmarshal shadow config",
+					zap.Error(err),
+					zap.String("policy_id", policy.ID.String()))
+				continue
+			}
+			result.ShadowConfig = &config
+
+		case models.PolicyTypeCache:
+			var config models.CacheConfig
+			if err := json.Unmarshal(policy.Config, &config); err != nil {
+				s.logger.Error("failed to unmarshal cache config",
+					zap.Error(err),
+					zap.String("policy_id", policy.ID.String()))
+				continue
+			}
+			result.CacheConfig = &config
 		}
 	}
 
@@ -226,7 +269,43 @@ func (s *PolicyService) fetchPoliciesHierarchical(ctx context.Context, orgID, ap
 	return policies, nil
 }
 
-// mergePolicies merges policies by type, prioritizing higher-level (user > app > org)
+// keyPolicies derives key-level policies from an API key's rate limit and
+// budget overrides. They carry the key's ID.
+func (s *PolicyService) keyPolicies(key *models.APIKey) ([]*models.Policy, error) {
+	scopes, err := key.GetScopes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read api key scopes: %w", err)
+	}
+
+	overrides := map[models.PolicyType]interface{}{}
+	if scopes.RateLimit != nil {
+		overrides[models.PolicyTypeRateLimit] = scopes.RateLimit
+	}
+	if scopes.Budget != nil {
+		overrides[models.PolicyTypeBudget] = scopes.Budget
+	}
+
+	policies := make([]*models.Policy, 0, len(overrides))
+	for policyType, override := range overrides {
+		config, err := json.Marshal(override)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal api key override: %w", err)
+		}
+		p := models.NewPolicy(key.OrgID, policyType, config, 0)
+		p.ID = key.ID
+		p.AppID = &key.AppID
+		p.APIKeyID = &key.ID
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// KeyScope returns the sub-scope under which an API key's overrides count usage
+func KeyScope(keyID uuid.UUID) string {
+	return "key:" + keyID.String()
+}
+
+// mergePolicies merges policies by type, prioritizing higher-level (key > user > app > org)
 // and within the same level, higher priority values take precedence
 func (s *PolicyService) mergePolicies(policies []*models.Policy) []*models.Policy {
 	// Group policies by type
@@ -245,12 +324,12 @@ func (s *PolicyService) mergePolicies(policies []*models.Policy) []*models.Polic
 			continue
 		}
 
-		// Sort by priority: user-level > app-level > org-level, then by priority value
+		// Sort by priority: key-level > user-level > app-level > org-level, then by priority value
 		sort.Slice(typePolicies, func(i, j int) bool {
 			pi := typePolicies[i]
 			pj := typePolicies[j]
 
-			// Calculate hierarchy level (user=3, app=2, org=1)
+			// Calculate hierarchy level (key=4, user=3, app=2, org=1)
 			levelI := s.getPolicyLevel(pi)
 			levelJ := s.getPolicyLevel(pj)
 
@@ -277,8 +356,11 @@ func (s *PolicyService) mergePolicies(policies []*models.Policy) []*models.Polic
 }
 
 // getPolicyLevel returns the hierarchy level of a policy
-// user=3, app=2, org=1
+// key=4, user=3, app=2, org=1
 func (s *PolicyService) getPolicyLevel(p *models.Policy) int {
+	if p.APIKeyID != nil {
+		return 4
+	}
 	if p.UserID != nil {
 		return 3
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/upb/llm-control-plane/backend/models"
 	"github.com/upb/llm-control-plane/backend/repositories"
 	"go.uber.org/zap"
@@ -157,6 +158,46 @@ func TestPolicyService_Evaluate(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestPolicyService_Evaluate_APIKeyOverrides(t *testing.T) {
+	cache := NewPolicyCache(10, 5*time.Minute)
+	mockRepo := new(MockPolicyRepository)
+	service := NewPolicyService(mockRepo, cache, zap.NewNop())
+
+	ctx := context.Background()
+	orgID := uuid.New()
+	appID := uuid.New()
+
+	appRateLimit, _ := json.Marshal(models.RateLimitConfig{RequestsPerMinute: 1000})
+	appBudget, _ := json.Marshal(models.BudgetConfig{MaxDailyCost: 500})
+	mockRepo.On("GetByOrgID", ctx, orgID).Return([]*models.Policy{}, nil)
+	mockRepo.On("GetByAppID", ctx, appID).Return([]*models.Policy{
+		{ID: uuid.New(), OrgID: orgID, AppID: &appID, PolicyType: models.PolicyTypeRateLimit, Config: appRateLimit, Priority: 100, Enabled: true},
+		{ID: uuid.New(), OrgID: orgID, AppID: &appID, PolicyType: models.PolicyTypeBudget, Config: appBudget, Priority: 100, Enabled: true},
+	}, nil)
+
+	key := models.NewAPIKey(orgID, appID, "ci", "lcp_0123abcd", "hash", nil)
+	require.NoError(t, key.SetScopes(&models.APIKeyScopes{
+		RateLimit: &models.RateLimitConfig{RequestsPerMinute: 10},
+	}))
+
+	result, err := service.Evaluate(ctx, EvaluationRequest{OrgID: orgID, AppID: appID, APIKey: key})
+	require.NoError(t, err)
+
+	// The key's rate limit replaces the application's and counts per key
+	assert.Equal(t, 10, result.RateLimitConfig.RequestsPerMinute)
+	assert.Equal(t, KeyScope(key.ID), result.RateLimitScope)
+
+	// Without a budget override the application's budget applies as before
+	assert.Equal(t, 500.0, result.BudgetConfig.MaxDailyCost)
+	assert.Empty(t, result.BudgetScope)
+
+	// Key-level policies are not cached for requests without the key
+	result, err = service.Evaluate(ctx, EvaluationRequest{OrgID: orgID, AppID: appID})
+	require.NoError(t, err)
+	assert.Equal(t, 1000, result.RateLimitConfig.RequestsPerMinute)
+	assert.Empty(t, result.RateLimitScope)
+}
+
 func TestPolicyService_MergePolicies_Priority(t *testing.T) {
 	logger, _ := zap.NewDevelopment()
 	cache := NewPolicyCache(10, 5*time.Minute)
@@ -458,3 +499,79 @@ func TestPolicyService_GetCacheStats(t *testing.T) {
 	assert.Equal(t, 0, stats.Size)
 	assert.Equal(t, 10, stats.MaxSize)
 }
+
+func TestPolicyService_Evaluate_ShadowConfig(t *testing.T) {
+	logger := zap.NewNop()
+	cache := NewPolicyCache(10, 5*time.Minute)
+	mockRepo := new(MockPolicyRepository)
+	service := NewPolicyService(mockRepo, cache, logger)
+
+	ctx := context.Background()
+	orgID := uuid.New()
+	appID := uuid.New()
+
+	shadowJSON, _ := json.Marshal(models.ShadowConfig{
+		Enabled:    true,
+		Provider:   "anthropic",
+		Model:      "claude-3-haiku",
+		SampleRate: 0.1,
+	})
+
+	mockRepo.On("GetByOrgID", ctx, orgID).Return([]*models.Policy{}, nil)
+	mockRepo.On("GetByAppID", ctx, appID).Return([]*models.Policy{
+		{
+			ID:         uuid.New(),
+			OrgID:      orgID,
+			AppID:      &appID,
+			PolicyType: models.PolicyTypeShadow,
+			Config:     shadowJSON,
+			Enabled:    true,
+		},
+	}, nil)
+
+	result, err := service.Evaluate(ctx, EvaluationRequest{OrgID: orgID, AppID: appID, Model: "gpt-4"})
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.ShadowConfig) {
+		assert.Equal(t, "anthropic", result.ShadowConfig.Provider)
+		assert.Equal(t, 0.1, result.ShadowConfig.SampleRate)
+	}
+}
+
+func TestPolicyService_Evaluate_CacheConfig(t *testing.T) {
+	logger := zap.NewNop()
+	cache := NewPolicyCache(10, 5*time.Minute)
+	mockRepo := new(MockPolicyRepository)
+	service := NewPolicyService(mockRepo, cache, logger)
+
+	ctx := context.Background()
+	orgID := uuid.New()
+	appID := uuid.New()
+
+	cacheJSON, _ := json.Marshal(models.CacheConfig{
+		Enabled:             true,
+		TTLSeconds:          600,
+		Semantic:            true,
+		SimilarityThreshold: 0.97,
+	})
+
+	mockRepo.On("GetByOrgID", ctx, orgID).Return([]*models.Policy{
+		{
+			ID:         uuid.New(),
+			OrgID:      orgID,
+			PolicyType: models.PolicyTypeCache,
+			Config:     cacheJSON,
+			Enabled:    true,
+		},
+	}, nil)
+	mockRepo.On("GetByAppID", ctx, appID).Return([]*models.Policy{}, nil)
+
+	result, err := service.Evaluate(ctx, EvaluationRequest{OrgID: orgID, AppID: appID, Model: "gpt-4"})
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.CacheConfig) {
+		assert.Equal(t, 600, result.CacheConfig.TTLSeconds)
+		assert.True(t, result.CacheConfig.Semantic)
+		assert.Equal(t, 0.97, result.CacheConfig.SimilarityThreshold)
+	}
+}
@@ -158,6 +158,9 @@ type ModelInfo struct {
 	// ContextWindow size
 	ContextWindow int `json:"context_window"`
 
+	// Tokenizer is the BPE encoding used by the model (e.g., "cl100k_base", "o200k_base")
+	Tokenizer string `json:"tokenizer,omitempty"`
+
 	// Pricing information
 	PricingPerPromptToken     float64 `json:"pricing_per_prompt_token"`
 	PricingPerCompletionToken float64 `json:"pricing_per_completion_token"`
@@ -274,3 +277,31 @@ type StreamingProvider interface {
 	// ChatCompletionStream performs a streaming chat completion
 	ChatCompletionStream(ctx context.Context, req *ChatRequest, callback StreamCallback) error
 }
+
+// EmbeddingRequest represents a request to embed one or more texts
+type EmbeddingRequest struct {
+	// Model identifier (e.g., "text-embedding-3-small")
+	Model string `json:"model"`
+
+	// Input texts to embed
+	Input []string `json:"input"`
+}
+
+// EmbeddingResponse represents embeddings for each input, in input order
+type EmbeddingResponse struct {
+	Provider   string      `json:"provider"`
+	Model      string      `json:"model"`
+	Embeddings [][]float64 `json:"embeddings"`
+	Usage      Usage       `json:"usage"`
+
+	// Cost of the request in USD
+	Cost float64 `json:"cost"`
+}
+
+// EmbeddingProvider extends Provider with embedding support
+type EmbeddingProvider interface {
+	Provider
+
+	// Embed generates embeddings for the request inputs
+	Embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error)
+}
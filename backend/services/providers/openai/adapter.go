@@ -78,14 +78,7 @@ func (a *OpenAIAdapter) ChatCompletion(ctx context.Context, req *providers.ChatR
 	}
 
 	// Set headers
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+a.config.APIKey)
-	if a.config.OrgID != "" {
-		httpReq.Header.Set("OpenAI-Organization", a.config.OrgID)
-	}
-	for k, v := range a.config.Headers {
-		httpReq.Header.Set(k, v)
-	}
+	a.setHeaders(httpReq)
 
 	// Execute request with retry logic
 	var httpResp *http.Response
@@ -160,12 +153,7 @@ func (a *OpenAIAdapter) EstimateCost(req *providers.ChatRequest) (float64, error
 		return 0, err
 	}
 
-	// Rough token estimation (4 chars per token average)
-	totalChars := 0
-	for _, msg := range req.Messages {
-		totalChars += len(msg.Content)
-	}
-	estimatedPromptTokens := totalChars / 4
+	estimatedPromptTokens := providers.CountPromptTokens(modelInfo, req.Model, req.Messages)
 
 	// Estimate completion tokens based on MaxTokens or default
 	estimatedCompletionTokens := req.MaxTokens
@@ -215,6 +203,7 @@ func (a *OpenAIAdapter) initModels() {
 			Description:               "Most capable GPT-4 model",
 			MaxTokens:                 8192,
 			ContextWindow:             8192,
+			Tokenizer:                 "cl100k_base",
 			PricingPerPromptToken:     0.00003,  // $0.03 per 1K tokens
 			PricingPerCompletionToken: 0.00006,  // $0.06 per 1K tokens
 			SupportsStreaming:         true,
@@ -228,6 +217,7 @@ func (a *OpenAIAdapter) initModels() {
 			Description:               "Latest GPT-4 Turbo with vision",
 			MaxTokens:                 4096,
 			ContextWindow:             128000,
+			Tokenizer:                 "cl100k_base",
 			PricingPerPromptToken:     0.00001,  // $0.01 per 1K tokens
 			PricingPerCompletionToken: 0.00003,  // $0.03 per 1K tokens
 			SupportsStreaming:         true,
@@ -242,6 +232,7 @@ func (a *OpenAIAdapter) initModels() {
 			Description:               "Fast and efficient model",
 			MaxTokens:                 4096,
 			ContextWindow:             16385,
+			Tokenizer:                 "cl100k_base",
 			PricingPerPromptToken:     0.0000005, // $0.0005 per 1K tokens
 			PricingPerCompletionToken: 0.0000015, // $0.0015 per 1K tokens
 			SupportsStreaming:         true,
@@ -255,6 +246,7 @@ func (a *OpenAIAdapter) initModels() {
 			Description:               "Optimized GPT-4 model",
 			MaxTokens:                 4096,
 			ContextWindow:             128000,
+			Tokenizer:                 "o200k_base",
 			PricingPerPromptToken:     0.000005,  // $0.005 per 1K tokens
 			PricingPerCompletionToken: 0.000015,  // $0.015 per 1K tokens
 			SupportsStreaming:         true,
@@ -269,6 +261,7 @@ func (a *OpenAIAdapter) initModels() {
 			Description:               "Smaller, faster GPT-4o model",
 			MaxTokens:                 16384,
 			ContextWindow:             128000,
+			Tokenizer:                 "o200k_base",
 			PricingPerPromptToken:     0.00000015, // $0.00015 per 1K tokens
 			PricingPerCompletionToken: 0.0000006,  // $0.0006 per 1K tokens
 			SupportsStreaming:         true,
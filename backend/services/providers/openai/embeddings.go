@@ -0,0 +1,131 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/upb/llm-control-plane/backend/services/providers"
+)
+
+// embeddingPricing is the price per input token of each embedding model
+var embeddingPricing = map[string]float64{
+	"text-embedding-3-small": 0.00000002, // $0.02 per 1M tokens
+	"text-embedding-3-large": 0.00000013, // $0.13 per 1M tokens
+	"text-embedding-ada-002": 0.0000001,  // $0.10 per 1M tokens
+}
+
+// Embed generates embeddings through the OpenAI embeddings endpoint
+func (a *OpenAIAdapter) Embed(ctx context.Context, req *providers.EmbeddingRequest) (*providers.EmbeddingResponse, error) {
+	price, ok := embeddingPricing[req.Model]
+	if !ok {
+		err := fmt.Errorf("embedding model %s is not supported by OpenAI provider", req.Model)
+		return nil, providers.NewProviderError(a.Name(), "INVALID_MODEL", err.Error(), 400, false, err)
+	}
+	if len(req.Input) == 0 {
+		return &providers.EmbeddingResponse{Provider: a.Name(), Model: req.Model}, nil
+	}
+
+	reqBody, err := json.Marshal(OpenAIEmbeddingRequest{Model: req.Model, Input: req.Input})
+	if err != nil {
+		return nil, providers.NewProviderError(a.Name(), "MARSHAL_ERROR", "Failed to marshal request", 0, false, err)
+	}
+
+	// Execute request with retry logic
+	var httpResp *http.Response
+	var lastErr error
+
+	for attempt := 0; attempt <= a.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(a.config.RetryDelay * time.Duration(attempt))
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", a.config.BaseURL+"/embeddings", bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, providers.NewProviderError(a.Name(), "REQUEST_ERROR", "Failed to create request", 0, false, err)
+		}
+		a.setHeaders(httpReq)
+
+		httpResp, lastErr = a.httpClient.Do(httpReq)
+		if lastErr == nil && httpResp.StatusCode < 500 {
+			break
+		}
+
+		if httpResp != nil {
+			httpResp.Body.Close()
+		}
+	}
+
+	if lastErr != nil {
+		return nil, providers.NewProviderError(a.Name(), "HTTP_ERROR", "HTTP request failed", 0, true, lastErr)
+	}
+	defer httpResp.Body.Close()
+
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, providers.NewProviderError(a.Name(), "READ_ERROR", "Failed to read response", httpResp.StatusCode, false, err)
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, a.handleErrorResponse(httpResp.StatusCode, respBody)
+	}
+
+	var openaiResp OpenAIEmbeddingResponse
+	if err := json.Unmarshal(respBody, &openaiResp); err != nil {
+		return nil, providers.NewProviderError(a.Name(), "UNMARSHAL_ERROR", "Failed to unmarshal response", httpResp.StatusCode, false, err)
+	}
+
+	embeddings := make([][]float64, len(req.Input))
+	for _, d := range openaiResp.Data {
+		if d.Index < 0 || d.Index >= len(embeddings) {
+			err := fmt.Errorf("embedding index %d out of range", d.Index)
+			return nil, providers.NewProviderError(a.Name(), "INVALID_RESPONSE", err.Error(), httpResp.StatusCode, false, err)
+		}
+		embeddings[d.Index] = d.Embedding
+	}
+
+	return &providers.EmbeddingResponse{
+		Provider:   a.Name(),
+		Model:      req.Model,
+		Embeddings: embeddings,
+		Usage: providers.Usage{
+			PromptTokens: openaiResp.Usage.PromptTokens,
+			TotalTokens:  openaiResp.Usage.TotalTokens,
+		},
+		Cost: float64(openaiResp.Usage.PromptTokens) * price,
+	}, nil
+}
+
+// setHeaders sets authentication and configured headers on a request
+func (a *OpenAIAdapter) setHeaders(httpReq *http.Request) {
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+a.config.APIKey)
+	if a.config.OrgID != "" {
+		httpReq.Header.Set("OpenAI-Organization", a.config.OrgID)
+	}
+	for k, v := range a.config.Headers {
+		httpReq.Header.Set(k, v)
+	}
+}
+
+type OpenAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type OpenAIEmbeddingResponse struct {
+	Object string                `json:"object"`
+	Data   []OpenAIEmbeddingData `json:"data"`
+	Model  string                `json:"model"`
+	Usage  OpenAIUsage           `json:"usage"`
+}
+
+type OpenAIEmbeddingData struct {
+	Object    string    `json:"object"`
+	Index     int       `json:"index"`
+	Embedding []float64 `json:"embedding"`
+}
@@ -0,0 +1,81 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/upb/llm-control-plane/backend/services/providers"
+)
+
+func TestOpenAIAdapter_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/embeddings" {
+			t.Errorf("Expected path /embeddings, got %s", r.URL.Path)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var req OpenAIEmbeddingRequest
+		json.Unmarshal(body, &req)
+		if len(req.Input) != 2 {
+			t.Errorf("Expected 2 inputs, got %d", len(req.Input))
+		}
+
+		// Return data out of order to check that results follow input order
+		resp := OpenAIEmbeddingResponse{
+			Object: "list",
+			Model:  req.Model,
+			Data: []OpenAIEmbeddingData{
+				{Object: "embedding", Index: 1, Embedding: []float64{0, 1}},
+				{Object: "embedding", Index: 0, Embedding: []float64{1, 0}},
+			},
+			Usage: OpenAIUsage{PromptTokens: 1000, TotalTokens: 1000},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	adapter := NewOpenAIAdapter(providers.ProviderConfig{
+		APIKey:  "test-key",
+		BaseURL: server.URL,
+		Timeout: 5 * time.Second,
+	})
+
+	resp, err := adapter.Embed(context.Background(), &providers.EmbeddingRequest{
+		Model: "text-embedding-3-small",
+		Input: []string{"first", "second"},
+	})
+	if err != nil {
+		t.Fatalf("Embed() error = %v", err)
+	}
+
+	if resp.Embeddings[0][0] != 1 || resp.Embeddings[1][1] != 1 {
+		t.Errorf("Embeddings not in input order: %v", resp.Embeddings)
+	}
+
+	if resp.Usage.PromptTokens != 1000 {
+		t.Errorf("PromptTokens = %d, want 1000", resp.Usage.PromptTokens)
+	}
+
+	if resp.Cost < 0.0000199 || resp.Cost > 0.0000201 {
+		t.Errorf("Cost = %v, want 0.00002", resp.Cost)
+	}
+}
+
+func TestOpenAIAdapter_Embed_UnsupportedModel(t *testing.T) {
+	adapter := NewOpenAIAdapter(providers.ProviderConfig{APIKey: "test-key"})
+
+	_, err := adapter.Embed(context.Background(), &providers.EmbeddingRequest{
+		Model: "gpt-4",
+		Input: []string{"hello"},
+	})
+	if err == nil {
+		t.Error("Expected error for unsupported embedding model")
+	}
+}
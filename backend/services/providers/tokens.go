@@ -0,0 +1,35 @@
+package providers
+
+import "github.com/upb/llm-control-plane/backend/internal/tokenizer"
+
+// CountPromptTokens counts the prompt tokens of a chat request with the
+// model's tokenizer, including per-message overhead. info may be nil, in which
+// case the tokenizer is inferred from the model name.
+func CountPromptTokens(info *ModelInfo, model string, messages []Message) int {
+	name := ""
+	if info != nil {
+		name = info.Tokenizer
+	}
+
+	msgs := make([]tokenizer.Message, len(messages))
+	for i, msg := range messages {
+		msgs[i] = tokenizer.Message{Role: msg.Role, Name: msg.Name, Content: msg.Content}
+	}
+	return tokenizer.ForModel(model, name).CountMessages(msgs)
+}
+
+// CountMessageTokens returns the token cost of each message and the fixed
+// per-request overhead, so callers can drop messages without recounting
+func CountMessageTokens(info *ModelInfo, model string, messages []Message) ([]int, int) {
+	name := ""
+	if info != nil {
+		name = info.Tokenizer
+	}
+
+	enc := tokenizer.ForModel(model, name)
+	counts := make([]int, len(messages))
+	for i, msg := range messages {
+		counts[i] = enc.CountMessages([]tokenizer.Message{{Role: msg.Role, Name: msg.Name, Content: msg.Content}}) - tokenizer.TokensPerReply
+	}
+	return counts, tokenizer.TokensPerReply
+}
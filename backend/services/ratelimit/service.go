@@ -27,6 +27,7 @@ type RateLimitRequest struct {
 	UserID   *uuid.UUID
 	Config   *models.RateLimitConfig
 	TokensUsed int
+	Scope    string // Optional sub-scope (e.g. an API key's)
 }
 
 // RateLimitResult represents the result of a rate limit check
@@ -64,7 +65,7 @@ func (s *RateLimitService) CheckLimit(ctx context.Context, req RateLimitRequest)
 	}
 
 	// Build scope key for rate limiting
-	scopeKey := s.buildScopeKey(req.OrgID, req.AppID, req.UserID)
+	scopeKey := s.scopedKey(req.OrgID, req.AppID, req.UserID, req.Scope)
 
 	// Check each time window
 	now := time.Now()
@@ -150,7 +151,7 @@ func (s *RateLimitService) RecordRequest(ctx context.Context, req RateLimitReque
 		return nil
 	}
 
-	scopeKey := s.buildScopeKey(req.OrgID, req.AppID, req.UserID)
+	scopeKey := s.scopedKey(req.OrgID, req.AppID, req.UserID, req.Scope)
 	now := time.Now()
 
 	// Record request count
@@ -239,6 +240,15 @@ func (s *RateLimitService) buildScopeKey(orgID, appID uuid.UUID, userID *uuid.UU
 	return fmt.Sprintf("org:%s:app:%s", orgID.String(), appID.String())
 }
 
+// scopedKey builds the scope key with an optional sub-scope suffix
+func (s *RateLimitService) scopedKey(orgID, appID uuid.UUID, userID *uuid.UUID, scope string) string {
+	key := s.buildScopeKey(orgID, appID, userID)
+	if scope != "" {
+		key += ":" + scope
+	}
+	return key
+}
+
 // CleanupOldRequests removes old rate limit events to keep the table size manageable
 // Should be called periodically (e.g., daily)
 func (s *RateLimitService) CleanupOldRequests(ctx context.Context, olderThan time.Duration) (int64, error) {
@@ -44,6 +44,12 @@ func TestRateLimitService_BuildScopeKey(t *testing.T) {
 		expected := "org:" + orgID.String() + ":app:" + appID.String() + ":user:" + userID.String()
 		assert.Equal(t, expected, key)
 	})
+
+	t.Run("with sub-scope", func(t *testing.T) {
+		key := service.scopedKey(orgID, appID, nil, "key:abc")
+		expected := "org:" + orgID.String() + ":app:" + appID.String() + ":key:abc"
+		assert.Equal(t, expected, key)
+	})
 }
 
 func TestRateLimitService_GetWindowBounds(t *testing.T) {
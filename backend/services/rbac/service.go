@@ -0,0 +1,332 @@
+package rbac
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/audit"
+	"go.uber.org/zap"
+)
+
+// DefaultCacheTTL is how long an organization's role mappings are cached;
+// changes made through the service take effect immediately
+const DefaultCacheTTL = time.Minute
+
+// Grantor is the caller granting a role its permissions: the roles and, for
+// scoped tokens, the scopes of the caller's claims
+type Grantor struct {
+	Roles  []string
+	Scopes []string
+}
+
+// CreateRoleRequest represents a request to create a custom role. The role
+// may only be granted permissions the grantor holds.
+type CreateRoleRequest struct {
+	OrgID       uuid.UUID
+	Name        string
+	Description string
+	Permissions []models.Permission
+	CreatedBy   *uuid.UUID
+	Grantor     Grantor
+}
+
+// UpdateRoleRequest represents a request to change a role's permissions.
+// Updating a built-in role overrides it for the organization. The role may
+// only be granted permissions the grantor holds.
+type UpdateRoleRequest struct {
+	OrgID       uuid.UUID
+	Name        string
+	Description *string // Unchanged when nil
+	Permissions []models.Permission
+	UpdatedBy   *uuid.UUID
+	Grantor     Grantor
+}
+
+// orgRoles caches the permissions of an organization's effective roles
+type orgRoles struct {
+	permissions map[string][]models.Permission
+	loadedAt    time.Time
+}
+
+// RBACService resolves role permissions per organization and manages custom roles
+type RBACService struct {
+	roleRepo     repositories.RoleRepository
+	auditService *audit.AuditService
+	logger       *zap.Logger
+	ttl          time.Duration
+	now          func() time.Time
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]*orgRoles
+}
+
+// NewRBACService creates a new RBACService instance
+func NewRBACService(roleRepo repositories.RoleRepository, logger *zap.Logger) *RBACService {
+	return &RBACService{
+		roleRepo: roleRepo,
+		logger:   logger,
+		ttl:      DefaultCacheTTL,
+		now:      time.Now,
+		cache:    make(map[uuid.UUID]*orgRoles),
+	}
+}
+
+// SetAuditService records role changes in the audit log
+func (s *RBACService) SetAuditService(auditService *audit.AuditService) {
+	s.auditService = auditService
+}
+
+// HasPermission reports whether any of the roles grants permission within the organization
+func (s *RBACService) HasPermission(ctx context.Context, orgID uuid.UUID, roles []string, permission models.Permission) (bool, error) {
+	if models.HasSuperAdminRole(roles) {
+		return true, nil
+	}
+	permissions, err := s.permissions(ctx, orgID)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range roles {
+		if models.Grants(permissions[role], permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ListRoles lists the organization's effective roles by name: its own roles,
+// and the built-in roles it does not override
+func (s *RBACService) ListRoles(ctx context.Context, orgID uuid.UUID) ([]*models.Role, error) {
+	roles, err := s.effectiveRoles(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*models.Role, 0, len(roles))
+	for _, role := range roles {
+		list = append(list, role)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// CreateRole creates a custom role for the organization
+func (s *RBACService) CreateRole(ctx context.Context, req CreateRoleRequest) (*models.Role, error) {
+	role, err := models.NewRole(&req.OrgID, req.Name, req.Description, req.Permissions)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), nil)
+	}
+	if err := s.checkGrant(ctx, req.OrgID, req.Permissions, req.Grantor); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.effectiveRoles(ctx, req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	if _, exists := roles[req.Name]; exists {
+		return nil, services.NewDomainError(services.ErrorTypeConflict, fmt.Sprintf("role %q already exists", req.Name), nil)
+	}
+
+	if err := s.roleRepo.Create(ctx, role); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create role", err)
+	}
+	s.InvalidateCache(req.OrgID)
+
+	s.logger.Info("role created",
+		zap.String("org_id", req.OrgID.String()),
+		zap.String("name", role.Name))
+	if s.auditService != nil {
+		if err := s.auditService.LogRoleCreated(req.OrgID, role, req.CreatedBy); err != nil {
+			s.logger.Error("failed to log role creation", zap.Error(err))
+		}
+	}
+
+	return role, nil
+}
+
+// UpdateRole changes a role's permissions; a built-in role is overridden for
+// the organization. The admin role cannot be changed, so that an organization
+// cannot lock itself out.
+func (s *RBACService) UpdateRole(ctx context.Context, req UpdateRoleRequest) (*models.Role, error) {
+	if req.Name == string(models.RoleAdmin) {
+		return nil, services.NewDomainError(services.ErrorTypeForbidden, "the admin role cannot be changed", nil)
+	}
+
+	roles, err := s.effectiveRoles(ctx, req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	existing, ok := roles[req.Name]
+	if !ok {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "role not found", nil)
+	}
+
+	description := existing.Description
+	if req.Description != nil {
+		description = *req.Description
+	}
+
+	var role *models.Role
+	if existing.IsBuiltIn() {
+		role, err = models.NewRole(&req.OrgID, req.Name, description, req.Permissions)
+		if err != nil {
+			return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), nil)
+		}
+	} else {
+		role = existing
+		if err := role.SetPermissions(req.Permissions); err != nil {
+			return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), nil)
+		}
+		role.Description = description
+		role.UpdatedAt = s.now()
+	}
+	if err := s.checkGrant(ctx, req.OrgID, req.Permissions, req.Grantor); err != nil {
+		return nil, err
+	}
+
+	if existing.IsBuiltIn() {
+		err = s.roleRepo.Create(ctx, role)
+	} else {
+		err = s.roleRepo.Update(ctx, role)
+	}
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to update role", err)
+	}
+	s.InvalidateCache(req.OrgID)
+
+	s.logger.Info("role updated",
+		zap.String("org_id", req.OrgID.String()),
+		zap.String("name", role.Name))
+	if s.auditService != nil {
+		if err := s.auditService.LogRoleUpdated(req.OrgID, role, req.UpdatedBy); err != nil {
+			s.logger.Error("failed to log role update", zap.Error(err))
+		}
+	}
+
+	return role, nil
+}
+
+// DeleteRole deletes an organization's role; deleting the override of a
+// built-in role restores the built-in permissions. Users keeping a deleted
+// custom role are left without its permissions.
+func (s *RBACService) DeleteRole(ctx context.Context, orgID uuid.UUID, name string, deletedBy *uuid.UUID) error {
+	roles, err := s.effectiveRoles(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	role, ok := roles[name]
+	if !ok {
+		return services.NewDomainError(services.ErrorTypeNotFound, "role not found", nil)
+	}
+	if role.IsBuiltIn() {
+		return services.NewDomainError(services.ErrorTypeForbidden, "built-in roles cannot be deleted", nil)
+	}
+
+	if err := s.roleRepo.Delete(ctx, role.ID); err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to delete role", err)
+	}
+	s.InvalidateCache(orgID)
+
+	s.logger.Info("role deleted",
+		zap.String("org_id", orgID.String()),
+		zap.String("name", name))
+	if s.auditService != nil {
+		if err := s.auditService.LogRoleDeleted(orgID, role, deletedBy); err != nil {
+			s.logger.Error("failed to log role deletion", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// checkGrant ensures the grantor holds every permission a role would be
+// granted, wildcards included, so that roles cannot be used to escalate
+// privileges
+func (s *RBACService) checkGrant(ctx context.Context, orgID uuid.UUID, permissions []models.Permission, grantor Grantor) error {
+	for _, permission := range permissions {
+		held, err := s.HasPermission(ctx, orgID, grantor.Roles, permission)
+		if err != nil {
+			return err
+		}
+		if !held || (len(grantor.Scopes) > 0 && !scopesGrant(grantor.Scopes, permission)) {
+			return services.NewDomainError(services.ErrorTypeForbidden, "cannot grant a permission you do not hold: "+string(permission), nil)
+		}
+	}
+	return nil
+}
+
+// scopesGrant reports whether a token's scopes include permission
+func scopesGrant(scopes []string, permission models.Permission) bool {
+	permissions := make([]models.Permission, 0, len(scopes))
+	for _, scope := range scopes {
+		permissions = append(permissions, models.Permission(scope))
+	}
+	return models.Grants(permissions, permission)
+}
+
+// InvalidateCache drops the cached role mappings of an organization
+func (s *RBACService) InvalidateCache(orgID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, orgID)
+}
+
+// effectiveRoles loads the organization's roles by name, its own roles
+// overriding built-in ones
+func (s *RBACService) effectiveRoles(ctx context.Context, orgID uuid.UUID) (map[string]*models.Role, error) {
+	list, err := s.roleRepo.ListByOrg(ctx, orgID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list roles", err)
+	}
+
+	roles := make(map[string]*models.Role, len(list))
+	for _, role := range list {
+		if existing, ok := roles[role.Name]; ok && !existing.IsBuiltIn() {
+			continue
+		}
+		roles[role.Name] = role
+	}
+	return roles, nil
+}
+
+// permissions returns the permissions of the organization's roles, from the
+// cache when fresh
+func (s *RBACService) permissions(ctx context.Context, orgID uuid.UUID) (map[string][]models.Permission, error) {
+	s.mu.RLock()
+	cached, ok := s.cache[orgID]
+	s.mu.RUnlock()
+	if ok && s.now().Sub(cached.loadedAt) < s.ttl {
+		return cached.permissions, nil
+	}
+
+	roles, err := s.effectiveRoles(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(map[string][]models.Permission, len(roles))
+	for name, role := range roles {
+		granted, err := role.GetPermissions()
+		if err != nil {
+			s.logger.Error("invalid role permissions",
+				zap.String("org_id", orgID.String()),
+				zap.String("role", name),
+				zap.Error(err))
+			continue
+		}
+		permissions[name] = granted
+	}
+
+	s.mu.Lock()
+	s.cache[orgID] = &orgRoles{permissions: permissions, loadedAt: s.now()}
+	s.mu.Unlock()
+
+	return permissions, nil
+}
@@ -0,0 +1,265 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"go.uber.org/zap"
+)
+
+// fakeRoleRepository stores roles in memory and counts list queries
+type fakeRoleRepository struct {
+	repositories.RoleRepository
+	roles []*models.Role
+	lists int
+}
+
+func newFakeRoleRepository() *fakeRoleRepository {
+	r := &fakeRoleRepository{}
+	for _, builtIn := range models.BuiltInRoles {
+		role, _ := models.NewRole(nil, string(builtIn.Name), builtIn.Description, builtIn.Permissions)
+		r.roles = append(r.roles, role)
+	}
+	return r
+}
+
+func (r *fakeRoleRepository) Create(ctx context.Context, role *models.Role) error {
+	copied := *role
+	r.roles = append(r.roles, &copied)
+	return nil
+}
+
+func (r *fakeRoleRepository) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*models.Role, error) {
+	r.lists++
+	var roles []*models.Role
+	for _, role := range r.roles {
+		if role.OrgID == nil || *role.OrgID == orgID {
+			copied := *role
+			roles = append(roles, &copied)
+		}
+	}
+	return roles, nil
+}
+
+func (r *fakeRoleRepository) Update(ctx context.Context, role *models.Role) error {
+	for i, existing := range r.roles {
+		if existing.ID == role.ID {
+			copied := *role
+			r.roles[i] = &copied
+			return nil
+		}
+	}
+	return errors.New("role not found")
+}
+
+func (r *fakeRoleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	for i, existing := range r.roles {
+		if existing.ID == id {
+			r.roles = append(r.roles[:i], r.roles[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("role not found")
+}
+
+func TestRBACService_HasPermission(t *testing.T) {
+	ctx := context.Background()
+	orgID := uuid.New()
+	repo := newFakeRoleRepository()
+	service := NewRBACService(repo, zap.NewNop())
+	now := time.Now()
+	service.now = func() time.Time { return now }
+
+	allowed, err := service.HasPermission(ctx, orgID, []string{"viewer"}, models.PermissionPoliciesRead)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = service.HasPermission(ctx, orgID, []string{"viewer"}, models.PermissionPoliciesWrite)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	allowed, err = service.HasPermission(ctx, orgID, []string{"admin"}, models.PermissionBudgetsOverride)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = service.HasPermission(ctx, orgID, []string{"developer"}, models.PermissionPoliciesRead)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	t.Run("cached until the TTL", func(t *testing.T) {
+		lists := repo.lists
+		_, err := service.HasPermission(ctx, orgID, []string{"viewer"}, models.PermissionPoliciesRead)
+		require.NoError(t, err)
+		assert.Equal(t, lists, repo.lists)
+
+		now = now.Add(DefaultCacheTTL)
+		_, err = service.HasPermission(ctx, orgID, []string{"viewer"}, models.PermissionPoliciesRead)
+		require.NoError(t, err)
+		assert.Equal(t, lists+1, repo.lists)
+	})
+}
+
+func TestRBACService_CustomRoles(t *testing.T) {
+	ctx := context.Background()
+	orgID, otherOrgID := uuid.New(), uuid.New()
+	service := NewRBACService(newFakeRoleRepository(), zap.NewNop())
+	admin := Grantor{Roles: []string{"admin"}}
+
+	// Warm the cache, so that the changes below must invalidate it
+	allowed, err := service.HasPermission(ctx, orgID, []string{"auditor"}, models.PermissionAuditRead)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	role, err := service.CreateRole(ctx, CreateRoleRequest{
+		OrgID:       orgID,
+		Name:        "auditor",
+		Permissions: []models.Permission{models.PermissionAuditRead},
+		Grantor:     admin,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, orgID, *role.OrgID)
+
+	allowed, err = service.HasPermission(ctx, orgID, []string{"auditor"}, models.PermissionAuditRead)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, err = service.HasPermission(ctx, otherOrgID, []string{"auditor"}, models.PermissionAuditRead)
+	require.NoError(t, err)
+	assert.False(t, allowed, "custom roles belong to their organization")
+
+	t.Run("duplicate name", func(t *testing.T) {
+		_, err := service.CreateRole(ctx, CreateRoleRequest{OrgID: orgID, Name: "viewer"})
+
+		assert.True(t, services.IsConflictError(err))
+	})
+
+	t.Run("unknown permission", func(t *testing.T) {
+		_, err := service.CreateRole(ctx, CreateRoleRequest{
+			OrgID:       orgID,
+			Name:        "operator",
+			Permissions: []models.Permission{"clusters:write"},
+		})
+
+		assert.True(t, services.IsValidationError(err))
+	})
+
+	t.Run("cannot grant permissions the grantor lacks", func(t *testing.T) {
+		member := Grantor{Roles: []string{"member"}}
+
+		_, err := service.CreateRole(ctx, CreateRoleRequest{
+			OrgID:       orgID,
+			Name:        "superuser",
+			Permissions: []models.Permission{models.PermissionAll},
+			Grantor:     member,
+		})
+		assert.True(t, services.IsForbiddenError(err))
+
+		_, err = service.UpdateRole(ctx, UpdateRoleRequest{
+			OrgID:       orgID,
+			Name:        "member",
+			Permissions: []models.Permission{models.PermissionAll},
+			Grantor:     member,
+		})
+		assert.True(t, services.IsForbiddenError(err))
+
+		_, err = service.CreateRole(ctx, CreateRoleRequest{
+			OrgID:       orgID,
+			Name:        "superuser",
+			Permissions: []models.Permission{models.PermissionAuditRead},
+			Grantor:     Grantor{Roles: []string{"admin"}, Scopes: []string{"roles:write"}},
+		})
+		assert.True(t, services.IsForbiddenError(err), "scoped tokens may only grant their scopes")
+
+		allowed, err := service.HasPermission(ctx, orgID, []string{"member"}, models.PermissionRolesWrite)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		_, err := service.UpdateRole(ctx, UpdateRoleRequest{
+			OrgID:       orgID,
+			Name:        "auditor",
+			Permissions: []models.Permission{models.PermissionAuditRead, models.PermissionPoliciesRead},
+			Grantor:     admin,
+		})
+		require.NoError(t, err)
+
+		allowed, err := service.HasPermission(ctx, orgID, []string{"auditor"}, models.PermissionPoliciesRead)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, service.DeleteRole(ctx, orgID, "auditor", nil))
+
+		allowed, err := service.HasPermission(ctx, orgID, []string{"auditor"}, models.PermissionAuditRead)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		assert.True(t, services.IsNotFoundError(service.DeleteRole(ctx, orgID, "auditor", nil)))
+	})
+}
+
+func TestRBACService_BuiltInRoles(t *testing.T) {
+	ctx := context.Background()
+	orgID, otherOrgID := uuid.New(), uuid.New()
+	service := NewRBACService(newFakeRoleRepository(), zap.NewNop())
+	admin := Grantor{Roles: []string{"admin"}}
+
+	t.Run("override for one organization", func(t *testing.T) {
+		role, err := service.UpdateRole(ctx, UpdateRoleRequest{
+			OrgID:       orgID,
+			Name:        "viewer",
+			Permissions: []models.Permission{models.PermissionPoliciesRead, models.PermissionAuditRead},
+			Grantor:     admin,
+		})
+		require.NoError(t, err)
+		assert.False(t, role.IsBuiltIn())
+		assert.Equal(t, "Read-only access", role.Description)
+
+		allowed, err := service.HasPermission(ctx, orgID, []string{"viewer"}, models.PermissionAuditRead)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+
+		allowed, err = service.HasPermission(ctx, otherOrgID, []string{"viewer"}, models.PermissionAuditRead)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		roles, err := service.ListRoles(ctx, orgID)
+		require.NoError(t, err)
+		require.Len(t, roles, len(models.BuiltInRoles))
+		for _, role := range roles {
+			if role.Name == "viewer" {
+				assert.False(t, role.IsBuiltIn())
+			}
+		}
+	})
+
+	t.Run("deleting the override restores the default", func(t *testing.T) {
+		require.NoError(t, service.DeleteRole(ctx, orgID, "viewer", nil))
+
+		allowed, err := service.HasPermission(ctx, orgID, []string{"viewer"}, models.PermissionAuditRead)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+	})
+
+	t.Run("built-in roles cannot be deleted", func(t *testing.T) {
+		err := service.DeleteRole(ctx, orgID, "member", nil)
+
+		assert.True(t, services.IsForbiddenError(err))
+	})
+
+	t.Run("admin cannot be changed", func(t *testing.T) {
+		_, err := service.UpdateRole(ctx, UpdateRoleRequest{OrgID: orgID, Name: "admin"})
+
+		assert.True(t, services.IsForbiddenError(err))
+	})
+}
@@ -0,0 +1,150 @@
+package routing
+
+import (
+	"context"
+	"time"
+
+	"github.com/upb/llm-control-plane/backend/services/providers"
+)
+
+// DefaultHedgePercentile is used when a hedge policy does not set a percentile
+const DefaultHedgePercentile = 95.0
+
+// HedgeAttempt records the outcome of one attempt of a hedged request
+type HedgeAttempt struct {
+	Provider  providers.Provider
+	Response  *providers.ChatResponse
+	Err       error
+	Latency   time.Duration
+	Hedged    bool // True for the second attempt sent after the hedge delay
+	Cancelled bool // True if the attempt was cut short because the other one won or the request ended
+}
+
+// HedgeResult represents the outcome of a hedged request
+type HedgeResult struct {
+	// Winner is the first attempt to succeed
+	Winner *HedgeAttempt
+
+	// Failed lists attempts that returned an error before a winner was found
+	Failed []*HedgeAttempt
+
+	// Pending delivers the attempts still in flight when the result was
+	// returned, once each returns, and is then closed: the cancelled loser,
+	// or every attempt if the request's context ended first. Nil if none
+	// were in flight.
+	Pending <-chan *HedgeAttempt
+}
+
+// HedgeDelay returns how long to wait for a provider before hedging: the given
+// percentile of its observed latency, but never less than minDelay.
+func (s *RoutingService) HedgeDelay(providerName string, percentile float64, minDelay time.Duration) time.Duration {
+	if percentile <= 0 {
+		percentile = DefaultHedgePercentile
+	}
+
+	delay, ok := s.LatencyPercentile(providerName, percentile)
+	if !ok || delay < minDelay {
+		return minDelay
+	}
+	return delay
+}
+
+// ExecuteHedged sends the request to the primary provider and, if it has not
+// answered within delay, to the hedge provider as well. The first successful
+// response wins and the other attempt is cancelled through its context. If the
+// primary fails before the delay, the hedge is sent immediately. The latency
+// of every attempt is recorded, cancelled ones for as long as they ran, so a
+// slow primary that loses still raises its hedge delay.
+func (s *RoutingService) ExecuteHedged(ctx context.Context, primary, hedge providers.Provider, req *providers.ChatRequest, delay time.Duration) (*HedgeResult, error) {
+	results := make(chan *HedgeAttempt, 2)
+	var cancels []context.CancelFunc
+	defer func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+
+	launch := func(provider providers.Provider, hedged bool) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+
+		go func() {
+			start := time.Now()
+			resp, err := provider.ChatCompletion(attemptCtx, req)
+			attempt := &HedgeAttempt{
+				Provider: provider,
+				Response: resp,
+				Err:      err,
+				Latency:  time.Since(start),
+				Hedged:   hedged,
+			}
+			if err != nil && attemptCtx.Err() != nil {
+				attempt.Cancelled = true
+			}
+			if err == nil || attempt.Cancelled {
+				s.ObserveLatency(provider.Name(), attempt.Latency)
+			}
+			results <- attempt
+		}()
+	}
+
+	launch(primary, false)
+	inFlight := 1
+	hedgeLaunched := hedge == nil
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var failed []*HedgeAttempt
+	for {
+		select {
+		case <-timer.C:
+			if !hedgeLaunched {
+				launch(hedge, true)
+				hedgeLaunched = true
+				inFlight++
+			}
+
+		case attempt := <-results:
+			inFlight--
+
+			if attempt.Err == nil {
+				return &HedgeResult{Winner: attempt, Failed: failed, Pending: drain(results, inFlight)}, nil
+			}
+
+			failed = append(failed, attempt)
+			if !hedgeLaunched {
+				// Primary failed early; no reason to wait for the delay
+				launch(hedge, true)
+				hedgeLaunched = true
+				inFlight++
+				continue
+			}
+			if inFlight == 0 {
+				return &HedgeResult{Failed: failed}, attempt.Err
+			}
+
+		case <-ctx.Done():
+			// Attempts in flight are cancelled on return but were still sent,
+			// so they are handed over to be charged and audited
+			return &HedgeResult{Failed: failed, Pending: drain(results, inFlight)}, ctx.Err()
+		}
+	}
+}
+
+// drain forwards the n attempts still in flight and then closes the returned
+// channel; nil if there are none
+func drain(results <-chan *HedgeAttempt, n int) <-chan *HedgeAttempt {
+	if n == 0 {
+		return nil
+	}
+
+	pending := make(chan *HedgeAttempt, n)
+	go func() {
+		defer close(pending)
+		for i := 0; i < n; i++ {
+			pending <- <-results
+		}
+	}()
+	return pending
+}
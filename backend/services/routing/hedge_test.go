@@ -0,0 +1,164 @@
+package routing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+)
+
+// delayedProvider answers after a fixed delay unless its context is cancelled
+type delayedProvider struct {
+	name  string
+	delay time.Duration
+	err   error
+}
+
+func (p *delayedProvider) Name() string { return p.name }
+
+func (p *delayedProvider) ChatCompletion(ctx context.Context, req *providers.ChatRequest) (*providers.ChatResponse, error) {
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &providers.ChatResponse{Model: req.Model, Provider: p.name}, nil
+}
+
+func (p *delayedProvider) IsAvailable(ctx context.Context) bool { return true }
+
+func (p *delayedProvider) EstimateCost(req *providers.ChatRequest) (float64, error) { return 0, nil }
+
+func (p *delayedProvider) ValidateModel(model string) error { return nil }
+
+func (p *delayedProvider) GetModelInfo(model string) (*providers.ModelInfo, error) {
+	return &providers.ModelInfo{ID: model}, nil
+}
+
+func (p *delayedProvider) ListModels() []string { return nil }
+
+func newHedgeTestService() *RoutingService {
+	return NewRoutingService(DefaultRoutingConfig(), providers.NewRegistry())
+}
+
+func TestExecuteHedged_PrimaryWinsBeforeDelay(t *testing.T) {
+	s := newHedgeTestService()
+	primary := &delayedProvider{name: "primary", delay: 5 * time.Millisecond}
+	hedge := &delayedProvider{name: "hedge", delay: time.Millisecond}
+
+	result, err := s.ExecuteHedged(context.Background(), primary, hedge, &providers.ChatRequest{Model: "m"}, time.Second)
+
+	require.NoError(t, err)
+	assert.Equal(t, "primary", result.Winner.Provider.Name())
+	assert.False(t, result.Winner.Hedged)
+	assert.Nil(t, result.Pending, "hedge should never have been sent")
+}
+
+func TestExecuteHedged_HedgeWinsAndPrimaryIsCancelled(t *testing.T) {
+	s := newHedgeTestService()
+	primary := &delayedProvider{name: "primary", delay: time.Second}
+	hedge := &delayedProvider{name: "hedge", delay: 5 * time.Millisecond}
+
+	result, err := s.ExecuteHedged(context.Background(), primary, hedge, &providers.ChatRequest{Model: "m"}, 10*time.Millisecond)
+
+	require.NoError(t, err)
+	assert.Equal(t, "hedge", result.Winner.Provider.Name())
+	assert.True(t, result.Winner.Hedged)
+	require.NotNil(t, result.Pending)
+
+	select {
+	case loser := <-result.Pending:
+		assert.Equal(t, "primary", loser.Provider.Name())
+		assert.True(t, loser.Cancelled)
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("losing attempt was not cancelled")
+	}
+
+	// The slow primary's latency is recorded although it lost
+	latency, ok := s.LatencyPercentile("primary", 100)
+	require.True(t, ok)
+	assert.GreaterOrEqual(t, latency, 5*time.Millisecond)
+}
+
+func TestExecuteHedged_RequestEndsWithAttemptsInFlight(t *testing.T) {
+	s := newHedgeTestService()
+	primary := &delayedProvider{name: "primary", delay: time.Second}
+	hedge := &delayedProvider{name: "hedge", delay: time.Second}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	result, err := s.ExecuteHedged(ctx, primary, hedge, &providers.ChatRequest{Model: "m"}, 5*time.Millisecond)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NotNil(t, result.Pending)
+
+	var names []string
+	for attempt := range result.Pending {
+		assert.True(t, attempt.Cancelled, "in-flight attempts are charged as cancelled")
+		names = append(names, attempt.Provider.Name())
+	}
+	assert.ElementsMatch(t, []string{"primary", "hedge"}, names)
+}
+
+func TestExecuteHedged_PrimaryErrorHedgesImmediately(t *testing.T) {
+	s := newHedgeTestService()
+	primary := &delayedProvider{name: "primary", err: errors.New("boom")}
+	hedge := &delayedProvider{name: "hedge"}
+
+	result, err := s.ExecuteHedged(context.Background(), primary, hedge, &providers.ChatRequest{Model: "m"}, time.Minute)
+
+	require.NoError(t, err)
+	assert.Equal(t, "hedge", result.Winner.Provider.Name())
+	require.Len(t, result.Failed, 1)
+	assert.Equal(t, "primary", result.Failed[0].Provider.Name())
+	assert.False(t, result.Failed[0].Cancelled)
+}
+
+func TestExecuteHedged_BothFail(t *testing.T) {
+	s := newHedgeTestService()
+	primary := &delayedProvider{name: "primary", err: errors.New("primary down")}
+	hedge := &delayedProvider{name: "hedge", err: errors.New("hedge down")}
+
+	result, err := s.ExecuteHedged(context.Background(), primary, hedge, &providers.ChatRequest{Model: "m"}, time.Minute)
+
+	assert.EqualError(t, err, "hedge down")
+	assert.Nil(t, result.Winner)
+	assert.Len(t, result.Failed, 2)
+}
+
+func TestHedgeDelay(t *testing.T) {
+	s := newHedgeTestService()
+
+	// No observations yet: the minimum delay applies
+	assert.Equal(t, 50*time.Millisecond, s.HedgeDelay("openai", 95, 50*time.Millisecond))
+
+	for i := 1; i <= 100; i++ {
+		s.RecordLatency("openai", time.Duration(i)*time.Millisecond)
+	}
+
+	assert.Equal(t, 95*time.Millisecond, s.HedgeDelay("openai", 95, 10*time.Millisecond))
+	assert.Equal(t, 50*time.Millisecond, s.HedgeDelay("openai", 50, 10*time.Millisecond))
+	assert.Equal(t, 95*time.Millisecond, s.HedgeDelay("openai", 0, 10*time.Millisecond), "defaults to p95")
+	assert.Equal(t, 200*time.Millisecond, s.HedgeDelay("openai", 95, 200*time.Millisecond), "floor applies")
+}
+
+func TestLatencyWindow_Wraps(t *testing.T) {
+	w := &latencyWindow{}
+	for i := 0; i < latencyWindowSize; i++ {
+		w.add(time.Second)
+	}
+	for i := 0; i < latencyWindowSize; i++ {
+		w.add(time.Millisecond)
+	}
+
+	p, ok := w.percentile(100)
+	require.True(t, ok)
+	assert.Equal(t, time.Millisecond, p, "old samples should be overwritten")
+}
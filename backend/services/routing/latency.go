@@ -0,0 +1,87 @@
+package routing
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyWindowSize is the number of recent samples kept per provider
+const latencyWindowSize = 200
+
+// latencyWindow keeps a ring buffer of recent latency samples for one provider
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// add records a latency sample, overwriting the oldest when full
+func (w *latencyWindow) add(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.samples) < latencyWindowSize {
+		w.samples = append(w.samples, d)
+		return
+	}
+	w.samples[w.next] = d
+	w.next = (w.next + 1) % latencyWindowSize
+}
+
+// percentile returns the nearest-rank percentile (0-100) of recorded samples
+func (w *latencyWindow) percentile(p float64) (time.Duration, bool) {
+	w.mu.Lock()
+	sorted := append([]time.Duration(nil), w.samples...)
+	w.mu.Unlock()
+
+	if len(sorted) == 0 {
+		return 0, false
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	if p <= 0 {
+		return sorted[0], true
+	}
+	if p >= 100 {
+		return sorted[len(sorted)-1], true
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[rank-1], true
+}
+
+// ObserveLatency records an attempt's latency if latency tracking is enabled.
+// Attempts cut short are recorded for as long as they ran, a lower bound that
+// still keeps slow providers from looking fast.
+func (s *RoutingService) ObserveLatency(providerName string, latency time.Duration) {
+	if s.config.EnableLatencyTracking {
+		s.RecordLatency(providerName, latency)
+	}
+}
+
+// RecordLatency records an observed latency for a provider
+func (s *RoutingService) RecordLatency(providerName string, latency time.Duration) {
+	s.latencyMu.Lock()
+	window, ok := s.latencyWindows[providerName]
+	if !ok {
+		window = &latencyWindow{}
+		s.latencyWindows[providerName] = window
+	}
+	s.latencyMu.Unlock()
+
+	window.add(latency)
+}
+
+// LatencyPercentile returns the given percentile of a provider's recent latency.
+// Returns false if no latency has been observed for the provider yet.
+func (s *RoutingService) LatencyPercentile(providerName string, p float64) (time.Duration, bool) {
+	s.latencyMu.Lock()
+	window, ok := s.latencyWindows[providerName]
+	s.latencyMu.Unlock()
+
+	if !ok {
+		return 0, false
+	}
+	return window.percentile(p)
+}
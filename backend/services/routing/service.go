@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/upb/llm-control-plane/backend/services/providers"
@@ -83,6 +84,8 @@ type RoutingService struct {
 	latencyTracker  map[string]time.Duration
 	requestCounter  map[string]int
 	roundRobinIndex map[string]int
+	latencyWindows  map[string]*latencyWindow
+	latencyMu       sync.Mutex
 }
 
 // NewRoutingService creates a new routing service
@@ -93,6 +96,7 @@ func NewRoutingService(config RoutingConfig, registry *providers.Registry) *Rout
 		latencyTracker:  make(map[string]time.Duration),
 		requestCounter:  make(map[string]int),
 		roundRobinIndex: make(map[string]int),
+		latencyWindows:  make(map[string]*latencyWindow),
 	}
 }
 
@@ -151,6 +155,11 @@ func (s *RoutingService) GetProviderForModel(model string) (providers.Provider,
 	return s.registry.GetProviderForModel(model)
 }
 
+// GetProvider returns a provider by name
+func (s *RoutingService) GetProvider(name string) (providers.Provider, error) {
+	return s.registry.GetProvider(name)
+}
+
 // selectProvider selects a provider based on the configured strategy
 func (s *RoutingService) selectProvider(ctx context.Context, req *providers.ChatRequest) (providers.Provider, error) {
 	strategy := s.config.DefaultStrategy
@@ -392,6 +401,7 @@ func (s *RoutingService) executeRequest(ctx context.Context, provider providers.
 		if s.config.EnableLatencyTracking {
 			latency := time.Since(startTime)
 			s.latencyTracker[provider.Name()] = latency
+			s.RecordLatency(provider.Name(), latency)
 		}
 
 		// Track request count
@@ -424,6 +434,10 @@ func (s *RoutingService) ResetStats() {
 	s.latencyTracker = make(map[string]time.Duration)
 	s.requestCounter = make(map[string]int)
 	s.roundRobinIndex = make(map[string]int)
+
+	s.latencyMu.Lock()
+	s.latencyWindows = make(map[string]*latencyWindow)
+	s.latencyMu.Unlock()
 }
 
 // SetStrategy updates the default routing strategy
@@ -0,0 +1,723 @@
+// Package scim provisions organizations' users and groups from their identity
+// providers over SCIM 2.0. Each organization's identity provider
+// authenticates with its own bearer token; groups grant the roles the
+// organization maps them to, and deactivating or deleting a user revokes the
+// user's sessions and API keys at once.
+package scim
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/audit"
+	"go.uber.org/zap"
+)
+
+const (
+	// TokenPrefix starts every SCIM bearer token
+	TokenPrefix = "lcpscim_"
+
+	// DefaultRole is the role of provisioned users in no mapped group
+	DefaultRole = models.RoleViewer
+
+	// MaxNameLength bounds user names, display names and external IDs
+	MaxNameLength = 255
+
+	// tokenBytes is the number of random bytes of a token
+	tokenBytes = 32
+)
+
+// CreatedToken is returned when a token is issued; the token is only shown
+// at this point
+type CreatedToken struct {
+	Token     string    `json:"token"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserInput is a user as provisioned by the identity provider
+type UserInput struct {
+	UserName    string // The user's email
+	ExternalID  string
+	DisplayName string
+	Active      bool
+}
+
+// UserFilter selects users by user name or external ID; empty fields match all users
+type UserFilter struct {
+	UserName   string
+	ExternalID string
+}
+
+// RoleLister lists an organization's effective roles, built-in and custom
+type RoleLister interface {
+	ListRoles(ctx context.Context, orgID uuid.UUID) ([]*models.Role, error)
+}
+
+// GroupInput is a group as provisioned by the identity provider
+type GroupInput struct {
+	DisplayName string
+	ExternalID  string
+	Members     []uuid.UUID
+}
+
+// SCIMService manages organizations' SCIM configurations and provisions
+// their users and groups
+type SCIMService struct {
+	configRepo   repositories.SCIMConfigRepository
+	userRepo     repositories.UserRepository
+	groupRepo    repositories.SCIMGroupRepository
+	sessionRepo  repositories.SessionRepository
+	keyRepo      repositories.APIKeyRepository
+	txManager    repositories.TransactionManager
+	roles        RoleLister
+	auditService *audit.AuditService
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewSCIMService creates a new SCIMService instance
+func NewSCIMService(
+	configRepo repositories.SCIMConfigRepository,
+	userRepo repositories.UserRepository,
+	groupRepo repositories.SCIMGroupRepository,
+	sessionRepo repositories.SessionRepository,
+	keyRepo repositories.APIKeyRepository,
+	txManager repositories.TransactionManager,
+	roles RoleLister,
+	logger *zap.Logger,
+) *SCIMService {
+	return &SCIMService{
+		configRepo:  configRepo,
+		userRepo:    userRepo,
+		groupRepo:   groupRepo,
+		sessionRepo: sessionRepo,
+		keyRepo:     keyRepo,
+		txManager:   txManager,
+		roles:       roles,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// SetAuditService sets the audit service for logging provisioning
+func (s *SCIMService) SetAuditService(auditService *audit.AuditService) {
+	s.auditService = auditService
+}
+
+// HashToken returns the hash under which a token is stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetConfig returns an organization's SCIM configuration; organizations that
+// never configured SCIM get a disabled configuration without group mappings
+func (s *SCIMService) GetConfig(ctx context.Context, orgID uuid.UUID) (*models.SCIMConfig, error) {
+	config, err := s.configRepo.Get(ctx, orgID)
+	if err != nil {
+		return models.NewSCIMConfig(orgID), nil
+	}
+	return config, nil
+}
+
+// RotateToken issues a new bearer token for the organization's identity
+// provider; the previous token stops working at once
+func (s *SCIMService) RotateToken(ctx context.Context, orgID uuid.UUID, rotatedBy *uuid.UUID) (*CreatedToken, error) {
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := randomHex(tokenBytes)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to generate scim token", err)
+	}
+	token = TokenPrefix + token
+
+	now := s.now()
+	config.TokenHash = HashToken(token)
+	config.TokenCreatedAt = &now
+	config.UpdatedAt = now
+	if err := s.configRepo.Upsert(ctx, config); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to save scim token", err)
+	}
+
+	s.logger.Info("scim token rotated", zap.String("org_id", orgID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogSCIMTokenRotated(orgID, rotatedBy); err != nil {
+			s.logger.Error("failed to log scim token rotation", zap.Error(err))
+		}
+	}
+
+	return &CreatedToken{Token: token, CreatedAt: now}, nil
+}
+
+// RevokeToken revokes the organization's bearer token, disabling provisioning
+func (s *SCIMService) RevokeToken(ctx context.Context, orgID uuid.UUID, revokedBy *uuid.UUID) error {
+	config, err := s.configRepo.Get(ctx, orgID)
+	if err != nil || !config.Enabled() {
+		return services.NewDomainError(services.ErrorTypeNotFound, "scim token not found", err)
+	}
+
+	config.TokenHash = ""
+	config.TokenCreatedAt = nil
+	config.UpdatedAt = s.now()
+	if err := s.configRepo.Upsert(ctx, config); err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to revoke scim token", err)
+	}
+
+	s.logger.Info("scim token revoked", zap.String("org_id", orgID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogSCIMTokenRevoked(orgID, revokedBy); err != nil {
+			s.logger.Error("failed to log scim token revocation", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// SetGroupRoles maps group display names to the roles their members get and
+// updates the roles of all group members. Without mappings, provisioning
+// leaves users' roles alone.
+func (s *SCIMService) SetGroupRoles(ctx context.Context, orgID uuid.UUID, groupRoles map[string]models.UserRole, updatedBy *uuid.UUID) (*models.SCIMConfig, error) {
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	ranks, err := s.roleRanks(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	for group, role := range groupRoles {
+		if _, ok := ranks[role]; !ok {
+			return nil, services.NewDomainError(services.ErrorTypeValidation, fmt.Sprintf("invalid role %q for group %q", role, group), nil)
+		}
+	}
+	if err := config.SetGroupRoles(groupRoles); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), err)
+	}
+	config.UpdatedAt = s.now()
+	if err := s.configRepo.Upsert(ctx, config); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to save scim group roles", err)
+	}
+
+	s.logger.Info("scim group roles updated", zap.String("org_id", orgID.String()), zap.Int("groups", len(groupRoles)))
+	if s.auditService != nil {
+		if err := s.auditService.LogSCIMGroupRolesUpdated(orgID, groupRoles, updatedBy); err != nil {
+			s.logger.Error("failed to log scim group roles update", zap.Error(err))
+		}
+	}
+
+	groups, err := s.groupRepo.ListByOrg(ctx, orgID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list scim groups", err)
+	}
+	var members []uuid.UUID
+	for _, group := range groups {
+		members = append(members, group.Members...)
+	}
+	s.syncRoles(ctx, orgID, members)
+
+	return config, nil
+}
+
+// Authenticate resolves a bearer token to the organization it provisions
+func (s *SCIMService) Authenticate(ctx context.Context, token string) (uuid.UUID, error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return uuid.Nil, services.NewDomainError(services.ErrorTypeUnauthorized, "invalid scim token", nil)
+	}
+	config, err := s.configRepo.GetByTokenHash(ctx, HashToken(token))
+	if err != nil {
+		return uuid.Nil, services.NewDomainError(services.ErrorTypeUnauthorized, "invalid scim token", err)
+	}
+	return config.OrgID, nil
+}
+
+// ListUsers lists the organization's users matching a filter
+func (s *SCIMService) ListUsers(ctx context.Context, orgID uuid.UUID, filter UserFilter) ([]*models.User, error) {
+	users, err := s.userRepo.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list users", err)
+	}
+
+	matched := []*models.User{}
+	for _, user := range users {
+		if filter.UserName != "" && !strings.EqualFold(user.Email, filter.UserName) {
+			continue
+		}
+		if filter.ExternalID != "" && user.ExternalID != filter.ExternalID {
+			continue
+		}
+		matched = append(matched, user)
+	}
+	return matched, nil
+}
+
+// GetUser returns one of the organization's users
+func (s *SCIMService) GetUser(ctx context.Context, orgID, id uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByOrgAndID(ctx, orgID, id)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "user not found", err)
+	}
+	return user, nil
+}
+
+// CreateUser provisions a user. Users are identified by the gateway user ID
+// their identity provider's logins carry.
+func (s *SCIMService) CreateUser(ctx context.Context, orgID uuid.UUID, input UserInput) (*models.User, error) {
+	input, err := validateUser(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUserName(ctx, orgID, uuid.Nil, input.UserName); err != nil {
+		return nil, err
+	}
+
+	user := models.NewUser(input.UserName, "", orgID, DefaultRole)
+	user.CognitoSub = user.ID.String()
+	user.ExternalID = input.ExternalID
+	user.DisplayName = input.DisplayName
+	if !input.Active {
+		user.DeactivatedAt = &user.CreatedAt
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create user", err)
+	}
+
+	s.logger.Info("user provisioned",
+		zap.String("org_id", orgID.String()),
+		zap.String("user_id", user.ID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogUserProvisioned(user); err != nil {
+			s.logger.Error("failed to log user provisioning", zap.Error(err))
+		}
+	}
+
+	return user, nil
+}
+
+// ReplaceUser replaces a user's attributes. Deactivating a user revokes the
+// user's sessions and the API keys the user created.
+func (s *SCIMService) ReplaceUser(ctx context.Context, orgID, id uuid.UUID, input UserInput) (*models.User, error) {
+	input, err := validateUser(input)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.GetUser(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkUserName(ctx, orgID, id, input.UserName); err != nil {
+		return nil, err
+	}
+
+	wasActive := user.IsActive()
+	now := s.now()
+	user.Email = input.UserName
+	user.ExternalID = input.ExternalID
+	user.DisplayName = input.DisplayName
+	user.UpdatedAt = now
+	switch {
+	case wasActive && !input.Active:
+		user.DeactivatedAt = &now
+	case !wasActive && input.Active:
+		user.DeactivatedAt = nil
+	}
+
+	var sessionsRevoked, keysRevoked int64
+	err = s.txManager.InTransaction(ctx, func(ctx context.Context, tx repositories.Transaction) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		if wasActive && !input.Active {
+			sessionsRevoked, keysRevoked, err = s.revokeCredentials(ctx, user, now)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to update user", err)
+	}
+
+	switch {
+	case wasActive && !input.Active:
+		s.logger.Info("user deactivated",
+			zap.String("org_id", orgID.String()),
+			zap.String("user_id", id.String()),
+			zap.Int64("sessions_revoked", sessionsRevoked),
+			zap.Int64("keys_revoked", keysRevoked))
+		if s.auditService != nil {
+			if err := s.auditService.LogUserDeactivated(user, sessionsRevoked, keysRevoked); err != nil {
+				s.logger.Error("failed to log user deactivation", zap.Error(err))
+			}
+		}
+	case !wasActive && input.Active:
+		s.logger.Info("user reactivated", zap.String("org_id", orgID.String()), zap.String("user_id", id.String()))
+		if s.auditService != nil {
+			if err := s.auditService.LogUserReactivated(user); err != nil {
+				s.logger.Error("failed to log user reactivation", zap.Error(err))
+			}
+		}
+	}
+
+	return user, nil
+}
+
+// DeleteUser deprovisions a user, revoking the user's sessions and the API
+// keys the user created
+func (s *SCIMService) DeleteUser(ctx context.Context, orgID, id uuid.UUID) error {
+	user, err := s.GetUser(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+
+	var sessionsRevoked, keysRevoked int64
+	err = s.txManager.InTransaction(ctx, func(ctx context.Context, tx repositories.Transaction) error {
+		sessionsRevoked, keysRevoked, err = s.revokeCredentials(ctx, user, s.now())
+		if err != nil {
+			return err
+		}
+		return s.userRepo.Delete(ctx, user.ID)
+	})
+	if err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to delete user", err)
+	}
+
+	s.logger.Info("user deprovisioned",
+		zap.String("org_id", orgID.String()),
+		zap.String("user_id", id.String()),
+		zap.Int64("sessions_revoked", sessionsRevoked),
+		zap.Int64("keys_revoked", keysRevoked))
+	if s.auditService != nil {
+		if err := s.auditService.LogUserDeleted(user, sessionsRevoked, keysRevoked); err != nil {
+			s.logger.Error("failed to log user deletion", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// ListGroups lists the organization's groups, those with a display name if one is given
+func (s *SCIMService) ListGroups(ctx context.Context, orgID uuid.UUID, displayName string) ([]*models.SCIMGroup, error) {
+	groups, err := s.groupRepo.ListByOrg(ctx, orgID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list groups", err)
+	}
+
+	matched := []*models.SCIMGroup{}
+	for _, group := range groups {
+		if displayName == "" || group.DisplayName == displayName {
+			matched = append(matched, group)
+		}
+	}
+	return matched, nil
+}
+
+// GetGroup returns one of the organization's groups
+func (s *SCIMService) GetGroup(ctx context.Context, orgID, id uuid.UUID) (*models.SCIMGroup, error) {
+	group, err := s.groupRepo.GetByID(ctx, id)
+	if err != nil || group.OrgID != orgID {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "group not found", err)
+	}
+	return group, nil
+}
+
+// CreateGroup provisions a group and updates its members' roles
+func (s *SCIMService) CreateGroup(ctx context.Context, orgID uuid.UUID, input GroupInput) (*models.SCIMGroup, error) {
+	input, err := s.validateGroup(ctx, orgID, uuid.Nil, input)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	group := &models.SCIMGroup{
+		ID:          uuid.New(),
+		OrgID:       orgID,
+		DisplayName: input.DisplayName,
+		ExternalID:  input.ExternalID,
+		Members:     input.Members,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	err = s.txManager.InTransaction(ctx, func(ctx context.Context, tx repositories.Transaction) error {
+		return s.groupRepo.Create(ctx, group)
+	})
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create group", err)
+	}
+
+	s.logger.Info("group provisioned",
+		zap.String("org_id", orgID.String()),
+		zap.String("group_id", group.ID.String()),
+		zap.Int("members", len(group.Members)))
+	s.syncRoles(ctx, orgID, group.Members)
+
+	return group, nil
+}
+
+// ReplaceGroup replaces a group's name and members and updates the roles of
+// its previous and current members
+func (s *SCIMService) ReplaceGroup(ctx context.Context, orgID, id uuid.UUID, input GroupInput) (*models.SCIMGroup, error) {
+	group, err := s.GetGroup(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	input, err = s.validateGroup(ctx, orgID, id, input)
+	if err != nil {
+		return nil, err
+	}
+
+	previous := group.Members
+	group.DisplayName = input.DisplayName
+	group.ExternalID = input.ExternalID
+	group.Members = input.Members
+	group.UpdatedAt = s.now()
+	err = s.txManager.InTransaction(ctx, func(ctx context.Context, tx repositories.Transaction) error {
+		return s.groupRepo.Update(ctx, group)
+	})
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to update group", err)
+	}
+
+	s.logger.Info("group updated",
+		zap.String("org_id", orgID.String()),
+		zap.String("group_id", id.String()),
+		zap.Int("members", len(group.Members)))
+	s.syncRoles(ctx, orgID, append(previous, group.Members...))
+
+	return group, nil
+}
+
+// DeleteGroup deletes a group and updates its members' roles
+func (s *SCIMService) DeleteGroup(ctx context.Context, orgID, id uuid.UUID) error {
+	group, err := s.GetGroup(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.groupRepo.Delete(ctx, id); err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to delete group", err)
+	}
+
+	s.logger.Info("group deleted", zap.String("org_id", orgID.String()), zap.String("group_id", id.String()))
+	s.syncRoles(ctx, orgID, group.Members)
+
+	return nil
+}
+
+// revokeCredentials revokes a user's sessions and the API keys the user
+// created. Sessions are found by the gateway user ID and by the subject the
+// user's Cognito logins carry.
+func (s *SCIMService) revokeCredentials(ctx context.Context, user *models.User, at time.Time) (int64, int64, error) {
+	byUser, err := s.sessionRepo.RevokeByUser(ctx, user.OrgID, user.ID, at)
+	if err != nil {
+		return 0, 0, err
+	}
+	bySubject, err := s.sessionRepo.RevokeBySubject(ctx, user.OrgID, user.CognitoSub, at)
+	if err != nil {
+		return 0, 0, err
+	}
+	keys, err := s.keyRepo.RevokeByCreator(ctx, user.OrgID, user.ID, at)
+	if err != nil {
+		return 0, 0, err
+	}
+	return byUser + bySubject, keys, nil
+}
+
+// syncRoles sets users' roles from the groups they are members of. Failures
+// are only logged: the membership change that triggered the sync stands and
+// the next change of the users' groups retries it.
+func (s *SCIMService) syncRoles(ctx context.Context, orgID uuid.UUID, userIDs []uuid.UUID) {
+	if len(userIDs) == 0 {
+		return
+	}
+	config, err := s.GetConfig(ctx, orgID)
+	if err != nil {
+		return
+	}
+	groupRoles, err := config.GetGroupRoles()
+	if err != nil {
+		s.logger.Error("invalid scim group roles", zap.String("org_id", orgID.String()), zap.Error(err))
+		return
+	}
+	if len(groupRoles) == 0 {
+		return
+	}
+	ranks, err := s.roleRanks(ctx, orgID)
+	if err != nil {
+		s.logger.Error("failed to list roles", zap.String("org_id", orgID.String()), zap.Error(err))
+		return
+	}
+
+	synced := make(map[uuid.UUID]bool, len(userIDs))
+	for _, userID := range userIDs {
+		if synced[userID] {
+			continue
+		}
+		synced[userID] = true
+		if err := s.syncRole(ctx, orgID, userID, groupRoles, ranks); err != nil {
+			s.logger.Error("failed to sync user role",
+				zap.String("org_id", orgID.String()),
+				zap.String("user_id", userID.String()),
+				zap.Error(err))
+		}
+	}
+}
+
+// syncRole sets a user's role to the most privileged role of the user's groups
+func (s *SCIMService) syncRole(ctx context.Context, orgID, userID uuid.UUID, groupRoles map[string]models.UserRole, ranks map[models.UserRole]int) error {
+	user, err := s.userRepo.GetByOrgAndID(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	groups, err := s.groupRepo.ListByMember(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	role := roleFor(groups, groupRoles, ranks)
+	if role == user.Role {
+		return nil
+	}
+	previous := user.Role
+	user.Role = role
+	user.UpdatedAt = s.now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
+	}
+
+	s.logger.Info("user role synced from groups",
+		zap.String("user_id", userID.String()),
+		zap.String("from", string(previous)),
+		zap.String("to", string(role)))
+	if s.auditService != nil {
+		if err := s.auditService.LogUserRoleChanged(user, previous); err != nil {
+			s.logger.Error("failed to log user role change", zap.Error(err))
+		}
+	}
+	return nil
+}
+
+// roleRanks ranks the organization's effective roles by privilege: the
+// number of permissions each grants
+func (s *SCIMService) roleRanks(ctx context.Context, orgID uuid.UUID) (map[models.UserRole]int, error) {
+	roles, err := s.roles.ListRoles(ctx, orgID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list roles", err)
+	}
+
+	ranks := make(map[models.UserRole]int, len(roles))
+	for _, role := range roles {
+		permissions, err := role.GetPermissions()
+		if err != nil {
+			return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to read role permissions", err)
+		}
+		rank := 0
+		for _, permission := range models.Permissions {
+			if models.Grants(permissions, permission) {
+				rank++
+			}
+		}
+		ranks[models.UserRole(role.Name)] = rank
+	}
+	return ranks, nil
+}
+
+// roleFor returns the most privileged role mapped from any of the groups, or
+// DefaultRole. Mapped roles the organization no longer has are skipped; ties
+// go to the role whose name sorts first, so the outcome does not depend on
+// group order.
+func roleFor(groups []*models.SCIMGroup, groupRoles map[string]models.UserRole, ranks map[models.UserRole]int) models.UserRole {
+	mapped := make(map[string]models.UserRole, len(groupRoles))
+	for name, role := range groupRoles {
+		mapped[strings.ToLower(name)] = role
+	}
+
+	var best models.UserRole
+	bestRank := -1
+	for _, group := range groups {
+		role, ok := mapped[strings.ToLower(group.DisplayName)]
+		if !ok {
+			continue
+		}
+		rank, ok := ranks[role]
+		if !ok {
+			continue
+		}
+		if rank > bestRank || (rank == bestRank && role < best) {
+			best, bestRank = role, rank
+		}
+	}
+	if bestRank < 0 {
+		return DefaultRole
+	}
+	return best
+}
+
+// checkUserName checks that no other user of the organization has a user name
+func (s *SCIMService) checkUserName(ctx context.Context, orgID, id uuid.UUID, userName string) error {
+	if existing, err := s.userRepo.GetByOrgAndEmail(ctx, orgID, userName); err == nil && existing.ID != id {
+		return services.NewDomainError(services.ErrorTypeConflict, fmt.Sprintf("user name %q is taken", userName), nil)
+	}
+	return nil
+}
+
+// validateGroup trims and checks a group's attributes, checks that no other
+// group of the organization has its name and that its members are users of
+// the organization, and removes duplicate members
+func (s *SCIMService) validateGroup(ctx context.Context, orgID, id uuid.UUID, input GroupInput) (GroupInput, error) {
+	input.DisplayName = strings.TrimSpace(input.DisplayName)
+	if input.DisplayName == "" || len(input.DisplayName) > MaxNameLength {
+		return input, services.NewDomainError(services.ErrorTypeValidation, fmt.Sprintf("displayName must be between 1 and %d characters", MaxNameLength), nil)
+	}
+	if len(input.ExternalID) > MaxNameLength {
+		return input, services.NewDomainError(services.ErrorTypeValidation, fmt.Sprintf("externalId must be at most %d characters", MaxNameLength), nil)
+	}
+	if existing, err := s.groupRepo.GetByOrgAndName(ctx, orgID, input.DisplayName); err == nil && existing.ID != id {
+		return input, services.NewDomainError(services.ErrorTypeConflict, fmt.Sprintf("group %q already exists", input.DisplayName), nil)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(input.Members))
+	members := make([]uuid.UUID, 0, len(input.Members))
+	for _, userID := range input.Members {
+		if seen[userID] {
+			continue
+		}
+		seen[userID] = true
+		if _, err := s.userRepo.GetByOrgAndID(ctx, orgID, userID); err != nil {
+			return input, services.NewDomainError(services.ErrorTypeValidation, fmt.Sprintf("member %s is not a user", userID), err)
+		}
+		members = append(members, userID)
+	}
+	input.Members = members
+	return input, nil
+}
+
+// validateUser trims and checks a user's attributes
+func validateUser(input UserInput) (UserInput, error) {
+	input.UserName = strings.TrimSpace(input.UserName)
+	input.DisplayName = strings.TrimSpace(input.DisplayName)
+	if input.UserName == "" || len(input.UserName) > MaxNameLength {
+		return input, services.NewDomainError(services.ErrorTypeValidation, fmt.Sprintf("userName must be between 1 and %d characters", MaxNameLength), nil)
+	}
+	if len(input.DisplayName) > MaxNameLength || len(input.ExternalID) > MaxNameLength {
+		return input, services.NewDomainError(services.ErrorTypeValidation, fmt.Sprintf("displayName and externalId must be at most %d characters", MaxNameLength), nil)
+	}
+	return input, nil
+}
+
+// randomHex returns n random bytes, hex encoded
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
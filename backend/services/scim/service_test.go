@@ -0,0 +1,434 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"go.uber.org/zap"
+)
+
+// fakeConfigRepository stores SCIM configurations in memory
+type fakeConfigRepository struct {
+	repositories.SCIMConfigRepository
+	configs map[uuid.UUID]*models.SCIMConfig
+}
+
+func (r *fakeConfigRepository) Get(ctx context.Context, orgID uuid.UUID) (*models.SCIMConfig, error) {
+	if config, ok := r.configs[orgID]; ok {
+		copied := *config
+		return &copied, nil
+	}
+	return nil, errors.New("scim config not found")
+}
+
+func (r *fakeConfigRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*models.SCIMConfig, error) {
+	for _, config := range r.configs {
+		if config.TokenHash != "" && config.TokenHash == tokenHash {
+			copied := *config
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("scim config not found")
+}
+
+func (r *fakeConfigRepository) Upsert(ctx context.Context, config *models.SCIMConfig) error {
+	copied := *config
+	r.configs[config.OrgID] = &copied
+	return nil
+}
+
+// fakeUserRepository stores users in memory
+type fakeUserRepository struct {
+	repositories.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *fakeUserRepository) Create(ctx context.Context, user *models.User) error {
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *fakeUserRepository) GetByOrgAndID(ctx context.Context, orgID, id uuid.UUID) (*models.User, error) {
+	if user, ok := r.users[id]; ok && user.OrgID == orgID {
+		copied := *user
+		return &copied, nil
+	}
+	return nil, errors.New("user not found")
+}
+
+func (r *fakeUserRepository) GetByOrgAndEmail(ctx context.Context, orgID uuid.UUID, email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.OrgID == orgID && user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (r *fakeUserRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID) ([]*models.User, error) {
+	var users []*models.User
+	for _, user := range r.users {
+		if user.OrgID == orgID {
+			copied := *user
+			users = append(users, &copied)
+		}
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepository) Update(ctx context.Context, user *models.User) error {
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *fakeUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.users, id)
+	return nil
+}
+
+// fakeGroupRepository stores groups in memory
+type fakeGroupRepository struct {
+	repositories.SCIMGroupRepository
+	groups map[uuid.UUID]*models.SCIMGroup
+}
+
+func (r *fakeGroupRepository) Create(ctx context.Context, group *models.SCIMGroup) error {
+	copied := *group
+	r.groups[group.ID] = &copied
+	return nil
+}
+
+func (r *fakeGroupRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SCIMGroup, error) {
+	if group, ok := r.groups[id]; ok {
+		copied := *group
+		return &copied, nil
+	}
+	return nil, errors.New("scim group not found")
+}
+
+func (r *fakeGroupRepository) GetByOrgAndName(ctx context.Context, orgID uuid.UUID, displayName string) (*models.SCIMGroup, error) {
+	for _, group := range r.groups {
+		if group.OrgID == orgID && group.DisplayName == displayName {
+			copied := *group
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("scim group not found")
+}
+
+func (r *fakeGroupRepository) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*models.SCIMGroup, error) {
+	var groups []*models.SCIMGroup
+	for _, group := range r.groups {
+		if group.OrgID == orgID {
+			copied := *group
+			groups = append(groups, &copied)
+		}
+	}
+	return groups, nil
+}
+
+func (r *fakeGroupRepository) ListByMember(ctx context.Context, userID uuid.UUID) ([]*models.SCIMGroup, error) {
+	var groups []*models.SCIMGroup
+	for _, group := range r.groups {
+		for _, member := range group.Members {
+			if member == userID {
+				copied := *group
+				groups = append(groups, &copied)
+				break
+			}
+		}
+	}
+	return groups, nil
+}
+
+func (r *fakeGroupRepository) Update(ctx context.Context, group *models.SCIMGroup) error {
+	copied := *group
+	r.groups[group.ID] = &copied
+	return nil
+}
+
+func (r *fakeGroupRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.groups, id)
+	return nil
+}
+
+// fakeSessionRepository records session revocations
+type fakeSessionRepository struct {
+	repositories.SessionRepository
+	revokedUsers    []uuid.UUID
+	revokedSubjects []string
+}
+
+func (r *fakeSessionRepository) RevokeByUser(ctx context.Context, orgID, userID uuid.UUID, at time.Time) (int64, error) {
+	r.revokedUsers = append(r.revokedUsers, userID)
+	return 1, nil
+}
+
+func (r *fakeSessionRepository) RevokeBySubject(ctx context.Context, orgID uuid.UUID, subject string, at time.Time) (int64, error) {
+	r.revokedSubjects = append(r.revokedSubjects, subject)
+	return 1, nil
+}
+
+// fakeKeyRepository records API key revocations
+type fakeKeyRepository struct {
+	repositories.APIKeyRepository
+	revokedCreators []uuid.UUID
+}
+
+func (r *fakeKeyRepository) RevokeByCreator(ctx context.Context, orgID, userID uuid.UUID, at time.Time) (int64, error) {
+	r.revokedCreators = append(r.revokedCreators, userID)
+	return 2, nil
+}
+
+// fakeTxManager runs transactional functions directly
+type fakeTxManager struct{}
+
+func (fakeTxManager) Begin(ctx context.Context) (repositories.Transaction, error) {
+	return nil, nil
+}
+
+func (fakeTxManager) InTransaction(ctx context.Context, fn func(ctx context.Context, tx repositories.Transaction) error) error {
+	return fn(ctx, nil)
+}
+
+// fakeRoleLister lists the built-in roles and the organization's custom roles
+type fakeRoleLister struct {
+	custom []*models.Role
+}
+
+func (f *fakeRoleLister) ListRoles(ctx context.Context, orgID uuid.UUID) ([]*models.Role, error) {
+	var roles []*models.Role
+	for _, builtIn := range models.BuiltInRoles {
+		role, err := models.NewRole(nil, string(builtIn.Name), builtIn.Description, builtIn.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return append(roles, f.custom...), nil
+}
+
+type scimFixture struct {
+	service  *SCIMService
+	users    *fakeUserRepository
+	sessions *fakeSessionRepository
+	keys     *fakeKeyRepository
+	roles    *fakeRoleLister
+	orgID    uuid.UUID
+}
+
+func newFixture() *scimFixture {
+	f := &scimFixture{
+		users:    &fakeUserRepository{users: make(map[uuid.UUID]*models.User)},
+		sessions: &fakeSessionRepository{},
+		keys:     &fakeKeyRepository{},
+		roles:    &fakeRoleLister{},
+		orgID:    uuid.New(),
+	}
+	f.service = NewSCIMService(
+		&fakeConfigRepository{configs: make(map[uuid.UUID]*models.SCIMConfig)},
+		f.users,
+		&fakeGroupRepository{groups: make(map[uuid.UUID]*models.SCIMGroup)},
+		f.sessions,
+		f.keys,
+		fakeTxManager{},
+		f.roles,
+		zap.NewNop(),
+	)
+	return f
+}
+
+func (f *scimFixture) createUser(t *testing.T, userName string) *models.User {
+	user, err := f.service.CreateUser(context.Background(), f.orgID, UserInput{UserName: userName, Active: true})
+	require.NoError(t, err)
+	return user
+}
+
+func (f *scimFixture) role(t *testing.T, id uuid.UUID) models.UserRole {
+	user, err := f.service.GetUser(context.Background(), f.orgID, id)
+	require.NoError(t, err)
+	return user.Role
+}
+
+func TestAuthenticate(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	_, err := f.service.Authenticate(ctx, TokenPrefix+"unknown")
+	assert.True(t, services.IsUnauthorizedError(err))
+
+	first, err := f.service.RotateToken(ctx, f.orgID, nil)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(first.Token, TokenPrefix))
+	orgID, err := f.service.Authenticate(ctx, first.Token)
+	require.NoError(t, err)
+	assert.Equal(t, f.orgID, orgID)
+
+	second, err := f.service.RotateToken(ctx, f.orgID, nil)
+	require.NoError(t, err)
+	_, err = f.service.Authenticate(ctx, first.Token)
+	assert.True(t, services.IsUnauthorizedError(err), "rotated tokens stop working")
+
+	require.NoError(t, f.service.RevokeToken(ctx, f.orgID, nil))
+	_, err = f.service.Authenticate(ctx, second.Token)
+	assert.True(t, services.IsUnauthorizedError(err))
+	assert.True(t, services.IsNotFoundError(f.service.RevokeToken(ctx, f.orgID, nil)))
+}
+
+func TestCreateUser(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+
+	user, err := f.service.CreateUser(ctx, f.orgID, UserInput{
+		UserName:    " ada@example.com ",
+		ExternalID:  "00u1",
+		DisplayName: "Ada Lovelace",
+		Active:      true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ada@example.com", user.Email)
+	assert.Equal(t, user.ID.String(), user.CognitoSub)
+	assert.Equal(t, DefaultRole, user.Role)
+	assert.True(t, user.IsActive())
+
+	_, err = f.service.CreateUser(ctx, f.orgID, UserInput{UserName: "ada@example.com", Active: true})
+	assert.True(t, services.IsConflictError(err))
+
+	_, err = f.service.CreateUser(ctx, f.orgID, UserInput{UserName: " ", Active: true})
+	assert.True(t, services.IsValidationError(err))
+
+	inactive, err := f.service.CreateUser(ctx, f.orgID, UserInput{UserName: "bob@example.com"})
+	require.NoError(t, err)
+	assert.False(t, inactive.IsActive())
+}
+
+func TestReplaceUser_DeactivationRevokesCredentials(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	user := f.createUser(t, "ada@example.com")
+
+	updated, err := f.service.ReplaceUser(ctx, f.orgID, user.ID, UserInput{UserName: "ada@example.com", DisplayName: "Ada"})
+	require.NoError(t, err)
+	assert.False(t, updated.IsActive())
+	assert.Equal(t, "Ada", updated.DisplayName)
+	assert.Equal(t, []uuid.UUID{user.ID}, f.sessions.revokedUsers)
+	assert.Equal(t, []string{user.CognitoSub}, f.sessions.revokedSubjects)
+	assert.Equal(t, []uuid.UUID{user.ID}, f.keys.revokedCreators)
+
+	// Replacing an inactive user again does not revoke anything more
+	_, err = f.service.ReplaceUser(ctx, f.orgID, user.ID, UserInput{UserName: "ada@example.com"})
+	require.NoError(t, err)
+	assert.Len(t, f.keys.revokedCreators, 1)
+
+	reactivated, err := f.service.ReplaceUser(ctx, f.orgID, user.ID, UserInput{UserName: "ada@example.com", Active: true})
+	require.NoError(t, err)
+	assert.True(t, reactivated.IsActive())
+}
+
+func TestDeleteUser(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	user := f.createUser(t, "ada@example.com")
+
+	err := f.service.DeleteUser(ctx, uuid.New(), user.ID)
+	assert.True(t, services.IsNotFoundError(err), "users of other organizations are not found")
+	assert.Empty(t, f.keys.revokedCreators)
+
+	require.NoError(t, f.service.DeleteUser(ctx, f.orgID, user.ID))
+	assert.Equal(t, []uuid.UUID{user.ID}, f.sessions.revokedUsers)
+	assert.Equal(t, []uuid.UUID{user.ID}, f.keys.revokedCreators)
+	_, err = f.service.GetUser(ctx, f.orgID, user.ID)
+	assert.True(t, services.IsNotFoundError(err))
+}
+
+func TestGroupRoles(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	ada := f.createUser(t, "ada@example.com")
+	bob := f.createUser(t, "bob@example.com")
+
+	engineers, err := f.service.CreateGroup(ctx, f.orgID, GroupInput{DisplayName: "Engineering", Members: []uuid.UUID{ada.ID, bob.ID}})
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleViewer, f.role(t, ada.ID), "groups grant no roles until mapped")
+
+	_, err = f.service.SetGroupRoles(ctx, f.orgID, map[string]models.UserRole{
+		"engineering": models.RoleMember,
+		"IT Admins":   models.RoleAdmin,
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleMember, f.role(t, ada.ID))
+	assert.Equal(t, models.RoleMember, f.role(t, bob.ID))
+
+	admins, err := f.service.CreateGroup(ctx, f.orgID, GroupInput{DisplayName: "IT Admins", Members: []uuid.UUID{ada.ID, ada.ID}})
+	require.NoError(t, err)
+	assert.Len(t, admins.Members, 1)
+	assert.Equal(t, models.RoleAdmin, f.role(t, ada.ID), "the most privileged role wins")
+
+	_, err = f.service.ReplaceGroup(ctx, f.orgID, engineers.ID, GroupInput{DisplayName: "Engineering", Members: []uuid.UUID{ada.ID}})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultRole, f.role(t, bob.ID), "members removed from every mapped group get the default role")
+
+	require.NoError(t, f.service.DeleteGroup(ctx, f.orgID, admins.ID))
+	assert.Equal(t, models.RoleMember, f.role(t, ada.ID))
+
+	_, err = f.service.SetGroupRoles(ctx, f.orgID, map[string]models.UserRole{"Engineering": models.RoleSuperAdmin}, nil)
+	assert.True(t, services.IsValidationError(err))
+	_, err = f.service.SetGroupRoles(ctx, f.orgID, map[string]models.UserRole{"Engineering": "auditor"}, nil)
+	assert.True(t, services.IsValidationError(err), "roles the organization does not have cannot be mapped")
+}
+
+func TestGroupRoles_CustomRoles(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	auditor, err := models.NewRole(&f.orgID, "auditor", "", []models.Permission{models.PermissionPoliciesRead})
+	require.NoError(t, err)
+	operator, err := models.NewRole(&f.orgID, "operator", "", []models.Permission{"policies:*", "experiments:*", "templates:*", "rag:write", "api_keys:*", "roles:read"})
+	require.NoError(t, err)
+	f.roles.custom = []*models.Role{auditor, operator}
+	ada := f.createUser(t, "ada@example.com")
+
+	_, err = f.service.CreateGroup(ctx, f.orgID, GroupInput{DisplayName: "Audit", Members: []uuid.UUID{ada.ID}})
+	require.NoError(t, err)
+	_, err = f.service.SetGroupRoles(ctx, f.orgID, map[string]models.UserRole{"Audit": "auditor"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.UserRole("auditor"), f.role(t, ada.ID))
+
+	_, err = f.service.CreateGroup(ctx, f.orgID, GroupInput{DisplayName: "Operations", Members: []uuid.UUID{ada.ID}})
+	require.NoError(t, err)
+	_, err = f.service.SetGroupRoles(ctx, f.orgID, map[string]models.UserRole{"Audit": "auditor", "Operations": "operator"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, models.UserRole("operator"), f.role(t, ada.ID), "the role granting more permissions wins")
+
+	// Roles deleted since they were mapped are skipped
+	f.roles.custom = []*models.Role{auditor}
+	_, err = f.service.CreateGroup(ctx, f.orgID, GroupInput{DisplayName: "Other", Members: []uuid.UUID{ada.ID}})
+	require.NoError(t, err)
+	assert.Equal(t, models.UserRole("auditor"), f.role(t, ada.ID))
+}
+
+func TestCreateGroup_Validation(t *testing.T) {
+	f := newFixture()
+	ctx := context.Background()
+	other := newFixture()
+	stranger := other.createUser(t, "eve@example.com")
+
+	_, err := f.service.CreateGroup(ctx, f.orgID, GroupInput{DisplayName: "Engineering", Members: []uuid.UUID{stranger.ID}})
+	assert.True(t, services.IsValidationError(err), "users of other organizations cannot be members")
+
+	_, err = f.service.CreateGroup(ctx, f.orgID, GroupInput{DisplayName: "Engineering"})
+	require.NoError(t, err)
+	_, err = f.service.CreateGroup(ctx, f.orgID, GroupInput{DisplayName: "Engineering"})
+	assert.True(t, services.IsConflictError(err))
+}
@@ -0,0 +1,451 @@
+package serviceaccount
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/audit"
+	"github.com/upb/llm-control-plane/backend/tokens"
+	"go.uber.org/zap"
+)
+
+const (
+	// ClientIDPrefix starts every service account's client ID
+	ClientIDPrefix = "sa_"
+
+	// SecretPrefix starts every client secret, so leaked secrets are easy to spot
+	SecretPrefix = "lcpcs_"
+
+	// MaxNameLength bounds service account names
+	MaxNameLength = 255
+
+	// clientIDBytes and secretBytes are the random bytes of client IDs and secrets
+	clientIDBytes = 12
+	secretBytes   = 32
+)
+
+var (
+	// ErrInvalidClient is returned when a client ID and secret do not
+	// identify an active service account
+	ErrInvalidClient = errors.New("invalid client")
+
+	// ErrInvalidScope is returned when a token is requested with a scope
+	// the service account may not have
+	ErrInvalidScope = errors.New("invalid scope")
+)
+
+// TokenIssuer signs the service accounts' access tokens
+type TokenIssuer interface {
+	Issue(claims *tokens.Claims) (string, time.Time, error)
+}
+
+// PermissionChecker resolves the permissions of roles within an organization
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, orgID uuid.UUID, roles []string, permission models.Permission) (bool, error)
+}
+
+// CreatedServiceAccount is returned when a service account is created or its
+// secret rotated; the plaintext secret is only shown at this point
+type CreatedServiceAccount struct {
+	*models.ServiceAccount
+	ClientSecret string `json:"client_secret"`
+}
+
+// Token is an access token issued to a service account, in the shape of an
+// OAuth2 token response
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// Grantor is the caller granting a service account its permissions: the
+// roles and, for scoped tokens, the scopes of the caller's claims
+type Grantor struct {
+	Roles  []string
+	Scopes []string
+}
+
+// CreateRequest represents a request to create a service account. The
+// account may only be granted permissions the grantor holds.
+type CreateRequest struct {
+	OrgID       uuid.UUID
+	Name        string
+	Description string
+	Roles       []string
+	Scopes      []models.Permission // Optional; limits the permissions of the roles
+	CreatedBy   *uuid.UUID
+	Grantor     Grantor
+}
+
+// UpdateRequest represents a request to change a service account; nil
+// fields are left unchanged
+type UpdateRequest struct {
+	OrgID       uuid.UUID
+	ID          uuid.UUID
+	Name        *string
+	Description *string
+	Roles       *[]string
+	Scopes      *[]models.Permission
+	Disabled    *bool
+	UpdatedBy   *uuid.UUID
+	Grantor     Grantor
+}
+
+// ServiceAccountService manages organizations' service accounts and issues
+// their access tokens with the OAuth2 client credentials grant. Tokens are
+// short-lived and not checked against the account once issued, so disabling
+// or deleting an account takes effect when its tokens expire.
+type ServiceAccountService struct {
+	repo         repositories.ServiceAccountRepository
+	issuer       TokenIssuer
+	permissions  PermissionChecker
+	auditService *audit.AuditService
+	logger       *zap.Logger
+	now          func() time.Time
+}
+
+// NewServiceAccountService creates a new ServiceAccountService instance
+func NewServiceAccountService(repo repositories.ServiceAccountRepository, issuer TokenIssuer, permissions PermissionChecker, logger *zap.Logger) *ServiceAccountService {
+	return &ServiceAccountService{
+		repo:        repo,
+		issuer:      issuer,
+		permissions: permissions,
+		logger:      logger,
+		now:         time.Now,
+	}
+}
+
+// SetAuditService records service account changes in the audit log
+func (s *ServiceAccountService) SetAuditService(auditService *audit.AuditService) {
+	s.auditService = auditService
+}
+
+// HashSecret returns the hash under which a client secret is stored
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create creates a service account for the organization and returns it with
+// its client secret
+func (s *ServiceAccountService) Create(ctx context.Context, req CreateRequest) (*CreatedServiceAccount, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > MaxNameLength {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "name must be between 1 and 255 characters", nil)
+	}
+
+	clientID, err := randomHex(clientIDBytes)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create service account", err)
+	}
+	now := s.now()
+	account := &models.ServiceAccount{
+		ID:          uuid.New(),
+		OrgID:       req.OrgID,
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		ClientID:    ClientIDPrefix + clientID,
+		CreatedBy:   req.CreatedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := account.SetRoles(req.Roles); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), err)
+	}
+	if err := account.SetScopes(req.Scopes); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), err)
+	}
+	if err := s.checkGrant(ctx, account, req.Grantor); err != nil {
+		return nil, err
+	}
+
+	secret, err := s.newSecret(account)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, account); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create service account", err)
+	}
+
+	s.logger.Info("service account created",
+		zap.String("service_account_id", account.ID.String()),
+		zap.String("org_id", account.OrgID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogServiceAccountCreated(account, req.CreatedBy); err != nil {
+			s.logger.Error("failed to log service account creation", zap.Error(err))
+		}
+	}
+
+	return &CreatedServiceAccount{ServiceAccount: account, ClientSecret: secret}, nil
+}
+
+// List returns the organization's service accounts, newest first
+func (s *ServiceAccountService) List(ctx context.Context, orgID uuid.UUID) ([]*models.ServiceAccount, error) {
+	accounts, err := s.repo.ListByOrg(ctx, orgID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list service accounts", err)
+	}
+	if accounts == nil {
+		accounts = []*models.ServiceAccount{}
+	}
+	return accounts, nil
+}
+
+// Get returns a service account of the organization
+func (s *ServiceAccountService) Get(ctx context.Context, orgID, id uuid.UUID) (*models.ServiceAccount, error) {
+	return s.getAccount(ctx, orgID, id)
+}
+
+// Update changes a service account's name, description, roles, scopes or
+// status. Disabled accounts cannot obtain new tokens.
+func (s *ServiceAccountService) Update(ctx context.Context, req UpdateRequest) (*models.ServiceAccount, error) {
+	account, err := s.getAccount(ctx, req.OrgID, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || len(name) > MaxNameLength {
+			return nil, services.NewDomainError(services.ErrorTypeValidation, "name must be between 1 and 255 characters", nil)
+		}
+		account.Name = name
+	}
+	if req.Description != nil {
+		account.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Roles != nil {
+		if err := account.SetRoles(*req.Roles); err != nil {
+			return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), err)
+		}
+	}
+	if req.Scopes != nil {
+		if err := account.SetScopes(*req.Scopes); err != nil {
+			return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), err)
+		}
+	}
+	if req.Roles != nil || req.Scopes != nil {
+		if err := s.checkGrant(ctx, account, req.Grantor); err != nil {
+			return nil, err
+		}
+	}
+	now := s.now()
+	if req.Disabled != nil {
+		if !*req.Disabled {
+			account.DisabledAt = nil
+		} else if account.DisabledAt == nil {
+			account.DisabledAt = &now
+		}
+	}
+	account.UpdatedAt = now
+
+	if err := s.repo.Update(ctx, account); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to update service account", err)
+	}
+
+	s.logger.Info("service account updated", zap.String("service_account_id", account.ID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogServiceAccountUpdated(account, req.UpdatedBy); err != nil {
+			s.logger.Error("failed to log service account update", zap.Error(err))
+		}
+	}
+
+	return account, nil
+}
+
+// RotateSecret replaces a service account's client secret; the previous
+// secret stops working immediately
+func (s *ServiceAccountService) RotateSecret(ctx context.Context, orgID, id uuid.UUID, rotatedBy *uuid.UUID) (*CreatedServiceAccount, error) {
+	account, err := s.getAccount(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := s.newSecret(account)
+	if err != nil {
+		return nil, err
+	}
+	account.UpdatedAt = s.now()
+	if err := s.repo.Update(ctx, account); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to rotate service account secret", err)
+	}
+
+	s.logger.Info("service account secret rotated", zap.String("service_account_id", account.ID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogServiceAccountRotated(account, rotatedBy); err != nil {
+			s.logger.Error("failed to log service account secret rotation", zap.Error(err))
+		}
+	}
+
+	return &CreatedServiceAccount{ServiceAccount: account, ClientSecret: secret}, nil
+}
+
+// Delete deletes a service account of the organization
+func (s *ServiceAccountService) Delete(ctx context.Context, orgID, id uuid.UUID, deletedBy *uuid.UUID) error {
+	account, err := s.getAccount(ctx, orgID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, account.ID); err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to delete service account", err)
+	}
+
+	s.logger.Info("service account deleted", zap.String("service_account_id", account.ID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogServiceAccountDeleted(account, deletedBy); err != nil {
+			s.logger.Error("failed to log service account deletion", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// IssueToken authenticates a service account by client ID and secret and
+// issues it an access token. The token is limited to the requested scopes,
+// which must be within the account's scopes; without any it carries the
+// account's scopes.
+func (s *ServiceAccountService) IssueToken(ctx context.Context, clientID, clientSecret string, scopes []string) (*Token, error) {
+	account, err := s.repo.GetByClientID(ctx, clientID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "invalid client credentials", ErrInvalidClient)
+	}
+	if subtle.ConstantTimeCompare([]byte(HashSecret(clientSecret)), []byte(account.SecretHash)) != 1 {
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "invalid client credentials", ErrInvalidClient)
+	}
+	if !account.IsActive() {
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "service account is disabled", ErrInvalidClient)
+	}
+
+	roles, err := account.GetRoles()
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to issue token", err)
+	}
+	allowed, err := account.GetScopes()
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to issue token", err)
+	}
+	granted := make([]string, 0, len(allowed))
+	if len(scopes) == 0 {
+		for _, scope := range allowed {
+			granted = append(granted, string(scope))
+		}
+	}
+	for _, scope := range scopes {
+		permission := models.Permission(scope)
+		if err := models.ValidatePermission(permission); err != nil {
+			return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), ErrInvalidScope)
+		}
+		if len(allowed) > 0 && !models.Grants(allowed, permission) {
+			return nil, services.NewDomainError(services.ErrorTypeValidation, "scope not allowed for service account: "+scope, ErrInvalidScope)
+		}
+		granted = append(granted, scope)
+	}
+
+	accessToken, expiresAt, err := s.issuer.Issue(&tokens.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: account.ID.String()},
+		OrgID:            account.OrgID.String(),
+		ClientID:         account.ClientID,
+		Roles:            roles,
+		Scope:            strings.Join(granted, " "),
+	})
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to issue token", err)
+	}
+
+	s.logger.Info("service account token issued",
+		zap.String("service_account_id", account.ID.String()),
+		zap.String("org_id", account.OrgID.String()))
+
+	return &Token{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(expiresAt.Sub(s.now()).Round(time.Second).Seconds()),
+		Scope:       strings.Join(granted, " "),
+	}, nil
+}
+
+// getAccount returns a service account of the organization; accounts of
+// other organizations are not found
+func (s *ServiceAccountService) getAccount(ctx context.Context, orgID, id uuid.UUID) (*models.ServiceAccount, error) {
+	account, err := s.repo.GetByID(ctx, id)
+	if err != nil || account.OrgID != orgID {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "service account not found", err)
+	}
+	return account, nil
+}
+
+// checkGrant ensures the grantor holds every permission the service account
+// would have, so that service accounts cannot be used to escalate privileges
+func (s *ServiceAccountService) checkGrant(ctx context.Context, account *models.ServiceAccount, grantor Grantor) error {
+	roles, err := account.GetRoles()
+	if err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to check permissions", err)
+	}
+	scopes, err := account.GetScopes()
+	if err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to check permissions", err)
+	}
+
+	for _, permission := range models.Permissions {
+		if len(scopes) > 0 && !models.Grants(scopes, permission) {
+			continue
+		}
+		granted, err := s.permissions.HasPermission(ctx, account.OrgID, roles, permission)
+		if err != nil {
+			return services.NewDomainError(services.ErrorTypeInternal, "failed to check permissions", err)
+		}
+		if !granted {
+			continue
+		}
+		held, err := s.permissions.HasPermission(ctx, account.OrgID, grantor.Roles, permission)
+		if err != nil {
+			return services.NewDomainError(services.ErrorTypeInternal, "failed to check permissions", err)
+		}
+		if !held || (len(grantor.Scopes) > 0 && !scopesGrant(grantor.Scopes, permission)) {
+			return services.NewDomainError(services.ErrorTypeForbidden, "cannot grant a permission you do not hold: "+string(permission), nil)
+		}
+	}
+	return nil
+}
+
+// scopesGrant reports whether a token's scopes include permission
+func scopesGrant(scopes []string, permission models.Permission) bool {
+	permissions := make([]models.Permission, 0, len(scopes))
+	for _, scope := range scopes {
+		permissions = append(permissions, models.Permission(scope))
+	}
+	return models.Grants(permissions, permission)
+}
+
+// newSecret generates a client secret and stores its hash on the account
+func (s *ServiceAccountService) newSecret(account *models.ServiceAccount) (string, error) {
+	random, err := randomHex(secretBytes)
+	if err != nil {
+		return "", services.NewDomainError(services.ErrorTypeInternal, "failed to generate client secret", err)
+	}
+	secret := SecretPrefix + random
+	account.SecretHash = HashSecret(secret)
+	return secret, nil
+}
+
+// randomHex returns n random bytes, hex encoded
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,248 @@
+package serviceaccount
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/config"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/tokens"
+	"go.uber.org/zap"
+)
+
+// fakeAccountRepository stores service accounts in memory
+type fakeAccountRepository struct {
+	repositories.ServiceAccountRepository
+	accounts map[uuid.UUID]*models.ServiceAccount
+}
+
+func (r *fakeAccountRepository) Create(ctx context.Context, account *models.ServiceAccount) error {
+	copied := *account
+	r.accounts[account.ID] = &copied
+	return nil
+}
+
+func (r *fakeAccountRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error) {
+	if account, ok := r.accounts[id]; ok {
+		copied := *account
+		return &copied, nil
+	}
+	return nil, errors.New("service account not found")
+}
+
+func (r *fakeAccountRepository) GetByClientID(ctx context.Context, clientID string) (*models.ServiceAccount, error) {
+	for _, account := range r.accounts {
+		if account.ClientID == clientID {
+			copied := *account
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("service account not found")
+}
+
+func (r *fakeAccountRepository) ListByOrg(ctx context.Context, orgID uuid.UUID) ([]*models.ServiceAccount, error) {
+	var accounts []*models.ServiceAccount
+	for _, account := range r.accounts {
+		if account.OrgID == orgID {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts, nil
+}
+
+func (r *fakeAccountRepository) Update(ctx context.Context, account *models.ServiceAccount) error {
+	copied := *account
+	r.accounts[account.ID] = &copied
+	return nil
+}
+
+func (r *fakeAccountRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.accounts, id)
+	return nil
+}
+
+// builtInPermissions resolves permissions by the built-in roles
+type builtInPermissions struct{}
+
+func (builtInPermissions) HasPermission(ctx context.Context, orgID uuid.UUID, roles []string, permission models.Permission) (bool, error) {
+	return models.BuiltInRolesGrant(roles, permission), nil
+}
+
+func newTestService(t *testing.T) (*ServiceAccountService, *tokens.Issuer) {
+	issuer, err := tokens.NewIssuer(config.TokensConfig{Issuer: "https://gateway.test"})
+	require.NoError(t, err)
+	repo := &fakeAccountRepository{accounts: make(map[uuid.UUID]*models.ServiceAccount)}
+	return NewServiceAccountService(repo, issuer, builtInPermissions{}, zap.NewNop()), issuer
+}
+
+func TestCreate(t *testing.T) {
+	orgID := uuid.New()
+
+	t.Run("returns the secret once and stores its hash", func(t *testing.T) {
+		svc, _ := newTestService(t)
+
+		created, err := svc.Create(context.Background(), CreateRequest{
+			OrgID:   orgID,
+			Name:    " nightly-eval ",
+			Roles:   []string{"member"},
+			Grantor: Grantor{Roles: []string{"admin"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "nightly-eval", created.Name)
+		assert.True(t, strings.HasPrefix(created.ClientID, ClientIDPrefix))
+		assert.True(t, strings.HasPrefix(created.ClientSecret, SecretPrefix))
+		assert.Equal(t, HashSecret(created.ClientSecret), created.SecretHash)
+
+		accounts, err := svc.List(context.Background(), orgID)
+		require.NoError(t, err)
+		require.Len(t, accounts, 1)
+	})
+
+	t.Run("cannot grant permissions the grantor lacks", func(t *testing.T) {
+		svc, _ := newTestService(t)
+
+		_, err := svc.Create(context.Background(), CreateRequest{
+			OrgID:   orgID,
+			Name:    "escalate",
+			Roles:   []string{"admin"},
+			Grantor: Grantor{Roles: []string{"member"}},
+		})
+		assert.True(t, services.IsForbiddenError(err))
+	})
+
+	t.Run("scopes limit what is granted", func(t *testing.T) {
+		svc, _ := newTestService(t)
+
+		_, err := svc.Create(context.Background(), CreateRequest{
+			OrgID:   orgID,
+			Name:    "reader",
+			Roles:   []string{"admin"},
+			Scopes:  []models.Permission{models.PermissionPoliciesRead},
+			Grantor: Grantor{Roles: []string{"member"}},
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("scoped grantors cannot grant beyond their scopes", func(t *testing.T) {
+		svc, _ := newTestService(t)
+
+		_, err := svc.Create(context.Background(), CreateRequest{
+			OrgID:   orgID,
+			Name:    "escalate",
+			Roles:   []string{"admin"},
+			Grantor: Grantor{Roles: []string{"admin"}, Scopes: []string{"service_accounts:write"}},
+		})
+		assert.True(t, services.IsForbiddenError(err))
+	})
+
+	t.Run("rejects superadmin and unknown scopes", func(t *testing.T) {
+		svc, _ := newTestService(t)
+
+		_, err := svc.Create(context.Background(), CreateRequest{
+			OrgID: orgID, Name: "root", Roles: []string{"superadmin"}, Grantor: Grantor{Roles: []string{"superadmin"}},
+		})
+		assert.True(t, services.IsValidationError(err))
+
+		_, err = svc.Create(context.Background(), CreateRequest{
+			OrgID: orgID, Name: "odd", Scopes: []models.Permission{"clusters:write"}, Grantor: Grantor{Roles: []string{"admin"}},
+		})
+		assert.True(t, services.IsValidationError(err))
+	})
+}
+
+func TestIssueToken(t *testing.T) {
+	orgID := uuid.New()
+	svc, issuer := newTestService(t)
+	created, err := svc.Create(context.Background(), CreateRequest{
+		OrgID:   orgID,
+		Name:    "ci",
+		Roles:   []string{"member"},
+		Scopes:  []models.Permission{models.PermissionPoliciesRead, "templates:*"},
+		Grantor: Grantor{Roles: []string{"admin"}},
+	})
+	require.NoError(t, err)
+
+	t.Run("issues a signed token with the account's roles and scopes", func(t *testing.T) {
+		token, err := svc.IssueToken(context.Background(), created.ClientID, created.ClientSecret, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer", token.TokenType)
+		assert.Equal(t, int(tokens.DefaultTTL.Seconds()), token.ExpiresIn)
+		assert.Equal(t, "policies:read templates:*", token.Scope)
+
+		claims, err := issuer.ValidateToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, created.ID.String(), claims.Subject)
+		assert.Equal(t, orgID.String(), claims.OrgID)
+		assert.Equal(t, created.ClientID, claims.ClientID)
+		assert.Equal(t, []string{"member"}, claims.Roles)
+	})
+
+	t.Run("narrows to requested scopes", func(t *testing.T) {
+		token, err := svc.IssueToken(context.Background(), created.ClientID, created.ClientSecret, []string{"templates:read"})
+		require.NoError(t, err)
+		assert.Equal(t, "templates:read", token.Scope)
+	})
+
+	t.Run("rejects scopes beyond the account's", func(t *testing.T) {
+		_, err := svc.IssueToken(context.Background(), created.ClientID, created.ClientSecret, []string{"policies:write"})
+		assert.ErrorIs(t, err, ErrInvalidScope)
+	})
+
+	t.Run("rejects wrong secrets and unknown clients", func(t *testing.T) {
+		_, err := svc.IssueToken(context.Background(), created.ClientID, SecretPrefix+"wrong", nil)
+		assert.ErrorIs(t, err, ErrInvalidClient)
+		_, err = svc.IssueToken(context.Background(), "sa_unknown", created.ClientSecret, nil)
+		assert.ErrorIs(t, err, ErrInvalidClient)
+	})
+
+	t.Run("rejects disabled accounts", func(t *testing.T) {
+		disabled := true
+		_, err := svc.Update(context.Background(), UpdateRequest{OrgID: orgID, ID: created.ID, Disabled: &disabled, Grantor: Grantor{Roles: []string{"admin"}}})
+		require.NoError(t, err)
+
+		_, err = svc.IssueToken(context.Background(), created.ClientID, created.ClientSecret, nil)
+		assert.ErrorIs(t, err, ErrInvalidClient)
+	})
+}
+
+func TestRotateSecret(t *testing.T) {
+	orgID := uuid.New()
+	svc, _ := newTestService(t)
+	created, err := svc.Create(context.Background(), CreateRequest{OrgID: orgID, Name: "ci", Grantor: Grantor{Roles: []string{"admin"}}})
+	require.NoError(t, err)
+
+	rotated, err := svc.RotateSecret(context.Background(), orgID, created.ID, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, created.ClientSecret, rotated.ClientSecret)
+	assert.Equal(t, created.ClientID, rotated.ClientID)
+
+	_, err = svc.IssueToken(context.Background(), created.ClientID, created.ClientSecret, nil)
+	assert.ErrorIs(t, err, ErrInvalidClient)
+	_, err = svc.IssueToken(context.Background(), created.ClientID, rotated.ClientSecret, nil)
+	assert.NoError(t, err)
+}
+
+func TestOtherOrganizationsAccountsAreNotFound(t *testing.T) {
+	svc, _ := newTestService(t)
+	created, err := svc.Create(context.Background(), CreateRequest{OrgID: uuid.New(), Name: "ci", Grantor: Grantor{Roles: []string{"admin"}}})
+	require.NoError(t, err)
+	otherOrg := uuid.New()
+
+	_, err = svc.Get(context.Background(), otherOrg, created.ID)
+	assert.True(t, services.IsNotFoundError(err))
+	_, err = svc.RotateSecret(context.Background(), otherOrg, created.ID, nil)
+	assert.True(t, services.IsNotFoundError(err))
+	err = svc.Delete(context.Background(), otherOrg, created.ID, nil)
+	assert.True(t, services.IsNotFoundError(err))
+
+	require.NoError(t, svc.Delete(context.Background(), created.OrgID, created.ID, nil))
+	_, err = svc.Get(context.Background(), created.OrgID, created.ID)
+	assert.True(t, services.IsNotFoundError(err))
+}
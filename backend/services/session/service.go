@@ -0,0 +1,453 @@
+// Package session keeps signed-in users' browser sessions on the server, so
+// that session cookies carry only an opaque token and sessions can be listed
+// and revoked.
+package session
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"go.uber.org/zap"
+)
+
+const (
+	// TokenPrefix starts every session token, telling session cookies apart
+	// from the raw ID tokens of earlier logins
+	TokenPrefix = "lcps_"
+
+	// DefaultLifetime is how long a session lasts after sign-in, however often
+	// its identity is refreshed
+	DefaultLifetime = 7 * 24 * time.Hour
+
+	// touchInterval limits how often a session's last use is written
+	touchInterval = time.Minute
+
+	// tokenBytes is the number of random bytes of a token
+	tokenBytes = 32
+
+	// lockStripes is the number of locks concurrent refreshes are serialized on
+	lockStripes = 64
+
+	// sealedPrefix marks refresh tokens encrypted with the refresh token key
+	sealedPrefix = "v1:"
+)
+
+// ErrRefreshRejected is returned by refreshers when the identity provider
+// rejects a refresh token, which ends the session. Other refresh errors, such
+// as the provider being unreachable, leave the session to be retried.
+var ErrRefreshRejected = errors.New("refresh token rejected")
+
+// Identity is the user a session was signed in as, mapped to the gateway's
+// tenants and roles
+type Identity struct {
+	Provider      string
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	OrgID         uuid.UUID
+	AppID         *uuid.UUID // Optional - may be nil
+	UserID        *uuid.UUID // Optional - set when the subject is a gateway user
+	Roles         []string
+	ExpiresAt     time.Time
+}
+
+// Refresher renews a provider's identities with refresh tokens
+type Refresher interface {
+	// Refresh exchanges a refresh token for a renewed identity and the refresh
+	// token to use next, which is "" when the provider does not rotate them
+	Refresh(ctx context.Context, refreshToken string) (*Identity, string, error)
+}
+
+// ClientInfo describes the client a session was created from, to tell
+// sessions apart in listings
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// CreatedSession is returned when a session is created; the token is only
+// known at this point
+type CreatedSession struct {
+	*models.Session
+	Token string `json:"-"`
+}
+
+// SessionService creates, authenticates, refreshes and revokes sessions
+type SessionService struct {
+	repo       repositories.SessionRepository
+	refreshers map[string]Refresher
+	lifetime   time.Duration
+	sealer     cipher.AEAD
+	logger     *zap.Logger
+	now        func() time.Time
+	locks      [lockStripes]sync.Mutex
+}
+
+// NewSessionService creates a new SessionService instance
+func NewSessionService(repo repositories.SessionRepository, logger *zap.Logger) *SessionService {
+	return &SessionService{
+		repo:       repo,
+		refreshers: make(map[string]Refresher),
+		lifetime:   DefaultLifetime,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// SetRefresher renews the identities of a provider's sessions when they
+// expire; sessions of providers without one end with their identity
+func (s *SessionService) SetRefresher(provider string, refresher Refresher) {
+	s.refreshers[provider] = refresher
+}
+
+// SetRefreshTokenKey encrypts refresh tokens at rest with AES-256-GCM under a
+// 32-byte key. Without a key, refresh tokens are not kept and sessions end
+// with their identity.
+func (s *SessionService) SetRefreshTokenKey(key []byte) error {
+	if len(key) != 32 {
+		return fmt.Errorf("invalid refresh token key: must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return fmt.Errorf("invalid refresh token key: %w", err)
+	}
+	sealer, err := cipher.NewGCM(block)
+	if err != nil {
+		return fmt.Errorf("invalid refresh token key: %w", err)
+	}
+	s.sealer = sealer
+	return nil
+}
+
+// HashToken returns the hash under which a token is stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create starts a session for an identity. Sessions without a refresh token
+// end when the identity expires.
+func (s *SessionService) Create(ctx context.Context, identity *Identity, refreshToken string, client ClientInfo) (*CreatedSession, error) {
+	now := s.now()
+	if identity == nil || identity.Subject == "" || identity.Provider == "" {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "identity requires a provider and subject", nil)
+	}
+	if !identity.ExpiresAt.After(now) {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "identity has expired", nil)
+	}
+
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to generate session token", err)
+	}
+	token := TokenPrefix + hex.EncodeToString(buf)
+	if s.sealer == nil {
+		refreshToken = ""
+	}
+
+	session := &models.Session{
+		ID:         uuid.New(),
+		TokenHash:  HashToken(token),
+		OrgID:      identity.OrgID,
+		Provider:   identity.Provider,
+		Issuer:     identity.Issuer,
+		Subject:    identity.Subject,
+		ExpiresAt:  now.Add(s.lifetime),
+		CreatedAt:  now,
+		LastSeenAt: now,
+		UserAgent:  truncate(client.UserAgent, 500),
+		IPAddress:  truncate(client.IPAddress, 45),
+	}
+	if err := applyIdentity(session, identity); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create session", err)
+	}
+	if refreshToken != "" {
+		sealed, err := s.seal(session.ID, refreshToken)
+		if err != nil {
+			return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create session", err)
+		}
+		session.RefreshToken = sealed
+	} else if identity.ExpiresAt.Before(session.ExpiresAt) {
+		session.ExpiresAt = identity.ExpiresAt
+	}
+
+	if err := s.repo.Create(ctx, session); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create session", err)
+	}
+
+	s.logger.Info("session created",
+		zap.String("session_id", session.ID.String()),
+		zap.String("provider", session.Provider),
+		zap.String("sub", session.Subject))
+
+	return &CreatedSession{Session: session, Token: token}, nil
+}
+
+// Authenticate resolves a token to its active session, refreshing the
+// session's identity when it has expired. Sessions are read on every call, so
+// revocations take effect immediately.
+func (s *SessionService) Authenticate(ctx context.Context, token string) (*models.Session, error) {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "invalid session", nil)
+	}
+	session, err := s.repo.GetByHash(ctx, HashToken(token))
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "invalid session", err)
+	}
+
+	now := s.now()
+	if !session.IsActive(now) {
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "session is revoked or expired", nil)
+	}
+	if session.IdentityExpired(now) {
+		if session, err = s.refresh(ctx, session.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if now.Sub(session.LastSeenAt) >= touchInterval {
+		if err := s.repo.Touch(ctx, session.ID, now); err != nil {
+			s.logger.Warn("failed to record session use", zap.String("session_id", session.ID.String()), zap.Error(err))
+		} else {
+			session.LastSeenAt = now
+		}
+	}
+
+	return session, nil
+}
+
+// List returns a user's active sessions, newest first
+func (s *SessionService) List(ctx context.Context, orgID uuid.UUID, subject string) ([]*models.Session, error) {
+	sessions, err := s.repo.ListBySubject(ctx, orgID, subject, s.now())
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list sessions", err)
+	}
+	if sessions == nil {
+		sessions = []*models.Session{}
+	}
+	return sessions, nil
+}
+
+// Revoke ends one of a user's sessions
+func (s *SessionService) Revoke(ctx context.Context, orgID uuid.UUID, subject string, id uuid.UUID) error {
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil || session.OrgID != orgID || session.Subject != subject {
+		return services.NewDomainError(services.ErrorTypeNotFound, "session not found", err)
+	}
+	return s.revoke(ctx, session)
+}
+
+// RevokeAll ends all of a user's sessions ("log out everywhere") and returns
+// how many were ended
+func (s *SessionService) RevokeAll(ctx context.Context, orgID uuid.UUID, subject string) (int64, error) {
+	count, err := s.repo.RevokeBySubject(ctx, orgID, subject, s.now())
+	if err != nil {
+		return 0, services.NewDomainError(services.ErrorTypeInternal, "failed to revoke sessions", err)
+	}
+
+	s.logger.Info("all sessions revoked",
+		zap.String("org_id", orgID.String()),
+		zap.String("sub", subject),
+		zap.Int64("count", count))
+	return count, nil
+}
+
+// RevokeToken ends the session of a token, for signing out; unknown tokens
+// are ignored
+func (s *SessionService) RevokeToken(ctx context.Context, token string) error {
+	if !strings.HasPrefix(token, TokenPrefix) {
+		return nil
+	}
+	session, err := s.repo.GetByHash(ctx, HashToken(token))
+	if err != nil {
+		return nil
+	}
+	return s.revoke(ctx, session)
+}
+
+// refresh renews the identity of a session with its refresh token. Requests
+// racing to refresh the same session are serialized within this instance,
+// and all but the first find the session already refreshed, so a rotated
+// refresh token is used once. Across instances, only the refresh that still
+// finds the token it used is stored; the others pick up the stored session.
+// Revocations are never overwritten.
+func (s *SessionService) refresh(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	lock := &s.locks[id[len(id)-1]%lockStripes]
+	lock.Lock()
+	defer lock.Unlock()
+
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "invalid session", err)
+	}
+	now := s.now()
+	if !session.IsActive(now) {
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "session is revoked or expired", nil)
+	}
+	if !session.IdentityExpired(now) {
+		return session, nil
+	}
+
+	refresher, ok := s.refreshers[session.Provider]
+	if !ok || session.RefreshToken == "" {
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "session has expired", nil)
+	}
+
+	previous := session.RefreshToken
+	refreshToken, err := s.open(session.ID, previous)
+	if err != nil {
+		s.logger.Warn("session refresh token cannot be decrypted",
+			zap.String("session_id", session.ID.String()),
+			zap.Error(err))
+		s.endSession(ctx, session)
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "session has expired", nil)
+	}
+	identity, next, err := refresher.Refresh(ctx, refreshToken)
+	if err != nil {
+		if !errors.Is(err, ErrRefreshRejected) {
+			return nil, services.NewDomainError(services.ErrorTypeExternal, "failed to refresh session", err)
+		}
+		// Another instance may have rotated the refresh token first
+		if refreshed := s.refreshedElsewhere(ctx, id); refreshed != nil {
+			return refreshed, nil
+		}
+		s.logger.Warn("session refresh rejected",
+			zap.String("session_id", session.ID.String()),
+			zap.String("provider", session.Provider),
+			zap.Error(err))
+		s.endSession(ctx, session)
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "session has expired", err)
+	}
+	if identity.Subject != session.Subject || identity.OrgID != session.OrgID {
+		s.logger.Warn("refreshed identity does not match session",
+			zap.String("session_id", session.ID.String()),
+			zap.String("provider", session.Provider))
+		s.endSession(ctx, session)
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "session has expired", nil)
+	}
+
+	if err := applyIdentity(session, identity); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to refresh session", err)
+	}
+	if next != "" {
+		if session.RefreshToken, err = s.seal(session.ID, next); err != nil {
+			return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to refresh session", err)
+		}
+	}
+	updated, err := s.repo.Refresh(ctx, session, previous)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to refresh session", err)
+	}
+	if !updated {
+		// Revoked meanwhile, or refreshed by another instance
+		if refreshed := s.refreshedElsewhere(ctx, id); refreshed != nil {
+			return refreshed, nil
+		}
+		return nil, services.NewDomainError(services.ErrorTypeUnauthorized, "session is revoked or expired", nil)
+	}
+
+	s.logger.Debug("session refreshed",
+		zap.String("session_id", session.ID.String()),
+		zap.Bool("rotated", next != ""))
+	return session, nil
+}
+
+// seal encrypts a session's refresh token, binding it to the session so it
+// cannot be copied to another
+func (s *SessionService) seal(id uuid.UUID, refreshToken string) (string, error) {
+	nonce := make([]byte, s.sealer.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := s.sealer.Seal(nonce, nonce, []byte(refreshToken), id[:])
+	return sealedPrefix + hex.EncodeToString(sealed), nil
+}
+
+// open decrypts a session's refresh token
+func (s *SessionService) open(id uuid.UUID, sealed string) (string, error) {
+	if s.sealer == nil {
+		return "", errors.New("no refresh token key is configured")
+	}
+	if !strings.HasPrefix(sealed, sealedPrefix) {
+		return "", errors.New("refresh token is not encrypted")
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil || len(data) < s.sealer.NonceSize() {
+		return "", errors.New("malformed refresh token")
+	}
+	nonce, ciphertext := data[:s.sealer.NonceSize()], data[s.sealer.NonceSize():]
+	plaintext, err := s.sealer.Open(nil, nonce, ciphertext, id[:])
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// refreshedElsewhere returns the stored session when another instance has
+// refreshed it and it is still active, or nil
+func (s *SessionService) refreshedElsewhere(ctx context.Context, id uuid.UUID) *models.Session {
+	session, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil
+	}
+	now := s.now()
+	if !session.IsActive(now) || session.IdentityExpired(now) {
+		return nil
+	}
+	return session
+}
+
+// revoke marks a session revoked; revoking an ended session is a no-op
+func (s *SessionService) revoke(ctx context.Context, session *models.Session) error {
+	if session.RevokedAt != nil {
+		return nil
+	}
+	now := s.now()
+	if err := s.repo.Revoke(ctx, session.ID, now); err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to revoke session", err)
+	}
+	session.RevokedAt = &now
+
+	s.logger.Info("session revoked", zap.String("session_id", session.ID.String()))
+	return nil
+}
+
+// endSession revokes a session whose identity cannot be renewed; the caller
+// fails authentication either way, so errors are only logged
+func (s *SessionService) endSession(ctx context.Context, session *models.Session) {
+	if err := s.revoke(ctx, session); err != nil {
+		s.logger.Error("failed to end session", zap.String("session_id", session.ID.String()), zap.Error(err))
+	}
+}
+
+// applyIdentity copies a (renewed) identity onto its session
+func applyIdentity(session *models.Session, identity *Identity) error {
+	session.AppID = identity.AppID
+	session.UserID = identity.UserID
+	session.Email = identity.Email
+	session.EmailVerified = identity.EmailVerified
+	session.IdentityExpiresAt = identity.ExpiresAt
+	return session.SetRoles(identity.Roles)
+}
+
+// truncate shortens client-supplied values to their column's length
+func truncate(value string, max int) string {
+	if len(value) > max {
+		return value[:max]
+	}
+	return value
+}
@@ -0,0 +1,432 @@
+package session
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"go.uber.org/zap"
+)
+
+// fakeSessionRepository stores copies of sessions in memory, like a database
+type fakeSessionRepository struct {
+	repositories.SessionRepository
+	mu       sync.Mutex
+	sessions map[uuid.UUID]models.Session
+}
+
+func newFakeSessionRepository() *fakeSessionRepository {
+	return &fakeSessionRepository{sessions: make(map[uuid.UUID]models.Session)}
+}
+
+func (r *fakeSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *fakeSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+	return &session, nil
+}
+
+func (r *fakeSessionRepository) GetByHash(ctx context.Context, tokenHash string) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, session := range r.sessions {
+		if session.TokenHash == tokenHash {
+			return &session, nil
+		}
+	}
+	return nil, errors.New("session not found")
+}
+
+func (r *fakeSessionRepository) ListBySubject(ctx context.Context, orgID uuid.UUID, subject string, at time.Time) ([]*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessions []*models.Session
+	for _, session := range r.sessions {
+		if session.OrgID == orgID && session.Subject == subject && session.IsActive(at) {
+			session := session
+			sessions = append(sessions, &session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeSessionRepository) Refresh(ctx context.Context, session *models.Session, previousRefreshToken string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.sessions[session.ID]
+	if !ok || stored.RevokedAt != nil || stored.RefreshToken != previousRefreshToken {
+		return false, nil
+	}
+	r.sessions[session.ID] = *session
+	return true, nil
+}
+
+func (r *fakeSessionRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if session, ok := r.sessions[id]; ok && session.RevokedAt == nil {
+		session.RevokedAt = &at
+		r.sessions[id] = session
+	}
+	return nil
+}
+
+func (r *fakeSessionRepository) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session := r.sessions[id]
+	session.LastSeenAt = at
+	r.sessions[id] = session
+	return nil
+}
+
+func (r *fakeSessionRepository) RevokeBySubject(ctx context.Context, orgID uuid.UUID, subject string, at time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for id, session := range r.sessions {
+		if session.OrgID == orgID && session.Subject == subject && session.IsActive(at) {
+			session.RevokedAt = &at
+			r.sessions[id] = session
+			count++
+		}
+	}
+	return count, nil
+}
+
+// fakeRefresher renews identities for one-time refresh tokens, rotating them
+type fakeRefresher struct {
+	mu       sync.Mutex
+	calls    int
+	valid    map[string]bool
+	identity Identity
+	err      error
+	now      func() time.Time
+	during   func() // Runs while a refresh is in flight, to race it
+}
+
+func (r *fakeRefresher) Refresh(ctx context.Context, refreshToken string) (*Identity, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	if r.during != nil {
+		r.during()
+	}
+	if r.err != nil {
+		return nil, "", r.err
+	}
+	if !r.valid[refreshToken] {
+		return nil, "", fmt.Errorf("%w: refresh token reused", ErrRefreshRejected)
+	}
+	delete(r.valid, refreshToken)
+	next := fmt.Sprintf("refresh-%d", r.calls)
+	r.valid[next] = true
+
+	identity := r.identity
+	identity.ExpiresAt = r.now().Add(time.Hour)
+	return &identity, next, nil
+}
+
+func newTestService(t *testing.T) (*SessionService, *fakeSessionRepository, *time.Time) {
+	t.Helper()
+	repo := newFakeSessionRepository()
+	svc := NewSessionService(repo, zap.NewNop())
+	require.NoError(t, svc.SetRefreshTokenKey(bytes.Repeat([]byte{7}, 32)))
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, repo, &now
+}
+
+func testIdentity(now time.Time) *Identity {
+	return &Identity{
+		Provider:  "cognito",
+		Subject:   "user-123",
+		Email:     "user@example.com",
+		OrgID:     uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		Roles:     []string{"member"},
+		ExpiresAt: now.Add(time.Hour),
+	}
+}
+
+func TestSessionService_CreateAndAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	svc, repo, now := newTestService(t)
+
+	created, err := svc.Create(ctx, testIdentity(*now), "refresh-0", ClientInfo{UserAgent: "test", IPAddress: "10.0.0.1"})
+	require.NoError(t, err)
+	assert.Contains(t, created.Token, TokenPrefix)
+	assert.Equal(t, now.Add(DefaultLifetime), created.ExpiresAt)
+
+	stored, err := repo.GetByID(ctx, created.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, stored.TokenHash, created.Token, "only the token's hash is stored")
+
+	session, err := svc.Authenticate(ctx, created.Token)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, session.ID)
+	roles, err := session.GetRoles()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"member"}, roles)
+
+	_, err = svc.Authenticate(ctx, TokenPrefix+"unknown")
+	assert.True(t, services.IsUnauthorizedError(err))
+
+	t.Run("without a refresh token the session ends with its identity", func(t *testing.T) {
+		created, err := svc.Create(ctx, testIdentity(*now), "", ClientInfo{})
+		require.NoError(t, err)
+		assert.Equal(t, now.Add(time.Hour), created.ExpiresAt)
+	})
+
+	t.Run("without a key refresh tokens are not kept", func(t *testing.T) {
+		svc := NewSessionService(repo, zap.NewNop())
+		svc.now = func() time.Time { return *now }
+		created, err := svc.Create(ctx, testIdentity(*now), "refresh-0", ClientInfo{})
+		require.NoError(t, err)
+		assert.Empty(t, created.RefreshToken)
+		assert.Equal(t, now.Add(time.Hour), created.ExpiresAt)
+	})
+
+	t.Run("expired identities are rejected", func(t *testing.T) {
+		identity := testIdentity(*now)
+		identity.ExpiresAt = now.Add(-time.Second)
+		_, err := svc.Create(ctx, identity, "refresh-0", ClientInfo{})
+		assert.True(t, services.IsValidationError(err))
+	})
+}
+
+func TestSessionService_Refresh(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("expired identities are refreshed and refresh tokens rotated", func(t *testing.T) {
+		svc, repo, now := newTestService(t)
+		refresher := &fakeRefresher{valid: map[string]bool{"refresh-0": true}, now: svc.now}
+		svc.SetRefresher("cognito", refresher)
+
+		created, err := svc.Create(ctx, testIdentity(*now), "refresh-0", ClientInfo{})
+		require.NoError(t, err)
+
+		identity := testIdentity(*now)
+		identity.Roles = []string{"admin"}
+		refresher.identity = *identity
+		*now = now.Add(2 * time.Hour)
+
+		// Concurrent requests refresh once, so the rotated token is not reused
+		var wg sync.WaitGroup
+		errs := make([]error, 8)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = svc.Authenticate(ctx, created.Token)
+			}(i)
+		}
+		wg.Wait()
+		for _, err := range errs {
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, refresher.calls)
+
+		stored, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.NotContains(t, stored.RefreshToken, "refresh-", "refresh tokens are encrypted at rest")
+		refreshToken, err := svc.open(stored.ID, stored.RefreshToken)
+		require.NoError(t, err)
+		assert.Equal(t, "refresh-1", refreshToken)
+		assert.Equal(t, now.Add(time.Hour), stored.IdentityExpiresAt)
+		roles, err := stored.GetRoles()
+		require.NoError(t, err)
+		assert.Equal(t, []string{"admin"}, roles, "the refreshed identity replaces the session's")
+	})
+
+	t.Run("rejected refresh tokens end the session", func(t *testing.T) {
+		svc, repo, now := newTestService(t)
+		refresher := &fakeRefresher{valid: map[string]bool{}, now: svc.now}
+		svc.SetRefresher("cognito", refresher)
+
+		created, err := svc.Create(ctx, testIdentity(*now), "refresh-0", ClientInfo{})
+		require.NoError(t, err)
+		*now = now.Add(2 * time.Hour)
+
+		_, err = svc.Authenticate(ctx, created.Token)
+		assert.True(t, services.IsUnauthorizedError(err))
+		stored, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, stored.RevokedAt)
+	})
+
+	t.Run("plaintext refresh tokens end the session", func(t *testing.T) {
+		svc, repo, now := newTestService(t)
+		refresher := &fakeRefresher{valid: map[string]bool{"refresh-0": true}, now: svc.now}
+		svc.SetRefresher("cognito", refresher)
+
+		created, err := svc.Create(ctx, testIdentity(*now), "refresh-0", ClientInfo{})
+		require.NoError(t, err)
+		stored, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		stored.RefreshToken = "refresh-0"
+		require.NoError(t, repo.Create(ctx, stored))
+		*now = now.Add(2 * time.Hour)
+
+		_, err = svc.Authenticate(ctx, created.Token)
+		assert.True(t, services.IsUnauthorizedError(err))
+		assert.Zero(t, refresher.calls, "the stored value is never sent to the provider")
+	})
+
+	t.Run("unreachable providers leave the session to be retried", func(t *testing.T) {
+		svc, repo, now := newTestService(t)
+		refresher := &fakeRefresher{err: errors.New("connection refused"), now: svc.now}
+		svc.SetRefresher("cognito", refresher)
+
+		created, err := svc.Create(ctx, testIdentity(*now), "refresh-0", ClientInfo{})
+		require.NoError(t, err)
+		*now = now.Add(2 * time.Hour)
+
+		_, err = svc.Authenticate(ctx, created.Token)
+		require.Error(t, err)
+		stored, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.Nil(t, stored.RevokedAt)
+	})
+
+	t.Run("a refreshed identity of another tenant ends the session", func(t *testing.T) {
+		svc, repo, now := newTestService(t)
+		refresher := &fakeRefresher{valid: map[string]bool{"refresh-0": true}, now: svc.now}
+		svc.SetRefresher("cognito", refresher)
+
+		created, err := svc.Create(ctx, testIdentity(*now), "refresh-0", ClientInfo{})
+		require.NoError(t, err)
+		identity := testIdentity(*now)
+		identity.OrgID = uuid.New()
+		refresher.identity = *identity
+		*now = now.Add(2 * time.Hour)
+
+		_, err = svc.Authenticate(ctx, created.Token)
+		assert.True(t, services.IsUnauthorizedError(err))
+		stored, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, stored.RevokedAt)
+	})
+
+	t.Run("revocations during a refresh are kept", func(t *testing.T) {
+		svc, repo, now := newTestService(t)
+		refresher := &fakeRefresher{valid: map[string]bool{"refresh-0": true}, now: svc.now}
+		svc.SetRefresher("cognito", refresher)
+
+		created, err := svc.Create(ctx, testIdentity(*now), "refresh-0", ClientInfo{})
+		require.NoError(t, err)
+		refresher.identity = *testIdentity(*now)
+		*now = now.Add(2 * time.Hour)
+		refresher.during = func() {
+			_, err := svc.RevokeAll(ctx, created.OrgID, created.Subject)
+			require.NoError(t, err)
+		}
+
+		_, err = svc.Authenticate(ctx, created.Token)
+		assert.True(t, services.IsUnauthorizedError(err))
+		stored, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, stored.RevokedAt)
+		assert.Equal(t, created.RefreshToken, stored.RefreshToken)
+	})
+
+	for name, valid := range map[string]bool{"accepted": true, "rejected": false} {
+		t.Run("refreshes by another instance are picked up when "+name, func(t *testing.T) {
+			svc, repo, now := newTestService(t)
+			refresher := &fakeRefresher{valid: map[string]bool{"refresh-0": valid}, now: svc.now}
+			svc.SetRefresher("cognito", refresher)
+
+			created, err := svc.Create(ctx, testIdentity(*now), "refresh-0", ClientInfo{})
+			require.NoError(t, err)
+			refresher.identity = *testIdentity(*now)
+			*now = now.Add(2 * time.Hour)
+			refresher.during = func() {
+				repo.mu.Lock()
+				defer repo.mu.Unlock()
+				session := repo.sessions[created.ID]
+				session.RefreshToken = "refresh-other"
+				session.IdentityExpiresAt = now.Add(time.Hour)
+				repo.sessions[created.ID] = session
+			}
+
+			session, err := svc.Authenticate(ctx, created.Token)
+			require.NoError(t, err)
+			assert.Equal(t, "refresh-other", session.RefreshToken)
+			stored, err := repo.GetByID(ctx, created.ID)
+			require.NoError(t, err)
+			assert.Nil(t, stored.RevokedAt)
+			assert.Equal(t, "refresh-other", stored.RefreshToken)
+		})
+	}
+
+}
+
+func TestSessionService_Revoke(t *testing.T) {
+	ctx := context.Background()
+	svc, _, now := newTestService(t)
+	identity := testIdentity(*now)
+
+	first, err := svc.Create(ctx, identity, "refresh-0", ClientInfo{})
+	require.NoError(t, err)
+	second, err := svc.Create(ctx, identity, "refresh-1", ClientInfo{})
+	require.NoError(t, err)
+	other := testIdentity(*now)
+	other.Subject = "user-456"
+	third, err := svc.Create(ctx, other, "refresh-2", ClientInfo{})
+	require.NoError(t, err)
+
+	sessions, err := svc.List(ctx, identity.OrgID, identity.Subject)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	t.Run("users cannot revoke other users' sessions", func(t *testing.T) {
+		err := svc.Revoke(ctx, identity.OrgID, identity.Subject, third.ID)
+		assert.True(t, services.IsNotFoundError(err))
+		err = svc.Revoke(ctx, uuid.New(), identity.Subject, first.ID)
+		assert.True(t, services.IsNotFoundError(err))
+	})
+
+	t.Run("revocation takes effect immediately", func(t *testing.T) {
+		require.NoError(t, svc.Revoke(ctx, identity.OrgID, identity.Subject, first.ID))
+		_, err := svc.Authenticate(ctx, first.Token)
+		assert.True(t, services.IsUnauthorizedError(err))
+		_, err = svc.Authenticate(ctx, second.Token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("log out everywhere", func(t *testing.T) {
+		count, err := svc.RevokeAll(ctx, identity.OrgID, identity.Subject)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		_, err = svc.Authenticate(ctx, second.Token)
+		assert.True(t, services.IsUnauthorizedError(err))
+		_, err = svc.Authenticate(ctx, third.Token)
+		assert.NoError(t, err, "other users stay signed in")
+	})
+
+	t.Run("sign out revokes the token's session", func(t *testing.T) {
+		require.NoError(t, svc.RevokeToken(ctx, third.Token))
+		_, err := svc.Authenticate(ctx, third.Token)
+		assert.True(t, services.IsUnauthorizedError(err))
+		assert.NoError(t, svc.RevokeToken(ctx, "not-a-session"))
+	})
+}
@@ -0,0 +1,257 @@
+package shadow
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/budget"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"github.com/upb/llm-control-plane/backend/services/routing"
+	"go.uber.org/zap"
+)
+
+// DefaultTimeout bounds a shadow call when the policy does not set one
+const DefaultTimeout = 30 * time.Second
+
+// storeTimeout bounds recording a shadow result, which runs after the call's
+// own deadline may have passed
+const storeTimeout = 5 * time.Second
+
+// MirrorRequest represents a primary request to be mirrored to a shadow target
+type MirrorRequest struct {
+	InferenceID uuid.UUID
+	OrgID       uuid.UUID
+	AppID       uuid.UUID
+	RequestID   string
+	Config      *models.ShadowConfig
+	Request     *providers.ChatRequest
+}
+
+// ShadowService mirrors sampled production traffic to a shadow provider or model.
+// Shadow calls run detached from the caller and never affect its response.
+type ShadowService struct {
+	routingService *routing.RoutingService
+	budgetService  *budget.BudgetService
+	shadowRepo     repositories.ShadowResponseRepository
+	logger         *zap.Logger
+	sample         func() float64
+	wg             sync.WaitGroup
+}
+
+// NewShadowService creates a new ShadowService instance
+func NewShadowService(routingService *routing.RoutingService, budgetService *budget.BudgetService, shadowRepo repositories.ShadowResponseRepository, logger *zap.Logger) *ShadowService {
+	return &ShadowService{
+		routingService: routingService,
+		budgetService:  budgetService,
+		shadowRepo:     shadowRepo,
+		logger:         logger,
+		sample:         rand.Float64,
+	}
+}
+
+// ShouldMirror decides whether a request is sampled for shadowing
+func (s *ShadowService) ShouldMirror(config *models.ShadowConfig) bool {
+	if config == nil || !config.Enabled || config.SampleRate <= 0 {
+		return false
+	}
+	if config.Provider == "" && config.Model == "" {
+		return false
+	}
+	return config.SampleRate >= 1 || s.sample() < config.SampleRate
+}
+
+// Mirror samples the request and, if selected, sends it to the shadow target
+// in the background. Returns true if a shadow call was started.
+func (s *ShadowService) Mirror(req MirrorRequest) bool {
+	if req.Request == nil || !s.ShouldMirror(req.Config) {
+		return false
+	}
+
+	// Copy before returning so later changes by the caller cannot leak into the shadow call
+	req.Request = s.buildShadowRequest(req)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				s.logger.Error("shadow request panicked",
+					zap.String("inference_id", req.InferenceID.String()),
+					zap.Any("panic", r))
+			}
+		}()
+
+		// Detached from the caller's context so the primary response is never delayed
+		timeout := DefaultTimeout
+		if req.Config.TimeoutSeconds > 0 {
+			timeout = time.Duration(req.Config.TimeoutSeconds) * time.Second
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		s.execute(ctx, req)
+	}()
+
+	return true
+}
+
+// Wait blocks until all in-flight shadow requests have finished
+func (s *ShadowService) Wait() {
+	s.wg.Wait()
+}
+
+// GetShadowResponses retrieves the shadow results stored for an inference
+// request of an organization
+func (s *ShadowService) GetShadowResponses(ctx context.Context, inferenceID, orgID uuid.UUID) ([]*models.ShadowResponse, error) {
+	results, err := s.shadowRepo.GetByInferenceID(ctx, inferenceID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to get shadow responses", err)
+	}
+
+	scoped := make([]*models.ShadowResponse, 0, len(results))
+	for _, result := range results {
+		if result.OrgID == orgID {
+			scoped = append(scoped, result)
+		}
+	}
+	return scoped, nil
+}
+
+// execute runs a single shadow call for a request built by Mirror and stores its outcome
+func (s *ShadowService) execute(ctx context.Context, req MirrorRequest) *models.ShadowResponse {
+	shadowReq := req.Request
+
+	provider, err := s.resolveProvider(req.Config, shadowReq.Model)
+	if err != nil {
+		s.logger.Warn("shadow provider unavailable",
+			zap.String("inference_id", req.InferenceID.String()),
+			zap.String("model", shadowReq.Model),
+			zap.Error(err))
+		return nil
+	}
+
+	result := models.NewShadowResponse(req.InferenceID, req.OrgID, req.AppID, provider.Name(), shadowReq.Model)
+
+	if reason := s.checkShadowBudget(ctx, req, provider, shadowReq); reason != "" {
+		result.MarkAsRejected(reason)
+		s.store(result)
+		return result
+	}
+
+	start := time.Now()
+	resp, err := provider.ChatCompletion(ctx, shadowReq)
+	latencyMs := int(time.Since(start).Milliseconds())
+	if err != nil {
+		result.MarkAsFailed(err.Error(), latencyMs)
+		s.store(result)
+		return result
+	}
+
+	cost := s.calculateCost(provider, resp)
+	content := ""
+	if len(resp.Choices) > 0 {
+		content = resp.Choices[0].Message.Content
+	}
+	result.MarkAsCompleted(content, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, latencyMs, cost)
+
+	if s.budgetService != nil {
+		recordCtx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+		if err := s.budgetService.RecordCost(recordCtx, budget.CostRecordRequest{
+			OrgID:      req.OrgID,
+			AppID:      req.AppID,
+			Scope:      budget.ScopeShadow,
+			Cost:       cost,
+			Currency:   "USD",
+			Provider:   provider.Name(),
+			Model:      shadowReq.Model,
+			RequestID:  req.RequestID,
+			TokensUsed: resp.Usage.TotalTokens,
+		}); err != nil {
+			s.logger.Error("failed to record shadow cost", zap.Error(err))
+		}
+	}
+
+	s.store(result)
+	return result
+}
+
+// buildShadowRequest copies the primary request and points it at the shadow model
+func (s *ShadowService) buildShadowRequest(req MirrorRequest) *providers.ChatRequest {
+	shadowReq := *req.Request
+	shadowReq.Messages = append([]providers.Message(nil), req.Request.Messages...)
+	shadowReq.Stream = false
+	if req.Config.Model != "" {
+		shadowReq.Model = req.Config.Model
+	}
+	return &shadowReq
+}
+
+// resolveProvider picks the configured shadow provider, or routes by model
+func (s *ShadowService) resolveProvider(config *models.ShadowConfig, model string) (providers.Provider, error) {
+	if config.Provider != "" {
+		return s.routingService.GetProvider(config.Provider)
+	}
+	return s.routingService.GetProviderForModel(model)
+}
+
+// checkShadowBudget returns a rejection reason if the shadow budget is exhausted
+func (s *ShadowService) checkShadowBudget(ctx context.Context, req MirrorRequest, provider providers.Provider, shadowReq *providers.ChatRequest) string {
+	if s.budgetService == nil || (req.Config.MaxDailyCost <= 0 && req.Config.MaxMonthlyCost <= 0) {
+		return ""
+	}
+
+	estimatedCost, err := provider.EstimateCost(shadowReq)
+	if err != nil {
+		estimatedCost = 0
+	}
+
+	result, err := s.budgetService.CheckBudget(ctx, budget.BudgetCheckRequest{
+		OrgID: req.OrgID,
+		AppID: req.AppID,
+		Scope: budget.ScopeShadow,
+		Config: &models.BudgetConfig{
+			MaxDailyCost:   req.Config.MaxDailyCost,
+			MaxMonthlyCost: req.Config.MaxMonthlyCost,
+			Currency:       "USD",
+		},
+		Cost: estimatedCost,
+	})
+	if err != nil {
+		return fmt.Sprintf("shadow budget check failed: %v", err)
+	}
+	if !result.Allowed {
+		return result.ViolationReason
+	}
+	return ""
+}
+
+// calculateCost prices the shadow response from the model's token pricing
+func (s *ShadowService) calculateCost(provider providers.Provider, resp *providers.ChatResponse) float64 {
+	modelInfo, err := provider.GetModelInfo(resp.Model)
+	if err != nil {
+		return 0
+	}
+	return float64(resp.Usage.PromptTokens)*modelInfo.PricingPerPromptToken +
+		float64(resp.Usage.CompletionTokens)*modelInfo.PricingPerCompletionToken
+}
+
+// store persists a shadow result, logging rather than propagating failures.
+// It does not use the call's context, which has expired when the call timed out.
+func (s *ShadowService) store(result *models.ShadowResponse) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	if err := s.shadowRepo.Create(ctx, result); err != nil {
+		s.logger.Error("failed to store shadow response",
+			zap.String("inference_id", result.InferenceID.String()),
+			zap.Error(err))
+	}
+}
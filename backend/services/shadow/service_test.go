@@ -0,0 +1,230 @@
+package shadow
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services/providers"
+	"github.com/upb/llm-control-plane/backend/services/routing"
+	"go.uber.org/zap"
+)
+
+// MockShadowResponseRepository is a mock implementation of ShadowResponseRepository
+type MockShadowResponseRepository struct {
+	mock.Mock
+}
+
+func (m *MockShadowResponseRepository) Create(ctx context.Context, resp *models.ShadowResponse) error {
+	args := m.Called(ctx, resp)
+	return args.Error(0)
+}
+
+func (m *MockShadowResponseRepository) GetByInferenceID(ctx context.Context, inferenceID uuid.UUID) ([]*models.ShadowResponse, error) {
+	args := m.Called(ctx, inferenceID)
+	if resps := args.Get(0); resps != nil {
+		return resps.([]*models.ShadowResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *MockShadowResponseRepository) WithTx(tx repositories.Transaction) repositories.ShadowResponseRepository {
+	return m
+}
+
+// fakeProvider is a minimal provider returning a canned response
+type fakeProvider struct {
+	name string
+	err  error
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) ChatCompletion(ctx context.Context, req *providers.ChatRequest) (*providers.ChatResponse, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &providers.ChatResponse{
+		Model:    req.Model,
+		Provider: p.name,
+		Choices: []providers.Choice{
+			{Message: providers.Message{Role: "assistant", Content: "shadow answer"}, FinishReason: "stop"},
+		},
+		Usage: providers.Usage{PromptTokens: 100, CompletionTokens: 50, TotalTokens: 150},
+	}, nil
+}
+
+func (p *fakeProvider) IsAvailable(ctx context.Context) bool { return true }
+
+func (p *fakeProvider) EstimateCost(req *providers.ChatRequest) (float64, error) { return 0.001, nil }
+
+func (p *fakeProvider) ValidateModel(model string) error {
+	if model == "shadow-model" {
+		return nil
+	}
+	return providers.ErrModelNotSupported
+}
+
+func (p *fakeProvider) GetModelInfo(model string) (*providers.ModelInfo, error) {
+	return &providers.ModelInfo{ID: model, PricingPerPromptToken: 0.001, PricingPerCompletionToken: 0.002}, nil
+}
+
+func (p *fakeProvider) ListModels() []string { return []string{"shadow-model"} }
+
+func newTestService(t *testing.T, provider providers.Provider, repo *MockShadowResponseRepository) *ShadowService {
+	registry := providers.NewRegistry()
+	require.NoError(t, registry.RegisterProvider(provider))
+	routingService := routing.NewRoutingService(routing.DefaultRoutingConfig(), registry)
+	return NewShadowService(routingService, nil, repo, zap.NewNop())
+}
+
+func newMirrorRequest(config *models.ShadowConfig) MirrorRequest {
+	return MirrorRequest{
+		InferenceID: uuid.New(),
+		OrgID:       uuid.New(),
+		AppID:       uuid.New(),
+		RequestID:   "req-1",
+		Config:      config,
+		Request: &providers.ChatRequest{
+			Model:    "gpt-4",
+			Messages: []providers.Message{{Role: "user", Content: "hello"}},
+			Stream:   true,
+		},
+	}
+}
+
+func TestShadowService_ShouldMirror(t *testing.T) {
+	service := NewShadowService(nil, nil, nil, zap.NewNop())
+	service.sample = func() float64 { return 0.5 }
+
+	tests := []struct {
+		name     string
+		config   *models.ShadowConfig
+		expected bool
+	}{
+		{name: "nil config", config: nil, expected: false},
+		{name: "disabled", config: &models.ShadowConfig{Enabled: false, Model: "m", SampleRate: 1}, expected: false},
+		{name: "no target", config: &models.ShadowConfig{Enabled: true, SampleRate: 1}, expected: false},
+		{name: "zero rate", config: &models.ShadowConfig{Enabled: true, Model: "m"}, expected: false},
+		{name: "sampled in", config: &models.ShadowConfig{Enabled: true, Model: "m", SampleRate: 0.6}, expected: true},
+		{name: "sampled out", config: &models.ShadowConfig{Enabled: true, Model: "m", SampleRate: 0.4}, expected: false},
+		{name: "full rate", config: &models.ShadowConfig{Enabled: true, Provider: "p", SampleRate: 1}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, service.ShouldMirror(tt.config))
+		})
+	}
+}
+
+func TestShadowService_Mirror(t *testing.T) {
+	repo := new(MockShadowResponseRepository)
+	service := newTestService(t, &fakeProvider{name: "shadow"}, repo)
+
+	req := newMirrorRequest(&models.ShadowConfig{Enabled: true, Model: "shadow-model", SampleRate: 1})
+
+	var stored *models.ShadowResponse
+	repo.On("Create", mock.Anything, mock.AnythingOfType("*models.ShadowResponse")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*models.ShadowResponse) }).
+		Return(nil)
+
+	assert.True(t, service.Mirror(req))
+	service.Wait()
+
+	require.NotNil(t, stored)
+	assert.Equal(t, req.InferenceID, stored.InferenceID)
+	assert.Equal(t, "shadow", stored.Provider)
+	assert.Equal(t, "shadow-model", stored.Model)
+	assert.Equal(t, models.InferenceStatusCompleted, stored.Status)
+	assert.Equal(t, "shadow answer", *stored.Response)
+	assert.InDelta(t, 0.2, stored.Cost, 1e-9)
+
+	// The caller's request must not be modified
+	assert.Equal(t, "gpt-4", req.Request.Model)
+	assert.True(t, req.Request.Stream)
+}
+
+func TestShadowService_Mirror_NotSampled(t *testing.T) {
+	repo := new(MockShadowResponseRepository)
+	service := newTestService(t, &fakeProvider{name: "shadow"}, repo)
+	service.sample = func() float64 { return 0.9 }
+
+	req := newMirrorRequest(&models.ShadowConfig{Enabled: true, Model: "shadow-model", SampleRate: 0.1})
+
+	assert.False(t, service.Mirror(req))
+	service.Wait()
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestShadowService_Execute_ProviderError(t *testing.T) {
+	repo := new(MockShadowResponseRepository)
+	service := newTestService(t, &fakeProvider{name: "shadow", err: errors.New("upstream timeout")}, repo)
+	repo.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+	req := newMirrorRequest(&models.ShadowConfig{Enabled: true, Provider: "shadow", SampleRate: 1})
+	result := service.execute(context.Background(), req)
+
+	require.NotNil(t, result)
+	assert.Equal(t, models.InferenceStatusFailed, result.Status)
+	assert.Equal(t, "upstream timeout", *result.ErrorMessage)
+	// Without a configured model the primary model is mirrored
+	assert.Equal(t, "gpt-4", result.Model)
+	repo.AssertExpectations(t)
+}
+
+func TestShadowService_Execute_UnknownProvider(t *testing.T) {
+	repo := new(MockShadowResponseRepository)
+	service := newTestService(t, &fakeProvider{name: "shadow"}, repo)
+
+	req := newMirrorRequest(&models.ShadowConfig{Enabled: true, Provider: "missing", SampleRate: 1})
+
+	assert.Nil(t, service.execute(context.Background(), req))
+	repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestShadowService_Execute_StoresAfterTimeout(t *testing.T) {
+	repo := new(MockShadowResponseRepository)
+	service := newTestService(t, &fakeProvider{name: "shadow", err: context.DeadlineExceeded}, repo)
+
+	var storeErr error
+	repo.On("Create", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { storeErr = args.Get(0).(context.Context).Err() }).
+		Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req := newMirrorRequest(&models.ShadowConfig{Enabled: true, Provider: "shadow", SampleRate: 1})
+	result := service.execute(ctx, req)
+
+	require.NotNil(t, result)
+	assert.Equal(t, models.InferenceStatusFailed, result.Status)
+	// The result is stored even though the call's context has ended
+	repo.AssertExpectations(t)
+	assert.NoError(t, storeErr)
+}
+
+func TestShadowService_GetShadowResponses(t *testing.T) {
+	repo := new(MockShadowResponseRepository)
+	service := NewShadowService(nil, nil, repo, zap.NewNop())
+
+	inferenceID, orgID := uuid.New(), uuid.New()
+	own := models.NewShadowResponse(inferenceID, orgID, uuid.New(), "shadow", "shadow-model")
+	other := models.NewShadowResponse(inferenceID, uuid.New(), uuid.New(), "shadow", "shadow-model")
+	repo.On("GetByInferenceID", mock.Anything, inferenceID).Return([]*models.ShadowResponse{own, other}, nil)
+
+	results, err := service.GetShadowResponses(context.Background(), inferenceID, orgID)
+	require.NoError(t, err)
+	assert.Equal(t, []*models.ShadowResponse{own}, results)
+
+	results, err = service.GetShadowResponses(context.Background(), inferenceID, uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
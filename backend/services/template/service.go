@@ -0,0 +1,334 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"go.uber.org/zap"
+)
+
+// CreateTemplateRequest represents a request to create a template with its first version
+type CreateTemplateRequest struct {
+	OrgID       uuid.UUID
+	Name        string
+	Description string
+	Messages    []models.TemplateMessage
+	Variables   []models.TemplateVariable
+	CreatedBy   *uuid.UUID
+}
+
+// CreateVersionRequest represents a request to add a version to a template
+type CreateVersionRequest struct {
+	Messages  []models.TemplateMessage
+	Variables []models.TemplateVariable
+	CreatedBy *uuid.UUID
+	Activate  bool // Make the new version the template's active version
+}
+
+// RenderRequest represents a request to render a template reference
+type RenderRequest struct {
+	OrgID     uuid.UUID
+	AppID     uuid.UUID
+	Ref       string // "template@version", where template is an ID or name
+	Variables map[string]string
+}
+
+// Rendered represents a rendered template version
+type Rendered struct {
+	TemplateID uuid.UUID                `json:"template_id"`
+	Name       string                   `json:"name"`
+	Version    int                      `json:"version"`
+	Messages   []models.TemplateMessage `json:"messages"`
+}
+
+// TemplateService manages versioned prompt templates and renders them for inference
+type TemplateService struct {
+	templateRepo repositories.PromptTemplateRepository
+	appRepo      repositories.ApplicationRepository
+	txManager    repositories.TransactionManager
+	logger       *zap.Logger
+}
+
+// NewTemplateService creates a new TemplateService instance
+func NewTemplateService(templateRepo repositories.PromptTemplateRepository, appRepo repositories.ApplicationRepository, txManager repositories.TransactionManager, logger *zap.Logger) *TemplateService {
+	return &TemplateService{
+		templateRepo: templateRepo,
+		appRepo:      appRepo,
+		txManager:    txManager,
+		logger:       logger,
+	}
+}
+
+// CreateTemplate creates a template and stores its first version as the active one
+func (s *TemplateService) CreateTemplate(ctx context.Context, req CreateTemplateRequest) (*models.PromptTemplate, error) {
+	if req.Name == "" {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "name is required", nil)
+	}
+
+	template := models.NewPromptTemplate(req.OrgID, req.Name, req.Description)
+	version, err := models.NewPromptTemplateVersion(template.ID, req.Messages, req.Variables)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), nil)
+	}
+	version.CreatedBy = req.CreatedBy
+
+	if existing, err := s.templateRepo.GetByName(ctx, req.OrgID, req.Name); err == nil && existing != nil {
+		return nil, services.NewDomainError(services.ErrorTypeConflict, fmt.Sprintf("template %q already exists", req.Name), nil)
+	}
+
+	err = s.txManager.InTransaction(ctx, func(ctx context.Context, tx repositories.Transaction) error {
+		if err := s.templateRepo.Create(ctx, template); err != nil {
+			return err
+		}
+		if err := s.templateRepo.CreateVersion(ctx, version); err != nil {
+			return err
+		}
+		template.SetActiveVersion(version.Version)
+		return s.templateRepo.Update(ctx, template)
+	})
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create template", err)
+	}
+
+	s.logger.Info("prompt template created",
+		zap.String("template_id", template.ID.String()),
+		zap.String("org_id", req.OrgID.String()),
+		zap.String("name", req.Name))
+
+	return template, nil
+}
+
+// GetTemplate retrieves a template scoped to an organization
+func (s *TemplateService) GetTemplate(ctx context.Context, id, orgID uuid.UUID) (*models.PromptTemplate, error) {
+	template, err := s.templateRepo.GetByID(ctx, id)
+	if err != nil || template.OrgID != orgID {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "template not found", err)
+	}
+	return template, nil
+}
+
+// ListTemplates lists all templates for an organization
+func (s *TemplateService) ListTemplates(ctx context.Context, orgID uuid.UUID) ([]*models.PromptTemplate, error) {
+	templates, err := s.templateRepo.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list templates", err)
+	}
+	return templates, nil
+}
+
+// DeleteTemplate deletes a template with all of its versions and pins
+func (s *TemplateService) DeleteTemplate(ctx context.Context, id, orgID uuid.UUID) error {
+	if _, err := s.GetTemplate(ctx, id, orgID); err != nil {
+		return err
+	}
+	if err := s.templateRepo.Delete(ctx, id); err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to delete template", err)
+	}
+	return nil
+}
+
+// CreateVersion adds a new version to a template
+func (s *TemplateService) CreateVersion(ctx context.Context, id, orgID uuid.UUID, req CreateVersionRequest) (*models.PromptTemplateVersion, error) {
+	template, err := s.GetTemplate(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := models.NewPromptTemplateVersion(template.ID, req.Messages, req.Variables)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), nil)
+	}
+	version.CreatedBy = req.CreatedBy
+
+	if err := s.templateRepo.CreateVersion(ctx, version); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create template version", err)
+	}
+
+	if req.Activate {
+		template.SetActiveVersion(version.Version)
+		if err := s.templateRepo.Update(ctx, template); err != nil {
+			return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to activate template version", err)
+		}
+	}
+
+	s.logger.Info("prompt template version created",
+		zap.String("template_id", template.ID.String()),
+		zap.Int("version", version.Version),
+		zap.Bool("activated", req.Activate))
+
+	return version, nil
+}
+
+// ListVersions lists all versions of a template, newest first
+func (s *TemplateService) ListVersions(ctx context.Context, id, orgID uuid.UUID) ([]*models.PromptTemplateVersion, error) {
+	if _, err := s.GetTemplate(ctx, id, orgID); err != nil {
+		return nil, err
+	}
+
+	versions, err := s.templateRepo.ListVersions(ctx, id)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list template versions", err)
+	}
+	return versions, nil
+}
+
+// GetVersion retrieves a specific version of a template
+func (s *TemplateService) GetVersion(ctx context.Context, id, orgID uuid.UUID, version int) (*models.PromptTemplateVersion, error) {
+	if _, err := s.GetTemplate(ctx, id, orgID); err != nil {
+		return nil, err
+	}
+	return s.getVersion(ctx, id, version)
+}
+
+// Rollback makes an earlier version the active one. A zero version rolls back
+// to the version before the currently active one.
+func (s *TemplateService) Rollback(ctx context.Context, id, orgID uuid.UUID, version int) (*models.PromptTemplate, error) {
+	template, err := s.GetTemplate(ctx, id, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if version == 0 {
+		version = template.ActiveVersion - 1
+	}
+	if version <= 0 {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, "no earlier version to roll back to", nil)
+	}
+	if _, err := s.getVersion(ctx, id, version); err != nil {
+		return nil, err
+	}
+
+	previous := template.ActiveVersion
+	template.SetActiveVersion(version)
+	if err := s.templateRepo.Update(ctx, template); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to roll back template", err)
+	}
+
+	s.logger.Info("prompt template rolled back",
+		zap.String("template_id", template.ID.String()),
+		zap.Int("from_version", previous),
+		zap.Int("to_version", version))
+
+	return template, nil
+}
+
+// PinVersion pins an application to a template version, overriding the active version
+func (s *TemplateService) PinVersion(ctx context.Context, id, orgID, appID uuid.UUID, version int) (*models.PromptTemplatePin, error) {
+	if _, err := s.GetTemplate(ctx, id, orgID); err != nil {
+		return nil, err
+	}
+	if err := s.checkApp(ctx, appID, orgID); err != nil {
+		return nil, err
+	}
+	if _, err := s.getVersion(ctx, id, version); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pin := &models.PromptTemplatePin{
+		TemplateID: id,
+		AppID:      appID,
+		Version:    version,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.templateRepo.SetPin(ctx, pin); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to pin template version", err)
+	}
+	return pin, nil
+}
+
+// UnpinVersion removes an application's pin so it follows the active version again
+func (s *TemplateService) UnpinVersion(ctx context.Context, id, orgID, appID uuid.UUID) error {
+	if _, err := s.GetTemplate(ctx, id, orgID); err != nil {
+		return err
+	}
+	if err := s.templateRepo.DeletePin(ctx, id, appID); err != nil {
+		return services.NewDomainError(services.ErrorTypeNotFound, "template pin not found", err)
+	}
+	return nil
+}
+
+// Resolve finds the template version a reference points to for an application.
+// An explicit version wins; otherwise the app's pin, then the active version.
+func (s *TemplateService) Resolve(ctx context.Context, orgID, appID uuid.UUID, ref string) (*models.PromptTemplate, *models.PromptTemplateVersion, error) {
+	parsed, err := models.ParseTemplateRef(ref)
+	if err != nil {
+		return nil, nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), nil)
+	}
+
+	var template *models.PromptTemplate
+	if id, err := uuid.Parse(parsed.Template); err == nil {
+		template, err = s.GetTemplate(ctx, id, orgID)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		template, err = s.templateRepo.GetByName(ctx, orgID, parsed.Template)
+		if err != nil {
+			return nil, nil, services.NewDomainError(services.ErrorTypeNotFound, "template not found", err)
+		}
+	}
+
+	version := parsed.Version
+	if version == 0 {
+		pin, err := s.templateRepo.GetPin(ctx, template.ID, appID)
+		if err != nil {
+			return nil, nil, services.NewDomainError(services.ErrorTypeInternal, "failed to get template pin", err)
+		}
+		if pin != nil {
+			version = pin.Version
+		} else {
+			version = template.ActiveVersion
+		}
+	}
+
+	v, err := s.getVersion(ctx, template.ID, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return template, v, nil
+}
+
+// Render resolves a template reference and substitutes its variables
+func (s *TemplateService) Render(ctx context.Context, req RenderRequest) (*Rendered, error) {
+	template, version, err := s.Resolve(ctx, req.OrgID, req.AppID, req.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := version.Render(req.Variables)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), nil)
+	}
+
+	return &Rendered{
+		TemplateID: template.ID,
+		Name:       template.Name,
+		Version:    version.Version,
+		Messages:   messages,
+	}, nil
+}
+
+// getVersion retrieves a version, mapping lookup failures to not found
+func (s *TemplateService) getVersion(ctx context.Context, id uuid.UUID, version int) (*models.PromptTemplateVersion, error) {
+	v, err := s.templateRepo.GetVersion(ctx, id, version)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, fmt.Sprintf("template version %d not found", version), err)
+	}
+	return v, nil
+}
+
+// checkApp verifies that an application belongs to the organization
+func (s *TemplateService) checkApp(ctx context.Context, appID, orgID uuid.UUID) error {
+	app, err := s.appRepo.GetByID(ctx, appID)
+	if err != nil || app.OrgID != orgID {
+		return services.NewDomainError(services.ErrorTypeNotFound, "application not found", err)
+	}
+	return nil
+}
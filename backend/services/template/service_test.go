@@ -0,0 +1,314 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"go.uber.org/zap"
+)
+
+// fakeTemplateRepository is an in-memory PromptTemplateRepository
+type fakeTemplateRepository struct {
+	templates map[uuid.UUID]*models.PromptTemplate
+	versions  map[uuid.UUID][]*models.PromptTemplateVersion
+	pins      map[string]*models.PromptTemplatePin
+}
+
+func newFakeTemplateRepository() *fakeTemplateRepository {
+	return &fakeTemplateRepository{
+		templates: map[uuid.UUID]*models.PromptTemplate{},
+		versions:  map[uuid.UUID][]*models.PromptTemplateVersion{},
+		pins:      map[string]*models.PromptTemplatePin{},
+	}
+}
+
+func pinKey(templateID, appID uuid.UUID) string {
+	return templateID.String() + ":" + appID.String()
+}
+
+func (r *fakeTemplateRepository) Create(ctx context.Context, template *models.PromptTemplate) error {
+	r.templates[template.ID] = template
+	return nil
+}
+
+func (r *fakeTemplateRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PromptTemplate, error) {
+	if t, ok := r.templates[id]; ok {
+		return t, nil
+	}
+	return nil, fmt.Errorf("prompt template not found: %s", id)
+}
+
+func (r *fakeTemplateRepository) GetByName(ctx context.Context, orgID uuid.UUID, name string) (*models.PromptTemplate, error) {
+	for _, t := range r.templates {
+		if t.OrgID == orgID && t.Name == name {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("prompt template not found")
+}
+
+func (r *fakeTemplateRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID) ([]*models.PromptTemplate, error) {
+	var templates []*models.PromptTemplate
+	for _, t := range r.templates {
+		if t.OrgID == orgID {
+			templates = append(templates, t)
+		}
+	}
+	return templates, nil
+}
+
+func (r *fakeTemplateRepository) Update(ctx context.Context, template *models.PromptTemplate) error {
+	r.templates[template.ID] = template
+	return nil
+}
+
+func (r *fakeTemplateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	delete(r.templates, id)
+	return nil
+}
+
+func (r *fakeTemplateRepository) CreateVersion(ctx context.Context, version *models.PromptTemplateVersion) error {
+	version.Version = len(r.versions[version.TemplateID]) + 1
+	r.versions[version.TemplateID] = append(r.versions[version.TemplateID], version)
+	return nil
+}
+
+func (r *fakeTemplateRepository) GetVersion(ctx context.Context, templateID uuid.UUID, version int) (*models.PromptTemplateVersion, error) {
+	versions := r.versions[templateID]
+	if version <= 0 || version > len(versions) {
+		return nil, fmt.Errorf("prompt template version not found: %s@%d", templateID, version)
+	}
+	return versions[version-1], nil
+}
+
+func (r *fakeTemplateRepository) ListVersions(ctx context.Context, templateID uuid.UUID) ([]*models.PromptTemplateVersion, error) {
+	return r.versions[templateID], nil
+}
+
+func (r *fakeTemplateRepository) SetPin(ctx context.Context, pin *models.PromptTemplatePin) error {
+	r.pins[pinKey(pin.TemplateID, pin.AppID)] = pin
+	return nil
+}
+
+func (r *fakeTemplateRepository) GetPin(ctx context.Context, templateID, appID uuid.UUID) (*models.PromptTemplatePin, error) {
+	return r.pins[pinKey(templateID, appID)], nil
+}
+
+func (r *fakeTemplateRepository) DeletePin(ctx context.Context, templateID, appID uuid.UUID) error {
+	if _, ok := r.pins[pinKey(templateID, appID)]; !ok {
+		return fmt.Errorf("prompt template pin not found: %s", templateID)
+	}
+	delete(r.pins, pinKey(templateID, appID))
+	return nil
+}
+
+func (r *fakeTemplateRepository) WithTx(tx repositories.Transaction) repositories.PromptTemplateRepository {
+	return r
+}
+
+// fakeAppRepository serves applications from a map; other methods are unused
+type fakeAppRepository struct {
+	repositories.ApplicationRepository
+	apps map[uuid.UUID]*models.Application
+}
+
+func (r *fakeAppRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Application, error) {
+	if app, ok := r.apps[id]; ok {
+		return app, nil
+	}
+	return nil, fmt.Errorf("application not found: %s", id)
+}
+
+// fakeTxManager runs transactional functions directly
+type fakeTxManager struct{}
+
+func (fakeTxManager) Begin(ctx context.Context) (repositories.Transaction, error) {
+	return nil, nil
+}
+
+func (fakeTxManager) InTransaction(ctx context.Context, fn func(ctx context.Context, tx repositories.Transaction) error) error {
+	return fn(ctx, nil)
+}
+
+type templateFixture struct {
+	service *TemplateService
+	repo    *fakeTemplateRepository
+	orgID   uuid.UUID
+	appID   uuid.UUID
+}
+
+func newTemplateFixture() *templateFixture {
+	orgID, appID := uuid.New(), uuid.New()
+	repo := newFakeTemplateRepository()
+	apps := &fakeAppRepository{apps: map[uuid.UUID]*models.Application{
+		appID: {ID: appID, OrgID: orgID},
+	}}
+	return &templateFixture{
+		service: NewTemplateService(repo, apps, fakeTxManager{}, zap.NewNop()),
+		repo:    repo,
+		orgID:   orgID,
+		appID:   appID,
+	}
+}
+
+func (f *templateFixture) createTemplate(t *testing.T, content string) *models.PromptTemplate {
+	t.Helper()
+	template, err := f.service.CreateTemplate(context.Background(), CreateTemplateRequest{
+		OrgID:    f.orgID,
+		Name:     "support",
+		Messages: []models.TemplateMessage{{Role: "system", Content: content}},
+	})
+	require.NoError(t, err)
+	return template
+}
+
+func (f *templateFixture) addVersion(t *testing.T, id uuid.UUID, content string, activate bool) {
+	t.Helper()
+	_, err := f.service.CreateVersion(context.Background(), id, f.orgID, CreateVersionRequest{
+		Messages: []models.TemplateMessage{{Role: "system", Content: content}},
+		Activate: activate,
+	})
+	require.NoError(t, err)
+}
+
+func (f *templateFixture) render(t *testing.T, ref string) *Rendered {
+	t.Helper()
+	rendered, err := f.service.Render(context.Background(), RenderRequest{
+		OrgID:     f.orgID,
+		AppID:     f.appID,
+		Ref:       ref,
+		Variables: map[string]string{"product": "Widget"},
+	})
+	require.NoError(t, err)
+	return rendered
+}
+
+func TestTemplateService_CreateTemplate(t *testing.T) {
+	f := newTemplateFixture()
+
+	template := f.createTemplate(t, "You support {{product}}.")
+	assert.Equal(t, 1, template.ActiveVersion)
+	assert.Len(t, f.repo.versions[template.ID], 1)
+
+	t.Run("duplicate name", func(t *testing.T) {
+		_, err := f.service.CreateTemplate(context.Background(), CreateTemplateRequest{
+			OrgID:    f.orgID,
+			Name:     "support",
+			Messages: []models.TemplateMessage{{Role: "system", Content: "x"}},
+		})
+		assert.True(t, services.IsConflictError(err))
+	})
+
+	t.Run("invalid messages", func(t *testing.T) {
+		_, err := f.service.CreateTemplate(context.Background(), CreateTemplateRequest{
+			OrgID:    f.orgID,
+			Name:     "other",
+			Messages: []models.TemplateMessage{{Role: "tool", Content: "x"}},
+		})
+		assert.True(t, services.IsValidationError(err))
+	})
+}
+
+func TestTemplateService_GetTemplate_OtherOrg(t *testing.T) {
+	f := newTemplateFixture()
+	template := f.createTemplate(t, "hi")
+
+	_, err := f.service.GetTemplate(context.Background(), template.ID, uuid.New())
+	assert.True(t, services.IsNotFoundError(err))
+}
+
+func TestTemplateService_ResolvePrecedence(t *testing.T) {
+	f := newTemplateFixture()
+	template := f.createTemplate(t, "v1 {{product}}")
+	f.addVersion(t, template.ID, "v2 {{product}}", true)
+	f.addVersion(t, template.ID, "v3 {{product}}", false)
+
+	rendered := f.render(t, "support")
+	assert.Equal(t, 2, rendered.Version, "active version is the default")
+	assert.Equal(t, "v2 Widget", rendered.Messages[0].Content)
+
+	_, err := f.service.PinVersion(context.Background(), template.ID, f.orgID, f.appID, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 3, f.render(t, "support").Version, "pin overrides the active version")
+
+	assert.Equal(t, 1, f.render(t, "support@1").Version, "explicit version overrides the pin")
+	assert.Equal(t, 1, f.render(t, template.ID.String()+"@1").Version, "templates resolve by ID")
+
+	require.NoError(t, f.service.UnpinVersion(context.Background(), template.ID, f.orgID, f.appID))
+	assert.Equal(t, 2, f.render(t, "support").Version)
+}
+
+func TestTemplateService_Rollback(t *testing.T) {
+	f := newTemplateFixture()
+	template := f.createTemplate(t, "v1")
+	f.addVersion(t, template.ID, "v2", true)
+	f.addVersion(t, template.ID, "v3", true)
+
+	rolled, err := f.service.Rollback(context.Background(), template.ID, f.orgID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rolled.ActiveVersion)
+
+	rolled, err = f.service.Rollback(context.Background(), template.ID, f.orgID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rolled.ActiveVersion)
+
+	_, err = f.service.Rollback(context.Background(), template.ID, f.orgID, 0)
+	assert.True(t, services.IsValidationError(err), "no version before 1")
+
+	_, err = f.service.Rollback(context.Background(), template.ID, f.orgID, 9)
+	assert.True(t, services.IsNotFoundError(err))
+}
+
+func TestTemplateService_PinVersion_Validation(t *testing.T) {
+	f := newTemplateFixture()
+	template := f.createTemplate(t, "v1")
+
+	_, err := f.service.PinVersion(context.Background(), template.ID, f.orgID, uuid.New(), 1)
+	assert.True(t, services.IsNotFoundError(err), "unknown application")
+
+	otherOrgApp := uuid.New()
+	f.service.appRepo.(*fakeAppRepository).apps[otherOrgApp] = &models.Application{ID: otherOrgApp, OrgID: uuid.New()}
+	_, err = f.service.PinVersion(context.Background(), template.ID, f.orgID, otherOrgApp, 1)
+	assert.True(t, services.IsNotFoundError(err), "application from another organization")
+
+	_, err = f.service.PinVersion(context.Background(), template.ID, f.orgID, f.appID, 2)
+	assert.True(t, services.IsNotFoundError(err), "unknown version")
+}
+
+func TestTemplateService_RenderErrors(t *testing.T) {
+	f := newTemplateFixture()
+	f.createTemplate(t, "You support {{product}}.")
+
+	tests := []struct {
+		name      string
+		ref       string
+		variables map[string]string
+		check     func(error) bool
+	}{
+		{"bad reference", "support@latest", nil, services.IsValidationError},
+		{"unknown template", "billing", nil, services.IsNotFoundError},
+		{"unknown version", "support@5", nil, services.IsNotFoundError},
+		{"missing variable", "support", nil, services.IsValidationError},
+		{"unknown variable", "support", map[string]string{"product": "x", "tone": "y"}, services.IsValidationError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.service.Render(context.Background(), RenderRequest{
+				OrgID:     f.orgID,
+				AppID:     f.appID,
+				Ref:       tt.ref,
+				Variables: tt.variables,
+			})
+			require.Error(t, err)
+			assert.True(t, tt.check(err), "unexpected error: %v", err)
+		})
+	}
+}
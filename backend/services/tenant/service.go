@@ -0,0 +1,721 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/apikey"
+	"github.com/upb/llm-control-plane/backend/services/audit"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultListLimit is the page size of organization listings
+	DefaultListLimit = 20
+	// MaxListLimit caps the page size of organization listings
+	MaxListLimit = 100
+
+	// DefaultRestoreWindow is how long deleted organizations and applications
+	// can be restored
+	DefaultRestoreWindow = 30 * 24 * time.Hour
+
+	// MaxNameLength is the maximum length of organization and application names
+	MaxNameLength = 255
+
+	// initialKeyName names the API key every application is created with
+	initialKeyName = "Default"
+)
+
+// KeyIssuer issues application API keys
+type KeyIssuer interface {
+	Issue(ctx context.Context, req apikey.IssueRequest) (*apikey.IssuedKey, error)
+}
+
+// RoleResolver resolves an organization's effective roles and the permissions they grant
+type RoleResolver interface {
+	ListRoles(ctx context.Context, orgID uuid.UUID) ([]*models.Role, error)
+	HasPermission(ctx context.Context, orgID uuid.UUID, roles []string, permission models.Permission) (bool, error)
+}
+
+// CreateOrganizationRequest represents a request to create an organization
+type CreateOrganizationRequest struct {
+	Name string
+	Slug string
+}
+
+// UpdateOrganizationRequest represents a request to rename an organization
+// or change its slug; nil fields are unchanged
+type UpdateOrganizationRequest struct {
+	ID   uuid.UUID
+	Name *string
+	Slug *string
+}
+
+// CreateApplicationRequest represents a request to create an application
+type CreateApplicationRequest struct {
+	OrgID uuid.UUID // The caller's organization when uuid.Nil
+	Name  string
+}
+
+// UpdateApplicationRequest represents a request to rename an application
+type UpdateApplicationRequest struct {
+	ID   uuid.UUID
+	Name string
+}
+
+// UpdateUserRequest represents a request to change a user's role or display
+// name; nil fields are unchanged
+type UpdateUserRequest struct {
+	ID          uuid.UUID
+	Role        *string
+	DisplayName *string
+}
+
+// CreatedApplication is a new application with its initial API key, whose
+// plaintext is only available at creation
+type CreatedApplication struct {
+	*models.Application
+	APIKey *apikey.IssuedKey `json:"api_key"`
+}
+
+// Scope identifies the caller an operation runs for. Queries are restricted
+// to the caller's organization; only a superadmin may reach other
+// organizations, and every such access is audited. Roles and, for scoped
+// tokens, Scopes bound the permissions the caller may assign to users.
+type Scope struct {
+	OrgID      uuid.UUID
+	UserID     *uuid.UUID
+	Subject    string
+	SuperAdmin bool
+	Roles      []string
+	Scopes     []string
+}
+
+// TenantService manages organizations, applications and users within the
+// caller's tenant
+type TenantService struct {
+	orgRepo       repositories.OrganizationRepository
+	appRepo       repositories.ApplicationRepository
+	userRepo      repositories.UserRepository
+	keys          KeyIssuer
+	txManager     repositories.TransactionManager
+	roles         RoleResolver
+	auditService  *audit.AuditService
+	logger        *zap.Logger
+	restoreWindow time.Duration
+	now           func() time.Time
+}
+
+// NewTenantService creates a new TenantService instance
+func NewTenantService(
+	orgRepo repositories.OrganizationRepository,
+	appRepo repositories.ApplicationRepository,
+	userRepo repositories.UserRepository,
+	keys KeyIssuer,
+	txManager repositories.TransactionManager,
+	roles RoleResolver,
+	logger *zap.Logger,
+) *TenantService {
+	return &TenantService{
+		orgRepo:       orgRepo,
+		appRepo:       appRepo,
+		userRepo:      userRepo,
+		keys:          keys,
+		txManager:     txManager,
+		roles:         roles,
+		logger:        logger,
+		restoreWindow: DefaultRestoreWindow,
+		now:           time.Now,
+	}
+}
+
+// SetAuditService records changes, and superadmin accesses to other
+// organizations, in the audit log
+func (s *TenantService) SetAuditService(auditService *audit.AuditService) {
+	s.auditService = auditService
+}
+
+// GetOrganization retrieves an organization; other organizations are not found
+// unless the caller is a superadmin
+func (s *TenantService) GetOrganization(ctx context.Context, scope Scope, id uuid.UUID) (*models.Organization, error) {
+	org, err := s.getOrganization(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+	s.checkCrossed(scope, org.ID, "organization", &org.ID, "get")
+
+	return org, nil
+}
+
+// ListOrganizations lists the caller's organization, or all organizations for a superadmin
+func (s *TenantService) ListOrganizations(ctx context.Context, scope Scope, limit, offset int) ([]*models.Organization, error) {
+	if !scope.SuperAdmin {
+		org, err := s.orgRepo.GetByID(ctx, scope.OrgID)
+		if err != nil {
+			return nil, services.NewDomainError(services.ErrorTypeNotFound, "organization not found", err)
+		}
+		if offset > 0 {
+			return []*models.Organization{}, nil
+		}
+		return []*models.Organization{org}, nil
+	}
+
+	if limit <= 0 {
+		limit = DefaultListLimit
+	}
+	limit = min(limit, MaxListLimit)
+	offset = max(offset, 0)
+
+	orgs, err := s.orgRepo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list organizations", err)
+	}
+	// Listing all organizations is recorded once, in the superadmin's own organization
+	s.crossed(scope, scope.OrgID, "organization", nil, "list")
+
+	return orgs, nil
+}
+
+// GetApplication retrieves an application of the caller's organization; a
+// superadmin may retrieve any application
+func (s *TenantService) GetApplication(ctx context.Context, scope Scope, id uuid.UUID) (*models.Application, error) {
+	app, err := s.getApplication(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+	s.checkCrossed(scope, app.OrgID, "application", &app.ID, "get")
+
+	return app, nil
+}
+
+// ListApplications lists the applications of an organization, the caller's
+// when orgID is uuid.Nil
+func (s *TenantService) ListApplications(ctx context.Context, scope Scope, orgID uuid.UUID) ([]*models.Application, error) {
+	orgID, err := s.resolveOrg(scope, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	apps, err := s.appRepo.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list applications", err)
+	}
+	s.checkCrossed(scope, orgID, "application", nil, "list")
+
+	return apps, nil
+}
+
+// GetUser retrieves a user of the caller's organization; a superadmin may
+// retrieve any user
+func (s *TenantService) GetUser(ctx context.Context, scope Scope, id uuid.UUID) (*models.User, error) {
+	user, err := s.getUser(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+	s.checkCrossed(scope, user.OrgID, "user", &user.ID, "get")
+
+	return user, nil
+}
+
+// ListUsers lists the users of an organization, the caller's when orgID is uuid.Nil
+func (s *TenantService) ListUsers(ctx context.Context, scope Scope, orgID uuid.UUID) ([]*models.User, error) {
+	orgID, err := s.resolveOrg(scope, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := s.userRepo.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to list users", err)
+	}
+	s.checkCrossed(scope, orgID, "user", nil, "list")
+
+	return users, nil
+}
+
+// UpdateUser changes a user's role or display name. The role must be one of
+// the organization's roles, and the caller must hold every permission of both
+// the user's current and new role.
+func (s *TenantService) UpdateUser(ctx context.Context, scope Scope, req UpdateUserRequest) (*models.User, error) {
+	user, err := s.getUser(ctx, scope, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{}
+	if req.Role != nil && models.UserRole(*req.Role) != user.Role {
+		if err := s.checkRole(ctx, user.OrgID, *req.Role); err != nil {
+			return nil, err
+		}
+		if err := s.checkGrant(ctx, scope, user.OrgID, string(user.Role)); err != nil {
+			return nil, err
+		}
+		if err := s.checkGrant(ctx, scope, user.OrgID, *req.Role); err != nil {
+			return nil, err
+		}
+		changes["role"] = map[string]string{"from": string(user.Role), "to": *req.Role}
+		user.Role = models.UserRole(*req.Role)
+	}
+	if req.DisplayName != nil && strings.TrimSpace(*req.DisplayName) != user.DisplayName {
+		displayName := strings.TrimSpace(*req.DisplayName)
+		if len(displayName) > MaxNameLength {
+			return nil, services.NewDomainError(services.ErrorTypeValidation, fmt.Sprintf("display name must be at most %d characters", MaxNameLength), nil)
+		}
+		changes["display_name"] = map[string]string{"from": user.DisplayName, "to": displayName}
+		user.DisplayName = displayName
+	}
+	if len(changes) == 0 {
+		return user, nil
+	}
+
+	user.UpdatedAt = s.now()
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to update user", err)
+	}
+	s.checkCrossed(scope, user.OrgID, "user", &user.ID, "update")
+
+	s.logger.Info("user updated",
+		zap.String("org_id", user.OrgID.String()),
+		zap.String("user_id", user.ID.String()))
+	if s.auditService != nil {
+		var updaterID uuid.UUID
+		if scope.UserID != nil {
+			updaterID = *scope.UserID
+		}
+		if err := s.auditService.LogUserUpdated(user, updaterID, changes); err != nil {
+			s.logger.Error("failed to log user update", zap.Error(err))
+		}
+	}
+
+	return user, nil
+}
+
+// CreateOrganization creates an organization; only superadmins create organizations
+func (s *TenantService) CreateOrganization(ctx context.Context, scope Scope, req CreateOrganizationRequest) (*models.Organization, error) {
+	if !scope.SuperAdmin {
+		return nil, services.NewDomainError(services.ErrorTypeForbidden, "only platform superadmins can create organizations", nil)
+	}
+
+	name, err := validateName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSlug(ctx, req.Slug, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	org := models.NewOrganization(name, req.Slug)
+	if err := s.orgRepo.Create(ctx, org); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create organization", err)
+	}
+
+	s.logger.Info("organization created",
+		zap.String("org_id", org.ID.String()),
+		zap.String("slug", org.Slug))
+	if s.auditService != nil {
+		if err := s.auditService.LogOrgCreated(org, scope.UserID); err != nil {
+			s.logger.Error("failed to log organization creation", zap.Error(err))
+		}
+	}
+
+	return org, nil
+}
+
+// UpdateOrganization renames an organization or changes its slug
+func (s *TenantService) UpdateOrganization(ctx context.Context, scope Scope, req UpdateOrganizationRequest) (*models.Organization, error) {
+	org, err := s.getOrganization(ctx, scope, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name, err := validateName(*req.Name)
+		if err != nil {
+			return nil, err
+		}
+		org.Name = name
+	}
+	if req.Slug != nil && *req.Slug != org.Slug {
+		if err := s.checkSlug(ctx, *req.Slug, org.ID); err != nil {
+			return nil, err
+		}
+		org.Slug = *req.Slug
+	}
+	org.UpdatedAt = s.now()
+
+	if err := s.orgRepo.Update(ctx, org); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to update organization", err)
+	}
+	s.checkCrossed(scope, org.ID, "organization", &org.ID, "update")
+
+	s.logger.Info("organization updated", zap.String("org_id", org.ID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogOrgUpdated(org, scope.UserID); err != nil {
+			s.logger.Error("failed to log organization update", zap.Error(err))
+		}
+	}
+
+	return org, nil
+}
+
+// DeleteOrganization soft-deletes an organization with its applications; they
+// can be restored within the restore window
+func (s *TenantService) DeleteOrganization(ctx context.Context, scope Scope, id uuid.UUID) error {
+	org, err := s.getOrganization(ctx, scope, id)
+	if err != nil {
+		return err
+	}
+
+	deletedAt := s.now()
+	err = s.txManager.InTransaction(ctx, func(ctx context.Context, tx repositories.Transaction) error {
+		if err := s.orgRepo.SoftDelete(ctx, org.ID, deletedAt); err != nil {
+			return err
+		}
+		return s.appRepo.SoftDeleteByOrg(ctx, org.ID, deletedAt)
+	})
+	if err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to delete organization", err)
+	}
+	s.checkCrossed(scope, org.ID, "organization", &org.ID, "delete")
+
+	s.logger.Info("organization deleted", zap.String("org_id", org.ID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogOrgDeleted(org, scope.UserID); err != nil {
+			s.logger.Error("failed to log organization deletion", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// RestoreOrganization restores a deleted organization with the applications
+// deleted along with it
+func (s *TenantService) RestoreOrganization(ctx context.Context, scope Scope, id uuid.UUID) (*models.Organization, error) {
+	if id != scope.OrgID && !scope.SuperAdmin {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "organization not found", nil)
+	}
+
+	org, err := s.orgRepo.GetDeleted(ctx, id)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "deleted organization not found", err)
+	}
+	if err := s.checkRestorable(org.DeletedAt, "organization"); err != nil {
+		return nil, err
+	}
+
+	// Applications deleted along with the organization share its deletion time
+	deletedAt := *org.DeletedAt
+	err = s.txManager.InTransaction(ctx, func(ctx context.Context, tx repositories.Transaction) error {
+		if err := s.orgRepo.Restore(ctx, org.ID); err != nil {
+			return err
+		}
+		return s.appRepo.RestoreByOrg(ctx, org.ID, deletedAt)
+	})
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to restore organization", err)
+	}
+	org.DeletedAt = nil
+	s.checkCrossed(scope, org.ID, "organization", &org.ID, "restore")
+
+	s.logger.Info("organization restored", zap.String("org_id", org.ID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogOrgRestored(org, scope.UserID); err != nil {
+			s.logger.Error("failed to log organization restoration", zap.Error(err))
+		}
+	}
+
+	return org, nil
+}
+
+// CreateApplication creates an application with an initial API key
+func (s *TenantService) CreateApplication(ctx context.Context, scope Scope, req CreateApplicationRequest) (*CreatedApplication, error) {
+	orgID, err := s.resolveOrg(scope, req.OrgID)
+	if err != nil {
+		return nil, err
+	}
+	name, err := validateName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.orgRepo.GetByID(ctx, orgID); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "organization not found", err)
+	}
+
+	// The legacy api_key_hash column holds the hash of the application's
+	// initial key, as for applications created before multiple keys
+	app := models.NewApplication(orgID, name, "")
+	app.APIKeyHash = "pending:" + app.ID.String()
+	var issued *apikey.IssuedKey
+	err = s.txManager.InTransaction(ctx, func(ctx context.Context, tx repositories.Transaction) error {
+		if err := s.appRepo.Create(ctx, app); err != nil {
+			return err
+		}
+		issued, err = s.keys.Issue(ctx, apikey.IssueRequest{
+			OrgID:     orgID,
+			AppID:     app.ID,
+			Name:      initialKeyName,
+			CreatedBy: scope.UserID,
+		})
+		if err != nil {
+			return err
+		}
+		app.APIKeyHash = issued.KeyHash
+		return s.appRepo.Update(ctx, app)
+	})
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to create application", err)
+	}
+	s.checkCrossed(scope, orgID, "application", &app.ID, "create")
+
+	s.logger.Info("application created",
+		zap.String("org_id", orgID.String()),
+		zap.String("app_id", app.ID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogAppCreated(app, scope.UserID); err != nil {
+			s.logger.Error("failed to log application creation", zap.Error(err))
+		}
+	}
+
+	return &CreatedApplication{Application: app, APIKey: issued}, nil
+}
+
+// UpdateApplication renames an application
+func (s *TenantService) UpdateApplication(ctx context.Context, scope Scope, req UpdateApplicationRequest) (*models.Application, error) {
+	app, err := s.getApplication(ctx, scope, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	name, err := validateName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{
+		"name": map[string]string{"from": app.Name, "to": name},
+	}
+	app.Name = name
+	app.UpdatedAt = s.now()
+	if err := s.appRepo.Update(ctx, app); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to update application", err)
+	}
+	s.checkCrossed(scope, app.OrgID, "application", &app.ID, "update")
+
+	s.logger.Info("application updated", zap.String("app_id", app.ID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogAppUpdated(app, scope.UserID, changes); err != nil {
+			s.logger.Error("failed to log application update", zap.Error(err))
+		}
+	}
+
+	return app, nil
+}
+
+// DeleteApplication soft-deletes an application; its API keys stop working
+// until it is restored within the restore window
+func (s *TenantService) DeleteApplication(ctx context.Context, scope Scope, id uuid.UUID) error {
+	app, err := s.getApplication(ctx, scope, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.appRepo.SoftDelete(ctx, app.ID, s.now()); err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to delete application", err)
+	}
+	s.checkCrossed(scope, app.OrgID, "application", &app.ID, "delete")
+
+	s.logger.Info("application deleted", zap.String("app_id", app.ID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogAppDeleted(app, scope.UserID); err != nil {
+			s.logger.Error("failed to log application deletion", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// RestoreApplication restores a deleted application of an organization that is not deleted
+func (s *TenantService) RestoreApplication(ctx context.Context, scope Scope, id uuid.UUID) (*models.Application, error) {
+	app, err := s.appRepo.GetDeleted(ctx, id)
+	if err != nil || (app.OrgID != scope.OrgID && !scope.SuperAdmin) {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "deleted application not found", err)
+	}
+	if err := s.checkRestorable(app.DeletedAt, "application"); err != nil {
+		return nil, err
+	}
+	if _, err := s.orgRepo.GetByID(ctx, app.OrgID); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeConflict, "the application's organization is deleted; restore the organization instead", err)
+	}
+
+	if err := s.appRepo.Restore(ctx, app.ID); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to restore application", err)
+	}
+	app.DeletedAt = nil
+	s.checkCrossed(scope, app.OrgID, "application", &app.ID, "restore")
+
+	s.logger.Info("application restored", zap.String("app_id", app.ID.String()))
+	if s.auditService != nil {
+		if err := s.auditService.LogAppRestored(app, scope.UserID); err != nil {
+			s.logger.Error("failed to log application restoration", zap.Error(err))
+		}
+	}
+
+	return app, nil
+}
+
+// getOrganization retrieves an organization the caller may access
+func (s *TenantService) getOrganization(ctx context.Context, scope Scope, id uuid.UUID) (*models.Organization, error) {
+	if id != scope.OrgID && !scope.SuperAdmin {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "organization not found", nil)
+	}
+
+	org, err := s.orgRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "organization not found", err)
+	}
+	return org, nil
+}
+
+// getApplication retrieves an application the caller may access
+func (s *TenantService) getApplication(ctx context.Context, scope Scope, id uuid.UUID) (*models.Application, error) {
+	var app *models.Application
+	var err error
+	if scope.SuperAdmin {
+		app, err = s.appRepo.GetByID(ctx, id)
+	} else {
+		app, err = s.appRepo.GetByOrgAndID(ctx, scope.OrgID, id)
+	}
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "application not found", err)
+	}
+	return app, nil
+}
+
+// getUser retrieves a user the caller may access
+func (s *TenantService) getUser(ctx context.Context, scope Scope, id uuid.UUID) (*models.User, error) {
+	var user *models.User
+	var err error
+	if scope.SuperAdmin {
+		user, err = s.userRepo.GetByID(ctx, id)
+	} else {
+		user, err = s.userRepo.GetByOrgAndID(ctx, scope.OrgID, id)
+	}
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "user not found", err)
+	}
+	return user, nil
+}
+
+// checkRole checks that a role is one of the organization's effective roles
+func (s *TenantService) checkRole(ctx context.Context, orgID uuid.UUID, name string) error {
+	roles, err := s.roles.ListRoles(ctx, orgID)
+	if err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to list roles", err)
+	}
+	for _, role := range roles {
+		if role.Name == name {
+			return nil
+		}
+	}
+	return services.NewDomainError(services.ErrorTypeValidation, fmt.Sprintf("unknown role %q", name), nil)
+}
+
+// checkGrant ensures the caller holds every permission a role grants in the
+// organization, so that assigning roles cannot be used to escalate privileges
+func (s *TenantService) checkGrant(ctx context.Context, scope Scope, orgID uuid.UUID, role string) error {
+	for _, permission := range models.Permissions {
+		granted, err := s.roles.HasPermission(ctx, orgID, []string{role}, permission)
+		if err != nil {
+			return services.NewDomainError(services.ErrorTypeInternal, "failed to check permissions", err)
+		}
+		if !granted {
+			continue
+		}
+		held, err := s.roles.HasPermission(ctx, orgID, scope.Roles, permission)
+		if err != nil {
+			return services.NewDomainError(services.ErrorTypeInternal, "failed to check permissions", err)
+		}
+		if !held || (len(scope.Scopes) > 0 && !scopesGrant(scope.Scopes, permission)) {
+			return services.NewDomainError(services.ErrorTypeForbidden, "cannot assign a role with a permission you do not hold: "+string(permission), nil)
+		}
+	}
+	return nil
+}
+
+// scopesGrant reports whether a token's scopes include permission
+func scopesGrant(scopes []string, permission models.Permission) bool {
+	permissions := make([]models.Permission, 0, len(scopes))
+	for _, scope := range scopes {
+		permissions = append(permissions, models.Permission(scope))
+	}
+	return models.Grants(permissions, permission)
+}
+
+// checkSlug validates a slug and checks that no other organization, deleted
+// or not, has it
+func (s *TenantService) checkSlug(ctx context.Context, slug string, orgID uuid.UUID) error {
+	if err := models.ValidateSlug(slug); err != nil {
+		return services.NewDomainError(services.ErrorTypeValidation, err.Error(), nil)
+	}
+	if existing, err := s.orgRepo.GetBySlug(ctx, slug); err == nil && existing.ID != orgID {
+		return services.NewDomainError(services.ErrorTypeConflict, fmt.Sprintf("slug %q is taken", slug), nil)
+	}
+	return nil
+}
+
+// checkRestorable checks that a resource was deleted within the restore window
+func (s *TenantService) checkRestorable(deletedAt *time.Time, resource string) error {
+	if deletedAt == nil || s.now().Sub(*deletedAt) > s.restoreWindow {
+		return services.NewDomainError(services.ErrorTypeNotFound, resource+" was deleted too long ago to be restored", nil)
+	}
+	return nil
+}
+
+// validateName trims a name and checks its length
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > MaxNameLength {
+		return "", services.NewDomainError(services.ErrorTypeValidation, fmt.Sprintf("name must be between 1 and %d characters", MaxNameLength), nil)
+	}
+	return name, nil
+}
+
+// resolveOrg returns the organization an operation runs in; other
+// organizations are not found unless the caller is a superadmin
+func (s *TenantService) resolveOrg(scope Scope, orgID uuid.UUID) (uuid.UUID, error) {
+	if orgID == uuid.Nil {
+		return scope.OrgID, nil
+	}
+	if orgID != scope.OrgID && !scope.SuperAdmin {
+		return uuid.Nil, services.NewDomainError(services.ErrorTypeNotFound, "organization not found", nil)
+	}
+	return orgID, nil
+}
+
+// checkCrossed records an access when the resource belongs to another organization than the caller's
+func (s *TenantService) checkCrossed(scope Scope, orgID uuid.UUID, resourceType string, resourceID *uuid.UUID, operation string) {
+	if orgID != scope.OrgID {
+		s.crossed(scope, orgID, resourceType, resourceID, operation)
+	}
+}
+
+// crossed records a superadmin's access to another organization
+func (s *TenantService) crossed(scope Scope, orgID uuid.UUID, resourceType string, resourceID *uuid.UUID, operation string) {
+	s.logger.Warn("tenant boundary crossed",
+		zap.String("actor_sub", scope.Subject),
+		zap.String("actor_org_id", scope.OrgID.String()),
+		zap.String("org_id", orgID.String()),
+		zap.String("resource_type", resourceType),
+		zap.String("operation", operation))
+
+	if s.auditService != nil {
+		if err := s.auditService.LogTenantCrossed(orgID, resourceType, resourceID, operation, scope.OrgID, scope.Subject, scope.UserID); err != nil {
+			s.logger.Error("failed to log tenant crossing", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,548 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"github.com/upb/llm-control-plane/backend/services/apikey"
+	"github.com/upb/llm-control-plane/backend/services/audit"
+	"go.uber.org/zap"
+)
+
+// fakeOrganizationRepository stores organizations in memory
+type fakeOrganizationRepository struct {
+	repositories.OrganizationRepository
+	orgs []*models.Organization
+}
+
+func (r *fakeOrganizationRepository) find(id uuid.UUID, deleted bool) (*models.Organization, error) {
+	for _, org := range r.orgs {
+		if org.ID == id && (org.DeletedAt != nil) == deleted {
+			return org, nil
+		}
+	}
+	return nil, errors.New("organization not found")
+}
+
+func (r *fakeOrganizationRepository) Create(ctx context.Context, org *models.Organization) error {
+	r.orgs = append(r.orgs, org)
+	return nil
+}
+
+func (r *fakeOrganizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	return r.find(id, false)
+}
+
+func (r *fakeOrganizationRepository) GetBySlug(ctx context.Context, slug string) (*models.Organization, error) {
+	for _, org := range r.orgs {
+		if org.Slug == slug {
+			return org, nil
+		}
+	}
+	return nil, errors.New("organization not found")
+}
+
+func (r *fakeOrganizationRepository) List(ctx context.Context, limit, offset int) ([]*models.Organization, error) {
+	return r.orgs, nil
+}
+
+func (r *fakeOrganizationRepository) Update(ctx context.Context, org *models.Organization) error {
+	_, err := r.find(org.ID, false)
+	return err
+}
+
+func (r *fakeOrganizationRepository) GetDeleted(ctx context.Context, id uuid.UUID) (*models.Organization, error) {
+	return r.find(id, true)
+}
+
+func (r *fakeOrganizationRepository) SoftDelete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	org, err := r.find(id, false)
+	if err != nil {
+		return err
+	}
+	org.DeletedAt = &deletedAt
+	return nil
+}
+
+func (r *fakeOrganizationRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	org, err := r.find(id, true)
+	if err != nil {
+		return err
+	}
+	org.DeletedAt = nil
+	return nil
+}
+
+// fakeApplicationRepository stores applications in memory
+type fakeApplicationRepository struct {
+	repositories.ApplicationRepository
+	apps []*models.Application
+}
+
+func (r *fakeApplicationRepository) find(id uuid.UUID, deleted bool) (*models.Application, error) {
+	for _, app := range r.apps {
+		if app.ID == id && (app.DeletedAt != nil) == deleted {
+			return app, nil
+		}
+	}
+	return nil, errors.New("application not found")
+}
+
+func (r *fakeApplicationRepository) Create(ctx context.Context, app *models.Application) error {
+	r.apps = append(r.apps, app)
+	return nil
+}
+
+func (r *fakeApplicationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Application, error) {
+	return r.find(id, false)
+}
+
+func (r *fakeApplicationRepository) Update(ctx context.Context, app *models.Application) error {
+	_, err := r.find(app.ID, false)
+	return err
+}
+
+func (r *fakeApplicationRepository) GetDeleted(ctx context.Context, id uuid.UUID) (*models.Application, error) {
+	return r.find(id, true)
+}
+
+func (r *fakeApplicationRepository) SoftDelete(ctx context.Context, id uuid.UUID, deletedAt time.Time) error {
+	app, err := r.find(id, false)
+	if err != nil {
+		return err
+	}
+	app.DeletedAt = &deletedAt
+	return nil
+}
+
+func (r *fakeApplicationRepository) SoftDeleteByOrg(ctx context.Context, orgID uuid.UUID, deletedAt time.Time) error {
+	for _, app := range r.apps {
+		if app.OrgID == orgID && app.DeletedAt == nil {
+			app.DeletedAt = &deletedAt
+		}
+	}
+	return nil
+}
+
+func (r *fakeApplicationRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	app, err := r.find(id, true)
+	if err != nil {
+		return err
+	}
+	app.DeletedAt = nil
+	return nil
+}
+
+func (r *fakeApplicationRepository) RestoreByOrg(ctx context.Context, orgID uuid.UUID, deletedAt time.Time) error {
+	for _, app := range r.apps {
+		if app.OrgID == orgID && app.DeletedAt != nil && app.DeletedAt.Equal(deletedAt) {
+			app.DeletedAt = nil
+		}
+	}
+	return nil
+}
+
+func (r *fakeApplicationRepository) GetByOrgAndID(ctx context.Context, orgID, id uuid.UUID) (*models.Application, error) {
+	app, err := r.GetByID(ctx, id)
+	if err != nil || app.OrgID != orgID {
+		return nil, errors.New("application not found")
+	}
+	return app, nil
+}
+
+func (r *fakeApplicationRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID) ([]*models.Application, error) {
+	var apps []*models.Application
+	for _, app := range r.apps {
+		if app.OrgID == orgID && app.DeletedAt == nil {
+			apps = append(apps, app)
+		}
+	}
+	return apps, nil
+}
+
+// fakeUserRepository stores users in memory
+type fakeUserRepository struct {
+	repositories.UserRepository
+	users []*models.User
+}
+
+func (r *fakeUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, errors.New("user not found")
+}
+
+func (r *fakeUserRepository) GetByOrgAndID(ctx context.Context, orgID, id uuid.UUID) (*models.User, error) {
+	user, err := r.GetByID(ctx, id)
+	if err != nil || user.OrgID != orgID {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+func (r *fakeUserRepository) GetByOrgID(ctx context.Context, orgID uuid.UUID) ([]*models.User, error) {
+	var users []*models.User
+	for _, user := range r.users {
+		if user.OrgID == orgID {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepository) Update(ctx context.Context, user *models.User) error {
+	return nil
+}
+
+// fakeRoleResolver resolves the built-in roles and the custom roles given
+type fakeRoleResolver struct {
+	custom []*models.Role
+}
+
+func (f *fakeRoleResolver) ListRoles(ctx context.Context, orgID uuid.UUID) ([]*models.Role, error) {
+	var roles []*models.Role
+	for _, builtIn := range models.BuiltInRoles {
+		role, err := models.NewRole(nil, string(builtIn.Name), builtIn.Description, builtIn.Permissions)
+		if err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return append(roles, f.custom...), nil
+}
+
+func (f *fakeRoleResolver) HasPermission(ctx context.Context, orgID uuid.UUID, roles []string, permission models.Permission) (bool, error) {
+	if models.HasSuperAdminRole(roles) || models.BuiltInRolesGrant(roles, permission) {
+		return true, nil
+	}
+	for _, role := range f.custom {
+		permissions, err := role.GetPermissions()
+		if err != nil {
+			return false, err
+		}
+		for _, name := range roles {
+			if role.Name == name && models.Grants(permissions, permission) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// fakeAuditRepository records inserted audit logs
+type fakeAuditRepository struct {
+	repositories.AuditRepository
+	mu   sync.Mutex
+	logs []*models.AuditLog
+}
+
+func (r *fakeAuditRepository) Insert(ctx context.Context, log *models.AuditLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *fakeAuditRepository) inserted() []*models.AuditLog {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*models.AuditLog(nil), r.logs...)
+}
+
+// fakeKeyIssuer issues keys without storing them
+type fakeKeyIssuer struct {
+	issued []apikey.IssueRequest
+}
+
+func (f *fakeKeyIssuer) Issue(ctx context.Context, req apikey.IssueRequest) (*apikey.IssuedKey, error) {
+	f.issued = append(f.issued, req)
+	key := models.NewAPIKey(req.OrgID, req.AppID, req.Name, "sk-test", "hash-"+req.AppID.String(), req.CreatedBy)
+	return &apikey.IssuedKey{APIKey: key, Key: "sk-test-secret"}, nil
+}
+
+// fakeTxManager runs transactional functions directly
+type fakeTxManager struct{}
+
+func (fakeTxManager) Begin(ctx context.Context) (repositories.Transaction, error) {
+	return nil, nil
+}
+
+func (fakeTxManager) InTransaction(ctx context.Context, fn func(ctx context.Context, tx repositories.Transaction) error) error {
+	return fn(ctx, nil)
+}
+
+// tenants holds two organizations with an application and a user each
+type tenants struct {
+	service    *TenantService
+	audits     *fakeAuditRepository
+	keys       *fakeKeyIssuer
+	apps       *fakeApplicationRepository
+	orgA, orgB *models.Organization
+	appA, appB *models.Application
+	userA      *models.User
+	userB      *models.User
+}
+
+func newTenants(t *testing.T) *tenants {
+	orgA := models.NewOrganization("Acme", "acme")
+	orgB := models.NewOrganization("Globex", "globex")
+	appA := models.NewApplication(orgA.ID, "acme-app", "")
+	appB := models.NewApplication(orgB.ID, "globex-app", "")
+	userA := models.NewUser("a@acme.test", "sub-a", orgA.ID, models.RoleMember)
+	userB := models.NewUser("b@globex.test", "sub-b", orgB.ID, models.RoleMember)
+	developer, err := models.NewRole(&orgA.ID, "developer", "", []models.Permission{models.PermissionTemplatesRead})
+	require.NoError(t, err)
+
+	audits := &fakeAuditRepository{}
+	auditService := audit.NewAuditService(audits, zap.NewNop(), audit.DefaultConfig())
+	require.NoError(t, auditService.Start())
+	t.Cleanup(func() { _ = auditService.Stop(5 * time.Second) })
+
+	keys := &fakeKeyIssuer{}
+	apps := &fakeApplicationRepository{apps: []*models.Application{appA, appB}}
+	service := NewTenantService(
+		&fakeOrganizationRepository{orgs: []*models.Organization{orgA, orgB}},
+		apps,
+		&fakeUserRepository{users: []*models.User{userA, userB}},
+		keys,
+		fakeTxManager{},
+		&fakeRoleResolver{custom: []*models.Role{developer}},
+		zap.NewNop(),
+	)
+	service.SetAuditService(auditService)
+
+	return &tenants{service, audits, keys, apps, orgA, orgB, appA, appB, userA, userB}
+}
+
+func TestTenantService_Isolation(t *testing.T) {
+	ctx := context.Background()
+	tt := newTenants(t)
+	scope := Scope{OrgID: tt.orgA.ID, UserID: &tt.userA.ID, Subject: "sub-a"}
+
+	app, err := tt.service.GetApplication(ctx, scope, tt.appA.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tt.appA.ID, app.ID)
+
+	_, err = tt.service.GetApplication(ctx, scope, tt.appB.ID)
+	assert.True(t, services.IsNotFoundError(err))
+
+	_, err = tt.service.GetUser(ctx, scope, tt.userB.ID)
+	assert.True(t, services.IsNotFoundError(err))
+
+	_, err = tt.service.GetOrganization(ctx, scope, tt.orgB.ID)
+	assert.True(t, services.IsNotFoundError(err))
+
+	_, err = tt.service.ListApplications(ctx, scope, tt.orgB.ID)
+	assert.True(t, services.IsNotFoundError(err))
+
+	_, err = tt.service.ListUsers(ctx, scope, tt.orgB.ID)
+	assert.True(t, services.IsNotFoundError(err))
+
+	orgs, err := tt.service.ListOrganizations(ctx, scope, 20, 0)
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	assert.Equal(t, tt.orgA.ID, orgs[0].ID)
+
+	apps, err := tt.service.ListApplications(ctx, scope, uuid.Nil)
+	require.NoError(t, err)
+	require.Len(t, apps, 1)
+	assert.Equal(t, tt.appA.ID, apps[0].ID)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(t, tt.audits.inserted(), "accesses within the tenant are not crossings")
+}
+
+func TestTenantService_SuperAdmin(t *testing.T) {
+	ctx := context.Background()
+	tt := newTenants(t)
+	adminID := uuid.New()
+	scope := Scope{OrgID: tt.orgA.ID, UserID: &adminID, Subject: "sub-admin", SuperAdmin: true}
+
+	app, err := tt.service.GetApplication(ctx, scope, tt.appB.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tt.appB.ID, app.ID)
+
+	users, err := tt.service.ListUsers(ctx, scope, tt.orgB.ID)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+
+	// Within the superadmin's own organization nothing is crossed
+	_, err = tt.service.GetApplication(ctx, scope, tt.appA.ID)
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	logs := tt.audits.inserted()
+	require.Len(t, logs, 2)
+	for _, log := range logs {
+		assert.Equal(t, models.AuditActionTenantCrossed, log.Action)
+		assert.Equal(t, tt.orgB.ID, log.OrgID, "crossings are recorded in the organization accessed")
+		assert.Equal(t, adminID, *log.UserID)
+		assert.Contains(t, string(log.Details), tt.orgA.ID.String())
+	}
+}
+
+func TestTenantService_CreateOrganization(t *testing.T) {
+	ctx := context.Background()
+	tt := newTenants(t)
+	adminID := uuid.New()
+	admin := Scope{OrgID: tt.orgA.ID, UserID: &adminID, SuperAdmin: true}
+
+	_, err := tt.service.CreateOrganization(ctx, Scope{OrgID: tt.orgA.ID}, CreateOrganizationRequest{Name: "Initech", Slug: "initech"})
+	assert.True(t, services.IsForbiddenError(err))
+
+	_, err = tt.service.CreateOrganization(ctx, admin, CreateOrganizationRequest{Name: "Initech", Slug: "Initech!"})
+	assert.True(t, services.IsValidationError(err))
+
+	_, err = tt.service.CreateOrganization(ctx, admin, CreateOrganizationRequest{Name: "Acme 2", Slug: "acme"})
+	assert.True(t, services.IsConflictError(err))
+
+	org, err := tt.service.CreateOrganization(ctx, admin, CreateOrganizationRequest{Name: " Initech ", Slug: "initech"})
+	require.NoError(t, err)
+	assert.Equal(t, "Initech", org.Name)
+
+	// Other organizations' slugs are taken, the organization's own is not
+	slug := "globex"
+	_, err = tt.service.UpdateOrganization(ctx, Scope{OrgID: tt.orgA.ID}, UpdateOrganizationRequest{ID: tt.orgA.ID, Slug: &slug})
+	assert.True(t, services.IsConflictError(err))
+	slug = "acme"
+	_, err = tt.service.UpdateOrganization(ctx, Scope{OrgID: tt.orgA.ID}, UpdateOrganizationRequest{ID: tt.orgA.ID, Slug: &slug})
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	logs := tt.audits.inserted()
+	require.NotEmpty(t, logs)
+	assert.Equal(t, models.AuditActionOrgCreated, logs[0].Action)
+}
+
+func TestTenantService_CreateApplication(t *testing.T) {
+	ctx := context.Background()
+	tt := newTenants(t)
+	scope := Scope{OrgID: tt.orgA.ID, UserID: &tt.userA.ID}
+
+	_, err := tt.service.CreateApplication(ctx, scope, CreateApplicationRequest{Name: "  "})
+	assert.True(t, services.IsValidationError(err))
+
+	_, err = tt.service.CreateApplication(ctx, scope, CreateApplicationRequest{OrgID: tt.orgB.ID, Name: "chat"})
+	assert.True(t, services.IsNotFoundError(err))
+
+	created, err := tt.service.CreateApplication(ctx, scope, CreateApplicationRequest{Name: "chat"})
+	require.NoError(t, err)
+	assert.Equal(t, tt.orgA.ID, created.OrgID)
+	require.NotNil(t, created.APIKey)
+	assert.Equal(t, "sk-test-secret", created.APIKey.Key)
+	assert.Equal(t, created.APIKey.KeyHash, created.APIKeyHash)
+	require.Len(t, tt.keys.issued, 1)
+	assert.Equal(t, created.ID, tt.keys.issued[0].AppID)
+
+	time.Sleep(100 * time.Millisecond)
+	logs := tt.audits.inserted()
+	require.Len(t, logs, 1)
+	assert.Equal(t, models.AuditActionAppCreated, logs[0].Action)
+	assert.Equal(t, tt.userA.ID, *logs[0].UserID)
+}
+
+func TestTenantService_DeleteAndRestore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	t.Run("organization deletion cascades to its applications", func(t *testing.T) {
+		tt := newTenants(t)
+		tt.service.now = func() time.Time { return now }
+		scope := Scope{OrgID: tt.orgA.ID}
+
+		// An application deleted before the organization stays deleted
+		other := models.NewApplication(tt.orgA.ID, "old", "")
+		tt.apps.apps = append(tt.apps.apps, other)
+		require.NoError(t, tt.service.DeleteApplication(ctx, Scope{OrgID: tt.orgA.ID}, other.ID))
+
+		tt.service.now = func() time.Time { return now.Add(time.Hour) }
+		require.NoError(t, tt.service.DeleteOrganization(ctx, scope, tt.orgA.ID))
+
+		_, err := tt.service.GetApplication(ctx, scope, tt.appA.ID)
+		assert.True(t, services.IsNotFoundError(err))
+
+		_, err = tt.service.RestoreApplication(ctx, scope, tt.appA.ID)
+		assert.True(t, services.IsConflictError(err))
+
+		_, err = tt.service.RestoreOrganization(ctx, scope, tt.orgA.ID)
+		require.NoError(t, err)
+
+		_, err = tt.service.GetApplication(ctx, scope, tt.appA.ID)
+		require.NoError(t, err)
+		_, err = tt.service.GetApplication(ctx, scope, other.ID)
+		assert.True(t, services.IsNotFoundError(err))
+	})
+
+	t.Run("restore window", func(t *testing.T) {
+		tt := newTenants(t)
+		tt.service.now = func() time.Time { return now }
+		scope := Scope{OrgID: tt.orgA.ID}
+
+		require.NoError(t, tt.service.DeleteApplication(ctx, scope, tt.appA.ID))
+
+		tt.service.now = func() time.Time { return now.Add(DefaultRestoreWindow + time.Minute) }
+		_, err := tt.service.RestoreApplication(ctx, scope, tt.appA.ID)
+		assert.True(t, services.IsNotFoundError(err))
+
+		tt.service.now = func() time.Time { return now.Add(DefaultRestoreWindow - time.Minute) }
+		app, err := tt.service.RestoreApplication(ctx, scope, tt.appA.ID)
+		require.NoError(t, err)
+		assert.Nil(t, app.DeletedAt)
+	})
+
+	t.Run("other tenants cannot delete or restore", func(t *testing.T) {
+		tt := newTenants(t)
+		scope := Scope{OrgID: tt.orgA.ID}
+
+		assert.True(t, services.IsNotFoundError(tt.service.DeleteApplication(ctx, scope, tt.appB.ID)))
+		assert.True(t, services.IsNotFoundError(tt.service.DeleteOrganization(ctx, scope, tt.orgB.ID)))
+
+		require.NoError(t, tt.service.DeleteApplication(ctx, Scope{OrgID: tt.orgB.ID}, tt.appB.ID))
+		_, err := tt.service.RestoreApplication(ctx, scope, tt.appB.ID)
+		assert.True(t, services.IsNotFoundError(err))
+	})
+}
+
+func TestTenantService_UpdateUser(t *testing.T) {
+	ctx := context.Background()
+	tt := newTenants(t)
+	adminID := uuid.New()
+	admin := Scope{OrgID: tt.orgA.ID, UserID: &adminID, Roles: []string{"admin"}}
+	member := Scope{OrgID: tt.orgA.ID, UserID: &tt.userA.ID, Roles: []string{"member"}}
+	role := func(name string) *string { return &name }
+
+	_, err := tt.service.UpdateUser(ctx, admin, UpdateUserRequest{ID: tt.userA.ID, Role: role("owner")})
+	assert.True(t, services.IsValidationError(err), "unknown roles are refused")
+
+	_, err = tt.service.UpdateUser(ctx, member, UpdateUserRequest{ID: tt.userA.ID, Role: role("admin")})
+	assert.True(t, services.IsForbiddenError(err), "members cannot grant admin")
+
+	_, err = tt.service.UpdateUser(ctx, admin, UpdateUserRequest{ID: tt.userB.ID, Role: role("viewer")})
+	assert.True(t, services.IsNotFoundError(err), "other tenants' users are not found")
+
+	updated, err := tt.service.UpdateUser(ctx, admin, UpdateUserRequest{ID: tt.userA.ID, Role: role("developer")})
+	require.NoError(t, err)
+	assert.Equal(t, models.UserRole("developer"), updated.Role)
+
+	tt.userA.Role = models.RoleAdmin
+	_, err = tt.service.UpdateUser(ctx, member, UpdateUserRequest{ID: tt.userA.ID, Role: role("viewer")})
+	assert.True(t, services.IsForbiddenError(err), "members cannot demote admins")
+
+	time.Sleep(100 * time.Millisecond)
+	logs := tt.audits.inserted()
+	require.Len(t, logs, 1)
+	assert.Equal(t, models.AuditActionUserUpdated, logs[0].Action)
+	assert.Equal(t, adminID, *logs[0].UserID)
+}
@@ -0,0 +1,321 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/models"
+	"github.com/upb/llm-control-plane/backend/repositories"
+	"github.com/upb/llm-control-plane/backend/services"
+	"go.uber.org/zap"
+)
+
+// Headers sent with every delivery. The signature is the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the application's webhook secret, prefixed
+// with "v1="; receivers should reject stale timestamps to prevent replays.
+const (
+	HeaderEventID   = "X-Webhook-ID"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Event types
+const (
+	EventInferenceCompleted = "inference.completed"
+	EventInferenceFailed    = "inference.failed"
+)
+
+// Event is the JSON body of a webhook delivery
+type Event struct {
+	ID        uuid.UUID   `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// NewEvent creates an event of the given type
+func NewEvent(eventType string, data interface{}) *Event {
+	return &Event{
+		ID:        uuid.New(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+}
+
+// Registration is returned when a webhook is configured; the secret is only
+// shown at this point
+type Registration struct {
+	AppID  uuid.UUID `json:"app_id"`
+	URL    string    `json:"url"`
+	Secret string    `json:"secret"`
+}
+
+// Config holds configuration for webhook delivery
+type Config struct {
+	Timeout     time.Duration // Deadline for one delivery attempt
+	MaxAttempts int           // Attempts before a delivery is given up
+	RetryDelay  time.Duration // Base delay between attempts, doubled after each
+}
+
+// DefaultConfig returns the default configuration
+func DefaultConfig() Config {
+	return Config{
+		Timeout:     10 * time.Second,
+		MaxAttempts: 4,
+		RetryDelay:  2 * time.Second,
+	}
+}
+
+// WebhookService manages application webhooks and delivers signed events to them
+type WebhookService struct {
+	appRepo repositories.ApplicationRepository
+	client  *http.Client
+	logger  *zap.Logger
+	config  Config
+}
+
+// NewWebhookService creates a new WebhookService instance
+func NewWebhookService(appRepo repositories.ApplicationRepository, logger *zap.Logger, config Config) *WebhookService {
+	return &WebhookService{
+		appRepo: appRepo,
+		client:  newClient(config.Timeout),
+		logger:  logger,
+		config:  config,
+	}
+}
+
+// Configure sets the application's webhook URL and issues a new signing secret
+func (s *WebhookService) Configure(ctx context.Context, orgID, appID uuid.UUID, rawURL string) (*Registration, error) {
+	if err := validateURL(rawURL); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeValidation, err.Error(), nil)
+	}
+
+	app, err := s.getApplication(ctx, orgID, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to generate webhook secret", err)
+	}
+
+	app.WebhookURL = &rawURL
+	app.WebhookSecret = &secret
+	app.UpdatedAt = time.Now()
+	if err := s.appRepo.Update(ctx, app); err != nil {
+		return nil, services.NewDomainError(services.ErrorTypeInternal, "failed to configure webhook", err)
+	}
+
+	s.logger.Info("webhook configured", zap.String("app_id", appID.String()))
+	return &Registration{AppID: appID, URL: rawURL, Secret: secret}, nil
+}
+
+// Remove stops deliveries to the application
+func (s *WebhookService) Remove(ctx context.Context, orgID, appID uuid.UUID) error {
+	app, err := s.getApplication(ctx, orgID, appID)
+	if err != nil {
+		return err
+	}
+	if !app.HasWebhook() {
+		return services.NewDomainError(services.ErrorTypeNotFound, "webhook not configured", nil)
+	}
+
+	app.WebhookURL = nil
+	app.WebhookSecret = nil
+	app.UpdatedAt = time.Now()
+	if err := s.appRepo.Update(ctx, app); err != nil {
+		return services.NewDomainError(services.ErrorTypeInternal, "failed to remove webhook", err)
+	}
+
+	s.logger.Info("webhook removed", zap.String("app_id", appID.String()))
+	return nil
+}
+
+// Deliver posts the event to the application's webhook, retrying failed
+// attempts with exponential backoff. Applications without a webhook are skipped.
+func (s *WebhookService) Deliver(ctx context.Context, appID uuid.UUID, event *Event) error {
+	app, err := s.appRepo.GetByID(ctx, appID)
+	if err != nil {
+		return fmt.Errorf("failed to load application: %w", err)
+	}
+	if !app.HasWebhook() {
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	delay := s.config.RetryDelay
+	for attempt := 1; ; attempt++ {
+		err = s.send(ctx, *app.WebhookURL, *app.WebhookSecret, event.ID, body)
+		if err == nil {
+			s.logger.Debug("webhook delivered",
+				zap.String("app_id", appID.String()),
+				zap.String("event_id", event.ID.String()),
+				zap.Int("attempt", attempt))
+			return nil
+		}
+		if attempt >= s.config.MaxAttempts {
+			return fmt.Errorf("webhook delivery failed after %d attempts: %w", attempt, err)
+		}
+
+		s.logger.Warn("webhook delivery failed, retrying",
+			zap.String("app_id", appID.String()),
+			zap.String("event_id", event.ID.String()),
+			zap.Int("attempt", attempt),
+			zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// send makes one signed delivery attempt; any non-2xx status is a failure
+func (s *WebhookService) send(ctx context.Context, target, secret string, eventID uuid.UUID, body []byte) error {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, eventID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// newClient returns the client deliveries are made with. Webhook URLs are
+// chosen by tenants, so it only connects to public addresses, checked after
+// the host is resolved so DNS cannot point it inside the network, and it does
+// not follow redirects, which could lead anywhere.
+func newClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !isPublic(ip) {
+				return fmt.Errorf("webhook address %s is not public", host)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598)
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// isPublic reports whether ip is routable on the internet: not loopback,
+// private, link-local (which includes cloud metadata endpoints), multicast or
+// unspecified
+func isPublic(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// getApplication retrieves an application of the organization
+func (s *WebhookService) getApplication(ctx context.Context, orgID, appID uuid.UUID) (*models.Application, error) {
+	app, err := s.appRepo.GetByID(ctx, appID)
+	if err != nil || app.OrgID != orgID {
+		return nil, services.NewDomainError(services.ErrorTypeNotFound, "application not found", err)
+	}
+	return app, nil
+}
+
+// Sign returns the signature header value for a delivery
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is valid for the delivery
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// validateURL accepts absolute HTTPS URLs. Hosts that are not public are
+// refused early when they are IP literals or localhost; names resolving to
+// them are refused when delivering.
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("webhook url must be an absolute URL")
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("webhook url must use https")
+	}
+	if u.User != nil {
+		return fmt.Errorf("webhook url must not contain credentials")
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); (ip != nil && !isPublic(ip)) || host == "localhost" {
+		return fmt.Errorf("webhook url must point to a public address")
+	}
+	return nil
+}
+
+// generateSecret returns a random signing secret
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,266 @@
+// Package tokens signs and validates the JWTs the gateway issues itself, to
+// service accounts and to admins impersonating users, and publishes the keys
+// to verify them as a JWKS.
+package tokens
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/upb/llm-control-plane/backend/config"
+)
+
+var (
+	// ErrInvalidToken is returned when the token is invalid
+	ErrInvalidToken = errors.New("invalid token")
+
+	// ErrTokenExpired is returned when the token has expired
+	ErrTokenExpired = errors.New("token expired")
+)
+
+const (
+	// DefaultTTL is the lifetime of issued tokens when none is configured
+	DefaultTTL = 15 * time.Minute
+
+	// signingMethod is the only algorithm tokens are signed and accepted with
+	signingMethod = "RS256"
+
+	// ephemeralKeyBits is the size of keys generated when none is configured
+	ephemeralKeyBits = 2048
+)
+
+// Claims are the claims of a gateway-issued token
+type Claims struct {
+	jwt.RegisteredClaims
+	OrgID    string   `json:"org_id"`
+	ClientID string   `json:"client_id,omitempty"`
+	Roles    []string `json:"roles"`
+	Scope    string   `json:"scope,omitempty"` // Space-separated permissions the token is limited to
+	Actor    *Actor   `json:"act,omitempty"`   // Set when the token acts as its subject on someone else's behalf
+}
+
+// Actor is the party acting as a token's subject (RFC 8693 "act" claim)
+type Actor struct {
+	Subject string `json:"sub"`
+	UserID  string `json:"user_id,omitempty"`
+}
+
+// Scopes returns the permissions the token is limited to; none means it is
+// limited by its roles only
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// JWKS represents the JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK represents a JSON Web Key
+type JWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// Issuer signs tokens with the gateway's key and validates them
+type Issuer struct {
+	issuer    string
+	keyID     string
+	key       *rsa.PrivateKey
+	ttl       time.Duration
+	ephemeral bool
+	now       func() time.Time
+}
+
+// NewIssuer creates an issuer from its configuration. Without a signing key
+// file a key is generated, so tokens do not survive restarts and are only
+// accepted by the instance that issued them.
+func NewIssuer(cfg config.TokensConfig) (*Issuer, error) {
+	if cfg.Issuer == "" {
+		return nil, fmt.Errorf("gateway token issuer is required")
+	}
+
+	i := &Issuer{
+		issuer: cfg.Issuer,
+		keyID:  cfg.KeyID,
+		ttl:    cfg.TTL,
+		now:    time.Now,
+	}
+	if i.ttl <= 0 {
+		i.ttl = DefaultTTL
+	}
+
+	if cfg.SigningKeyFile == "" {
+		key, err := rsa.GenerateKey(rand.Reader, ephemeralKeyBits)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate signing key: %w", err)
+		}
+		i.key = key
+		i.ephemeral = true
+	} else {
+		data, err := os.ReadFile(cfg.SigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read signing key: %w", err)
+		}
+		key, err := parsePrivateKey(data)
+		if err != nil {
+			return nil, err
+		}
+		i.key = key
+	}
+
+	if i.keyID == "" {
+		i.keyID = thumbprint(&i.key.PublicKey)
+	}
+	return i, nil
+}
+
+// Issuer returns the iss claim of issued tokens
+func (i *Issuer) Issuer() string {
+	return i.issuer
+}
+
+// TTL returns the lifetime of issued tokens
+func (i *Issuer) TTL() time.Duration {
+	return i.ttl
+}
+
+// Ephemeral reports whether the signing key was generated at startup
+func (i *Issuer) Ephemeral() bool {
+	return i.ephemeral
+}
+
+// Issue signs a token with the given claims, setting its issuer, audience,
+// ID and validity, and returns it with its expiry
+func (i *Issuer) Issue(claims *Claims) (string, time.Time, error) {
+	return i.IssueFor(claims, i.ttl)
+}
+
+// IssueFor signs a token like Issue, valid for ttl instead of the
+// configured lifetime
+func (i *Issuer) IssueFor(claims *Claims, ttl time.Duration) (string, time.Time, error) {
+	now := i.now()
+	expiresAt := now.Add(ttl)
+
+	claims.Issuer = i.issuer
+	claims.Audience = jwt.ClaimStrings{i.issuer}
+	claims.ID = uuid.New().String()
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.NotBefore = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(signingMethod), claims)
+	token.Header["kid"] = i.keyID
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// Handles reports whether a token claims to be issued by the gateway. The
+// issuer is read without verifying the token; ValidateToken verifies it.
+func (i *Issuer) Handles(tokenString string) bool {
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return false
+	}
+	issuer, err := claims.GetIssuer()
+	return err == nil && issuer == i.issuer
+}
+
+// ValidateToken validates a gateway-issued token and returns its claims
+func (i *Issuer) ValidateToken(ctx context.Context, tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if kid, _ := token.Header["kid"].(string); kid != i.keyID {
+			return nil, errors.New("unknown kid")
+		}
+		return &i.key.PublicKey, nil
+	},
+		jwt.WithValidMethods([]string{signingMethod}),
+		jwt.WithIssuer(i.issuer),
+		jwt.WithAudience(i.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(i.now),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrTokenExpired
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// JWKS returns the key set to verify issued tokens with
+func (i *Issuer) JWKS() JWKS {
+	return JWKS{Keys: []JWK{publicJWK(&i.key.PublicKey, i.keyID)}}
+}
+
+// parsePrivateKey parses a PEM PKCS#1 or PKCS#8 RSA private key
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key is not an RSA key")
+	}
+	return key, nil
+}
+
+// publicJWK converts an RSA public key to a JWK
+func publicJWK(key *rsa.PublicKey, kid string) JWK {
+	return JWK{
+		Kid: kid,
+		Kty: "RSA",
+		Alg: signingMethod,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+// thumbprint returns the RFC 7638 thumbprint of an RSA public key, used as
+// its key ID when none is configured
+func thumbprint(key *rsa.PublicKey) string {
+	jwk := publicJWK(key, "")
+	// Members in lexicographic order, as the thumbprint requires
+	data, _ := json.Marshal(struct {
+		E   string `json:"e"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+	}{jwk.E, jwk.Kty, jwk.N})
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
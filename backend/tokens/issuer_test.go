@@ -0,0 +1,180 @@
+package tokens
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/upb/llm-control-plane/backend/config"
+)
+
+func newTestIssuer(t *testing.T) *Issuer {
+	i, err := NewIssuer(config.TokensConfig{Issuer: "https://gateway.test"})
+	require.NoError(t, err)
+	return i
+}
+
+func TestIssuer_IssueAndValidate(t *testing.T) {
+	i := newTestIssuer(t)
+	assert.True(t, i.Ephemeral())
+	assert.Equal(t, DefaultTTL, i.TTL())
+
+	token, expiresAt, err := i.Issue(&Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "account-1"},
+		OrgID:            "org-1",
+		ClientID:         "sa_0123",
+		Roles:            []string{"member"},
+		Scope:            "policies:read templates:read",
+	})
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(DefaultTTL), expiresAt, time.Second)
+	assert.True(t, i.Handles(token))
+
+	claims, err := i.ValidateToken(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "account-1", claims.Subject)
+	assert.Equal(t, "org-1", claims.OrgID)
+	assert.Equal(t, []string{"member"}, claims.Roles)
+	assert.Equal(t, []string{"policies:read", "templates:read"}, claims.Scopes())
+	assert.NotEmpty(t, claims.ID)
+	assert.Nil(t, claims.Actor)
+}
+
+func TestIssuer_IssueFor(t *testing.T) {
+	i := newTestIssuer(t)
+
+	token, expiresAt, err := i.IssueFor(&Claims{
+		RegisteredClaims: jwt.RegisteredClaims{Subject: "user-1"},
+		OrgID:            "org-1",
+		Roles:            []string{"viewer"},
+		Actor:            &Actor{Subject: "admin-sub", UserID: "admin-1"},
+	}, 5*time.Minute)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), expiresAt, time.Second)
+
+	claims, err := i.ValidateToken(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.Subject)
+	require.NotNil(t, claims.Actor)
+	assert.Equal(t, "admin-sub", claims.Actor.Subject)
+	assert.Equal(t, "admin-1", claims.Actor.UserID)
+}
+
+func TestIssuer_ValidateToken_Rejects(t *testing.T) {
+	i := newTestIssuer(t)
+
+	t.Run("expired", func(t *testing.T) {
+		i.now = func() time.Time { return time.Now().Add(-time.Hour) }
+		token, _, err := i.Issue(&Claims{OrgID: "org-1"})
+		i.now = time.Now
+		require.NoError(t, err)
+
+		_, err = i.ValidateToken(context.Background(), token)
+		assert.ErrorIs(t, err, ErrTokenExpired)
+	})
+
+	t.Run("signed by another key", func(t *testing.T) {
+		other := newTestIssuer(t)
+		other.keyID = i.keyID
+		token, _, err := other.Issue(&Claims{OrgID: "org-1"})
+		require.NoError(t, err)
+
+		assert.True(t, i.Handles(token))
+		_, err = i.ValidateToken(context.Background(), token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("another issuer", func(t *testing.T) {
+		other, err := NewIssuer(config.TokensConfig{Issuer: "https://other.test"})
+		require.NoError(t, err)
+		token, _, err := other.Issue(&Claims{OrgID: "org-1"})
+		require.NoError(t, err)
+
+		assert.False(t, i.Handles(token))
+		_, err = i.ValidateToken(context.Background(), token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodNone, &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Issuer:    i.Issuer(),
+				Audience:  jwt.ClaimStrings{i.Issuer()},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+		}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+
+		_, err = i.ValidateToken(context.Background(), token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+func TestIssuer_JWKS(t *testing.T) {
+	i := newTestIssuer(t)
+	token, _, err := i.Issue(&Claims{OrgID: "org-1"})
+	require.NoError(t, err)
+
+	jwks := i.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	jwk := jwks.Keys[0]
+	assert.Equal(t, "RS256", jwk.Alg)
+	assert.Equal(t, "sig", jwk.Use)
+
+	// A relying party verifies the token with the published key alone
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	require.NoError(t, err)
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	require.NoError(t, err)
+	publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+
+	parsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		assert.Equal(t, jwk.Kid, token.Header["kid"])
+		return publicKey, nil
+	}, jwt.WithValidMethods([]string{"RS256"}))
+	require.NoError(t, err)
+	assert.True(t, parsed.Valid)
+}
+
+func TestNewIssuer_SigningKeyFile(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "signing.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	cfg := config.TokensConfig{Issuer: "https://gateway.test", SigningKeyFile: path, TTL: 5 * time.Minute}
+	first, err := NewIssuer(cfg)
+	require.NoError(t, err)
+	second, err := NewIssuer(cfg)
+	require.NoError(t, err)
+
+	assert.False(t, first.Ephemeral())
+	assert.Equal(t, 5*time.Minute, first.TTL())
+	// Instances sharing the key publish the same key ID and accept each other's tokens
+	assert.Equal(t, first.JWKS(), second.JWKS())
+	token, _, err := first.Issue(&Claims{OrgID: "org-1"})
+	require.NoError(t, err)
+	_, err = second.ValidateToken(context.Background(), token)
+	assert.NoError(t, err)
+
+	cfg.KeyID = "gateway-2026"
+	named, err := NewIssuer(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "gateway-2026", named.JWKS().Keys[0].Kid)
+
+	_, err = NewIssuer(config.TokensConfig{Issuer: "https://gateway.test", SigningKeyFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.Error(t, err)
+}
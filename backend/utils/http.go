@@ -40,6 +40,11 @@ func WriteCreated(w http.ResponseWriter, data interface{}) error {
 	return WriteJSON(w, http.StatusCreated, SuccessResponse{Data: data})
 }
 
+// WriteAccepted writes a 202 Accepted response with optional data
+func WriteAccepted(w http.ResponseWriter, data interface{}) error {
+	return WriteJSON(w, http.StatusAccepted, SuccessResponse{Data: data})
+}
+
 // WriteNoContent writes a 204 No Content response
 func WriteNoContent(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusNoContent)
@@ -133,6 +138,8 @@ func WriteError(w http.ResponseWriter, status int, message string, details map[s
 		errorType = "not_found"
 	case http.StatusConflict:
 		errorType = "conflict"
+	case http.StatusUnprocessableEntity:
+		errorType = "unprocessable_entity"
 	case http.StatusTooManyRequests:
 		errorType = "rate_limit_exceeded"
 	default: